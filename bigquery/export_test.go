@@ -0,0 +1,33 @@
+package bigquery
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Appender exposes appender to the tests
+type Appender = appender
+
+// WithColumns exposes withColumns to the tests
+var WithColumns = withColumns
+
+// NewTestWriter returns a writer appending the rows to a
+func NewTestWriter(a Appender) (*Writer, error) {
+	desc, err := descriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{stream: a, desc: desc}, nil
+}
+
+// DecodeRow returns the row serialized by the writer as JSON
+func (w *Writer) DecodeRow(data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(w.desc)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	return protojson.Marshal(msg)
+}
@@ -0,0 +1,367 @@
+// Package bigquery streams the entries into a BigQuery table with the storage write API.
+package bigquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	bq "cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"github.com/gosom/scrapemate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	// maxBatchRows is the number of rows of an append, far below the 10MB a request accepts
+	maxBatchRows = 500
+	// maxBatchAge is how long the rows wait for a full batch
+	maxBatchAge = time.Minute
+)
+
+// schema is the schema of the table. It is partitioned by day of scraped_at and
+// clustered by category.
+var schema = bq.Schema{
+	{Name: "scraped_at", Type: bq.TimestampFieldType, Required: true},
+	{Name: "input_id", Type: bq.StringFieldType},
+	{Name: "cid", Type: bq.StringFieldType},
+	{Name: "data_id", Type: bq.StringFieldType},
+	{Name: "link", Type: bq.StringFieldType},
+	{Name: "title", Type: bq.StringFieldType},
+	{Name: "category", Type: bq.StringFieldType},
+	{Name: "categories", Type: bq.StringFieldType, Repeated: true},
+	{Name: "address", Type: bq.StringFieldType},
+	{Name: "website", Type: bq.StringFieldType},
+	{Name: "phone", Type: bq.StringFieldType},
+	{Name: "plus_code", Type: bq.StringFieldType},
+	{Name: "review_count", Type: bq.IntegerFieldType},
+	{Name: "review_rating", Type: bq.FloatFieldType},
+	{Name: "latitude", Type: bq.FloatFieldType},
+	{Name: "longitude", Type: bq.FloatFieldType},
+	{Name: "emails", Type: bq.StringFieldType, Repeated: true},
+	{Name: "data", Type: bq.JSONFieldType},
+	{Name: "distance_m", Type: bq.FloatFieldType},
+}
+
+// row is an entry in the columns of the schema, the storage write API takes the
+// timestamps in microseconds and the JSON columns as strings
+type row struct {
+	ScrapedAt    int64    `json:"scraped_at"`
+	InputID      string   `json:"input_id"`
+	Cid          string   `json:"cid"`
+	DataID       string   `json:"data_id"`
+	Link         string   `json:"link"`
+	Title        string   `json:"title"`
+	Category     string   `json:"category"`
+	Categories   []string `json:"categories,omitempty"`
+	Address      string   `json:"address"`
+	Website      string   `json:"website"`
+	Phone        string   `json:"phone"`
+	PlusCode     string   `json:"plus_code"`
+	ReviewCount  int      `json:"review_count"`
+	ReviewRating float64  `json:"review_rating"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	Emails       []string `json:"emails,omitempty"`
+	Data         string   `json:"data"`
+	DistanceM    float64  `json:"distance_m"`
+}
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer appends the entries to a table through its default stream. The table
+// is created when it does not exist.
+//
+// The default stream is at least once: a retried append may duplicate rows, the
+// places are deduplicated by cid when querying.
+type Writer struct {
+	stream appender
+	desc   protoreflect.MessageDescriptor
+}
+
+// appender appends the serialized rows to the table
+type appender interface {
+	// AppendRows returns once BigQuery acknowledged the rows
+	AppendRows(ctx context.Context, rows [][]byte) error
+	Close() error
+}
+
+// managedStream is the default stream of the table in the storage write API
+type managedStream struct {
+	client *managedwriter.Client
+	stream *managedwriter.ManagedStream
+}
+
+func (m *managedStream) AppendRows(ctx context.Context, rows [][]byte) error {
+	res, err := m.stream.AppendRows(ctx, rows)
+	if err != nil {
+		return err
+	}
+
+	_, err = res.GetResult(ctx)
+
+	return err
+}
+
+func (m *managedStream) Close() error {
+	return errors.Join(m.stream.Close(), m.client.Close())
+}
+
+// NewWriter returns the writer of table, given as project.dataset.table. The
+// credentials are the application default credentials.
+func NewWriter(ctx context.Context, table string) (*Writer, error) {
+	project, dataset, tableID, err := ParseTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureTable(ctx, project, dataset, tableID); err != nil {
+		return nil, err
+	}
+
+	desc, err := descriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	dp, err := adapt.NormalizeDescriptor(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := managedwriter.NewClient(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the BigQuery write client: %w", err)
+	}
+
+	stream, err := client.NewManagedStream(ctx,
+		managedwriter.WithDestinationTable(managedwriter.TableParentFromParts(project, dataset, tableID)),
+		managedwriter.WithType(managedwriter.DefaultStream),
+		managedwriter.WithSchemaDescriptor(dp),
+	)
+	if err != nil {
+		_ = client.Close()
+
+		return nil, fmt.Errorf("cannot open the stream of %s: %w", table, err)
+	}
+
+	return &Writer{stream: &managedStream{client: client, stream: stream}, desc: desc}, nil
+}
+
+// ParseTable splits a table given as project.dataset.table
+func ParseTable(table string) (project, dataset, tableID string, err error) {
+	parts := strings.Split(table, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid BigQuery table %q: use project.dataset.table", table)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
+
+// ensureTable creates the table, partitioned by scrape date and clustered by category
+func ensureTable(ctx context.Context, project, dataset, tableID string) error {
+	client, err := bq.NewClient(ctx, project)
+	if err != nil {
+		return fmt.Errorf("cannot create the BigQuery client: %w", err)
+	}
+
+	defer client.Close()
+
+	table := client.Dataset(dataset).Table(tableID)
+
+	current, err := table.Metadata(ctx)
+	if err == nil {
+		return addColumns(ctx, table, current)
+	}
+
+	if !isStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("cannot read the BigQuery table: %w", err)
+	}
+
+	md := bq.TableMetadata{
+		Schema: schema,
+		TimePartitioning: &bq.TimePartitioning{
+			Type:  bq.DayPartitioningType,
+			Field: "scraped_at",
+		},
+		Clustering: &bq.Clustering{Fields: []string{"category"}},
+	}
+
+	// another scraper may have created it in the meantime
+	if err := table.Create(ctx, &md); err != nil && !isStatus(err, http.StatusConflict) {
+		return fmt.Errorf("cannot create the BigQuery table: %w", err)
+	}
+
+	return nil
+}
+
+// addColumns adds the columns of the schema missing from the table, e.g. of a
+// table created by a previous version. The new columns are nullable, the rows
+// already in the table have no value.
+func addColumns(ctx context.Context, table *bq.Table, md *bq.TableMetadata) error {
+	columns := withColumns(md.Schema)
+	if len(columns) == len(md.Schema) {
+		return nil
+	}
+
+	if _, err := table.Update(ctx, bq.TableMetadataToUpdate{Schema: columns}, md.ETag); err != nil {
+		return fmt.Errorf("cannot add the columns of the BigQuery table: %w", err)
+	}
+
+	return nil
+}
+
+// withColumns returns the columns of the table followed by the columns of the
+// schema it misses
+func withColumns(columns bq.Schema) bq.Schema {
+	existing := make(map[string]struct{}, len(columns))
+	for _, f := range columns {
+		existing[f.Name] = struct{}{}
+	}
+
+	ans := slices.Clone(columns)
+
+	for _, f := range schema {
+		if _, ok := existing[f.Name]; !ok {
+			ans = append(ans, f)
+		}
+	}
+
+	return ans
+}
+
+func isStatus(err error, code int) bool {
+	var apiErr *googleapi.Error
+
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// descriptor is the protocol buffer message of the rows
+func descriptor() (protoreflect.MessageDescriptor, error) {
+	ts, err := adapt.BQSchemaToStorageTableSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := adapt.StorageSchemaToProto2Descriptor(ts, "root")
+	if err != nil {
+		return nil, err
+	}
+
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, errors.New("the BigQuery schema is not a message")
+	}
+
+	return md, nil
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	defer w.stream.Close()
+
+	buff := make([][]byte, 0, maxBatchRows)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for _, e := range entries {
+			b, err := w.encode(e, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+
+			buff = append(buff, b)
+		}
+
+		if len(buff) >= maxBatchRows || time.Now().UTC().Sub(lastSave) >= maxBatchAge {
+			if err := w.append(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	// the last rows are appended even when the context is canceled
+	return w.append(context.WithoutCancel(ctx), buff)
+}
+
+// encode returns the serialized row of the entry
+func (w *Writer) encode(e *gmaps.Entry, scrapedAt time.Time) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	r := row{
+		ScrapedAt:    scrapedAt.UnixMicro(),
+		InputID:      e.ID,
+		Cid:          e.Cid,
+		DataID:       e.DataID,
+		Link:         e.Link,
+		Title:        e.Title,
+		Category:     e.Category,
+		Categories:   e.Categories,
+		Address:      e.Address,
+		Website:      e.WebSite,
+		Phone:        e.Phone,
+		PlusCode:     e.PlusCode,
+		ReviewCount:  e.ReviewCount,
+		ReviewRating: e.ReviewRating,
+		Latitude:     e.Latitude,
+		Longitude:    e.Longtitude,
+		Emails:       e.Emails,
+		Data:         string(data),
+		DistanceM:    e.DistanceM,
+	}
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(w.desc)
+	if err := protojson.Unmarshal(raw, msg); err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(msg)
+}
+
+// append appends the rows and waits for BigQuery to acknowledge them
+func (w *Writer) append(ctx context.Context, rows [][]byte) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := w.stream.AppendRows(ctx, rows); err != nil {
+		return fmt.Errorf("cannot append the rows to BigQuery: %w", err)
+	}
+
+	return nil
+}
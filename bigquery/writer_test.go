@@ -0,0 +1,239 @@
+package bigquery_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	bq "cloud.google.com/go/bigquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/bigquery"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var errAppend = errors.New("quota exceeded")
+
+// stream is a fake default stream recording the batches of rows it is appended
+type stream struct {
+	batches [][][]byte
+	fail    bool
+	closed  bool
+}
+
+func (s *stream) AppendRows(_ context.Context, rows [][]byte) error {
+	if s.fail {
+		return errAppend
+	}
+
+	s.batches = append(s.batches, append([][]byte(nil), rows...))
+
+	return nil
+}
+
+func (s *stream) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+// entries returns n results of one entry each
+func entries(n int) []scrapemate.Result {
+	ans := make([]scrapemate.Result, 0, n)
+	for range n {
+		ans = append(ans, scrapemate.Result{Data: &gmaps.Entry{Cid: "1", Title: "a"}})
+	}
+
+	return ans
+}
+
+func runWriter(t *testing.T, s *stream, results []scrapemate.Result) error {
+	t.Helper()
+
+	w, err := bigquery.NewTestWriter(s)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	err = w.Run(context.Background(), in)
+	require.True(t, s.closed)
+
+	return err
+}
+
+func Test_WriterBatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []scrapemate.Result
+		batches []int
+	}{
+		{
+			name:    "last batch",
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Title: "a"}}, {Data: []*gmaps.Entry{{Title: "b"}, {Title: "c"}}}},
+			batches: []int{3},
+		},
+		{
+			name:    "full batch",
+			results: entries(501),
+			batches: []int{500, 1},
+		},
+		{
+			name:    "results without places",
+			results: []scrapemate.Result{{Data: nil}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var s stream
+
+			require.NoError(t, runWriter(t, &s, tc.results))
+
+			var sizes []int
+			for _, b := range s.batches {
+				sizes = append(sizes, len(b))
+			}
+
+			require.Equal(t, tc.batches, sizes)
+		})
+	}
+}
+
+func Test_WriterRow(t *testing.T) {
+	var s stream
+
+	w, err := bigquery.NewTestWriter(&s)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{
+		ID:         "query-1",
+		Cid:        "123",
+		Title:      "Cafe",
+		Categories: []string{"Cafe", "Bakery"},
+		Emails:     []string{"info@cafe.com"},
+		Latitude:   34.7,
+		Longtitude: 33.0,
+		DistanceM:  120,
+	}}
+
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+	require.Len(t, s.batches, 1)
+
+	raw, err := w.DecodeRow(s.batches[0][0])
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(raw, &got))
+
+	require.NotEmpty(t, got["scrapedAt"])
+	require.Equal(t, "query-1", got["inputId"])
+	require.Equal(t, "123", got["cid"])
+	require.Equal(t, "Cafe", got["title"])
+	require.Equal(t, []any{"Cafe", "Bakery"}, got["categories"])
+	require.Equal(t, []any{"info@cafe.com"}, got["emails"])
+	require.InDelta(t, 34.7, got["latitude"], 1e-9)
+	require.InDelta(t, 120, got["distanceM"], 1e-9)
+
+	var data gmaps.Entry
+	require.NoError(t, json.Unmarshal([]byte(got["data"].(string)), &data))
+	require.Equal(t, "Cafe", data.Title)
+}
+
+func Test_WriterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    bool
+		results []scrapemate.Result
+		err     error
+	}{
+		{
+			name:    "append fails",
+			fail:    true,
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Title: "a"}}},
+			err:     errAppend,
+		},
+		{
+			name:    "full batch fails",
+			fail:    true,
+			results: entries(500),
+			err:     errAppend,
+		},
+		{
+			name:    "invalid data",
+			results: []scrapemate.Result{{Data: "place"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := stream{fail: tc.fail}
+
+			err := runWriter(t, &s, tc.results)
+			require.Error(t, err)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func Test_WithColumns(t *testing.T) {
+	names := func(s bq.Schema) []string {
+		var ans []string
+		for _, f := range s {
+			ans = append(ans, f.Name)
+		}
+
+		return ans
+	}
+
+	full := bigquery.WithColumns(nil)
+
+	// the columns of a table created by a previous version are kept first
+	previous := bq.Schema{{Name: "scraped_at"}, {Name: "custom"}}
+	got := names(bigquery.WithColumns(previous))
+
+	require.Equal(t, []string{"scraped_at", "custom"}, got[:2])
+	require.Len(t, got, len(full)+1)
+	require.Equal(t, names(full)[1:], got[2:])
+
+	// the table with every column is not changed
+	require.Len(t, bigquery.WithColumns(full), len(full))
+}
+
+func Test_ParseTable(t *testing.T) {
+	tests := []struct {
+		table string
+		want  []string
+	}{
+		{table: "project.dataset.places", want: []string{"project", "dataset", "places"}},
+		{table: "dataset.places"},
+		{table: "project..places"},
+		{table: "a.b.c.d"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.table, func(t *testing.T) {
+			project, dataset, table, err := bigquery.ParseTable(tc.table)
+			if tc.want == nil {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, []string{project, dataset, table})
+		})
+	}
+}
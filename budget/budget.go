@@ -0,0 +1,230 @@
+// Package budget counts the requests of a run by type, stops the run when it
+// sends more requests than its cap and reports their cost.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind is the type of a request
+type Kind string
+
+const (
+	// Search is a request of a search, a page of results or a scroll
+	Search Kind = "search"
+	// Detail is a request of the page of a place
+	Detail Kind = "detail"
+	// Review is a request of a page of the reviews of a place
+	Review Kind = "review"
+	// Enrichment is a request of the data added to the places, e.g. their
+	// websites for the emails or their photos
+	Enrichment Kind = "enrichment"
+)
+
+// Kinds are the types of the requests in the order they are reported
+var Kinds = []Kind{Search, Detail, Review, Enrichment}
+
+// ErrExceeded is the error of the requests refused once the run has sent its
+// maximum number of requests
+var ErrExceeded = errors.New("request budget exceeded")
+
+// Prices are the prices of a request of every kind, e.g. the price of the
+// proxy or of the API sending it. The costs are in the unit of the prices.
+type Prices map[Kind]float64
+
+// ParsePrices parses prices like "search=0.002,detail=0.001", the kinds
+// without a price are free
+func ParsePrices(s string) (Prices, error) {
+	ans := make(Prices)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid price %q: use kind=price", part)
+		}
+
+		kind := Kind(strings.ToLower(strings.TrimSpace(name)))
+		if !validKind(kind) {
+			return nil, fmt.Errorf("invalid request kind %q: use one of %s", name, kindNames())
+		}
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price of %s: %w", kind, err)
+		}
+
+		if price < 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+			return nil, fmt.Errorf("invalid price of %s: %q must be 0 or greater", kind, value)
+		}
+
+		ans[kind] = price
+	}
+
+	return ans, nil
+}
+
+// KindReport is the requests and the cost of a kind of request
+type KindReport struct {
+	Kind     Kind    `json:"kind"`
+	Requests int     `json:"requests"`
+	Price    float64 `json:"price"`
+	Cost     float64 `json:"cost"`
+}
+
+// Report is the requests and the cost of a run
+type Report struct {
+	Requests int `json:"requests"`
+	// MaxRequests is the cap of the run, 0 without a cap
+	MaxRequests int `json:"max_requests"`
+	// Refused is the number of the requests refused once the cap was reached
+	Refused int          `json:"refused"`
+	Kinds   []KindReport `json:"kinds"`
+	Cost    float64      `json:"cost"`
+}
+
+// Exceeded reports whether the run was stopped by its cap
+func (r Report) Exceeded() bool {
+	return r.Refused > 0
+}
+
+// Tracker counts the requests of a run, it is safe for concurrent use
+type Tracker struct {
+	mu sync.Mutex
+
+	maxRequests int
+	prices      Prices
+	cancelFunc  context.CancelFunc
+
+	requests map[Kind]int
+	total    int
+	refused  int
+}
+
+// New returns a tracker allowing at most maxRequests requests, 0 allows any
+// number of them. prices are the prices of the requests of the cost report.
+func New(maxRequests int, prices Prices) *Tracker {
+	return &Tracker{
+		maxRequests: maxRequests,
+		prices:      prices,
+		requests:    make(map[Kind]int),
+	}
+}
+
+// SetCancelFunc sets the function stopping the run once the cap is reached
+func (t *Tracker) SetCancelFunc(fn context.CancelFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cancelFunc = fn
+}
+
+// Allow counts a request of kind. It returns ErrExceeded, and stops the run,
+// when the run has already sent its maximum number of requests: the request
+// must not be sent.
+func (t *Tracker) Allow(kind Kind) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.maxRequests > 0 && t.total >= t.maxRequests {
+		t.refused++
+
+		if t.refused == 1 {
+			slog.Warn("request budget exceeded, stopping the run", "max_requests", t.maxRequests)
+
+			if t.cancelFunc != nil {
+				t.cancelFunc()
+			}
+		}
+
+		return ErrExceeded
+	}
+
+	t.total++
+	t.requests[kind]++
+
+	return nil
+}
+
+// Report returns the requests counted so far and their cost
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ans := Report{
+		Requests:    t.total,
+		MaxRequests: t.maxRequests,
+		Refused:     t.refused,
+		Kinds:       make([]KindReport, 0, len(Kinds)),
+	}
+
+	for _, kind := range Kinds {
+		k := KindReport{
+			Kind:     kind,
+			Requests: t.requests[kind],
+			Price:    t.prices[kind],
+		}
+
+		k.Cost = round(float64(k.Requests) * k.Price)
+		ans.Cost += float64(k.Requests) * k.Price
+
+		ans.Kinds = append(ans.Kinds, k)
+	}
+
+	ans.Cost = round(ans.Cost)
+
+	return ans
+}
+
+// LogReport logs the requests of every kind and the cost of the run
+func LogReport(r Report) {
+	args := []any{"requests", r.Requests}
+
+	for _, k := range r.Kinds {
+		args = append(args, string(k.Kind), k.Requests)
+	}
+
+	args = append(args, "cost", r.Cost)
+
+	for _, k := range r.Kinds {
+		if k.Price > 0 {
+			args = append(args, string(k.Kind)+"_cost", k.Cost)
+		}
+	}
+
+	if r.MaxRequests > 0 {
+		args = append(args, "max_requests", r.MaxRequests, "refused", r.Refused)
+	}
+
+	slog.Info("request budget", args...)
+}
+
+func validKind(kind Kind) bool {
+	return slices.Contains(Kinds, kind)
+}
+
+func kindNames() string {
+	names := make([]string, 0, len(Kinds))
+	for _, k := range Kinds {
+		names = append(names, string(k))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// round keeps 6 decimals, the prices of the requests are fractions of a cent
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
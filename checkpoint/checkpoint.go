@@ -0,0 +1,368 @@
+// Package checkpoint saves the progress of a run to the state store, so that
+// an interrupted run can be resumed without searching the completed seeds
+// again.
+//
+// A seed is completed when all the jobs it created are done without error:
+// its search, its places and their reviews, emails and photos. The seeds that
+// were in progress are searched again when the run is resumed.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// DefaultInterval is the time between two saves of the checkpoint
+const DefaultInterval = 30 * time.Second
+
+// State is the content of the checkpoint file
+type State struct {
+	SavedAt time.Time `json:"saved_at"`
+	// Completed are the keys of the completed seeds
+	Completed []string `json:"completed"`
+	// InProgress are the keys of the seeds with jobs in the queue and the number
+	// of these jobs
+	InProgress map[string]int `json:"in_progress"`
+	// Failed are the keys of the seeds with a failed job
+	Failed []string `json:"failed"`
+}
+
+// Key returns the key of a seed in the checkpoint. The url of the seed has
+// its query, its coordinates and its zoom, it is the same in every run.
+func Key(job scrapemate.IJob) string {
+	return job.GetFullURL()
+}
+
+var _ scrapemate.JobProvider = (*Provider)(nil)
+
+// Provider is a job provider that follows the jobs of the seeds to know the
+// completed ones and saves them in the checkpoint of the state store
+type Provider struct {
+	next  scrapemate.JobProvider
+	store state.Store
+	key   string
+
+	mu sync.Mutex
+	// seeds are the keys of the seed jobs by job id
+	seeds map[string]string
+	// roots are the keys of the seeds of the jobs by job id
+	roots map[string]string
+	// ids are the ids of the jobs of the seeds in progress
+	ids       map[string][]string
+	pending   map[string]int
+	failed    map[string]struct{}
+	completed map[string]struct{}
+	// running are the jobs taken by the workers and not done, by job id
+	running map[string]scrapemate.IJob
+	// finished and settled are the ids of the jobs of the seeds finished since
+	// the last save and before it. Their roots are kept until the next save, so
+	// that a failure received from scrapemate after its seed finished still
+	// fails the seed.
+	finished []string
+	settled  []string
+}
+
+// New returns a provider that pushes the jobs to next and saves the progress
+// in the key of store. With resume the seeds completed in the checkpoint are
+// skipped.
+func New(ctx context.Context, next scrapemate.JobProvider, store state.Store, key string, resume bool) (*Provider, error) {
+	p := Provider{
+		next:      next,
+		store:     store,
+		key:       key,
+		seeds:     make(map[string]string),
+		roots:     make(map[string]string),
+		ids:       make(map[string][]string),
+		pending:   make(map[string]int),
+		failed:    make(map[string]struct{}),
+		completed: make(map[string]struct{}),
+		running:   make(map[string]scrapemate.IJob),
+	}
+
+	if !resume {
+		return &p, nil
+	}
+
+	saved, err := Load(ctx, store, key)
+
+	switch {
+	case errors.Is(err, state.ErrNotFound):
+		slog.Info("checkpoint: no checkpoint to resume, starting a new run", "key", key)
+	case err != nil:
+		return nil, err
+	default:
+		for _, seed := range saved.Completed {
+			p.completed[seed] = struct{}{}
+		}
+
+		slog.Info("checkpoint: resuming the run", "key", key, "completed", len(saved.Completed), "saved_at", saved.SavedAt)
+	}
+
+	return &p, nil
+}
+
+// Load reads the checkpoint of the key of store, state.ErrNotFound when there
+// is none
+func Load(ctx context.Context, store state.Store, key string) (State, error) {
+	var saved State
+
+	b, err := store.Get(ctx, key)
+	if err != nil {
+		return saved, err
+	}
+
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return saved, fmt.Errorf("invalid checkpoint %s: %w", key, err)
+	}
+
+	return saved, nil
+}
+
+// Seeds returns the seeds that are not completed. They must be the seeds of
+// the run, the jobs they create are followed to complete them.
+func (p *Provider) Seeds(jobs []scrapemate.IJob) []scrapemate.IJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ans := make([]scrapemate.IJob, 0, len(jobs))
+
+	for _, job := range jobs {
+		key := Key(job)
+
+		if _, ok := p.completed[key]; ok {
+			continue
+		}
+
+		p.seeds[job.GetID()] = key
+
+		ans = append(ans, job)
+	}
+
+	if skipped := len(jobs) - len(ans); skipped > 0 {
+		slog.Info("checkpoint: completed seeds skipped", "skipped", skipped, "remaining", len(ans))
+	}
+
+	return ans
+}
+
+// Push follows the job and pushes it to the next provider
+func (p *Provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	p.mu.Lock()
+
+	key, ok := p.seeds[job.GetID()]
+	if !ok {
+		// the jobs are created by the job of their parent id
+		key, ok = p.roots[job.GetParentID()]
+	}
+
+	if ok {
+		p.roots[job.GetID()] = key
+		p.ids[key] = append(p.ids[key], job.GetID())
+		p.pending[key]++
+	}
+
+	p.mu.Unlock()
+
+	return p.next.Push(ctx, job)
+}
+
+// Jobs returns the jobs of the next provider. Every worker of scrapemate has
+// its own channel and takes its next job when the previous one is done, the
+// previous job of the channel is done when a job is received.
+//
+//nolint:gocritic // scrapemate.JobProvider returns read only channels
+func (p *Provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	in, errc := p.next.Jobs(ctx)
+	out := make(chan scrapemate.IJob)
+
+	go func() {
+		var current scrapemate.IJob
+
+		for {
+			var job scrapemate.IJob
+
+			select {
+			case <-ctx.Done():
+				return
+			case job = <-in:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- job:
+			}
+
+			p.taken(job)
+
+			if current != nil {
+				p.done(current)
+			}
+
+			current = job
+		}
+	}()
+
+	return out, errc
+}
+
+// Failed records that a job failed, its seed is not completed in this run.
+// The failures of the jobs of gmaps are recorded when the jobs are done, the
+// failed jobs of scrapemate are received after it.
+func (p *Provider) Failed(job scrapemate.IJob) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.roots[job.GetID()]; ok {
+		p.failed[key] = struct{}{}
+		delete(p.completed, key)
+	}
+}
+
+func (p *Provider) taken(job scrapemate.IJob) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.roots[job.GetID()]; ok {
+		p.running[job.GetID()] = job
+	}
+}
+
+func (p *Provider) done(job scrapemate.IJob) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.finishJob(job)
+}
+
+func (p *Provider) finishJob(job scrapemate.IJob) {
+	delete(p.running, job.GetID())
+
+	key, ok := p.roots[job.GetID()]
+	if !ok {
+		return
+	}
+
+	if failed, _ := gmaps.JobFailed(job); failed {
+		p.failed[key] = struct{}{}
+		delete(p.completed, key)
+	}
+
+	// the seed of the job is finished, its roots are kept until it is settled
+	if _, ok := p.pending[key]; !ok {
+		return
+	}
+
+	p.pending[key]--
+	if p.pending[key] > 0 {
+		return
+	}
+
+	p.finish(key)
+}
+
+// finish completes a seed whose jobs are all done
+func (p *Provider) finish(key string) {
+	if _, ok := p.failed[key]; !ok {
+		p.completed[key] = struct{}{}
+	}
+
+	p.finished = append(p.finished, p.ids[key]...)
+
+	delete(p.ids, key)
+	delete(p.pending, key)
+}
+
+// Complete completes the seeds in progress. It is called when all the jobs
+// of the run are done, the last jobs of the workers are done then.
+func (p *Provider) Complete() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, job := range p.running {
+		p.finishJob(job)
+	}
+
+	for key := range p.pending {
+		p.finish(key)
+	}
+}
+
+// Run saves the checkpoint every interval until ctx is done
+func (p *Provider) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Save(ctx); err != nil {
+				slog.Error("checkpoint: cannot save the checkpoint", "key", p.key, "error", err)
+			}
+		}
+	}
+}
+
+// Save writes the checkpoint to the store, at once so that an interrupted
+// save keeps the previous checkpoint
+func (p *Provider) Save(ctx context.Context) error {
+	b, err := json.MarshalIndent(p.state(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := p.store.Put(ctx, p.key, b); err != nil {
+		return fmt.Errorf("cannot save the checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Provider) state() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the failures of the jobs finished before the previous save are settled
+	for _, id := range p.settled {
+		delete(p.roots, id)
+	}
+
+	p.settled, p.finished = p.finished, nil
+
+	state := State{
+		SavedAt:    time.Now().UTC(),
+		Completed:  make([]string, 0, len(p.completed)),
+		InProgress: make(map[string]int, len(p.pending)),
+		Failed:     make([]string, 0, len(p.failed)),
+	}
+
+	for key := range p.completed {
+		state.Completed = append(state.Completed, key)
+	}
+
+	for key, n := range p.pending {
+		state.InProgress[key] = n
+	}
+
+	for key := range p.failed {
+		state.Failed = append(state.Failed, key)
+	}
+
+	sort.Strings(state.Completed)
+	sort.Strings(state.Failed)
+
+	return state
+}
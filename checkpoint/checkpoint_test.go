@@ -0,0 +1,148 @@
+package checkpoint_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/checkpoint"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// queue is a job provider keeping the pushed jobs in a channel
+type queue struct {
+	jobs chan scrapemate.IJob
+}
+
+func newQueue() *queue {
+	return &queue{jobs: make(chan scrapemate.IJob, 10)}
+}
+
+func (q *queue) Push(_ context.Context, job scrapemate.IJob) error {
+	q.jobs <- job
+
+	return nil
+}
+
+//nolint:gocritic // scrapemate.JobProvider returns read only channels
+func (q *queue) Jobs(_ context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	return q.jobs, make(chan error)
+}
+
+func newJob(id, parentID, u string) *scrapemate.Job {
+	return &scrapemate.Job{ID: id, ParentID: parentID, URL: u}
+}
+
+// failedPlaceJob returns a place job of the seed whose fetch is refused
+func failedPlaceJob(parentID string) scrapemate.IJob {
+	job := gmaps.NewPlaceJob(parentID, "en", "https://www.google.com/maps/place/x", false, false)
+	job.DoCheckResponse(&scrapemate.Response{StatusCode: http.StatusInternalServerError})
+
+	return job
+}
+
+func Test_ProviderFailedChild(t *testing.T) {
+	const seedURL = "https://www.google.com/maps/search/cafe"
+
+	tests := []struct {
+		name string
+		// child returns the child job of the seed
+		child func(parentID string) scrapemate.IJob
+		// late reports the failure of the child after it is done, like the
+		// failed jobs of scrapemate
+		late      bool
+		completed bool
+	}{
+		{
+			name:      "child done",
+			child:     func(parentID string) scrapemate.IJob { return newJob("child", parentID, "https://example.com") },
+			completed: true,
+		},
+		{
+			name:  "child failed when done",
+			child: failedPlaceJob,
+		},
+		{
+			name:  "child failure received after the seed finished",
+			child: func(parentID string) scrapemate.IJob { return newJob("child", parentID, "https://example.com") },
+			late:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			store, err := state.NewFS(t.TempDir())
+			require.NoError(t, err)
+
+			p, err := checkpoint.New(ctx, newQueue(), store, "checkpoint.json", false)
+			require.NoError(t, err)
+
+			seed := newJob("seed", "", seedURL)
+			require.Len(t, p.Seeds([]scrapemate.IJob{seed}), 1)
+			require.NoError(t, p.Push(ctx, seed))
+
+			jobs, _ := p.Jobs(ctx)
+			require.Equal(t, seed, <-jobs)
+
+			// the seed creates its child while it is processed
+			child := tc.child(seed.GetID())
+			require.NoError(t, p.Push(ctx, child))
+			require.Equal(t, child, <-jobs)
+
+			// the worker of the child takes another job, the child is done
+			other := newJob("other", "", "https://example.com/other")
+			require.NoError(t, p.Push(ctx, other))
+			require.Equal(t, other, <-jobs)
+
+			p.Complete()
+
+			// the seed is finished, a save before the failure keeps its roots
+			require.NoError(t, p.Save(ctx))
+
+			if tc.late {
+				p.Failed(child)
+			}
+
+			require.NoError(t, p.Save(ctx))
+
+			saved, err := checkpoint.Load(ctx, store, "checkpoint.json")
+			require.NoError(t, err)
+
+			if tc.completed {
+				require.Equal(t, []string{seedURL}, saved.Completed)
+				require.Empty(t, saved.Failed)
+			} else {
+				require.Empty(t, saved.Completed)
+				require.Equal(t, []string{seedURL}, saved.Failed)
+			}
+
+			require.Empty(t, saved.InProgress)
+		})
+	}
+}
+
+func Test_ProviderResume(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := state.NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = checkpoint.Load(ctx, store, "checkpoint.json")
+	require.ErrorIs(t, err, state.ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "checkpoint.json", []byte(`{"completed":["https://example.com/a"]}`)))
+
+	p, err := checkpoint.New(ctx, newQueue(), store, "checkpoint.json", true)
+	require.NoError(t, err)
+
+	a, b := newJob("a", "", "https://example.com/a"), newJob("b", "", "https://example.com/b")
+
+	require.Equal(t, []scrapemate.IJob{b}, p.Seeds([]scrapemate.IJob{a, b}))
+}
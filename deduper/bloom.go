@@ -0,0 +1,210 @@
+package deduper
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+)
+
+var _ Deduper = (*Bloom)(nil)
+
+const bloomMagic = "GMBLOOM1"
+
+// maxBloomHashes is the maximum number of hashes of a filter, a false
+// positive rate of 1e-15 needs 50
+const maxBloomHashes = 64
+
+// bloomHeaderSize is the size of the magic and of the header of a filter file
+const bloomHeaderSize = len(bloomMagic) + 16
+
+// ErrInvalidBloom is returned when a bloom filter file is not valid
+var ErrInvalidBloom = errors.New("invalid bloom filter file")
+
+// Bloom is a Deduper backed by a bloom filter.
+// It uses a fraction of the memory of the default deduper and can be saved
+// to disk to skip the places processed in previous runs.
+// A key may be wrongly reported as seen with the configured false positive rate.
+type Bloom struct {
+	mu   sync.Mutex
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// NewBloom creates a bloom filter sized for capacity keys
+// with false positive rate fpRate.
+func NewBloom(capacity int, fpRate float64) (*Bloom, error) {
+	if capacity <= 0 {
+		return nil, errors.New("bloom capacity must be positive")
+	}
+
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, errors.New("bloom false positive rate must be between 0 and 1")
+	}
+
+	m, k := bloomParams(capacity, fpRate)
+
+	return newBloom(m, k), nil
+}
+
+// bloomParams returns the number of bits and of hashes of a filter sized for
+// capacity keys with false positive rate fpRate
+func bloomParams(capacity int, fpRate float64) (m, k uint64) {
+	n := float64(capacity)
+	bits := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(1, math.Round(bits/n*math.Ln2))
+
+	return uint64(bits), uint64(hashes)
+}
+
+func newBloom(m, k uint64) *Bloom {
+	return &Bloom{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// LoadBloom reads the filter saved in path.
+// If the file does not exist a new filter is created using capacity and fpRate.
+// The filter of the file is kept when it was sized for other values, with a
+// warning.
+func LoadBloom(path string, capacity int, fpRate float64) (*Bloom, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewBloom(capacity, fpRate)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(f)
+
+	magic := make([]byte, len(bloomMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != bloomMagic {
+		return nil, fmt.Errorf("%w: %s is not a bloom filter file", ErrInvalidBloom, path)
+	}
+
+	var header [2]uint64
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("%w: %s: failed to read the header: %w", ErrInvalidBloom, path, err)
+	}
+
+	m, k := header[0], header[1]
+
+	switch {
+	case m == 0:
+		return nil, fmt.Errorf("%w: %s: the filter has no bits", ErrInvalidBloom, path)
+	case k == 0 || k > maxBloomHashes:
+		return nil, fmt.Errorf("%w: %s: the filter has %d hashes, not between 1 and %d", ErrInvalidBloom, path, k, maxBloomHashes)
+	}
+
+	// the size is checked before the bits are allocated
+	words := (m + 63) / 64
+	if size := uint64(info.Size()) - uint64(bloomHeaderSize); size/8 != words || size%8 != 0 {
+		return nil, fmt.Errorf("%w: %s: the filter of %d bits has %d bytes of bits, not %d", ErrInvalidBloom, path, m, size, words*8)
+	}
+
+	if wm, wk := bloomParams(capacity, fpRate); capacity > 0 && fpRate > 0 && fpRate < 1 && (wm != m || wk != k) {
+		slog.Warn("the bloom filter file was sized for another capacity or false positive rate, its size is kept",
+			"path", path, "bits", m, "hashes", k, "capacity", capacity, "fp_rate", fpRate)
+	}
+
+	b := newBloom(m, k)
+
+	if err := binary.Read(r, binary.LittleEndian, b.bits); err != nil {
+		return nil, fmt.Errorf("%w: %s: failed to read the bits: %w", ErrInvalidBloom, path, err)
+	}
+
+	return b, nil
+}
+
+// Save writes the filter to path
+func (b *Bloom) Save(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	_, err = w.WriteString(bloomMagic)
+	if err == nil {
+		err = binary.Write(w, binary.LittleEndian, [2]uint64{b.m, b.k})
+	}
+
+	if err == nil {
+		err = binary.Write(w, binary.LittleEndian, b.bits)
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("failed to save bloom filter: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (b *Bloom) AddIfNotExists(_ context.Context, key string) bool {
+	h1, h2 := bloomHashes(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	added := false
+
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			added = true
+		}
+	}
+
+	return added
+}
+
+// bloomHashes returns the two hashes used for double hashing
+func bloomHashes(key string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(key))
+
+	sum := h.Sum(nil)
+
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+
+	return h1, h2
+}
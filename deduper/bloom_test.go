@@ -0,0 +1,110 @@
+package deduper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+// bloomFile returns a filter file with the header m, k and n words of bits
+func bloomFile(m, k uint64, n int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("GMBLOOM1")
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint64{m, k})
+	_ = binary.Write(&buf, binary.LittleEndian, make([]uint64, n))
+
+	return buf.Bytes()
+}
+
+func Test_LoadBloom(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  bool
+	}{
+		{
+			name: "valid",
+			data: bloomFile(128, 3, 2),
+		},
+		{
+			name: "not a filter",
+			data: []byte("place_id\n"),
+			err:  true,
+		},
+		{
+			name: "truncated header",
+			data: bloomFile(128, 3, 0)[:12],
+			err:  true,
+		},
+		{
+			name: "no bits",
+			data: bloomFile(0, 3, 0),
+			err:  true,
+		},
+		{
+			name: "no hashes",
+			data: bloomFile(128, 0, 2),
+			err:  true,
+		},
+		{
+			name: "too many hashes",
+			data: bloomFile(128, 1000, 2),
+			err:  true,
+		},
+		{
+			name: "truncated bits",
+			data: bloomFile(128, 3, 1),
+			err:  true,
+		},
+		{
+			name: "bits larger than the header",
+			data: bloomFile(128, 3, 3),
+			err:  true,
+		},
+		{
+			name: "bits of a huge filter",
+			data: bloomFile(1<<62, 3, 2),
+			err:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seen.bloom")
+			require.NoError(t, os.WriteFile(path, tc.data, 0o600))
+
+			b, err := deduper.LoadBloom(path, 1000, 0.01)
+			if tc.err {
+				require.ErrorIs(t, err, deduper.ErrInvalidBloom)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, b.AddIfNotExists(context.Background(), "a"))
+		})
+	}
+}
+
+func Test_BloomSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.bloom")
+
+	b, err := deduper.LoadBloom(path, 1000, 0.01)
+	require.NoError(t, err)
+	require.True(t, b.AddIfNotExists(context.Background(), "a"))
+	require.NoError(t, b.Save(path))
+
+	// the filter of the file is kept with other flags
+	b, err = deduper.LoadBloom(path, 10, 0.5)
+	require.NoError(t, err)
+	require.False(t, b.AddIfNotExists(context.Background(), "a"))
+	require.True(t, b.AddIfNotExists(context.Background(), "b"))
+}
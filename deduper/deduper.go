@@ -3,12 +3,32 @@ package deduper
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 type Deduper interface {
 	AddIfNotExists(context.Context, string) bool
 }
 
+// Store is a Deduper that persists the seen keys, they survive the restarts
+// and are shared by the scrapers using the same store. With a ttl the keys
+// added before the ttl are expired: they are added again and not seen.
+type Store interface {
+	Deduper
+	// Seen reports whether key was added, without adding it
+	Seen(ctx context.Context, key string) bool
+	Close() error
+}
+
+// cutoff returns the unix time the keys added before are expired
+func cutoff(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	return time.Now().Add(-ttl).Unix()
+}
+
 func New() Deduper {
 	return &hashmap{
 		seen: make(map[uint64]struct{}),
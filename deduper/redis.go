@@ -0,0 +1,74 @@
+package deduper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Store = (*redisStore)(nil)
+
+// redisAddScript adds a key to the sorted set with the time it was added,
+// unless it is there and not expired. The expired keys are removed.
+var redisAddScript = redis.NewScript(`
+if tonumber(ARGV[3]) > 0 then
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[3])
+end
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if score and tonumber(score) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+type redisStore struct {
+	client redis.UniversalClient
+	key    string
+	ttl    time.Duration
+}
+
+// NewRedisStore returns a Store that keeps the seen keys in the redis sorted
+// set key, scored by the time they were added. The keys added before ttl are
+// expired, 0 keeps them forever. The client is closed with the store.
+func NewRedisStore(client redis.UniversalClient, key string, ttl time.Duration) Store {
+	return &redisStore{
+		client: client,
+		key:    key,
+		ttl:    ttl,
+	}
+}
+
+func (d *redisStore) AddIfNotExists(ctx context.Context, key string) bool {
+	added, err := redisAddScript.Run(ctx, d.client, []string{d.key}, key, time.Now().Unix(), cutoff(d.ttl)).Int()
+	if err != nil {
+		// better to scrape a place twice than to miss it
+		slog.Warn("redis dedup failed", "key", key, "error", err)
+
+		return true
+	}
+
+	return added == 1
+}
+
+func (d *redisStore) Seen(ctx context.Context, key string) bool {
+	score, err := d.client.ZScore(ctx, d.key, key).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return false
+	case err != nil:
+		slog.Warn("redis dedup failed", "key", key, "error", err)
+
+		return false
+	}
+
+	return int64(score) >= cutoff(d.ttl)
+}
+
+func (d *redisStore) Close() error {
+	return d.client.Close()
+}
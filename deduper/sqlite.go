@@ -0,0 +1,143 @@
+package deduper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	_ "modernc.org/sqlite" // sqlite driver
+)
+
+var _ Store = (*sqliteStore)(nil)
+
+// sqliteTable is the table of the seen keys of NewSQLite
+const sqliteTable = "seen"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS %s (
+	key TEXT PRIMARY KEY,
+	seen_at INTEGER NOT NULL
+) WITHOUT ROWID;
+`
+
+// sqliteTableRe matches the names of the tables of the seen keys
+var sqliteTableRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// sqliteStore keeps the seen keys and the time they were added in a SQLite
+// database file. The database is in WAL mode with a busy timeout, so several
+// scrapers can share the file.
+type sqliteStore struct {
+	db    *sql.DB
+	table string
+	ttl   time.Duration
+	// owned is set when the store opened the database, it closes it
+	owned bool
+}
+
+// NewSQLite opens the SQLite database file path, it is created if it does
+// not exist. The keys added before ttl are expired, 0 keeps them forever.
+func NewSQLite(path string, ttl time.Duration) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	pragmas := []string{
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+	}
+
+	for _, q := range pragmas {
+		if _, err := db.Exec(q); err != nil {
+			_ = db.Close()
+
+			return nil, err
+		}
+	}
+
+	store, err := newSQLiteStore(db, sqliteTable, ttl)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	store.owned = true
+
+	return store, nil
+}
+
+// NewSQLiteDB returns a Store keeping the seen keys in the table of db, it is
+// created if it does not exist. The database is not closed with the store.
+func NewSQLiteDB(db *sql.DB, table string, ttl time.Duration) (Store, error) {
+	store, err := newSQLiteStore(db, table, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func newSQLiteStore(db *sql.DB, table string, ttl time.Duration) (*sqliteStore, error) {
+	if !sqliteTableRe.MatchString(table) {
+		return nil, fmt.Errorf("invalid table of the seen keys %q", table)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(sqliteSchema, table)); err != nil {
+		return nil, err
+	}
+
+	return &sqliteStore{db: db, table: table, ttl: ttl}, nil
+}
+
+func (d *sqliteStore) AddIfNotExists(ctx context.Context, key string) bool {
+	// an expired key is added again
+	res, err := d.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (key, seen_at) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET seen_at = excluded.seen_at WHERE %[1]s.seen_at < ?`, d.table),
+		key, time.Now().UTC().Unix(), cutoff(d.ttl))
+	if err == nil {
+		var n int64
+
+		n, err = res.RowsAffected()
+		if err == nil {
+			return n == 1
+		}
+	}
+
+	// better to scrape a place twice than to miss it
+	slog.Warn("sqlite dedup failed", "key", key, "error", err)
+
+	return true
+}
+
+func (d *sqliteStore) Seen(ctx context.Context, key string) bool {
+	var seenAt int64
+
+	err := d.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT seen_at FROM %s WHERE key = ?`, d.table), key).Scan(&seenAt)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false
+	case err != nil:
+		slog.Warn("sqlite dedup failed", "key", key, "error", err)
+
+		return false
+	}
+
+	return seenAt >= cutoff(d.ttl)
+}
+
+func (d *sqliteStore) Close() error {
+	if !d.owned {
+		return nil
+	}
+
+	return d.db.Close()
+}
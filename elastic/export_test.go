@@ -0,0 +1,9 @@
+package elastic
+
+import "time"
+
+// SetBackoff sets the wait before the first retry, far below the one of the
+// clusters in the tests
+func (w *Writer) SetBackoff(backoff time.Duration) {
+	w.backoff = backoff
+}
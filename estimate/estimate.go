@@ -0,0 +1,165 @@
+// Package estimate estimates the size of a run before it is launched,
+// so that a quote can be shown before scraping.
+package estimate
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Params describe a run the way the runners create it
+type Params struct {
+	// Queries is the number of search queries
+	Queries  int  `json:"queries"`
+	FastMode bool `json:"fast_mode"`
+	// Depth is the maximum scroll depth of the search results (not in fast mode)
+	Depth int `json:"depth"`
+	// Pages is the maximum number of result pages of a search (fast mode),
+	// the estimate assumes the pages are full
+	Pages        int  `json:"pages"`
+	Email        bool `json:"email"`
+	EmailPages   int  `json:"email_pages"`
+	ExtraReviews bool `json:"extra_reviews"`
+	Concurrency  int  `json:"concurrency"`
+	// PlacesPerQuery overrides the expected number of places of a query, e.g.
+	// with the counts of a previous run of the same area. 0 uses the defaults.
+	PlacesPerQuery int `json:"places_per_query"`
+	// Area is the circle searched by the queries and Polygon the polygons
+	// searched instead of it. In fast mode they are split in the tiles of
+	// Viewport at Zoom, 0 for the zoom covering them, like the seed jobs.
+	Area     *gmaps.MapLocation `json:"-"`
+	Polygon  *gmaps.Polygon     `json:"-"`
+	Zoom     int                `json:"zoom"`
+	Viewport gmaps.Viewport     `json:"-"`
+	// TilesPerQuery overrides the tiles of a query, e.g. with the searches
+	// planned by a dry run. 0 uses the tiles of Area or Polygon.
+	TilesPerQuery int `json:"tiles_per_query"`
+}
+
+// Estimate is the expected size of a run
+type Estimate struct {
+	// Tiles is the number of map searches: one per query and tile of its area
+	Tiles          int           `json:"tiles"`
+	Places         int           `json:"places"`
+	Requests       int           `json:"requests"`
+	BandwidthBytes int64         `json:"bandwidth_bytes"`
+	Duration       time.Duration `json:"duration"`
+}
+
+// The averages of the runs the estimate is based on. The real numbers depend
+// on the area, the category and the network; the estimate is an order of magnitude.
+const (
+	// a scroll of the results feed loads about this many places
+	placesPerScroll = 10
+	// google maps stops listing results after this many places per search
+	maxPlacesPerSearch = 120
+	// the fast mode search returns a single page of results
+	fastModePlaces = 20
+	// share of the places with a website the emails are looked for
+	websiteRate = 0.7
+	// average pages of extra reviews of a place (20 reviews per page)
+	reviewPagesPerPlace = 5
+
+	browserPageBytes = 2_500_000
+	searchPageBytes  = 150_000
+	websitePageBytes = 500_000
+	reviewPageBytes  = 60_000
+
+	browserPageTime = 4 * time.Second
+	httpRequestTime = time.Second
+)
+
+var ErrNoQueries = errors.New("no queries to estimate")
+
+// EstimateRun returns the expected tiles, requests, bandwidth and duration of a run
+func EstimateRun(p Params) (Estimate, error) {
+	if p.Queries <= 0 {
+		return Estimate{}, ErrNoQueries
+	}
+
+	concurrency := max(p.Concurrency, 1)
+	emailPages := max(p.EmailPages, 1)
+	pages := max(p.Pages, 1)
+
+	ans := Estimate{Tiles: p.Queries * tilesPerQuery(&p)}
+
+	// every tile is a search returning its own places
+	placesPerSearch := min(max(p.Depth, 1)*placesPerScroll, maxPlacesPerSearch)
+	if p.FastMode {
+		placesPerSearch = fastModePlaces * pages
+	}
+
+	ans.Places = ans.Tiles * placesPerSearch
+
+	if p.PlacesPerQuery > 0 {
+		ans.Places = p.Queries * p.PlacesPerQuery
+	}
+
+	var (
+		browserPages int
+		httpRequests int
+	)
+
+	if p.FastMode {
+		httpRequests += ans.Tiles * pages
+		ans.BandwidthBytes += int64(ans.Tiles*pages) * searchPageBytes
+	} else {
+		browserPages += ans.Tiles + ans.Places
+		ans.BandwidthBytes += int64(ans.Tiles+ans.Places) * browserPageBytes
+
+		if p.ExtraReviews {
+			reviewPages := ans.Places * reviewPagesPerPlace
+			httpRequests += reviewPages
+			ans.BandwidthBytes += int64(reviewPages) * reviewPageBytes
+		}
+	}
+
+	if p.Email {
+		websitePages := int(math.Ceil(float64(ans.Places) * websiteRate * float64(emailPages)))
+		httpRequests += websitePages
+		ans.BandwidthBytes += int64(websitePages) * websitePageBytes
+	}
+
+	ans.Requests = browserPages + httpRequests
+	ans.Duration = (time.Duration(browserPages)*browserPageTime + time.Duration(httpRequests)*httpRequestTime) /
+		time.Duration(concurrency)
+
+	return ans, nil
+}
+
+// tilesPerQuery returns the map searches of a query: the tiles of its area in
+// fast mode, one search of the results feed otherwise
+func tilesPerQuery(p *Params) int {
+	if p.TilesPerQuery > 0 {
+		return p.TilesPerQuery
+	}
+
+	if !p.FastMode {
+		return 1
+	}
+
+	switch {
+	case p.Polygon != nil:
+		zoom := p.Zoom
+		if zoom <= 0 {
+			c := p.Polygon.Circle(zoom)
+			zoom = gmaps.ZoomForRadius(c.Lat, c.Radius, p.Viewport)
+		}
+
+		return max(len(p.Polygon.Tiles(zoom, p.Viewport)), 1)
+	case p.Area != nil:
+		area := *p.Area
+
+		area.ZoomLvl = float64(p.Zoom)
+		if p.Zoom <= 0 {
+			area.ZoomLvl = float64(gmaps.ZoomForRadius(area.Lat, area.Radius, p.Viewport))
+		}
+
+		return len(gmaps.TileArea(area, p.Viewport))
+	default:
+		return 1
+	}
+}
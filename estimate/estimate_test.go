@@ -0,0 +1,119 @@
+package estimate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/estimate"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_EstimateRun(t *testing.T) {
+	athens := gmaps.MapLocation{Lat: 37.98, Lon: 23.73, Radius: 10000}
+
+	tests := []struct {
+		name   string
+		params estimate.Params
+		tiles  int
+		places int
+		// requests is checked when it is not 0
+		requests int
+	}{
+		{
+			name:     "browser search",
+			params:   estimate.Params{Queries: 2, Depth: 5},
+			tiles:    2,
+			places:   100,
+			requests: 102,
+		},
+		{
+			name:   "browser depth capped",
+			params: estimate.Params{Queries: 1, Depth: 100},
+			tiles:  1,
+			places: 120,
+		},
+		{
+			name:     "fast mode without area",
+			params:   estimate.Params{Queries: 3, FastMode: true, Pages: 2},
+			tiles:    3,
+			places:   120,
+			requests: 6,
+		},
+		{
+			name:   "fast mode radius at the zoom covering it",
+			params: estimate.Params{Queries: 2, FastMode: true, Area: &athens},
+			tiles:  2,
+			places: 40,
+		},
+		{
+			name:   "fast mode radius tiled at the zoom",
+			params: estimate.Params{Queries: 2, FastMode: true, Area: &athens, Zoom: 15},
+			// 37 tiles cover 10 km at zoom 15
+			tiles:  2 * 37,
+			places: 2 * 37 * 20,
+		},
+		{
+			name:   "browser mode does not tile",
+			params: estimate.Params{Queries: 2, Depth: 1, Area: &athens, Zoom: 15},
+			tiles:  2,
+			places: 20,
+		},
+		{
+			name:   "tiles of a dry run",
+			params: estimate.Params{Queries: 2, FastMode: true, TilesPerQuery: 4},
+			tiles:  8,
+			places: 160,
+		},
+		{
+			name:   "places of a previous run",
+			params: estimate.Params{Queries: 2, FastMode: true, TilesPerQuery: 4, PlacesPerQuery: 50},
+			tiles:  8,
+			places: 100,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := estimate.EstimateRun(tc.params)
+			require.NoError(t, err)
+			require.Equal(t, tc.tiles, got.Tiles)
+			require.Equal(t, tc.places, got.Places)
+
+			if tc.requests > 0 {
+				require.Equal(t, tc.requests, got.Requests)
+			}
+
+			require.Positive(t, got.BandwidthBytes)
+			require.Positive(t, got.Duration)
+		})
+	}
+}
+
+func Test_EstimateRunPolygon(t *testing.T) {
+	polygon, err := gmaps.ParsePolygon([]byte(`{"type":"Polygon","coordinates":[[[23.70,37.95],[23.76,37.95],[23.76,38.01],[23.70,38.01],[23.70,37.95]]]}`))
+	require.NoError(t, err)
+
+	got, err := estimate.EstimateRun(estimate.Params{Queries: 1, FastMode: true, Polygon: polygon, Zoom: 16})
+	require.NoError(t, err)
+	require.Equal(t, len(polygon.Tiles(16, gmaps.Viewport{})), got.Tiles)
+	require.Greater(t, got.Tiles, 1)
+}
+
+func Test_EstimateRunConcurrency(t *testing.T) {
+	one, err := estimate.EstimateRun(estimate.Params{Queries: 4, Depth: 2, Email: true})
+	require.NoError(t, err)
+
+	four, err := estimate.EstimateRun(estimate.Params{Queries: 4, Depth: 2, Email: true, Concurrency: 4})
+	require.NoError(t, err)
+
+	require.Equal(t, one.Requests, four.Requests)
+	require.Equal(t, one.Duration/4, four.Duration)
+	require.Greater(t, one.Duration, time.Duration(0))
+}
+
+func Test_EstimateRunNoQueries(t *testing.T) {
+	_, err := estimate.EstimateRun(estimate.Params{})
+	require.ErrorIs(t, err, estimate.ErrNoQueries)
+}
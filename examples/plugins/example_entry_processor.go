@@ -0,0 +1,24 @@
+//go:build plugin
+// +build plugin
+
+package main
+
+import (
+	"context"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// LeadScorer tags the places with a phone, a website and a rating of 4 stars
+// or more as leads, and drops the permanently closed places.
+var LeadScorer gmaps.Processor = func(_ context.Context, entry *gmaps.Entry) (*gmaps.Entry, error) {
+	if entry.BusinessStatus == gmaps.BusinessPermanentlyClosed {
+		return nil, nil
+	}
+
+	if entry.Phone != "" && entry.WebSite != "" && entry.ReviewRating >= 4 {
+		entry.Tags = append(entry.Tags, "lead")
+	}
+
+	return entry, nil
+}
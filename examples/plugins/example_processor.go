@@ -0,0 +1,24 @@
+//go:build plugin
+// +build plugin
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/scrapemate"
+)
+
+// TitleCleaner trims and upper cases the titles of the entries
+// and drops the entries without a phone number.
+var TitleCleaner gmaps.AfterParseFunc = func(_ context.Context, _ scrapemate.IJob, entry *gmaps.Entry) error {
+	if entry.Phone == "" {
+		return gmaps.ErrSkipEntry
+	}
+
+	entry.Title = strings.ToUpper(strings.TrimSpace(entry.Title))
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package exiter
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// Tile is the outcome of the search of one area
+type Tile struct {
+	Query   string  `json:"query"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Zoom    int     `json:"zoom"`
+	Radius  float64 `json:"radius"`
+	Results int     `json:"results"`
+	// Error is why the search failed, empty when it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// bounds returns the south west and north east corners of the square around the tile's circle
+func (t *Tile) bounds() (minLon, minLat, maxLon, maxLat float64) {
+	const earthRadius = 6371000
+
+	dLat := t.Radius / earthRadius * 180 / math.Pi
+	dLon := dLat / math.Cos(t.Lat*math.Pi/180)
+
+	return t.Lon - dLon, t.Lat - dLat, t.Lon + dLon, t.Lat + dLat
+}
+
+// WriteCoverageGeoJSON writes the tiles as a FeatureCollection of squares with
+// the results and the error of each search as properties
+func WriteCoverageGeoJSON(w io.Writer, tiles []Tile) error {
+	type geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+
+	type feature struct {
+		Type       string   `json:"type"`
+		Geometry   geometry `json:"geometry"`
+		Properties Tile     `json:"properties"`
+	}
+
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{
+		Type:     "FeatureCollection",
+		Features: make([]feature, 0, len(tiles)),
+	}
+
+	for i := range tiles {
+		minLon, minLat, maxLon, maxLat := tiles[i].bounds()
+
+		collection.Features = append(collection.Features, feature{
+			Type: "Feature",
+			Geometry: geometry{
+				Type: "Polygon",
+				Coordinates: [][][2]float64{{
+					{minLon, minLat}, {maxLon, minLat}, {maxLon, maxLat}, {minLon, maxLat}, {minLon, minLat},
+				}},
+			},
+			Properties: tiles[i],
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(collection)
+}
+
+// WriteCoveragePNG draws the tiles as a heatmap: the more results the darker
+// the green, the failed tiles in red. The smaller tiles are drawn over the
+// bigger ones they were split from.
+func WriteCoveragePNG(w io.Writer, tiles []Tile) error {
+	const size = 1024
+
+	sorted := make([]Tile, len(tiles))
+	copy(sorted, tiles)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Radius > sorted[j].Radius
+	})
+
+	west, south, east, north := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	maxResults := 0
+
+	for i := range sorted {
+		minLon, minLat, maxLon, maxLat := sorted[i].bounds()
+
+		west, south = min(west, minLon), min(south, minLat)
+		east, north = max(east, maxLon), max(north, maxLat)
+		maxResults = max(maxResults, sorted[i].Results)
+	}
+
+	width, height := size, size
+
+	if !(east > west && north > south) {
+		// no tiles or no radius, nothing to draw
+		sorted = nil
+	} else {
+		// keep the aspect ratio of the area, the longitudes shrink with the latitude
+		ratio := (east - west) * math.Cos((north+south)/2*math.Pi/180) / (north - south)
+		if ratio > 1 {
+			height = max(1, int(size/ratio))
+		} else {
+			width = max(1, int(size*ratio))
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for i := range sorted {
+		minLon, minLat, maxLon, maxLat := sorted[i].bounds()
+
+		rect := image.Rect(
+			int((minLon-west)/(east-west)*float64(width)),
+			int((north-maxLat)/(north-south)*float64(height)),
+			int(math.Ceil((maxLon-west)/(east-west)*float64(width))),
+			int(math.Ceil((north-minLat)/(north-south)*float64(height))),
+		)
+
+		fill := tileColor(&sorted[i], maxResults)
+
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				// a one pixel border keeps the neighbouring tiles apart
+				if x == rect.Min.X || y == rect.Min.Y {
+					img.Set(x, y, color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff})
+				} else {
+					img.Set(x, y, fill)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func tileColor(t *Tile, maxResults int) color.RGBA {
+	if t.Error != "" {
+		return color.RGBA{R: 0xd7, G: 0x30, B: 0x27, A: 0xff}
+	}
+
+	if t.Results == 0 || maxResults == 0 {
+		return color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	}
+
+	// log scale, a handful of results in a tile is already visible
+	v := math.Log1p(float64(t.Results)) / math.Log1p(float64(maxResults))
+
+	return color.RGBA{
+		R: uint8(0xe5 - v*0xe0),
+		G: uint8(0xf5 - v*0x8a),
+		B: uint8(0xe0 - v*0xd8),
+		A: 0xff,
+	}
+}
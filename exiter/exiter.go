@@ -2,24 +2,95 @@ package exiter
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"math"
 	"sync"
 	"time"
 )
 
 type Exiter interface {
 	SetSeedCount(int)
+	IncrSeedCount(int)
 	SetCancelFunc(context.CancelFunc)
 	IncrSeedCompleted(int)
 	IncrPlacesFound(int)
 	IncrPlacesCompleted(int)
+	IncrParseWarnings(int)
+	ParseWarnings() int
+	IncrErrors(int)
+	SetMaxResults(perRun, perQuery int)
+	MaxResults() (perRun, perQuery int)
+	AddResults(query string, n int) int
+	MaxResultsReached(query string) bool
+	RecordTile(Tile)
+	Tiles() []Tile
+	Progress() Progress
+	AddQuery(query string)
+	RecordQuery(query string, places int)
+	AddPlaces(query string, keys []string) int
+	RecordFailure(query string, class ErrorClass)
+	Summary() Summary
 	Run(context.Context)
 }
 
+// rateWindow is the time the rates of the progress are measured over
+const rateWindow = 5 * time.Minute
+
+// Progress is a snapshot of the counters of a run, with the rates of the
+// last minutes and the estimated time left
+type Progress struct {
+	SeedCount       int `json:"seed_count"`
+	SeedCompleted   int `json:"seed_completed"`
+	PlacesFound     int `json:"places_found"`
+	PlacesCompleted int `json:"places_completed"`
+	ParseWarnings   int `json:"parse_warnings"`
+	Errors          int `json:"errors"`
+
+	StartedAt       time.Time `json:"started_at"`
+	SeedsPerMinute  float64   `json:"seeds_per_minute"`
+	PlacesPerMinute float64   `json:"places_per_minute"`
+	// ETASeconds is the estimated time left to complete the remaining seeds
+	// and places at the current rates, 0 when it is unknown or the run is done
+	ETASeconds int64 `json:"eta_seconds,omitempty"`
+}
+
+// ETA returns the estimated time left, 0 when it is unknown
+func (p Progress) ETA() time.Duration {
+	return time.Duration(p.ETASeconds) * time.Second
+}
+
+// progressSample are the completed counters at a time, the rates are measured
+// from the oldest sample of rateWindow
+type progressSample struct {
+	at     time.Time
+	seeds  int
+	places int
+}
+
 type exiter struct {
 	seedCount       int
 	seedCompleted   int
 	placesFound     int
 	placesCompleted int
+	parseWarnings   int
+	errors          int
+	tiles           []Tile
+	maxResults      int
+	maxQueryResults int
+	results         int
+	queryResults    map[string]int
+	startedAt       time.Time
+	samples         []progressSample
+	// queries are the stats of the seed queries in queryOrder, failures the
+	// failed jobs by error class
+	queries    map[string]*QueryStats
+	queryOrder []string
+	failures   map[ErrorClass]int
+	// places are the keys of the places found by all the queries of the run,
+	// placesRaw counts them once per search that found them
+	places    map[string]struct{}
+	placesRaw int
 
 	mu         *sync.Mutex
 	cancelFunc context.CancelFunc
@@ -27,7 +98,8 @@ type exiter struct {
 
 func New() Exiter {
 	return &exiter{
-		mu: &sync.Mutex{},
+		startedAt: time.Now(),
+		mu:        &sync.Mutex{},
 	}
 }
 
@@ -38,6 +110,14 @@ func (e *exiter) SetSeedCount(val int) {
 	e.seedCount = val
 }
 
+// IncrSeedCount adds seeds created during the run, e.g. the subdivided search tiles
+func (e *exiter) IncrSeedCount(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seedCount += val
+}
+
 func (e *exiter) SetCancelFunc(fn context.CancelFunc) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -66,6 +146,177 @@ func (e *exiter) IncrPlacesCompleted(val int) {
 	e.placesCompleted += val
 }
 
+func (e *exiter) IncrParseWarnings(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.parseWarnings += val
+}
+
+// ParseWarnings returns the number of search results that could not be parsed
+func (e *exiter) ParseWarnings() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.parseWarnings
+}
+
+// IncrErrors adds the jobs that failed after all their retries
+func (e *exiter) IncrErrors(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errors += val
+}
+
+// SetMaxResults sets the maximum number of places of the run and of every
+// query, 0 is no limit
+func (e *exiter) SetMaxResults(perRun, perQuery int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maxResults = perRun
+	e.maxQueryResults = perQuery
+}
+
+// MaxResults returns the limits set with SetMaxResults
+func (e *exiter) MaxResults() (perRun, perQuery int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.maxResults, e.maxQueryResults
+}
+
+// AddResults adds up to n places of query to the results and returns how many
+// are kept within the limits of the run and of the query
+func (e *exiter) AddResults(query string, n int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.maxResults > 0 {
+		n = min(n, e.maxResults-e.results)
+	}
+
+	if e.maxQueryResults > 0 {
+		n = min(n, e.maxQueryResults-e.queryResults[query])
+	}
+
+	n = max(n, 0)
+
+	if e.queryResults == nil {
+		e.queryResults = make(map[string]int)
+	}
+
+	e.results += n
+	e.queryResults[query] += n
+
+	return n
+}
+
+// MaxResultsReached reports whether the run or query have all their places,
+// the searches of query are not needed anymore
+func (e *exiter) MaxResultsReached(query string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.maxResults > 0 && e.results >= e.maxResults {
+		return true
+	}
+
+	return e.maxQueryResults > 0 && e.queryResults[query] >= e.maxQueryResults
+}
+
+// RecordTile records the outcome of the search of a tile for the coverage export
+func (e *exiter) RecordTile(t Tile) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.tiles = append(e.tiles, t)
+}
+
+// Tiles returns the tiles searched so far
+func (e *exiter) Tiles() []Tile {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ans := make([]Tile, len(e.tiles))
+	copy(ans, e.tiles)
+
+	return ans
+}
+
+// Progress returns the counters of the run so far, the rates since the start
+// of the run until Run samples them
+func (e *exiter) Progress() Progress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ans := Progress{
+		SeedCount:       e.seedCount,
+		SeedCompleted:   e.seedCompleted,
+		PlacesFound:     e.placesFound,
+		PlacesCompleted: e.placesCompleted,
+		ParseWarnings:   e.parseWarnings,
+		Errors:          e.errors,
+		StartedAt:       e.startedAt,
+	}
+
+	now := time.Now()
+	from := progressSample{at: e.startedAt}
+
+	for _, s := range e.samples {
+		if now.Sub(s.at) <= rateWindow {
+			from = s
+
+			break
+		}
+	}
+
+	minutes := now.Sub(from.at).Minutes()
+	if minutes <= 0 {
+		return ans
+	}
+
+	ans.SeedsPerMinute = float64(e.seedCompleted-from.seeds) / minutes
+	ans.PlacesPerMinute = float64(e.placesCompleted-from.places) / minutes
+
+	// the run is done once both the seeds and the places are
+	var eta float64
+
+	if left := e.seedCount - e.seedCompleted; left > 0 {
+		if ans.SeedsPerMinute <= 0 {
+			return ans
+		}
+
+		eta = float64(left) / ans.SeedsPerMinute
+	}
+
+	if left := e.placesFound - e.placesCompleted; left > 0 {
+		if ans.PlacesPerMinute <= 0 {
+			return ans
+		}
+
+		eta = max(eta, float64(left)/ans.PlacesPerMinute)
+	}
+
+	ans.ETASeconds = int64(math.Ceil(eta * 60))
+
+	return ans
+}
+
+// sample records the completed counters for the rates of Progress and drops
+// the samples older than rateWindow
+func (e *exiter) sample(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.samples = append(e.samples, progressSample{at: now, seeds: e.seedCompleted, places: e.placesCompleted})
+
+	for len(e.samples) > 1 && now.Sub(e.samples[0].at) > rateWindow {
+		e.samples = e.samples[1:]
+	}
+}
+
 func (e *exiter) Run(ctx context.Context) {
 	ticker := time.NewTicker(time.Second * 5)
 	defer ticker.Stop()
@@ -74,7 +325,9 @@ func (e *exiter) Run(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case now := <-ticker.C:
+			e.sample(now)
+
 			if e.isDone() {
 				e.cancelFunc()
 
@@ -98,3 +351,31 @@ func (e *exiter) isDone() bool {
 
 	return true
 }
+
+// Report logs the progress of e every interval until ctx is done
+func Report(ctx context.Context, e Exiter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p := e.Progress()
+
+			args := []any{
+				"seeds", fmt.Sprintf("%d/%d", p.SeedCompleted, p.SeedCount),
+				"places", fmt.Sprintf("%d/%d", p.PlacesCompleted, p.PlacesFound),
+				"seeds_per_min", math.Round(p.SeedsPerMinute*10) / 10,
+				"places_per_min", math.Round(p.PlacesPerMinute*10) / 10,
+			}
+
+			if eta := p.ETA(); eta > 0 {
+				args = append(args, "eta", eta.String())
+			}
+
+			slog.Info("progress", args...)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package exiter
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// ErrorClass is the category of the error of a failed job
+type ErrorClass string
+
+const (
+	// ErrorParse is a response that could not be parsed
+	ErrorParse ErrorClass = "parse_error"
+	// ErrorEmptyBody is a response without a body
+	ErrorEmptyBody ErrorClass = "empty_body"
+	// ErrorBlocked is a request refused by Google, with the status 403 or 429
+	// or with the consent or the captcha page
+	ErrorBlocked ErrorClass = "blocked"
+	// ErrorTimeout is a request or a page that timed out
+	ErrorTimeout ErrorClass = "timeout"
+	// ErrorOther is any other error
+	ErrorOther ErrorClass = "other"
+)
+
+// QueryStats are the outcome of the jobs of a seed query
+type QueryStats struct {
+	Query string `json:"query"`
+	// Searches is the number of the searches of the query completed, its
+	// tiles and its pages
+	Searches int `json:"searches"`
+	// Places is the number of the places found by the searches
+	Places int `json:"places"`
+	// Unique is the number of the places of the query not found by the other
+	// queries before
+	Unique   int                `json:"unique"`
+	Failures map[ErrorClass]int `json:"failures,omitempty"`
+}
+
+// Failed returns the number of the failed jobs of the query
+func (q *QueryStats) Failed() int {
+	ans := 0
+	for _, n := range q.Failures {
+		ans += n
+	}
+
+	return ans
+}
+
+// Summary is the breakdown of a run by seed query and by error class
+type Summary struct {
+	// Queries are in the order they were added
+	Queries  []QueryStats       `json:"queries"`
+	Failures map[ErrorClass]int `json:"failures"`
+	// PlacesRaw is the number of the places found by every search, a place
+	// found by several searches is counted by each of them. PlacesUnique is
+	// the number of the different places.
+	PlacesRaw    int `json:"places_raw"`
+	PlacesUnique int `json:"places_unique"`
+}
+
+// Duplicates returns the number of the places found again by other searches
+func (s *Summary) Duplicates() int {
+	return s.PlacesRaw - s.PlacesUnique
+}
+
+// Empty returns the queries that found no place
+func (s *Summary) Empty() []QueryStats {
+	var ans []QueryStats
+
+	for i := range s.Queries {
+		if s.Queries[i].Places == 0 {
+			ans = append(ans, s.Queries[i])
+		}
+	}
+
+	return ans
+}
+
+// AddQuery adds a seed query to the summary, it is reported even when none of
+// its jobs complete
+func (e *exiter) AddQuery(query string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.query(query)
+}
+
+// RecordQuery records a completed search of query that found places
+func (e *exiter) RecordQuery(query string, places int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	q := e.query(query)
+	q.Searches++
+	q.Places += places
+}
+
+// AddPlaces records the places found by a search of query, by their keys, and
+// returns the number of the places not found by the run before. A place is
+// counted once in the totals of the run whatever the number of the queries,
+// the tiles and the pages that find it.
+func (e *exiter) AddPlaces(query string, keys []string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.places == nil {
+		e.places = make(map[string]struct{})
+	}
+
+	unique := 0
+
+	for _, key := range keys {
+		if _, ok := e.places[key]; ok {
+			continue
+		}
+
+		e.places[key] = struct{}{}
+		unique++
+	}
+
+	e.placesRaw += len(keys)
+	e.query(query).Unique += unique
+
+	return unique
+}
+
+// RecordFailure records a failed job of query, an empty query for the jobs
+// that are not part of a search
+func (e *exiter) RecordFailure(query string, class ErrorClass) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.failures == nil {
+		e.failures = make(map[ErrorClass]int)
+	}
+
+	e.failures[class]++
+
+	if query == "" {
+		return
+	}
+
+	q := e.query(query)
+	if q.Failures == nil {
+		q.Failures = make(map[ErrorClass]int)
+	}
+
+	q.Failures[class]++
+}
+
+// query returns the stats of query, e.mu must be held
+func (e *exiter) query(query string) *QueryStats {
+	if q, ok := e.queries[query]; ok {
+		return q
+	}
+
+	if e.queries == nil {
+		e.queries = make(map[string]*QueryStats)
+	}
+
+	q := &QueryStats{Query: query}
+	e.queries[query] = q
+	e.queryOrder = append(e.queryOrder, query)
+
+	return q
+}
+
+// Summary returns the breakdown of the run so far
+func (e *exiter) Summary() Summary {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ans := Summary{
+		Queries:      make([]QueryStats, 0, len(e.queryOrder)),
+		Failures:     maps.Clone(e.failures),
+		PlacesRaw:    e.placesRaw,
+		PlacesUnique: len(e.places),
+	}
+
+	for _, query := range e.queryOrder {
+		q := *e.queries[query]
+		q.Failures = maps.Clone(q.Failures)
+
+		ans.Queries = append(ans.Queries, q)
+	}
+
+	return ans
+}
+
+// LogSummary logs the failed jobs by error class, and the queries that found
+// no place with the classes of their failures
+func LogSummary(s Summary) {
+	for _, q := range s.Empty() {
+		args := []any{"query", q.Query, "searches", q.Searches, "failed", q.Failed()}
+
+		for _, class := range slices.Sorted(maps.Keys(q.Failures)) {
+			args = append(args, string(class), q.Failures[class])
+		}
+
+		slog.Warn("query found no places", args...)
+	}
+
+	args := []any{
+		"queries", len(s.Queries), "empty_queries", len(s.Empty()),
+		"places", s.PlacesUnique, "places_raw", s.PlacesRaw, "duplicates", s.Duplicates(),
+	}
+
+	for _, class := range slices.Sorted(maps.Keys(s.Failures)) {
+		args = append(args, string(class), s.Failures[class])
+	}
+
+	slog.Info("run summary", args...)
+}
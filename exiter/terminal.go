@@ -0,0 +1,148 @@
+package exiter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+const (
+	// terminalInterval is the time between two renders of the progress panel
+	terminalInterval = time.Second
+	// barWidth is the number of cells of the progress bar of the seeds
+	barWidth = 24
+)
+
+// Terminal renders the progress of a run as a panel of lines at the bottom of
+// a terminal, redrawn in place. The logs are written through it so that they
+// scroll above the panel instead of overwriting it.
+type Terminal struct {
+	mu    sync.Mutex
+	out   *os.File
+	panel []string
+}
+
+var _ io.Writer = (*Terminal)(nil)
+
+// IsTerminal reports whether f is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// NewTerminal returns the terminal of out, see IsTerminal
+func NewTerminal(out *os.File) *Terminal {
+	return &Terminal{out: out}
+}
+
+// Write writes p above the panel
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clear()
+
+	n, err := t.out.Write(p)
+
+	t.draw()
+
+	return n, err
+}
+
+// Show renders the progress of e until stop is called, stop renders it a
+// last time and leaves it above the next logs
+func (t *Terminal) Show(e Exiter) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(terminalInterval)
+		defer ticker.Stop()
+
+		for {
+			t.render(e.Progress())
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+
+			t.render(e.Progress())
+
+			t.mu.Lock()
+			t.panel = nil
+			t.mu.Unlock()
+		})
+	}
+}
+
+func (t *Terminal) render(p Progress) {
+	lines := progressLines(p, time.Now())
+
+	width, _, err := term.GetSize(int(t.out.Fd()))
+	if err == nil && width > 1 {
+		for i := range lines {
+			lines[i] = runewidth.Truncate(lines[i], width-1, "")
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clear()
+	t.panel = lines
+	t.draw()
+}
+
+// clear erases the panel and moves the cursor to its first line
+func (t *Terminal) clear() {
+	if len(t.panel) > 0 {
+		fmt.Fprintf(t.out, "\x1b[%dF\x1b[J", len(t.panel))
+	}
+}
+
+func (t *Terminal) draw() {
+	for _, line := range t.panel {
+		fmt.Fprintln(t.out, line)
+	}
+}
+
+// progressLines returns the lines of the panel of p at now
+func progressLines(p Progress, now time.Time) []string {
+	done := 0.0
+	if p.SeedCount > 0 {
+		done = float64(p.SeedCompleted) / float64(p.SeedCount)
+	}
+
+	filled := min(int(done*barWidth), barWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	eta := "unknown"
+	if d := p.ETA(); d > 0 {
+		eta = d.String()
+	}
+
+	return []string{
+		fmt.Sprintf("Seeds   %s %d/%d %5.1f%%  %.1f/min", bar, p.SeedCompleted, p.SeedCount, done*100, p.SeedsPerMinute),
+		fmt.Sprintf("Places  %d found, %d completed  %.1f/min", p.PlacesFound, p.PlacesCompleted, p.PlacesPerMinute),
+		fmt.Sprintf("Errors  %d failed jobs, %d results skipped", p.Errors, p.ParseWarnings),
+		fmt.Sprintf("ETA     %s  (elapsed %s)", eta, now.Sub(p.StartedAt).Round(time.Second)),
+	}
+}
@@ -0,0 +1,173 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// The defaults of AdaptiveConfig
+const (
+	DefaultAdaptiveWindow    = 10
+	DefaultAdaptiveThreshold = 0.1
+)
+
+// AdaptiveConfig configures the adaptive concurrency
+type AdaptiveConfig struct {
+	// Min and Max bound the number of the requests to Google in flight, Max
+	// is the number of the workers
+	Min int
+	Max int
+	// Window is the number of the responses between two adjustments
+	Window int
+	// Threshold is the ratio (0-1) of the failed or blocked responses of a
+	// window above which the concurrency is halved
+	Threshold float64
+}
+
+var _ scrapemate.HTTPFetcher = (*adaptive)(nil)
+
+type adaptive struct {
+	next scrapemate.HTTPFetcher
+	cfg  AdaptiveConfig
+
+	mu       sync.Mutex
+	limit    int
+	inflight int
+	// released is closed when a request is done, the waiting requests then
+	// try to take its place
+	released chan struct{}
+	count    int
+	failed   int
+}
+
+// NewAdaptive returns an HTTPFetcher that adapts the number of the requests
+// to Google in flight to their error rate. It starts at a quarter of
+// cfg.Max, adds a request after every window of cfg.Window responses whose
+// ratio of failed or blocked responses (errors and timeouts, 429s, captcha and
+// consent pages) stays below half of cfg.Threshold, and halves them after a
+// window above cfg.Threshold. The requests over the limit wait for a request
+// to be done. The other requests, e.g. to the websites of the places, are not
+// limited.
+func NewAdaptive(next scrapemate.HTTPFetcher, cfg AdaptiveConfig) scrapemate.HTTPFetcher {
+	cfg.Max = max(cfg.Max, 1)
+	cfg.Min = min(max(cfg.Min, 1), cfg.Max)
+
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultAdaptiveWindow
+	}
+
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultAdaptiveThreshold
+	}
+
+	return &adaptive{
+		next:     next,
+		cfg:      cfg,
+		limit:    max(cfg.Min, cfg.Max/4),
+		released: make(chan struct{}),
+	}
+}
+
+func (a *adaptive) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if !isGoogle(job.GetURL()) {
+		return a.next.Fetch(ctx, job)
+	}
+
+	if err := a.acquire(ctx); err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	resp := a.next.Fetch(ctx, job)
+
+	// the context was canceled, this says nothing about the error rate
+	a.release(ctx.Err() == nil, resp.Error != nil || IsBlocked(&resp) || Interstitial(&resp) == InterstitialConsent)
+
+	return resp
+}
+
+func (a *adaptive) Close() error {
+	return a.next.Close()
+}
+
+// acquire waits for the number of the requests in flight to be under the
+// limit
+func (a *adaptive) acquire(ctx context.Context) error {
+	for {
+		a.mu.Lock()
+
+		if a.inflight < a.limit {
+			a.inflight++
+			a.mu.Unlock()
+
+			return nil
+		}
+
+		released := a.released
+
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// release records the response of a request when counted and wakes up the
+// waiting requests
+func (a *adaptive) release(counted, failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.inflight--
+
+	close(a.released)
+	a.released = make(chan struct{})
+
+	if !counted {
+		return
+	}
+
+	a.count++
+
+	if failed {
+		a.failed++
+	}
+
+	if a.count < a.cfg.Window {
+		return
+	}
+
+	ratio := float64(a.failed) / float64(a.count)
+	a.count, a.failed = 0, 0
+
+	switch {
+	case ratio > a.cfg.Threshold && a.limit > a.cfg.Min:
+		a.limit = max(a.limit/2, a.cfg.Min)
+
+		slog.Warn("adaptive concurrency: backing off", "failed_ratio", ratio, "concurrency", a.limit)
+	case ratio <= a.cfg.Threshold/2 && a.limit < a.cfg.Max:
+		a.limit++
+
+		slog.Debug("adaptive concurrency: ramping up", "failed_ratio", ratio, "concurrency", a.limit)
+	}
+}
+
+// isGoogle reports whether the request goes to Google, the requests to
+// the other hosts say nothing about the blocking of the scraper
+func isGoogle(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+
+	return host == "google.com" || strings.HasPrefix(host, "google.") || strings.HasSuffix(host, ".google.com")
+}
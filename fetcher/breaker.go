@@ -0,0 +1,144 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// BreakerConfig configures the circuit breaker
+type BreakerConfig struct {
+	// Threshold is the ratio (0-1) of failed responses that trips the breaker
+	Threshold float64
+	// Window is the number of most recent responses used to compute the ratio
+	Window int
+	// Cooldown is how long the requests pause when the breaker trips
+	Cooldown time.Duration
+}
+
+var _ scrapemate.HTTPFetcher = (*breaker)(nil)
+
+type breaker struct {
+	next scrapemate.HTTPFetcher
+	cfg  BreakerConfig
+
+	mu        sync.Mutex
+	results   []bool // ring buffer, true means failed
+	pos       int
+	count     int
+	failed    int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker returns an HTTPFetcher that tracks the ratio of failed
+// or blocked responses of the last cfg.Window requests. When it exceeds cfg.Threshold
+// all the requests pause for cfg.Cooldown, instead of burning proxies on a blocked session.
+func NewCircuitBreaker(next scrapemate.HTTPFetcher, cfg BreakerConfig) scrapemate.HTTPFetcher {
+	const (
+		defaultWindow   = 50
+		defaultCooldown = 5 * time.Minute
+	)
+
+	if cfg.Window <= 0 {
+		cfg.Window = defaultWindow
+	}
+
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultCooldown
+	}
+
+	return &breaker{
+		next:    next,
+		cfg:     cfg,
+		results: make([]bool, cfg.Window),
+	}
+}
+
+func (b *breaker) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if wait := b.waitTime(); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return scrapemate.Response{Error: ctx.Err()}
+		case <-time.After(wait):
+		}
+	}
+
+	resp := b.next.Fetch(ctx, job)
+
+	// the context was canceled, this says nothing about the health of the session
+	if ctx.Err() != nil {
+		return resp
+	}
+
+	b.record(resp.Error != nil || IsBlocked(&resp))
+
+	return resp
+}
+
+func (b *breaker) Close() error {
+	return b.next.Close()
+}
+
+func (b *breaker) waitTime() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return time.Until(b.openUntil)
+}
+
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.count == len(b.results) {
+		if b.results[b.pos] {
+			b.failed--
+		}
+	} else {
+		b.count++
+	}
+
+	b.results[b.pos] = failed
+	b.pos = (b.pos + 1) % len(b.results)
+
+	if failed {
+		b.failed++
+	}
+
+	// wait for a full window before deciding
+	if b.count < len(b.results) {
+		return
+	}
+
+	ratio := float64(b.failed) / float64(b.count)
+	if ratio <= b.cfg.Threshold {
+		return
+	}
+
+	b.openUntil = time.Now().Add(b.cfg.Cooldown)
+
+	slog.Warn("circuit breaker open: pausing the requests", "failed_ratio", ratio, "responses", b.count, "cooldown", b.cfg.Cooldown)
+
+	// start again with a clean window after the cooldown
+	clear(b.results)
+	b.pos, b.count, b.failed = 0, 0, 0
+}
+
+// IsBlocked reports whether the response looks like Google blocked the client:
+// rate limited, forbidden, server errors or the captcha page.
+func IsBlocked(resp *scrapemate.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode >= http.StatusInternalServerError:
+		return true
+	case Interstitial(resp) == InterstitialCaptcha:
+		return true
+	default:
+		return false
+	}
+}
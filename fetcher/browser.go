@@ -0,0 +1,67 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// BrowserJob is implemented by the jobs rendered by a browser, their requests
+// are sent by the browser fetcher of NewBrowserFallback
+type BrowserJob interface {
+	UseBrowser() bool
+}
+
+var _ scrapemate.HTTPFetcher = (*browserFallback)(nil)
+
+type browserFallback struct {
+	fetcher    scrapemate.HTTPFetcher
+	newBrowser func() (scrapemate.HTTPFetcher, error)
+
+	once    sync.Once
+	browser scrapemate.HTTPFetcher
+	err     error
+}
+
+// NewBrowserFallback returns an HTTPFetcher that sends the requests of the
+// BrowserJobs with the fetcher returned by newBrowser, and the other requests
+// with f. The browser fetcher is created by the first BrowserJob, the runs
+// without one never start a browser.
+func NewBrowserFallback(f scrapemate.HTTPFetcher, newBrowser func() (scrapemate.HTTPFetcher, error)) scrapemate.HTTPFetcher {
+	return &browserFallback{
+		fetcher:    f,
+		newBrowser: newBrowser,
+	}
+}
+
+func (b *browserFallback) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if bj, ok := job.(BrowserJob); !ok || !bj.UseBrowser() {
+		return b.fetcher.Fetch(ctx, job)
+	}
+
+	b.once.Do(func() {
+		b.browser, b.err = b.newBrowser()
+	})
+
+	if b.err != nil {
+		return scrapemate.Response{Error: fmt.Errorf("cannot start the browser: %w", b.err)}
+	}
+
+	return b.browser.Fetch(ctx, job)
+}
+
+func (b *browserFallback) Close() error {
+	err := b.fetcher.Close()
+
+	// the browser is not started after Close
+	b.once.Do(func() {})
+
+	if b.browser != nil {
+		err = errors.Join(err, b.browser.Close())
+	}
+
+	return err
+}
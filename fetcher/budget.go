@@ -0,0 +1,40 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/budget"
+)
+
+var _ scrapemate.HTTPFetcher = (*budgeted)(nil)
+
+type budgeted struct {
+	next    scrapemate.HTTPFetcher
+	tracker *budget.Tracker
+	kind    func(scrapemate.IJob) budget.Kind
+}
+
+// NewBudgeted returns an HTTPFetcher that counts every request with the
+// tracker, by the kind of its job, before sending it with next. The requests
+// refused by the tracker are not sent and fail with budget.ErrExceeded.
+func NewBudgeted(next scrapemate.HTTPFetcher, tracker *budget.Tracker, kind func(scrapemate.IJob) budget.Kind) scrapemate.HTTPFetcher {
+	return &budgeted{
+		next:    next,
+		tracker: tracker,
+		kind:    kind,
+	}
+}
+
+func (f *budgeted) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if err := f.tracker.Allow(f.kind(job)); err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	return f.next.Fetch(ctx, job)
+}
+
+func (f *budgeted) Close() error {
+	return f.next.Close()
+}
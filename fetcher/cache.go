@@ -0,0 +1,166 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// CachedJob is implemented by the jobs whose url changes between the runs,
+// e.g. with a random request id: the response is cached for CacheURL
+type CachedJob interface {
+	CacheURL() string
+}
+
+// cached is a response of the cache and the time it was fetched
+type cached struct {
+	FetchedAt time.Time
+	Response  fixture
+}
+
+var _ scrapemate.HTTPFetcher = (*cache)(nil)
+
+type cache struct {
+	next scrapemate.HTTPFetcher
+	dir  string
+	ttl  time.Duration
+}
+
+// NewCache returns an HTTPFetcher that serves the requests from the responses
+// of next saved in dir for ttl, the requests are sent again after it. Only the
+// successful responses are saved, not the errors, the consent or the captcha
+// pages. Cookies and authorization headers are not saved.
+func NewCache(next scrapemate.HTTPFetcher, dir string, ttl time.Duration) (scrapemate.HTTPFetcher, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &cache{next: next, dir: dir, ttl: ttl}, nil
+}
+
+func (c *cache) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	path := filepath.Join(c.dir, CacheKey(job)+".gob")
+
+	if resp, ok := c.get(path); ok {
+		scrapemate.GetLoggerFromContext(ctx).Debug("response served from the cache", "url", job.GetFullURL())
+
+		return resp
+	}
+
+	resp := c.next.Fetch(ctx, job)
+
+	if resp.Error != nil || resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices || IsBlocked(&resp) || Interstitial(&resp) != "" {
+		return resp
+	}
+
+	if err := c.set(path, &resp); err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("cannot cache the response", "url", job.GetFullURL(), "error", err)
+	}
+
+	return resp
+}
+
+func (c *cache) Close() error {
+	return c.next.Close()
+}
+
+func (c *cache) get(path string) (scrapemate.Response, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scrapemate.Response{}, false
+	}
+
+	var entry cached
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return scrapemate.Response{}, false
+	}
+
+	if time.Since(entry.FetchedAt) > c.ttl {
+		return scrapemate.Response{}, false
+	}
+
+	return scrapemate.Response{
+		URL:        entry.Response.URL,
+		StatusCode: entry.Response.StatusCode,
+		Headers:    entry.Response.Headers,
+		Body:       entry.Response.Body,
+		Meta:       entry.Response.Meta,
+	}, true
+}
+
+func (c *cache) set(path string, resp *scrapemate.Response) error {
+	entry := cached{
+		FetchedAt: time.Now(),
+		Response: fixture{
+			URL:        resp.URL,
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Headers.Clone(),
+			Body:       resp.Body,
+			Meta:       resp.Meta,
+		},
+	}
+
+	for _, h := range sensitiveHeaders {
+		entry.Response.Headers.Del(h)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(&entry); err != nil {
+		return err
+	}
+
+	// the entry is renamed once written, the workers never read a part of it
+	f, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// CacheKey returns the key of the cached response of the job: the method,
+// the url with its scheme and its host in lower case and its query parameters
+// sorted, and the body
+func CacheKey(job scrapemate.IJob) string {
+	raw := job.GetFullURL()
+	if cj, ok := job.(CachedJob); ok {
+		raw = cj.CacheURL()
+	}
+
+	if u, err := url.Parse(raw); err == nil {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		u.RawQuery = u.Query().Encode()
+		u.Fragment = ""
+		raw = u.String()
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", job.GetMethod(), raw)
+	h.Write(job.GetBody())
+
+	return hex.EncodeToString(h.Sum(nil))
+}
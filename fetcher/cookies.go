@@ -0,0 +1,188 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	fhttp "github.com/Noooste/fhttp"
+	"github.com/Noooste/fhttp/cookiejar"
+)
+
+// consentURL is the url the consent cookie of the new jars is set for
+const consentURL = "https://www.google.com/"
+
+// CookieJars are the cookie jars of the proxies: the requests sent through a
+// proxy, or through a session of a rotating proxy provider, share its cookies
+// like the requests of a browser do. The jars can be saved to a file and
+// loaded by the next runs.
+type CookieJars struct {
+	mu   sync.Mutex
+	jars map[string]*savingJar
+}
+
+// NewCookieJars returns empty cookie jars
+func NewCookieJars() *CookieJars {
+	return &CookieJars{jars: make(map[string]*savingJar)}
+}
+
+// LoadCookieJars returns the cookie jars saved to path by Save, they are empty
+// when the file does not exist
+func LoadCookieJars(path string) (*CookieJars, error) {
+	c := NewCookieJars()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var saved map[string][]savedCookie
+
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return nil, fmt.Errorf("invalid cookies file %s: %w", path, err)
+	}
+
+	for key, cookies := range saved {
+		j := c.jar(key)
+
+		for _, sc := range cookies {
+			u, err := url.Parse(sc.URL)
+			if err != nil {
+				continue
+			}
+
+			j.SetCookies(u, []*fhttp.Cookie{sc.Cookie})
+		}
+	}
+
+	return c, nil
+}
+
+// jar returns the cookie jar of key, the proxy of the requests. A new jar
+// has the consent cookie of Google, the cookie consent is rejected.
+func (c *CookieJars) jar(key string) *savingJar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if j, ok := c.jars[key]; ok {
+		return j
+	}
+
+	// cookiejar.New fails only with invalid options
+	cj, _ := cookiejar.New(nil)
+
+	j := &savingJar{jar: cj, cookies: make(map[string]savedCookie)}
+
+	u, _ := url.Parse(consentURL)
+	j.SetCookies(u, []*fhttp.Cookie{{
+		Name:   ConsentCookieName,
+		Value:  ConsentCookieValue,
+		Domain: ".google.com",
+		Path:   "/",
+	}})
+
+	c.jars[key] = j
+
+	return j
+}
+
+// Save writes the cookies of the jars that are not expired to path
+func (c *CookieJars) Save(path string) error {
+	c.mu.Lock()
+
+	saved := make(map[string][]savedCookie, len(c.jars))
+
+	for key, j := range c.jars {
+		saved[key] = j.saved(time.Now())
+	}
+
+	c.mu.Unlock()
+
+	b, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
+
+// savedCookie is a cookie of the jars file and the url it was set for
+type savedCookie struct {
+	URL    string        `json:"url"`
+	Cookie *fhttp.Cookie `json:"cookie"`
+}
+
+// savingJar is a cookie jar keeping the cookies set, so that they can be saved
+type savingJar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	cookies map[string]savedCookie
+}
+
+func (j *savingJar) SetCookies(u *url.URL, cookies []*fhttp.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, c := range cookies {
+		sc := *c
+
+		// the max age is relative to the time it is set
+		if sc.MaxAge > 0 {
+			sc.Expires = now.Add(time.Duration(sc.MaxAge) * time.Second)
+			sc.MaxAge = 0
+		}
+
+		domain := sc.Domain
+		if domain == "" {
+			domain = u.Hostname()
+		}
+
+		key := domain + ";" + sc.Path + ";" + sc.Name
+
+		if sc.MaxAge < 0 {
+			delete(j.cookies, key)
+
+			continue
+		}
+
+		j.cookies[key] = savedCookie{
+			URL:    u.Scheme + "://" + u.Host + "/",
+			Cookie: &sc,
+		}
+	}
+}
+
+func (j *savingJar) Cookies(u *url.URL) []*fhttp.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// saved returns the cookies of the jar not expired at now
+func (j *savingJar) saved(now time.Time) []savedCookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ans := make([]savedCookie, 0, len(j.cookies))
+
+	for _, sc := range j.cookies {
+		if !sc.Cookie.Expires.IsZero() && sc.Cookie.Expires.Before(now) {
+			continue
+		}
+
+		ans = append(ans, sc)
+	}
+
+	return ans
+}
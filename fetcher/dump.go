@@ -0,0 +1,171 @@
+package fetcher
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// The PAX records of the entries of the dump archive
+const (
+	dumpURLRecord    = "GMAPS.url"
+	dumpStatusRecord = "GMAPS.status"
+)
+
+var _ scrapemate.HTTPFetcher = (*dumper)(nil)
+
+type dumper struct {
+	next scrapemate.HTTPFetcher
+
+	mu       sync.Mutex
+	f        *os.File
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	attempts map[string]int
+	closed   bool
+}
+
+// NewDumper returns an HTTPFetcher that writes the raw body of every response
+// of next to the tar.gz archive path, before it is parsed. The entries are
+// named after the job id and the attempt, e.g. <job id>-2 for the first retry,
+// and carry the url and the status code of the response as the PAX records
+// GMAPS.url and GMAPS.status. The archive is complete once the fetcher is
+// closed.
+func NewDumper(next scrapemate.HTTPFetcher, path string) (scrapemate.HTTPFetcher, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(f)
+
+	return &dumper{
+		next:     next,
+		f:        f,
+		gz:       gz,
+		tw:       tar.NewWriter(gz),
+		attempts: make(map[string]int),
+	}, nil
+}
+
+func (d *dumper) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	resp := d.next.Fetch(ctx, job)
+	if resp.Error != nil {
+		return resp
+	}
+
+	if err := d.write(job.GetID(), &resp); err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("cannot dump the response", "url", job.GetFullURL(), "error", err)
+	}
+
+	return resp
+}
+
+func (d *dumper) write(jobID string, resp *scrapemate.Response) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return errors.New("dump archive closed")
+	}
+
+	d.attempts[jobID]++
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     fmt.Sprintf("%s-%d", jobID, d.attempts[jobID]),
+		Size:     int64(len(resp.Body)),
+		Mode:     0o600,
+		ModTime:  time.Now(),
+		Format:   tar.FormatPAX,
+		PAXRecords: map[string]string{
+			dumpURLRecord:    resp.URL,
+			dumpStatusRecord: strconv.Itoa(resp.StatusCode),
+		},
+	}
+
+	if err := d.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err := d.tw.Write(resp.Body)
+
+	return err
+}
+
+// Close closes next and completes the archive
+func (d *dumper) Close() error {
+	err := d.next.Close()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return err
+	}
+
+	d.closed = true
+
+	return errors.Join(err, d.tw.Close(), d.gz.Close(), d.f.Close())
+}
+
+// ReadDump calls fn with the response of every entry of the archive path
+// written by NewDumper, in the order they were written. It stops at the first
+// error of fn.
+func ReadDump(path string, fn func(scrapemate.Response) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid dump archive %s: %w", path, err)
+	}
+
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid dump archive %s: %w", path, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("invalid dump entry %s: %w", hdr.Name, err)
+		}
+
+		status, _ := strconv.Atoi(hdr.PAXRecords[dumpStatusRecord])
+
+		err = fn(scrapemate.Response{
+			URL:        hdr.PAXRecords[dumpURLRecord],
+			StatusCode: status,
+			Body:       body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
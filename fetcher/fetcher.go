@@ -0,0 +1,67 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/fetchers/nethttp"
+)
+
+// DefaultTimeout is the request timeout used by fetchers created with
+// NewRoundTripper.
+const DefaultTimeout = 10 * time.Second
+
+// JobFetcher is implemented by jobs that carry their own fetcher.
+// A nil return value means that the default fetcher should be used.
+type JobFetcher interface {
+	Fetcher() scrapemate.HTTPFetcher
+}
+
+// NewRoundTripper returns an HTTPFetcher that sends the requests
+// using the provided RoundTripper. When rt is nil http.DefaultTransport is used.
+func NewRoundTripper(rt http.RoundTripper) (scrapemate.HTTPFetcher, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout:   DefaultTimeout,
+		Jar:       jar,
+		Transport: rt,
+	}
+
+	return nethttp.New(client), nil
+}
+
+var _ scrapemate.HTTPFetcher = (*dispatcher)(nil)
+
+type dispatcher struct {
+	fallback scrapemate.HTTPFetcher
+}
+
+// NewDispatcher returns an HTTPFetcher that uses the job's own fetcher when the
+// job implements JobFetcher and returns a non nil fetcher.
+// Otherwise, the request is sent using the fallback fetcher.
+func NewDispatcher(fallback scrapemate.HTTPFetcher) scrapemate.HTTPFetcher {
+	return &dispatcher{
+		fallback: fallback,
+	}
+}
+
+func (d *dispatcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if jf, ok := job.(JobFetcher); ok {
+		if f := jf.Fetcher(); f != nil {
+			return f.Fetch(ctx, job)
+		}
+	}
+
+	return d.fallback.Fetch(ctx, job)
+}
+
+func (d *dispatcher) Close() error {
+	return d.fallback.Close()
+}
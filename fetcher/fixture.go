@@ -0,0 +1,183 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gosom/scrapemate"
+)
+
+// ErrNoFixture is returned by the replayer when a request has not been recorded.
+var ErrNoFixture = errors.New("no fixture for request")
+
+// sensitiveHeaders are removed from the recorded responses
+var sensitiveHeaders = []string{
+	"Set-Cookie",
+	"Cookie",
+	"Authorization",
+	"Proxy-Authorization",
+}
+
+// fixture is the recorded part of a scrapemate.Response
+type fixture struct {
+	URL        string
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	Meta       map[string]any
+}
+
+var _ scrapemate.HTTPFetcher = (*recorder)(nil)
+
+type recorder struct {
+	next scrapemate.HTTPFetcher
+	dir  string
+}
+
+// NewRecorder returns an HTTPFetcher that saves every successful response
+// of next in dir, so that it can be replayed with NewReplayer.
+// Cookies and authorization headers are not saved.
+func NewRecorder(next scrapemate.HTTPFetcher, dir string) (scrapemate.HTTPFetcher, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &recorder{next: next, dir: dir}, nil
+}
+
+func (r *recorder) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	resp := r.next.Fetch(ctx, job)
+	if resp.Error != nil {
+		return resp
+	}
+
+	fx := fixture{
+		URL:        resp.URL,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Headers.Clone(),
+		Body:       resp.Body,
+		Meta:       resp.Meta,
+	}
+
+	for _, h := range sensitiveHeaders {
+		fx.Headers.Del(h)
+	}
+
+	if err := r.save(job, &fx); err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Error(fmt.Sprintf("failed to record fixture: %v", err))
+	}
+
+	return resp
+}
+
+func (r *recorder) save(job scrapemate.IJob, fx *fixture) error {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(fx); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fixturePath(r.dir, job), buf.Bytes(), 0o600)
+}
+
+func (r *recorder) Close() error {
+	return r.next.Close()
+}
+
+var _ scrapemate.HTTPFetcher = (*replayer)(nil)
+
+type replayer struct {
+	dir string
+}
+
+// NewReplayer returns an HTTPFetcher that serves the responses
+// recorded with NewRecorder without doing any request.
+func NewReplayer(dir string) (scrapemate.HTTPFetcher, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return &replayer{dir: dir}, nil
+}
+
+func (r *replayer) Fetch(_ context.Context, job scrapemate.IJob) scrapemate.Response {
+	data, err := os.ReadFile(fixturePath(r.dir, job))
+	if errors.Is(err, os.ErrNotExist) {
+		return scrapemate.Response{Error: fmt.Errorf("%w: %s", ErrNoFixture, job.GetFullURL())}
+	}
+
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	var fx fixture
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fx); err != nil {
+		return scrapemate.Response{Error: fmt.Errorf("invalid fixture: %w", err)}
+	}
+
+	return scrapemate.Response{
+		URL:        fx.URL,
+		StatusCode: fx.StatusCode,
+		Headers:    fx.Headers,
+		Body:       fx.Body,
+		Meta:       fx.Meta,
+	}
+}
+
+func (r *replayer) Close() error {
+	return nil
+}
+
+// ReadFixtures calls fn with the response of every fixture in dir, in the order
+// of the file names. It stops at the first error of fn.
+func ReadFixtures(dir string, fn func(scrapemate.Response) error) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.gob"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var fx fixture
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fx); err != nil {
+			return fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+
+		err = fn(scrapemate.Response{
+			URL:        fx.URL,
+			StatusCode: fx.StatusCode,
+			Headers:    fx.Headers,
+			Body:       fx.Body,
+			Meta:       fx.Meta,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fixturePath returns the file of the job's fixture
+func fixturePath(dir string, job scrapemate.IJob) string {
+	sum := sha256.Sum256([]byte(job.GetMethod() + " " + job.GetFullURL()))
+
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".gob")
+}
@@ -0,0 +1,38 @@
+package fetcher
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+)
+
+// The interstitial pages Google serves instead of the requested page
+const (
+	InterstitialConsent = "consent page"
+	InterstitialCaptcha = "captcha page"
+)
+
+// The cookie of the rejected cookie consent, the clients sending it are not
+// served the consent page
+const (
+	ConsentCookieName  = "SOCS"
+	ConsentCookieValue = "CAESEwgDEgk0ODE3Nzk3MjQaAmVuIAEaBgiA_LyaBg"
+)
+
+// Interstitial returns the interstitial page of the response: the cookie
+// consent or the captcha of the "unusual traffic" page. It is empty when the
+// response is the requested page.
+func Interstitial(resp *scrapemate.Response) string {
+	switch {
+	case strings.Contains(resp.URL, "consent.google.com"),
+		bytes.Contains(resp.Body, []byte(`action="https://consent.google.com/save"`)):
+		return InterstitialConsent
+	case strings.Contains(resp.URL, "/sorry/"),
+		bytes.Contains(resp.Body, []byte("/sorry/index")),
+		bytes.Contains(resp.Body, []byte(`id="captcha-form"`)):
+		return InterstitialCaptcha
+	default:
+		return ""
+	}
+}
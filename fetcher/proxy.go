@@ -0,0 +1,314 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/ratelimit"
+)
+
+var (
+	_ scrapemate.ProxyRotator = (*limitedRotator)(nil)
+	_ ProxyCooler             = (*limitedRotator)(nil)
+)
+
+type limitedRotator struct {
+	proxies    []scrapemate.Proxy
+	byURL      map[string]int
+	buckets    *ratelimit.Buckets
+	current    atomic.Uint32
+	transports sync.Map
+}
+
+// NewLimitedRotator returns a proxy rotator sending at most rate requests per
+// second through every proxy, with bursts of burst requests. The next proxy
+// is the one allowed to send a request first, so the proxies are used evenly,
+// and the requests wait for it before they are sent. When a response has
+// status 429 the proxy pauses for DefaultCooldown.
+func NewLimitedRotator(proxies []string, rate float64, burst int) (scrapemate.ProxyRotator, error) {
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("no proxies provided")
+	}
+
+	buckets, err := ratelimit.NewBuckets(rate, burst)
+	if err != nil {
+		return nil, err
+	}
+
+	r := limitedRotator{
+		proxies: make([]scrapemate.Proxy, len(proxies)),
+		byURL:   make(map[string]int, len(proxies)),
+		buckets: buckets,
+	}
+
+	for i := range proxies {
+		r.proxies[i], err = scrapemate.NewProxy(proxies[i])
+		if err != nil {
+			return nil, err
+		}
+
+		r.byURL[r.proxies[i].URL] = i
+	}
+
+	return &r, nil
+}
+
+func (r *limitedRotator) Proxies() []string {
+	ans := make([]string, len(r.proxies))
+	for i := range r.proxies {
+		ans[i] = r.proxies[i].FullURL()
+	}
+
+	return ans
+}
+
+// Next waits for the next proxy, it is used by the fetchers without the
+// context of the request
+func (r *limitedRotator) Next() scrapemate.Proxy {
+	p, _ := r.reserve(context.Background())
+
+	return p
+}
+
+func (r *limitedRotator) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, err := r.reserve(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := r.transport(p)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		_ = r.Cooldown(req.Context(), p, DefaultCooldown)
+	}
+
+	return resp, err
+}
+
+// Cooldown pauses the requests sent through the proxy p for d
+func (r *limitedRotator) Cooldown(ctx context.Context, p scrapemate.Proxy, d time.Duration) error {
+	return r.buckets.Cooldown(ctx, p.URL, d)
+}
+
+// reserve takes the token of the proxy allowed to send a request first, the
+// proxies are tried from the next one of the round robin on a tie
+func (r *limitedRotator) reserve(ctx context.Context) (scrapemate.Proxy, error) {
+	start := int(r.current.Add(1) - 1)
+	keys := make([]string, len(r.proxies))
+
+	for i := range keys {
+		keys[i] = r.proxies[(start+i)%len(r.proxies)].URL
+	}
+
+	key, wait := r.buckets.ReserveAny(keys...)
+	p := r.proxies[r.byURL[key]]
+
+	if wait <= 0 {
+		return p, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return p, ctx.Err()
+	case <-timer.C:
+		return p, nil
+	}
+}
+
+func (r *limitedRotator) transport(p scrapemate.Proxy) (*http.Transport, error) {
+	return proxyTransport(&r.transports, p)
+}
+
+// proxyTransport returns the transport of the proxy p kept in transports,
+// it is created on the first request through p
+func proxyTransport(transports *sync.Map, p scrapemate.Proxy) (*http.Transport, error) {
+	if tr, ok := transports.Load(p.URL); ok {
+		return tr.(*http.Transport), nil
+	}
+
+	tr, err := NewProxyTransport(p)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := transports.LoadOrStore(p.URL, tr)
+
+	return actual.(*http.Transport), nil
+}
+
+// ProxyCooler is a proxy rotator whose proxies can be paused, the fetchers
+// pause the proxy of a response with status 429
+type ProxyCooler interface {
+	Cooldown(ctx context.Context, p scrapemate.Proxy, d time.Duration) error
+}
+
+var (
+	_ scrapemate.ProxyRotator = (*cooldownRotator)(nil)
+	_ ProxyCooler             = (*cooldownRotator)(nil)
+)
+
+type cooldownRotator struct {
+	next       scrapemate.ProxyRotator
+	cooldowns  ratelimit.Limiter
+	transports sync.Map
+}
+
+// NewCooldownRotator returns a proxy rotator sending the requests through the
+// proxies of next, the requests wait for cooldowns with the URL of their proxy
+// as key. When a response has status 429 its proxy pauses for DefaultCooldown,
+// with a shared limiter like ratelimit.NewRedisCooldowns in all the workers.
+func NewCooldownRotator(next scrapemate.ProxyRotator, cooldowns ratelimit.Limiter) scrapemate.ProxyRotator {
+	return &cooldownRotator{
+		next:      next,
+		cooldowns: cooldowns,
+	}
+}
+
+func (r *cooldownRotator) Proxies() []string {
+	return r.next.Proxies()
+}
+
+// Next waits for the cooldown of the next proxy, it is used by the fetchers
+// without the context of the request
+func (r *cooldownRotator) Next() scrapemate.Proxy {
+	p, _ := r.take(context.Background())
+
+	return p
+}
+
+func (r *cooldownRotator) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, err := r.take(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := proxyTransport(&r.transports, p)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		_ = r.Cooldown(req.Context(), p, DefaultCooldown)
+	}
+
+	return resp, err
+}
+
+// Cooldown pauses the requests sent through the proxy p for d, in next too
+// when it can pause its proxies
+func (r *cooldownRotator) Cooldown(ctx context.Context, p scrapemate.Proxy, d time.Duration) error {
+	if c, ok := r.next.(ProxyCooler); ok {
+		if err := c.Cooldown(ctx, p, d); err != nil {
+			return err
+		}
+	}
+
+	return r.cooldowns.Cooldown(ctx, p.URL, d)
+}
+
+func (r *cooldownRotator) take(ctx context.Context) (scrapemate.Proxy, error) {
+	p := r.next.Next()
+
+	return p, r.cooldowns.Wait(ctx, p.URL)
+}
+
+var _ scrapemate.ProxyRotator = (*rotator)(nil)
+
+type rotator struct {
+	proxies    []scrapemate.Proxy
+	transports []*http.Transport
+	current    atomic.Uint32
+}
+
+// NewRotator returns a proxy rotator sending the requests through the proxies
+// in turn, the scheme of every proxy selects its protocol, see
+// NewProxyTransport
+func NewRotator(proxies []string) (scrapemate.ProxyRotator, error) {
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("no proxies provided")
+	}
+
+	r := rotator{
+		proxies:    make([]scrapemate.Proxy, len(proxies)),
+		transports: make([]*http.Transport, len(proxies)),
+	}
+
+	for i := range proxies {
+		p, err := scrapemate.NewProxy(proxies[i])
+		if err != nil {
+			return nil, err
+		}
+
+		r.proxies[i] = p
+
+		r.transports[i], err = NewProxyTransport(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+func (r *rotator) Proxies() []string {
+	ans := make([]string, len(r.proxies))
+	for i := range r.proxies {
+		ans[i] = r.proxies[i].FullURL()
+	}
+
+	return ans
+}
+
+func (r *rotator) Next() scrapemate.Proxy {
+	return r.proxies[r.next()]
+}
+
+func (r *rotator) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.transports[r.next()].RoundTrip(req)
+}
+
+func (r *rotator) next() int {
+	return int((r.current.Add(1) - 1) % uint32(len(r.proxies))) //nolint:gosec // len is positive
+}
+
+// NewProxyTransport returns a transport sending the requests through the
+// proxy p. The scheme of the proxy selects the protocol: the requests are
+// tunneled with CONNECT through the http and https proxies, and the socks5 and
+// socks5h proxies are dialed with the username and password of p when it has
+// them.
+func NewProxyTransport(p scrapemate.Proxy) (*http.Transport, error) {
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %s: %w", p.URL, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %s: unsupported scheme %q", p.URL, proxyURL.Scheme)
+	}
+
+	if p.Username != "" && p.Password != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = http.ProxyURL(proxyURL)
+
+	return tr, nil
+}
@@ -0,0 +1,51 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/ratelimit"
+)
+
+// DefaultCooldown is how long the requests pause after a 429 response.
+const DefaultCooldown = time.Minute
+
+var _ scrapemate.HTTPFetcher = (*rateLimited)(nil)
+
+type rateLimited struct {
+	next    scrapemate.HTTPFetcher
+	limiter ratelimit.Limiter
+	key     string
+}
+
+// NewRateLimited returns an HTTPFetcher that waits for the limiter
+// before every request. When a response has status 429 all the requests
+// for key pause for DefaultCooldown.
+func NewRateLimited(next scrapemate.HTTPFetcher, limiter ratelimit.Limiter, key string) scrapemate.HTTPFetcher {
+	return &rateLimited{
+		next:    next,
+		limiter: limiter,
+		key:     key,
+	}
+}
+
+func (f *rateLimited) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if err := f.limiter.Wait(ctx, f.key); err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	resp := f.next.Fetch(ctx, job)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		_ = f.limiter.Cooldown(ctx, f.key, DefaultCooldown)
+	}
+
+	return resp
+}
+
+func (f *rateLimited) Close() error {
+	return f.next.Close()
+}
@@ -0,0 +1,44 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+)
+
+// SkippedJob is implemented by the jobs that may not need their request
+// anymore when their turn comes, e.g. the searches of a query that has all
+// its places
+type SkippedJob interface {
+	// SkipFetch returns the error of the response of the job when its request
+	// is not sent, nil otherwise
+	SkipFetch() error
+}
+
+var _ scrapemate.HTTPFetcher = (*skipper)(nil)
+
+type skipper struct {
+	next scrapemate.HTTPFetcher
+}
+
+// NewSkipper returns an HTTPFetcher that does not send the requests of the
+// SkippedJobs skipping their fetch, the other requests are sent with next.
+// The skipped requests do not wait for the rate limits and are not counted
+// by the circuit breaker.
+func NewSkipper(next scrapemate.HTTPFetcher) scrapemate.HTTPFetcher {
+	return &skipper{next: next}
+}
+
+func (s *skipper) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if sj, ok := job.(SkippedJob); ok {
+		if err := sj.SkipFetch(); err != nil {
+			return scrapemate.Response{Error: err}
+		}
+	}
+
+	return s.next.Fetch(ctx, job)
+}
+
+func (s *skipper) Close() error {
+	return s.next.Close()
+}
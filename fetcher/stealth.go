@@ -0,0 +1,240 @@
+package fetcher
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/Noooste/azuretls-client"
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/useragent"
+)
+
+// directJar is the key of the cookie jar of the requests sent without proxy
+const directJar = "direct"
+
+var _ scrapemate.HTTPFetcher = (*stealthFetcher)(nil)
+
+type stealthFetcher struct {
+	browser string
+	headers azuretls.OrderedHeaders
+	rotator scrapemate.ProxyRotator
+	jars    *CookieJars
+	agents  *useragent.Rotator
+}
+
+// NewStealth returns an HTTPFetcher sending the requests with the TLS
+// fingerprint and the headers of browser, like the stealth fetcher of
+// scrapemate. The requests sent through the same proxy of rotator share the
+// cookie jar of the proxy in jars. With agents the requests are sent as the
+// profile picked for their job or their proxy instead of browser. When a
+// response has status 429 its proxy pauses for DefaultCooldown if rotator is a
+// ProxyCooler. rotator and agents may be nil.
+func NewStealth(browser string, rotator scrapemate.ProxyRotator, jars *CookieJars, agents *useragent.Rotator) scrapemate.HTTPFetcher {
+	if jars == nil {
+		jars = NewCookieJars()
+	}
+
+	return &stealthFetcher{
+		browser: browser,
+		headers: stealthHeaders(browser),
+		rotator: rotator,
+		jars:    jars,
+		agents:  agents,
+	}
+}
+
+func (f *stealthFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	session := azuretls.NewSessionWithContext(ctx)
+	defer session.Close()
+
+	key := directJar
+
+	var proxy scrapemate.Proxy
+
+	if f.rotator != nil {
+		session.InsecureSkipVerify = true
+
+		proxy = f.rotator.Next()
+
+		if err := session.SetProxy(proxy.FullURL()); err != nil {
+			return scrapemate.Response{Error: err}
+		}
+
+		// the sessions of a rotating proxy provider are users of the same proxy
+		key = proxy.Username + "@" + proxy.URL
+	}
+
+	session.CookieJar = f.jars.jar(key)
+
+	headers := f.headers
+
+	switch {
+	case f.agents != nil:
+		p := f.agents.Pick(job.GetID(), key)
+
+		session.Browser = p.Browser
+		headers = p.Headers
+	case f.browser != "":
+		session.Browser = f.browser
+	}
+
+	session.OrderedHeaders = withHeaders(headers, job.GetHeaders())
+
+	u := job.GetFullURL()
+
+	req := azuretls.Request{
+		Method:  job.GetMethod(),
+		Url:     u,
+		TimeOut: job.GetTimeout(),
+	}
+
+	if body := job.GetBody(); len(body) > 0 {
+		req.Body = body
+	}
+
+	req.SetContext(ctx)
+
+	resp, err := session.Do(&req)
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	if c, ok := f.rotator.(ProxyCooler); ok && resp.StatusCode == http.StatusTooManyRequests {
+		_ = c.Cooldown(ctx, proxy, DefaultCooldown)
+	}
+
+	ans := scrapemate.Response{
+		URL:        u,
+		StatusCode: resp.StatusCode,
+		Headers:    http.Header{},
+		Body:       resp.Body,
+	}
+
+	for k, v := range resp.Header {
+		ans.Headers[k] = v
+	}
+
+	return ans
+}
+
+func (f *stealthFetcher) Close() error {
+	return nil
+}
+
+// withHeaders returns the ordered headers with the headers of the job, they
+// replace the ordered headers with the same names and the others are added
+func withHeaders(ordered azuretls.OrderedHeaders, headers map[string]string) azuretls.OrderedHeaders {
+	if len(headers) == 0 {
+		return ordered
+	}
+
+	canonical := make(map[string]string, len(headers))
+	for k, v := range headers {
+		canonical[http.CanonicalHeaderKey(k)] = v
+	}
+
+	ans := make(azuretls.OrderedHeaders, 0, len(ordered)+len(canonical))
+
+	for _, h := range ordered {
+		name := http.CanonicalHeaderKey(h[0])
+
+		if v, ok := canonical[name]; ok {
+			h = []string{h[0], v}
+			delete(canonical, name)
+		}
+
+		ans = append(ans, h)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(canonical)) {
+		ans = append(ans, []string{name, canonical[name]})
+	}
+
+	return ans
+}
+
+// stealthHeaders returns the headers sent by browser, the ones of the stealth
+// fetcher of scrapemate
+func stealthHeaders(browser string) azuretls.OrderedHeaders {
+	switch browser {
+	case azuretls.Chrome:
+		return azuretls.OrderedHeaders{
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.9"},
+			{"Cache-Control", "max-age=0"},
+			{"Sec-Ch-Ua", `"Google Chrome";v="120", "Chromium";v="120", "Not?A_Brand";v="24"`},
+			{"Sec-Ch-Ua-Mobile", "?0"},
+			{"Sec-Ch-Ua-Platform", `"Windows"`},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-User", "?1"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+		}
+	case azuretls.Firefox:
+		return azuretls.OrderedHeaders{
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.5"},
+			{"Cache-Control", "max-age=0"},
+			{"DNT", "1"},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-User", "?1"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0"},
+		}
+	case azuretls.Opera:
+		return azuretls.OrderedHeaders{
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.9"},
+			{"Cache-Control", "max-age=0"},
+			{"Sec-Ch-Ua", `"Opera";v="103", "Chromium";v="117", "Not;A=Brand";v="8"`},
+			{"Sec-Ch-Ua-Mobile", "?0"},
+			{"Sec-Ch-Ua-Platform", `"Windows"`},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-User", "?1"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/117.0.0.0 Safari/537.36 OPR/103.0.0.0"},
+		}
+	case azuretls.Safari:
+		return azuretls.OrderedHeaders{
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.9"},
+			{"Cache-Control", "max-age=0"},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15"},
+		}
+	case azuretls.Edge:
+		return azuretls.OrderedHeaders{
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.9"},
+			{"Cache-Control", "max-age=0"},
+			{"Sec-Ch-Ua", `"Microsoft Edge";v="119", "Chromium";v="119", "Not?A_Brand";v="24"`},
+			{"Sec-Ch-Ua-Mobile", "?0"},
+			{"Sec-Ch-Ua-Platform", `"Windows"`},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-User", "?1"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36 Edg/119.0.0.0"},
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/gosom/google-maps-scraper/tracing"
+)
+
+var _ scrapemate.HTTPFetcher = (*traced)(nil)
+
+type traced struct {
+	next scrapemate.HTTPFetcher
+}
+
+// NewTraced returns an HTTPFetcher that records a span for every request,
+// a child of the span the job was created in.
+func NewTraced(next scrapemate.HTTPFetcher) scrapemate.HTTPFetcher {
+	return &traced{next: next}
+}
+
+func (f *traced) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	ctx, span := tracing.StartJob(ctx, job, "gmaps.fetch")
+
+	resp := f.next.Fetch(ctx, job)
+
+	span.SetAttributes(
+		attribute.Int("http.response.status_code", resp.StatusCode),
+		attribute.Int("http.response.body.size", len(resp.Body)),
+	)
+
+	err := resp.Error
+	if err == nil && resp.StatusCode >= 400 {
+		err = fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	tracing.End(span, err)
+
+	return resp
+}
+
+func (f *traced) Close() error {
+	return f.next.Close()
+}
@@ -0,0 +1,41 @@
+package gcsuploader
+
+import (
+	"context"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+type Uploader struct {
+	client *storage.Client
+}
+
+// New returns an uploader with the application default credentials
+func New(ctx context.Context) (*Uploader, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Uploader{
+		client: client,
+	}, nil
+}
+
+// Upload writes body to the object. The bodies larger than a chunk are sent
+// as resumable uploads, a chunk at a time.
+func (u *Uploader) Upload(ctx context.Context, bucketName, key string, body io.Reader) error {
+	// canceling the context aborts the upload, closing the writer would create
+	// the object with the part copied so far
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := u.client.Bucket(bucketName).Object(key).NewWriter(ctx)
+
+	if _, err := io.Copy(w, body); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
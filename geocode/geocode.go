@@ -0,0 +1,117 @@
+// Package geocode fills in the coordinates of the places parsed without them
+// from their address.
+//
+// The coordinates are obtained from a Geocoder; a client for Nominatim, the
+// geocoder of OpenStreetMap, is included.
+package geocode
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Point is a geographic coordinate
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Geocoder finds the coordinates of addresses
+type Geocoder interface {
+	// Geocode returns the coordinates of address, false when it is not found
+	Geocode(ctx context.Context, address string) (Point, bool, error)
+}
+
+// New creates the geocoder described by spec, which has the form
+// <provider>:<base url>, e.g. nominatim:http://localhost:8080 or
+// nominatim:https://nominatim.openstreetmap.org
+func New(spec string) (Geocoder, error) {
+	name, baseURL, ok := strings.Cut(spec, ":")
+	if !ok || baseURL == "" {
+		return nil, fmt.Errorf("invalid geocoder %q: expected <provider>:<url>", spec)
+	}
+
+	switch strings.ToLower(name) {
+	case "nominatim":
+		return NewNominatim(baseURL, 0)
+	default:
+		return nil, fmt.Errorf("unknown geocoder %s", name)
+	}
+}
+
+// Enricher geocodes the addresses of the entries without coordinates. The
+// addresses are geocoded once, the places with the same address share them.
+type Enricher struct {
+	geocoder Geocoder
+
+	mu    sync.Mutex
+	found map[string]*Point
+}
+
+// NewEnricher returns an Enricher geocoding with g
+func NewEnricher(g Geocoder) *Enricher {
+	return &Enricher{geocoder: g, found: make(map[string]*Point)}
+}
+
+// Process fills in the coordinates of the entry without them from its address
+// and sets Geocoded. The entries whose address is not found are kept without
+// coordinates. It can be used as a gmaps.AfterParseFunc.
+func (e *Enricher) Process(ctx context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+	if entry.Latitude != 0 || entry.Longtitude != 0 || entry.Address == "" {
+		return nil
+	}
+
+	p, err := e.geocode(ctx, entry.Address)
+	if err != nil {
+		slog.Warn("cannot geocode the address", "title", entry.Title, "address", entry.Address, "job_id", job.GetID(), "error", err)
+
+		return nil
+	}
+
+	if p == nil {
+		slog.Debug("address not found", "title", entry.Title, "address", entry.Address, "job_id", job.GetID())
+
+		return nil
+	}
+
+	entry.Latitude, entry.Longtitude = p.Lat, p.Lon
+	entry.Geocoded = true
+
+	return nil
+}
+
+// geocode returns the coordinates of address, nil when it is not found. The
+// errors are not cached, the address is geocoded again for the next entry.
+func (e *Enricher) geocode(ctx context.Context, address string) (*Point, error) {
+	key := strings.ToLower(strings.TrimSpace(address))
+
+	e.mu.Lock()
+	p, ok := e.found[key]
+	e.mu.Unlock()
+
+	if ok {
+		return p, nil
+	}
+
+	point, found, err := e.geocoder.Geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		p = &point
+	}
+
+	e.mu.Lock()
+	e.found[key] = p
+	e.mu.Unlock()
+
+	return p, nil
+}
@@ -0,0 +1,112 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/ratelimit"
+)
+
+// nominatimUserAgent identifies the requests, the usage policy of the public
+// server requires it
+const nominatimUserAgent = "google-maps-scraper (+https://github.com/gosom/google-maps-scraper)"
+
+var _ Geocoder = (*Nominatim)(nil)
+
+// Nominatim uses the search service of a Nominatim server
+type Nominatim struct {
+	baseURL string
+	client  *http.Client
+	limiter *ratelimit.Buckets
+}
+
+// NewNominatim creates a client for the Nominatim server at baseURL sending
+// at most rate requests per second, 1 if rate is 0 like the usage policy of
+// the public server.
+func NewNominatim(baseURL string, rate float64) (*Nominatim, error) {
+	const timeout = 30 * time.Second
+
+	if rate == 0 {
+		rate = 1
+	}
+
+	limiter, err := ratelimit.NewBuckets(rate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := Nominatim{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+		limiter: limiter,
+	}
+
+	return &ans, nil
+}
+
+type nominatimPlace struct {
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+func (n *Nominatim) Geocode(ctx context.Context, address string) (Point, bool, error) {
+	if err := n.limiter.Wait(ctx, n.baseURL); err != nil {
+		return Point{}, false, err
+	}
+
+	q := url.Values{}
+	q.Set("q", address)
+	q.Set("format", "jsonv2")
+	q.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/search?"+q.Encode(), http.NoBody)
+	if err != nil {
+		return Point{}, false, err
+	}
+
+	req.Header.Set("User-Agent", nominatimUserAgent)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return Point{}, false, fmt.Errorf("nominatim request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Point{}, false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Point{}, false, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	}
+
+	var places []nominatimPlace
+	if err := json.Unmarshal(data, &places); err != nil {
+		return Point{}, false, fmt.Errorf("invalid nominatim response: %w", err)
+	}
+
+	if len(places) == 0 {
+		return Point{}, false, nil
+	}
+
+	lat, err := strconv.ParseFloat(places[0].Lat, 64)
+	if err != nil {
+		return Point{}, false, fmt.Errorf("invalid nominatim latitude: %w", err)
+	}
+
+	lon, err := strconv.ParseFloat(places[0].Lon, 64)
+	if err != nil {
+		return Point{}, false, fmt.Errorf("invalid nominatim longitude: %w", err)
+	}
+
+	return Point{Lat: lat, Lon: lon}, true, nil
+}
@@ -0,0 +1,96 @@
+package gmaps
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// TransitStop is a stop of the Nearby transit block
+type TransitStop struct {
+	Name string `json:"name"`
+	// Distance is the distance as shown, e.g. "4 min walk"
+	Distance string   `json:"distance"`
+	Lines    []string `json:"lines"`
+}
+
+var attributeKeyRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+var transitDistanceRegex = regexp.MustCompile(`(?i)^(?:\d+(?:[.,]\d+)?\s?(?:min|mins|minutes?|m|km|ft|mi)\b.*|.*\bwalk)$`)
+
+// setParking sets the enabled options of the parking section of the About tab
+func (e *Entry) setParking() {
+	for _, about := range e.About {
+		if about.ID != "parking" {
+			continue
+		}
+
+		for _, opt := range about.Options {
+			if opt.Enabled {
+				e.Parking = append(e.Parking, opt.Name)
+			}
+		}
+	}
+}
+
+// setAttributes sets the options of all the sections of the About tab, like
+// the accessibility, the amenities and the service options, keyed by their
+// name in snake case, e.g. wheelchair_accessible_entrance or outdoor_seating.
+// An option shown in several sections is enabled when one of them enables it.
+func (e *Entry) setAttributes() {
+	for _, about := range e.About {
+		for _, opt := range about.Options {
+			key := attributeKey(opt.Name)
+			if key == "" {
+				continue
+			}
+
+			if e.Attributes == nil {
+				e.Attributes = make(map[string]bool)
+			}
+
+			e.Attributes[key] = e.Attributes[key] || opt.Enabled
+		}
+	}
+}
+
+// attributeKey returns the name of an option in snake case, "Wi-Fi" is wi_fi
+func attributeKey(name string) string {
+	return strings.Trim(attributeKeyRegex.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// fetchTransitTexts returns the texts of the Nearby transit block of the overview.
+// It must run before a tab is opened.
+func fetchTransitTexts(page playwright.Page) ([]string, error) {
+	return sectionTexts(page, `^(nearby transit|transit|public transport)$`)
+}
+
+// parseTransit groups the texts of the Nearby transit block by stop:
+// a stop is a name followed by its lines and its distance.
+func parseTransit(texts []string) []TransitStop {
+	var (
+		stops   []TransitStop
+		current *TransitStop
+	)
+
+	for _, t := range texts {
+		switch {
+		case transitDistanceRegex.MatchString(t):
+			if current != nil {
+				current.Distance = t
+				current = nil
+			}
+		case current == nil:
+			stops = append(stops, TransitStop{Name: t})
+			current = &stops[len(stops)-1]
+		default:
+			// the line badges are short, e.g. "M1", "24", "RE7"
+			if len(t) <= 8 && !strings.Contains(t, " ") {
+				current.Lines = append(current.Lines, t)
+			}
+		}
+	}
+
+	return stops
+}
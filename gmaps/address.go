@@ -0,0 +1,93 @@
+package gmaps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// addressPostalCodeRegex matches a part of an address with a postal code: a
+// UK or a Canadian postcode, or digits like 3042, 10115, 00-950 or 62704-1234.
+// The text before and after the code are the city, or the state.
+var addressPostalCodeRegex = regexp.MustCompile(`^(?:(.*?)\s+)?([A-Z]{1,2}\d[A-Z\d]?\s?\d[A-Z]{2}|[A-Z]\d[A-Z]\s?\d[A-Z]\d|\d{2}-\d{3}|\d{3,6}(?:-\d{3,4})?)(?:\s+(.*))?$`)
+
+// stateCountries are the countries whose addresses end with the state and
+// the postal code after the city, e.g. Springfield, IL 62704
+var stateCountries = map[string]bool{
+	"US": true,
+	"CA": true,
+	"AU": true,
+}
+
+// setCompleteAddress sets the components of the address from the address
+// block of darray, or from the address when the block is missing
+func (e *Entry) setCompleteAddress(darray []any) {
+	e.CompleteAddress = Address{
+		Borough:    getNthElementAndCast[string](darray, 183, 1, 0),
+		Street:     getNthElementAndCast[string](darray, 183, 1, 1),
+		City:       getNthElementAndCast[string](darray, 183, 1, 3),
+		PostalCode: getNthElementAndCast[string](darray, 183, 1, 4),
+		State:      getNthElementAndCast[string](darray, 183, 1, 5),
+		Country:    getNthElementAndCast[string](darray, 183, 1, 6),
+	}
+
+	if e.CompleteAddress.Street == "" && e.CompleteAddress.City == "" && e.Address != "" {
+		e.CompleteAddress = parseAddress(e.Address, e.CompleteAddress.Country)
+	}
+}
+
+// parseAddress splits an address like "Old port, Limassol 3042" in its
+// components. The city is next to the postal code of the last part with one,
+// or is the last part, and the parts before it are the street. country is the
+// ISO code of the country of the place, the addresses of the countries with
+// states have the state next to the postal code instead of the city. The
+// parts after the postal code, usually the name of the country, are dropped.
+func parseAddress(address, country string) Address {
+	var parts []string
+
+	for _, p := range strings.Split(address, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	ans := Address{Country: country}
+
+	if len(parts) == 0 {
+		return ans
+	}
+
+	// the first part is the street, unless it is the only one
+	first := min(1, len(parts)-1)
+
+	for i := len(parts) - 1; i >= first; i-- {
+		m := addressPostalCodeRegex.FindStringSubmatch(parts[i])
+		if m == nil {
+			continue
+		}
+
+		ans.PostalCode = m[2]
+		rest := strings.TrimSpace(m[1] + " " + m[3])
+
+		if stateCountries[strings.ToUpper(country)] && i > 1 {
+			ans.State = rest
+			ans.City = parts[i-1]
+			ans.Street = strings.Join(parts[:i-1], ", ")
+
+			return ans
+		}
+
+		ans.City = rest
+		ans.Street = strings.Join(parts[:i], ", ")
+
+		return ans
+	}
+
+	if len(parts) > 1 {
+		ans.City = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	ans.Street = strings.Join(parts, ", ")
+
+	return ans
+}
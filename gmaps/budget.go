@@ -0,0 +1,22 @@
+package gmaps
+
+import (
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/budget"
+)
+
+// RequestKind returns the kind of the requests of a job for the request
+// budget of the run
+func RequestKind(job scrapemate.IJob) budget.Kind {
+	switch job.(type) {
+	case *SearchJob, *GmapJob:
+		return budget.Search
+	case *PlaceJob, *PlaceLookupJob, *QaJob:
+		return budget.Detail
+	case *ReviewJob:
+		return budget.Review
+	default:
+		return budget.Enrichment
+	}
+}
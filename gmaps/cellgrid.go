@@ -0,0 +1,266 @@
+package gmaps
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The S2 levels of a CellGrid, from cells of about 40 km to about 10 m
+const (
+	MinGridLevel = 8
+	MaxGridLevel = 20
+)
+
+// CellGrid plans the fast mode searches on the cells of the S2 grid of Level
+// instead of a grid of viewports: one search at the center of every cell
+// overlapping the area, at the zoom covering the cell. The cells do not depend
+// on the area, the same cells are searched by every run.
+type CellGrid struct {
+	Level int
+}
+
+// ParseCellGrid parses a grid like s2:13
+func ParseCellGrid(s string) (CellGrid, error) {
+	grid, level, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || !strings.EqualFold(grid, "s2") {
+		return CellGrid{}, fmt.Errorf("invalid grid %q: use s2:<level>", s)
+	}
+
+	n, err := strconv.Atoi(level)
+	if err != nil || n < MinGridLevel || n > MaxGridLevel {
+		return CellGrid{}, fmt.Errorf("invalid grid %q: the level is between %d and %d", s, MinGridLevel, MaxGridLevel)
+	}
+
+	return CellGrid{Level: n}, nil
+}
+
+func (c CellGrid) String() string {
+	return "s2:" + strconv.Itoa(c.Level)
+}
+
+func (c CellGrid) IsZero() bool {
+	return c.Level == 0
+}
+
+// AreaTiles returns the search locations of the cells overlapping the circle
+// of area, or an error when there are more than limit
+func (c CellGrid) AreaTiles(area MapLocation, viewport Viewport, limit int) ([]MapLocation, error) {
+	dLat := area.Radius / earthRadius * 180 / math.Pi
+	dLon := dLat / math.Cos(min(math.Abs(area.Lat)+dLat, 85)*math.Pi/180)
+
+	center := Entry{Latitude: area.Lat, Longtitude: area.Lon}
+
+	return c.tiles(area.Lat-dLat, area.Lon-dLon, area.Lat+dLat, area.Lon+dLon, viewport, limit, func(lat, lon float64) bool {
+		return center.haversineDistance(lat, lon) <= area.Radius
+	})
+}
+
+// PolygonTiles returns the search locations of the cells overlapping the
+// polygons, or an error when there are more than limit
+func (c CellGrid) PolygonTiles(p *Polygon, viewport Viewport, limit int) ([]MapLocation, error) {
+	minLon, minLat, maxLon, maxLat := p.bounds()
+
+	return c.tiles(minLat, minLon, maxLat, maxLon, viewport, limit, p.Contains)
+}
+
+// tiles returns the cells of the points of the bounding box inside the area,
+// sampled a few times per cell
+func (c CellGrid) tiles(minLat, minLon, maxLat, maxLon float64, viewport Viewport, limit int, inside func(lat, lon float64) bool) ([]MapLocation, error) {
+	const samplesPerCell = 4
+
+	midLat := (minLat + maxLat) / 2
+	side := s2CellAt(midLat, (minLon+maxLon)/2, c.Level).side()
+
+	extentY := (maxLat - minLat) * math.Pi / 180 * earthRadius
+	extentX := (maxLon - minLon) * math.Pi / 180 * earthRadius * math.Cos(midLat*math.Pi/180)
+
+	// the cells are only sampled when the bounding box may have few enough
+	if estimate := extentX * extentY / (side * side); estimate > float64(4*limit) {
+		return nil, fmt.Errorf("the area needs about %.0f cells at level %d, more than %d: lower the level", estimate, c.Level, limit)
+	}
+
+	step := side / samplesPerCell / earthRadius * 180 / math.Pi
+	cells := make(map[s2Cell]struct{})
+
+	for lat := minLat; lat <= maxLat+step; lat += step {
+		lat := min(lat, maxLat)
+		lonStep := step / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+		for lon := minLon; lon <= maxLon+lonStep; lon += lonStep {
+			lon := min(lon, maxLon)
+
+			if inside(lat, lon) {
+				cells[s2CellAt(lat, lon, c.Level)] = struct{}{}
+			}
+		}
+	}
+
+	if len(cells) > limit {
+		return nil, fmt.Errorf("the area needs %d cells at level %d, more than %d: lower the level", len(cells), c.Level, limit)
+	}
+
+	sorted := make([]s2Cell, 0, len(cells))
+	for cell := range cells {
+		sorted = append(sorted, cell)
+	}
+
+	slices.SortFunc(sorted, func(a, b s2Cell) int {
+		if a.face != b.face {
+			return a.face - b.face
+		}
+
+		if a.j != b.j {
+			return a.j - b.j
+		}
+
+		return a.i - b.i
+	})
+
+	tiles := make([]MapLocation, 0, len(sorted))
+
+	for _, cell := range sorted {
+		lat, lon := cell.point(0.5, 0.5)
+		radius := cell.radius()
+
+		tiles = append(tiles, MapLocation{
+			Lat:     lat,
+			Lon:     lon,
+			ZoomLvl: float64(ZoomForRadius(lat, radius, viewport)),
+			Radius:  radius,
+		})
+	}
+
+	return tiles, nil
+}
+
+// s2Cell is the cell of the S2 grid of level at i, j on a face of the cube
+type s2Cell struct {
+	face  int
+	level int
+	i     int
+	j     int
+}
+
+// s2CellAt returns the cell of level containing lat, lon
+func s2CellAt(lat, lon float64, level int) s2Cell {
+	phi, theta := lat*math.Pi/180, lon*math.Pi/180
+	x, y, z := math.Cos(phi)*math.Cos(theta), math.Cos(phi)*math.Sin(theta), math.Sin(phi)
+
+	face, u, v := xyzToFaceUV(x, y, z)
+	n := 1 << level
+
+	cell := func(st float64) int {
+		return min(max(int(math.Floor(st*float64(n))), 0), n-1)
+	}
+
+	return s2Cell{face: face, level: level, i: cell(uvToST(u)), j: cell(uvToST(v))}
+}
+
+// point returns the point of the cell at s, t, between 0 and 1 from its
+// corner
+func (c s2Cell) point(s, t float64) (lat, lon float64) {
+	n := float64(int(1) << c.level)
+	x, y, z := faceUVToXYZ(c.face, stToUV((float64(c.i)+s)/n), stToUV((float64(c.j)+t)/n))
+
+	return math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi, math.Atan2(y, x) * 180 / math.Pi
+}
+
+// radius returns the distance in meters from the center of the cell to its
+// farthest corner
+func (c s2Cell) radius() float64 {
+	lat, lon := c.point(0.5, 0.5)
+	center := Entry{Latitude: lat, Longtitude: lon}
+
+	var ans float64
+
+	for _, corner := range [][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		lat, lon := c.point(corner[0], corner[1])
+		ans = math.Max(ans, center.haversineDistance(lat, lon))
+	}
+
+	return ans
+}
+
+// side returns the length in meters of the shortest side of the cell
+func (c s2Cell) side() float64 {
+	corners := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	ans := math.Inf(1)
+
+	for k, a := range corners {
+		b := corners[(k+1)%len(corners)]
+
+		lat, lon := c.point(a[0], a[1])
+		from := Entry{Latitude: lat, Longtitude: lon}
+
+		ans = math.Min(ans, from.haversineDistance(c.point(b[0], b[1])))
+	}
+
+	return ans
+}
+
+// xyzToFaceUV returns the face of the cube of the unit vector x, y, z and its
+// coordinates on the face
+func xyzToFaceUV(x, y, z float64) (face int, u, v float64) {
+	ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z)
+
+	switch {
+	case ax >= ay && ax >= az:
+		if x < 0 {
+			return 3, z / x, y / x
+		}
+
+		return 0, y / x, z / x
+	case ay >= az:
+		if y < 0 {
+			return 4, z / y, -x / y
+		}
+
+		return 1, -x / y, z / y
+	default:
+		if z < 0 {
+			return 5, -y / z, -x / z
+		}
+
+		return 2, -x / z, -y / z
+	}
+}
+
+// faceUVToXYZ returns the vector of the coordinates u, v on the face
+func faceUVToXYZ(face int, u, v float64) (x, y, z float64) {
+	switch face {
+	case 0:
+		return 1, u, v
+	case 1:
+		return -u, 1, v
+	case 2:
+		return -u, -v, 1
+	case 3:
+		return -1, -v, -u
+	case 4:
+		return v, -1, -u
+	default:
+		return v, u, -1
+	}
+}
+
+// uvToST is the quadratic transform of S2 making the cells of a level of
+// about the same area
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+// stToUV is the inverse of uvToST
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
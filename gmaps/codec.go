@@ -0,0 +1,328 @@
+package gmaps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/tracing"
+)
+
+// The payload types of the encoded jobs
+const (
+	PayloadSearch     = "search"
+	PayloadFastSearch = "fast_search"
+	PayloadPlace      = "place"
+	PayloadEmail      = "email"
+	PayloadLookup     = "lookup"
+	PayloadQa         = "qa"
+	PayloadReview     = "review"
+	PayloadImage      = "image"
+)
+
+// EncodeJob encodes a job to store it in a shared queue, see DecodeJob. The
+// state of the run of the job is not encoded, see Runtime.
+func EncodeJob(job scrapemate.IJob) (payloadType string, payload []byte, err error) {
+	var v any
+
+	switch j := job.(type) {
+	case *GmapJob:
+		c := *j
+		c.Deduper, c.ExitMonitor = nil, nil
+		payloadType, v = PayloadSearch, &c
+	case *SearchJob:
+		payloadType, v = PayloadFastSearch, j
+	case *PlaceJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadPlace, &c
+	case *EmailExtractJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadEmail, &c
+	case *PlaceLookupJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadLookup, &c
+	case *QaJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadQa, &c
+	case *ReviewJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadReview, &c
+	case *ImageDownloadJob:
+		c := *j
+		c.ExitMonitor = nil
+		payloadType, v = PayloadImage, &c
+	default:
+		return "", nil, fmt.Errorf("invalid job type %T", job)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return "", nil, err
+	}
+
+	return payloadType, buf.Bytes(), nil
+}
+
+// DecodeJob decodes a job encoded by EncodeJob
+func DecodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
+	var job scrapemate.IJob
+
+	switch payloadType {
+	case PayloadSearch:
+		job = new(GmapJob)
+	case PayloadFastSearch:
+		job = new(SearchJob)
+	case PayloadPlace:
+		job = new(PlaceJob)
+	case PayloadEmail:
+		job = new(EmailExtractJob)
+	case PayloadLookup:
+		job = new(PlaceLookupJob)
+	case PayloadQa:
+		job = new(QaJob)
+	case PayloadReview:
+		job = new(ReviewJob)
+	case PayloadImage:
+		job = new(ImageDownloadJob)
+	default:
+		return nil, fmt.Errorf("invalid payload type: %s", payloadType)
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(job); err != nil {
+		return nil, fmt.Errorf("failed to decode %s job: %w", payloadType, err)
+	}
+
+	return job, nil
+}
+
+// Runtime is the state of the run shared by its jobs, it is not encoded. The
+// queues set it on the decoded jobs with Attach.
+type Runtime struct {
+	Deduper      deduper.Deduper
+	ExitMonitor  exiter.Exiter
+	Known        Known
+	Images       *ImageDownloader
+	EmailFetcher *EmailFetcher
+	Nearest      *Nearest
+}
+
+// Attach sets the state of the run on a decoded job. The subdivided tiles and
+// the next pages of a fast search share it.
+func (r *Runtime) Attach(job scrapemate.IJob) {
+	switch j := job.(type) {
+	case *GmapJob:
+		j.Deduper = r.Deduper
+		j.ExitMonitor = r.ExitMonitor
+		j.known = r.Known
+		j.images = r.Images
+		j.emailFetcher = r.EmailFetcher
+	case *SearchJob:
+		opts := []SearchJobOptions{WithSearchJobExitMonitor(r.ExitMonitor)}
+		if r.Deduper != nil {
+			opts = append(opts, WithSearchJobDeduper(r.Deduper))
+		}
+
+		if r.Nearest != nil && j.nearest != nil {
+			opts = append(opts, WithSearchJobNearest(r.Nearest))
+		}
+
+		for _, opt := range opts {
+			opt(j)
+		}
+
+		j.opts = append(j.opts, opts...)
+	case *PlaceJob:
+		j.ExitMonitor = r.ExitMonitor
+		j.images = r.Images
+		j.emailFetcher = r.EmailFetcher
+	case *PlaceLookupJob:
+		j.ExitMonitor = r.ExitMonitor
+		j.images = r.Images
+		j.emailFetcher = r.EmailFetcher
+	case *EmailExtractJob:
+		j.ExitMonitor = r.ExitMonitor
+		j.fetcher = r.EmailFetcher
+	case *QaJob:
+		j.ExitMonitor = r.ExitMonitor
+		j.emailFetcher = r.EmailFetcher
+	case *ReviewJob:
+		j.ExitMonitor = r.ExitMonitor
+	case *ImageDownloadJob:
+		j.ExitMonitor = r.ExitMonitor
+		j.downloader = r.Images
+	}
+}
+
+// searchJobGob are the encoded fields of a SearchJob
+type searchJobGob struct {
+	Job           scrapemate.Job
+	Carrier       tracing.Carrier
+	Params        *MapSearchParams
+	Sample        Sample
+	Nearest       int
+	QuarantineDir string
+	Splits        int
+	Pages         int
+	Found         int
+	Area          *MapLocation
+	Polygon       *Polygon
+	Route         *Route
+	Reviews       bool
+	ReviewPages   int
+	ReviewLimits  ReviewLimits
+	Interstitials int
+	FallbackDepth int
+}
+
+func (j *SearchJob) GobEncode() ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		nearest int
+	)
+
+	if j.nearest != nil {
+		nearest = j.nearest.n
+	}
+
+	err := gob.NewEncoder(&buf).Encode(searchJobGob{
+		Job:           j.Job,
+		Carrier:       j.Carrier,
+		Params:        j.params,
+		Sample:        j.sample,
+		Nearest:       nearest,
+		QuarantineDir: j.quarantineDir,
+		Splits:        j.splits,
+		Pages:         j.pages,
+		Found:         j.found,
+		Area:          j.area,
+		Polygon:       j.polygon,
+		Route:         j.route,
+		Reviews:       j.reviews,
+		ReviewPages:   j.reviewPages,
+		ReviewLimits:  j.reviewLimits,
+		Interstitials: j.interstitials,
+		FallbackDepth: j.fallbackDepth,
+	})
+
+	return buf.Bytes(), err
+}
+
+// GobDecode decodes the job and the options passed to its subdivided tiles
+// and its next pages
+func (j *SearchJob) GobDecode(data []byte) error {
+	var g searchJobGob
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+
+	opts := []SearchJobOptions{withSearchJobSplits(g.Splits)}
+
+	if !g.Sample.IsZero() {
+		opts = append(opts, WithSearchJobSample(g.Sample))
+	}
+
+	// the Nearest of the run is set by Runtime.Attach
+	if g.Nearest > 0 {
+		opts = append(opts, WithSearchJobNearest(NewNearest(g.Nearest)))
+	}
+
+	if g.QuarantineDir != "" {
+		opts = append(opts, WithSearchJobQuarantine(g.QuarantineDir))
+	}
+
+	if g.Pages > 0 {
+		opts = append(opts, WithSearchJobPages(g.Pages))
+	}
+
+	if g.Area != nil {
+		opts = append(opts, WithSearchJobArea(*g.Area))
+	}
+
+	if g.Polygon != nil {
+		opts = append(opts, WithSearchJobPolygon(g.Polygon))
+	}
+
+	if g.Route != nil {
+		opts = append(opts, WithSearchJobRoute(g.Route))
+	}
+
+	if g.Reviews {
+		opts = append(opts, WithSearchJobReviews(g.ReviewPages))
+	}
+
+	if !g.ReviewLimits.IsZero() {
+		opts = append(opts, WithSearchJobReviewLimits(g.ReviewLimits))
+	}
+
+	if len(g.Job.Headers) > 0 {
+		opts = append(opts, WithSearchJobHeaders(g.Job.Headers))
+	}
+
+	if g.FallbackDepth > 0 {
+		opts = append(opts, WithSearchJobBrowserFallback(g.FallbackDepth))
+	}
+
+	*j = SearchJob{
+		Job:           g.Job,
+		Carrier:       g.Carrier,
+		params:        g.Params,
+		found:         g.Found,
+		opts:          opts,
+		interstitials: g.Interstitials,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return nil
+}
+
+func (p *Polygon) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(p.polygons)
+
+	return buf.Bytes(), err
+}
+
+func (p *Polygon) GobDecode(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(&p.polygons)
+}
+
+// routeGob is the encoding of a Route
+type routeGob struct {
+	Points [][2]float64
+	Width  float64
+}
+
+func (r *Route) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(routeGob{Points: r.points, Width: r.width})
+
+	return buf.Bytes(), err
+}
+
+func (r *Route) GobDecode(data []byte) error {
+	var g routeGob
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+
+	r.points, r.width = g.Points, g.Width
+
+	return nil
+}
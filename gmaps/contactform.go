@@ -0,0 +1,123 @@
+package gmaps
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ContactForm is a contact form found on the website of a place
+type ContactForm struct {
+	// URL is the page the form was found in
+	URL string `json:"url"`
+	// Action is the url the form is submitted to
+	Action         string   `json:"action"`
+	Method         string   `json:"method"`
+	Fields         []string `json:"fields"`
+	RequiredFields []string `json:"required_fields"`
+}
+
+var contactFormHints = []string{
+	"contact", "kontakt", "contacto", "contatto", "wpcf7", "enquiry", "inquiry", "message",
+}
+
+// findContactForm returns the first form of the page that looks like a contact form:
+// it has a message box and a way to reply, or it is marked as a contact form.
+// Search, login and newsletter forms are ignored.
+func findContactForm(doc *goquery.Document, pageURL string) (ContactForm, bool) {
+	var (
+		ans   ContactForm
+		found bool
+	)
+
+	doc.Find("form").EachWithBreak(func(_ int, form *goquery.Selection) bool {
+		if form.Find("input[type='password'], input[type='search']").Length() > 0 {
+			return true
+		}
+
+		if role, _ := form.Attr("role"); role == "search" {
+			return true
+		}
+
+		var (
+			fields, required []string
+			hasEmail         bool
+		)
+
+		form.Find("input, textarea, select").Each(func(_ int, field *goquery.Selection) {
+			typ := strings.ToLower(field.AttrOr("type", "text"))
+			if typ == "hidden" || typ == "submit" || typ == "button" || typ == "reset" || typ == "image" {
+				return
+			}
+
+			name := field.AttrOr("name", field.AttrOr("id", ""))
+			if name == "" {
+				return
+			}
+
+			if typ == "email" || strings.Contains(strings.ToLower(name), "mail") {
+				hasEmail = true
+			}
+
+			fields = append(fields, name)
+
+			_, isRequired := field.Attr("required")
+			if isRequired || field.AttrOr("aria-required", "") == "true" {
+				required = append(required, name)
+			}
+		})
+
+		hasMessage := form.Find("textarea").Length() > 0
+
+		if !(hasMessage && (hasEmail || len(fields) > 1)) && !hasContactHint(form) {
+			return true
+		}
+
+		// a single email field is a newsletter signup
+		if !hasMessage && len(fields) < 2 {
+			return true
+		}
+
+		ans = ContactForm{
+			URL:            pageURL,
+			Action:         resolveURL(pageURL, form.AttrOr("action", "")),
+			Method:         strings.ToUpper(form.AttrOr("method", http.MethodGet)),
+			Fields:         fields,
+			RequiredFields: required,
+		}
+		found = true
+
+		return false
+	})
+
+	return ans, found
+}
+
+func hasContactHint(form *goquery.Selection) bool {
+	attrs := strings.ToLower(form.AttrOr("id", "") + " " + form.AttrOr("class", "") + " " + form.AttrOr("action", ""))
+
+	for _, hint := range contactFormHints {
+		if strings.Contains(attrs, hint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveURL resolves ref against base. An empty ref is the base itself.
+func resolveURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	r, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+
+	return b.ResolveReference(r).String()
+}
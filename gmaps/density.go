@@ -0,0 +1,123 @@
+package gmaps
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gosom/scrapemate"
+)
+
+// Completeness is how many of the places of an area a fast mode search aims for.
+// It chooses the zoom level from the radius and how the searches are subdivided.
+type Completeness string
+
+const (
+	// CompletenessMajor runs one search covering the radius: the most relevant places only
+	CompletenessMajor Completeness = "major"
+	// CompletenessBalanced splits a saturated search once into four tiles
+	CompletenessBalanced Completeness = "balanced"
+	// CompletenessExhaustive splits the saturated tiles until they are not
+	// saturated or the maximum zoom is reached
+	CompletenessExhaustive Completeness = "exhaustive"
+)
+
+const (
+	// maxSplitZoom is the zoom the tiles are not split beyond, a few streets
+	maxSplitZoom = 18
+)
+
+// ParseCompleteness parses the completeness level
+func ParseCompleteness(s string) (Completeness, error) {
+	switch c := Completeness(s); c {
+	case CompletenessMajor, CompletenessBalanced, CompletenessExhaustive:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid completeness %q: use major, balanced or exhaustive", s)
+	}
+}
+
+// splits returns how many times a saturated tile is split
+func (c Completeness) splits() int {
+	switch c {
+	case CompletenessBalanced:
+		return 1
+	case CompletenessExhaustive:
+		return maxSplitZoom
+	default:
+		return 0
+	}
+}
+
+// ZoomForRadius returns the highest zoom level whose search viewport
+// covers the circle of radius meters around lat.
+func ZoomForRadius(lat, radius float64, viewport Viewport) int {
+	const (
+		minZoom = 1
+		maxZoom = 21
+	)
+
+	if radius <= 0 {
+		return maxZoom
+	}
+
+	worldWidth := 2 * math.Pi * earthRadius * math.Cos(lat*math.Pi/180)
+	zoom := math.Floor(math.Log2(viewport.side() * worldWidth / (256 * 2 * radius)))
+
+	return int(min(max(zoom, minZoom), maxZoom))
+}
+
+// saturated reports whether the search returned a full page, i.e. there are
+// more places in the tile than the search lists
+func (j *SearchJob) saturated(found int) bool {
+	return j.splits > 0 && !j.params.Locationless &&
+		found >= searchPageSize && j.params.Location.ZoomLvl < maxSplitZoom
+}
+
+// subdivide returns the searches of the four quadrants of the tile,
+// one zoom level closer. The quadrants cover the circle of the tile.
+func (j *SearchJob) subdivide() []scrapemate.IJob {
+	loc := j.params.Location
+
+	half := loc.Radius / 2
+	dLat := half / earthRadius * 180 / math.Pi
+	dLon := dLat / math.Cos(loc.Lat*math.Pi/180)
+
+	jobs := make([]scrapemate.IJob, 0, 4)
+
+	for _, offset := range [][2]float64{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		params := *j.params
+		params.Offset = 0
+		params.Location = MapLocation{
+			Lat:     loc.Lat + offset[0]*dLat,
+			Lon:     loc.Lon + offset[1]*dLon,
+			ZoomLvl: loc.ZoomLvl + 1,
+			// the circle around the square quadrant
+			Radius: half * math.Sqrt2,
+		}
+
+		opts := append(j.opts[:len(j.opts):len(j.opts)], withSearchJobSplits(j.splits-1))
+
+		child := NewSearchJob(&params, opts...)
+		child.ParentID = j.ID
+
+		jobs = append(jobs, child)
+	}
+
+	return jobs
+}
+
+func withSearchJobSplits(n int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.splits = n
+	}
+}
+
+// dedupKey identifies a place found by the overlapping tiles
+func dedupKey(e *Entry) string {
+	if e.DataID != "" {
+		return e.DataID
+	}
+
+	return e.Title + "@" + strconv.FormatFloat(e.Latitude, 'f', 6, 64) + "," + strconv.FormatFloat(e.Longtitude, 'f', 6, 64)
+}
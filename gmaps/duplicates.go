@@ -0,0 +1,124 @@
+package gmaps
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The reasons two entries are duplicate listings of the same place
+const (
+	DuplicatePhone = "phone"
+	DuplicateName  = "name"
+)
+
+// minPhoneDigits is the number of digits of the shortest phone compared, the
+// shorter ones are extensions or short numbers shared by unrelated places
+const minPhoneDigits = 7
+
+// phoneKeyDigits is the number of the last digits of the phone compared, so
+// that the national and the international formats of a number match
+const phoneKeyDigits = 9
+
+// MergedSource is a listing merged into the entry as a duplicate of its place,
+// see Entry.MergeDuplicate
+type MergedSource struct {
+	Cid    string `json:"cid"`
+	DataID string `json:"data_id"`
+	Link   string `json:"link"`
+	Title  string `json:"title"`
+	// Reason is why the listing is a duplicate, DuplicatePhone or DuplicateName
+	Reason string `json:"reason"`
+}
+
+// PhoneKey returns the phone of the entry normalized to its last digits, empty
+// when it has no phone or a too short one
+func (e *Entry) PhoneKey() string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, e.Phone)
+
+	if len(digits) < minPhoneDigits {
+		return ""
+	}
+
+	return digits[max(len(digits)-phoneKeyDigits, 0):]
+}
+
+// NameKey returns the title of the entry folded to lower case letters and
+// digits without accents, separated by single spaces
+func (e *Entry) NameKey() string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	name, _, err := transform.String(t, e.Title)
+	if err != nil {
+		name = e.Title
+	}
+
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// DistanceTo returns the distance in meters between the entries
+func (e *Entry) DistanceTo(other *Entry) float64 {
+	return e.haversineDistance(other.Latitude, other.Longtitude)
+}
+
+// MergeDuplicate merges dup, a duplicate listing of the place of the entry,
+// into the entry: the empty fields of the entry are set from dup, their
+// categories, emails and tags are joined, and dup and the listings merged into
+// it are recorded in MergedFrom.
+func (e *Entry) MergeDuplicate(dup *Entry, reason string) {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+
+	fill(&e.Phone, dup.Phone)
+	fill(&e.WebSite, dup.WebSite)
+	fill(&e.Address, dup.Address)
+	fill(&e.PlusCode, dup.PlusCode)
+	fill(&e.Category, dup.Category)
+	fill(&e.CanonicalCategory, dup.CanonicalCategory)
+	fill(&e.Description, dup.Description)
+	fill(&e.Thumbnail, dup.Thumbnail)
+
+	if len(e.OpenHours) == 0 {
+		e.OpenHours = dup.OpenHours
+	}
+
+	e.Categories = union(e.Categories, dup.Categories)
+	e.CanonicalCategories = union(e.CanonicalCategories, dup.CanonicalCategories)
+	e.Emails = union(e.Emails, dup.Emails)
+	e.Tags = union(e.Tags, dup.Tags)
+
+	e.MergedFrom = append(e.MergedFrom, MergedSource{
+		Cid:    dup.Cid,
+		DataID: dup.DataID,
+		Link:   dup.Link,
+		Title:  dup.Title,
+		Reason: reason,
+	})
+	e.MergedFrom = append(e.MergedFrom, dup.MergedFrom...)
+}
+
+// union appends the values of b missing from a
+func union(a, b []string) []string {
+	for _, v := range b {
+		if !slices.Contains(a, v) {
+			a = append(a, v)
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,116 @@
+package gmaps
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mcnijman/go-emailaddress"
+)
+
+var (
+	// name [at] domain [dot] com, name(at)domain(dot)com, name {at} domain.com
+	obfuscatedAtRegex  = regexp.MustCompile(`(?i)\s*[\[\(\{<]\s*(?:at|@|arroba|ät)\s*[\]\)\}>]\s*`)
+	obfuscatedDotRegex = regexp.MustCompile(`(?i)\s*[\[\(\{<]\s*(?:dot|punto|punkt|\.)\s*[\]\)\}>]\s*`)
+	// "info" + "@" + "example.com"
+	jsConcatRegex = regexp.MustCompile(`(?:["'][^"'\n]*["']\s*\+\s*)+["'][^"'\n]*["']`)
+	jsStringRegex = regexp.MustCompile(`["']([^"'\n]*)["']`)
+)
+
+const cloudflareEmailPath = "/cdn-cgi/l/email-protection#"
+
+// obfuscatedEmailExtractor finds the emails hidden from naive crawlers:
+// Cloudflare email protection, "name [at] domain [dot] com" texts and
+// addresses assembled with string concatenation in scripts.
+func obfuscatedEmailExtractor(doc *goquery.Document) []string {
+	var candidates []string
+
+	doc.Find("[data-cfemail]").Each(func(_ int, s *goquery.Selection) {
+		if email, ok := decodeCloudflareEmail(s.AttrOr("data-cfemail", "")); ok {
+			candidates = append(candidates, email)
+		}
+	})
+
+	doc.Find("a[href*='" + cloudflareEmailPath + "']").Each(func(_ int, s *goquery.Selection) {
+		_, encoded, _ := strings.Cut(s.AttrOr("href", ""), cloudflareEmailPath)
+		if email, ok := decodeCloudflareEmail(encoded); ok {
+			candidates = append(candidates, email)
+		}
+	})
+
+	text := deobfuscateEmailText(visibleText(doc))
+	for _, addr := range emailaddress.Find([]byte(text), false) {
+		candidates = append(candidates, addr.String())
+	}
+
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		for _, expr := range jsConcatRegex.FindAllString(s.Text(), -1) {
+			var sb strings.Builder
+
+			for _, m := range jsStringRegex.FindAllStringSubmatch(expr, -1) {
+				sb.WriteString(m[1])
+			}
+
+			for _, addr := range emailaddress.Find([]byte(sb.String()), false) {
+				candidates = append(candidates, addr.String())
+			}
+		}
+	})
+
+	seen := map[string]bool{}
+
+	var emails []string
+
+	for _, c := range candidates {
+		email, err := getValidEmail(c)
+		if err != nil || seen[email] {
+			continue
+		}
+
+		emails = append(emails, email)
+		seen[email] = true
+	}
+
+	return emails
+}
+
+// decodeCloudflareEmail decodes the hex string of Cloudflare email protection:
+// the first byte is the key the rest of the bytes are xored with.
+func decodeCloudflareEmail(encoded string) (string, bool) {
+	data, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(data) < 2 {
+		return "", false
+	}
+
+	key := data[0]
+	out := make([]byte, len(data)-1)
+
+	for i, b := range data[1:] {
+		out[i] = b ^ key
+	}
+
+	return string(out), true
+}
+
+// visibleText returns the text nodes of the body separated by spaces,
+// so that text of adjacent elements is not glued together.
+func visibleText(doc *goquery.Document) string {
+	var parts []string
+
+	doc.Find("body, body *").Not("script, style, noscript").Contents().Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "#text" {
+			if t := strings.TrimSpace(s.Text()); t != "" {
+				parts = append(parts, t)
+			}
+		}
+	})
+
+	return strings.Join(parts, " ")
+}
+
+func deobfuscateEmailText(text string) string {
+	text = obfuscatedAtRegex.ReplaceAllString(text, "@")
+
+	return obfuscatedDotRegex.ReplaceAllString(text, ".")
+}
@@ -0,0 +1,141 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// ErrDisallowedByRobots is returned for the pages the robots.txt of the website disallows
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+var _ scrapemate.HTTPFetcher = (*EmailFetcher)(nil)
+
+// EmailFetcher fetches the websites for the EmailExtractJobs without the
+// browser, with its own concurrency and timeout so that slow websites do not
+// hold the workers scraping Google Maps.
+type EmailFetcher struct {
+	client *http.Client
+	sem    chan struct{}
+	robots bool
+
+	mu sync.Mutex
+	// rules are the robots.txt rules by host
+	rules map[string]robotsRules
+}
+
+// NewEmailFetcher returns a fetcher requesting at most concurrency pages at a
+// time. When robots is set the pages disallowed by the robots.txt of the
+// website are skipped.
+func NewEmailFetcher(concurrency int, timeout time.Duration, robots bool) *EmailFetcher {
+	const defaultTimeout = 10 * time.Second
+
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &EmailFetcher{
+		client: &http.Client{Timeout: timeout},
+		sem:    make(chan struct{}, max(concurrency, 1)),
+		robots: robots,
+		rules:  make(map[string]robotsRules),
+	}
+}
+
+func (f *EmailFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	select {
+	case f.sem <- struct{}{}:
+	case <-ctx.Done():
+		return scrapemate.Response{Error: ctx.Err()}
+	}
+
+	defer func() { <-f.sem }()
+
+	u, err := url.Parse(job.GetFullURL())
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	if f.robots && !f.allowed(ctx, u) {
+		return scrapemate.Response{URL: u.String(), Error: ErrDisallowedByRobots}
+	}
+
+	start := time.Now()
+
+	resp, body, err := f.get(ctx, u.String())
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	return scrapemate.Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}
+}
+
+// allowed fetches the robots.txt of the host once. A website without a
+// readable robots.txt allows everything.
+func (f *EmailFetcher) allowed(ctx context.Context, u *url.URL) bool {
+	f.mu.Lock()
+	rules, ok := f.rules[u.Host]
+	f.mu.Unlock()
+
+	if !ok {
+		robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+		resp, body, err := f.get(ctx, robotsURL.String())
+		if err == nil && resp.StatusCode == http.StatusOK {
+			rules = parseRobots(body)
+		}
+
+		f.mu.Lock()
+		f.rules[u.Host] = rules
+		f.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return rules.allowed(path)
+}
+
+func (f *EmailFetcher) get(ctx context.Context, u string) (*http.Response, []byte, error) {
+	// the pages are read up to 5MB, the emails are in the first bytes of the larger ones
+	const maxBody = 5 << 20
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, body, nil
+}
+
+func (f *EmailFetcher) Close() error {
+	f.client.CloseIdleConnections()
+
+	return nil
+}
@@ -2,11 +2,13 @@ package gmaps
 
 import (
 	"context"
+	"net/url"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/google/uuid"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/tracing"
 	"github.com/gosom/scrapemate"
 	"github.com/mcnijman/go-emailaddress"
 )
@@ -15,9 +17,22 @@ type EmailExtractJobOptions func(*EmailExtractJob)
 
 type EmailExtractJob struct {
 	scrapemate.Job
+	tracing.Carrier
 
 	Entry       *Entry
 	ExitMonitor exiter.Exiter
+	// MaxPages is the number of pages of the website visited to find emails
+	MaxPages int
+	// Visited is the number of pages visited so far
+	Visited int
+	// Queue are the next pages to visit
+	Queue []string
+	// Sitemap is set when the job fetches the sitemap of the website
+	Sitemap bool
+
+	// pending is set when the entry is passed to the next page
+	pending bool
+	fetcher *EmailFetcher
 }
 
 func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions) *EmailExtractJob {
@@ -35,6 +50,7 @@ func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions)
 			MaxRetries: defaultMaxRetries,
 			Priority:   defaultPrio,
 		},
+		MaxPages: 1,
 	}
 
 	job.Entry = entry
@@ -52,30 +68,61 @@ func WithEmailJobExitMonitor(exitMonitor exiter.Exiter) EmailExtractJobOptions {
 	}
 }
 
+// WithEmailJobPages sets the number of pages of the website that may be visited.
+// When the first page has no emails, the sitemap is used to find the contact,
+// about or imprint pages.
+func WithEmailJobPages(n int) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		if n > 0 {
+			j.MaxPages = n
+		}
+	}
+}
+
+// WithEmailJobFetcher fetches the website with f instead of the fetcher of the app
+func WithEmailJobFetcher(f *EmailFetcher) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.fetcher = f
+	}
+}
+
+// Fetcher returns the fetcher set with WithEmailJobFetcher
+func (j *EmailExtractJob) Fetcher() scrapemate.HTTPFetcher {
+	if j.fetcher == nil {
+		return nil
+	}
+
+	return j.fetcher
+}
+
 func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 	}()
 
-	defer func() {
-		if j.ExitMonitor != nil {
-			j.ExitMonitor.IncrPlacesCompleted(1)
-		}
-	}()
-
 	log := scrapemate.GetLoggerFromContext(ctx)
 
 	log.Info("Processing email job", "url", j.URL)
 
-	// if html fetch failed just return
-	if resp.Error != nil {
-		return j.Entry, nil, nil
+	if j.Sitemap {
+		var urls []string
+		if resp.Error == nil {
+			urls = sitemapURLs(resp.Body)
+		}
+
+		queue := rankContactPages(j.Entry.WebSite, append(urls, j.Queue...), j.MaxPages-j.Visited)
+
+		return j.next(queue)
 	}
 
+	j.Visited++
+
 	doc, ok := resp.Document.(*goquery.Document)
-	if !ok {
-		return j.Entry, nil, nil
+
+	// if html fetch failed just go on
+	if resp.Error != nil || !ok {
+		return j.next(j.Queue)
 	}
 
 	emails := docEmailExtractor(doc)
@@ -83,11 +130,99 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 		emails = regexEmailExtractor(resp.Body)
 	}
 
-	j.Entry.Emails = emails
+	if len(emails) == 0 {
+		emails = obfuscatedEmailExtractor(doc)
+	}
+
+	j.Entry.Emails = append(j.Entry.Emails, emails...)
+
+	pageURL := resp.URL
+	if pageURL == "" {
+		pageURL = j.GetFullURL()
+	}
+
+	if j.Visited == 1 {
+		j.Entry.WebsiteMeta = extractWebsiteMeta(doc, pageURL)
+	}
+
+	j.Entry.addMessengerLinksFromDoc(doc)
+	j.Entry.addSocialLinksFromDoc(doc)
+	j.Entry.Platforms = appendUnique(j.Entry.Platforms, detectSignatures(resp.Body, bookingPlatforms)...)
+	j.Entry.PaymentProviders = appendUnique(j.Entry.PaymentProviders, detectSignatures(resp.Body, paymentProviders)...)
+
+	vat, registration := extractLegalIDs(visibleText(doc))
+	j.Entry.VATNumbers = appendUnique(j.Entry.VATNumbers, vat...)
+	j.Entry.RegistrationNumbers = appendUnique(j.Entry.RegistrationNumbers, registration...)
+
+	if j.Entry.EcommercePlatform == "" {
+		if found := detectSignatures(resp.Body, ecommercePlatforms); len(found) > 0 {
+			j.Entry.EcommercePlatform = found[0]
+		}
+	}
+
+	if j.Entry.ContactFormURL == "" {
+		if form, ok := findContactForm(doc, pageURL); ok {
+			j.Entry.ContactForm = form
+			j.Entry.ContactFormURL = form.URL
+		}
+	}
+
+	if len(j.Entry.Emails) > 0 || j.Visited >= j.MaxPages {
+		return j.finish()
+	}
+
+	if j.Visited == 1 {
+		// the links of the home page are used if there is no sitemap
+		sitemap := j.child(sitemapURL(pageURL))
+		sitemap.Sitemap = true
+		sitemap.Queue = pageLinks(doc, pageURL)
+
+		j.pending = true
+
+		return nil, []scrapemate.IJob{sitemap}, nil
+	}
+
+	return j.next(j.Queue)
+}
+
+// next continues with the first page of queue, or finishes when it is empty
+func (j *EmailExtractJob) next(queue []string) (any, []scrapemate.IJob, error) {
+	if len(queue) == 0 || j.Visited >= j.MaxPages {
+		return j.finish()
+	}
+
+	child := j.child(queue[0])
+	child.Queue = queue[1:]
+
+	j.pending = true
+
+	return nil, []scrapemate.IJob{child}, nil
+}
+
+func (j *EmailExtractJob) finish() (any, []scrapemate.IJob, error) {
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesCompleted(1)
+	}
 
 	return j.Entry, nil, nil
 }
 
+func (j *EmailExtractJob) child(u string) *EmailExtractJob {
+	child := *j
+
+	child.Job.ID = uuid.New().String()
+	child.Job.URL = u
+	child.Job.Response = scrapemate.Response{}
+	child.Sitemap = false
+	child.Queue = nil
+
+	return &child
+}
+
+func (j *EmailExtractJob) UseInResults() bool {
+	return !j.pending
+}
+
 func (j *EmailExtractJob) ProcessOnFetchError() bool {
 	return true
 }
@@ -99,9 +234,18 @@ func docEmailExtractor(doc *goquery.Document) []string {
 
 	doc.Find("a[href^='mailto:']").Each(func(_ int, s *goquery.Selection) {
 		mailto, exists := s.Attr("href")
-		if exists {
-			value := strings.TrimPrefix(mailto, "mailto:")
-			if email, err := getValidEmail(value); err == nil {
+		if !exists {
+			return
+		}
+
+		// mailto:a@example.com,b@example.com?subject=hello with url encoded characters
+		value, _, _ := strings.Cut(strings.TrimPrefix(mailto, "mailto:"), "?")
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+
+		for _, addr := range strings.Split(value, ",") {
+			if email, err := getValidEmail(addr); err == nil {
 				if !seen[email] {
 					emails = append(emails, email)
 					seen[email] = true
@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"iter"
+	"log/slog"
 	"math"
 	"net/url"
 	"runtime/debug"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	olc "github.com/google/open-location-code/go"
 )
 
 type Image struct {
@@ -20,6 +24,9 @@ type Image struct {
 type LinkSource struct {
 	Link   string `json:"link"`
 	Source string `json:"source"`
+	// Provider is the name of the reservation or ordering platform of the
+	// link, e.g. OpenTable or Uber Eats
+	Provider string `json:"provider,omitempty"`
 }
 
 type Owner struct {
@@ -55,45 +62,105 @@ type Review struct {
 	Description    string
 	Images         []string
 	When           string
+	OwnerResponse  string
+	// OwnerResponseWhen is the date of the owner response, like When
+	OwnerResponseWhen string
+	// Language is the language the review was written in, e.g. en
+	Language string
+	// Translation is the description translated by Google in the language of
+	// the request, empty when the review is written in it
+	Translation string
 }
 
 type Entry struct {
-	ID         string              `json:"input_id"`
-	Link       string              `json:"link"`
-	Cid        string              `json:"cid"`
-	Title      string              `json:"title"`
-	Categories []string            `json:"categories"`
-	Category   string              `json:"category"`
-	Address    string              `json:"address"`
-	OpenHours  map[string][]string `json:"open_hours"`
+	ID         string   `json:"input_id"`
+	Link       string   `json:"link"`
+	Cid        string   `json:"cid"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+	Category   string   `json:"category"`
+	// CanonicalCategory and CanonicalCategories are the categories of the
+	// canonical taxonomy, e.g. restaurant for Ristorante, set by the taxonomy package
+	CanonicalCategory   string              `json:"canonical_category"`
+	CanonicalCategories []string            `json:"canonical_categories"`
+	Address             string              `json:"address"`
+	OpenHours           map[string][]string `json:"open_hours"`
 	// PopularTImes is a map with keys the days of the week
 	// and value is a map with key the hour and value the traffic in that time
 	PopularTimes        map[string]map[int]int `json:"popular_times"`
 	WebSite             string                 `json:"web_site"`
 	Phone               string                 `json:"phone"`
 	PlusCode            string                 `json:"plus_code"`
+	GlobalPlusCode      string                 `json:"global_plus_code"`
 	ReviewCount         int                    `json:"review_count"`
 	ReviewRating        float64                `json:"review_rating"`
 	ReviewsPerRating    map[int]int            `json:"reviews_per_rating"`
 	Latitude            float64                `json:"latitude"`
 	Longtitude          float64                `json:"longtitude"`
+	Geocoded            bool                   `json:"geocoded"`
+	DistanceM           float64                `json:"distance_m"`
+	Bearing             float64                `json:"bearing"`
 	Status              string                 `json:"status"`
+	BusinessStatus      string                 `json:"business_status"`
 	Description         string                 `json:"description"`
 	ReviewsLink         string                 `json:"reviews_link"`
 	Thumbnail           string                 `json:"thumbnail"`
 	Timezone            string                 `json:"timezone"`
+	UTCOffset           string                 `json:"utc_offset"`
+	OpenHoursUTC        map[string][]string    `json:"open_hours_utc"`
+	Schedule            []DayHours             `json:"schedule"`
 	PriceRange          string                 `json:"price_range"`
+	PriceLevel          int                    `json:"price_level"`
 	DataID              string                 `json:"data_id"`
+	Kgmid               string                 `json:"kgmid"`
 	Images              []Image                `json:"images"`
+	Photos              []Photo                `json:"photos"`
 	Reservations        []LinkSource           `json:"reservations"`
 	OrderOnline         []LinkSource           `json:"order_online"`
-	Menu                LinkSource             `json:"menu"`
+	Menu                Menu                   `json:"menu"`
 	Owner               Owner                  `json:"owner"`
 	CompleteAddress     Address                `json:"complete_address"`
 	About               []About                `json:"about"`
+	StarClass           int                    `json:"star_class"`
+	Amenities           []string               `json:"amenities"`
+	HotelRates          []HotelRate            `json:"hotel_rates"`
+	HotelAmenities      []string               `json:"hotel_amenities"`
+	Attributes          map[string]bool        `json:"attributes"`
+	ServiceOptions      ServiceOptions         `json:"service_options"`
+	FuelPrices          []FuelPrice            `json:"fuel_prices"`
+	EVCharging          EVCharging             `json:"ev_charging"`
+	Parking             []string               `json:"parking"`
+	NearbyTransit       []TransitStop          `json:"nearby_transit"`
 	UserReviews         []Review               `json:"user_reviews"`
 	UserReviewsExtended []Review               `json:"user_reviews_extended"`
+	Posts               []Post                 `json:"posts"`
+	Products            []Product              `json:"products"`
+	Questions           []Question             `json:"questions"`
 	Emails              []string               `json:"emails"`
+	ContactFormURL      string                 `json:"contact_form_url"`
+	ContactForm         ContactForm            `json:"contact_form"`
+	WhatsApp            []string               `json:"whatsapp"`
+	Messenger           []string               `json:"messenger"`
+	Telegram            []string               `json:"telegram"`
+	Facebook            string                 `json:"facebook"`
+	Instagram           string                 `json:"instagram"`
+	LinkedIn            string                 `json:"linkedin"`
+	Twitter             string                 `json:"twitter"`
+	TikTok              string                 `json:"tiktok"`
+	YouTube             string                 `json:"youtube"`
+	WebsiteMeta         WebsiteMeta            `json:"website_meta"`
+	Platforms           []string               `json:"platforms"`
+	EcommercePlatform   string                 `json:"ecommerce_platform"`
+	PaymentProviders    []string               `json:"payment_providers"`
+	VATNumbers          []string               `json:"vat_numbers"`
+	RegistrationNumbers []string               `json:"registration_numbers"`
+	WebsiteStatus       string                 `json:"website_status"`
+	WebsiteCheck        WebsiteCheck           `json:"website_check"`
+	Tags                []string               `json:"tags"`
+	Language            string                 `json:"language"`
+	Localized           map[string]Localized   `json:"localized"`
+	MergedFrom          []MergedSource         `json:"merged_from"`
+	Metadata            map[string]string      `json:"metadata"`
 	Raw                 []any                  `json:"raw"`
 }
 
@@ -118,6 +185,26 @@ func (e *Entry) haversineDistance(lat, lon float64) float64 {
 	return R * c
 }
 
+// bearing returns the initial compass bearing in degrees (0-360, 0 is north)
+// from lat, lon to the entry.
+func (e *Entry) bearing(lat, lon float64) float64 {
+	clat := lat * math.Pi / 180
+	elat := e.Latitude * math.Pi / 180
+	dlon := (e.Longtitude - lon) * math.Pi / 180
+
+	y := math.Sin(dlon) * math.Cos(elat)
+	x := math.Cos(clat)*math.Sin(elat) - math.Sin(clat)*math.Cos(elat)*math.Cos(dlon)
+
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// setDistance sets the distance in meters and the bearing in degrees of the
+// entry from the center of its search
+func (e *Entry) setDistance(lat, lon float64) {
+	e.DistanceM = math.Round(e.haversineDistance(lat, lon)*100) / 100
+	e.Bearing = math.Round(e.bearing(lat, lon)*10) / 10
+}
+
 func (e *Entry) isWithinRadius(lat, lon, radius float64) bool {
 	distance := e.haversineDistance(lat, lon)
 
@@ -144,6 +231,19 @@ func (e *Entry) IsWebsiteValidForEmail() bool {
 	return true
 }
 
+// Key identifies the place of the entry across runs: its cid, its data id
+// when the cid is missing, or its link
+func (e *Entry) Key() string {
+	switch {
+	case e.Cid != "":
+		return e.Cid
+	case e.DataID != "":
+		return e.DataID
+	default:
+		return e.Link
+	}
+}
+
 func (e *Entry) Validate() error {
 	if e.Title == "" {
 		return fmt.Errorf("title is empty")
@@ -191,6 +291,65 @@ func (e *Entry) CsvHeaders() []string {
 		"user_reviews",
 		"user_reviews_extended",
 		"emails",
+		"tags",
+		"utc_offset",
+		"open_hours_utc",
+		"distance_m",
+		"bearing",
+		"contact_form_url",
+		"website_meta",
+		"whatsapp",
+		"messenger",
+		"telegram",
+		"website_status",
+		"website_final_url",
+		"certificate_expires",
+		"platforms",
+		"ecommerce_platform",
+		"payment_providers",
+		"vat_numbers",
+		"registration_numbers",
+		"posts",
+		"last_post_date",
+		"products",
+		"star_class",
+		"amenities",
+		"fuel_prices",
+		"ev_charging",
+		"parking",
+		"nearby_transit",
+		"photos",
+		"questions",
+		"facebook",
+		"instagram",
+		"linkedin",
+		"twitter",
+		"tiktok",
+		"youtube",
+		"schedule",
+		"business_status",
+		"price_level",
+		"attributes",
+		"service_options",
+		"borough",
+		"street",
+		"city",
+		"postal_code",
+		"state",
+		"country",
+		"geocoded",
+		"language",
+		"localized",
+		"metadata",
+		"global_plus_code",
+		"kgmid",
+		"reservation_providers",
+		"order_online_providers",
+		"hotel_rates",
+		"hotel_amenities",
+		"merged_from",
+		"canonical_category",
+		"canonical_categories",
 		"raw",
 	}
 }
@@ -230,6 +389,65 @@ func (e *Entry) CsvRow() []string {
 		stringify(e.UserReviews),
 		stringify(e.UserReviewsExtended),
 		stringSliceToString(e.Emails),
+		stringSliceToString(e.Tags),
+		e.UTCOffset,
+		stringify(e.OpenHoursUTC),
+		stringify(e.DistanceM),
+		stringify(e.Bearing),
+		e.ContactFormURL,
+		stringify(e.WebsiteMeta),
+		stringSliceToString(e.WhatsApp),
+		stringSliceToString(e.Messenger),
+		stringSliceToString(e.Telegram),
+		e.WebsiteStatus,
+		e.WebsiteCheck.FinalURL,
+		formatDate(e.WebsiteCheck.Certificate.Expires),
+		stringSliceToString(e.Platforms),
+		e.EcommercePlatform,
+		stringSliceToString(e.PaymentProviders),
+		stringSliceToString(e.VATNumbers),
+		stringSliceToString(e.RegistrationNumbers),
+		stringify(e.Posts),
+		lastPostDate(e.Posts),
+		stringify(e.Products),
+		stringify(e.StarClass),
+		stringSliceToString(e.Amenities),
+		stringify(e.FuelPrices),
+		stringify(e.EVCharging),
+		stringSliceToString(e.Parking),
+		stringify(e.NearbyTransit),
+		stringify(e.Photos),
+		stringify(e.Questions),
+		e.Facebook,
+		e.Instagram,
+		e.LinkedIn,
+		e.Twitter,
+		e.TikTok,
+		e.YouTube,
+		stringify(e.Schedule),
+		e.BusinessStatus,
+		stringify(e.PriceLevel),
+		stringify(e.Attributes),
+		stringify(e.ServiceOptions),
+		e.CompleteAddress.Borough,
+		e.CompleteAddress.Street,
+		e.CompleteAddress.City,
+		e.CompleteAddress.PostalCode,
+		e.CompleteAddress.State,
+		e.CompleteAddress.Country,
+		stringify(e.Geocoded),
+		e.Language,
+		stringify(e.Localized),
+		stringify(e.Metadata),
+		e.GlobalPlusCode,
+		e.Kgmid,
+		stringSliceToString(linkProviders(e.Reservations)),
+		stringSliceToString(linkProviders(e.OrderOnline)),
+		stringify(e.HotelRates),
+		stringSliceToString(e.HotelAmenities),
+		stringify(e.MergedFrom),
+		e.CanonicalCategory,
+		stringSliceToString(e.CanonicalCategories),
 		stringify(e.Raw),
 	}
 }
@@ -254,7 +472,7 @@ func extractReviews(data []byte) []Review {
 
 	var jd []any
 	if err := json.Unmarshal(data, &jd); err != nil {
-		fmt.Printf("Error unmarshalling JSON: %v\n", err)
+		slog.Warn("cannot unmarshal the reviews", "error", err)
 		return nil
 	}
 
@@ -317,21 +535,31 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		strings.TrimPrefix(getNthElementAndCast[string](darray, 18), entry.Title+","),
 	)
 	entry.OpenHours = getHours(darray)
+	entry.Schedule = entry.weekSchedule()
 	entry.PopularTimes = getPopularTimes(darray)
 	entry.WebSite = extractActualURL(getNthElementAndCast[string](darray, 7, 0))
 	entry.Phone = getNthElementAndCast[string](darray, 178, 0, 0)
 	entry.PlusCode = getNthElementAndCast[string](darray, 183, 2, 2, 0)
+	entry.GlobalPlusCode = getNthElementAndCast[string](darray, 183, 2, 1, 0)
 	entry.ReviewRating = getNthElementAndCast[float64](darray, 4, 7)
 	entry.Latitude = getNthElementAndCast[float64](darray, 9, 2)
 	entry.Longtitude = getNthElementAndCast[float64](darray, 9, 3)
 	entry.Cid = getNthElementAndCast[string](jd, 25, 3, 0, 13, 0, 0, 1)
 	entry.Status = getNthElementAndCast[string](darray, 34, 4, 4)
+	entry.BusinessStatus = businessStatus(entry.Status)
 	entry.Description = getNthElementAndCast[string](darray, 32, 1, 1)
 	entry.ReviewsLink = getNthElementAndCast[string](darray, 4, 3, 0)
 	entry.Thumbnail = getNthElementAndCast[string](darray, 72, 0, 1, 6, 0)
 	entry.Timezone = getNthElementAndCast[string](darray, 30)
 	entry.PriceRange = getNthElementAndCast[string](darray, 4, 2)
+	entry.PriceLevel = priceLevel(entry.PriceRange, "")
 	entry.DataID = getNthElementAndCast[string](darray, 10)
+	entry.Kgmid = getNthElementAndCast[string](darray, 89)
+
+	// the payload has no Plus Code for some places, e.g. without an address
+	if entry.GlobalPlusCode == "" && (entry.Latitude != 0 || entry.Longtitude != 0) {
+		entry.GlobalPlusCode = olc.Encode(entry.Latitude, entry.Longtitude, plusCodeLength)
+	}
 
 	items := getLinkSource(getLinkSourceParams{
 		arr:    getNthElementAndCast[[]any](darray, 171, 0),
@@ -348,10 +576,13 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		}
 	}
 
+	entry.setPhotos(darray)
+
 	entry.Reservations = getLinkSource(getLinkSourceParams{
-		arr:    getNthElementAndCast[[]any](darray, 46),
-		link:   []int{0},
-		source: []int{1},
+		arr:      getNthElementAndCast[[]any](darray, 46),
+		link:     []int{0},
+		source:   []int{1},
+		provider: true,
 	})
 
 	orderOnlineI := getNthElementAndCast[[]any](darray, 75, 0, 1, 2)
@@ -361,12 +592,14 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 	}
 
 	entry.OrderOnline = getLinkSource(getLinkSourceParams{
-		arr:    orderOnlineI,
-		link:   []int{1, 2, 0},
-		source: []int{0, 0},
+		arr:      orderOnlineI,
+		link:     []int{1, 2, 0},
+		source:   []int{0, 0},
+		provider: true,
+		name:     []int{0, 2, 1},
 	})
 
-	entry.Menu = LinkSource{
+	entry.Menu = Menu{
 		Link:   getNthElementAndCast[string](darray, 38, 0),
 		Source: getNthElementAndCast[string](darray, 38, 1),
 	}
@@ -380,39 +613,14 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		entry.Owner.Link = fmt.Sprintf("https://www.google.com/maps/contrib/%s", entry.Owner.ID)
 	}
 
-	entry.CompleteAddress = Address{
-		Borough:    getNthElementAndCast[string](darray, 183, 1, 0),
-		Street:     getNthElementAndCast[string](darray, 183, 1, 1),
-		City:       getNthElementAndCast[string](darray, 183, 1, 3),
-		PostalCode: getNthElementAndCast[string](darray, 183, 1, 4),
-		State:      getNthElementAndCast[string](darray, 183, 1, 5),
-		Country:    getNthElementAndCast[string](darray, 183, 1, 6),
-	}
-
-	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
-
-	for i := range aboutI {
-		el := getNthElementAndCast[[]any](aboutI, i)
-		about := About{
-			ID:   getNthElementAndCast[string](el, 0),
-			Name: getNthElementAndCast[string](el, 1),
-		}
-
-		optsI := getNthElementAndCast[[]any](el, 2)
+	entry.setCompleteAddress(darray)
 
-		for j := range optsI {
-			opt := Option{
-				Enabled: (getNthElementAndCast[float64](optsI, j, 2, 1, 0, 0)) == 1,
-				Name:    getNthElementAndCast[string](optsI, j, 1),
-			}
+	entry.About = getAbout(darray)
 
-			if opt.Name != "" {
-				about.Options = append(about.Options, opt)
-			}
-		}
-
-		entry.About = append(entry.About, about)
-	}
+	entry.setLodgingDetails(darray)
+	entry.setParking()
+	entry.setAttributes()
+	entry.setServiceOptions()
 
 	entry.ReviewsPerRating = map[int]int{
 		1: int(getNthElementAndCast[float64](darray, 175, 3, 0)),
@@ -425,6 +633,10 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 	reviewsI := getNthElementAndCast[[]any](darray, 175, 9, 0, 0)
 	entry.UserReviews = make([]Review, 0, len(reviewsI))
 
+	entry.addMessengerLinksFromJSON(darray)
+	// the website of many businesses is their social media page
+	entry.addSocialLink(entry.WebSite)
+
 	return entry, nil
 }
 
@@ -451,8 +663,15 @@ func parseReviews(reviewsI []any) []Review {
 
 				return fmt.Sprintf("%v-%v-%v", time[0], time[1], time[2])
 			}(),
-			Rating:      int(getNthElementAndCast[float64](el, 2, 0, 0)),
-			Description: getNthElementAndCast[string](el, 2, 15, 0, 0),
+			Rating:        int(getNthElementAndCast[float64](el, 2, 0, 0)),
+			Description:   getNthElementAndCast[string](el, 2, 15, 0, 0),
+			OwnerResponse: getNthElementAndCast[string](el, 3, 14, 0, 0),
+			Language:      getNthElementAndCast[string](el, 2, 14, 0),
+			Translation:   getNthElementAndCast[string](el, 2, 15, 1, 0),
+		}
+
+		if review.OwnerResponse != "" {
+			review.OwnerResponseWhen = reviewDate(getNthElementAndCast[float64](el, 3, 1))
 		}
 
 		if review.Name == "" {
@@ -474,10 +693,24 @@ func parseReviews(reviewsI []any) []Review {
 	return ans
 }
 
+// reviewDate returns the date of a timestamp in microseconds of a review in the
+// format of When, or an empty string without timestamp
+func reviewDate(usec float64) string {
+	if usec <= 0 {
+		return ""
+	}
+
+	return time.UnixMicro(int64(usec)).UTC().Format("2006-1-2")
+}
+
 type getLinkSourceParams struct {
 	arr    []any
 	source []int
 	link   []int
+	// provider identifies the providers of the links, from the name shown by
+	// Google at name when there is one
+	provider bool
+	name     []int
 }
 
 func getLinkSource(params getLinkSourceParams) []LinkSource {
@@ -491,6 +724,15 @@ func getLinkSource(params getLinkSourceParams) []LinkSource {
 			Link:   getNthElementAndCast[string](item, params.link...),
 		}
 		if el.Link != "" && el.Source != "" {
+			if params.provider {
+				var name string
+				if len(params.name) > 0 {
+					name = getNthElementAndCast[string](item, params.name...)
+				}
+
+				el.Provider = linkProvider(el.Link, name, el.Source)
+			}
+
 			result = append(result, el)
 		}
 	}
@@ -498,6 +740,38 @@ func getLinkSource(params getLinkSourceParams) []LinkSource {
 	return result
 }
 
+// getAbout returns the sections of the About tab and their options
+func getAbout(darray []any) []About {
+	var ans []About
+
+	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
+
+	for i := range aboutI {
+		el := getNthElementAndCast[[]any](aboutI, i)
+		about := About{
+			ID:   getNthElementAndCast[string](el, 0),
+			Name: getNthElementAndCast[string](el, 1),
+		}
+
+		optsI := getNthElementAndCast[[]any](el, 2)
+
+		for j := range optsI {
+			opt := Option{
+				Enabled: (getNthElementAndCast[float64](optsI, j, 2, 1, 0, 0)) == 1,
+				Name:    getNthElementAndCast[string](optsI, j, 1),
+			}
+
+			if opt.Name != "" {
+				about.Options = append(about.Options, opt)
+			}
+		}
+
+		ans = append(ans, about)
+	}
+
+	return ans
+}
+
 //nolint:gomnd // it's ok, I need the indexes
 func getHours(darray []any) map[string][]string {
 	// Try new structure first (as of Nov 2025) - darray[203][0]
@@ -577,13 +851,17 @@ func getPopularTimes(darray []any) map[string]map[int]int {
 		7: "Sunday",
 	}
 
+	// a malformed day or hour is skipped, the rest of the histogram is kept
 	for ii := range items {
 		item, ok := items[ii].([]any)
 		if !ok {
-			return nil
+			continue
 		}
 
-		day := int(getNthElementAndCast[float64](item, 0))
+		name, ok := dayOfWeek[int(getNthElementAndCast[float64](item, 0))]
+		if !ok {
+			continue
+		}
 
 		timesI := getNthElementAndCast[[]any](item, 1)
 
@@ -591,24 +869,21 @@ func getPopularTimes(darray []any) map[string]map[int]int {
 
 		for i := range timesI {
 			t, ok := timesI[i].([]any)
-			if !ok {
-				return nil
+			if !ok || len(t) < 2 {
+				continue
 			}
 
-			v, ok := t[1].(float64)
-			if !ok {
-				return nil
-			}
+			h, okH := t[0].(float64)
+			v, okV := t[1].(float64)
 
-			h, ok := t[0].(float64)
-			if !ok {
-				return nil
+			if !okH || !okV {
+				continue
 			}
 
 			times[int(h)] = int(v)
 		}
 
-		popularTimes[dayOfWeek[day]] = times
+		popularTimes[name] = times
 	}
 
 	return popularTimes
@@ -718,6 +993,8 @@ func filterAndSortEntriesWithinRadius(entries []*Entry, lat, lon, radius float64
 		for _, entry := range entries {
 			distance := entry.haversineDistance(lat, lon)
 			if distance <= radius {
+				entry.setDistance(lat, lon)
+
 				if !yield(EntryWithDistance{Entry: entry, Distance: distance}) {
 					return
 				}
@@ -748,3 +1025,12 @@ func filterAndSortEntriesWithinRadius(entries []*Entry, lat, lon, radius float64
 
 	return slices.Collect(iter.Seq[*Entry](resultIterator))
 }
+
+// formatDate formats t as YYYY-MM-DD, or returns an empty string for the zero time
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.DateOnly)
+}
@@ -26,6 +26,8 @@ func createGoQueryFromFile(t *testing.T, path string) *goquery.Document {
 }
 
 func Test_EntryFromJSON(t *testing.T) {
+	hours := []gmaps.HoursInterval{{Open: "12:30", Close: "22:00"}}
+
 	expected := gmaps.Entry{
 		Link:       "https://www.google.com/maps/place/Kipriakon/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47!10m1!1e1",
 		Title:      "Kipriakon",
@@ -41,20 +43,33 @@ func Test_EntryFromJSON(t *testing.T) {
 			"Saturday":  {"12:30–10 pm"},
 			"Sunday":    {"12:30–10 pm"},
 		},
-		WebSite:      "",
-		Phone:        "25 101555",
-		PlusCode:     "M2CR+6X Limassol",
-		ReviewCount:  396,
-		ReviewRating: 4.2,
-		Latitude:     34.670595399999996,
-		Longtitude:   33.042456699999995,
-		Cid:          "16519582940102929223",
-		Status:       "Closed ⋅ Opens 12:30\u202fpm Tue",
-		ReviewsLink:  "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
-		Thumbnail:    "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
-		Timezone:     "Asia/Nicosia",
-		PriceRange:   "€€",
-		DataID:       "0x14e732fd76f0d90d:0xe5415928d6702b47",
+		BusinessStatus: gmaps.BusinessOperational,
+		Schedule: []gmaps.DayHours{
+			{Day: "Monday", Intervals: hours},
+			{Day: "Tuesday", Intervals: hours},
+			{Day: "Wednesday", Intervals: hours},
+			{Day: "Thursday", Intervals: hours},
+			{Day: "Friday", Intervals: hours},
+			{Day: "Saturday", Intervals: hours},
+			{Day: "Sunday", Intervals: hours},
+		},
+		WebSite:        "",
+		Phone:          "25 101555",
+		PlusCode:       "M2CR+6X Limassol",
+		GlobalPlusCode: "8G6MM2CR+6X",
+		ReviewCount:    396,
+		ReviewRating:   4.2,
+		Latitude:       34.670595399999996,
+		Longtitude:     33.042456699999995,
+		Cid:            "16519582940102929223",
+		Status:         "Closed ⋅ Opens 12:30\u202fpm Tue",
+		ReviewsLink:    "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
+		Thumbnail:      "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
+		Timezone:       "Asia/Nicosia",
+		PriceRange:     "€€",
+		PriceLevel:     2,
+		DataID:         "0x14e732fd76f0d90d:0xe5415928d6702b47",
+		Kgmid:          "/g/11c54_9hlz",
 		Images: []gmaps.Image{
 			{
 				Title: "All",
@@ -97,16 +112,95 @@ func Test_EntryFromJSON(t *testing.T) {
 				Image: "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
 			},
 		},
+		Photos: []gmaps.Photo{
+			{
+				ID:       "AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F",
+				Category: "All",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w298-h298-k-no",
+				Width:    2048,
+				Height:   2048,
+			},
+			{
+				ID:       "AF1QipNgMqyaQs2MqH1oiGC44eDcvudurxQfNb2RuDsd",
+				Category: "Latest",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipNgMqyaQs2MqH1oiGC44eDcvudurxQfNb2RuDsd=w224-h298-k-no",
+				Width:    3000,
+				Height:   4000,
+			},
+			{
+				ID:       "AF1QipPZbq8v8K8RZfvL6gZ_4Dw6qwNJ_MUxxOOfBo7h",
+				Category: "Videos",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipPZbq8v8K8RZfvL6gZ_4Dw6qwNJ_MUxxOOfBo7h=w224-h398-k-no",
+				Width:    720,
+				Height:   1280,
+			},
+			{
+				ID:       "AF1QipNhoFtPcaLCIhdN3GhlJ6sQIvdhaESnRG8nyeC8",
+				Category: "Menu",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipNhoFtPcaLCIhdN3GhlJ6sQIvdhaESnRG8nyeC8=w397-h298-k-no",
+				Width:    2048,
+				Height:   1536,
+			},
+			{
+				ID:       "AF1QipMbu-iiWkE4DsXx3aI7nGaqyXJKbBYCrBXvzOnu",
+				Category: "Food & drink",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipMbu-iiWkE4DsXx3aI7nGaqyXJKbBYCrBXvzOnu=w298-h298-k-no",
+				Width:    2048,
+				Height:   2048,
+			},
+			{
+				ID:       "AF1QipOGg_vrD4bzkOre5Ly6CFXuO3YCOGfFxQ-EiEkW",
+				Category: "Vibe",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipOGg_vrD4bzkOre5Ly6CFXuO3YCOGfFxQ-EiEkW=w224-h398-k-no",
+				Width:    2268,
+				Height:   4032,
+			},
+			{
+				ID:       "AF1QipOziHd2hqM1jnK9KfCGf1zVhcOrx8Bj7VdJXj0",
+				Category: "Fried green tomatoes",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipOziHd2hqM1jnK9KfCGf1zVhcOrx8Bj7VdJXj0=w397-h298-k-no",
+				Width:    4032,
+				Height:   3024,
+			},
+			{
+				ID:       "AF1QipNJyq7nAlKtsxxbNy4PHUZOhJ0k7HPP8tTAlwcV",
+				Category: "French fries",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipNJyq7nAlKtsxxbNy4PHUZOhJ0k7HPP8tTAlwcV=w397-h298-k-no",
+				Width:    4032,
+				Height:   3024,
+			},
+			{
+				ID:       "AF1QipNRE2R5k13zT-0WG4b6XOD_BES9-nMK04hlCMVV",
+				Category: "By owner",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipNRE2R5k13zT-0WG4b6XOD_BES9-nMK04hlCMVV=w298-h298-k-no",
+				Width:    2048,
+				Height:   2048,
+			},
+			{
+				ID:       "AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL",
+				Category: "Street View & 360°",
+				URL:      "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
+				Width:    7200,
+				Height:   3600,
+			},
+		},
 		OrderOnline: []gmaps.LinkSource{
 			{
-				Link:   "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
-				Source: "foody.com.cy",
+				Link:     "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
+				Source:   "foody.com.cy",
+				Provider: "eFood",
 			},
 			{
-				Link:   "https://wolt.com/en/cyp/limassol/restaurant/kypriakon?utm_source=googlemapreserved&utm_campaign=kypriakon",
-				Source: "wolt.com",
+				Link:     "https://wolt.com/en/cyp/limassol/restaurant/kypriakon?utm_source=googlemapreserved&utm_campaign=kypriakon",
+				Source:   "wolt.com",
+				Provider: "Wolt",
 			},
 		},
+		ServiceOptions: gmaps.ServiceOptions{
+			Delivery: true,
+			Takeout:  true,
+			DineIn:   true,
+		},
 		Owner: gmaps.Owner{
 			ID:   "102769814432182832009",
 			Name: "Kipriakon (Owner)",
@@ -146,6 +240,11 @@ func Test_EntryFromJSON(t *testing.T) {
 
 	entry.About = nil
 
+	require.True(t, entry.Attributes["wheelchair_accessible_entrance"])
+	require.True(t, entry.Attributes["outdoor_seating"])
+
+	entry.Attributes = nil
+
 	require.Len(t, entry.PopularTimes, 7)
 
 	for k, v := range entry.PopularTimes {
@@ -216,3 +315,23 @@ func Test_EntryFromJsonC(t *testing.T) {
 		fmt.Printf("%+v\n", entry)
 	}
 }
+
+func Test_EntryCsvColumns(t *testing.T) {
+	// the columns of the first releases keep their position, the new ones
+	// are added before raw
+	first := []string{
+		"input_id", "link", "title", "category", "address", "open_hours", "popular_times", "website", "phone",
+		"plus_code", "review_count", "review_rating", "reviews_per_rating", "latitude", "longitude", "cid", "status",
+		"descriptions", "reviews_link", "thumbnail", "timezone", "price_range", "data_id", "images", "reservations",
+		"order_online", "menu", "owner", "complete_address", "about", "user_reviews", "user_reviews_extended", "emails",
+	}
+
+	var entry gmaps.Entry
+
+	headers := entry.CsvHeaders()
+
+	require.Equal(t, first, headers[:len(first)])
+	require.Equal(t, "raw", headers[len(headers)-1])
+	require.Len(t, entry.CsvRow(), len(headers))
+	require.Equal(t, []string{"canonical_category", "canonical_categories"}, headers[len(headers)-3:len(headers)-1])
+}
@@ -0,0 +1,124 @@
+package gmaps
+
+import (
+	"encoding/gob"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// EVCharging are the details of the EV charging block of a charging station
+type EVCharging struct {
+	Operator string      `json:"operator"`
+	Chargers []EVCharger `json:"chargers"`
+}
+
+// EVCharger is a group of chargers with the same connector and power
+type EVCharger struct {
+	Connector string  `json:"connector"`
+	Count     int     `json:"count"`
+	Available int     `json:"available"`
+	PowerKW   float64 `json:"power_kw"`
+}
+
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register([]string{})
+}
+
+var (
+	connectorRegex   = regexp.MustCompile(`(?i)^(ccs(?:\s?(?:combo\s?)?[12])?(?:\s?\(type [12]\))?|chademo|type [12]|j1772|mennekes|tesla|nacs|gb/t|schuko|wall)(?:$|\W)`)
+	powerRegex       = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s?kw\b`)
+	availableRegex   = regexp.MustCompile(`(?i)(\d+)\s?/\s?(\d+)(?:\s?available)?`)
+	countRegex       = regexp.MustCompile(`(?i)^(\d+)(?:\s?(?:chargers?|plugs?|ports?|stalls?))?$`)
+	evOperatorRegex  = regexp.MustCompile(`(?i)^(?:operated by|operator|network)\s*:?\s*(.+)$`)
+	evChargerNetwork = []string{
+		"Tesla", "ChargePoint", "Electrify America", "EVgo", "Blink", "Ionity", "Fastned", "Allego",
+		"EnBW", "Shell Recharge", "BP Pulse", "Pod Point", "Instavolt", "Osprey", "Gridserve", "EWE Go",
+		"Aral pulse", "TotalEnergies", "Electra", "Zunder", "Iberdrola", "Endesa X", "Enel X", "Be Charge",
+		"Tesla Supercharger", "FLO", "Petro-Canada", "Circuit électrique", "Ampol", "Evie", "Chargefox",
+	}
+	// evNetworkRegexes match the names of evChargerNetwork as words, in the same order
+	evNetworkRegexes = wordRegexes(evChargerNetwork)
+)
+
+// fetchEVChargingTexts returns the texts of the EV charging block of the overview.
+// They are parsed with the title of the place, see parseEVCharging.
+func fetchEVChargingTexts(page playwright.Page) ([]string, error) {
+	return sectionTexts(page, `^(ev charging|electric vehicle charging|charging|chargers)$`)
+}
+
+// parseEVCharging groups the texts of the EV charging block by connector:
+// a connector starts a group, the power and the counts after it belong to it.
+// The operator is the one in the block or the charging network in the title.
+// The power is parsed in the language hl.
+func parseEVCharging(texts []string, title, hl string) EVCharging {
+	var ans EVCharging
+
+	var current *EVCharger
+
+	for _, t := range texts {
+		if m := evOperatorRegex.FindStringSubmatch(t); m != nil {
+			ans.Operator = strings.TrimSpace(m[1])
+
+			continue
+		}
+
+		if m := connectorRegex.FindStringSubmatch(t); m != nil {
+			ans.Chargers = append(ans.Chargers, EVCharger{Connector: m[1]})
+			current = &ans.Chargers[len(ans.Chargers)-1]
+			// the connector, power and count may be in the same text: "CCS · 150 kW · 4"
+			t = strings.TrimSpace(t[len(m[0]):])
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if m := powerRegex.FindStringSubmatch(t); m != nil {
+			current.PowerKW, _ = parseNumber(m[1], hl)
+			t = strings.TrimSpace(strings.Replace(t, m[0], "", 1))
+		}
+
+		t = strings.Trim(t, " ·•-")
+
+		if m := availableRegex.FindStringSubmatch(t); m != nil {
+			current.Available, _ = strconv.Atoi(m[1])
+			current.Count, _ = strconv.Atoi(m[2])
+		} else if m := countRegex.FindStringSubmatch(t); m != nil {
+			current.Count, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if ans.Operator == "" && len(ans.Chargers) > 0 {
+		ans.Operator = evNetwork(title)
+	}
+
+	return ans
+}
+
+// evNetwork returns the longest charging network name in the title
+func evNetwork(title string) string {
+	var ans string
+
+	for i, n := range evChargerNetwork {
+		if len(n) > len(ans) && evNetworkRegexes[i].MatchString(title) {
+			ans = n
+		}
+	}
+
+	return ans
+}
+
+// wordRegexes returns the case insensitive regexps matching the names as words
+func wordRegexes(names []string) []*regexp.Regexp {
+	ans := make([]*regexp.Regexp, len(names))
+
+	for i, n := range names {
+		ans[i] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(n) + `\b`)
+	}
+
+	return ans
+}
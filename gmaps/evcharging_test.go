@@ -0,0 +1,74 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_ParseEVCharging(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		title string
+		hl    string
+		want  gmaps.EVCharging
+	}{
+		{
+			name:  "connectors with their power and counts",
+			texts: []string{"CCS", "150 kW", "2/4 available", "CHAdeMO", "50 kW", "1 charger"},
+			title: "Ionity Brennerautobahn",
+			hl:    "en",
+			want: gmaps.EVCharging{
+				Operator: "Ionity",
+				Chargers: []gmaps.EVCharger{
+					{Connector: "CCS", Count: 4, Available: 2, PowerKW: 150},
+					{Connector: "CHAdeMO", Count: 1, PowerKW: 50},
+				},
+			},
+		},
+		{
+			name:  "connector, power and count in one text",
+			texts: []string{"Type 2 · 22 kW · 6", "Operated by: Allego"},
+			title: "Parking Centrum",
+			hl:    "en",
+			want: gmaps.EVCharging{
+				Operator: "Allego",
+				Chargers: []gmaps.EVCharger{{Connector: "Type 2", Count: 6, PowerKW: 22}},
+			},
+		},
+		{
+			name:  "power in the language of the place",
+			texts: []string{"CCS", "7,4 kW"},
+			title: "Stadtwerke",
+			hl:    "de",
+			want: gmaps.EVCharging{
+				Chargers: []gmaps.EVCharger{{Connector: "CCS", PowerKW: 7.4}},
+			},
+		},
+		{
+			name:  "longest network of the title",
+			texts: []string{"Tesla", "250 kW", "8 stalls"},
+			title: "Tesla Supercharger Gilroy",
+			hl:    "en",
+			want: gmaps.EVCharging{
+				Operator: "Tesla Supercharger",
+				Chargers: []gmaps.EVCharger{{Connector: "Tesla", Count: 8, PowerKW: 250}},
+			},
+		},
+		{
+			name:  "texts before the connectors",
+			texts: []string{"150 kW", "4 chargers"},
+			title: "Ionity",
+			hl:    "en",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, gmaps.ParseEVCharging(tc.texts, tc.title, tc.hl))
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package gmaps
+
+// ParseFuelPrices exposes parseFuelPrices to the tests
+var ParseFuelPrices = parseFuelPrices
+
+// ParseEVCharging exposes parseEVCharging to the tests
+var ParseEVCharging = parseEVCharging
+
+// ExtractLegalIDs exposes extractLegalIDs to the tests
+var ExtractLegalIDs = extractLegalIDs
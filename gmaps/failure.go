@@ -0,0 +1,147 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+var (
+	// ErrEmptyBody is the error of the responses without a body
+	ErrEmptyBody = errors.New("empty response body")
+	// ErrParse is the error of the responses that could not be parsed
+	ErrParse = errors.New("cannot parse the response")
+)
+
+// statusError is the error of a response refused by the check of the job
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status code %d", int(e))
+}
+
+// jobFailure is the last error of a job, of its last fetch or of its
+// processing, the cause of its failure when it fails
+type jobFailure struct {
+	err error
+	// done is set when the last fetch of the job was processed without error
+	done bool
+}
+
+// checked records the error of a fetch refused by the check of the job
+func (f *jobFailure) checked(resp *scrapemate.Response, ok bool) {
+	f.done = false
+
+	switch {
+	case resp.Error != nil:
+		f.err = resp.Error
+	case !ok:
+		f.err = statusError(resp.StatusCode)
+	default:
+		f.err = nil
+	}
+}
+
+// processed records the error of the processing of the job
+func (f *jobFailure) processed(err error) {
+	f.done = err == nil
+
+	if err != nil {
+		f.err = err
+	}
+}
+
+// jobFailureOf returns the failure of the search, place and lookup jobs, nil
+// for the other jobs
+func jobFailureOf(job scrapemate.IJob) *jobFailure {
+	switch j := job.(type) {
+	case *SearchJob:
+		return &j.failure
+	case *GmapJob:
+		return &j.failure
+	case *PlaceJob:
+		return &j.failure
+	case *PlaceLookupJob:
+		return &j.failure
+	default:
+		return nil
+	}
+}
+
+// JobFailed reports whether the last run of the job failed, its fetch was
+// refused without being processed or its processing returned an error. It is
+// known once the job is done, before its failure is sent to the failed jobs of
+// scrapemate. ok is false for the jobs that do not record their failures.
+func JobFailed(job scrapemate.IJob) (failed, ok bool) {
+	f := jobFailureOf(job)
+	if f == nil {
+		return false, false
+	}
+
+	return !f.done, true
+}
+
+// JobQuery returns the seed query of the search, place and lookup jobs, an
+// empty string for the other jobs
+func JobQuery(job scrapemate.IJob) string {
+	switch j := job.(type) {
+	case *SearchJob:
+		return j.params.term()
+	case *GmapJob:
+		return j.Query
+	case *PlaceJob:
+		return j.Query
+	case *PlaceLookupJob:
+		return j.Query
+	default:
+		return ""
+	}
+}
+
+// FailureClass returns the class of the last error of a failed job
+func FailureClass(job scrapemate.IJob) exiter.ErrorClass {
+	f := jobFailureOf(job)
+	if f == nil {
+		return exiter.ErrorOther
+	}
+
+	return ClassifyError(f.err)
+}
+
+// ClassifyError returns the class of err
+func ClassifyError(err error) exiter.ErrorClass {
+	var (
+		netErr    net.Error
+		statusErr statusError
+	)
+
+	switch {
+	case err == nil:
+		return exiter.ErrorOther
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.Is(err, playwright.ErrTimeout), errors.As(err, &netErr) && netErr.Timeout():
+		return exiter.ErrorTimeout
+	case errors.Is(err, ErrInterstitial):
+		return exiter.ErrorBlocked
+	case errors.As(err, &statusErr):
+		if statusErr == http.StatusForbidden || statusErr == http.StatusTooManyRequests {
+			return exiter.ErrorBlocked
+		}
+
+		return exiter.ErrorOther
+	case errors.Is(err, ErrEmptyBody):
+		return exiter.ErrorEmptyBody
+	case errors.Is(err, ErrParse):
+		return exiter.ErrorParse
+	default:
+		return exiter.ErrorOther
+	}
+}
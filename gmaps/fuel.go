@@ -0,0 +1,117 @@
+package gmaps
+
+import (
+	"encoding/gob"
+	"regexp"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// FuelPrice is a price of the Gas prices block of a gas station
+type FuelPrice struct {
+	Type     string  `json:"type"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	// Updated is when the price was reported as shown, e.g. "Updated 3 hours ago"
+	Updated string `json:"updated"`
+}
+
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register([]FuelPrice{})
+}
+
+var (
+	fuelPriceRegex   = regexp.MustCompile(`^(?:([$€£¥₹]|[A-Z]{3})\s?)?(\d+(?:[.,]\d+)?)\s?([$€£¥₹]|[A-Z]{3})?(?:/\s?[lL]|/\s?gal)?$`)
+	fuelUpdatedRegex = regexp.MustCompile(`(?i)\b(updated|ago|aktualisiert|mis à jour|actualizado)\b`)
+)
+
+// currencySymbols maps the symbols to ISO 4217 codes.
+// $ is assumed to be USD, the dollars of other countries use the same symbol.
+var currencySymbols = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+}
+
+// fetchFuelPrices returns the prices of the Gas prices block of the overview.
+// They are parsed in the language hl. It must run before a tab is opened.
+func fetchFuelPrices(page playwright.Page, hl string) ([]FuelPrice, error) {
+	texts, err := sectionTexts(page, `^(gas|fuel|petrol) prices$`)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFuelPrices(texts, hl), nil
+}
+
+// parseFuelPrices pairs the prices of the Gas prices block with their fuel type:
+// the text before the price, or after it when that one is taken.
+func parseFuelPrices(texts []string, hl string) []FuelPrice {
+	var (
+		prices  []FuelPrice
+		updated string
+	)
+
+	used := make([]bool, len(texts))
+	isLabel := func(i int) bool {
+		return i >= 0 && i < len(texts) && !used[i] &&
+			!fuelPriceRegex.MatchString(texts[i]) && !fuelUpdatedRegex.MatchString(texts[i])
+	}
+
+	for i, t := range texts {
+		if fuelUpdatedRegex.MatchString(t) {
+			updated = t
+
+			continue
+		}
+
+		m := fuelPriceRegex.FindStringSubmatch(t)
+		if m == nil {
+			continue
+		}
+
+		price, err := parseNumber(m[2], hl)
+		if err != nil {
+			continue
+		}
+
+		label := -1
+
+		switch {
+		case isLabel(i - 1):
+			label = i - 1
+		case isLabel(i + 1):
+			label = i + 1
+		}
+
+		if label == -1 {
+			continue
+		}
+
+		used[i], used[label] = true, true
+
+		currency := m[1]
+		if currency == "" {
+			currency = m[3]
+		}
+
+		if code, ok := currencySymbols[currency]; ok {
+			currency = code
+		}
+
+		prices = append(prices, FuelPrice{
+			Type:     texts[label],
+			Price:    price,
+			Currency: currency,
+		})
+	}
+
+	for i := range prices {
+		prices[i].Updated = updated
+	}
+
+	return prices
+}
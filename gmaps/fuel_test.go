@@ -0,0 +1,62 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_ParseFuelPrices(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		hl    string
+		want  []gmaps.FuelPrice
+	}{
+		{
+			name:  "labels before the prices",
+			texts: []string{"Regular", "$3.45", "Premium", "$4.05", "Updated 3 hours ago"},
+			hl:    "en",
+			want: []gmaps.FuelPrice{
+				{Type: "Regular", Price: 3.45, Currency: "USD", Updated: "Updated 3 hours ago"},
+				{Type: "Premium", Price: 4.05, Currency: "USD", Updated: "Updated 3 hours ago"},
+			},
+		},
+		{
+			name:  "labels after the prices",
+			texts: []string{"1,789 €", "Super E10", "1,859 €", "Diesel"},
+			hl:    "de",
+			want: []gmaps.FuelPrice{
+				{Type: "Super E10", Price: 1.789, Currency: "EUR"},
+				{Type: "Diesel", Price: 1.859, Currency: "EUR"},
+			},
+		},
+		{
+			name:  "currency codes and units",
+			texts: []string{"Unleaded", "GBP 1.45/L", "Diesel", "1.52 GBP/l"},
+			hl:    "en",
+			want: []gmaps.FuelPrice{
+				{Type: "Unleaded", Price: 1.45, Currency: "GBP"},
+				{Type: "Diesel", Price: 1.52, Currency: "GBP"},
+			},
+		},
+		{
+			name:  "price without a label",
+			texts: []string{"$3.45", "Updated yesterday"},
+			hl:    "en",
+		},
+		{
+			name:  "no prices",
+			texts: []string{"Gas prices", "Not reported"},
+			hl:    "en",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, gmaps.ParseFuelPrices(tc.texts, tc.hl))
+		})
+	}
+}
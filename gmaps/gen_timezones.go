@@ -0,0 +1,243 @@
+//go:build ignore
+
+// gen_timezones writes timezones.geojson from the GeoJSON of a release of
+// timezone-boundary-builder (https://github.com/evansiroky/timezone-boundary-builder):
+//
+//	go run gen_timezones.go -o timezones.geojson combined-with-oceans.json
+//
+// The Etc zones of the oceans are dropped, the rings are simplified with the
+// Douglas-Peucker algorithm and the coordinates are rounded, which keeps the
+// borders within about a kilometer.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+type feature struct {
+	Type       string `json:"type"`
+	Properties struct {
+		TZID string `json:"tzid"`
+	} `json:"properties"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+func main() {
+	out := flag.String("o", "timezones.geojson", "output file")
+	tolerance := flag.Float64("tolerance", 0.01, "simplification tolerance in degrees")
+	precision := flag.Int("precision", 3, "decimals of the coordinates")
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen_timezones.go [-o timezones.geojson] <timezone-boundary-builder geojson>")
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *out, *tolerance, *precision); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string, tolerance float64, precision int) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	var collection struct {
+		Features []feature `json:"features"`
+	}
+
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	slices.SortFunc(collection.Features, func(a, b feature) int {
+		return strings.Compare(a.Properties.TZID, b.Properties.TZID)
+	})
+
+	scale := math.Pow(10, float64(precision))
+
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"type":"FeatureCollection","features":[`)
+
+	n := 0
+
+	for i := range collection.Features {
+		f := &collection.Features[i]
+
+		if strings.HasPrefix(f.Properties.TZID, "Etc/") {
+			continue
+		}
+
+		polygons, err := decodePolygons(f)
+		if err != nil {
+			return fmt.Errorf("invalid boundary of %s: %w", f.Properties.TZID, err)
+		}
+
+		var simplified [][][][2]float64
+
+		for _, rings := range polygons {
+			var kept [][][2]float64
+
+			for j, ring := range rings {
+				ring = simplify(round(ring, scale), tolerance)
+				if len(ring) < 4 {
+					if j == 0 {
+						break
+					}
+
+					continue
+				}
+
+				kept = append(kept, ring)
+			}
+
+			if len(kept) > 0 {
+				simplified = append(simplified, kept)
+			}
+		}
+
+		if len(simplified) == 0 {
+			continue
+		}
+
+		coordinates, err := json.Marshal(simplified)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+
+		fmt.Fprintf(&buf, "\n{\"type\":\"Feature\",\"properties\":{\"tzid\":%q},\"geometry\":{\"type\":\"MultiPolygon\",\"coordinates\":%s}}",
+			f.Properties.TZID, coordinates)
+
+		n++
+	}
+
+	buf.WriteString("\n]}\n")
+
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
+
+func decodePolygons(f *feature) ([][][][2]float64, error) {
+	switch f.Geometry.Type {
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &rings); err != nil {
+			return nil, err
+		}
+
+		return [][][][2]float64{rings}, nil
+	case "MultiPolygon":
+		var polygons [][][][2]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &polygons); err != nil {
+			return nil, err
+		}
+
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry %q", f.Geometry.Type)
+	}
+}
+
+// round rounds the points of the ring and removes the repeated ones
+func round(ring [][2]float64, scale float64) [][2]float64 {
+	ans := make([][2]float64, 0, len(ring))
+
+	for _, pt := range ring {
+		pt = [2]float64{math.Round(pt[0]*scale) / scale, math.Round(pt[1]*scale) / scale}
+		if len(ans) > 0 && ans[len(ans)-1] == pt {
+			continue
+		}
+
+		ans = append(ans, pt)
+	}
+
+	if len(ans) > 0 && ans[0] != ans[len(ans)-1] {
+		ans = append(ans, ans[0])
+	}
+
+	return ans
+}
+
+// simplify simplifies the closed ring by its two halves, so that it keeps
+// at least three distinct points
+func simplify(ring [][2]float64, tolerance float64) [][2]float64 {
+	if len(ring) <= 4 {
+		return ring
+	}
+
+	h := len(ring) / 2
+
+	first := douglasPeucker(ring[:h+1], tolerance)
+	second := douglasPeucker(ring[h:], tolerance)
+
+	return append(first[:len(first)-1], second...)
+}
+
+func douglasPeucker(points [][2]float64, tolerance float64) [][2]float64 {
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	var split func(a, b int)
+
+	split = func(a, b int) {
+		if b <= a+1 {
+			return
+		}
+
+		ax, ay := points[a][0], points[a][1]
+		dx, dy := points[b][0]-ax, points[b][1]-ay
+		length := math.Hypot(dx, dy)
+
+		farthest, index := -1.0, a
+
+		for i := a + 1; i < b; i++ {
+			var d float64
+			if length == 0 {
+				d = math.Hypot(points[i][0]-ax, points[i][1]-ay)
+			} else {
+				d = math.Abs(dy*(points[i][0]-ax)-dx*(points[i][1]-ay)) / length
+			}
+
+			if d > farthest {
+				farthest, index = d, i
+			}
+		}
+
+		if farthest > tolerance {
+			keep[index] = true
+
+			split(a, index)
+			split(index, b)
+		}
+	}
+
+	split(0, len(points)-1)
+
+	ans := make([][2]float64, 0, len(points))
+
+	for i, k := range keep {
+		if k {
+			ans = append(ans, points[i])
+		}
+	}
+
+	return ans
+}
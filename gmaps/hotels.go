@@ -0,0 +1,131 @@
+package gmaps
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// HotelRate is a rate of the Prices block of a hotel
+type HotelRate struct {
+	// Provider is the site selling the room, e.g. Booking.com or the hotel
+	// website
+	Provider string  `json:"provider"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	// Text is the rate as shown, e.g. "$125"
+	Text string `json:"text"`
+}
+
+var (
+	hotelRateRegex = regexp.MustCompile(`^(?:([$€£¥₹]|[A-Z]{3})\s?)?(\d{1,3}(?:[,.\s\x{a0}\x{202f}]\d{3})+|\d+)(?:[.,](\d{1,2}))?\s?([$€£¥₹]|[A-Z]{3})?(?:\s?(?:/|per)\s?night)?$`)
+	// hotelNoteRegex matches the notes of the rates, they are not providers
+	hotelNoteRegex = regexp.MustCompile(`(?i)(official site|cancell|breakfast|less than usual|deal|nightly|per night|total|taxes|fees|sponsored|^ads?$|view|more|prices|compare|check[- ]?(in|out)|guests?\b|nights?\b)`)
+	// hotelMoreRegex matches the links of the Amenities block
+	hotelMoreRegex = regexp.MustCompile(`(?i)^(view|see|show) (all|more)|^(about|amenities|popular amenities)$`)
+)
+
+// fetchHotelTexts returns the texts of the Prices and of the Amenities blocks
+// of the overview of a hotel. It must run before a tab is opened.
+func fetchHotelTexts(page playwright.Page) (rates, amenities []string, err error) {
+	rates, err = sectionTexts(page, `^(prices|rates|compare prices|check availability)$`)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	amenities, err = sectionTexts(page, `^(amenities|popular amenities)$`)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rates, amenities, nil
+}
+
+// parseHotelRates pairs the rates of the Prices block with their provider, the
+// closest text before the rate that is neither a rate nor a note
+func parseHotelRates(texts []string) []HotelRate {
+	var rates []HotelRate
+
+	provider := ""
+
+	for _, t := range texts {
+		m := hotelRateRegex.FindStringSubmatch(t)
+		if m == nil {
+			if !hotelNoteRegex.MatchString(t) {
+				provider = t
+			}
+
+			continue
+		}
+
+		if provider == "" {
+			continue
+		}
+
+		amount := strings.NewReplacer(",", "", ".", "").Replace(groupSeparators.Replace(m[2]))
+		if m[3] != "" {
+			amount += "." + m[3]
+		}
+
+		price, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			continue
+		}
+
+		currency := m[1]
+		if currency == "" {
+			currency = m[4]
+		}
+
+		if code, ok := currencySymbols[currency]; ok {
+			currency = code
+		}
+
+		rates = append(rates, HotelRate{
+			Provider: provider,
+			Price:    price,
+			Currency: currency,
+			Text:     t,
+		})
+
+		// one rate per provider, the next rate needs its own provider
+		provider = ""
+	}
+
+	return rates
+}
+
+// parseHotelAmenities returns the amenities of the Amenities block as shown,
+// once each
+func parseHotelAmenities(texts []string) []string {
+	var ans []string
+
+	for _, t := range texts {
+		if hotelMoreRegex.MatchString(t) {
+			continue
+		}
+
+		ans = appendUnique(ans, t)
+	}
+
+	return ans
+}
+
+// setHotelDetails sets the rates and the amenities of the hotel page of a place
+// to stay, its amenities are added to Amenities too
+func (e *Entry) setHotelDetails(rates, amenities []string) {
+	if !e.IsLodging() {
+		return
+	}
+
+	e.HotelRates = parseHotelRates(rates)
+	e.HotelAmenities = parseHotelAmenities(amenities)
+
+	for _, a := range e.HotelAmenities {
+		if name := amenityName(a); name != "" {
+			e.Amenities = appendUnique(e.Amenities, name)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package gmaps
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the docker images do not ship the zoneinfo database
+)
+
+var (
+	// ErrUnknownTimezone is returned when the timezone of a place cannot be resolved
+	ErrUnknownTimezone = errors.New("unknown timezone")
+	// ErrNoOpenHours is returned when a place has no opening hours that can be parsed
+	ErrNoOpenHours = errors.New("no parsable opening hours")
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// interval is an opening interval in minutes since local midnight.
+// end is greater than minutesPerDay when the place closes after midnight.
+type interval struct {
+	start int
+	end   int
+}
+
+var clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+
+// Location returns the IANA timezone of the place.
+// When Google does not provide one, it is resolved from the coordinates with
+// the embedded timezone boundaries, see TimezoneAt. Out of them it is
+// ErrUnknownTimezone: a fixed offset would miss the daylight saving time.
+func (e *Entry) Location() (*time.Location, error) {
+	if e.Timezone != "" {
+		if loc, err := time.LoadLocation(e.Timezone); err == nil {
+			return loc, nil
+		}
+	}
+
+	if e.Latitude == 0 && e.Longtitude == 0 {
+		return nil, ErrUnknownTimezone
+	}
+
+	if tzid, ok := TimezoneAt(e.Latitude, e.Longtitude); ok {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, ErrUnknownTimezone
+}
+
+// ResolveTimezone sets the timezone of the place if missing, and the
+// UTC offset and the opening hours in UTC as they are at the given time.
+func (e *Entry) ResolveTimezone(at time.Time) {
+	loc, err := e.Location()
+	if err != nil {
+		return
+	}
+
+	if e.Timezone == "" {
+		e.Timezone = loc.String()
+	}
+
+	_, offset := at.In(loc).Zone()
+
+	e.UTCOffset = formatOffset(offset)
+
+	schedule := e.schedule()
+	if len(schedule) == 0 {
+		return
+	}
+
+	e.OpenHoursUTC = utcHours(schedule, offset/60)
+}
+
+// OpenNowAt reports whether the place is open at ts,
+// according to its opening hours in its local time.
+// Only the english day names (hl=en) are understood.
+func (e *Entry) OpenNowAt(ts time.Time) (bool, error) {
+	loc, err := e.Location()
+	if err != nil {
+		return false, err
+	}
+
+	schedule := e.schedule()
+	if len(schedule) == 0 {
+		return false, ErrNoOpenHours
+	}
+
+	t := ts.In(loc)
+
+	return openAt(schedule, WeekTime{Day: t.Weekday(), Minutes: t.Hour()*60 + t.Minute()}), nil
+}
+
+// OpenAt reports whether the place is open at the time of the week t in its
+// local time, according to its opening hours
+func (e *Entry) OpenAt(t WeekTime) (bool, error) {
+	schedule := e.schedule()
+	if len(schedule) == 0 {
+		return false, ErrNoOpenHours
+	}
+
+	return openAt(schedule, t), nil
+}
+
+func openAt(schedule map[time.Weekday][]interval, t WeekTime) bool {
+	for _, iv := range schedule[t.Day] {
+		if t.Minutes >= iv.start && t.Minutes < iv.end {
+			return true
+		}
+	}
+
+	// intervals of the day before that end after midnight
+	for _, iv := range schedule[(t.Day+6)%7] {
+		if iv.end > minutesPerDay && t.Minutes < iv.end-minutesPerDay {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WeekTime is a time of the week in the local time of the places
+type WeekTime struct {
+	Day time.Weekday
+	// Minutes are the minutes since midnight
+	Minutes int
+}
+
+// ParseWeekTime parses a day of the week and a time, e.g. "Saturday 10:00",
+// "sat 22:30" or "Monday 9 am"
+func ParseWeekTime(s string) (WeekTime, error) {
+	dayStr, clock, _ := strings.Cut(strings.TrimSpace(s), " ")
+
+	day, ok := parseWeekday(dayStr)
+	if !ok {
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if len(dayStr) == 3 && strings.EqualFold(wd.String()[:3], dayStr) {
+				day, ok = wd, true
+			}
+		}
+	}
+
+	if !ok {
+		return WeekTime{}, fmt.Errorf("invalid week time %q: the day must be a day of the week like Saturday or sat", s)
+	}
+
+	minutes, meridiem, ok := parseClock(strings.ToLower(clock))
+	if !ok || minutes >= minutesPerDay {
+		return WeekTime{}, fmt.Errorf("invalid week time %q: the time must be like 10:00 or 9 am", s)
+	}
+
+	if meridiem != "" {
+		minutes = applyMeridiem(minutes, meridiem)
+	}
+
+	return WeekTime{Day: day, Minutes: minutes}, nil
+}
+
+// DayHours are the opening hours of a day of the week in the local time of
+// the place
+type DayHours struct {
+	// Day is the english name of the day
+	Day    string `json:"day"`
+	Closed bool   `json:"closed"`
+	// Intervals are in the order of the opening hours of the day
+	Intervals []HoursInterval `json:"intervals"`
+}
+
+// HoursInterval is an opening interval, the times are HH:MM on a 24 hours
+// clock. Close is before Open when the place closes after midnight and is
+// 24:00 when it closes at midnight.
+type HoursInterval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// weekSchedule returns the opening hours per day from Monday to Sunday, the
+// days that cannot be parsed are left out
+func (e *Entry) weekSchedule() []DayHours {
+	schedule := e.schedule()
+
+	var ans []DayHours
+
+	for i := range 7 {
+		wd := time.Weekday((i + 1) % 7)
+
+		ivs, ok := schedule[wd]
+		if !ok {
+			continue
+		}
+
+		day := DayHours{
+			Day:       wd.String(),
+			Closed:    len(ivs) == 0,
+			Intervals: make([]HoursInterval, 0, len(ivs)),
+		}
+
+		for _, iv := range ivs {
+			closing := formatClock(iv.end)
+			if iv.end == minutesPerDay {
+				closing = "24:00"
+			}
+
+			day.Intervals = append(day.Intervals, HoursInterval{Open: formatClock(iv.start), Close: closing})
+		}
+
+		ans = append(ans, day)
+	}
+
+	return ans
+}
+
+// schedule parses the opening hours. Days or hours that cannot be parsed are ignored.
+func (e *Entry) schedule() map[time.Weekday][]interval {
+	ans := make(map[time.Weekday][]interval, len(e.OpenHours))
+
+	for day, hours := range e.OpenHours {
+		wd, ok := parseWeekday(day)
+		if !ok {
+			continue
+		}
+
+		for _, h := range hours {
+			ivs, ok := parseHoursRange(h)
+			if !ok {
+				continue
+			}
+
+			ans[wd] = append(ans[wd], ivs...)
+		}
+	}
+
+	return ans
+}
+
+// utcHours converts the schedule to UTC, keyed by the UTC day name
+func utcHours(schedule map[time.Weekday][]interval, offset int) map[string][]string {
+	type weekInterval struct {
+		start, end int
+	}
+
+	var all []weekInterval
+
+	for wd, ivs := range schedule {
+		for _, iv := range ivs {
+			start := int(wd)*minutesPerDay + iv.start - offset
+			start = ((start % minutesPerWeek) + minutesPerWeek) % minutesPerWeek
+
+			all = append(all, weekInterval{start: start, end: start + iv.end - iv.start})
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].start < all[j].start
+	})
+
+	ans := make(map[string][]string, len(schedule))
+
+	for _, wi := range all {
+		day := time.Weekday(wi.start / minutesPerDay).String()
+		ans[day] = append(ans[day], formatClock(wi.start)+"–"+formatClock(wi.end))
+	}
+
+	return ans
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.TrimSpace(s)
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(wd.String(), s) {
+			return wd, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseHoursRange parses the hours as displayed by Google, e.g.
+// "9 am–5 pm", "12:30–10 pm", "09:00–17:00", "Open 24 hours" or "Closed".
+// The start and the end have their own meridiem, the start without one shares
+// the meridiem of the end when it is before it.
+func parseHoursRange(s string) ([]interval, bool) {
+	s = strings.ToLower(strings.TrimSpace(strings.NewReplacer(
+		"\u202f", " ",
+		"\u00a0", " ",
+		"\u2009", " ",
+		"—", "–",
+	).Replace(s)))
+
+	switch s {
+	case "open 24 hours":
+		return []interval{{start: 0, end: minutesPerDay}}, true
+	case "closed":
+		return nil, true
+	}
+
+	from, to, ok := strings.Cut(s, "–")
+	if !ok {
+		from, to, ok = strings.Cut(s, "-")
+		if !ok {
+			return nil, false
+		}
+	}
+
+	start, startMeridiem, ok := parseClock(from)
+	if !ok {
+		return nil, false
+	}
+
+	end, endMeridiem, ok := parseClock(to)
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case startMeridiem != "":
+		start = applyMeridiem(start, startMeridiem)
+	case endMeridiem != "":
+		// "12:30–10 pm" shares the meridiem, "11–2 pm" does not
+		shared := applyMeridiem(start, endMeridiem)
+		if shared > applyMeridiem(end, endMeridiem) && endMeridiem == "pm" {
+			shared = applyMeridiem(start, "am")
+		}
+
+		start = shared
+	}
+
+	if endMeridiem != "" {
+		end = applyMeridiem(end, endMeridiem)
+	}
+
+	if end <= start {
+		end += minutesPerDay
+	}
+
+	return []interval{{start: start, end: end}}, true
+}
+
+// parseClock returns the minutes since midnight of a 12 or 24 hours clock
+// and its meridiem if any.
+func parseClock(s string) (int, string, bool) {
+	m := clockRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	if hour > 24 || minute > 59 {
+		return 0, "", false
+	}
+
+	meridiem := strings.ReplaceAll(m[3], ".", "")
+	if meridiem != "" && (hour == 0 || hour > 12) {
+		return 0, "", false
+	}
+
+	return hour*60 + minute, meridiem, true
+}
+
+func applyMeridiem(minutes int, meridiem string) int {
+	hour, minute := minutes/60, minutes%60
+
+	switch {
+	case meridiem == "am" && hour == 12:
+		hour = 0
+	case meridiem == "pm" && hour != 12:
+		hour += 12
+	}
+
+	return hour*60 + minute
+}
+
+func formatClock(minutes int) string {
+	minutes %= minutesPerDay
+
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
@@ -0,0 +1,186 @@
+package gmaps_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_EntryLocation(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry gmaps.Entry
+		want  string
+		err   error
+	}{
+		{
+			name:  "timezone of google",
+			entry: gmaps.Entry{Timezone: "America/New_York", Latitude: 37.98, Longtitude: 23.72},
+			want:  "America/New_York",
+		},
+		{
+			name:  "timezone of the coordinates",
+			entry: gmaps.Entry{Latitude: 37.98, Longtitude: 23.72},
+			want:  "Europe/Athens",
+		},
+		{
+			name:  "invalid timezone of google",
+			entry: gmaps.Entry{Timezone: "Mars/Olympus", Latitude: 37.98, Longtitude: 23.72},
+			want:  "Europe/Athens",
+		},
+		{
+			name:  "out of the timezone boundaries",
+			entry: gmaps.Entry{Latitude: -40, Longtitude: -130},
+			err:   gmaps.ErrUnknownTimezone,
+		},
+		{
+			name: "without coordinates",
+			err:  gmaps.ErrUnknownTimezone,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := tc.entry.Location()
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, loc.String())
+		})
+	}
+}
+
+func Test_EntryResolveTimezone(t *testing.T) {
+	winter := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2025, time.July, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		entry    gmaps.Entry
+		at       time.Time
+		timezone string
+		offset   string
+		hours    map[string][]string
+	}{
+		{
+			name:     "standard time",
+			entry:    gmaps.Entry{Latitude: 37.98, Longtitude: 23.72, OpenHours: map[string][]string{"Monday": {"9 am–5 pm"}}},
+			at:       winter,
+			timezone: "Europe/Athens",
+			offset:   "+02:00",
+			hours:    map[string][]string{"Monday": {"07:00–15:00"}},
+		},
+		{
+			name:     "daylight saving time",
+			entry:    gmaps.Entry{Latitude: 37.98, Longtitude: 23.72, OpenHours: map[string][]string{"Monday": {"9 am–5 pm"}}},
+			at:       summer,
+			timezone: "Europe/Athens",
+			offset:   "+03:00",
+			hours:    map[string][]string{"Monday": {"06:00–14:00"}},
+		},
+		{
+			name:     "negative offset",
+			entry:    gmaps.Entry{Timezone: "America/New_York", OpenHours: map[string][]string{"Sunday": {"8 pm–11 pm"}}},
+			at:       winter,
+			timezone: "America/New_York",
+			offset:   "-05:00",
+			hours:    map[string][]string{"Monday": {"01:00–04:00"}},
+		},
+		{
+			name:  "unknown timezone",
+			entry: gmaps.Entry{Latitude: -40, Longtitude: -130, OpenHours: map[string][]string{"Monday": {"9 am–5 pm"}}},
+			at:    winter,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := tc.entry
+			entry.ResolveTimezone(tc.at)
+
+			require.Equal(t, tc.timezone, entry.Timezone)
+			require.Equal(t, tc.offset, entry.UTCOffset)
+			require.Equal(t, tc.hours, entry.OpenHoursUTC)
+		})
+	}
+}
+
+func Test_EntryOpenHours(t *testing.T) {
+	tests := []struct {
+		hours string
+		want  []string
+	}{
+		{hours: "9 am–5 pm", want: []string{"09:00–17:00"}},
+		{hours: "11 am–2 pm", want: []string{"11:00–14:00"}},
+		{hours: "11 AM – 2 PM", want: []string{"11:00–14:00"}},
+		{hours: "11 a.m.–2 p.m.", want: []string{"11:00–14:00"}},
+		{hours: "12:30–10 pm", want: []string{"12:30–22:00"}},
+		{hours: "11–2 pm", want: []string{"11:00–14:00"}},
+		{hours: "1 pm–14:30", want: []string{"13:00–14:30"}},
+		{hours: "6 am–12 pm", want: []string{"06:00–12:00"}},
+		{hours: "10 pm–2 am", want: []string{"22:00–02:00"}},
+		{hours: "09:00-17:00", want: []string{"09:00–17:00"}},
+		{hours: "Open 24 hours", want: []string{"00:00–00:00"}},
+		{hours: "Closed"},
+		{hours: "by appointment"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.hours, func(t *testing.T) {
+			entry := gmaps.Entry{Timezone: "UTC", OpenHours: map[string][]string{"Wednesday": {tc.hours}}}
+			entry.ResolveTimezone(time.Now())
+
+			require.Equal(t, tc.want, entry.OpenHoursUTC["Wednesday"])
+		})
+	}
+}
+
+func Test_EntryOpenAt(t *testing.T) {
+	entry := gmaps.Entry{
+		Timezone: "Europe/Athens",
+		OpenHours: map[string][]string{
+			"Friday":   {"11 am–2 pm", "6 pm–1 am"},
+			"Saturday": {"Closed"},
+		},
+	}
+
+	tests := []struct {
+		at   string
+		open bool
+	}{
+		{at: "Friday 10:59"},
+		{at: "Friday 11:00", open: true},
+		{at: "fri 1:59 pm", open: true},
+		{at: "Friday 14:00"},
+		{at: "Friday 23:30", open: true},
+		{at: "Saturday 00:30", open: true},
+		{at: "Saturday 01:00"},
+		{at: "Sunday 12:00"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.at, func(t *testing.T) {
+			wt, err := gmaps.ParseWeekTime(tc.at)
+			require.NoError(t, err)
+
+			open, err := entry.OpenAt(wt)
+			require.NoError(t, err)
+			require.Equal(t, tc.open, open)
+		})
+	}
+
+	// the local time of the place, 21:30 in Athens in summer
+	open, err := entry.OpenNowAt(time.Date(2025, time.July, 18, 18, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, open)
+
+	_, err = (&gmaps.Entry{Timezone: "UTC"}).OpenNowAt(time.Now())
+	require.ErrorIs(t, err, gmaps.ErrNoOpenHours)
+}
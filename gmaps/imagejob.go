@@ -0,0 +1,294 @@
+package gmaps
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/tracing"
+)
+
+// ErrImageTooLarge is returned when an image is larger than the size limit of the downloader
+var ErrImageTooLarge = errors.New("image too large")
+
+// ImageStore saves the downloaded images under their key, a slash separated path
+type ImageStore interface {
+	Save(ctx context.Context, key string, body []byte) error
+}
+
+type dirImageStore struct {
+	dir string
+}
+
+// NewDirImageStore returns a store that saves the images in the directory dir
+func NewDirImageStore(dir string) ImageStore {
+	return &dirImageStore{dir: dir}
+}
+
+func (s *dirImageStore) Save(_ context.Context, key string, body []byte) error {
+	fpath := filepath.Join(s.dir, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(fpath), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fpath, body, 0o644)
+}
+
+// ImageUploader uploads a file to a bucket, it is implemented by the s3uploader
+type ImageUploader interface {
+	Upload(ctx context.Context, bucket, key string, body io.Reader) error
+}
+
+type s3ImageStore struct {
+	uploader ImageUploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3ImageStore returns a store that uploads the images to the bucket, with
+// their key prefixed by prefix
+func NewS3ImageStore(uploader ImageUploader, bucket, prefix string) ImageStore {
+	return &s3ImageStore{uploader: uploader, bucket: bucket, prefix: prefix}
+}
+
+func (s *s3ImageStore) Save(ctx context.Context, key string, body []byte) error {
+	return s.uploader.Upload(ctx, s.bucket, path.Join(s.prefix, key), bytes.NewReader(body))
+}
+
+var _ scrapemate.HTTPFetcher = (*ImageDownloader)(nil)
+
+// ImageDownloader downloads the photos of the places and saves them in a store.
+// It is the fetcher of the ImageDownloadJobs: the images are downloaded without
+// the browser, at most concurrency at a time.
+type ImageDownloader struct {
+	store   ImageStore
+	client  *http.Client
+	maxSize int64
+	sem     chan struct{}
+}
+
+// NewImageDownloader returns a downloader saving the images in store. The
+// images larger than maxSize bytes are skipped, 0 means no limit.
+func NewImageDownloader(store ImageStore, concurrency int, maxSize int64) *ImageDownloader {
+	const timeout = 30 * time.Second
+
+	return &ImageDownloader{
+		store:   store,
+		client:  &http.Client{Timeout: timeout},
+		maxSize: maxSize,
+		sem:     make(chan struct{}, max(concurrency, 1)),
+	}
+}
+
+// Jobs returns the jobs downloading the photos of the entry. The exit monitor
+// waits for them: they are counted as seeds before the place is completed.
+func (d *ImageDownloader) Jobs(parentID string, entry *Entry, exitMonitor exiter.Exiter) []scrapemate.IJob {
+	if len(entry.Photos) == 0 {
+		return nil
+	}
+
+	place := entry.Cid
+	if place == "" {
+		place = strings.ReplaceAll(entry.DataID, ":", "_")
+	}
+
+	if place == "" {
+		return nil
+	}
+
+	jobs := make([]scrapemate.IJob, 0, len(entry.Photos))
+
+	for i := range entry.Photos {
+		photo := &entry.Photos[i]
+
+		name := photoIDReplacer.Replace(photo.ID)
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+
+		key := path.Join(place, keySegment(photo.Category), name+".jpg")
+
+		jobs = append(jobs, NewImageDownloadJob(parentID, d, photo.OriginalURL(), key, exitMonitor))
+	}
+
+	if exitMonitor != nil {
+		exitMonitor.IncrSeedCount(len(jobs))
+	}
+
+	return jobs
+}
+
+// photoIDReplacer keeps the ids in one file name, they are case sensitive
+var photoIDReplacer = strings.NewReplacer("/", "_", "\\", "_", ".", "_")
+
+// keySegment makes s usable as a file name, e.g. "Street View & 360°" is street-view-360
+func keySegment(s string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+
+			b.WriteRune(r)
+
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	if b.Len() == 0 {
+		return "other"
+	}
+
+	return b.String()
+}
+
+func (d *ImageDownloader) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	select {
+	case d.sem <- struct{}{}:
+	case <-ctx.Done():
+		return scrapemate.Response{Error: ctx.Err()}
+	}
+
+	defer func() { <-d.sem }()
+
+	start := time.Now()
+	u := job.GetFullURL()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	defer resp.Body.Close()
+
+	ans := scrapemate.Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+	}
+
+	if d.maxSize > 0 && resp.ContentLength > d.maxSize {
+		ans.Error = fmt.Errorf("%w: %d bytes", ErrImageTooLarge, resp.ContentLength)
+
+		return ans
+	}
+
+	body := io.Reader(resp.Body)
+	if d.maxSize > 0 {
+		body = io.LimitReader(resp.Body, d.maxSize+1)
+	}
+
+	ans.Body, ans.Error = io.ReadAll(body)
+	if ans.Error == nil && d.maxSize > 0 && int64(len(ans.Body)) > d.maxSize {
+		ans.Body = nil
+		ans.Error = fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, d.maxSize)
+	}
+
+	ans.Duration = time.Since(start)
+
+	return ans
+}
+
+func (d *ImageDownloader) Close() error {
+	d.client.CloseIdleConnections()
+
+	return nil
+}
+
+// ImageDownloadJob downloads a photo of a place and saves it with the store of
+// its downloader. It has no result, a failed download is only logged.
+type ImageDownloadJob struct {
+	scrapemate.Job
+	tracing.Carrier
+
+	// Key is the path of the image in the store
+	Key         string
+	ExitMonitor exiter.Exiter
+
+	downloader *ImageDownloader
+}
+
+func NewImageDownloadJob(parentID string, d *ImageDownloader, u, key string, exitMonitor exiter.Exiter) *ImageDownloadJob {
+	const (
+		defaultPrio       = scrapemate.PriorityLow
+		defaultMaxRetries = 1
+	)
+
+	return &ImageDownloadJob{
+		Job: scrapemate.Job{
+			ID:         uuid.New().String(),
+			ParentID:   parentID,
+			Method:     http.MethodGet,
+			URL:        u,
+			MaxRetries: defaultMaxRetries,
+			Priority:   defaultPrio,
+		},
+		Key:         key,
+		ExitMonitor: exitMonitor,
+		downloader:  d,
+	}
+}
+
+// Fetcher returns the downloader, the images are not fetched by the browser
+func (j *ImageDownloadJob) Fetcher() scrapemate.HTTPFetcher {
+	return j.downloader
+}
+
+func (j *ImageDownloadJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	defer func() {
+		resp.Document = nil
+		resp.Body = nil
+
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrSeedCompleted(1)
+		}
+	}()
+
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	if resp.Error != nil {
+		log.Warn("image download failed", "job_id", j.ID, "key", j.Key, "error", resp.Error)
+
+		return nil, nil, nil
+	}
+
+	if err := j.downloader.store.Save(ctx, j.Key, resp.Body); err != nil {
+		log.Warn("image not saved", "job_id", j.ID, "key", j.Key, "error", err)
+	}
+
+	return nil, nil, nil
+}
+
+func (j *ImageDownloadJob) UseInResults() bool {
+	return false
+}
+
+func (j *ImageDownloadJob) ProcessOnFetchError() bool {
+	return true
+}
@@ -0,0 +1,84 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+)
+
+// maxInterstitials is the number of times a job served the consent or the
+// captcha page is pushed again, through another proxy, before it fails
+const maxInterstitials = 3
+
+// ErrInterstitial is the error of the jobs served the consent or the captcha
+// page on every try
+var ErrInterstitial = errors.New("google served an interstitial page")
+
+// checkInterstitial accepts the consent and the captcha pages besides the
+// responses accepted by check: the jobs process them to push themselves
+// again instead of failing. The error of a response refused is recorded in f.
+func checkInterstitial(resp *scrapemate.Response, check func(*scrapemate.Response) bool, f *jobFailure) bool {
+	ok := fetcher.Interstitial(resp) != "" || check(resp)
+	f.checked(resp, ok)
+
+	return ok
+}
+
+// interstitialError returns the error of a job served the page on its last
+// try, nil when the job is pushed again. tries is the number of the previous
+// tries served an interstitial page.
+func interstitialError(ctx context.Context, page string, tries int) error {
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	if tries >= maxInterstitials {
+		log.Error("google served an interstitial page on every try", "page", page, "tries", tries+1)
+
+		return fmt.Errorf("%w: %s", ErrInterstitial, page)
+	}
+
+	log.Warn("google served an interstitial page, the job is pushed again", "page", page, "try", tries+1)
+
+	return nil
+}
+
+// setConsentCookie rejects the cookie consent in the browser before the page
+// is visited on the Google domain, google.com when empty, so that it is not
+// served the consent page
+func setConsentCookie(page playwright.Page, domain string) {
+	if domain == "" {
+		domain = DefaultDomain
+	}
+
+	_ = page.Context().AddCookies([]playwright.OptionalCookie{{
+		Name:   fetcher.ConsentCookieName,
+		Value:  fetcher.ConsentCookieValue,
+		Domain: playwright.String("." + domain),
+		Path:   playwright.String("/"),
+	}})
+}
+
+// interstitialPage sets the response of the page when the browser was served
+// the consent or the captcha page, the job processes it without waiting for
+// the results
+func interstitialPage(page playwright.Page, pageResponse playwright.Response, resp *scrapemate.Response) bool {
+	u := page.URL()
+
+	if fetcher.Interstitial(&scrapemate.Response{URL: u}) == "" {
+		return false
+	}
+
+	resp.URL = u
+	resp.StatusCode = pageResponse.Status()
+
+	body, err := page.Content()
+	if err == nil {
+		resp.Body = []byte(body)
+	}
+
+	return true
+}
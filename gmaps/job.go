@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,24 +16,52 @@ import (
 	"github.com/google/uuid"
 	"github.com/gosom/scrapemate"
 	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/tracing"
 )
 
 type GmapJobOptions func(*GmapJob)
 
 type GmapJob struct {
 	scrapemate.Job
+	tracing.Carrier
 
 	MaxDepth     int
 	LangCode     string
 	ExtractEmail bool
+	// Query and GeoCoordinates are the search of the job, they are logged with every record
+	Query          string
+	GeoCoordinates string
 
 	Deduper             deduper.Deduper
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+	ExtractPosts        bool
+	ExtractProducts     bool
+	ExtractMenu         bool
+	ExtractQuestions    bool
+	ExtractHotels       bool
+	DeterministicID     bool
 	ValidatePlaceIdUrl  string
+	Sample              Sample
+	EmailPages          int
+	ReviewLimits        ReviewLimits
+	Metadata            map[string]string
+	// Interstitials is the number of the previous tries of the search served
+	// the consent or the captcha page, the tries are the children of the seed
+	Interstitials int
+	// Gl and Domain are the region of the search, see WithRegion
+	Gl     string
+	Domain string
+
+	images       *ImageDownloader
+	emailFetcher *EmailFetcher
+	known        Known
+	failure      jobFailure
 }
 
 func NewGmapJob(
@@ -43,6 +73,7 @@ func NewGmapJob(
 	validatePlaceIdUrl string,
 	opts ...GmapJobOptions,
 ) *GmapJob {
+	unescaped := query
 	query = url.QueryEscape(query)
 
 	const (
@@ -50,10 +81,6 @@ func NewGmapJob(
 		prio       = scrapemate.PriorityLow
 	)
 
-	if id == "" {
-		id = uuid.New().String()
-	}
-
 	mapURL := ""
 	if geoCoordinates != "" && zoom > 0 {
 		mapURL = fmt.Sprintf("https://www.google.com/maps/search/%s/@%s,%dz", query, strings.ReplaceAll(geoCoordinates, " ", ""), zoom)
@@ -74,6 +101,8 @@ func NewGmapJob(
 		MaxDepth:           maxDepth,
 		LangCode:           langCode,
 		ExtractEmail:       extractEmail,
+		Query:              unescaped,
+		GeoCoordinates:     geoCoordinates,
 		ValidatePlaceIdUrl: validatePlaceIdUrl,
 	}
 
@@ -81,6 +110,21 @@ func NewGmapJob(
 		opt(&job)
 	}
 
+	job.Headers = localeHeaders(job.Headers, langCode, job.Gl)
+
+	switch {
+	case job.ID != "":
+	case job.DeterministicID:
+		parts := []string{langCode, unescaped, geoCoordinates, strconv.Itoa(zoom)}
+		if job.Gl != "" || job.Domain != "" {
+			parts = append(parts, job.Gl, job.Domain)
+		}
+
+		job.ID = JobID("gmap", parts...)
+	default:
+		job.ID = uuid.New().String()
+	}
+
 	return &job
 }
 
@@ -102,22 +146,276 @@ func WithValidatePlaceIdUrl(v string) GmapJobOptions {
 	}
 }
 
+// WithSample processes only a sample of the places found, a random one or the
+// first places
+func WithSample(s Sample) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Sample = s
+	}
+}
+
+// WithEmailPages sets the number of pages of each website visited to find emails
+func WithEmailPages(n int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.EmailPages = n
+	}
+}
+
 func WithExtraReviews() GmapJobOptions {
 	return func(j *GmapJob) {
 		j.ExtractExtraReviews = true
 	}
 }
 
+// WithReviewLimits limits the extra reviews fetched per place
+func WithReviewLimits(l ReviewLimits) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ReviewLimits = l
+	}
+}
+
+// WithMetadata sets the metadata of the input query on every entry
+func WithMetadata(m map[string]string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Metadata = m
+	}
+}
+
+// WithHeaders sends the headers with the requests of the search and of the
+// places found, e.g. the Accept-Language of -accept-language
+func WithHeaders(headers map[string]string) GmapJobOptions {
+	return func(j *GmapJob) {
+		if j.Headers == nil {
+			j.Headers = make(map[string]string, len(headers))
+		}
+
+		maps.Copy(j.Headers, headers)
+	}
+}
+
+// WithExtraPosts collects the posts of the Updates tab of every place
+func WithExtraPosts() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExtractPosts = true
+	}
+}
+
+// WithExtraProducts collects the products of the Products tab of every place
+func WithExtraProducts() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExtractProducts = true
+	}
+}
+
+// WithExtraMenu collects the items of the Menu tab of every place
+func WithExtraMenu() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExtractMenu = true
+	}
+}
+
+// WithExtraQuestions collects the questions and answers of every place
+func WithExtraQuestions() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExtractQuestions = true
+	}
+}
+
+// WithExtraHotels collects the rates and the amenities of every place to stay
+func WithExtraHotels() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExtractHotels = true
+	}
+}
+
+// WithDeterministicID derives the ids of the search and of its place jobs from
+// the query, the coordinates, the zoom and the places instead of random ids.
+// The ids of the input are kept.
+func WithDeterministicID() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.DeterministicID = true
+	}
+}
+
+// WithEmailFetcher fetches the websites of the places with f to find emails
+func WithEmailFetcher(f *EmailFetcher) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.emailFetcher = f
+	}
+}
+
+// WithImages downloads the photos of every place with d
+func WithImages(d *ImageDownloader) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.images = d
+	}
+}
+
 func (j *GmapJob) UseInResults() bool {
 	return false
 }
 
+// UseBrowser renders the search with the browser, the searches of the fast
+// mode falling back to it included
+func (j *GmapJob) UseBrowser() bool {
+	return true
+}
+
+// DoCheckResponse accepts the consent and the captcha pages, the job is
+// pushed again
+func (j *GmapJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	return checkInterstitial(resp, j.Job.DoCheckResponse, &j.failure)
+}
+
+// seedID returns the id of the seed of the job, the places are its results
+func (j *GmapJob) seedID() string {
+	if j.Interstitials > 0 {
+		return j.ParentID
+	}
+
+	return j.ID
+}
+
+// center returns the coordinates of the search, false without -geo
+func (j *GmapJob) center() (lat, lon float64, ok bool) {
+	latStr, lonStr, ok := strings.Cut(j.GeoCoordinates, ",")
+	if !ok {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	lon, err = strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return lat, lon, true
+}
+
+// placeJobOptions returns the options of the place jobs of the search
+func (j *GmapJob) placeJobOptions() []PlaceJobOptions {
+	jopts := []PlaceJobOptions{}
+	if j.ExitMonitor != nil {
+		jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
+	}
+
+	if j.EmailPages > 0 {
+		jopts = append(jopts, WithPlaceJobEmailPages(j.EmailPages))
+	}
+
+	if !j.ReviewLimits.IsZero() {
+		jopts = append(jopts, WithPlaceJobReviewLimits(j.ReviewLimits))
+	}
+
+	if len(j.Metadata) > 0 {
+		jopts = append(jopts, WithPlaceJobMetadata(j.Metadata))
+	}
+
+	jopts = append(jopts, WithPlaceJobQuery(j.Query))
+
+	if lat, lon, ok := j.center(); ok {
+		jopts = append(jopts, WithPlaceJobCenter(lat, lon))
+	}
+
+	if j.Gl != "" {
+		jopts = append(jopts, WithPlaceJobGl(j.Gl))
+	}
+
+	jopts = append(jopts, WithPlaceJobHeaders(j.Headers))
+
+	if j.ExtractPosts {
+		jopts = append(jopts, WithPlaceJobPosts())
+	}
+
+	if j.ExtractProducts {
+		jopts = append(jopts, WithPlaceJobProducts())
+	}
+
+	if j.ExtractMenu {
+		jopts = append(jopts, WithPlaceJobMenu())
+	}
+
+	if j.ExtractQuestions {
+		jopts = append(jopts, WithPlaceJobQuestions())
+	}
+
+	if j.ExtractHotels {
+		jopts = append(jopts, WithPlaceJobHotels())
+	}
+
+	if j.DeterministicID {
+		jopts = append(jopts, WithPlaceJobDeterministicID())
+	}
+
+	if j.images != nil {
+		jopts = append(jopts, WithPlaceJobImages(j.images))
+	}
+
+	if j.emailFetcher != nil {
+		jopts = append(jopts, WithPlaceJobEmailFetcher(j.emailFetcher))
+	}
+
+	return jopts
+}
+
+// again pushes the search again after the interstitial page, the seed is
+// completed with the error of the last try
+func (j *GmapJob) again(ctx context.Context, page string) ([]scrapemate.IJob, error) {
+	if err := interstitialError(ctx, page, j.Interstitials); err != nil {
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrSeedCompleted(1)
+		}
+
+		return nil, err
+	}
+
+	next := *j
+	next.ID = uuid.New().String()
+	next.ParentID = j.seedID()
+	next.Interstitials++
+
+	return []scrapemate.IJob{&next}, nil
+}
+
+// logContext returns ctx with a logger that adds the search of the job to the records
+func (j *GmapJob) logContext(ctx context.Context) context.Context {
+	log := scrapemate.GetLoggerFromContext(ctx).With("job_id", j.ID, "query", j.Query, "geo", j.GeoCoordinates)
+
+	return scrapemate.ContextWithLogger(ctx, log)
+}
+
 func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	ctx = j.logContext(ctx)
+
+	ctx, span := tracing.StartJob(ctx, j, "gmaps.search.process")
+
+	data, next, err := j.process(ctx, resp)
+
+	span.SetAttributes(attribute.Int("gmaps.places", len(next)))
+	tracing.Propagate(ctx, next)
+	tracing.End(span, err)
+
+	j.failure.processed(err)
+
+	return data, next, err
+}
+
+func (j *GmapJob) process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 	}()
 
+	if page := fetcher.Interstitial(resp); page != "" {
+		next, err := j.again(ctx, page)
+
+		return nil, next, err
+	}
+
 	log := scrapemate.GetLoggerFromContext(ctx)
 
 	doc, ok := resp.Document.(*goquery.Document)
@@ -125,20 +423,29 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 		return nil, nil, fmt.Errorf("could not convert to goquery document")
 	}
 
-	var next []scrapemate.IJob
+	var (
+		next  []scrapemate.IJob
+		known int
+		// keys are the places of the results, the known and the duplicate ones too
+		keys []string
+	)
 
 	if strings.Contains(resp.URL, "/maps/place/") {
-		jopts := []PlaceJobOptions{}
-		if j.ExitMonitor != nil {
-			jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-		}
+		keys = append(keys, placeKey(resp.URL))
 
-		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
+		placeJob := NewPlaceJob(j.seedID(), j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, j.placeJobOptions()...)
 
 		next = append(next, placeJob)
 	} else {
 		doc.Find(`div[role=feed] div[jsaction]>a`).Each(func(_ int, s *goquery.Selection) {
 			if href := s.AttrOr("href", ""); href != "" {
+				keys = append(keys, placeKey(href))
+
+				if KnownPlace(ctx, j.known, href) {
+					known++
+
+					return
+				}
 
 				if j.ValidatePlaceIdUrl != "" {
 					// Make GET request
@@ -166,26 +473,26 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 					}
 				}
 
-				jopts := []PlaceJobOptions{}
-				if j.ExitMonitor != nil {
-					jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-				}
-
-				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
+				nextJob := NewPlaceJob(j.seedID(), j.LangCode, href, j.ExtractEmail, j.ExtractExtraReviews, j.placeJobOptions()...)
 
-				if j.Deduper == nil || j.Deduper.AddIfNotExists(ctx, href) {
+				// the urls of a place differ between the queries, its data id does not
+				if j.Deduper == nil || j.Deduper.AddIfNotExists(ctx, placeKey(href)) {
 					next = append(next, nextJob)
 				}
 			}
 		})
+
+		next = sample(next, j.Sample)
 	}
 
 	if j.ExitMonitor != nil {
+		j.ExitMonitor.AddPlaces(j.Query, keys)
 		j.ExitMonitor.IncrPlacesFound(len(next))
 		j.ExitMonitor.IncrSeedCompleted(1)
+		j.ExitMonitor.RecordQuery(j.Query, len(next))
 	}
 
-	log.Info(fmt.Sprintf("%d places found", len(next)))
+	log.Info("places found", "places", len(next), "known", known)
 
 	return nil, next, nil
 }
@@ -193,8 +500,19 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
+	ctx = j.logContext(ctx)
+	log := scrapemate.GetLoggerFromContext(ctx)
+
 	fullURL := j.GetFullURL()
-	fmt.Printf("Visiting URL: %s\n", fullURL)
+	log.Debug("visiting url", "url", fullURL)
+
+	setConsentCookie(page, j.Domain)
+
+	if err := setPageHeaders(page, j.GetHeaders()); err != nil {
+		resp.Error = err
+
+		return resp
+	}
 
 	const navigationTimeout = 30000 // 30 seconds
 
@@ -204,13 +522,17 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 	})
 	if err != nil {
 		resp.Error = fmt.Errorf("navigation failed: %w", err)
-		fmt.Printf("Navigation error: %v\n", err)
+		log.Warn("navigation failed", "url", fullURL, "error", err)
 
 		return resp
 	}
 
 	clickRejectCookiesIfRequired(page)
 
+	if interstitialPage(page, pageResponse, &resp) {
+		return resp
+	}
+
 	const defaultTimeout = 5000
 
 	// Wait for the URL to stabilize
@@ -219,7 +541,7 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 		Timeout:   playwright.Float(defaultTimeout),
 	})
 	if err != nil {
-		fmt.Printf("URL stabilization error (non-fatal): %v\n", err)
+		log.Debug("url not stabilized", "error", err)
 		// Don't return yet, continue with the process
 	}
 
@@ -269,7 +591,7 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 		if !singlePlace {
 			// If we're not in a single place view and couldn't find the feed selector,
 			// check if we've been redirected to a search results view with a different structure
-			fmt.Println("Feed not found, checking for alternative results structure...")
+			log.Debug("feed not found, using the page content")
 
 			// Try one last approach - just get the page content regardless
 			singlePlace = true
@@ -296,13 +618,13 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 	}
 
 	// Handle search results with scrolling
-	scrollCnt, err := scroll(ctx, page, j.MaxDepth, feedSelector)
+	scrollCnt, err := scroll(ctx, page, j.Sample.scrollDepth(j.MaxDepth), feedSelector)
 	if err != nil {
-		fmt.Printf("Scroll error: %v\n", err)
+		log.Warn("scroll failed", "error", err)
 		// Continue to get the content anyway
 	}
 
-	fmt.Printf("Scrolled %d times\n", scrollCnt)
+	log.Debug("scrolled", "times", scrollCnt)
 
 	// Get the final page content
 	body, err := page.Content()
@@ -383,9 +705,9 @@ func scroll(ctx context.Context,
 	}`, scrollSelector))
 
 	if err != nil {
-		fmt.Printf("Error checking for scrollable elements: %v\n", err)
+		scrapemate.GetLoggerFromContext(ctx).Warn("cannot check for scrollable elements", "error", err)
 	} else if hasElement.(bool) == false {
-		fmt.Println("No scrollable elements found, will try to scroll the document body")
+		scrapemate.GetLoggerFromContext(ctx).Debug("no scrollable elements found, scrolling the document body")
 
 		// If no elements found, just scroll the document and return
 		for i := 0; i < maxDepth; i++ {
@@ -500,7 +822,7 @@ func scroll(ctx context.Context,
 		// Scroll to the bottom of the page.
 		scrollHeight, err := page.Evaluate(fmt.Sprintf(expr, waitTime2))
 		if err != nil {
-			fmt.Printf("Scroll error on iteration %d: %v\n", i, err)
+			scrapemate.GetLoggerFromContext(ctx).Warn("scroll failed", "iteration", i, "error", err)
 
 			// Try a simple fallback
 			_, fallbackErr := page.Evaluate(`() => {
@@ -523,7 +845,7 @@ func scroll(ctx context.Context,
 			if floatHeight, isFloat := scrollHeight.(float64); isFloat {
 				height = int(floatHeight)
 			} else {
-				fmt.Printf("Unexpected scrollHeight type: %T\n", scrollHeight)
+				scrapemate.GetLoggerFromContext(ctx).Warn("unexpected scroll height", "type", fmt.Sprintf("%T", scrollHeight))
 				// Continue with fallback scrolling
 				_, fallbackErr := page.Evaluate(`() => {
 					window.scrollBy(0, 500);
@@ -0,0 +1,41 @@
+package gmaps
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// jobIDNamespace is the namespace of the UUIDs of JobID
+var jobIDNamespace = uuid.MustParse("8a6f4d1e-2b7c-4f0a-9d3e-5c1b7a2e6f90")
+
+// JobID returns the deterministic id of a job, a UUID derived from its kind
+// and the parts identifying it. The same logical job gets the same id every
+// time it is created, so the queues and the dedupers recognize a job pushed
+// again, e.g. when a run is submitted twice.
+func JobID(kind string, parts ...string) string {
+	return uuid.NewSHA1(jobIDNamespace, []byte(kind+"\x00"+strings.Join(parts, "\x00"))).String()
+}
+
+// jobID returns the deterministic id of the search of the params
+func (p *MapSearchParams) jobID() string {
+	coord := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 6, 64)
+	}
+
+	viewport := Viewport{Width: p.ViewportW, Height: p.ViewportH}.size()
+
+	parts := []string{
+		p.Hl, p.Query, p.Category, strconv.FormatBool(p.Locationless),
+		coord(p.Location.Lat), coord(p.Location.Lon), coord(p.Location.ZoomLvl), coord(p.Location.Radius),
+		strconv.Itoa(viewport.Width), strconv.Itoa(viewport.Height), strconv.Itoa(p.Offset),
+	}
+
+	// the searches without region keep their ids
+	if p.Gl != "" || p.Domain != "" {
+		parts = append(parts, p.Gl, p.Domain)
+	}
+
+	return JobID("search", parts...)
+}
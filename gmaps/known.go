@@ -0,0 +1,92 @@
+package gmaps
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var placeDataIDRegex = regexp.MustCompile(`!1s(0x[0-9a-fA-F]+:0x[0-9a-fA-F]+)`)
+
+// Known are the places scraped by the previous runs. The GmapJobs do not
+// fetch the details of the known places again, see WithKnown.
+type Known interface {
+	// Seen reports whether the place of key, its cid or its data id, is known
+	Seen(ctx context.Context, key string) bool
+}
+
+// PlaceKeys returns the data id and the cid of the place of a place url.
+// They are empty when the url has none.
+func PlaceKeys(u string) (dataID, cid string) {
+	if m := placeDataIDRegex.FindStringSubmatch(u); m != nil {
+		return m[1], CidFromDataID(m[1])
+	}
+
+	if parsed, err := url.Parse(u); err == nil {
+		cid = parsed.Query().Get("cid")
+	}
+
+	return "", cid
+}
+
+// placeKey returns the key of the place of a place url, its data id, or the
+// url when it has none
+func placeKey(u string) string {
+	if dataID, _ := PlaceKeys(u); dataID != "" {
+		return dataID
+	}
+
+	return u
+}
+
+// CidFromDataID returns the cid of the place of a data id, the second part of
+// the data id in hexadecimal. It is empty when dataID is not a data id.
+func CidFromDataID(dataID string) string {
+	if !dataIDRegex.MatchString(dataID) {
+		return ""
+	}
+
+	_, hex, _ := strings.Cut(dataID, ":")
+
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(hex), "0x"), 16, 64)
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatUint(n, 10)
+}
+
+// KnownPlace reports whether the place of the place url u is known
+func KnownPlace(ctx context.Context, known Known, u string) bool {
+	dataID, cid := PlaceKeys(u)
+
+	return knownKeys(ctx, known, cid, dataID)
+}
+
+// KnownEntry reports whether the place of the entry is known
+func KnownEntry(ctx context.Context, known Known, e *Entry) bool {
+	return knownKeys(ctx, known, e.Cid, e.DataID)
+}
+
+func knownKeys(ctx context.Context, known Known, keys ...string) bool {
+	if known == nil {
+		return false
+	}
+
+	for _, key := range keys {
+		if key != "" && known.Seen(ctx, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithKnown skips the places of known, their details are not fetched
+func WithKnown(known Known) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.known = known
+	}
+}
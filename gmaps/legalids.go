@@ -0,0 +1,89 @@
+package gmaps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// vatRegex matches the EU (and UK, Swiss, Norwegian) VAT number formats.
+// Spaces, dots and dashes inside the number are allowed.
+var vatRegex = regexp.MustCompile(`\b(?:` + strings.Join([]string{
+	`ATU[\s.]?\d{8}`,
+	`BE[\s.]?[01][\s.]?\d{3}[\s.]?\d{3}[\s.]?\d{3}`,
+	`CHE[\s.-]?\d{3}[\s.]?\d{3}[\s.]?\d{3}(?:\s?(?:MWST|TVA|IVA))?`,
+	`CY[\s.]?\d{8}[A-Z]`,
+	`CZ[\s.]?\d{8,10}`,
+	`DE[\s.]?\d{3}[\s.]?\d{3}[\s.]?\d{3}`,
+	`DK[\s.]?\d{2}[\s.]?\d{2}[\s.]?\d{2}[\s.]?\d{2}`,
+	`EL[\s.]?\d{9}`,
+	`ES[\s.]?[A-Z0-9]\d{7}[A-Z0-9]`,
+	`FI[\s.]?\d{8}`,
+	`FR[\s.]?[0-9A-Z]{2}[\s.]?\d{3}[\s.]?\d{3}[\s.]?\d{3}`,
+	`GB[\s.]?\d{3}[\s.]?\d{4}[\s.]?\d{2}(?:\d{3})?`,
+	`HR[\s.]?\d{11}`,
+	`HU[\s.]?\d{8}`,
+	`IE[\s.]?\d{7}[A-W][A-I]?`,
+	`IT[\s.]?\d{11}`,
+	`LU[\s.]?\d{8}`,
+	`NL[\s.]?\d{9}[\s.]?B[\s.]?\d{2}`,
+	`NO[\s.]?\d{9}[\s.]?MVA`,
+	`PL[\s.]?\d{10}`,
+	`PT[\s.]?\d{9}`,
+	`RO[\s.]?\d{2,10}`,
+	`SE[\s.]?\d{12}`,
+	`SI[\s.]?\d{8}`,
+	`SK[\s.]?\d{10}`,
+}, "|") + `)\b`)
+
+// registrationRegexes match the company registration numbers, with the
+// label used in the output
+var registrationRegexes = []struct {
+	label string
+	re    *regexp.Regexp
+}{
+	// Handelsregister (DE, AT)
+	{label: "", re: regexp.MustCompile(`\b(HR[AB])\s?(\d{2,6}(?:\s?[A-Z]{1,2})?)\b`)},
+	{label: "FN", re: regexp.MustCompile(`\bFN\s?(\d{5,6}\s?[a-z])\b`)},
+	{label: "SIRET", re: regexp.MustCompile(`(?i)\bSIRET\s*(?:n[°o]\.?)?\s*:?\s*(\d{3}\s?\d{3}\s?\d{3}\s?\d{5})\b`)},
+	{label: "SIREN", re: regexp.MustCompile(`(?i)\bSIREN\s*(?:n[°o]\.?)?\s*:?\s*(\d{3}\s?\d{3}\s?\d{3})\b`)},
+	{label: "KvK", re: regexp.MustCompile(`(?i)\b(?:KvK|Kamer van Koophandel)(?:[\s-]*(?:nummer|nr\.?))?\s*:?\s*(\d{8})\b`)},
+	{label: "Company No", re: regexp.MustCompile(`(?i)\bCompany\s+(?:Registration\s+)?(?:No\.?|Number)\s*:?\s*([A-Z]{2}\d{6}|\d{8})\b`)},
+	{label: "CRN", re: regexp.MustCompile(`(?i)\b(?:CRN|Registro Mercantil|REA|CVR)\s*(?:n[°o.]*)?\s*:?\s*([A-Z]{0,2}[\s-]?\d{5,10})\b`)},
+}
+
+// vatLabelRegex matches the labels of the VAT numbers in the languages of the formats above.
+// The numbers are only looked for right after a label, since short formats match random text.
+var vatLabelRegex = regexp.MustCompile(`(?i)(?:VAT|USt|UID|TVA|IVA|BTW|MwSt|MWST|NIP|NIF|CIF|ΑΦΜ|moms|ALV|DIČ|DPH|ADÓ|OIB|PVM|MVA|Tax ID)`)
+
+var nonAlnumRegex = regexp.MustCompile(`[^0-9A-Z]`)
+
+// extractLegalIDs finds the VAT numbers and the company registration numbers in text.
+// VAT numbers are normalized without separators.
+func extractLegalIDs(text string) (vat, registration []string) {
+	// how far after the label the number is looked for
+	const vatWindow = 40
+
+	for _, loc := range vatLabelRegex.FindAllStringIndex(text, -1) {
+		end := min(loc[1]+vatWindow, len(text))
+
+		if m := vatRegex.FindString(text[loc[1]:end]); m != "" {
+			vat = appendUnique(vat, nonAlnumRegex.ReplaceAllString(strings.ToUpper(m), ""))
+		}
+	}
+
+	for _, r := range registrationRegexes {
+		for _, m := range r.re.FindAllStringSubmatch(text, -1) {
+			var id string
+
+			if r.label == "" {
+				id = m[1] + " " + m[2]
+			} else {
+				id = r.label + " " + strings.ReplaceAll(m[1], " ", "")
+			}
+
+			registration = appendUnique(registration, strings.Join(strings.Fields(id), " "))
+		}
+	}
+
+	return vat, registration
+}
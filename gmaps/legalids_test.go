@@ -0,0 +1,61 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_ExtractLegalIDs(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		vat          []string
+		registration []string
+	}{
+		{
+			name:         "german imprint",
+			text:         "Amtsgericht München HRB 123456, USt-IdNr.: DE 123 456 789",
+			vat:          []string{"DE123456789"},
+			registration: []string{"HRB 123456"},
+		},
+		{
+			name:         "french legal notice",
+			text:         "SIRET : 123 456 789 00012 - TVA intracommunautaire FR 12 345 678 901",
+			vat:          []string{"FR12345678901"},
+			registration: []string{"SIRET 12345678900012"},
+		},
+		{
+			name:         "dutch footer",
+			text:         "KvK-nummer: 12345678 | BTW: NL123456789B01",
+			vat:          []string{"NL123456789B01"},
+			registration: []string{"KvK 12345678"},
+		},
+		{
+			name:         "uk company",
+			text:         "Company No. 01234567. VAT Reg No GB 123 4567 89",
+			vat:          []string{"GB123456789"},
+			registration: []string{"Company No 01234567"},
+		},
+		{
+			name:         "duplicates",
+			text:         "VAT: ATU12345678 ... VAT ATU12345678, FN 123456a, FN 123456a",
+			vat:          []string{"ATU12345678"},
+			registration: []string{"FN 123456a"},
+		},
+		{
+			name: "number without a label",
+			text: "Call DE123456789 for orders",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vat, registration := gmaps.ExtractLegalIDs(tc.text)
+			require.Equal(t, tc.vat, vat)
+			require.Equal(t, tc.registration, registration)
+		})
+	}
+}
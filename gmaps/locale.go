@@ -0,0 +1,148 @@
+package gmaps
+
+import (
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+)
+
+// acceptLanguageHeader is the header of the languages of the requests, Google
+// formats the numbers and the dates of the pages with it
+const acceptLanguageHeader = "Accept-Language"
+
+// decimalComma are the languages whose numbers have a decimal comma, e.g.
+// 4,5 or 1.234,50 in German
+var decimalComma = map[string]bool{
+	"af": true, "az": true, "be": true, "bg": true, "bs": true, "ca": true, "cs": true, "da": true,
+	"de": true, "el": true, "es": true, "et": true, "eu": true, "fi": true, "fr": true, "gl": true,
+	"hr": true, "hu": true, "hy": true, "id": true, "is": true, "it": true, "ka": true, "kk": true,
+	"lt": true, "lv": true, "mk": true, "mn": true, "nb": true, "nl": true, "nn": true, "no": true,
+	"pl": true, "pt": true, "ro": true, "ru": true, "sk": true, "sl": true, "sq": true, "sr": true,
+	"sv": true, "tr": true, "uk": true, "uz": true, "vi": true,
+}
+
+// decimalPoint are the regions of these languages whose numbers have a
+// decimal point, e.g. 1,234.50 in Mexico or 1'234.50 in Switzerland
+var decimalPoint = map[string]bool{
+	"es-419": true, "es-mx": true, "es-us": true, "de-ch": true, "it-ch": true,
+}
+
+// groupSeparators removes the spaces and the apostrophes grouping the
+// thousands, e.g. 1 234 in French or 1'234 in Swiss German
+var groupSeparators = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "\u2009", "", "'", "", "\u2019", "")
+
+// AcceptLanguage returns the Accept-Language header of the language hl, e.g.
+// de or pt-BR, in the country gl: de-DE,de;q=0.9 for de in de
+func AcceptLanguage(hl, gl string) string {
+	hl = strings.TrimSpace(hl)
+	if hl == "" {
+		hl = "en"
+	}
+
+	lang, region, ok := strings.Cut(hl, "-")
+	if !ok && gl != "" {
+		region = gl
+	}
+
+	if region == "" {
+		return lang
+	}
+
+	return lang + "-" + strings.ToUpper(region) + "," + lang + ";q=0.9"
+}
+
+// localeHeaders returns headers with the Accept-Language of hl in gl, unless
+// they have one already, e.g. the one of -accept-language
+func localeHeaders(headers map[string]string, hl, gl string) map[string]string {
+	if _, ok := headers[acceptLanguageHeader]; ok {
+		return headers
+	}
+
+	ans := make(map[string]string, len(headers)+1)
+	maps.Copy(ans, headers)
+	ans[acceptLanguageHeader] = AcceptLanguage(hl, gl)
+
+	return ans
+}
+
+// setPageHeaders sends the headers of the job with the requests of the page,
+// the browsers do not send the headers of the jobs
+func setPageHeaders(page playwright.Page, headers map[string]string) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return page.SetExtraHTTPHeaders(headers)
+}
+
+// setLanguage sets the language of the entry and parses its fields formatted
+// in the language again, they are parsed in English by default
+func (e *Entry) setLanguage(hl string) {
+	e.Language = hl
+	e.PriceLevel = priceLevel(e.PriceRange, hl)
+}
+
+// responseLanguage returns the language of the response, the hl parameter of
+// its URL
+func responseLanguage(resp *scrapemate.Response) string {
+	u, err := url.Parse(resp.URL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Query().Get("hl")
+}
+
+// decimalSeparator returns the decimal separator of the numbers of hl
+func decimalSeparator(hl string) string {
+	hl = strings.ToLower(strings.TrimSpace(hl))
+	lang, _, _ := strings.Cut(hl, "-")
+
+	if decimalComma[lang] && !decimalPoint[hl] {
+		return ","
+	}
+
+	return "."
+}
+
+// parseNumber parses a number as formatted by Google in the language hl,
+// e.g. 1,234.5 in English, 1.234,5 in German or 1 234,5 in French. A number
+// with both separators or with a separator repeated has its thousands
+// grouped, one separator not followed by three digits is the decimal one
+// whatever the language, e.g. 4,5 or 1.85. Only 1,234 and 1.234 depend on hl.
+func parseNumber(s, hl string) (float64, error) {
+	s = groupSeparators.Replace(strings.TrimSpace(s))
+
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+
+	const groupDigits = 3
+
+	switch {
+	case dot >= 0 && comma >= 0:
+		if dot > comma {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+		}
+	case dot >= 0 || comma >= 0:
+		sep, i := ".", dot
+		if comma >= 0 {
+			sep, i = ",", comma
+		}
+
+		grouped := strings.Count(s, sep) > 1 ||
+			(len(s)-i-1 == groupDigits && sep != decimalSeparator(hl))
+
+		if grouped {
+			s = strings.ReplaceAll(s, sep, "")
+		} else {
+			s = strings.Replace(s, sep, ".", 1)
+		}
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,29 @@
+package gmaps
+
+// Localized are the names of a place in another language than the one of its
+// entry, see Entry.Localize
+type Localized struct {
+	Title      string   `json:"title"`
+	Category   string   `json:"category"`
+	Categories []string `json:"categories"`
+	Address    string   `json:"address"`
+}
+
+// Localize adds the names of other, the entry of the same place found in
+// another language, to the localized names of the entry
+func (e *Entry) Localize(other *Entry) {
+	if other.Language == "" || other.Language == e.Language {
+		return
+	}
+
+	if e.Localized == nil {
+		e.Localized = make(map[string]Localized)
+	}
+
+	e.Localized[other.Language] = Localized{
+		Title:      other.Title,
+		Category:   other.Category,
+		Categories: other.Categories,
+		Address:    other.Address,
+	}
+}
@@ -0,0 +1,127 @@
+package gmaps
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// lodgingCategoryRegex matches the categories of the places to stay
+var lodgingCategoryRegex = regexp.MustCompile(`(?i)\b(hotel|motel|hostel|inn|resort|lodging|lodge|bed & breakfast|guest ?house|aparthotel|holiday apartment|campground)\b`)
+
+// starClassRegex matches the hotel class shown under the name, e.g. "4-star hotel"
+var starClassRegex = regexp.MustCompile(`(?i)^\s*([1-5])[- ]star\b`)
+
+// amenityRegexes map the amenities to the names of the options of the About tab.
+// The first matching amenity wins, so the specific ones come first.
+var amenityRegexes = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{name: "ev_charging", re: regexp.MustCompile(`(?i)\b(ev charg|electric vehicle|charging station)`)},
+	{name: "airport_shuttle", re: regexp.MustCompile(`(?i)\bairport (shuttle|transfer)`)},
+	{name: "wifi", re: regexp.MustCompile(`(?i)\b(wi-?fi|internet)\b`)},
+	{name: "pool", re: regexp.MustCompile(`(?i)\bpools?\b`)},
+	{name: "parking", re: regexp.MustCompile(`(?i)\bparking\b`)},
+	{name: "pet_friendly", re: regexp.MustCompile(`(?i)\b(pets?|dogs? allowed|dog-friendly)\b`)},
+	{name: "breakfast", re: regexp.MustCompile(`(?i)\bbreakfast\b`)},
+	{name: "air_conditioning", re: regexp.MustCompile(`(?i)\bair[- ]condition`)},
+	{name: "fitness_center", re: regexp.MustCompile(`(?i)\b(fitness|gym)\b`)},
+	{name: "spa", re: regexp.MustCompile(`(?i)\b(spa|sauna|hot tub)\b`)},
+	{name: "restaurant", re: regexp.MustCompile(`(?i)\brestaurants?\b`)},
+	{name: "bar", re: regexp.MustCompile(`(?i)\bbars?\b`)},
+	{name: "room_service", re: regexp.MustCompile(`(?i)\broom service\b`)},
+	{name: "laundry", re: regexp.MustCompile(`(?i)\blaundry\b`)},
+	{name: "kitchen", re: regexp.MustCompile(`(?i)\bkitchens?\b`)},
+	{name: "accessible", re: regexp.MustCompile(`(?i)\b(accessible|wheelchair)`)},
+	{name: "beach_access", re: regexp.MustCompile(`(?i)\bbeach`)},
+}
+
+// IsLodging reports whether the place is a hotel or another place to stay
+func (e *Entry) IsLodging() bool {
+	for _, c := range e.Categories {
+		if lodgingCategoryRegex.MatchString(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setLodgingDetails sets the star class and the amenities of places to stay.
+// The amenities are the enabled options of the About tab normalized to
+// the names of amenityRegexes, the rest are ignored.
+func (e *Entry) setLodgingDetails(darray []any) {
+	if !e.IsLodging() {
+		return
+	}
+
+	e.StarClass = starClass(darray)
+
+	for _, about := range e.About {
+		for _, opt := range about.Options {
+			if !opt.Enabled {
+				continue
+			}
+
+			if name := amenityName(opt.Name); name != "" {
+				e.Amenities = appendUnique(e.Amenities, name)
+			}
+		}
+	}
+}
+
+// starClass returns the hotel class from the short texts of the place data.
+// The reviews are skipped, their texts may mention other hotels.
+func starClass(darray []any) int {
+	//nolint:gomnd // the indexes of the reviews
+	skip := map[int]bool{52: true, 171: true, 175: true}
+
+	var walk func(v any, depth int) int
+
+	walk = func(v any, depth int) int {
+		const maxDepth = 4
+
+		switch v := v.(type) {
+		case string:
+			if m := starClassRegex.FindStringSubmatch(v); m != nil && len(v) < 40 {
+				n, _ := strconv.Atoi(m[1])
+
+				return n
+			}
+		case []any:
+			if depth >= maxDepth {
+				return 0
+			}
+
+			for _, el := range v {
+				if n := walk(el, depth+1); n > 0 {
+					return n
+				}
+			}
+		}
+
+		return 0
+	}
+
+	for i, v := range darray {
+		if skip[i] {
+			continue
+		}
+
+		if n := walk(v, 0); n > 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+func amenityName(option string) string {
+	for _, a := range amenityRegexes {
+		if a.re.MatchString(option) {
+			return a.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+package gmaps
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+)
+
+var (
+	cidRegex    = regexp.MustCompile(`^\d{5,20}$`)
+	dataIDRegex = regexp.MustCompile(`^0x[0-9a-fA-F]+:0x[0-9a-fA-F]+$`)
+)
+
+// PlaceLookupJob fetches the details of a known place from its url, its cid or
+// its data id, without a search. It is a PlaceJob that is also a seed: the
+// place is parsed and written like the places found by the searches.
+type PlaceLookupJob struct {
+	PlaceJob
+}
+
+// NewPlaceLookupJob returns the job of ref: a Google Maps place url, a cid like
+// 16519582940102929223 or a data id like 0x14e732fd76f0d90d:0xe5415928d6702b47.
+// id is the input id of the entry.
+func NewPlaceLookupJob(id, langCode, ref string, extractEmail, extraReviews bool, opts ...PlaceJobOptions) (*PlaceLookupJob, error) {
+	u, err := PlaceURL(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	job := PlaceLookupJob{
+		PlaceJob: *NewPlaceJob(id, langCode, u, extractEmail, extraReviews, opts...),
+	}
+
+	job.Query = ref
+
+	return &job, nil
+}
+
+// PlaceURL returns the url of the place page of ref, see NewPlaceLookupJob
+func PlaceURL(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	switch {
+	case cidRegex.MatchString(ref):
+		return "https://www.google.com/maps?cid=" + ref, nil
+	case dataIDRegex.MatchString(ref):
+		return "https://www.google.com/maps/place/data=!4m2!3m1!1s" + ref, nil
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid place %q: use a place url, a cid or a data id", ref)
+	}
+
+	if !strings.Contains(u.Path, "/maps/place/") && u.Query().Get("cid") == "" {
+		return "", fmt.Errorf("invalid place url %q: it is not the url of a place", ref)
+	}
+
+	return ref, nil
+}
+
+func (j *PlaceLookupJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	// the place is found by its seed, not by a search
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesFound(1)
+		j.ExitMonitor.IncrSeedCompleted(1)
+		j.ExitMonitor.RecordQuery(j.Query, 1)
+	}
+
+	return j.PlaceJob.Process(ctx, resp)
+}
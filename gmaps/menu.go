@@ -0,0 +1,92 @@
+package gmaps
+
+import (
+	"encoding/gob"
+	"fmt"
+	"regexp"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Menu is the menu of a place: the link to the menu of its website and, with
+// the menu extraction, the items of the Menu tab
+type Menu struct {
+	Link     string        `json:"link"`
+	Source   string        `json:"source"`
+	Sections []MenuSection `json:"sections,omitempty"`
+}
+
+// MenuSection is a heading of the Menu tab with its items, e.g. "Starters"
+type MenuSection struct {
+	Name  string     `json:"name"`
+	Items []MenuItem `json:"items"`
+}
+
+// MenuItem is a dish or a product of the Menu tab
+type MenuItem struct {
+	Name string `json:"name"`
+	// Price is the price as shown, with the currency
+	Price       string `json:"price"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+}
+
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register([]MenuSection{})
+}
+
+// the labels of the Menu tab in the most common languages
+var menuTabRegex = regexp.MustCompile(`(?i)^\s*(menu|speisekarte|carte|menú|carta|cardápio|μενού|menü)\s*$`)
+
+// fetchMenu opens the Menu tab of the place page and returns its items grouped
+// by the section they are listed under. Items before the first heading are in
+// a section without name.
+func fetchMenu(page playwright.Page) ([]MenuSection, error) {
+	var sections []MenuSection
+
+	if err := scrapeTab(page, menuTabRegex, menuSelector, menuJS, &sections); err != nil {
+		return nil, fmt.Errorf("could not scrape the menu tab: %w", err)
+	}
+
+	return sections, nil
+}
+
+const menuSelector = `div[role='main'] div[role='tabpanel'] [role='button'][aria-label], div[role='main'] div[role='tabpanel'] h2`
+
+// the items follow the heading of their section in the document order, the
+// description is the longest text that is neither the name nor the price
+const menuJS = `
+(selector) => {
+	const sections = [];
+	const priceRe = /(?:[$€£¥₹]\s?\d[\d.,]*|\d[\d.,]*\s?(?:[$€£¥₹]|[A-Z]{3}\b))/;
+	let section = null;
+	document.querySelectorAll(selector).forEach((el) => {
+		if (el.tagName === 'H2') {
+			section = { name: el.textContent.trim(), items: [] };
+			sections.push(section);
+			return;
+		}
+		if (!section) {
+			section = { name: '', items: [] };
+			sections.push(section);
+		}
+		const name = el.getAttribute('aria-label').trim();
+		const texts = Array.from(el.querySelectorAll('span, div'))
+			.filter((n) => n.children.length === 0)
+			.map((n) => n.textContent.trim())
+			.filter((t) => t.length > 0);
+		const price = texts.find((t) => priceRe.test(t)) || '';
+		const description = texts.filter((t) => t !== name && t !== price)
+			.reduce((a, t) => (t.length > a.length ? t : a), '');
+		const img = el.querySelector('img[src^="https://"]');
+		section.items.push({
+			name: name,
+			price: price,
+			description: description,
+			image_url: img ? img.src : '',
+		});
+	});
+	return JSON.stringify(sections.filter((s) => s.items.length > 0));
+}
+`
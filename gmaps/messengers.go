@@ -0,0 +1,66 @@
+package gmaps
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var messengerRegex = regexp.MustCompile(
+	`(?i)https?://(?:www\.)?(wa\.me|api\.whatsapp\.com/send|chat\.whatsapp\.com|m\.me|messenger\.com/t|t\.me|telegram\.me)(?:/[^\s"'<>\\]*)?`,
+)
+
+// addMessengerLinks finds the WhatsApp, Messenger and Telegram links in s
+// and adds them to the entry
+func (e *Entry) addMessengerLinks(s string) {
+	if !strings.Contains(s, "://") {
+		return
+	}
+
+	s = extractActualURL(s)
+
+	for _, m := range messengerRegex.FindAllStringSubmatch(s, -1) {
+		link := strings.TrimRight(m[0], ".,;)")
+
+		var target *[]string
+
+		switch strings.ToLower(m[1]) {
+		case "wa.me", "api.whatsapp.com/send", "chat.whatsapp.com":
+			target = &e.WhatsApp
+		case "m.me", "messenger.com/t":
+			target = &e.Messenger
+		default:
+			target = &e.Telegram
+		}
+
+		// a bare domain is not a contact
+		if len(link) <= len("https://")+len(m[1])+1 {
+			continue
+		}
+
+		if !slices.Contains(*target, link) {
+			*target = append(*target, link)
+		}
+	}
+}
+
+// addMessengerLinksFromJSON adds the messenger links found in any string of v
+func (e *Entry) addMessengerLinksFromJSON(v any) {
+	switch val := v.(type) {
+	case string:
+		e.addMessengerLinks(val)
+	case []any:
+		for i := range val {
+			e.addMessengerLinksFromJSON(val[i])
+		}
+	}
+}
+
+// addMessengerLinksFromDoc adds the messenger links of the page
+func (e *Entry) addMessengerLinksFromDoc(doc *goquery.Document) {
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		e.addMessengerLinks(s.AttrOr("href", ""))
+	})
+}
@@ -0,0 +1,270 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gosom/scrapemate"
+)
+
+// ErrSkipEntry can be returned by AfterParseFunc and BeforeWriteFunc
+// to drop an entry without failing the job.
+var ErrSkipEntry = errors.New("skip entry")
+
+// BeforeFetchFunc is called before the request of a job is sent.
+// It may mutate the job, for example by adding headers.
+type BeforeFetchFunc func(ctx context.Context, job scrapemate.IJob) error
+
+// AfterParseFunc is called for every entry right after it has been parsed.
+type AfterParseFunc func(ctx context.Context, job scrapemate.IJob, entry *Entry) error
+
+// BeforeWriteFunc is called for every entry before it reaches the writers.
+type BeforeWriteFunc func(ctx context.Context, entry *Entry) error
+
+// Processor enriches, scores or normalizes every entry before it reaches the
+// writers. It returns the entry written, the same one or another, or nil to
+// drop it. An error stops the writing.
+type Processor func(ctx context.Context, entry *Entry) (*Entry, error)
+
+// Middleware holds the functions that run around the processing of the jobs.
+// Functions run in the order they are registered.
+// Register all the functions before starting the scraping.
+type Middleware struct {
+	beforeFetch []BeforeFetchFunc
+	afterParse  []AfterParseFunc
+	beforeWrite []BeforeWriteFunc
+}
+
+// NewMiddleware creates an empty Middleware
+func NewMiddleware() *Middleware {
+	return &Middleware{}
+}
+
+// UseBeforeFetch registers functions that run before each request
+func (m *Middleware) UseBeforeFetch(fns ...BeforeFetchFunc) *Middleware {
+	m.beforeFetch = append(m.beforeFetch, fns...)
+
+	return m
+}
+
+// UseAfterParse registers functions that run after an entry is parsed
+func (m *Middleware) UseAfterParse(fns ...AfterParseFunc) *Middleware {
+	m.afterParse = append(m.afterParse, fns...)
+
+	return m
+}
+
+// UseBeforeWrite registers functions that run before an entry is written
+func (m *Middleware) UseBeforeWrite(fns ...BeforeWriteFunc) *Middleware {
+	m.beforeWrite = append(m.beforeWrite, fns...)
+
+	return m
+}
+
+// UseProcessors registers processors that run before an entry is written,
+// with the before write functions in the order they are registered
+func (m *Middleware) UseProcessors(ps ...Processor) *Middleware {
+	for _, p := range ps {
+		m.beforeWrite = append(m.beforeWrite, p.beforeWrite)
+	}
+
+	return m
+}
+
+// WrapFetcher returns a fetcher that runs the before fetch functions
+// and then delegates to next.
+func (m *Middleware) WrapFetcher(next scrapemate.HTTPFetcher) scrapemate.HTTPFetcher {
+	if m == nil || len(m.beforeFetch) == 0 {
+		return next
+	}
+
+	return &middlewareFetcher{m: m, next: next}
+}
+
+// FilterResults runs the before write functions once on the entries of every
+// result of in and passes the results to the returned channel, the one the
+// writers share. The result of a dropped entry is not passed, a result of
+// entries is passed without the dropped ones. The channel is closed once in
+// is closed or ctx is done, an error of a function is sent to errc.
+//
+//nolint:gocritic // the results are read like the ones of scrapemate
+func (m *Middleware) FilterResults(ctx context.Context, in <-chan scrapemate.Result) (<-chan scrapemate.Result, <-chan error) {
+	errc := make(chan error, 1)
+
+	if m == nil || len(m.beforeWrite) == 0 {
+		close(errc)
+
+		return in, errc
+	}
+
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		defer close(out)
+		defer close(errc)
+
+		// keep consuming so that the producer does not block
+		defer func() {
+			go func() {
+				for range in {
+				}
+			}()
+		}()
+
+		for result := range in {
+			keep, err := m.beforeWriteResult(ctx, &result)
+			if err != nil {
+				errc <- err
+
+				return
+			}
+
+			if !keep {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- result:
+			}
+		}
+	}()
+
+	return out, errc
+}
+
+// AfterParse runs the after parse functions for entry.
+// It returns false if the entry must be dropped.
+func (m *Middleware) AfterParse(ctx context.Context, job scrapemate.IJob, entry *Entry) (bool, error) {
+	if m == nil {
+		return true, nil
+	}
+
+	for _, fn := range m.afterParse {
+		if err := fn(ctx, job, entry); err != nil {
+			if errors.Is(err, ErrSkipEntry) {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (m *Middleware) afterParseAll(ctx context.Context, job scrapemate.IJob, entries []*Entry) ([]*Entry, error) {
+	if m == nil || len(m.afterParse) == 0 {
+		return entries, nil
+	}
+
+	ans := entries[:0]
+
+	for _, entry := range entries {
+		keep, err := m.AfterParse(ctx, job, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if keep {
+			ans = append(ans, entry)
+		}
+	}
+
+	return ans, nil
+}
+
+// beforeWriteResult runs the before write functions on the entries of the
+// result, it returns false when its entry is dropped
+func (m *Middleware) beforeWriteResult(ctx context.Context, result *scrapemate.Result) (bool, error) {
+	switch data := result.Data.(type) {
+	case *Entry:
+		return m.beforeWriteOne(ctx, data)
+	case []*Entry:
+		entries := make([]*Entry, 0, len(data))
+
+		for _, entry := range data {
+			keep, err := m.beforeWriteOne(ctx, entry)
+			if err != nil {
+				return false, err
+			}
+
+			if keep {
+				entries = append(entries, entry)
+			}
+		}
+
+		result.Data = entries
+	}
+
+	return true, nil
+}
+
+func (m *Middleware) beforeWriteOne(ctx context.Context, entry *Entry) (bool, error) {
+	for _, fn := range m.beforeWrite {
+		if err := fn(ctx, entry); err != nil {
+			if errors.Is(err, ErrSkipEntry) {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// beforeWrite runs the processor as a before write function, the entry is
+// replaced with the one returned
+func (p Processor) beforeWrite(ctx context.Context, entry *Entry) error {
+	ans, err := p(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if ans == nil {
+		return ErrSkipEntry
+	}
+
+	if ans != entry {
+		*entry = *ans
+	}
+
+	return nil
+}
+
+type middlewareCtxKey struct{}
+
+// ContextWithMiddleware returns a context carrying m.
+// The jobs use the middleware found in the context passed to Process.
+func ContextWithMiddleware(ctx context.Context, m *Middleware) context.Context {
+	return context.WithValue(ctx, middlewareCtxKey{}, m)
+}
+
+// MiddlewareFromContext returns the middleware of the context or nil.
+// All methods of a nil *Middleware are no-ops.
+func MiddlewareFromContext(ctx context.Context) *Middleware {
+	m, _ := ctx.Value(middlewareCtxKey{}).(*Middleware)
+
+	return m
+}
+
+type middlewareFetcher struct {
+	m    *Middleware
+	next scrapemate.HTTPFetcher
+}
+
+func (f *middlewareFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	for _, fn := range f.m.beforeFetch {
+		if err := fn(ctx, job); err != nil {
+			return scrapemate.Response{Error: err}
+		}
+	}
+
+	return f.next.Fetch(ctx, job)
+}
+
+func (f *middlewareFetcher) Close() error {
+	return f.next.Close()
+}
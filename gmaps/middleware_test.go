@@ -0,0 +1,97 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_MiddlewareFilterResults(t *testing.T) {
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name    string
+		results []scrapemate.Result
+		want    []scrapemate.Result
+		// calls is the number of calls of the before write function
+		calls int
+		err   error
+	}{
+		{
+			name: "entries kept",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Title: "a"}},
+				{Data: []*gmaps.Entry{{Title: "b"}, {Title: "c"}}},
+			},
+			want: []scrapemate.Result{
+				{Data: &gmaps.Entry{Title: "a!"}},
+				{Data: []*gmaps.Entry{{Title: "b!"}, {Title: "c!"}}},
+			},
+			calls: 3,
+		},
+		{
+			name: "entries dropped",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Title: "skip"}},
+				{Data: []*gmaps.Entry{{Title: "skip"}, {Title: "c"}}},
+			},
+			want: []scrapemate.Result{
+				{Data: []*gmaps.Entry{{Title: "c!"}}},
+			},
+			calls: 3,
+		},
+		{
+			name: "error",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Title: "fail"}},
+				{Data: &gmaps.Entry{Title: "a"}},
+			},
+			calls: 1,
+			err:   errWrite,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+
+			m := gmaps.NewMiddleware().UseBeforeWrite(func(_ context.Context, entry *gmaps.Entry) error {
+				calls++
+
+				switch entry.Title {
+				case "skip":
+					return gmaps.ErrSkipEntry
+				case "fail":
+					return errWrite
+				}
+
+				entry.Title += "!"
+
+				return nil
+			})
+
+			in := make(chan scrapemate.Result, len(tc.results))
+			for _, result := range tc.results {
+				in <- result
+			}
+
+			close(in)
+
+			out, errc := m.FilterResults(context.Background(), in)
+
+			var got []scrapemate.Result
+			for result := range out {
+				got = append(got, result)
+			}
+
+			require.Equal(t, tc.want, got)
+			require.ErrorIs(t, <-errc, tc.err)
+			require.Equal(t, tc.calls, calls)
+		})
+	}
+}
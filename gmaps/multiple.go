@@ -1,85 +1,107 @@
 package gmaps
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	olc "github.com/google/open-location-code/go"
 )
 
-func ParseSearchResults(raw []byte) ([]*Entry, error) {
-	var data []any
-	if err := json.Unmarshal(raw, &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
-	}
+// plusCodeLength is the length of the Plus Codes encoded from the coordinates
+// of the places, a 14 by 14 meters area
+const plusCodeLength = 10
 
-	if len(data) == 0 {
-		return nil, fmt.Errorf("empty JSON data")
-	}
+// ParseWarning describes a search result that could not be parsed
+type ParseWarning struct {
+	// Index is the position of the result in the response
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
 
-	container, ok := data[0].([]any)
-	if !ok || len(container) == 0 {
-		return nil, fmt.Errorf("invalid business list structure")
-	}
+func (w ParseWarning) String() string {
+	return fmt.Sprintf("result %d: %s", w.Index, w.Reason)
+}
 
-	items := getNthElementAndCast[[]any](container, 1)
-	if len(items) < 2 {
-		return nil, fmt.Errorf("empty business list")
-	}
+// ParseSearchResults parses the response of a tbm=map search.
+// Results that cannot be parsed are skipped, see ParseSearchResultsWithWarnings.
+func ParseSearchResults(raw []byte) ([]*Entry, error) {
+	entries, _, err := ParseSearchResultsWithWarnings(raw)
 
-	entries := make([]*Entry, 0, len(items)-1)
+	return entries, err
+}
 
-	for i := 1; i < len(items); i++ {
-		arr, ok := items[i].([]any)
-		if !ok {
-			continue
-		}
+// ParseSearchResultsWithWarnings parses the response of a tbm=map search
+// with the parsers of all the versions, see NewSearchParser.
+// A malformed result does not fail the whole response: it is skipped
+// and a warning is returned for it.
+// An error is returned only when the response itself is invalid.
+func ParseSearchResultsWithWarnings(raw []byte) ([]*Entry, []ParseWarning, error) {
+	return fallbackParser{parsers: searchParsers}.Parse(raw)
+}
 
-		business := getNthElementAndCast[[]any](arr, 14)
+func parseSearchResult(arr []any) (_ *Entry, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while parsing: %v", r)
+		}
+	}()
 
-		var entry Entry
+	business := getNthElementAndCast[[]any](arr, 14)
+	if len(business) == 0 {
+		return nil, fmt.Errorf("missing business data")
+	}
 
-		entry.ID = getNthElementAndCast[string](business, 0)
-		entry.Title = getNthElementAndCast[string](business, 11)
-		entry.Categories = toStringSlice(getNthElementAndCast[[]any](business, 13))
-		entry.WebSite = getNthElementAndCast[string](business, 7, 0)
+	var entry Entry
 
-		entry.ReviewRating = getNthElementAndCast[float64](business, 4, 7)
-		entry.ReviewCount = int(getNthElementAndCast[float64](business, 4, 8))
+	entry.ID = getNthElementAndCast[string](business, 0)
+	entry.Title = getNthElementAndCast[string](business, 11)
+	entry.Categories = toStringSlice(getNthElementAndCast[[]any](business, 13))
+	entry.WebSite = getNthElementAndCast[string](business, 7, 0)
 
-		fullAddress := getNthElementAndCast[[]any](business, 2)
+	entry.ReviewRating = getNthElementAndCast[float64](business, 4, 7)
+	entry.ReviewCount = int(getNthElementAndCast[float64](business, 4, 8))
+	entry.PriceRange = getNthElementAndCast[string](business, 4, 2)
+	entry.PriceLevel = priceLevel(entry.PriceRange, "")
 
-		entry.Address = func() string {
-			sb := strings.Builder{}
+	fullAddress := getNthElementAndCast[[]any](business, 2)
 
-			for i, part := range fullAddress {
-				if i > 0 {
-					sb.WriteString(", ")
-				}
+	entry.Address = func() string {
+		sb := strings.Builder{}
 
-				sb.WriteString(fmt.Sprintf("%v", part))
+		for i, part := range fullAddress {
+			if i > 0 {
+				sb.WriteString(", ")
 			}
 
-			return sb.String()
-		}()
+			sb.WriteString(fmt.Sprintf("%v", part))
+		}
+
+		return sb.String()
+	}()
 
-		entry.Latitude = getNthElementAndCast[float64](business, 9, 2)
-		entry.Longtitude = getNthElementAndCast[float64](business, 9, 3)
-		entry.Phone = strings.ReplaceAll(getNthElementAndCast[string](business, 178, 0, 0), " ", "")
-		entry.OpenHours = getHours(business)
-		entry.Status = getNthElementAndCast[string](business, 34, 4, 4)
-		entry.Timezone = getNthElementAndCast[string](business, 30)
-		entry.DataID = getNthElementAndCast[string](business, 10)
+	entry.setCompleteAddress(business)
 
-		entry.PlusCode = olc.Encode(entry.Latitude, entry.Longtitude, 10)
+	entry.Latitude = getNthElementAndCast[float64](business, 9, 2)
+	entry.Longtitude = getNthElementAndCast[float64](business, 9, 3)
+	entry.Phone = strings.ReplaceAll(getNthElementAndCast[string](business, 178, 0, 0), " ", "")
+	entry.OpenHours = getHours(business)
+	entry.Schedule = entry.weekSchedule()
+	entry.Status = getNthElementAndCast[string](business, 34, 4, 4)
+	entry.BusinessStatus = businessStatus(entry.Status)
+	entry.Timezone = getNthElementAndCast[string](business, 30)
+	entry.DataID = getNthElementAndCast[string](business, 10)
+	entry.Kgmid = getNthElementAndCast[string](business, 89)
+	entry.About = getAbout(business)
+	entry.setParking()
+	entry.setAttributes()
+	entry.setServiceOptions()
 
-		entry.Raw = business
+	entry.PlusCode = olc.Encode(entry.Latitude, entry.Longtitude, plusCodeLength)
+	entry.GlobalPlusCode = entry.PlusCode
 
-		entries = append(entries, &entry)
-	}
+	entry.Raw = business
 
-	return entries, nil
+	return &entry, nil
 }
 
 func toStringSlice(arr []any) []string {
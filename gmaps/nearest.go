@@ -0,0 +1,115 @@
+package gmaps
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// Nearest keeps the places of every query nearest to the center of its
+// search over all the searches of the query, its tiles and its pages: the
+// searches add their places and the last one to complete returns them.
+//
+// The searches decoded from a queue of a previous run are not counted, their
+// places are cut per search.
+type Nearest struct {
+	n int
+
+	mu      sync.Mutex
+	queries map[string]*nearestQuery
+}
+
+// nearestQuery is the number of the searches of a query not completed yet
+// and the nearest places they found
+type nearestQuery struct {
+	pending int
+	entries []*Entry
+	keys    map[string]struct{}
+}
+
+// NewNearest returns a Nearest keeping the n places of every query nearest
+// to its center, nil when n is not positive
+func NewNearest(n int) *Nearest {
+	if n <= 0 {
+		return nil
+	}
+
+	return &Nearest{
+		n:       n,
+		queries: make(map[string]*nearestQuery),
+	}
+}
+
+// start records a search of the query of params, it is completed with done
+func (n *Nearest) start(params *MapSearchParams) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.query(params).pending++
+}
+
+// done adds the entries of a completed search of the query of params. Once
+// all the searches of the query are completed it returns the n entries
+// nearest to its center, by distance, the places found by several searches
+// once.
+func (n *Nearest) done(params *MapSearchParams, entries []*Entry) []*Entry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	q := n.query(params)
+
+	for _, e := range entries {
+		key := dedupKey(e)
+		if _, ok := q.keys[key]; ok {
+			continue
+		}
+
+		q.keys[key] = struct{}{}
+		q.entries = append(q.entries, e)
+	}
+
+	slices.SortStableFunc(q.entries, func(a, b *Entry) int {
+		return cmp.Compare(a.DistanceM, b.DistanceM)
+	})
+
+	if len(q.entries) > n.n {
+		q.entries = q.entries[:n.n]
+	}
+
+	q.pending--
+	if q.pending > 0 {
+		return nil
+	}
+
+	delete(n.queries, nearestKey(params))
+
+	return q.entries
+}
+
+// Skip completes the search job that is not run, e.g. the seed completed by
+// the previous run of a checkpoint
+func (n *Nearest) Skip(job scrapemate.IJob) {
+	if j, ok := job.(*SearchJob); ok && j.nearest == n {
+		n.done(j.params, nil)
+	}
+}
+
+func (n *Nearest) query(params *MapSearchParams) *nearestQuery {
+	key := nearestKey(params)
+
+	q, ok := n.queries[key]
+	if !ok {
+		q = &nearestQuery{keys: make(map[string]struct{})}
+		n.queries[key] = q
+	}
+
+	return q
+}
+
+// nearestKey returns the key of the query of params, the languages of a
+// query keep their places
+func nearestKey(params *MapSearchParams) string {
+	return params.Hl + ":" + params.term()
+}
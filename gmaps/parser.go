@@ -0,0 +1,270 @@
+package gmaps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// AutoParser is the version of the parser trying the parsers of all the
+// versions, see NewSearchParser
+const AutoParser = "auto"
+
+// scanDepth is the depth of the arrays of a response searched for the list of
+// the results by the scan parser
+const scanDepth = 6
+
+// SearchParser parses the response of a tbm=map search with the layout of
+// its version. A malformed result does not fail the whole response: it is
+// skipped and a warning is returned for it. An error is returned when the
+// response does not have the layout of the parser.
+type SearchParser interface {
+	Version() string
+	Parse(raw []byte) ([]*Entry, []ParseWarning, error)
+}
+
+// searchParsers are the parsers of the layouts of the responses, in the order
+// they are tried. A new layout of Google is a new version added first.
+var searchParsers = []SearchParser{
+	searchParserV1{},
+	searchParserScan{},
+}
+
+// SearchParserVersions returns the versions of the parsers in the order they
+// are tried
+func SearchParserVersions() []string {
+	ans := make([]string, 0, len(searchParsers))
+	for _, p := range searchParsers {
+		ans = append(ans, p.Version())
+	}
+
+	return ans
+}
+
+// NewSearchParser returns the parser of version. The parser of AutoParser, or
+// of an empty version, tries the versions in order until one of them parses
+// results from the response, the results of the first version are kept when
+// none does.
+func NewSearchParser(version string) (SearchParser, error) {
+	if version == "" || version == AutoParser {
+		return fallbackParser{parsers: searchParsers}, nil
+	}
+
+	for _, p := range searchParsers {
+		if p.Version() == version {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid parser version %q: use %s or one of %s", version, AutoParser,
+		strings.Join(SearchParserVersions(), ", "))
+}
+
+type searchParserCtxKey struct{}
+
+// ContextWithSearchParser returns a context carrying the parser of the search
+// results of the run
+func ContextWithSearchParser(ctx context.Context, p SearchParser) context.Context {
+	return context.WithValue(ctx, searchParserCtxKey{}, p)
+}
+
+// SearchParserFromContext returns the parser of the context, the parser of
+// AutoParser when it has none
+func SearchParserFromContext(ctx context.Context) SearchParser {
+	if p, ok := ctx.Value(searchParserCtxKey{}).(SearchParser); ok {
+		return p
+	}
+
+	return fallbackParser{parsers: searchParsers}
+}
+
+// fallbackParser tries its parsers in order
+type fallbackParser struct {
+	parsers []SearchParser
+}
+
+func (p fallbackParser) Version() string {
+	return AutoParser
+}
+
+func (p fallbackParser) Parse(raw []byte) ([]*Entry, []ParseWarning, error) {
+	var (
+		entries  []*Entry
+		warnings []ParseWarning
+		err      error
+	)
+
+	for i, parser := range p.parsers {
+		e, w, perr := parser.Parse(raw)
+		if perr == nil && (len(e) > 0 || len(w) == 0) {
+			if i > 0 {
+				slog.Warn("search results parsed by a fallback parser", "version", parser.Version(),
+					"places", len(e), "error", fallbackReason(err, warnings))
+			}
+
+			return e, w, nil
+		}
+
+		// the results of the first version are returned when none parses them
+		if i == 0 {
+			entries, warnings, err = e, w, perr
+		}
+	}
+
+	return entries, warnings, err
+}
+
+// fallbackReason returns why the first parser did not parse the results
+func fallbackReason(err error, warnings []ParseWarning) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("the %d results were skipped", len(warnings))
+}
+
+// searchParserV1 parses the results at data[0][1][1:], the business of a
+// result at its index 14
+type searchParserV1 struct{}
+
+func (searchParserV1) Version() string {
+	return "v1"
+}
+
+func (searchParserV1) Parse(raw []byte) ([]*Entry, []ParseWarning, error) {
+	var data []any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("empty JSON data")
+	}
+
+	container, ok := data[0].([]any)
+	if !ok || len(container) == 0 {
+		return nil, nil, fmt.Errorf("invalid business list structure")
+	}
+
+	items := getNthElementAndCast[[]any](container, 1)
+	if len(items) < 2 {
+		return nil, nil, fmt.Errorf("empty business list")
+	}
+
+	var (
+		entries  = make([]*Entry, 0, len(items)-1)
+		warnings []ParseWarning
+	)
+
+	for i := 1; i < len(items); i++ {
+		arr, ok := items[i].([]any)
+		if !ok {
+			warnings = append(warnings, ParseWarning{Index: i, Reason: fmt.Sprintf("unexpected type %T", items[i])})
+
+			continue
+		}
+
+		entry, err := parseSearchResult(arr)
+		if err != nil {
+			warnings = append(warnings, ParseWarning{Index: i, Reason: err.Error()})
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, warnings, nil
+}
+
+// searchParserScan finds the list of the results anywhere in the response:
+// the array with the most results, arrays with the business at their index 14
+// and its title at the index 11 of the business. It parses the responses whose
+// results moved when their business did not change.
+type searchParserScan struct{}
+
+func (searchParserScan) Version() string {
+	return "scan"
+}
+
+func (searchParserScan) Parse(raw []byte) ([]*Entry, []ParseWarning, error) {
+	var data []any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	var (
+		items []any
+		found int
+	)
+
+	var scan func(arr []any, depth int)
+
+	scan = func(arr []any, depth int) {
+		if n := countResults(arr); n > found {
+			items, found = arr, n
+		}
+
+		if depth == scanDepth {
+			return
+		}
+
+		for _, v := range arr {
+			if child, ok := v.([]any); ok {
+				scan(child, depth+1)
+			}
+		}
+	}
+
+	scan(data, 0)
+
+	if found == 0 {
+		return nil, nil, errors.New("no business list found")
+	}
+
+	var (
+		entries  = make([]*Entry, 0, found)
+		warnings []ParseWarning
+	)
+
+	for i, item := range items {
+		arr, ok := item.([]any)
+		if !ok || !isResult(arr) {
+			continue
+		}
+
+		entry, err := parseSearchResult(arr)
+		if err != nil {
+			warnings = append(warnings, ParseWarning{Index: i, Reason: err.Error()})
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, warnings, nil
+}
+
+// countResults returns the number of the results of arr
+func countResults(arr []any) int {
+	n := 0
+
+	for _, v := range arr {
+		if result, ok := v.([]any); ok && isResult(result) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// isResult reports whether arr has the business of a result
+func isResult(arr []any) bool {
+	business := getNthElementAndCast[[]any](arr, 14)
+
+	return len(business) > 11 && getNthElementAndCast[string](business, 11) != ""
+}
@@ -0,0 +1,63 @@
+package gmaps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Photo is a photo of the gallery of a place
+type Photo struct {
+	ID string `json:"id"`
+	// Category is the tab of the gallery, e.g. "Menu" or "By owner"
+	Category string `json:"category"`
+	// URL is the thumbnail shown in the gallery, see OriginalURL
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// OriginalURL returns the url of the photo at its original size
+func (p *Photo) OriginalURL() string {
+	if p.Width == 0 || p.Height == 0 {
+		return p.URL
+	}
+
+	i := strings.LastIndex(p.URL, "=")
+	if i < 0 {
+		return p.URL
+	}
+
+	return fmt.Sprintf("%s=w%d-h%d-k-no", p.URL[:i], p.Width, p.Height)
+}
+
+// setPhotos sets the photos of every category of the gallery. The first
+// category is usually "All", a photo is listed once in the first category it
+// appears in.
+func (e *Entry) setPhotos(darray []any) {
+	seen := make(map[string]bool)
+
+	for _, c := range getNthElementAndCast[[]any](darray, 171, 0) {
+		category, _ := c.([]any)
+		name := getNthElementAndCast[string](category, 2)
+
+		for _, p := range getNthElementAndCast[[]any](category, 3) {
+			item, _ := p.([]any)
+
+			photo := Photo{
+				ID:       getNthElementAndCast[string](item, 0),
+				Category: name,
+				URL:      getNthElementAndCast[string](item, 6, 0),
+				Width:    int(getNthElementAndCast[float64](item, 6, 2, 0)),
+				Height:   int(getNthElementAndCast[float64](item, 6, 2, 1)),
+			}
+
+			if photo.URL == "" || (photo.ID != "" && seen[photo.ID]) {
+				continue
+			}
+
+			seen[photo.ID] = true
+
+			e.Photos = append(e.Photos, photo)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package gmaps
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"strings"
 	"time"
@@ -10,19 +13,45 @@ import (
 	"github.com/google/uuid"
 	"github.com/gosom/scrapemate"
 	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/tracing"
 )
 
 type PlaceJobOptions func(*PlaceJob)
 
 type PlaceJob struct {
 	scrapemate.Job
+	tracing.Carrier
 
 	UsageInResultststs  bool
 	ExtractEmail        bool
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+	ExtractPosts        bool
+	ExtractProducts     bool
+	ExtractMenu         bool
+	ExtractQuestions    bool
+	ExtractHotels       bool
+	DeterministicID     bool
+	EmailPages          int
+	ReviewLimits        ReviewLimits
+	Metadata            map[string]string
+	// Query is the seed query that found the place
+	Query string
+	// Center is the location of the search that found the place, the
+	// distance and the bearing of the entry are measured from it
+	Center *MapLocation
+	// Interstitials is the number of the previous tries of the place served
+	// the consent or the captcha page
+	Interstitials int
+
+	fetcher      scrapemate.HTTPFetcher
+	images       *ImageDownloader
+	emailFetcher *EmailFetcher
+	failure      jobFailure
 }
 
 func NewPlaceJob(parentID, langCode, u string, extractEmail, extraExtraReviews bool, opts ...PlaceJobOptions) *PlaceJob {
@@ -51,6 +80,12 @@ func NewPlaceJob(parentID, langCode, u string, extractEmail, extraExtraReviews b
 		opt(&job)
 	}
 
+	job.Headers = localeHeaders(job.Headers, langCode, job.URLParams["gl"])
+
+	if job.DeterministicID {
+		job.ID = JobID("place", parentID, langCode, u)
+	}
+
 	return &job
 }
 
@@ -60,32 +95,212 @@ func WithPlaceJobExitMonitor(exitMonitor exiter.Exiter) PlaceJobOptions {
 	}
 }
 
-func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+// WithPlaceJobEmailPages sets the number of pages of the website visited to find emails
+func WithPlaceJobEmailPages(n int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.EmailPages = n
+	}
+}
+
+// WithPlaceJobReviewLimits limits the extra reviews fetched
+func WithPlaceJobReviewLimits(l ReviewLimits) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ReviewLimits = l
+	}
+}
+
+// WithPlaceJobMetadata sets the metadata of the input query on the entry
+func WithPlaceJobMetadata(m map[string]string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Metadata = m
+	}
+}
+
+// WithPlaceJobHeaders sends the headers with the requests of the place
+func WithPlaceJobHeaders(headers map[string]string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		if j.Headers == nil {
+			j.Headers = make(map[string]string, len(headers))
+		}
+
+		maps.Copy(j.Headers, headers)
+	}
+}
+
+// WithPlaceJobQuery sets the seed query that found the place
+func WithPlaceJobQuery(query string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Query = query
+	}
+}
+
+// WithPlaceJobCenter sets the location of the search that found the place
+func WithPlaceJobCenter(lat, lon float64) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Center = &MapLocation{Lat: lat, Lon: lon}
+	}
+}
+
+// WithPlaceJobPosts collects the posts of the Updates tab
+func WithPlaceJobPosts() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExtractPosts = true
+	}
+}
+
+// WithPlaceJobProducts collects the products of the Products tab
+func WithPlaceJobProducts() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExtractProducts = true
+	}
+}
+
+// WithPlaceJobMenu collects the items of the Menu tab
+func WithPlaceJobMenu() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExtractMenu = true
+	}
+}
+
+// WithPlaceJobQuestions collects the questions and answers with a QaJob
+func WithPlaceJobQuestions() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExtractQuestions = true
+	}
+}
+
+// WithPlaceJobDeterministicID derives the id of the job from its seed, its
+// language and the URL of the place instead of a random id
+func WithPlaceJobDeterministicID() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.DeterministicID = true
+	}
+}
+
+// WithPlaceJobHotels collects the rates and the amenities of the hotel page of
+// the places to stay
+func WithPlaceJobHotels() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExtractHotels = true
+	}
+}
+
+// WithPlaceJobEmailFetcher fetches the website of the place with f to find emails
+func WithPlaceJobEmailFetcher(f *EmailFetcher) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.emailFetcher = f
+	}
+}
+
+// WithPlaceJobImages downloads the photos of the place with d
+func WithPlaceJobImages(d *ImageDownloader) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.images = d
+	}
+}
+
+// WithPlaceJobFetcher sets a fetcher that is used for this job
+// instead of the one configured in the app.
+// The fetcher may return the raw html of the place page, the place data
+// are then extracted from it.
+func WithPlaceJobFetcher(f scrapemate.HTTPFetcher) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.fetcher = f
+	}
+}
+
+// Fetcher returns the fetcher set with WithPlaceJobFetcher
+func (j *PlaceJob) Fetcher() scrapemate.HTTPFetcher {
+	return j.fetcher
+}
+
+func (j *PlaceJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	ctx, span := tracing.StartJob(ctx, j, "gmaps.place.process")
+
+	data, next, err := j.process(ctx, resp)
+
+	tracing.Propagate(ctx, next)
+	tracing.End(span, err)
+
+	j.failure.processed(err)
+
+	return data, next, err
+}
+
+func (j *PlaceJob) process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 		resp.Meta = nil
 	}()
 
-	raw, ok := resp.Meta["json"].([]byte)
-	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to []byte")
+	if page := fetcher.Interstitial(resp); page != "" {
+		next, err := j.again(ctx, page)
+
+		return nil, next, err
 	}
 
-	entry, err := EntryFromJSON(raw)
+	_, parse := tracing.Tracer().Start(ctx, "gmaps.place.parse")
+
+	entry, err := entryFromPlaceResponse(resp)
+
+	parse.SetAttributes(attribute.String("gmaps.place", entry.Title))
+	tracing.End(parse, err)
+
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("%w: place: %w", ErrParse, err)
 	}
 
 	entry.ID = j.ParentID
+	entry.setLanguage(j.URLParams["hl"])
+	entry.Metadata = j.Metadata
+
+	if !j.ReviewLimits.IsZero() {
+		entry.UserReviewsExtended, _ = j.ReviewLimits.apply(0, entry.UserReviewsExtended)
+	}
 
 	if entry.Link == "" {
 		entry.Link = j.GetFullURL()
 	}
 
-	allReviewsRaw, ok := resp.Meta["reviews_raw"].(fetchReviewsResponse)
-	if ok && len(allReviewsRaw.pages) > 0 {
-		entry.AddExtraReviews(allReviewsRaw.pages)
+	if j.Center != nil && (entry.Latitude != 0 || entry.Longtitude != 0) {
+		entry.setDistance(j.Center.Lat, j.Center.Lon)
+	}
+
+	keep, err := MiddlewareFromContext(ctx).AfterParse(ctx, j, &entry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !keep {
+		j.UsageInResultststs = false
+
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrPlacesCompleted(1)
+		}
+
+		return nil, nil, nil
+	}
+
+	var next []scrapemate.IJob
+
+	if j.images != nil {
+		next = j.images.Jobs(j.ID, &entry, j.ExitMonitor)
+	}
+
+	if j.ExtractQuestions {
+		opts := []QaJobOptions{}
+		if j.ExitMonitor != nil {
+			opts = append(opts, WithQaJobExitMonitor(j.ExitMonitor))
+		}
+
+		if j.ExtractEmail {
+			opts = append(opts, WithQaJobEmail(j.EmailPages, j.emailFetcher))
+		}
+
+		j.UsageInResultststs = false
+
+		return nil, append(next, NewQaJob(j.ID, &entry, opts...)), nil
 	}
 
 	if j.ExtractEmail && entry.IsWebsiteValidForEmail() {
@@ -94,21 +309,59 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 			opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
 		}
 
+		if j.EmailPages > 0 {
+			opts = append(opts, WithEmailJobPages(j.EmailPages))
+		}
+
+		if j.emailFetcher != nil {
+			opts = append(opts, WithEmailJobFetcher(j.emailFetcher))
+		}
+
 		emailJob := NewEmailJob(j.ID, &entry, opts...)
 
 		j.UsageInResultststs = false
 
-		return nil, []scrapemate.IJob{emailJob}, nil
+		return nil, append(next, emailJob), nil
 	} else if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrPlacesCompleted(1)
 	}
 
-	return &entry, nil, err
+	return &entry, next, err
+}
+
+// DoCheckResponse accepts the consent and the captcha pages, the job is
+// pushed again
+func (j *PlaceJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	return checkInterstitial(resp, j.Job.DoCheckResponse, &j.failure)
+}
+
+// again pushes the place again after the interstitial page, its parent is the
+// search of the place
+func (j *PlaceJob) again(ctx context.Context, page string) ([]scrapemate.IJob, error) {
+	if err := interstitialError(ctx, page, j.Interstitials); err != nil {
+		return nil, err
+	}
+
+	next := *j
+	next.ID = uuid.New().String()
+	next.Interstitials++
+
+	j.UsageInResultststs = false
+
+	return []scrapemate.IJob{&next}, nil
 }
 
 func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
+	setConsentCookie(page, "")
+
+	if err := setPageHeaders(page, j.GetHeaders()); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
 	pageResponse, err := page.Goto(j.GetURL(), playwright.PageGotoOptions{
 		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
 	})
@@ -132,6 +385,10 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scr
 		return resp
 	}
 
+	if interstitialPage(page, pageResponse, &resp) {
+		return resp
+	}
+
 	resp.URL = pageResponse.URL()
 	resp.StatusCode = pageResponse.Status()
 	resp.Headers = make(http.Header, len(pageResponse.Headers()))
@@ -153,6 +410,65 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scr
 
 	resp.Meta["json"] = raw
 
+	// only gas stations have the block, the others get no prices
+	fuelPrices, err := fetchFuelPrices(page, j.URLParams["hl"])
+	if err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the fuel prices", "url", j.GetURL(), "error", err)
+	}
+
+	resp.Meta["fuel_prices"] = fuelPrices
+
+	evTexts, err := fetchEVChargingTexts(page)
+	if err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the ev charging details", "url", j.GetURL(), "error", err)
+	}
+
+	resp.Meta["ev_charging"] = evTexts
+
+	transitTexts, err := fetchTransitTexts(page)
+	if err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the nearby transit", "url", j.GetURL(), "error", err)
+	}
+
+	resp.Meta["transit"] = transitTexts
+
+	if j.ExtractHotels {
+		rates, amenities, err := fetchHotelTexts(page)
+		if err != nil {
+			scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the hotel details", "url", j.GetURL(), "error", err)
+		}
+
+		resp.Meta["hotel_rates"] = rates
+		resp.Meta["hotel_amenities"] = amenities
+	}
+
+	if j.ExtractPosts {
+		posts, err := fetchPosts(page)
+		if err != nil {
+			scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the posts", "url", j.GetURL(), "error", err)
+		}
+
+		resp.Meta["posts"] = posts
+	}
+
+	if j.ExtractProducts {
+		products, err := fetchProducts(page)
+		if err != nil {
+			scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the products", "url", j.GetURL(), "error", err)
+		}
+
+		resp.Meta["products"] = products
+	}
+
+	if j.ExtractMenu {
+		sections, err := fetchMenu(page)
+		if err != nil {
+			scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the menu", "url", j.GetURL(), "error", err)
+		}
+
+		resp.Meta["menu"] = sections
+	}
+
 	if j.ExtractExtraReviews {
 		reviewCount := j.getReviewCount(raw)
 		if reviewCount > 8 { // we have more reviews
@@ -160,6 +476,8 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scr
 				page:        page,
 				mapURL:      page.URL(),
 				reviewCount: reviewCount,
+				limits:      j.ReviewLimits,
+				proxies:     ProxiesFromContext(ctx),
 			}
 
 			reviewFetcher := newReviewFetcher(params)
@@ -213,6 +531,91 @@ func (j *PlaceJob) extractJSON(page playwright.Page) ([]byte, error) {
 	return []byte(raw), nil
 }
 
+// entryFromPlaceResponse parses the place page and the data the browser
+// actions collected in the meta of the response
+func entryFromPlaceResponse(resp *scrapemate.Response) (Entry, error) {
+	raw, ok := resp.Meta["json"].([]byte)
+	if !ok {
+		var err error
+
+		raw, err = extractJSONFromHTML(resp.Body)
+		if err != nil {
+			return Entry{}, fmt.Errorf("could not convert to []byte: %w", err)
+		}
+	}
+
+	entry, err := EntryFromJSON(raw)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	allReviewsRaw, ok := resp.Meta["reviews_raw"].(fetchReviewsResponse)
+	if ok && len(allReviewsRaw.pages) > 0 {
+		entry.AddExtraReviews(allReviewsRaw.pages)
+	}
+
+	if posts, ok := resp.Meta["posts"].([]Post); ok {
+		entry.Posts = posts
+	}
+
+	if products, ok := resp.Meta["products"].([]Product); ok {
+		entry.Products = products
+	}
+
+	if sections, ok := resp.Meta["menu"].([]MenuSection); ok {
+		entry.Menu.Sections = sections
+	}
+
+	if fuelPrices, ok := resp.Meta["fuel_prices"].([]FuelPrice); ok {
+		entry.FuelPrices = fuelPrices
+	}
+
+	if evTexts, ok := resp.Meta["ev_charging"].([]string); ok {
+		entry.EVCharging = parseEVCharging(evTexts, entry.Title, responseLanguage(resp))
+	}
+
+	if transitTexts, ok := resp.Meta["transit"].([]string); ok {
+		entry.NearbyTransit = parseTransit(transitTexts)
+	}
+
+	if rates, ok := resp.Meta["hotel_rates"].([]string); ok {
+		amenities, _ := resp.Meta["hotel_amenities"].([]string)
+		entry.setHotelDetails(rates, amenities)
+	}
+
+	entry.ResolveTimezone(time.Now())
+
+	return entry, nil
+}
+
+// extractJSONFromHTML extracts the same data as the js snippet
+// from the raw html of a place page.
+func extractJSONFromHTML(body []byte) ([]byte, error) {
+	const marker = "window.APP_INITIALIZATION_STATE="
+
+	idx := bytes.Index(body, []byte(marker))
+	if idx == -1 {
+		return nil, fmt.Errorf("APP_INITIALIZATION_STATE data not found")
+	}
+
+	var state []any
+
+	// the decoder stops after the first value so the rest of the script is ignored
+	dec := json.NewDecoder(bytes.NewReader(body[idx+len(marker):]))
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode APP_INITIALIZATION_STATE: %w", err)
+	}
+
+	raw := getNthElementAndCast[string](state, 3, 0, 6)
+	if raw == "" {
+		return nil, fmt.Errorf("APP_INITIALIZATION_STATE data not found")
+	}
+
+	const prefix = `)]}'`
+
+	return []byte(strings.TrimSpace(strings.TrimPrefix(raw, prefix))), nil
+}
+
 func (j *PlaceJob) getReviewCount(data []byte) int {
 	tmpEntry, err := EntryFromJSON(data, true)
 	if err != nil {
@@ -226,6 +629,11 @@ func (j *PlaceJob) UseInResults() bool {
 	return j.UsageInResultststs
 }
 
+// UseBrowser renders the place page with the browser
+func (j *PlaceJob) UseBrowser() bool {
+	return true
+}
+
 const js = `
 (function() {
 	if (!window.APP_INITIALIZATION_STATE || !window.APP_INITIALIZATION_STATE[3]) {
@@ -0,0 +1,71 @@
+package gmaps
+
+import (
+	"github.com/gosom/scrapemate"
+)
+
+// The kinds of the planned jobs
+const (
+	// PlanSearch is a fast mode search of a tile, over HTTP
+	PlanSearch = "search"
+	// PlanBrowserSearch is a search scrolled in a browser
+	PlanBrowserSearch = "browser_search"
+	// PlanLookup is the page of a place looked up by its url, cid or data id
+	PlanLookup = "lookup"
+)
+
+// JobPlan describes a seed job before it runs
+type JobPlan struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Query string `json:"query"`
+	Lang  string `json:"lang"`
+	// URL is the URL of the first request of the job with its params
+	URL  string  `json:"url"`
+	Lat  float64 `json:"lat,omitempty"`
+	Lon  float64 `json:"lon,omitempty"`
+	Zoom float64 `json:"zoom,omitempty"`
+	// Pages is the maximum number of the pages of results of a fast mode
+	// search, the next pages are requested while the pages are full
+	Pages int `json:"pages,omitempty"`
+	// Depth is the maximum number of the scrolls of a browser search
+	Depth int `json:"depth,omitempty"`
+	// Requests is the maximum number of the requests of the job itself, the
+	// jobs of the places it finds are not counted
+	Requests int `json:"requests"`
+}
+
+// PlanJob returns the plan of a seed job, without running it
+func PlanJob(job scrapemate.IJob) JobPlan {
+	ans := JobPlan{
+		ID:       job.GetID(),
+		Query:    JobQuery(job),
+		URL:      job.GetFullURL(),
+		Requests: 1,
+	}
+
+	switch j := job.(type) {
+	case *SearchJob:
+		ans.Kind = PlanSearch
+		ans.Lang = j.params.Hl
+		ans.Pages = max(j.pages, 1) - j.params.Offset/searchPageSize
+		ans.Requests = ans.Pages
+
+		if !j.params.Locationless {
+			ans.Lat, ans.Lon, ans.Zoom = j.params.Location.Lat, j.params.Location.Lon, j.params.Location.ZoomLvl
+		}
+	case *GmapJob:
+		ans.Kind = PlanBrowserSearch
+		ans.Lang = j.LangCode
+		ans.Depth = j.MaxDepth
+
+		if lat, lon, ok := j.center(); ok {
+			ans.Lat, ans.Lon = lat, lon
+		}
+	case *PlaceLookupJob:
+		ans.Kind = PlanLookup
+		ans.Lang = j.URLParams["hl"]
+	}
+
+	return ans
+}
@@ -0,0 +1,138 @@
+package gmaps
+
+import (
+	"bytes"
+	"net/url"
+	"slices"
+)
+
+// signature identifies a third party service embedded in a website
+type signature struct {
+	name    string
+	needles []string
+}
+
+// bookingPlatforms are the booking and ordering services embedded in websites
+var bookingPlatforms = []signature{
+	{name: "Calendly", needles: []string{"calendly.com"}},
+	{name: "Acuity Scheduling", needles: []string{"acuityscheduling.com"}},
+	{name: "Setmore", needles: []string{"setmore.com"}},
+	{name: "SimplyBook.me", needles: []string{"simplybook.me", "simplybook.it"}},
+	{name: "Booksy", needles: []string{"booksy.com"}},
+	{name: "Fresha", needles: []string{"fresha.com"}},
+	{name: "Treatwell", needles: []string{"treatwell."}},
+	{name: "OpenTable", needles: []string{"opentable.com", "otstatic.com"}},
+	{name: "Resy", needles: []string{"resy.com"}},
+	{name: "SevenRooms", needles: []string{"sevenrooms.com"}},
+	{name: "Tock", needles: []string{"exploretock.com"}},
+	{name: "TheFork", needles: []string{"thefork.", "lafourchette.com"}},
+	{name: "Quandoo", needles: []string{"quandoo."}},
+	{name: "Glovo", needles: []string{"glovoapp.com"}},
+	{name: "Uber Eats", needles: []string{"ubereats.com"}},
+	{name: "Deliveroo", needles: []string{"deliveroo."}},
+	{name: "DoorDash", needles: []string{"doordash.com"}},
+	{name: "Just Eat", needles: []string{"just-eat.", "justeat.", "lieferando.", "thuisbezorgd.nl"}},
+	{name: "Wolt", needles: []string{"wolt.com"}},
+	{name: "Bolt Food", needles: []string{"food.bolt.eu"}},
+	{name: "Grubhub", needles: []string{"grubhub.com"}},
+	{name: "Postmates", needles: []string{"postmates.com"}},
+	{name: "foodpanda", needles: []string{"foodpanda."}},
+	{name: "Talabat", needles: []string{"talabat.com"}},
+	{name: "ChowNow", needles: []string{"chownow.com"}},
+	{name: "Toast", needles: []string{"toasttab.com"}},
+	{name: "Slice", needles: []string{"slicelife.com"}},
+	{name: "Yelp", needles: []string{"yelp.com/reservations", "yelp.com/waitlist"}},
+}
+
+// ecommercePlatforms are the storefront platforms, the most specific first
+var ecommercePlatforms = []signature{
+	{name: "Shopify", needles: []string{"cdn.shopify.com", "myshopify.com", "shopify.theme"}},
+	{name: "BigCommerce", needles: []string{"bigcommerce.com"}},
+	{name: "Magento", needles: []string{"mage/cookies", "magento_", "x-magento-init"}},
+	{name: "Shopware", needles: []string{"shopware"}},
+	{name: "PrestaShop", needles: []string{"prestashop"}},
+	{name: "OpenCart", needles: []string{"index.php?route=product", "opencart"}},
+	{name: "Ecwid", needles: []string{"app.ecwid.com"}},
+	{name: "Wix Stores", needles: []string{"wixstores"}},
+	{name: "Squarespace Commerce", needles: []string{"squarespace-commerce", "static.squarespace.com/universal/scripts-compressed/commerce"}},
+	{name: "WooCommerce", needles: []string{"woocommerce", "wc-ajax"}},
+}
+
+// paymentProviders are the payment providers visible in websites
+var paymentProviders = []signature{
+	{name: "Stripe", needles: []string{"js.stripe.com"}},
+	{name: "PayPal", needles: []string{"paypal.com/sdk", "paypalobjects.com", "paypal-button"}},
+	{name: "Klarna", needles: []string{"klarna"}},
+	{name: "Adyen", needles: []string{"adyen.com"}},
+	{name: "Square", needles: []string{"squareup.com", "squarecdn.com"}},
+	{name: "Braintree", needles: []string{"braintreegateway.com", "braintree-api.com"}},
+	{name: "Mollie", needles: []string{"mollie.com"}},
+	{name: "Worldpay", needles: []string{"worldpay"}},
+	{name: "Afterpay", needles: []string{"afterpay", "clearpay"}},
+	{name: "Amazon Pay", needles: []string{"payments.amazon", "amazonpay"}},
+	{name: "Apple Pay", needles: []string{"apple-pay", "applepay"}},
+	{name: "Google Pay", needles: []string{"pay.google.com", "googlepay"}},
+}
+
+// detectSignatures returns the names of the signatures found in body
+func detectSignatures(body []byte, sigs []signature) []string {
+	body = bytes.ToLower(body)
+
+	var found []string
+
+	for _, sig := range sigs {
+		for _, needle := range sig.needles {
+			if bytes.Contains(body, []byte(needle)) {
+				found = append(found, sig.name)
+
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+// linkProvider returns the name of the platform of a reservation or ordering
+// link: the known platform of its URL, else the name shown by Google, else its
+// source
+func linkProvider(link, name, source string) string {
+	target := link
+	if u, err := url.Parse(link); err == nil {
+		target = u.Host + u.Path
+	}
+
+	if found := detectSignatures([]byte(target), bookingPlatforms); len(found) > 0 {
+		return found[0]
+	}
+
+	if name != "" {
+		return name
+	}
+
+	return source
+}
+
+// linkProviders returns the providers of links, once each
+func linkProviders(links []LinkSource) []string {
+	var ans []string
+
+	for i := range links {
+		if links[i].Provider != "" {
+			ans = appendUnique(ans, links[i].Provider)
+		}
+	}
+
+	return ans
+}
+
+// appendUnique appends the values not already in dst
+func appendUnique(dst []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(dst, v) {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,253 @@
+package gmaps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Polygon is the area of a search delimited by one or more polygons, e.g. the
+// boundary of a city. The points are [longitude, latitude] like in GeoJSON,
+// the first ring of a polygon is its exterior and the others are its holes.
+type Polygon struct {
+	polygons [][][][2]float64
+}
+
+// LoadPolygon reads the polygons of a GeoJSON file
+func LoadPolygon(path string) (*Polygon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePolygon(data)
+}
+
+// ParsePolygon parses a GeoJSON Polygon or MultiPolygon, a Feature with one of
+// them as geometry or a FeatureCollection of such features
+func ParsePolygon(data []byte) (*Polygon, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var p Polygon
+
+	if err := p.add(&obj); err != nil {
+		return nil, err
+	}
+
+	if len(p.polygons) == 0 {
+		return nil, errors.New("the GeoJSON has no polygon")
+	}
+
+	return &p, nil
+}
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+func (p *Polygon) add(obj *geoJSONObject) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := p.add(&obj.Features[i]); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if obj.Geometry != nil {
+			return p.add(obj.Geometry)
+		}
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return fmt.Errorf("invalid Polygon: %w", err)
+		}
+
+		p.polygons = append(p.polygons, rings)
+	case "MultiPolygon":
+		var polygons [][][][2]float64
+		if err := json.Unmarshal(obj.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("invalid MultiPolygon: %w", err)
+		}
+
+		p.polygons = append(p.polygons, polygons...)
+	default:
+		return fmt.Errorf("unsupported GeoJSON type %q: use a Polygon or a MultiPolygon", obj.Type)
+	}
+
+	return nil
+}
+
+// Contains reports whether the point is inside one of the polygons and not in its holes
+func (p *Polygon) Contains(lat, lon float64) bool {
+	for _, rings := range p.polygons {
+		if len(rings) == 0 || !ringContains(rings[0], lon, lat) {
+			continue
+		}
+
+		inHole := false
+
+		for _, hole := range rings[1:] {
+			if ringContains(hole, lon, lat) {
+				inHole = true
+
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringContains is the even-odd rule: a ray from the point crosses the ring an odd number of times
+func ringContains(ring [][2]float64, x, y float64) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// bounds returns the bounding box of the polygons
+func (p *Polygon) bounds() (minLon, minLat, maxLon, maxLat float64) {
+	minLon, minLat = math.Inf(1), math.Inf(1)
+	maxLon, maxLat = math.Inf(-1), math.Inf(-1)
+
+	for _, rings := range p.polygons {
+		for _, ring := range rings {
+			for _, pt := range ring {
+				minLon, maxLon = math.Min(minLon, pt[0]), math.Max(maxLon, pt[0])
+				minLat, maxLat = math.Min(minLat, pt[1]), math.Max(maxLat, pt[1])
+			}
+		}
+	}
+
+	return minLon, minLat, maxLon, maxLat
+}
+
+// Circle returns the circle around the bounding box of the polygons, at zoom
+func (p *Polygon) Circle(zoom int) MapLocation {
+	minLon, minLat, maxLon, maxLat := p.bounds()
+
+	center := MapLocation{Lat: (minLat + maxLat) / 2, Lon: (minLon + maxLon) / 2, ZoomLvl: float64(zoom)}
+
+	corner := Entry{Latitude: maxLat, Longtitude: maxLon}
+	center.Radius = corner.haversineDistance(center.Lat, center.Lon)
+
+	return center
+}
+
+// Tiles returns the search locations at zoom covering the polygons: the squares
+// of a grid of viewports over their bounding box that overlap one of them.
+func (p *Polygon) Tiles(zoom int, viewport Viewport) []MapLocation {
+	minLon, minLat, maxLon, maxLat := p.bounds()
+	center := p.Circle(zoom)
+
+	// the viewports are narrower away from the equator, the grid is sized for
+	// the latitude closest to the pole and is the widest at the one closest to the equator
+	half := ViewportRadius(min(math.Max(math.Abs(minLat), math.Abs(maxLat)), 85), zoom, viewport)
+
+	equatorLat := 0.0
+	if minLat > 0 || maxLat < 0 {
+		equatorLat = math.Min(math.Abs(minLat), math.Abs(maxLat))
+	}
+
+	extentY := (maxLat - minLat) / 2 * math.Pi / 180 * earthRadius
+	extentX := (maxLon - minLon) / 2 * math.Pi / 180 * earthRadius * math.Cos(equatorLat*math.Pi/180)
+
+	nx := int(math.Ceil(math.Max(extentX-half, 0) / (2 * half)))
+	ny := int(math.Ceil(math.Max(extentY-half, 0) / (2 * half)))
+
+	return grid(center, half, nx, ny, func(_, _ float64, tile MapLocation) bool {
+		dLat := half / earthRadius * 180 / math.Pi
+		dLon := dLat / math.Cos(tile.Lat*math.Pi/180)
+
+		return p.intersects(tile.Lon-dLon, tile.Lat-dLat, tile.Lon+dLon, tile.Lat+dLat)
+	})
+}
+
+// intersects reports whether the rectangle overlaps one of the polygons
+func (p *Polygon) intersects(minLon, minLat, maxLon, maxLat float64) bool {
+	corners := [][2]float64{{minLon, minLat}, {maxLon, minLat}, {maxLon, maxLat}, {minLon, maxLat}}
+
+	for _, c := range corners {
+		if p.Contains(c[1], c[0]) {
+			return true
+		}
+	}
+
+	for _, rings := range p.polygons {
+		for _, ring := range rings {
+			for i, pt := range ring {
+				if pt[0] >= minLon && pt[0] <= maxLon && pt[1] >= minLat && pt[1] <= maxLat {
+					return true
+				}
+
+				next := ring[(i+1)%len(ring)]
+
+				for k := range corners {
+					if segmentsCross(pt, next, corners[k], corners[(k+1)%len(corners)]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// segmentsCross reports whether the segments ab and cd cross
+func segmentsCross(a, b, c, d [2]float64) bool {
+	orient := func(p, q, r [2]float64) float64 {
+		return (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	}
+
+	d1, d2 := orient(c, d, a), orient(c, d, b)
+	d3, d4 := orient(a, b, c), orient(a, b, d)
+
+	return ((d1 > 0) != (d2 > 0)) && ((d3 > 0) != (d4 > 0))
+}
+
+// filter keeps the entries inside the polygons, sorted by their distance to
+// the center of the bounding box
+func (p *Polygon) filter(entries []*Entry) []*Entry {
+	inside := entries[:0]
+
+	for _, e := range entries {
+		if p.Contains(e.Latitude, e.Longtitude) {
+			inside = append(inside, e)
+		}
+	}
+
+	center := p.Circle(0)
+
+	return filterAndSortEntriesWithinRadius(inside, center.Lat, center.Lon, math.Inf(1))
+}
+
+// WithSearchJobPolygon keeps the places inside the polygon instead of the
+// circle of the tile searched
+func WithSearchJobPolygon(p *Polygon) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.polygon = p
+	}
+}
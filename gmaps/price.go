@@ -0,0 +1,104 @@
+package gmaps
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPriceLevel is the most expensive price level, $$$$
+const maxPriceLevel = 4
+
+// priceBands are the upper bounds of the amounts per person of the price
+// levels, as in the bands of Google: $1–10 and $10–20 are inexpensive, $20–30
+// and $30–50 moderate, $50–100 expensive and $100+ very expensive
+var priceBands = []float64{20, 50, 100}
+
+var priceAmountRegex = regexp.MustCompile(`\d+(?:[.,\x{a0}\x{202f}]\d+)*`)
+
+// priceLevel returns the price level of the price range shown by Google, from
+// 1 (inexpensive) to 4 (very expensive): the number of currency symbols like
+// "$$" or "€€€", or the band of an amount per person like "$10–20" or
+// "€100+", parsed in the language hl. It is 0 when the range cannot be
+// parsed.
+func priceLevel(priceRange, hl string) int {
+	s := strings.TrimSpace(priceRange)
+	if s == "" {
+		return 0
+	}
+
+	amounts := priceAmountRegex.FindAllString(s, -1)
+	if len(amounts) == 0 {
+		r, _ := utf8.DecodeRuneInString(s)
+		if !unicode.Is(unicode.Sc, r) || strings.Trim(s, string(r)) != "" {
+			return 0
+		}
+
+		return min(utf8.RuneCountInString(s), maxPriceLevel)
+	}
+
+	upper, err := parseNumber(amounts[len(amounts)-1], hl)
+	if err != nil {
+		return 0
+	}
+
+	if strings.HasSuffix(s, "+") {
+		return maxPriceLevel
+	}
+
+	for i, band := range priceBands {
+		if upper <= band {
+			return i + 1
+		}
+	}
+
+	return maxPriceLevel
+}
+
+// PriceLevels is a range of price levels, from 1 (inexpensive) to 4 (very
+// expensive)
+type PriceLevels struct {
+	Min int
+	Max int
+}
+
+// Contains reports whether level is in the range
+func (p PriceLevels) Contains(level int) bool {
+	return level >= p.Min && level <= p.Max
+}
+
+// ParsePriceLevels parses a price level or a range of price levels as numbers
+// or currency symbols, e.g. "2", "2-3", "$$" or "$$-$$$"
+func ParsePriceLevels(s string) (PriceLevels, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		to = from
+	}
+
+	minLevel, okMin := parsePriceLevel(from)
+	maxLevel, okMax := parsePriceLevel(to)
+
+	if !okMin || !okMax || minLevel > maxLevel {
+		return PriceLevels{}, fmt.Errorf("invalid price levels %q: use a level from 1 to %d or $ to $$$$, or a range like 2-3", s, maxPriceLevel)
+	}
+
+	return PriceLevels{Min: minLevel, Max: maxLevel}, nil
+}
+
+func parsePriceLevel(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+
+	level, err := strconv.Atoi(s)
+	if err != nil {
+		if s == "" || strings.Trim(s, "$") != "" {
+			return 0, false
+		}
+
+		level = len(s)
+	}
+
+	return level, level >= 1 && level <= maxPriceLevel
+}
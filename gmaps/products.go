@@ -0,0 +1,68 @@
+package gmaps
+
+import (
+	"encoding/gob"
+	"fmt"
+	"regexp"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Product is an item of the Products tab of a listing
+type Product struct {
+	Name string `json:"name"`
+	// Price is the price as shown, with the currency
+	Price    string `json:"price"`
+	Category string `json:"category"`
+	ImageURL string `json:"image_url"`
+}
+
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register([]Product{})
+}
+
+// the labels of the Products tab in the most common languages
+var productsTabRegex = regexp.MustCompile(`(?i)^\s*(products|produkte|produits|productos|prodotti|produtos|προϊόντα|produkty)\s*$`)
+
+// fetchProducts opens the Products tab of the place page and returns the products listed
+// with the category they are listed under.
+func fetchProducts(page playwright.Page) ([]Product, error) {
+	var products []Product
+
+	if err := scrapeTab(page, productsTabRegex, productsSelector, productsJS, &products); err != nil {
+		return nil, fmt.Errorf("could not scrape the products tab: %w", err)
+	}
+
+	return products, nil
+}
+
+const productsSelector = `div[role='main'] div[role='tabpanel'] [role='button'][aria-label], div[role='main'] div[role='tabpanel'] h2`
+
+// the products follow the heading of their category in the document order
+const productsJS = `
+(selector) => {
+	const products = [];
+	const priceRe = /(?:[$€£¥₹]\s?\d[\d.,]*|\d[\d.,]*\s?(?:[$€£¥₹]|[A-Z]{3}\b))/;
+	let category = '';
+	document.querySelectorAll(selector).forEach((el) => {
+		if (el.tagName === 'H2') {
+			category = el.textContent.trim();
+			return;
+		}
+		const texts = Array.from(el.querySelectorAll('span, div'))
+			.filter((n) => n.children.length === 0)
+			.map((n) => n.textContent.trim())
+			.filter((t) => t.length > 0);
+		const price = texts.find((t) => priceRe.test(t)) || '';
+		const img = el.querySelector('img[src^="https://"]');
+		products.push({
+			name: el.getAttribute('aria-label').trim(),
+			price: price,
+			category: category,
+			image_url: img ? img.src : '',
+		});
+	});
+	return JSON.stringify(products);
+}
+`
@@ -0,0 +1,23 @@
+package gmaps
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+)
+
+type proxiesCtxKey struct{}
+
+// ContextWithProxies returns a context carrying the proxies of the run.
+// The jobs send the requests made besides their fetcher (e.g. the extra
+// reviews of a place) through the proxies found in the context.
+func ContextWithProxies(ctx context.Context, r scrapemate.ProxyRotator) context.Context {
+	return context.WithValue(ctx, proxiesCtxKey{}, r)
+}
+
+// ProxiesFromContext returns the proxies of the context or nil
+func ProxiesFromContext(ctx context.Context) scrapemate.ProxyRotator {
+	r, _ := ctx.Value(proxiesCtxKey{}).(scrapemate.ProxyRotator)
+
+	return r
+}
@@ -0,0 +1,283 @@
+package gmaps
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/tracing"
+)
+
+// Question is a question of the Questions & answers section of a place
+type Question struct {
+	Text   string `json:"text"`
+	Author string `json:"author"`
+	// Date is the date as shown, e.g. "2 years ago"
+	Date string `json:"date"`
+	// Votes is the number of people who found the question helpful
+	Votes   int      `json:"votes"`
+	Answers []Answer `json:"answers"`
+}
+
+// Answer is an answer to a question of a place
+type Answer struct {
+	Text   string `json:"text"`
+	Author string `json:"author"`
+	Date   string `json:"date"`
+	Votes  int    `json:"votes"`
+	// Owner is set when the business answered
+	Owner bool `json:"owner"`
+}
+
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register([]Question{})
+}
+
+// the labels of the button opening all the questions in the most common languages
+var questionsButtonRegex = regexp.MustCompile(`(?i)(more questions|see all questions|all questions|weitere fragen|alle fragen|plus de questions|toutes les questions|más preguntas|todas las preguntas|altre domande|mais perguntas|περισσότερες ερωτήσεις)`)
+
+type QaJobOptions func(*QaJob)
+
+// QaJob opens the Questions & answers section of the place of an entry and adds
+// the questions to it. It follows the PlaceJob, so it continues with the
+// EmailExtractJob of the entry when the emails are extracted.
+type QaJob struct {
+	scrapemate.Job
+	tracing.Carrier
+
+	Entry       *Entry
+	ExitMonitor exiter.Exiter
+	// ExtractEmail continues with the EmailExtractJob of the entry
+	ExtractEmail bool
+	EmailPages   int
+
+	// pending is set when the entry is passed to the email job
+	pending      bool
+	emailFetcher *EmailFetcher
+}
+
+func NewQaJob(parentID string, entry *Entry, opts ...QaJobOptions) *QaJob {
+	const (
+		defaultPrio       = scrapemate.PriorityHigh
+		defaultMaxRetries = 1
+	)
+
+	job := QaJob{
+		Job: scrapemate.Job{
+			ID:         uuid.New().String(),
+			ParentID:   parentID,
+			Method:     http.MethodGet,
+			URL:        entry.Link,
+			MaxRetries: defaultMaxRetries,
+			Priority:   defaultPrio,
+		},
+		Entry: entry,
+	}
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	job.Headers = localeHeaders(job.Headers, entry.Language, "")
+
+	return &job
+}
+
+func WithQaJobExitMonitor(exitMonitor exiter.Exiter) QaJobOptions {
+	return func(j *QaJob) {
+		j.ExitMonitor = exitMonitor
+	}
+}
+
+// WithQaJobEmail extracts the emails of the website after the questions,
+// visiting up to pages pages with f, or with the fetcher of the app when f is nil
+func WithQaJobEmail(pages int, f *EmailFetcher) QaJobOptions {
+	return func(j *QaJob) {
+		j.ExtractEmail = true
+		j.EmailPages = pages
+		j.emailFetcher = f
+	}
+}
+
+func (j *QaJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	defer func() {
+		resp.Document = nil
+		resp.Body = nil
+		resp.Meta = nil
+	}()
+
+	// a failed page keeps the place without its questions
+	if resp.Error != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("questions failed", "job_id", j.ID, "place", j.Entry.Title, "error", resp.Error)
+	}
+
+	if questions, ok := resp.Meta["questions"].([]Question); ok {
+		j.Entry.Questions = questions
+	}
+
+	if j.ExtractEmail && j.Entry.IsWebsiteValidForEmail() {
+		opts := []EmailExtractJobOptions{}
+		if j.ExitMonitor != nil {
+			opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
+		}
+
+		if j.EmailPages > 0 {
+			opts = append(opts, WithEmailJobPages(j.EmailPages))
+		}
+
+		if j.emailFetcher != nil {
+			opts = append(opts, WithEmailJobFetcher(j.emailFetcher))
+		}
+
+		j.pending = true
+
+		next := []scrapemate.IJob{NewEmailJob(j.ID, j.Entry, opts...)}
+		tracing.Propagate(j.TraceContext(ctx), next)
+
+		return nil, next, nil
+	}
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesCompleted(1)
+	}
+
+	return j.Entry, nil, nil
+}
+
+func (j *QaJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
+	var resp scrapemate.Response
+
+	setConsentCookie(page, "")
+
+	if err := setPageHeaders(page, j.GetHeaders()); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	pageResponse, err := page.Goto(j.GetURL(), playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	})
+	if err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	clickRejectCookiesIfRequired(page)
+
+	resp.URL = pageResponse.URL()
+	resp.StatusCode = pageResponse.Status()
+
+	questions, err := fetchQuestions(page)
+	if err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Warn("could not fetch the questions", "url", j.GetURL(), "error", err)
+	}
+
+	resp.Meta = map[string]any{"questions": questions}
+
+	return resp
+}
+
+func (j *QaJob) UseInResults() bool {
+	return !j.pending
+}
+
+// UseBrowser renders the questions with the browser
+func (j *QaJob) UseBrowser() bool {
+	return true
+}
+
+func (j *QaJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// fetchQuestions opens all the questions of the place page and returns them
+// with their answers. Places without questions have none.
+func fetchQuestions(page playwright.Page) ([]Question, error) {
+	const timeout = 5000
+
+	button := page.Locator(`div[role='main'] button, div[role='main'] a`).Filter(playwright.LocatorFilterOptions{
+		HasText: questionsButtonRegex,
+	})
+
+	// the button is below the reviews summary, it is rendered once the overview is
+	_ = button.First().WaitFor(playwright.LocatorWaitForOptions{Timeout: playwright.Float(timeout)})
+
+	count, err := button.Count()
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	if err := button.First().Click(playwright.LocatorClickOptions{Timeout: playwright.Float(timeout)}); err != nil {
+		return nil, fmt.Errorf("could not open the questions: %w", err)
+	}
+
+	_ = page.Locator(questionsSelector).First().WaitFor(playwright.LocatorWaitForOptions{
+		Timeout: playwright.Float(timeout),
+	})
+
+	rawI, err := page.Evaluate(questionsJS, questionsSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := rawI.(string)
+	if !ok {
+		return nil, fmt.Errorf("could not convert to string, got type %T", rawI)
+	}
+
+	var questions []Question
+	if err := json.Unmarshal([]byte(raw), &questions); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+const questionsSelector = `div[role='main'] div[data-question-id]`
+
+// a question and its answers have the author, the date, the text and a helpful
+// button whose label has the number of votes; the answers are nested in the question
+const questionsJS = `
+(selector) => {
+	const dateRe = /\b(ago|yesterday|today|year|month|week|day|hour|vor|il y a|hace|fa|há|πριν)\b/i;
+	const ownerRe = /\((owner|inhaber|propriétaire|propietario|proprietario|proprietário|ιδιοκτήτης)\)/i;
+	const outside = (n, skip) => !(skip && skip.some((s) => s.contains(n)));
+	const votes = (el, skip) => {
+		const b = Array.from(el.querySelectorAll('button[aria-label*="helpful" i], button[aria-label*="hilfreich" i], button[aria-label*="utile" i], button[aria-label*="útil" i]'))
+			.find((n) => outside(n, skip));
+		// the votes may have their thousands grouped, e.g. 1,234 or 1.234
+		const m = b && b.getAttribute('aria-label').match(/\d[\d.,\s\u00a0\u202f]*/);
+		return m ? parseInt(m[0].replace(/\D/g, ''), 10) : 0;
+	};
+	const block = (el, skip) => {
+		const texts = Array.from(el.querySelectorAll('span, div'))
+			.filter((n) => n.children.length === 0 && outside(n, skip))
+			.map((n) => n.textContent.trim())
+			.filter((t) => t.length > 0);
+		const author = texts[0] || '';
+		const date = texts.find((t) => dateRe.test(t) && t.length < 40) || '';
+		const text = texts.filter((t) => t !== author && t !== date)
+			.reduce((a, t) => (t.length > a.length ? t : a), '');
+		return { text, author: author.replace(ownerRe, '').trim(), date, votes: votes(el, skip), owner: ownerRe.test(author) };
+	};
+	const questions = Array.from(document.querySelectorAll(selector)).map((q) => {
+		const answers = Array.from(q.querySelectorAll('[data-answer-id]'));
+		const question = block(q, answers);
+		delete question.owner;
+		question.answers = answers.map((a) => block(a));
+		return question;
+	});
+	return JSON.stringify(questions.filter((q) => q.text.length > 0));
+}
+`
@@ -0,0 +1,74 @@
+package gmaps
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDomain is the Google domain of the requests when none is given
+const DefaultDomain = "google.com"
+
+// domainRe matches the Google domains, e.g. google.de or google.com.br
+var domainRe = regexp.MustCompile(`^google(\.[a-z]{2,3}){1,2}$`)
+
+// glRe matches the country codes of the gl parameter, e.g. de or br
+var glRe = regexp.MustCompile(`^[a-z]{2}$`)
+
+// ParseRegion validates the country code of the gl parameter and the Google
+// domain of the requests, both optional. They are returned lower case, the
+// domain without its www. prefix.
+func ParseRegion(gl, domain string) (string, string, error) {
+	gl = strings.ToLower(strings.TrimSpace(gl))
+	if gl != "" && !glRe.MatchString(gl) {
+		return "", "", fmt.Errorf("invalid gl %q: use a two letter country code, e.g. de", gl)
+	}
+
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+	if domain != "" && !domainRe.MatchString(domain) {
+		return "", "", fmt.Errorf("invalid Google domain %q: use e.g. google.de or google.com.br", domain)
+	}
+
+	if domain == DefaultDomain {
+		domain = ""
+	}
+
+	return gl, domain, nil
+}
+
+// onDomain returns the URL of Google rawURL on domain, rawURL itself when
+// domain is empty
+func onDomain(rawURL, domain string) string {
+	if domain == "" {
+		return rawURL
+	}
+
+	for _, host := range []string{"www.", "maps."} {
+		prefix := "https://" + host + DefaultDomain + "/"
+		if strings.HasPrefix(rawURL, prefix) {
+			return "https://" + host + domain + "/" + strings.TrimPrefix(rawURL, prefix)
+		}
+	}
+
+	return rawURL
+}
+
+// WithRegion biases the search to the country of gl, the gl parameter, on the
+// Google domain, e.g. google.de. The places found get gl too.
+func WithRegion(gl, domain string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Gl, j.Domain = gl, domain
+		j.URL = onDomain(j.URL, domain)
+
+		if gl != "" {
+			j.URLParams["gl"] = gl
+		}
+	}
+}
+
+// WithPlaceJobGl biases the details of the place to the country of gl
+func WithPlaceJobGl(gl string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.URLParams["gl"] = gl
+	}
+}
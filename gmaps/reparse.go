@@ -0,0 +1,57 @@
+package gmaps
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// EntriesFromResponse parses a recorded response again: a place page gives one
+// entry and a fast mode search gives its results. The other responses, like the
+// search pages and the websites, give no entries.
+//
+// The entries are not filtered by the radius of the search and they do not have
+// the data of the website, which is scraped by follow-up jobs.
+func EntriesFromResponse(resp *scrapemate.Response) ([]*Entry, error) {
+	u, err := url.Parse(resp.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response url: %w", err)
+	}
+
+	switch {
+	case strings.HasSuffix(u.Path, "/search") && u.Query().Get("tbm") == "map":
+		body := removeFirstLine(resp.Body)
+		if len(body) == 0 {
+			return nil, nil
+		}
+
+		entries, _, err := ParseSearchResultsWithWarnings(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse search results: %w", err)
+		}
+
+		now := time.Now()
+		for _, e := range entries {
+			e.setLanguage(u.Query().Get("hl"))
+			e.ResolveTimezone(now)
+		}
+
+		return entries, nil
+	case strings.Contains(u.Path, "/maps/place/"):
+		entry, err := entryFromPlaceResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Link == "" {
+			entry.Link = resp.URL
+		}
+
+		return []*Entry{&entry}, nil
+	default:
+		return nil, nil
+	}
+}
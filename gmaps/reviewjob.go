@@ -0,0 +1,160 @@
+package gmaps
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/tracing"
+	"github.com/gosom/scrapemate"
+)
+
+// reviewPageSize is the number of reviews requested per page
+const reviewPageSize = 20
+
+type ReviewJobOptions func(*ReviewJob)
+
+// ReviewJob fetches the reviews of the place of an entry page by page, without
+// a browser, and adds them to its UserReviewsExtended. The entry is the result
+// of the last page.
+type ReviewJob struct {
+	scrapemate.Job
+	tracing.Carrier
+
+	Entry       *Entry
+	ExitMonitor exiter.Exiter
+	// MaxPages is the number of pages of reviews fetched, 0 fetches all of them
+	MaxPages int
+	// Limits limit the reviews fetched, the newest first
+	Limits ReviewLimits
+	// Visited is the number of pages fetched so far
+	Visited int
+	// Hl is the language of the reviews
+	Hl string
+	// RequestID is the same for all the pages of the reviews of a place
+	RequestID string
+
+	// pending is set when the entry is passed to the next page
+	pending bool
+}
+
+func NewReviewJob(parentID string, entry *Entry, opts ...ReviewJobOptions) *ReviewJob {
+	const (
+		defaultPrio       = scrapemate.PriorityHigh
+		defaultMaxRetries = 2
+	)
+
+	job := ReviewJob{
+		Job: scrapemate.Job{
+			ID:         uuid.New().String(),
+			ParentID:   parentID,
+			Method:     http.MethodGet,
+			MaxRetries: defaultMaxRetries,
+			Priority:   defaultPrio,
+		},
+		Entry: entry,
+		Hl:    "en",
+	}
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	// the id only has to be random, the uuid is a fallback for a failing crypto/rand
+	job.RequestID, _ = generateRandomID(21)
+	if job.RequestID == "" {
+		job.RequestID = job.ID[:21]
+	}
+
+	job.URL = reviewsURL(entry.DataID, "", job.Hl, reviewPageSize, job.RequestID, job.Limits.sort())
+
+	return &job
+}
+
+func WithReviewJobExitMonitor(exitMonitor exiter.Exiter) ReviewJobOptions {
+	return func(j *ReviewJob) {
+		j.ExitMonitor = exitMonitor
+	}
+}
+
+// WithReviewJobPages sets the maximum number of pages of reviews fetched
+func WithReviewJobPages(n int) ReviewJobOptions {
+	return func(j *ReviewJob) {
+		j.MaxPages = n
+	}
+}
+
+// WithReviewJobLimits limits the reviews fetched
+func WithReviewJobLimits(l ReviewLimits) ReviewJobOptions {
+	return func(j *ReviewJob) {
+		j.Limits = l
+	}
+}
+
+// WithReviewJobLang sets the language of the reviews
+func WithReviewJobLang(hl string) ReviewJobOptions {
+	return func(j *ReviewJob) {
+		if hl != "" {
+			j.Hl = hl
+		}
+	}
+}
+
+func (j *ReviewJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	defer func() {
+		resp.Document = nil
+		resp.Body = nil
+	}()
+
+	// a failed page keeps the reviews of the previous pages
+	if resp.Error != nil || resp.StatusCode != http.StatusOK {
+		scrapemate.GetLoggerFromContext(ctx).Warn("reviews page failed", "job_id", j.ID, "place", j.Entry.Title, "page", j.Visited+1)
+
+		return j.finish()
+	}
+
+	j.Visited++
+
+	reviews, done := j.Limits.apply(len(j.Entry.UserReviewsExtended), extractReviews(resp.Body))
+
+	j.Entry.UserReviewsExtended = append(j.Entry.UserReviewsExtended, reviews...)
+
+	token := extractNextPageToken(resp.Body)
+	if token == "" || done || (j.MaxPages > 0 && j.Visited >= j.MaxPages) {
+		return j.finish()
+	}
+
+	child := *j
+
+	child.Job.ID = uuid.New().String()
+	child.Job.URL = reviewsURL(j.Entry.DataID, token, j.Hl, reviewPageSize, j.RequestID, j.Limits.sort())
+	child.Job.Response = scrapemate.Response{}
+
+	j.pending = true
+
+	return nil, []scrapemate.IJob{&child}, nil
+}
+
+func (j *ReviewJob) finish() (any, []scrapemate.IJob, error) {
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesCompleted(1)
+	}
+
+	return j.Entry, nil, nil
+}
+
+func (j *ReviewJob) UseInResults() bool {
+	return !j.pending
+}
+
+func (j *ReviewJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// CacheURL returns the url of the page without the random request id, the
+// page is cached across the runs
+func (j *ReviewJob) CacheURL() string {
+	return strings.Replace(j.GetFullURL(), j.RequestID, "", 1)
+}
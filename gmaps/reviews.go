@@ -1,39 +1,116 @@
 package gmaps
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/fetchers/stealth"
 	"github.com/playwright-community/playwright-go"
 )
 
+// The sort orders of the reviews requested
+const (
+	reviewSortRelevant = 1
+	reviewSortNewest   = 2
+)
+
+// ReviewLimits limit the reviews fetched per place. With a limit the newest
+// reviews are requested first, and no more pages are requested once it is
+// reached.
+type ReviewLimits struct {
+	// Max is the maximum number of reviews of a place, 0 disables it
+	Max int
+	// Since drops the reviews posted before it, the zero time disables it
+	Since time.Time
+}
+
+// IsZero reports whether the reviews are not limited
+func (l ReviewLimits) IsZero() bool {
+	return l.Max == 0 && l.Since.IsZero()
+}
+
+// sort returns the sort order of the reviews requested
+func (l ReviewLimits) sort() int {
+	if l.IsZero() {
+		return reviewSortRelevant
+	}
+
+	return reviewSortNewest
+}
+
+// apply returns the reviews of a page kept when have reviews are kept
+// already, and whether a limit is reached. The reviews are the newest first,
+// the first one posted before Since ends them.
+func (l ReviewLimits) apply(have int, reviews []Review) ([]Review, bool) {
+	for i := range reviews {
+		if l.Max > 0 && have+i >= l.Max {
+			return reviews[:i], true
+		}
+
+		if l.Since.IsZero() {
+			continue
+		}
+
+		if posted, err := time.Parse("2006-1-2", reviews[i].When); err == nil && posted.Before(l.Since) {
+			return reviews[:i], true
+		}
+	}
+
+	return reviews, l.Max > 0 && have+len(reviews) >= l.Max
+}
+
 type fetchReviewsParams struct {
 	page        playwright.Page
 	mapURL      string
 	reviewCount int
+	limits      ReviewLimits
+	// proxies are the proxies of the run, the requests are sent directly
+	// without them
+	proxies scrapemate.ProxyRotator
 }
 
 type fetchReviewsResponse struct {
 	pages [][]byte
 }
 
-type fetcher struct {
+func init() {
+	// the response meta may be gob encoded, e.g. when recording fixtures
+	gob.Register(fetchReviewsResponse{})
+}
+
+func (r fetchReviewsResponse) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(r.pages); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r *fetchReviewsResponse) GobDecode(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(&r.pages)
+}
+
+type reviewFetcher struct {
 	httpClient scrapemate.HTTPFetcher
 	params     fetchReviewsParams
 }
 
-func newReviewFetcher(params fetchReviewsParams) *fetcher {
-	netClient := stealth.New("firefox", nil)
-	ans := fetcher{
+func newReviewFetcher(params fetchReviewsParams) *reviewFetcher {
+	netClient := stealth.New("firefox", params.proxies)
+	ans := reviewFetcher{
 		params:     params,
 		httpClient: netClient,
 	}
@@ -41,7 +118,7 @@ func newReviewFetcher(params fetchReviewsParams) *fetcher {
 	return &ans
 }
 
-func (f *fetcher) fetch(ctx context.Context) (fetchReviewsResponse, error) {
+func (f *reviewFetcher) fetch(ctx context.Context) (fetchReviewsResponse, error) {
 	requestIDForSession, err := generateRandomID(21)
 	if err != nil {
 		return fetchReviewsResponse{}, fmt.Errorf("failed to generate session request ID: %v", err)
@@ -62,16 +139,27 @@ func (f *fetcher) fetch(ctx context.Context) (fetchReviewsResponse, error) {
 
 	nextPageToken := extractNextPageToken(currentPageBody)
 
+	have := 0
+
 	for nextPageToken != "" {
+		if !f.params.limits.IsZero() {
+			kept, done := f.params.limits.apply(have, extractReviews(currentPageBody))
+			if done {
+				break
+			}
+
+			have += len(kept)
+		}
+
 		reviewURL, err = f.generateURL(f.params.mapURL, nextPageToken, 20, requestIDForSession)
 		if err != nil {
-			fmt.Printf("Error generating URL for token %s: %v\n", nextPageToken, err)
+			scrapemate.GetLoggerFromContext(ctx).Warn("cannot generate the reviews url", "token", nextPageToken, "error", err)
 			break
 		}
 
 		currentPageBody, err = f.fetchReviewPage(ctx, reviewURL)
 		if err != nil {
-			fmt.Printf("Error fetching review page with token %s: %v (%s)\n", nextPageToken, err, reviewURL)
+			scrapemate.GetLoggerFromContext(ctx).Warn("cannot fetch the reviews page", "token", nextPageToken, "url", reviewURL, "error", err)
 			break
 		}
 
@@ -83,7 +171,7 @@ func (f *fetcher) fetch(ctx context.Context) (fetchReviewsResponse, error) {
 }
 
 // Note the added 'requestID' parameter
-func (f *fetcher) generateURL(mapURL, pageToken string, pageSize int, requestID string) (string, error) {
+func (f *reviewFetcher) generateURL(mapURL, pageToken string, pageSize int, requestID string) (string, error) {
 	placeIDRegex := regexp.MustCompile(`!1s([^!]+)`)
 
 	placeIDMatch := placeIDRegex.FindStringSubmatch(mapURL)
@@ -96,28 +184,29 @@ func (f *fetcher) generateURL(mapURL, pageToken string, pageSize int, requestID
 		rawPlaceID = placeIDMatch[1]
 	}
 
-	encodedPlaceID := url.QueryEscape(rawPlaceID)
-
-	encodedPageToken := url.QueryEscape(pageToken)
+	return reviewsURL(rawPlaceID, pageToken, "el", pageSize, requestID, f.params.limits.sort()), nil
+}
 
+// reviewsURL returns the URL of a page of reviews of the place with the
+// data id placeID in the sort order, pageToken is empty for the first page
+func reviewsURL(placeID, pageToken, hl string, pageSize int, requestID string, sort int) string {
 	pbComponents := []string{
-		fmt.Sprintf("!1m6!1s%s", encodedPlaceID),
+		fmt.Sprintf("!1m6!1s%s", url.QueryEscape(placeID)),
 		"!6m4!4m1!1e1!4m1!1e3",
-		fmt.Sprintf("!2m2!1i%d!2s%s", pageSize, encodedPageToken),
+		fmt.Sprintf("!2m2!1i%d!2s%s", pageSize, url.QueryEscape(pageToken)),
 		fmt.Sprintf("!5m2!1s%s!7e81", requestID),
 		"!8m9!2b1!3b1!5b1!7b1",
-		"!12m4!1b1!2b1!4m1!1e1!11m0!13m1!1e1",
+		fmt.Sprintf("!12m4!1b1!2b1!4m1!1e1!11m0!13m1!1e%d", sort),
 	}
 
-	fullURL := fmt.Sprintf(
-		"https://www.google.com/maps/rpc/listugcposts?authuser=0&hl=el&pb=%s",
+	return fmt.Sprintf(
+		"https://www.google.com/maps/rpc/listugcposts?authuser=0&hl=%s&pb=%s",
+		url.QueryEscape(hl),
 		strings.Join(pbComponents, ""),
 	)
-
-	return fullURL, nil
 }
 
-func (f *fetcher) fetchReviewPage(ctx context.Context, u string) ([]byte, error) {
+func (f *reviewFetcher) fetchReviewPage(ctx context.Context, u string) ([]byte, error) {
 	job := scrapemate.Job{
 		Method: "GET",
 		URL:    u,
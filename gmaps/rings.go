@@ -0,0 +1,154 @@
+package gmaps
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Ring is a circle around the center of the search, searched at Zoom or at
+// the zoom covering Radius when Zoom is 0
+type Ring struct {
+	Radius float64
+	Zoom   int
+}
+
+// Rings plans the fast mode searches on concentric circles around the center
+// of the area: the inner rings are searched at the high zooms listing the
+// places of the dense center, then the whole area at the low zoom reaching
+// its far places. The places found by several rings are kept once.
+type Rings []Ring
+
+// ParseRings parses the radii in meters of the inner rings, each with an
+// optional zoom, like 1000,5000:14
+func ParseRings(s string) (Rings, error) {
+	var ans Rings
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		radius, zoom, hasZoom := strings.Cut(part, ":")
+
+		var (
+			ring Ring
+			err  error
+		)
+
+		ring.Radius, err = strconv.ParseFloat(strings.TrimSpace(radius), 64)
+		if err != nil || ring.Radius <= 0 {
+			return nil, fmt.Errorf("invalid ring %q: use <radius in meters>[:<zoom>]", part)
+		}
+
+		if hasZoom {
+			ring.Zoom, err = strconv.Atoi(strings.TrimSpace(zoom))
+			if err != nil || ring.Zoom < 1 || ring.Zoom > 21 {
+				return nil, fmt.Errorf("invalid ring %q: the zoom is between 1 and 21", part)
+			}
+		}
+
+		ans = append(ans, ring)
+	}
+
+	if len(ans) == 0 {
+		return nil, fmt.Errorf("invalid rings %q: use the radii of the rings, e.g. 1000,5000:14", s)
+	}
+
+	slices.SortFunc(ans, func(a, b Ring) int {
+		switch {
+		case a.Radius < b.Radius:
+			return -1
+		case a.Radius > b.Radius:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for i := 1; i < len(ans); i++ {
+		if ans[i].Radius == ans[i-1].Radius {
+			return nil, fmt.Errorf("invalid rings %q: the radius %g is repeated", s, ans[i].Radius)
+		}
+	}
+
+	return ans, nil
+}
+
+func (r Rings) String() string {
+	parts := make([]string, 0, len(r))
+
+	for _, ring := range r {
+		part := strconv.FormatFloat(ring.Radius, 'f', -1, 64)
+		if ring.Zoom > 0 {
+			part += ":" + strconv.Itoa(ring.Zoom)
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (r Rings) IsZero() bool {
+	return len(r) == 0
+}
+
+// Tiles returns the search locations of the rings around the center of area,
+// from the inner one, then of area at the zoom covering its radius. A ring at
+// a zoom whose viewport does not cover it is searched on several tiles, the
+// tiles searched by a previous ring are skipped. It returns an error when
+// there are more than limit.
+func (r Rings) Tiles(area MapLocation, viewport Viewport, limit int) ([]MapLocation, error) {
+	var tiles []MapLocation
+
+	// the searches are the same for the same coordinates and zoom
+	type search struct {
+		lat, lon, zoom float64
+	}
+
+	seen := make(map[search]struct{})
+
+	add := func(tile MapLocation) {
+		key := search{lat: tile.Lat, lon: tile.Lon, zoom: tile.ZoomLvl}
+		if _, ok := seen[key]; ok {
+			return
+		}
+
+		seen[key] = struct{}{}
+		tiles = append(tiles, tile)
+	}
+
+	for _, ring := range r {
+		if ring.Radius >= area.Radius {
+			return nil, fmt.Errorf("the ring of %g m is not inside the radius of %g m", ring.Radius, area.Radius)
+		}
+
+		zoom := ring.Zoom
+		if zoom == 0 {
+			zoom = ZoomForRadius(area.Lat, ring.Radius, viewport)
+		}
+
+		for _, tile := range TileArea(MapLocation{
+			Lat:     area.Lat,
+			Lon:     area.Lon,
+			ZoomLvl: float64(zoom),
+			Radius:  ring.Radius,
+		}, viewport) {
+			add(tile)
+		}
+	}
+
+	outer := area
+	outer.ZoomLvl = float64(ZoomForRadius(area.Lat, area.Radius, viewport))
+
+	add(outer)
+
+	if len(tiles) > limit {
+		return nil, fmt.Errorf("the rings need %d tiles, more than %d: lower their zooms", len(tiles), limit)
+	}
+
+	return tiles, nil
+}
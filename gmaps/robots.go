@@ -0,0 +1,91 @@
+package gmaps
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// robotsRules are the Allow and Disallow rules of the robots.txt of a website
+// that apply to all the user agents
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+// parseRobots returns the rules of the groups of the user agent "*". The rules
+// are path prefixes, the wildcards are not supported.
+func parseRobots(body []byte) robotsRules {
+	var (
+		rules robotsRules
+		// inGroup is set in the groups of the user agent "*"
+		inGroup bool
+		// agents is set while the user-agent lines of a group are read
+		agents bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share the group
+			if !agents {
+				inGroup = false
+			}
+
+			agents = true
+
+			if value == "*" {
+				inGroup = true
+			}
+		case "allow", "disallow":
+			agents = false
+
+			if !inGroup || value == "" {
+				continue
+			}
+
+			if key == "allow" {
+				rules.allow = append(rules.allow, value)
+			} else {
+				rules.disallow = append(rules.disallow, value)
+			}
+		default:
+			agents = false
+		}
+	}
+
+	return rules
+}
+
+// allowed reports whether the path may be fetched: the longest matching rule
+// wins and Allow wins a tie
+func (r robotsRules) allowed(path string) bool {
+	longest := func(prefixes []string) int {
+		n := -1
+
+		for _, p := range prefixes {
+			if strings.HasPrefix(path, p) && len(p) > n {
+				n = len(p)
+			}
+		}
+
+		return n
+	}
+
+	return longest(r.allow) >= longest(r.disallow)
+}
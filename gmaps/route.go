@@ -0,0 +1,281 @@
+package gmaps
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Route is the corridor of the fast mode searches along a route: the places
+// within half its width of the polyline of its points
+type Route struct {
+	// points are the latitudes and the longitudes of the route
+	points [][2]float64
+	width  float64
+}
+
+// ParseRoute parses the route of an encoded polyline, or of waypoints like
+// 48.8566,2.3522;45.764,4.8357, with a corridor of width meters
+func ParseRoute(s string, width float64) (*Route, error) {
+	s = strings.TrimSpace(s)
+
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid route width: %f", width)
+	}
+
+	var (
+		points [][2]float64
+		err    error
+	)
+
+	if strings.Trim(s, "0123456789.,;- ") == "" {
+		points, err = parseWaypoints(s)
+	} else {
+		points, err = decodePolyline(s)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+
+	if len(points) == 0 {
+		return nil, errors.New("invalid route: no points")
+	}
+
+	for _, p := range points {
+		if p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+			return nil, fmt.Errorf("invalid route: point %f,%f out of range", p[0], p[1])
+		}
+	}
+
+	return &Route{points: points, width: width}, nil
+}
+
+func parseWaypoints(s string) ([][2]float64, error) {
+	var points [][2]float64
+
+	for _, wp := range strings.Split(s, ";") {
+		if wp = strings.TrimSpace(wp); wp == "" {
+			continue
+		}
+
+		lat, lon, ok := strings.Cut(wp, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid waypoint %q: use lat,lon", wp)
+		}
+
+		var (
+			p   [2]float64
+			err error
+		)
+
+		if p[0], err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+			return nil, fmt.Errorf("invalid waypoint %q: %w", wp, err)
+		}
+
+		if p[1], err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil {
+			return nil, fmt.Errorf("invalid waypoint %q: %w", wp, err)
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+// decodePolyline decodes a polyline of the Encoded Polyline Algorithm
+// Format, with a precision of 5 decimals
+func decodePolyline(s string) ([][2]float64, error) {
+	const precision = 1e5
+
+	var (
+		points   [][2]float64
+		lat, lon int
+	)
+
+	for i := 0; i < len(s); {
+		for _, v := range []*int{&lat, &lon} {
+			var result, shift int
+
+			for {
+				if i >= len(s) {
+					return nil, errors.New("truncated polyline")
+				}
+
+				b := int(s[i]) - 63
+				i++
+
+				if b < 0 || b > 63 {
+					return nil, fmt.Errorf("invalid polyline character %q", s[i-1])
+				}
+
+				result |= (b & 0x1f) << shift
+				shift += 5
+
+				if b < 0x20 {
+					break
+				}
+			}
+
+			if result&1 != 0 {
+				*v += ^(result >> 1)
+			} else {
+				*v += result >> 1
+			}
+		}
+
+		points = append(points, [2]float64{float64(lat) / precision, float64(lon) / precision})
+	}
+
+	return points, nil
+}
+
+// Width returns the width in meters of the corridor
+func (r *Route) Width() float64 {
+	return r.width
+}
+
+// Length returns the length in meters of the route
+func (r *Route) Length() float64 {
+	var ans float64
+
+	for i := 1; i < len(r.points); i++ {
+		ans += r.segment(i)
+	}
+
+	return ans
+}
+
+// segment returns the length of the segment from the point i-1 to the point i
+func (r *Route) segment(i int) float64 {
+	from := Entry{Latitude: r.points[i-1][0], Longtitude: r.points[i-1][1]}
+
+	return from.haversineDistance(r.points[i][0], r.points[i][1])
+}
+
+// at returns the point of the route d meters from its start
+func (r *Route) at(d float64) (lat, lon float64) {
+	for i := 1; i < len(r.points); i++ {
+		l := r.segment(i)
+		if d <= l && l > 0 {
+			f := d / l
+			a, b := r.points[i-1], r.points[i]
+
+			return a[0] + (b[0]-a[0])*f, a[1] + (b[1]-a[1])*f
+		}
+
+		d -= l
+	}
+
+	last := r.points[len(r.points)-1]
+
+	return last[0], last[1]
+}
+
+// Contains reports whether lat, lon is in the corridor
+func (r *Route) Contains(lat, lon float64) bool {
+	return r.distance(lat, lon) <= r.width/2
+}
+
+// distance returns the distance in meters from lat, lon to the route, on the
+// plane tangent at lat, lon
+func (r *Route) distance(lat, lon float64) float64 {
+	cos := math.Cos(lat * math.Pi / 180)
+
+	project := func(p [2]float64) (x, y float64) {
+		return (p[1] - lon) * math.Pi / 180 * earthRadius * cos, (p[0] - lat) * math.Pi / 180 * earthRadius
+	}
+
+	ax, ay := project(r.points[0])
+	ans := math.Hypot(ax, ay)
+
+	for i := 1; i < len(r.points); i++ {
+		bx, by := project(r.points[i])
+
+		// the closest point of the segment to the origin
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+
+		if l := dx*dx + dy*dy; l > 0 {
+			t = min(max(-(ax*dx+ay*dy)/l, 0), 1)
+		}
+
+		ans = math.Min(ans, math.Hypot(ax+t*dx, ay+t*dy))
+		ax, ay = bx, by
+	}
+
+	return ans
+}
+
+// Tiles returns the search locations along the route covering the corridor,
+// at zoom or at the zoom of the viewports covering its width when zoom is 0,
+// or an error when there are more than limit
+func (r *Route) Tiles(zoom int, viewport Viewport, limit int) ([]MapLocation, error) {
+	// the viewports are narrower away from the equator, the tiles are sized
+	// for the latitude of the route closest to the pole
+	var lat float64
+	for _, p := range r.points {
+		lat = math.Max(lat, math.Abs(p[0]))
+	}
+
+	lat = min(lat, 85)
+	half := r.width / 2
+
+	if zoom == 0 {
+		zoom = ZoomForRadius(lat, half*math.Sqrt2, viewport)
+	}
+
+	radius := ViewportRadius(lat, zoom, viewport)
+	if radius <= half {
+		return nil, fmt.Errorf("the route corridor of %.0f m is wider than the searches at zoom %d: lower the zoom or use -zoom 0", r.width, zoom)
+	}
+
+	// a search covers the corridor over the chord of its circle at the edges
+	// of the corridor
+	spacing := 2 * math.Sqrt(radius*radius-half*half)
+	length := r.Length()
+	n := max(int(math.Ceil(length/spacing)), 1)
+
+	if n > limit {
+		return nil, fmt.Errorf("the route needs %d tiles at zoom %d, more than %d: lower the zoom", n, zoom, limit)
+	}
+
+	tiles := make([]MapLocation, 0, n)
+
+	for k := range n {
+		lat, lon := r.at((float64(k) + 0.5) * length / float64(n))
+
+		tiles = append(tiles, MapLocation{
+			Lat:     lat,
+			Lon:     lon,
+			ZoomLvl: float64(zoom),
+			Radius:  radius * math.Sqrt2,
+		})
+	}
+
+	return tiles, nil
+}
+
+// filter keeps the entries in the corridor, sorted by their distance to the
+// start of the route
+func (r *Route) filter(entries []*Entry) []*Entry {
+	inside := entries[:0]
+
+	for _, e := range entries {
+		if r.Contains(e.Latitude, e.Longtitude) {
+			inside = append(inside, e)
+		}
+	}
+
+	return filterAndSortEntriesWithinRadius(inside, r.points[0][0], r.points[0][1], math.Inf(1))
+}
+
+// WithSearchJobRoute keeps the places in the corridor of the route instead
+// of the circle of the tile searched
+func WithSearchJobRoute(r *Route) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.route = r
+	}
+}
@@ -0,0 +1,83 @@
+package gmaps
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// Sample describes how many of the places found by a search are processed.
+// The zero value processes all of them.
+type Sample struct {
+	// Rate is the probability that a place is processed (0-1)
+	Rate float64
+	// PerSearch is the number of the first places of every query that are
+	// processed, the query is not searched further once it has them
+	PerSearch int
+}
+
+// placesPerScroll is about the number of the places a scroll of the results
+// feed loads
+const placesPerScroll = 10
+
+// ParseSample parses a sample like "1%" (a rate) or "5" (the first places of
+// every query).
+// An empty string returns the zero Sample.
+func ParseSample(s string) (Sample, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Sample{}, nil
+	}
+
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		rate, err := strconv.ParseFloat(pct, 64)
+		if err != nil || rate <= 0 || rate > 100 {
+			return Sample{}, fmt.Errorf("invalid sample rate: %s", s)
+		}
+
+		return Sample{Rate: rate / 100}, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return Sample{}, fmt.Errorf("invalid sample size: %s", s)
+	}
+
+	return Sample{PerSearch: n}, nil
+}
+
+// IsZero reports whether the sample keeps all the places
+func (s Sample) IsZero() bool {
+	return s.Rate == 0 && s.PerSearch == 0
+}
+
+// scrollDepth returns the scrolls of the results feed needed for the sample,
+// maxDepth at most
+func (s Sample) scrollDepth(maxDepth int) int {
+	if s.PerSearch == 0 {
+		return maxDepth
+	}
+
+	return min(maxDepth, (s.PerSearch+placesPerScroll-1)/placesPerScroll)
+}
+
+func sample[T any](items []T, s Sample) []T {
+	if s.IsZero() || len(items) == 0 {
+		return items
+	}
+
+	if s.PerSearch > 0 {
+		return items[:min(len(items), s.PerSearch)]
+	}
+
+	ans := items[:0]
+
+	for _, item := range items {
+		if rand.Float64() < s.Rate {
+			ans = append(ans, item)
+		}
+	}
+
+	return ans
+}
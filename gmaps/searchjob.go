@@ -3,18 +3,36 @@ package gmaps
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/tracing"
 	"github.com/gosom/scrapemate"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrMaxResults is the error of the searches skipped because their query or
+// the run has the maximum number of places
+var ErrMaxResults = errors.New("maximum number of results reached")
+
 // Earth radius in meters (WGS84)
 const earthRadius = 6378137.0
 
+// searchPageSize is the number of results requested per page (!7i20 in pb)
+const searchPageSize = 20
+
 type SearchJobOptions func(*SearchJob)
 
 type MapLocation struct {
@@ -25,18 +43,78 @@ type MapLocation struct {
 }
 
 type MapSearchParams struct {
-	Location  MapLocation
-	Query     string
-	ViewportW int
-	ViewportH int
-	Hl        string
+	Location MapLocation
+	Query    string
+	// Category searches the places of the category around Location instead of
+	// Query, e.g. Restaurant: only the places of the category are kept
+	Category string
+	// Locationless searches without location bias, Location is ignored
+	Locationless bool
+	ViewportW    int
+	ViewportH    int
+	Hl           string
+	// Gl biases the results to the country of the code, e.g. de, and Domain
+	// is the Google domain of the search, e.g. google.de, google.com when empty
+	Gl     string
+	Domain string
+	// Offset is the index of the first result, a multiple of the page size
+	Offset int
+	// Metadata is the metadata of the input query, set on all the entries
+	Metadata map[string]string
+}
+
+// term returns the searched text, the category or the query
+func (p *MapSearchParams) term() string {
+	if p.Category != "" {
+		return p.Category
+	}
+
+	return p.Query
 }
 
 type SearchJob struct {
 	scrapemate.Job
+	tracing.Carrier
 
 	params      *MapSearchParams
 	ExitMonitor exiter.Exiter
+	fetcher     scrapemate.HTTPFetcher
+	sample      Sample
+	nearest     *Nearest
+	// quarantineDir keeps the bodies that could not be fully parsed
+	quarantineDir string
+	// splits is how many more times the tile is split when saturated
+	splits int
+	dedup  deduper.Deduper
+	// opts are passed to the searches of the subdivided tiles and of the next pages
+	opts []SearchJobOptions
+	// pages is the maximum number of result pages of the search
+	pages int
+	// found is the number of places of the previous pages
+	found int
+	// area is the circle the places are kept in, the tile's when nil
+	area *MapLocation
+	// polygon replaces the circle the places are kept in
+	polygon *Polygon
+	// route replaces the circle the places are kept in with its corridor
+	route *Route
+	// reviews fetches the reviews of the places with ReviewJobs
+	reviews      bool
+	reviewPages  int
+	reviewLimits ReviewLimits
+	// interstitials is the number of the previous tries of the search served
+	// the consent or the captcha page
+	interstitials int
+	// fallbackDepth is the depth of the browser search replacing the failed
+	// search, 0 disables the fallback
+	fallbackDepth int
+	// deterministicID derives the ids of the search and of its pages from
+	// their params, see JobID
+	deterministicID bool
+	failure         jobFailure
+	// skipResult is set when the search has no places, e.g. when it is
+	// pushed again, so that no result is sent to the writers
+	skipResult bool
 }
 
 func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob {
@@ -50,7 +128,7 @@ func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob
 		Job: scrapemate.Job{
 			ID:         uuid.New().String(),
 			Method:     http.MethodGet,
-			URL:        baseURL,
+			URL:        onDomain(baseURL, params.Domain),
 			URLParams:  buildGoogleMapsParams(params),
 			MaxRetries: defaultMaxRetries,
 			Priority:   defaultPrio,
@@ -58,11 +136,22 @@ func NewSearchJob(params *MapSearchParams, opts ...SearchJobOptions) *SearchJob
 	}
 
 	job.params = params
+	job.opts = opts
 
 	for _, opt := range opts {
 		opt(&job)
 	}
 
+	job.Headers = localeHeaders(job.Headers, params.Hl, params.Gl)
+
+	if job.deterministicID {
+		job.ID = params.jobID()
+	}
+
+	if job.nearest != nil {
+		job.nearest.start(params)
+	}
+
 	return &job
 }
 
@@ -72,42 +161,592 @@ func WithSearchJobExitMonitor(exitMonitor exiter.Exiter) SearchJobOptions {
 	}
 }
 
-func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+// WithSearchJobSample keeps only a sample of the entries found, a random one
+// or the first entries
+func WithSearchJobSample(s Sample) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.sample = s
+	}
+}
+
+// WithSearchJobNearest keeps only the entries of the query nearest to the
+// search center, n is shared by the searches of the query
+func WithSearchJobNearest(n *Nearest) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.nearest = n
+	}
+}
+
+// WithSearchJobCompleteness splits the saturated searches according to c,
+// see Completeness. The zoom level of the search is not changed, use ZoomForRadius.
+func WithSearchJobCompleteness(c Completeness) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.splits = c.splits()
+	}
+}
+
+// WithSearchJobPages requests the next pages of results, up to n pages in
+// total, as long as the pages are full
+func WithSearchJobPages(n int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.pages = n
+	}
+}
+
+// WithSearchJobReviews fetches up to maxPages pages of reviews of every place,
+// all of them when maxPages is 0. The places are the results of the ReviewJobs.
+func WithSearchJobReviews(maxPages int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.reviews = true
+		j.reviewPages = maxPages
+	}
+}
+
+// WithSearchJobReviewLimits limits the reviews fetched per place by
+// WithSearchJobReviews
+func WithSearchJobReviewLimits(l ReviewLimits) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.reviewLimits = l
+	}
+}
+
+// WithSearchJobDeduper skips the places already found by other searches,
+// e.g. by the overlapping subdivided tiles
+func WithSearchJobDeduper(d deduper.Deduper) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.dedup = d
+	}
+}
+
+// WithSearchJobQuarantine saves the response bodies that could not be
+// fully parsed in dir
+func WithSearchJobQuarantine(dir string) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.quarantineDir = dir
+	}
+}
+
+// WithSearchJobFetcher sets a fetcher that is used for this job
+// instead of the one configured in the app.
+func WithSearchJobFetcher(f scrapemate.HTTPFetcher) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.fetcher = f
+	}
+}
+
+// WithSearchJobBrowserFallback searches the query with a browser, scrolling
+// the results maxDepth times, when the fast search fails on every retry or its
+// response cannot be parsed. Only the first page of a search falls back.
+func WithSearchJobBrowserFallback(maxDepth int) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.fallbackDepth = maxDepth
+	}
+}
+
+// WithSearchJobDeterministicID derives the ids of the searches from their
+// query, location, zoom and page instead of random ids
+func WithSearchJobDeterministicID() SearchJobOptions {
+	return func(j *SearchJob) {
+		j.deterministicID = true
+	}
+}
+
+// WithSearchJobHeaders sends the headers with the requests of the search,
+// they replace the headers of the fetcher with the same names
+func WithSearchJobHeaders(headers map[string]string) SearchJobOptions {
+	return func(j *SearchJob) {
+		if j.Headers == nil {
+			j.Headers = make(map[string]string, len(headers))
+		}
+
+		maps.Copy(j.Headers, headers)
+	}
+}
+
+// UseInResults reports whether the places of the search are sent to the writers
+func (j *SearchJob) UseInResults() bool {
+	return !j.skipResult
+}
+
+// Fetcher returns the fetcher set with WithSearchJobFetcher
+func (j *SearchJob) Fetcher() scrapemate.HTTPFetcher {
+	return j.fetcher
+}
+
+// DoCheckResponse accepts the consent and the captcha pages, the job is
+// pushed again through another proxy
+func (j *SearchJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	return checkInterstitial(resp, j.Job.DoCheckResponse, &j.failure)
+}
+
+// ProcessOnFetchError processes the failed fetches of the searches falling
+// back to a browser, of the searches keeping the nearest places, and the
+// skipped searches of the runs with a maximum number of results
+func (j *SearchJob) ProcessOnFetchError() bool {
+	return j.fallbackDepth > 0 || j.nearest != nil || j.limited()
+}
+
+// SkipFetch skips the search once its query or the run has the maximum number
+// of places, see exiter.Exiter.SetMaxResults
+func (j *SearchJob) SkipFetch() error {
+	if j.limited() && j.ExitMonitor.MaxResultsReached(j.params.term()) {
+		return ErrMaxResults
+	}
+
+	return nil
+}
+
+// limited reports whether the run has a maximum number of places
+func (j *SearchJob) limited() bool {
+	if j.ExitMonitor == nil {
+		return false
+	}
+
+	perRun, perQuery := j.ExitMonitor.MaxResults()
+
+	return perRun > 0 || perQuery > 0
+}
+
+// logContext returns ctx with a logger that adds the search of the job to the records
+func (j *SearchJob) logContext(ctx context.Context) context.Context {
+	args := []any{"job_id", j.ID, "query", j.params.term()}
+
+	if !j.params.Locationless {
+		args = append(args, "lat", j.params.Location.Lat, "lon", j.params.Location.Lon, "zoom", j.params.Location.ZoomLvl)
+	}
+
+	if j.params.Offset > 0 {
+		args = append(args, "offset", j.params.Offset)
+	}
+
+	log := scrapemate.GetLoggerFromContext(ctx).With(args...)
+
+	return scrapemate.ContextWithLogger(ctx, log)
+}
+
+func (j *SearchJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	ctx = j.logContext(ctx)
+
+	ctx, span := tracing.StartJob(ctx, j, "gmaps.search.process", attribute.String("gmaps.query", j.params.term()))
+
+	data, next, err := j.process(ctx, resp)
+
+	if entries, ok := data.([]*Entry); ok {
+		span.SetAttributes(attribute.Int("gmaps.places", len(entries)))
+	}
+
+	tracing.Propagate(ctx, next)
+	tracing.End(span, err)
+
+	j.failure.processed(err)
+
+	return data, next, err
+}
+
+func (j *SearchJob) process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 		resp.Meta = nil
 	}()
 
+	if errors.Is(resp.Error, ErrMaxResults) {
+		scrapemate.GetLoggerFromContext(ctx).Debug("search skipped, the maximum number of results is reached")
+
+		j.ExitMonitor.IncrSeedCompleted(1)
+		j.skipResult = true
+
+		// the places of the query over the limit are not written
+		if j.nearest != nil {
+			j.nearest.done(j.params, nil)
+		}
+
+		return nil, nil, nil
+	}
+
+	if resp.Error != nil {
+		return j.fallback(ctx, resp.Error)
+	}
+
+	if page := fetcher.Interstitial(resp); page != "" {
+		return j.again(ctx, page)
+	}
+
 	body := removeFirstLine(resp.Body)
 	if len(body) == 0 {
+		return j.fallback(ctx, ErrEmptyBody)
+	}
+
+	_, parse := tracing.Tracer().Start(ctx, "gmaps.search.parse")
+
+	entries, warnings, err := SearchParserFromContext(ctx).Parse(body)
+
+	parse.SetAttributes(attribute.Int("gmaps.places", len(entries)), attribute.Int("gmaps.parse_warnings", len(warnings)))
+	tracing.End(parse, err)
+
+	if err != nil {
+		j.quarantine(ctx, body)
+
+		err = fmt.Errorf("%w: search results: %w", ErrParse, err)
+
+		scrapemate.GetLoggerFromContext(ctx).Error("search results not parsed", "bytes", len(body), "error", err)
+
+		return j.fallback(ctx, err)
+	}
+
+	if len(warnings) > 0 {
+		log := scrapemate.GetLoggerFromContext(ctx)
+
+		for _, w := range warnings {
+			log.Warn("search result skipped", "index", w.Index, "reason", w.Reason)
+		}
+
+		j.quarantine(ctx, body)
+
 		if j.ExitMonitor != nil {
-			j.ExitMonitor.IncrSeedCompleted(1)
+			j.ExitMonitor.IncrParseWarnings(len(warnings))
+		}
+	}
+
+	results := len(entries)
+
+	if j.params.Locationless {
+		// a full page means there are more
+		if results >= searchPageSize && j.lastPage() {
+			scrapemate.GetLoggerFromContext(ctx).Warn("locationless search returned a full last page, results are truncated: split the query by region or raise -pages")
 		}
-		return nil, nil, fmt.Errorf("empty response body")
+	} else if j.polygon != nil {
+		entries = j.polygon.filter(entries)
+	} else if j.route != nil {
+		entries = j.route.filter(entries)
+	} else {
+		loc := j.params.Location
+		if j.area != nil {
+			loc = *j.area
+		}
+
+		entries = filterAndSortEntriesWithinRadius(entries, loc.Lat, loc.Lon, loc.Radius)
+	}
+
+	if j.params.Category != "" {
+		entries = filterCategory(entries, j.params.Category)
+	}
+
+	found := len(entries)
+
+	if j.ExitMonitor != nil {
+		keys := make([]string, len(entries))
+		for i, e := range entries {
+			keys[i] = dedupKey(e)
+		}
+
+		j.ExitMonitor.AddPlaces(j.params.term(), keys)
+	}
+
+	var (
+		next []scrapemate.IJob
+		page *SearchJob
+	)
+
+	switch {
+	case j.saturated(results):
+		next = j.subdivide()
+	case results >= searchPageSize && !j.lastPage():
+		page = j.nextPage(found)
+		next = append(next, page)
 	}
 
-	entries, err := ParseSearchResults(body)
+	entries, next, reviewJobs, err := j.results(ctx, entries, next)
 	if err != nil {
 		if j.ExitMonitor != nil {
+			j.recordTile(found, err)
 			j.ExitMonitor.IncrSeedCompleted(1)
 		}
-		return nil, nil, fmt.Errorf("failed to parse search results: %w", err)
-	}
 
-	entries = filterAndSortEntriesWithinRadius(entries,
-		j.params.Location.Lat,
-		j.params.Location.Lon,
-		j.params.Location.Radius,
-	)
+		return nil, nil, err
+	}
 
 	if j.ExitMonitor != nil {
+		// the next page records the tile with the places of all the pages
+		if page == nil || len(next) == 0 {
+			j.recordTile(found, nil)
+		}
+
+		// the subdivided tiles and the next pages are seeds too, they are added before this one completes
+		j.ExitMonitor.IncrSeedCount(len(next))
 		j.ExitMonitor.IncrSeedCompleted(1)
+		j.ExitMonitor.RecordQuery(j.params.term(), len(entries))
+	}
+
+	return entries, append(next, reviewJobs...), nil
+}
+
+// results returns the places of the search to write and the ReviewJobs of the
+// places with reviews. The nearest places of the query are cut before the
+// deduplication, the sample and the middleware. next are the searches
+// following this one, they are dropped once the query has the maximum number
+// of places.
+func (j *SearchJob) results(ctx context.Context, entries []*Entry, next []scrapemate.IJob) ([]*Entry, []scrapemate.IJob, []scrapemate.IJob, error) {
+	if j.nearest != nil {
+		entries = j.nearest.done(j.params, entries)
+	}
+
+	if j.dedup != nil {
+		unique := entries[:0]
+
+		for _, e := range entries {
+			if j.dedup.AddIfNotExists(ctx, dedupKey(e)) {
+				unique = append(unique, e)
+			}
+		}
+
+		entries = unique
+	}
+
+	entries = sample(entries, j.sample)
+
+	now := time.Now()
+	for i := range entries {
+		entries[i].setLanguage(j.params.Hl)
+		entries[i].Metadata = j.params.Metadata
+		entries[i].ResolveTimezone(now)
+	}
+
+	entries, err := MiddlewareFromContext(ctx).afterParseAll(ctx, j, entries)
+	if err != nil {
+		j.drop(next)
+
+		return nil, nil, nil, err
+	}
+
+	if j.limited() {
+		entries = entries[:j.ExitMonitor.AddResults(j.params.term(), len(entries))]
+
+		// the next pages and the subdivided tiles would find places over the limit
+		if j.ExitMonitor.MaxResultsReached(j.params.term()) {
+			j.drop(next)
+			next = nil
+		}
+	}
+
+	if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrPlacesFound(len(entries))
+	}
+
+	entries, reviewJobs := j.reviewJobs(entries)
+
+	if j.ExitMonitor != nil {
+		// the places with reviews are completed by their ReviewJob
 		j.ExitMonitor.IncrPlacesCompleted(len(entries))
 	}
 
-	return entries, nil, nil
+	return entries, next, reviewJobs, nil
+}
+
+// drop completes the searches of next that are not pushed, see Nearest
+func (j *SearchJob) drop(next []scrapemate.IJob) {
+	if j.nearest == nil {
+		return
+	}
+
+	for range next {
+		j.nearest.done(j.params, nil)
+	}
+}
+
+// reviewJobs returns the ReviewJobs of the entries with reviews and the
+// entries without reviews, which are results of the search
+func (j *SearchJob) reviewJobs(entries []*Entry) ([]*Entry, []scrapemate.IJob) {
+	if !j.reviews {
+		return entries, nil
+	}
+
+	var (
+		rest []*Entry
+		jobs []scrapemate.IJob
+	)
+
+	for _, e := range entries {
+		if e.ReviewCount == 0 || e.DataID == "" {
+			rest = append(rest, e)
+
+			continue
+		}
+
+		opts := []ReviewJobOptions{
+			WithReviewJobPages(j.reviewPages),
+			WithReviewJobLimits(j.reviewLimits),
+			WithReviewJobLang(j.params.Hl),
+		}
+
+		if j.ExitMonitor != nil {
+			opts = append(opts, WithReviewJobExitMonitor(j.ExitMonitor))
+		}
+
+		jobs = append(jobs, NewReviewJob(j.ID, e, opts...))
+	}
+
+	return rest, jobs
+}
+
+// lastPage reports whether no more pages of results are requested
+func (j *SearchJob) lastPage() bool {
+	return j.params.Offset/searchPageSize+1 >= j.pages
+}
+
+// nextPage returns the search of the next page of results, found is the
+// number of places of this page
+func (j *SearchJob) nextPage(found int) *SearchJob {
+	params := *j.params
+	params.Offset += searchPageSize
+
+	page := NewSearchJob(&params, j.opts...)
+	page.ParentID = j.ID
+	page.found = j.found + found
+
+	return page
+}
+
+// again pushes the search again after the interstitial page, the last try
+// falls back to a browser
+func (j *SearchJob) again(ctx context.Context, page string) (any, []scrapemate.IJob, error) {
+	if err := interstitialError(ctx, page, j.interstitials); err != nil {
+		return j.fallback(ctx, err)
+	}
+
+	next := *j
+	next.ID = uuid.New().String()
+	next.ParentID = j.ID
+	next.interstitials++
+
+	j.skipResult = true
+
+	return nil, []scrapemate.IJob{&next}, nil
+}
+
+// fallback replaces the search failing with err by the search of the query
+// with a browser, the GmapJob completes the seed. Without the fallback the
+// seed is completed with err.
+//
+// The places of the browser are not filtered by the radius or the polygon.
+// The last search of a query keeping the nearest places writes the ones of
+// the other searches.
+func (j *SearchJob) fallback(ctx context.Context, err error) (any, []scrapemate.IJob, error) {
+	var (
+		entries    []*Entry
+		reviewJobs []scrapemate.IJob
+	)
+
+	if j.nearest != nil {
+		var rerr error
+
+		entries, _, reviewJobs, rerr = j.results(ctx, nil, nil)
+		if rerr != nil {
+			scrapemate.GetLoggerFromContext(ctx).Error("nearest places not written", "error", rerr)
+		}
+	}
+
+	if j.fallbackDepth == 0 || j.params.Offset > 0 || ctx.Err() != nil {
+		if j.ExitMonitor != nil {
+			j.recordTile(0, err)
+			j.ExitMonitor.IncrSeedCompleted(1)
+		}
+
+		if len(entries) > 0 || len(reviewJobs) > 0 {
+			scrapemate.GetLoggerFromContext(ctx).Error("search failed", "error", err)
+
+			return entries, reviewJobs, nil
+		}
+
+		return nil, nil, err
+	}
+
+	var (
+		geo  string
+		zoom int
+	)
+
+	if !j.params.Locationless {
+		geo = fmt.Sprintf("%f,%f", j.params.Location.Lat, j.params.Location.Lon)
+		zoom = int(j.params.Location.ZoomLvl)
+	}
+
+	opts := []GmapJobOptions{}
+	if j.ExitMonitor != nil {
+		opts = append(opts, WithExitMonitor(j.ExitMonitor))
+	}
+
+	if j.dedup != nil {
+		opts = append(opts, WithDeduper(j.dedup))
+	}
+
+	if !j.sample.IsZero() {
+		opts = append(opts, WithSample(j.sample))
+	}
+
+	if len(j.params.Metadata) > 0 {
+		opts = append(opts, WithMetadata(j.params.Metadata))
+	}
+
+	if j.deterministicID {
+		opts = append(opts, WithDeterministicID())
+	}
+
+	if j.params.Gl != "" || j.params.Domain != "" {
+		opts = append(opts, WithRegion(j.params.Gl, j.params.Domain))
+	}
+
+	opts = append(opts, WithHeaders(j.Headers))
+
+	job := NewGmapJob("", j.params.Hl, j.params.term(), j.fallbackDepth, false, geo, zoom, "", opts...)
+	job.ParentID = j.ID
+
+	scrapemate.GetLoggerFromContext(ctx).Warn("fast search failed, searching with a browser", "error", err)
+
+	j.skipResult = len(entries) == 0
+
+	return entries, append([]scrapemate.IJob{job}, reviewJobs...), nil
+}
+
+// recordTile records the places found in the tile by all the pages, before
+// the deduplication, and the error of the search
+func (j *SearchJob) recordTile(found int, err error) {
+	if j.params.Locationless {
+		return
+	}
+
+	t := exiter.Tile{
+		Query:   j.params.term(),
+		Lat:     j.params.Location.Lat,
+		Lon:     j.params.Location.Lon,
+		Zoom:    int(j.params.Location.ZoomLvl),
+		Radius:  j.params.Location.Radius,
+		Results: j.found + found,
+	}
+
+	if err != nil {
+		t.Error = err.Error()
+	}
+
+	j.ExitMonitor.RecordTile(t)
+}
+
+// quarantine saves the body in the quarantine directory, if one is set
+func (j *SearchJob) quarantine(ctx context.Context, body []byte) {
+	if j.quarantineDir == "" {
+		return
+	}
+
+	path := filepath.Join(j.quarantineDir, j.ID+".json")
+
+	err := os.MkdirAll(j.quarantineDir, os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(path, body, 0o600)
+	}
+
+	if err != nil {
+		scrapemate.GetLoggerFromContext(ctx).Error("failed to quarantine the search results", "path", path, "error", err)
+	}
 }
 
 func removeFirstLine(data []byte) []byte {
@@ -147,31 +786,73 @@ func Altitude(widthPx int, heightPx int, lat float64, zoom float64) float64 {
 	return altitude
 }
 
+// buildGoogleMapsParams returns the query of the search, with the viewport of
+// params or DefaultViewport when it is not set
 func buildGoogleMapsParams(params *MapSearchParams) map[string]string {
-	params.ViewportH = 1000
-	params.ViewportW = 1000
+	viewport := Viewport{Width: params.ViewportW, Height: params.ViewportH}.size()
 
 	ans := map[string]string{
 		"tbm":      "map",
 		"authuser": "0",
 		"hl":       params.Hl,
-		"q":        params.Query,
+		"q":        params.term(),
 	}
 
-	alt := Altitude(params.ViewportW, params.ViewportH, params.Location.Lat, params.Location.ZoomLvl)
+	if params.Gl != "" {
+		ans["gl"] = params.Gl
+	}
+
+	resultsPart := fmt.Sprintf("!7i%d!8i%d", searchPageSize, params.Offset) +
+		"!10b1!12m22!1m3!18b1!30b1!34e1!2m3!5m1!6e2!20e3!4b0!10b1!12b1!13b1!16b1!17m1!3e1!20m3!5e2!6b1!14b1!46m1!1b0" +
+		"!96b1!19m4!2m3!1i360!2i120!4i8"
+
+	if params.Locationless {
+		// without the map center and distance, only the viewport size
+		ans["pb"] = fmt.Sprintf("!4m8!2m3!1f0!2f0!3f0!3m2!1i%d!2i%d!4f%.1f%s",
+			viewport.Width,
+			viewport.Height,
+			params.Location.ZoomLvl,
+			resultsPart,
+		)
 
-	pb := fmt.Sprintf("!4m12!1m3!1d%f!2d%.4f!3d%.4f!2m3!1f0!2f0!3f0!3m2!1i%d!2i%d!4f%.1f!7i20!8i0"+
-		"!10b1!12m22!1m3!18b1!30b1!34e1!2m3!5m1!6e2!20e3!4b0!10b1!12b1!13b1!16b1!17m1!3e1!20m3!5e2!6b1!14b1!46m1!1b0"+
-		"!96b1!19m4!2m3!1i360!2i120!4i8",
+		return ans
+	}
+
+	alt := Altitude(viewport.Width, viewport.Height, params.Location.Lat, params.Location.ZoomLvl)
+
+	pb := fmt.Sprintf("!4m12!1m3!1d%f!2d%.4f!3d%.4f!2m3!1f0!2f0!3f0!3m2!1i%d!2i%d!4f%.1f%s",
 		alt,
 		params.Location.Lon,
 		params.Location.Lat,
-		params.ViewportW,
-		params.ViewportH,
+		viewport.Width,
+		viewport.Height,
 		params.Location.ZoomLvl,
+		resultsPart,
 	)
 
 	ans["pb"] = pb
 
 	return ans
 }
+
+// filterCategory keeps the entries of the category, the ones whose main
+// category or one of the others contains it case insensitively
+func filterCategory(entries []*Entry, category string) []*Entry {
+	category = strings.ToLower(category)
+	ans := entries[:0]
+
+	for _, e := range entries {
+		categories := e.Categories
+		if e.Category != "" {
+			categories = append([]string{e.Category}, categories...)
+		}
+
+		if slices.ContainsFunc(categories, func(c string) bool {
+			return strings.Contains(strings.ToLower(c), category)
+		}) {
+			ans = append(ans, e)
+		}
+	}
+
+	return ans
+}
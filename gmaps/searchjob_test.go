@@ -0,0 +1,148 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_SearchJobFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		resp scrapemate.Response
+	}{
+		{
+			name: "fetch error",
+			resp: scrapemate.Response{Error: errors.New("connection reset")},
+		},
+		{
+			name: "empty body",
+			resp: scrapemate.Response{StatusCode: 200, Body: []byte(")]}'\n")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			job := gmaps.NewSearchJob(&gmaps.MapSearchParams{
+				Location: gmaps.MapLocation{Lat: 34.7, Lon: 33.0, ZoomLvl: 9, Radius: 100000},
+				Query:    "restaurants in cyprus",
+				Hl:       "en",
+			}, gmaps.WithSearchJobBrowserFallback(1))
+
+			resp := tc.resp
+
+			data, next, err := job.Process(context.Background(), &resp)
+			require.NoError(t, err)
+			require.Nil(t, data)
+			require.Len(t, next, 1)
+			require.IsType(t, &gmaps.GmapJob{}, next[0])
+
+			// the search has no places, the browser search writes them
+			require.False(t, job.UseInResults())
+		})
+	}
+}
+
+func Test_SearchJobNearest(t *testing.T) {
+	body, err := os.ReadFile("../gmapstest/testdata/searches/restaurants-in-cyprus.json")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		seen   []string
+		fail   bool
+		titles []string
+	}{
+		{
+			name:   "nearest of the query",
+			titles: []string{"Kipriakon"},
+		},
+		{
+			// the places are deduplicated after the cut
+			name: "nearest seen",
+			seen: []string{"0x14e732fd76f0d90d:0xe5415928d6702b47"},
+		},
+		{
+			// the failed search writes the places of the other ones
+			name:   "last search failed",
+			fail:   true,
+			titles: []string{"Kipriakon"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dedup := deduper.New()
+			for _, key := range tc.seen {
+				require.True(t, dedup.AddIfNotExists(context.Background(), key))
+			}
+
+			nearest := gmaps.NewNearest(1)
+
+			// the tiles of the query find the same places
+			tiles := make([]*gmaps.SearchJob, 2)
+			for i := range tiles {
+				tiles[i] = gmaps.NewSearchJob(&gmaps.MapSearchParams{
+					Location: gmaps.MapLocation{Lat: 34.7, Lon: 33.0, ZoomLvl: 10, Radius: 100000},
+					Query:    "restaurants in cyprus",
+					Hl:       "en",
+				}, gmaps.WithSearchJobNearest(nearest), gmaps.WithSearchJobDeduper(dedup))
+			}
+
+			var titles []string
+
+			for i, job := range tiles {
+				resp := scrapemate.Response{StatusCode: 200, Body: body}
+				if tc.fail && i == len(tiles)-1 {
+					resp = scrapemate.Response{Error: errors.New("connection reset")}
+				}
+
+				data, _, err := job.Process(context.Background(), &resp)
+				require.NoError(t, err)
+
+				entries, ok := data.([]*gmaps.Entry)
+				require.True(t, ok)
+
+				// the last search of the query returns its places
+				if i < len(tiles)-1 {
+					require.Empty(t, entries)
+				}
+
+				for _, e := range entries {
+					titles = append(titles, e.Title)
+				}
+			}
+
+			require.Equal(t, tc.titles, titles)
+		})
+	}
+}
+
+func Test_SearchJobParams(t *testing.T) {
+	params := gmaps.MapSearchParams{
+		Location: gmaps.MapLocation{Lat: 34.7, Lon: 33.0, ZoomLvl: 9, Radius: 100000},
+		Query:    "restaurants in cyprus",
+		Hl:       "en",
+	}
+
+	job := gmaps.NewSearchJob(&params, gmaps.WithSearchJobDeterministicID())
+
+	// the default viewport is in the query, not in the params of the caller
+	require.Zero(t, params.ViewportW)
+	require.Zero(t, params.ViewportH)
+	require.Contains(t, job.URLParams["pb"], fmt.Sprintf("!3m2!1i%d!2i%d", gmaps.DefaultViewport.Width, gmaps.DefaultViewport.Height))
+
+	// the search has the id of the one with the default viewport
+	withViewport := params
+	withViewport.ViewportW, withViewport.ViewportH = gmaps.DefaultViewport.Width, gmaps.DefaultViewport.Height
+
+	require.Equal(t, gmaps.NewSearchJob(&withViewport, gmaps.WithSearchJobDeterministicID()).ID, job.ID)
+}
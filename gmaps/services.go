@@ -0,0 +1,40 @@
+package gmaps
+
+// serviceOptionsID is the id of the service options section of the About tab
+const serviceOptionsID = "service_options"
+
+// ServiceOptions are the service options of the About tab enabled for the place
+type ServiceOptions struct {
+	Delivery       bool `json:"delivery"`
+	Takeout        bool `json:"takeout"`
+	DineIn         bool `json:"dine_in"`
+	CurbsidePickup bool `json:"curbside_pickup"`
+}
+
+// setServiceOptions sets the service options from the enabled options of the
+// service options section. Only the english names (-lang en) are understood,
+// the options of the other languages are left in the attributes.
+func (e *Entry) setServiceOptions() {
+	for _, about := range e.About {
+		if about.ID != serviceOptionsID {
+			continue
+		}
+
+		for _, opt := range about.Options {
+			if !opt.Enabled {
+				continue
+			}
+
+			switch attributeKey(opt.Name) {
+			case "delivery":
+				e.ServiceOptions.Delivery = true
+			case "takeout", "takeaway":
+				e.ServiceOptions.Takeout = true
+			case "dine_in":
+				e.ServiceOptions.DineIn = true
+			case "curbside_pickup", "kerbside_pickup":
+				e.ServiceOptions.CurbsidePickup = true
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package gmaps
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// contactPagePatterns are the path fragments of the pages that usually have
+// the contact details, the most promising first
+var contactPagePatterns = []string{
+	"contact", "kontakt", "contacto", "contatto", "contato",
+	"impressum", "imprint", "mentions-legales", "aviso-legal", "legal-notice",
+	"about", "ueber-uns", "uber-uns", "a-propos", "quienes-somos", "chi-siamo",
+	"team", "staff",
+}
+
+// the browser may return the xml of the sitemap escaped in an html page
+var sitemapLocRegex = regexp.MustCompile(`(?i)(?:<|&lt;)loc(?:>|&gt;)\s*(.*?)\s*(?:<|&lt;)/loc(?:>|&gt;)`)
+
+// sitemapURL returns the url of the sitemap of the website of pageURL
+func sitemapURL(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+
+	return u.Scheme + "://" + u.Host + "/sitemap.xml"
+}
+
+// sitemapURLs returns the page urls listed in a sitemap.
+// Nested sitemaps of a sitemap index are not followed.
+func sitemapURLs(body []byte) []string {
+	matches := sitemapLocRegex.FindAllSubmatch(body, -1)
+	urls := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		u := html.UnescapeString(strings.TrimSpace(string(m[1])))
+		if u == "" || strings.HasSuffix(strings.ToLower(u), ".xml") {
+			continue
+		}
+
+		urls = append(urls, u)
+	}
+
+	return urls
+}
+
+// pageLinks returns the links of the page
+func pageLinks(doc *goquery.Document, pageURL string) []string {
+	var links []string
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" || strings.HasPrefix(href, "#") || (strings.Contains(href, ":") && !strings.HasPrefix(href, "http")) {
+			return
+		}
+
+		links = append(links, resolveURL(pageURL, href))
+	})
+
+	return links
+}
+
+// rankContactPages returns up to n urls of the website that look like
+// contact, imprint, about or team pages, the most promising first.
+func rankContactPages(website string, urls []string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	site, err := url.Parse(website)
+	if err != nil {
+		return nil
+	}
+
+	host := strings.TrimPrefix(site.Hostname(), "www.")
+
+	type candidate struct {
+		url   string
+		score int
+	}
+
+	var (
+		candidates []candidate
+		seen       = map[string]bool{}
+	)
+
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || strings.TrimPrefix(u.Hostname(), "www.") != host {
+			continue
+		}
+
+		u.Fragment = ""
+		key := strings.TrimSuffix(u.String(), "/")
+
+		if seen[key] || strings.TrimSuffix(u.Path, "/") == strings.TrimSuffix(site.Path, "/") {
+			continue
+		}
+
+		seen[key] = true
+
+		path := strings.ToLower(u.Path)
+
+		for i, p := range contactPagePatterns {
+			if strings.Contains(path, p) {
+				candidates = append(candidates, candidate{url: u.String(), score: i})
+
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+
+		// prefer /contact over /blog/how-to-contact-us
+		return len(candidates[i].url) < len(candidates[j].url)
+	})
+
+	ans := make([]string, 0, n)
+
+	for i := 0; i < len(candidates) && i < n; i++ {
+		ans = append(ans, candidates[i].url)
+	}
+
+	return ans
+}
@@ -0,0 +1,46 @@
+package gmaps
+
+import (
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/gosom/google-maps-scraper/social"
+)
+
+// addSocialLink sets the social media profile of link, the first profile
+// found for a network is kept
+func (e *Entry) addSocialLink(link string) {
+	network, profile, ok := social.Classify(extractActualURL(link))
+	if !ok {
+		return
+	}
+
+	var target *string
+
+	switch network {
+	case social.Facebook:
+		target = &e.Facebook
+	case social.Instagram:
+		target = &e.Instagram
+	case social.LinkedIn:
+		target = &e.LinkedIn
+	case social.Twitter:
+		target = &e.Twitter
+	case social.TikTok:
+		target = &e.TikTok
+	case social.YouTube:
+		target = &e.YouTube
+	default:
+		return
+	}
+
+	if *target == "" {
+		*target = profile
+	}
+}
+
+// addSocialLinksFromDoc adds the social media profiles linked by the page
+func (e *Entry) addSocialLinksFromDoc(doc *goquery.Document) {
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		e.addSocialLink(s.AttrOr("href", ""))
+	})
+}
@@ -0,0 +1,26 @@
+package gmaps
+
+import "strings"
+
+// The business statuses of the places
+const (
+	BusinessOperational       = "operational"
+	BusinessTemporarilyClosed = "temporarily_closed"
+	BusinessPermanentlyClosed = "permanently_closed"
+)
+
+// businessStatus returns the business status of the place of status, the
+// status shown by Google like "Closed ⋅ Opens 9 am" or "Permanently closed".
+// Only the english statuses (hl=en) of the closed places are understood.
+func businessStatus(status string) string {
+	status = strings.ToLower(status)
+
+	switch {
+	case strings.Contains(status, "permanently closed"), strings.Contains(status, "closed permanently"):
+		return BusinessPermanentlyClosed
+	case strings.Contains(status, "temporarily closed"), strings.Contains(status, "closed temporarily"):
+		return BusinessTemporarilyClosed
+	default:
+		return BusinessOperational
+	}
+}
@@ -0,0 +1,91 @@
+package gmaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// scrapeTab clicks the tab of the place page whose label matches label, waits for
+// the elements matching selector and decodes into out the JSON string js returns.
+// js is called with the selector. Nothing is decoded when the place has no such tab.
+func scrapeTab(page playwright.Page, label *regexp.Regexp, selector, js string, out any) error {
+	const timeout = 5000
+
+	tab := page.Locator(`button[role='tab']`).Filter(playwright.LocatorFilterOptions{
+		HasText: label,
+	})
+
+	count, err := tab.Count()
+	if err != nil || count == 0 {
+		return err
+	}
+
+	if err := tab.First().Click(playwright.LocatorClickOptions{Timeout: playwright.Float(timeout)}); err != nil {
+		return fmt.Errorf("could not open the tab: %w", err)
+	}
+
+	// a tab without items never renders them
+	_ = page.Locator(selector).First().WaitFor(playwright.LocatorWaitForOptions{
+		Timeout: playwright.Float(timeout),
+	})
+
+	rawI, err := page.Evaluate(js, selector)
+	if err != nil {
+		return err
+	}
+
+	raw, ok := rawI.(string)
+	if !ok {
+		return fmt.Errorf("could not convert to string, got type %T", rawI)
+	}
+
+	return json.Unmarshal([]byte(raw), out)
+}
+
+// sectionTexts returns the texts of the block of the overview whose heading
+// matches the JavaScript regular expression pattern, in the document order.
+// It must run before a tab is opened. A place without the block has no texts.
+func sectionTexts(page playwright.Page, pattern string) ([]string, error) {
+	rawI, err := page.Evaluate(sectionTextsJS, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := rawI.([]any)
+	if !ok {
+		return nil, fmt.Errorf("could not convert to []any, got type %T", rawI)
+	}
+
+	texts := make([]string, 0, len(raw))
+
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			texts = append(texts, s)
+		}
+	}
+
+	return texts, nil
+}
+
+// the block is the closest ancestor of the heading with a few texts
+const sectionTextsJS = `
+(pattern) => {
+	const re = new RegExp(pattern, 'i');
+	const heading = Array.from(document.querySelectorAll('div[role="main"] h2, div[role="main"] h3, div[role="main"] div'))
+		.find((el) => el.children.length === 0 && re.test(el.textContent.trim()));
+	if (!heading) {
+		return [];
+	}
+	let section = heading.parentElement;
+	for (let i = 0; i < 3 && section.parentElement && section.querySelectorAll('span, div').length < 4; i++) {
+		section = section.parentElement;
+	}
+	return Array.from(section.querySelectorAll('span, div'))
+		.filter((el) => el.children.length === 0 && el !== heading)
+		.map((el) => el.textContent.trim())
+		.filter((t) => t.length > 0);
+}
+`
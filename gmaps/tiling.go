@@ -0,0 +1,152 @@
+package gmaps
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The sizes in pixels of a side of the viewports of the searches
+const (
+	MinViewportPx = 256
+	MaxViewportPx = 4096
+)
+
+// Viewport is the size in pixels of the map of a search. At the same zoom a
+// larger viewport covers a larger area, but a search still returns at most
+// one page of results: the dense areas need smaller viewports or higher zooms.
+// The zero Viewport is DefaultViewport.
+type Viewport struct {
+	Width  int
+	Height int
+}
+
+// DefaultViewport is the viewport of the searches when none is set
+var DefaultViewport = Viewport{Width: 1000, Height: 1000}
+
+// ParseViewport parses a viewport like 1000x1000, the width and the height
+// are between MinViewportPx and MaxViewportPx
+func ParseViewport(s string) (Viewport, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if !ok {
+		return Viewport{}, fmt.Errorf("invalid viewport %q: use <width>x<height>, e.g. 1000x1000", s)
+	}
+
+	var (
+		v   Viewport
+		err error
+	)
+
+	if v.Width, err = strconv.Atoi(w); err != nil {
+		return Viewport{}, fmt.Errorf("invalid viewport width %q: %w", w, err)
+	}
+
+	if v.Height, err = strconv.Atoi(h); err != nil {
+		return Viewport{}, fmt.Errorf("invalid viewport height %q: %w", h, err)
+	}
+
+	if min(v.Width, v.Height) < MinViewportPx || max(v.Width, v.Height) > MaxViewportPx {
+		return Viewport{}, fmt.Errorf("invalid viewport %q: the sides must be between %d and %d pixels", s, MinViewportPx, MaxViewportPx)
+	}
+
+	return v, nil
+}
+
+// String returns the viewport like 1000x1000
+func (v Viewport) String() string {
+	v = v.size()
+
+	return strconv.Itoa(v.Width) + "x" + strconv.Itoa(v.Height)
+}
+
+// size returns the viewport, DefaultViewport when it is zero
+func (v Viewport) size() Viewport {
+	if v.Width <= 0 || v.Height <= 0 {
+		return DefaultViewport
+	}
+
+	return v
+}
+
+// side returns the smaller side of the viewport in pixels: the square of the
+// tiles that the viewport covers whole
+func (v Viewport) side() float64 {
+	v = v.size()
+
+	return float64(min(v.Width, v.Height))
+}
+
+// ViewportRadius returns the radius in meters of the circle that the search
+// viewport covers at zoom around lat, see ZoomForRadius
+func ViewportRadius(lat float64, zoom int, viewport Viewport) float64 {
+	worldWidth := 2 * math.Pi * earthRadius * math.Cos(lat*math.Pi/180)
+
+	return viewport.side() * worldWidth / (256 * math.Pow(2, float64(zoom))) / 2
+}
+
+// TileArea returns the search locations covering the circle of area at its
+// zoom level with viewport. When one viewport covers the circle it is the only location,
+// otherwise the circle is split in a grid of square viewports and the locations
+// are the squares that overlap the circle. Every location has the radius of the
+// circle around its square so that the tiles overlap a little.
+func TileArea(area MapLocation, viewport Viewport) []MapLocation {
+	// the viewports are narrower away from the equator, the grid is sized
+	// for the latitude of the circle closest to the pole
+	dLatArea := area.Radius / earthRadius * 180 / math.Pi
+	half := ViewportRadius(min(math.Abs(area.Lat)+dLatArea, 85), int(area.ZoomLvl), viewport)
+
+	if area.Radius <= half {
+		return []MapLocation{area}
+	}
+
+	n := int(math.Ceil((area.Radius - half) / (2 * half)))
+
+	return grid(area, half, n, n, func(dx, dy float64, _ MapLocation) bool {
+		// the distance from the center of the circle to the closest point of the square
+		nearX := math.Max(math.Abs(dx)-half, 0)
+		nearY := math.Max(math.Abs(dy)-half, 0)
+
+		return math.Hypot(nearX, nearY) <= area.Radius
+	})
+}
+
+// grid returns the squares of side 2*half meters of the grid centered on
+// center, with nx columns and ny rows on each side of the center, that keep
+// accepts. keep gets the offsets in meters of the square from the center.
+func grid(center MapLocation, half float64, nx, ny int, keep func(dx, dy float64, tile MapLocation) bool) []MapLocation {
+	side := 2 * half
+
+	tiles := make([]MapLocation, 0, (2*nx+1)*(2*ny+1))
+
+	for row := -ny; row <= ny; row++ {
+		dy := float64(row) * side
+		lat := center.Lat + dy/earthRadius*180/math.Pi
+
+		for col := -nx; col <= nx; col++ {
+			dx := float64(col) * side
+
+			tile := MapLocation{
+				Lat:     lat,
+				Lon:     center.Lon + dx/(earthRadius*math.Cos(lat*math.Pi/180))*180/math.Pi,
+				ZoomLvl: center.ZoomLvl,
+				Radius:  half * math.Sqrt2,
+			}
+
+			if keep(dx, dy, tile) {
+				tiles = append(tiles, tile)
+			}
+		}
+	}
+
+	return tiles
+}
+
+// WithSearchJobArea keeps the places within the circle of area, the whole
+// search, instead of the circle of the tile searched. The distances and the
+// bearings of the places are measured from the center of area.
+func WithSearchJobArea(area MapLocation) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.area = &area
+	}
+}
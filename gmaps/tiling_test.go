@@ -0,0 +1,103 @@
+package gmaps_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_TileArea(t *testing.T) {
+	tests := []struct {
+		name     string
+		area     gmaps.MapLocation
+		viewport gmaps.Viewport
+		tiles    int
+	}{
+		{
+			name:  "one viewport",
+			area:  gmaps.MapLocation{Lat: 37.98, Lon: 23.72, ZoomLvl: 15, Radius: 500},
+			tiles: 1,
+		},
+		{
+			name:  "grid",
+			area:  gmaps.MapLocation{Lat: 37.98, Lon: 23.72, ZoomLvl: 15, Radius: 10000},
+			tiles: 37,
+		},
+		{
+			name:     "larger viewport",
+			area:     gmaps.MapLocation{Lat: 37.98, Lon: 23.72, ZoomLvl: 15, Radius: 10000},
+			viewport: gmaps.Viewport{Width: 2000, Height: 2000},
+			tiles:    9,
+		},
+		{
+			name:  "lower zoom",
+			area:  gmaps.MapLocation{Lat: 37.98, Lon: 23.72, ZoomLvl: 13, Radius: 10000},
+			tiles: 5,
+		},
+		{
+			name:  "closer to the pole",
+			area:  gmaps.MapLocation{Lat: 64.1, Lon: -21.9, ZoomLvl: 15, Radius: 10000},
+			tiles: 97,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tiles := gmaps.TileArea(tc.area, tc.viewport)
+			require.Len(t, tiles, tc.tiles)
+
+			if tc.tiles == 1 {
+				require.Equal(t, tc.area, tiles[0])
+
+				return
+			}
+
+			center := &gmaps.Entry{Latitude: tc.area.Lat, Longtitude: tc.area.Lon}
+
+			for _, tile := range tiles {
+				require.Equal(t, tc.area.ZoomLvl, tile.ZoomLvl)
+				// the tiles overlap the circle
+				require.Less(t, center.DistanceTo(&gmaps.Entry{Latitude: tile.Lat, Longtitude: tile.Lon}), tc.area.Radius+tile.Radius)
+			}
+
+			// the points of the circle are in the circle of a tile
+			for _, point := range circlePoints(tc.area) {
+				covered := false
+
+				for _, tile := range tiles {
+					if point.DistanceTo(&gmaps.Entry{Latitude: tile.Lat, Longtitude: tile.Lon}) <= tile.Radius {
+						covered = true
+
+						break
+					}
+				}
+
+				require.True(t, covered, "point %f,%f", point.Latitude, point.Longtitude)
+			}
+		})
+	}
+}
+
+// circlePoints returns points on rings of the circle of area
+func circlePoints(area gmaps.MapLocation) []*gmaps.Entry {
+	const earthRadius = 6378137.0
+
+	var points []*gmaps.Entry
+
+	for _, r := range []float64{0.25, 0.5, 0.75, 1} {
+		for deg := 0; deg < 360; deg += 15 {
+			a := float64(deg) * math.Pi / 180
+			dy, dx := r*area.Radius*math.Sin(a), r*area.Radius*math.Cos(a)
+
+			points = append(points, &gmaps.Entry{
+				Latitude:   area.Lat + dy/earthRadius*180/math.Pi,
+				Longtitude: area.Lon + dx/(earthRadius*math.Cos(area.Lat*math.Pi/180))*180/math.Pi,
+			})
+		}
+	}
+
+	return points
+}
@@ -0,0 +1,84 @@
+package gmaps
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// timezonesGeoJSON is a FeatureCollection of the timezone boundaries, with the
+// IANA name of every feature in its tzid property. It is generated by
+// gen_timezones.go from the 2026b release of timezone-boundary-builder,
+// simplified to about a kilometer, without the zones of the oceans.
+//
+//go:embed timezones.geojson
+var timezonesGeoJSON []byte
+
+type timezoneBoundary struct {
+	tzid    string
+	polygon Polygon
+
+	minLon, minLat, maxLon, maxLat float64
+}
+
+var timezoneBoundaries = sync.OnceValue(func() []timezoneBoundary {
+	ans, err := parseTimezones(timezonesGeoJSON)
+	if err != nil {
+		panic(err)
+	}
+
+	return ans
+})
+
+func parseTimezones(data []byte) ([]timezoneBoundary, error) {
+	var collection struct {
+		Features []struct {
+			Properties struct {
+				TZID string `json:"tzid"`
+			} `json:"properties"`
+			Geometry geoJSONObject `json:"geometry"`
+		} `json:"features"`
+	}
+
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid timezone boundaries: %w", err)
+	}
+
+	ans := make([]timezoneBoundary, 0, len(collection.Features))
+
+	for i := range collection.Features {
+		f := &collection.Features[i]
+
+		b := timezoneBoundary{tzid: f.Properties.TZID}
+		if err := b.polygon.add(&f.Geometry); err != nil {
+			return nil, fmt.Errorf("invalid timezone boundary of %s: %w", b.tzid, err)
+		}
+
+		b.minLon, b.minLat, b.maxLon, b.maxLat = b.polygon.bounds()
+
+		ans = append(ans, b)
+	}
+
+	return ans, nil
+}
+
+// TimezoneAt returns the IANA timezone at the coordinates from the embedded
+// timezone boundaries, false when they are in none of them, e.g. at sea
+func TimezoneAt(lat, lon float64) (string, bool) {
+	boundaries := timezoneBoundaries()
+
+	for i := range boundaries {
+		b := &boundaries[i]
+
+		if lon < b.minLon || lon > b.maxLon || lat < b.minLat || lat > b.maxLat {
+			continue
+		}
+
+		if b.polygon.Contains(lat, lon) {
+			return b.tzid, true
+		}
+	}
+
+	return "", false
+}
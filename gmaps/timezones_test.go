@@ -0,0 +1,127 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_TimezoneAt(t *testing.T) {
+	// the cities on both sides of a border, a few kilometers apart
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     string
+	}{
+		{name: "El Paso", lat: 31.7619, lon: -106.4850, want: "America/Denver"},
+		{name: "Ciudad Juarez", lat: 31.6904, lon: -106.4245, want: "America/Ciudad_Juarez"},
+		{name: "San Diego", lat: 32.7157, lon: -117.1611, want: "America/Los_Angeles"},
+		{name: "Tijuana", lat: 32.5149, lon: -117.0382, want: "America/Tijuana"},
+		{name: "Nogales AZ", lat: 31.3404, lon: -110.9343, want: "America/Phoenix"},
+		{name: "Nogales Sonora", lat: 31.3086, lon: -110.9422, want: "America/Hermosillo"},
+		{name: "Laredo", lat: 27.5306, lon: -99.4803, want: "America/Chicago"},
+		{name: "Brownsville", lat: 25.9017, lon: -97.4975, want: "America/Chicago"},
+		{name: "Yuma", lat: 32.6927, lon: -114.6277, want: "America/Phoenix"},
+		{name: "Mexicali", lat: 32.6245, lon: -115.4523, want: "America/Tijuana"},
+		{name: "Calexico", lat: 32.6789, lon: -115.4989, want: "America/Los_Angeles"},
+		{name: "Damascus", lat: 33.5138, lon: 36.2765, want: "Asia/Damascus"},
+		{name: "Beirut", lat: 33.8938, lon: 35.5018, want: "Asia/Beirut"},
+		{name: "Zahle", lat: 33.8463, lon: 35.9020, want: "Asia/Beirut"},
+		{name: "Homs", lat: 34.7324, lon: 36.7137, want: "Asia/Damascus"},
+		{name: "Tripoli LB", lat: 34.4367, lon: 35.8497, want: "Asia/Beirut"},
+		{name: "Amman", lat: 31.9454, lon: 35.9284, want: "Asia/Amman"},
+		{name: "Jerusalem", lat: 31.7683, lon: 35.2137, want: "Asia/Jerusalem"},
+		{name: "Tel Aviv", lat: 32.0853, lon: 34.7818, want: "Asia/Jerusalem"},
+		{name: "Gaza", lat: 31.5017, lon: 34.4668, want: "Asia/Gaza"},
+		{name: "Daraa", lat: 32.6189, lon: 36.1021, want: "Asia/Damascus"},
+		{name: "Irbid", lat: 32.5556, lon: 35.8500, want: "Asia/Amman"},
+		{name: "Strasbourg", lat: 48.5734, lon: 7.7521, want: "Europe/Paris"},
+		{name: "Kehl", lat: 48.5729, lon: 7.8155, want: "Europe/Berlin"},
+		{name: "Basel", lat: 47.5596, lon: 7.5886, want: "Europe/Zurich"},
+		{name: "Gorizia", lat: 45.9400, lon: 13.6200, want: "Europe/Rome"},
+		{name: "Nova Gorica", lat: 45.9560, lon: 13.6480, want: "Europe/Ljubljana"},
+		{name: "Narva", lat: 59.3797, lon: 28.1791, want: "Europe/Tallinn"},
+		{name: "Ivangorod", lat: 59.3766, lon: 28.2190, want: "Europe/Moscow"},
+		{name: "Haparanda", lat: 65.8355, lon: 24.1368, want: "Europe/Stockholm"},
+		{name: "Tornio", lat: 65.8481, lon: 24.1466, want: "Europe/Helsinki"},
+		{name: "Gorlitz", lat: 51.1526, lon: 14.9873, want: "Europe/Berlin"},
+		{name: "Zgorzelec", lat: 51.1489, lon: 15.0089, want: "Europe/Warsaw"},
+		{name: "Frankfurt Oder", lat: 52.3471, lon: 14.5506, want: "Europe/Berlin"},
+		{name: "Slubice", lat: 52.3500, lon: 14.5600, want: "Europe/Warsaw"},
+		{name: "Ruse", lat: 43.8356, lon: 25.9657, want: "Europe/Sofia"},
+		{name: "Giurgiu", lat: 43.9037, lon: 25.9699, want: "Europe/Bucharest"},
+		{name: "Valga", lat: 57.7769, lon: 26.0473, want: "Europe/Tallinn"},
+		{name: "Valka", lat: 57.7752, lon: 26.0178, want: "Europe/Riga"},
+		{name: "Lahore", lat: 31.5204, lon: 74.3587, want: "Asia/Karachi"},
+		{name: "Amritsar", lat: 31.6340, lon: 74.8723, want: "Asia/Kolkata"},
+		{name: "Birgunj", lat: 27.0104, lon: 84.8770, want: "Asia/Kathmandu"},
+		{name: "Raxaul", lat: 26.9800, lon: 84.8500, want: "Asia/Kolkata"},
+		{name: "Shenzhen", lat: 22.5431, lon: 114.0579, want: "Asia/Shanghai"},
+		{name: "Hong Kong", lat: 22.3193, lon: 114.1694, want: "Asia/Hong_Kong"},
+		{name: "Macau", lat: 22.1987, lon: 113.5439, want: "Asia/Macau"},
+		{name: "Zhuhai", lat: 22.2710, lon: 113.5767, want: "Asia/Shanghai"},
+		{name: "Singapore", lat: 1.3521, lon: 103.8198, want: "Asia/Singapore"},
+		{name: "Johor Bahru", lat: 1.4927, lon: 103.7414, want: "Asia/Kuala_Lumpur"},
+		{name: "Dhaka", lat: 23.8103, lon: 90.4125, want: "Asia/Dhaka"},
+		{name: "Kolkata", lat: 22.5726, lon: 88.3639, want: "Asia/Kolkata"},
+		{name: "Agartala", lat: 23.8315, lon: 91.2868, want: "Asia/Kolkata"},
+		{name: "Istanbul", lat: 41.0082, lon: 28.9784, want: "Europe/Istanbul"},
+		{name: "Edirne", lat: 41.6771, lon: 26.5557, want: "Europe/Istanbul"},
+		{name: "Thessaloniki", lat: 40.6401, lon: 22.9444, want: "Europe/Athens"},
+		{name: "Tbilisi", lat: 41.7151, lon: 44.8271, want: "Asia/Tbilisi"},
+		{name: "Yerevan", lat: 40.1792, lon: 44.4991, want: "Asia/Yerevan"},
+		{name: "Baku", lat: 40.4093, lon: 49.8671, want: "Asia/Baku"},
+		{name: "Vienna", lat: 48.2082, lon: 16.3738, want: "Europe/Vienna"},
+		{name: "Bratislava", lat: 48.1486, lon: 17.1077, want: "Europe/Bratislava"},
+		{name: "Geneva", lat: 46.2044, lon: 6.1432, want: "Europe/Zurich"},
+		{name: "Annemasse", lat: 46.1934, lon: 6.2342, want: "Europe/Paris"},
+		{name: "Lille", lat: 50.6292, lon: 3.0573, want: "Europe/Paris"},
+		{name: "Kortrijk", lat: 50.8280, lon: 3.2649, want: "Europe/Brussels"},
+		{name: "Maastricht", lat: 50.8514, lon: 5.6910, want: "Europe/Amsterdam"},
+		{name: "Aachen", lat: 50.7753, lon: 6.0839, want: "Europe/Berlin"},
+		{name: "Luxembourg", lat: 49.6116, lon: 6.1319, want: "Europe/Luxembourg"},
+		{name: "Copenhagen", lat: 55.6761, lon: 12.5683, want: "Europe/Copenhagen"},
+		{name: "Malmo", lat: 55.6050, lon: 13.0038, want: "Europe/Stockholm"},
+		{name: "Pensacola", lat: 30.4213, lon: -87.2169, want: "America/Chicago"},
+		{name: "Tallahassee", lat: 30.4383, lon: -84.2807, want: "America/New_York"},
+		{name: "Nashville", lat: 36.1627, lon: -86.7816, want: "America/Chicago"},
+		{name: "Knoxville", lat: 35.9606, lon: -83.9207, want: "America/New_York"},
+		{name: "Denver", lat: 39.7392, lon: -104.9903, want: "America/Denver"},
+		{name: "Kansas City", lat: 39.0997, lon: -94.5786, want: "America/Chicago"},
+		{name: "Boise", lat: 43.6150, lon: -116.2023, want: "America/Boise"},
+		{name: "Spokane", lat: 47.6588, lon: -117.4260, want: "America/Los_Angeles"},
+		{name: "Las Vegas", lat: 36.1699, lon: -115.1398, want: "America/Los_Angeles"},
+		{name: "Phoenix", lat: 33.4484, lon: -112.0740, want: "America/Phoenix"},
+		{name: "Toronto", lat: 43.6532, lon: -79.3832, want: "America/Toronto"},
+		{name: "Winnipeg", lat: 49.8951, lon: -97.1384, want: "America/Winnipeg"},
+		{name: "Regina", lat: 50.4452, lon: -104.6189, want: "America/Regina"},
+		{name: "Calgary", lat: 51.0447, lon: -114.0719, want: "America/Edmonton"},
+		{name: "Vancouver", lat: 49.2827, lon: -123.1207, want: "America/Vancouver"},
+		{name: "Seattle", lat: 47.6062, lon: -122.3321, want: "America/Los_Angeles"},
+		{name: "Buenos Aires", lat: -34.6037, lon: -58.3816, want: "America/Argentina/Buenos_Aires"},
+		{name: "Montevideo", lat: -34.9011, lon: -56.1645, want: "America/Montevideo"},
+		{name: "Santiago", lat: -33.4489, lon: -70.6693, want: "America/Santiago"},
+		{name: "Mendoza", lat: -32.8895, lon: -68.8458, want: "America/Argentina/Mendoza"},
+		{name: "Lima", lat: -12.0464, lon: -77.0428, want: "America/Lima"},
+		{name: "La Paz", lat: -16.4897, lon: -68.1193, want: "America/La_Paz"},
+		{name: "Bogota", lat: 4.7110, lon: -74.0721, want: "America/Bogota"},
+		{name: "Caracas", lat: 10.4806, lon: -66.9036, want: "America/Caracas"},
+		{name: "Cucuta", lat: 7.8939, lon: -72.5078, want: "America/Bogota"},
+		{name: "San Cristobal VE", lat: 7.7669, lon: -72.2250, want: "America/Caracas"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := gmaps.TimezoneAt(tc.lat, tc.lon)
+			require.True(t, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_TimezoneAtSea(t *testing.T) {
+	_, ok := gmaps.TimezoneAt(-40, -130)
+	require.False(t, ok)
+}
@@ -0,0 +1,84 @@
+package gmaps
+
+import (
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// WebsiteMeta is the metadata of the website of a place
+type WebsiteMeta struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Language    string `json:"language"`
+	Image       string `json:"image"`
+}
+
+// The values of WebsiteCheck.Status
+const (
+	WebsiteLive        = "live"
+	WebsiteRedirected  = "redirected"
+	WebsiteParked      = "parked"
+	WebsiteDead        = "dead"
+	WebsiteUnreachable = "unreachable"
+)
+
+// WebsiteCheck is the result of requesting the website of a place
+type WebsiteCheck struct {
+	Status string `json:"status"`
+	// FinalURL is the url after following the redirects
+	FinalURL   string `json:"final_url"`
+	StatusCode int    `json:"status_code"`
+	Parked     bool   `json:"parked"`
+	Error      string `json:"error"`
+	// HTTPS is set when the final url uses https
+	HTTPS       bool        `json:"https"`
+	Certificate Certificate `json:"certificate"`
+}
+
+// Certificate is the TLS certificate of a website
+type Certificate struct {
+	Issuer       string    `json:"issuer"`
+	Organization string    `json:"organization"`
+	Expires      time.Time `json:"expires"`
+	// Valid is false when the certificate is not trusted or does not match the domain
+	Valid bool `json:"valid"`
+}
+
+// extractWebsiteMeta reads the title, description, language and
+// OpenGraph image of the page, preferring the standard tags over OpenGraph.
+func extractWebsiteMeta(doc *goquery.Document, pageURL string) WebsiteMeta {
+	meta := func(selector string) string {
+		return strings.TrimSpace(doc.Find(selector).First().AttrOr("content", ""))
+	}
+
+	ans := WebsiteMeta{
+		Title:       strings.TrimSpace(doc.Find("head title").First().Text()),
+		Description: meta("meta[name='description' i]"),
+		Language:    strings.TrimSpace(doc.Find("html").First().AttrOr("lang", "")),
+		Image:       meta("meta[property='og:image'], meta[name='og:image']"),
+	}
+
+	if ans.Title == "" {
+		ans.Title = meta("meta[property='og:title']")
+	}
+
+	if ans.Description == "" {
+		ans.Description = meta("meta[property='og:description']")
+	}
+
+	if ans.Language == "" {
+		ans.Language = meta("meta[http-equiv='content-language' i]")
+	}
+
+	if ans.Language == "" {
+		ans.Language = meta("meta[property='og:locale']")
+	}
+
+	if ans.Image != "" {
+		ans.Image = resolveURL(pageURL, ans.Image)
+	}
+
+	return ans
+}
@@ -0,0 +1,74 @@
+package gmapstest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+)
+
+// LoadSearches registers the search results of the fixtures of dir: every
+// file is a recorded tbm=map response, with its )]}' first line, returned for
+// the query of its name without extension, the dashes are spaces, e.g.
+// restaurants-in-cyprus.json. It returns the number of the queries.
+func (s *Server) LoadSearches(dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, path := range paths {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+
+		query := strings.ReplaceAll(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), "-", " ")
+
+		s.AddSearchResults(query, searchPayload(body))
+	}
+
+	return len(paths), nil
+}
+
+// LoadRecording registers the search results recorded in dir by -record, see
+// fetcher.NewRecorder: the responses of the tbm=map searches are returned for
+// their query. It returns the number of the queries.
+func (s *Server) LoadRecording(dir string) (int, error) {
+	n := 0
+
+	err := fetcher.ReadFixtures(dir, func(resp scrapemate.Response) error {
+		u, err := url.Parse(resp.URL)
+		if err != nil {
+			return fmt.Errorf("invalid fixture url %s: %w", resp.URL, err)
+		}
+
+		if u.Path != "/search" || u.Query().Get("tbm") != "map" || resp.StatusCode != http.StatusOK {
+			return nil
+		}
+
+		s.AddSearchResults(u.Query().Get("q"), searchPayload(resp.Body))
+		n++
+
+		return nil
+	})
+
+	return n, err
+}
+
+// searchPayload returns the payload of a tbm=map response, without its )]}'
+// first line
+func searchPayload(body []byte) []byte {
+	if first, rest, ok := bytes.Cut(body, []byte("\n")); ok && bytes.HasPrefix(first, []byte(searchPrefix)) {
+		return rest
+	}
+
+	return body
+}
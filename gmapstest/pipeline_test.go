@@ -0,0 +1,163 @@
+package gmapstest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
+	"github.com/gosom/scrapemate/scrapemateapp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/gmapstest"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// runPipeline runs the search of query through the app against the server
+// and returns the CSV rows written, the header first
+func runPipeline(t *testing.T, srv *gmapstest.Server, query string, m *gmaps.Middleware) [][]string {
+	t.Helper()
+
+	job := gmaps.NewSearchJob(&gmaps.MapSearchParams{
+		Location: gmaps.MapLocation{Lat: 34.7, Lon: 33.0, ZoomLvl: 9, Radius: 100000},
+		Query:    query,
+		Hl:       "en",
+	})
+
+	return runJobs(t, srv, m, job)
+}
+
+// runJobs runs the jobs through the app against the server and returns the
+// CSV rows written, the header first
+func runJobs(t *testing.T, srv *gmapstest.Server, m *gmaps.Middleware, jobs ...scrapemate.IJob) [][]string {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	matecfg, err := scrapemateapp.NewConfig(
+		[]scrapemate.ResultWriter{csvwriter.NewCsvWriter(csv.NewWriter(&out))},
+		scrapemateapp.WithConcurrency(1),
+		scrapemateapp.WithExitOnInactivity(500*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	app, err := runner.NewApp(matecfg, runner.WithRoundTripper(srv.Transport()), runner.WithMiddleware(m))
+	require.NoError(t, err)
+
+	err = app.Start(context.Background(), jobs...)
+	if err != nil && !errors.Is(err, scrapemate.ErrInactivityTimeout) {
+		require.NoError(t, err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+
+	return rows
+}
+
+func Test_Pipeline(t *testing.T) {
+	srv := gmapstest.NewServer()
+	defer srv.Close()
+
+	n, err := srv.LoadSearches("testdata/searches")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	rows := runPipeline(t, srv, "restaurants in cyprus", nil)
+	require.Len(t, rows, 3)
+
+	header := rows[0]
+	title := func(row []string) string {
+		for i, h := range header {
+			if h == "title" {
+				return row[i]
+			}
+		}
+
+		return ""
+	}
+
+	require.ElementsMatch(t, []string{"Kipriakon", "Happy Island Restaurant"}, []string{title(rows[1]), title(rows[2])})
+
+	// the places under 4.5 stars are dropped before they are written
+	rows = runPipeline(t, srv, "restaurants in cyprus", gmaps.NewMiddleware().UseAfterParse(runner.MinRating(4.5, 0)))
+	require.Len(t, rows, 2)
+	require.Equal(t, "Happy Island Restaurant", title(rows[1]))
+
+	require.Equal(t, 2, srv.Requests())
+}
+
+func Test_PipelineInterstitial(t *testing.T) {
+	srv := gmapstest.NewServer()
+	defer srv.Close()
+
+	srv.Block("/search", gmapstest.ScenarioCaptcha)
+
+	// the search is pushed again on the captcha without a result for the
+	// writers, which cannot write a nil result
+	rows := runPipeline(t, srv, "restaurants in cyprus", nil)
+	require.Empty(t, rows)
+
+	require.Greater(t, srv.Requests(), 1)
+}
+
+func Test_PipelineMaxResults(t *testing.T) {
+	srv := gmapstest.NewServer()
+	defer srv.Close()
+
+	_, err := srv.LoadSearches("testdata/searches")
+	require.NoError(t, err)
+
+	exitMonitor := exiter.New()
+	exitMonitor.SetMaxResults(1, 0)
+	require.Equal(t, 1, exitMonitor.AddResults("restaurants in cyprus", 1))
+
+	job := gmaps.NewSearchJob(&gmaps.MapSearchParams{
+		Location: gmaps.MapLocation{Lat: 34.7, Lon: 33.0, ZoomLvl: 9, Radius: 100000},
+		Query:    "restaurants in cyprus",
+		Hl:       "en",
+	}, gmaps.WithSearchJobExitMonitor(exitMonitor))
+
+	// the limit is reached, the search is skipped without a request and
+	// without a result for the writers
+	rows := runJobs(t, srv, nil, job)
+	require.Empty(t, rows)
+
+	require.Zero(t, srv.Requests())
+}
+
+func Test_PipelineSampleFastMode(t *testing.T) {
+	srv := gmapstest.NewServer()
+	defer srv.Close()
+
+	_, err := srv.LoadSearches("testdata/searches")
+	require.NoError(t, err)
+
+	// the limit of -fast-mode -sample 1, see runner.ParseConfig
+	exitMonitor := exiter.New()
+	exitMonitor.SetMaxResults(0, 1)
+
+	jobs, err := runner.CreateSeedJobs(strings.NewReader("restaurants in cyprus\n"), runner.SeedJobsOptions{
+		FastMode:       true,
+		LangCode:       "en",
+		GeoCoordinates: "34.67,33.04",
+		Zoom:           15,
+		Radius:         3000,
+		ExitMonitor:    exitMonitor,
+		Sample:         gmaps.Sample{PerSearch: 1},
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(jobs), 1)
+
+	// the tiles after the first one are skipped once the query has its place
+	rows := runJobs(t, srv, nil, jobs...)
+	require.Len(t, rows, 2)
+	require.Equal(t, 1, srv.Requests())
+}
@@ -0,0 +1,283 @@
+// Package gmapstest provides a server that emulates the Google Maps endpoints
+// used by the scraper, so that pipelines using the gmaps package can be
+// tested end to end without hitting Google.
+//
+// Payloads are registered per query and the server is used via its Transport:
+//
+//	srv := gmapstest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddSearchResults("cafe", searchPayload) // tbm=map response (fast mode)
+//	srv.AddPlace("cafe", placeJSON)             // listed in the search page
+//	srv.LoadSearches("testdata/searches")       // recorded tbm=map responses
+//
+//	cfg.RoundTripper = srv.Transport()
+package gmapstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Scenario is a failure the server emulates
+type Scenario int
+
+const (
+	// ScenarioCaptcha answers with the "unusual traffic" captcha page
+	ScenarioCaptcha Scenario = iota + 1
+	// ScenarioRateLimit answers with status 429 and no body
+	ScenarioRateLimit
+	// ScenarioServerError answers with status 500
+	ScenarioServerError
+)
+
+const searchPrefix = `)]}'`
+
+// captchaPage mimics the page Google serves to blocked clients
+const captchaPage = `<html><head><title>https://www.google.com/sorry/index</title></head>
+<body><div id="infoDiv">Our systems have detected unusual traffic from your computer network.</div>
+<form id="captcha-form" action="index" method="post"><div class="g-recaptcha"></div></form></body></html>`
+
+// Server is the emulated Google Maps server
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	searches map[string][]byte
+	places   map[string][]place
+	blocks   map[string]Scenario
+	requests atomic.Int64
+}
+
+type place struct {
+	id  string
+	raw []byte
+}
+
+// NewServer starts a new server. Close it when done.
+func NewServer() *Server {
+	s := Server{
+		searches: make(map[string][]byte),
+		places:   make(map[string][]place),
+		blocks:   make(map[string]Scenario),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/maps/search/", s.handleMapsSearch)
+	mux.HandleFunc("/maps/place/", s.handlePlace)
+
+	s.srv = httptest.NewServer(s.block(mux))
+
+	return &s
+}
+
+// URL returns the base url of the server
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Requests returns the number of requests the server received
+func (s *Server) Requests() int {
+	return int(s.requests.Load())
+}
+
+// Transport returns a RoundTripper that sends all the requests to the server,
+// whatever their host is.
+func (s *Server) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.srv.URL)
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.Host = target.Host
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+}
+
+// AddSearchResults registers the tbm=map payload returned for query.
+// The payload is what follows the first line of a real response.
+func (s *Server) AddSearchResults(query string, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.searches[normalize(query)] = payload
+}
+
+// AddPlace registers the place json (the APP_INITIALIZATION_STATE data of a place page)
+// as a result of query and returns the url of the place.
+func (s *Server) AddPlace(query string, raw []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := normalize(query)
+	id := fmt.Sprintf("place-%d", len(s.places[q])+1)
+
+	s.places[q] = append(s.places[q], place{id: id, raw: raw})
+
+	return s.placeURL(q, id)
+}
+
+// Block makes all the requests whose path starts with prefix fail with scenario.
+// Use "/" to block everything.
+func (s *Server) Block(prefix string, scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[prefix] = scenario
+}
+
+// Unblock removes a block added with Block
+func (s *Server) Unblock(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocks, prefix)
+}
+
+func (s *Server) block(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+
+		s.mu.Lock()
+
+		var scenario Scenario
+
+		for prefix, sc := range s.blocks {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				scenario = sc
+
+				break
+			}
+		}
+
+		s.mu.Unlock()
+
+		switch scenario {
+		case ScenarioCaptcha:
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(captchaPage))
+		case ScenarioRateLimit:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case ScenarioServerError:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("tbm") != "map" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	s.mu.Lock()
+	payload, ok := s.searches[normalize(r.URL.Query().Get("q"))]
+	s.mu.Unlock()
+
+	if !ok {
+		payload = []byte(`[["",[null]]]`)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, _ = w.Write([]byte(searchPrefix + "\n"))
+	_, _ = w.Write(payload)
+}
+
+// handleMapsSearch serves the search page with the feed of the places
+func (s *Server) handleMapsSearch(w http.ResponseWriter, r *http.Request) {
+	query, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/maps/search/"), "/")
+	q := normalize(query)
+
+	s.mu.Lock()
+	places := s.places[q]
+	s.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString(`<html><body><div role="feed">`)
+
+	for i := range places {
+		fmt.Fprintf(&sb, `<div jsaction="mouseover"><a href="%s"></a></div>`,
+			html.EscapeString(s.placeURL(q, places[i].id)))
+	}
+
+	sb.WriteString(`</div></body></html>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+// handlePlace serves a place page embedding the place json like Google does
+func (s *Server) handlePlace(w http.ResponseWriter, r *http.Request) {
+	query, id, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/maps/place/"), "/")
+	q := normalize(query)
+
+	var raw []byte
+
+	s.mu.Lock()
+
+	for _, p := range s.places[q] {
+		if p.id == id {
+			raw = p.raw
+		}
+	}
+
+	s.mu.Unlock()
+
+	if raw == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	state := []any{nil, nil, nil, []any{[]any{nil, nil, nil, nil, nil, nil, searchPrefix + "\n" + string(raw)}}}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	_, _ = fmt.Fprintf(w, "<html><head><script>window.APP_INITIALIZATION_STATE=%s;</script></head><body></body></html>", data)
+}
+
+func (s *Server) placeURL(q, id string) string {
+	return s.srv.URL + "/maps/place/" + url.PathEscape(q) + "/" + id
+}
+
+// normalize returns the form of a query used as key.
+// Queries arrive escaped in different ways depending on the job.
+func normalize(query string) string {
+	if unescaped, err := url.QueryUnescape(query); err == nil {
+		query = unescaped
+	}
+
+	return strings.ToLower(strings.Join(strings.Fields(query), "+"))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,66 @@
+package gmapstest_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/gmapstest"
+)
+
+func fetchAndProcess(t *testing.T, f scrapemate.HTTPFetcher, job scrapemate.IJob) (any, []scrapemate.IJob) {
+	t.Helper()
+
+	resp := f.Fetch(context.Background(), job)
+	require.NoError(t, resp.Error)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Body))
+	require.NoError(t, err)
+
+	resp.Document = doc
+
+	result, next, err := job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+
+	return result, next
+}
+
+func Test_Server(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/raw.json")
+	require.NoError(t, err)
+
+	srv := gmapstest.NewServer()
+	defer srv.Close()
+
+	srv.AddPlace("restaurants in limassol", raw)
+
+	f, err := fetcher.NewRoundTripper(srv.Transport())
+	require.NoError(t, err)
+
+	job := gmaps.NewGmapJob("", "en", "restaurants in limassol", 1, false, "", 0, "")
+
+	_, next := fetchAndProcess(t, f, job)
+	require.Len(t, next, 1)
+
+	result, _ := fetchAndProcess(t, f, next[0])
+
+	entry, ok := result.(*gmaps.Entry)
+	require.True(t, ok)
+	require.Equal(t, "Kipriakon", entry.Title)
+
+	srv.Block("/", gmapstest.ScenarioCaptcha)
+
+	resp := f.Fetch(context.Background(), job)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Contains(t, string(resp.Body), "unusual traffic")
+	require.Equal(t, 3, srv.Requests())
+}
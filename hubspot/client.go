@@ -0,0 +1,210 @@
+// Package hubspot exports the entries to HubSpot CRM as companies and contacts.
+package hubspot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://api.hubapi.com"
+	// maxRetries is how many times a rate limited or failed request is retried
+	maxRetries = 5
+)
+
+// APIError is a response of the HubSpot API with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("hubspot: status %d: %s", e.StatusCode, e.Body)
+}
+
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// do sends the request with body encoded as JSON and decodes the response in out.
+// The rate limited (429) and the server error responses are retried, after the
+// delay of the Retry-After header when there is one.
+func (c *client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	backoff := time.Second
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if err != nil {
+			return err
+		}
+
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+
+		if retry && attempt < maxRetries {
+			delay := backoff
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+				delay = time.Duration(s) * time.Second
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+
+			backoff *= 2
+
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &APIError{StatusCode: resp.StatusCode, Body: string(data)}
+		}
+
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+
+		return json.Unmarshal(data, out)
+	}
+}
+
+type object struct {
+	ID         string            `json:"id,omitempty"`
+	Properties map[string]string `json:"properties"`
+}
+
+type batchResponse struct {
+	Results []object `json:"results"`
+}
+
+// findCompanies returns the ids of the companies with the domains
+func (c *client) findCompanies(ctx context.Context, domains []string) (map[string]string, error) {
+	body := map[string]any{
+		"filterGroups": []any{
+			map[string]any{
+				"filters": []any{
+					map[string]any{"propertyName": "domain", "operator": "IN", "values": domains},
+				},
+			},
+		},
+		"properties": []string{"domain"},
+		"limit":      batchSize,
+	}
+
+	var resp batchResponse
+	if err := c.do(ctx, http.MethodPost, "/crm/v3/objects/companies/search", body, &resp); err != nil {
+		return nil, fmt.Errorf("cannot search the companies: %w", err)
+	}
+
+	ans := make(map[string]string, len(resp.Results))
+	for _, r := range resp.Results {
+		ans[r.Properties["domain"]] = r.ID
+	}
+
+	return ans, nil
+}
+
+// batch creates or updates the objects, the results are not in the order of the inputs
+func (c *client) batch(ctx context.Context, objectType, action string, inputs []object) ([]object, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	var resp batchResponse
+
+	path := "/crm/v3/objects/" + objectType + "/batch/" + action
+	if err := c.do(ctx, http.MethodPost, path, map[string]any{"inputs": inputs}, &resp); err != nil {
+		return nil, fmt.Errorf("cannot %s the %s: %w", action, objectType, err)
+	}
+
+	return resp.Results, nil
+}
+
+type contactInput struct {
+	ID         string            `json:"id"`
+	IDProperty string            `json:"idProperty"`
+	Properties map[string]string `json:"properties"`
+}
+
+// upsertContacts creates or updates the contacts by email and returns their ids by email
+func (c *client) upsertContacts(ctx context.Context, emails []string) (map[string]string, error) {
+	inputs := make([]contactInput, len(emails))
+	for i, email := range emails {
+		inputs[i] = contactInput{ID: email, IDProperty: "email", Properties: map[string]string{"email": email}}
+	}
+
+	var resp batchResponse
+	if err := c.do(ctx, http.MethodPost, "/crm/v3/objects/contacts/batch/upsert", map[string]any{"inputs": inputs}, &resp); err != nil {
+		return nil, fmt.Errorf("cannot upsert the contacts: %w", err)
+	}
+
+	ans := make(map[string]string, len(resp.Results))
+	for _, r := range resp.Results {
+		ans[r.Properties["email"]] = r.ID
+	}
+
+	return ans, nil
+}
+
+// associate links the contacts to their companies, contactID to companyID
+func (c *client) associate(ctx context.Context, links map[string]string) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	type ref struct {
+		ID string `json:"id"`
+	}
+
+	type input struct {
+		From ref `json:"from"`
+		To   ref `json:"to"`
+	}
+
+	inputs := make([]input, 0, len(links))
+	for contactID, companyID := range links {
+		inputs = append(inputs, input{From: ref{contactID}, To: ref{companyID}})
+	}
+
+	path := "/crm/v4/associations/contacts/companies/batch/associate/default"
+	if err := c.do(ctx, http.MethodPost, path, map[string]any{"inputs": inputs}, nil); err != nil {
+		return fmt.Errorf("cannot associate the contacts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,384 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/mapping"
+)
+
+// batchSize is the maximum number of inputs of the batch endpoints
+const batchSize = 100
+
+// defaultMapping maps the entries to the default company properties of HubSpot
+const defaultMapping = `
+columns:
+  - name: name
+    field: title
+  - name: website
+    field: web_site
+  - name: phone
+    field: phone
+  - name: address
+    field: complete_address.street
+  - name: city
+    field: complete_address.city
+  - name: state
+    field: complete_address.state
+  - name: zip
+    field: complete_address.postal_code
+  - name: country
+    field: complete_address.country
+  - name: description
+    field: description
+`
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer creates or updates a HubSpot company per place, matched by the domain
+// of its website, and optionally a contact per scraped email associated to it.
+type Writer struct {
+	client   client
+	schema   *mapping.Schema
+	contacts bool
+	dryRun   io.Writer
+}
+
+// WriterOption configures the writer
+type WriterOption func(*Writer)
+
+// WithSchema maps the entries to the company properties, the column names of
+// the schema are the internal names of the properties
+func WithSchema(schema *mapping.Schema) WriterOption {
+	return func(w *Writer) {
+		w.schema = schema
+	}
+}
+
+// WithContacts creates a contact for every email of the places
+func WithContacts() WriterOption {
+	return func(w *Writer) {
+		w.contacts = true
+	}
+}
+
+// WithDryRun writes the changes as lines of JSON to out instead of sending them.
+// Without a token every company is reported as created.
+func WithDryRun(out io.Writer) WriterOption {
+	return func(w *Writer) {
+		w.dryRun = out
+	}
+}
+
+// WithBaseURL replaces the HubSpot API URL
+func WithBaseURL(u string) WriterOption {
+	return func(w *Writer) {
+		w.client.baseURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithHTTPClient replaces the HTTP client of the requests
+func WithHTTPClient(c *http.Client) WriterOption {
+	return func(w *Writer) {
+		w.client.http = c
+	}
+}
+
+// NewWriter returns a writer using the private app token
+func NewWriter(token string, opts ...WriterOption) (*Writer, error) {
+	ans := Writer{
+		client: client{baseURL: defaultBaseURL, token: token, http: http.DefaultClient},
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	if token == "" && ans.dryRun == nil {
+		return nil, errors.New("hubspot: a token is required")
+	}
+
+	if ans.schema == nil {
+		schema, err := mapping.Parse([]byte(defaultMapping))
+		if err != nil {
+			return nil, err
+		}
+
+		ans.schema = schema
+	}
+
+	return &ans, nil
+}
+
+type company struct {
+	id         string
+	domain     string
+	properties map[string]string
+	emails     []string
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	buff := make([]*gmaps.Entry, 0, batchSize)
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		}
+
+		for len(buff) >= batchSize {
+			if err := w.flush(ctx, buff[:batchSize]); err != nil {
+				return err
+			}
+
+			buff = append(buff[:0], buff[batchSize:]...)
+		}
+	}
+
+	return w.flush(ctx, buff)
+}
+
+func (w *Writer) flush(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	companies := w.companies(entries)
+
+	var domains []string
+
+	for _, c := range companies {
+		if c.domain != "" {
+			domains = append(domains, c.domain)
+		}
+	}
+
+	if len(domains) > 0 && w.client.token != "" {
+		existing, err := w.client.findCompanies(ctx, domains)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range companies {
+			c.id = existing[c.domain]
+		}
+	}
+
+	if w.dryRun != nil {
+		return w.report(companies)
+	}
+
+	var creates, updates []object
+
+	for _, c := range companies {
+		if c.id != "" {
+			updates = append(updates, object{ID: c.id, Properties: c.properties})
+		} else {
+			creates = append(creates, object{Properties: c.properties})
+		}
+	}
+
+	if _, err := w.client.batch(ctx, "companies", "update", updates); err != nil {
+		return err
+	}
+
+	created, err := w.client.batch(ctx, "companies", "create", creates)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range created {
+		for _, c := range companies {
+			if c.id != "" {
+				continue
+			}
+
+			if (c.domain != "" && c.domain == o.Properties["domain"]) ||
+				(c.domain == "" && c.properties["name"] == o.Properties["name"]) {
+				c.id = o.ID
+
+				break
+			}
+		}
+	}
+
+	contacts := 0
+
+	if w.contacts {
+		contacts, err = w.upsertContacts(ctx, companies)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("hubspot: companies written", "created", len(creates), "updated", len(updates), "contacts", contacts)
+
+	return nil
+}
+
+// companies maps the entries to companies, merging the places with the same domain
+func (w *Writer) companies(entries []*gmaps.Entry) []*company {
+	var ans []*company
+
+	byDomain := make(map[string]*company)
+
+	for _, e := range entries {
+		record := w.schema.Apply(e)
+		headers, values := record.CsvHeaders(), record.CsvRow()
+
+		properties := make(map[string]string, len(headers)+1)
+
+		for i := range headers {
+			// an empty value would clear the property of an existing company
+			if values[i] != "" {
+				properties[headers[i]] = values[i]
+			}
+		}
+
+		domain := properties["domain"]
+		if domain == "" {
+			domain = domainOf(e.WebSite)
+		}
+
+		if domain != "" {
+			properties["domain"] = domain
+		}
+
+		if c, ok := byDomain[domain]; ok && domain != "" {
+			for k, v := range properties {
+				c.properties[k] = v
+			}
+
+			c.emails = append(c.emails, e.Emails...)
+
+			continue
+		}
+
+		c := &company{domain: domain, properties: properties, emails: append([]string(nil), e.Emails...)}
+		if domain != "" {
+			byDomain[domain] = c
+		}
+
+		ans = append(ans, c)
+	}
+
+	return ans
+}
+
+// upsertContacts creates the contacts of the emails and associates them to their company
+func (w *Writer) upsertContacts(ctx context.Context, companies []*company) (int, error) {
+	companyOf := make(map[string]string)
+
+	var emails []string
+
+	for _, c := range companies {
+		if c.id == "" {
+			continue
+		}
+
+		for _, email := range c.emails {
+			email = strings.ToLower(email)
+			if _, ok := companyOf[email]; !ok {
+				companyOf[email] = c.id
+				emails = append(emails, email)
+			}
+		}
+	}
+
+	for start := 0; start < len(emails); start += batchSize {
+		chunk := emails[start:min(start+batchSize, len(emails))]
+
+		ids, err := w.client.upsertContacts(ctx, chunk)
+		if err != nil {
+			return 0, err
+		}
+
+		links := make(map[string]string, len(ids))
+		for email, id := range ids {
+			links[id] = companyOf[strings.ToLower(email)]
+		}
+
+		if err := w.client.associate(ctx, links); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(emails), nil
+}
+
+// report writes the changes of the dry run
+func (w *Writer) report(companies []*company) error {
+	enc := json.NewEncoder(w.dryRun)
+
+	for _, c := range companies {
+		change := struct {
+			Action     string            `json:"action"`
+			ID         string            `json:"id,omitempty"`
+			Properties map[string]string `json:"properties"`
+			Contacts   []string          `json:"contacts,omitempty"`
+		}{
+			Action:     "create",
+			ID:         c.id,
+			Properties: c.properties,
+		}
+
+		if c.id != "" {
+			change.Action = "update"
+		}
+
+		if w.contacts {
+			change.Contacts = c.emails
+		}
+
+		if err := enc.Encode(change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// domainOf returns the host of the website without www, the domain HubSpot
+// identifies the companies with
+func domainOf(website string) string {
+	if website == "" {
+		return ""
+	}
+
+	if !strings.Contains(website, "://") {
+		website = "http://" + website
+	}
+
+	u, err := url.Parse(website)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	for _, shared := range sharedHosts {
+		if host == shared || strings.HasSuffix(host, "."+shared) {
+			return ""
+		}
+	}
+
+	return host
+}
+
+// sharedHosts host the pages of many businesses, their domain does not identify a company
+var sharedHosts = []string{
+	"facebook.com", "instagram.com", "linkedin.com", "twitter.com", "x.com", "tiktok.com",
+	"youtube.com", "linktr.ee", "business.site", "sites.google.com", "google.com",
+	"wixsite.com", "wordpress.com", "blogspot.com", "squarespace.com", "yelp.com", "tripadvisor.com",
+}
@@ -0,0 +1,82 @@
+// Package isochrone filters places by travel time instead of straight distance.
+//
+// An isochrone is the polygon of the points reachable from a center within a
+// travel time. It is obtained from a Provider; clients for Valhalla and OSRM
+// are included.
+package isochrone
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Point is a geographic coordinate
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Polygon is a closed ring of points
+type Polygon []Point
+
+// Provider computes isochrones
+type Provider interface {
+	// Isochrone returns the area reachable from center within d
+	Isochrone(ctx context.Context, center Point, d time.Duration) (Polygon, error)
+}
+
+// New creates the provider described by spec, which has the form
+// <provider>:<base url>, e.g. valhalla:http://localhost:8002 or osrm:http://localhost:5000
+func New(spec string) (Provider, error) {
+	name, baseURL, ok := strings.Cut(spec, ":")
+	if !ok || baseURL == "" {
+		return nil, fmt.Errorf("invalid isochrone provider %q: expected <provider>:<url>", spec)
+	}
+
+	switch strings.ToLower(name) {
+	case "valhalla":
+		return NewValhalla(baseURL, ""), nil
+	case "osrm":
+		return NewOSRM(baseURL, ""), nil
+	default:
+		return nil, fmt.Errorf("unknown isochrone provider %s", name)
+	}
+}
+
+// Contains reports whether p is inside the polygon (ray casting)
+func (poly Polygon) Contains(p Point) bool {
+	inside := false
+
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Filter returns an after parse function that drops the entries outside the polygon.
+// Entries without coordinates are dropped as well.
+func Filter(poly Polygon) gmaps.AfterParseFunc {
+	return func(_ context.Context, _ scrapemate.IJob, entry *gmaps.Entry) error {
+		if entry.Latitude == 0 && entry.Longtitude == 0 {
+			return gmaps.ErrSkipEntry
+		}
+
+		if !poly.Contains(Point{Lat: entry.Latitude, Lon: entry.Longtitude}) {
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
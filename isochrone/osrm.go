@@ -0,0 +1,128 @@
+package isochrone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Provider = (*OSRM)(nil)
+
+// OSRM approximates isochrones with the table service of an OSRM server,
+// which has no isochrone service: the travel times to points along
+// evenly spaced bearings are computed and the farthest reachable point
+// of every bearing becomes a vertex of the polygon.
+type OSRM struct {
+	baseURL string
+	profile string
+	client  *http.Client
+}
+
+const (
+	osrmBearings = 24
+	osrmSteps    = 10
+	// osrmMaxSpeed bounds the distance sampled, in meters per second (~130 km/h)
+	osrmMaxSpeed = 36.0
+	earthRadius  = 6371e3
+)
+
+// NewOSRM creates a client for the OSRM server at baseURL.
+// profile is the routing profile, driving if empty.
+func NewOSRM(baseURL, profile string) *OSRM {
+	const timeout = 30 * time.Second
+
+	if profile == "" {
+		profile = "driving"
+	}
+
+	return &OSRM{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		profile: profile,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type osrmResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+func (o *OSRM) Isochrone(ctx context.Context, center Point, d time.Duration) (Polygon, error) {
+	maxDistance := d.Seconds() * osrmMaxSpeed
+
+	points := make([]Point, 0, 1+osrmBearings*osrmSteps)
+	points = append(points, center)
+
+	for b := range osrmBearings {
+		bearing := float64(b) * 360 / osrmBearings
+
+		for s := 1; s <= osrmSteps; s++ {
+			points = append(points, destination(center, bearing, maxDistance*float64(s)/osrmSteps))
+		}
+	}
+
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = strconv.FormatFloat(p.Lon, 'f', 6, 64) + "," + strconv.FormatFloat(p.Lat, 'f', 6, 64)
+	}
+
+	u := fmt.Sprintf("%s/table/v1/%s/%s?sources=0&annotations=duration", o.baseURL, o.profile, strings.Join(coords, ";"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("osrm request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var ans osrmResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ans); err != nil {
+		return nil, fmt.Errorf("invalid osrm response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if ans.Code != "Ok" || len(ans.Durations) == 0 || len(ans.Durations[0]) != len(points) {
+		return nil, fmt.Errorf("osrm returned %s: %s", ans.Code, ans.Message)
+	}
+
+	durations := ans.Durations[0]
+	poly := make(Polygon, 0, osrmBearings)
+
+	for b := range osrmBearings {
+		vertex := center
+
+		for s := 1; s <= osrmSteps; s++ {
+			idx := 1 + b*osrmSteps + s - 1
+			if durations[idx] != nil && *durations[idx] <= d.Seconds() {
+				vertex = points[idx]
+			}
+		}
+
+		poly = append(poly, vertex)
+	}
+
+	return poly, nil
+}
+
+// destination returns the point at distance meters from p following bearing (degrees)
+func destination(p Point, bearing, distance float64) Point {
+	lat := p.Lat * math.Pi / 180
+	lon := p.Lon * math.Pi / 180
+	brng := bearing * math.Pi / 180
+	ang := distance / earthRadius
+
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(ang) + math.Cos(lat)*math.Sin(ang)*math.Cos(brng))
+	lon2 := lon + math.Atan2(math.Sin(brng)*math.Sin(ang)*math.Cos(lat), math.Cos(ang)-math.Sin(lat)*math.Sin(lat2))
+
+	return Point{Lat: lat2 * 180 / math.Pi, Lon: lon2 * 180 / math.Pi}
+}
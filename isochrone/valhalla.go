@@ -0,0 +1,121 @@
+package isochrone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ Provider = (*Valhalla)(nil)
+
+// Valhalla uses the isochrone service of a Valhalla server
+type Valhalla struct {
+	baseURL string
+	costing string
+	client  *http.Client
+}
+
+// NewValhalla creates a client for the Valhalla server at baseURL.
+// costing is the travel mode, auto if empty.
+func NewValhalla(baseURL, costing string) *Valhalla {
+	const timeout = 30 * time.Second
+
+	if costing == "" {
+		costing = "auto"
+	}
+
+	return &Valhalla{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		costing: costing,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type valhallaLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type valhallaContour struct {
+	Time float64 `json:"time"`
+}
+
+type valhallaRequest struct {
+	Locations []valhallaLocation `json:"locations"`
+	Costing   string             `json:"costing"`
+	Contours  []valhallaContour  `json:"contours"`
+	Polygons  bool               `json:"polygons"`
+}
+
+type valhallaResponse struct {
+	Features []struct {
+		Geometry struct {
+			Type        string        `json:"type"`
+			Coordinates [][][]float64 `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+	Error string `json:"error"`
+}
+
+func (v *Valhalla) Isochrone(ctx context.Context, center Point, d time.Duration) (Polygon, error) {
+	body, err := json.Marshal(valhallaRequest{
+		Locations: []valhallaLocation{{Lat: center.Lat, Lon: center.Lon}},
+		Costing:   v.costing,
+		Contours:  []valhallaContour{{Time: d.Minutes()}},
+		Polygons:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.baseURL+"/isochrone", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("valhalla request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ans valhallaResponse
+	if err := json.Unmarshal(data, &ans); err != nil {
+		return nil, fmt.Errorf("invalid valhalla response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || ans.Error != "" {
+		return nil, fmt.Errorf("valhalla returned status %d: %s", resp.StatusCode, ans.Error)
+	}
+
+	if len(ans.Features) == 0 || len(ans.Features[0].Geometry.Coordinates) == 0 {
+		return nil, fmt.Errorf("valhalla returned no isochrone")
+	}
+
+	// the first ring of a geojson polygon is the outer one, coordinates are lon, lat
+	ring := ans.Features[0].Geometry.Coordinates[0]
+	poly := make(Polygon, 0, len(ring))
+
+	for _, c := range ring {
+		if len(c) < 2 {
+			continue
+		}
+
+		poly = append(poly, Point{Lat: c[1], Lon: c[0]})
+	}
+
+	return poly, nil
+}
@@ -0,0 +1,16 @@
+package kafka
+
+import kafkago "github.com/segmentio/kafka-go"
+
+// Producer exposes producer to the tests
+type Producer = producer
+
+// NewTestWriter returns a writer sending the messages to p, which reports
+// their deliveries with the report of the writer
+func NewTestWriter(p func(report func([]kafkago.Message, error)) Producer) *Writer {
+	var w Writer
+
+	w.writer = p(w.report)
+
+	return &w
+}
@@ -0,0 +1,162 @@
+// Package kafka publishes the entries to a Kafka topic.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	// batchTimeout is how long the messages wait for a full batch
+	batchTimeout = time.Second
+	// closeTimeout is how long the pending messages are flushed for on shutdown
+	closeTimeout = 30 * time.Second
+)
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer publishes every entry as a JSON message keyed by its cid, so that the
+// messages of a place go to the same partition. The messages are sent in
+// batches in the background. Their delivery reports are counted and the
+// failures logged, and the pending messages are flushed when the results end.
+type Writer struct {
+	writer producer
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+}
+
+// producer sends the messages in the background, the kafka-go writer
+type producer interface {
+	WriteMessages(ctx context.Context, messages ...kafkago.Message) error
+	Close() error
+}
+
+// NewWriter returns the writer of the topic on the brokers, given as host:port
+func NewWriter(brokers []string, topic string) (*Writer, error) {
+	if len(brokers) == 0 || topic == "" {
+		return nil, errors.New("kafka: brokers and a topic are required")
+	}
+
+	ans := Writer{}
+
+	ans.writer = &kafkago.Writer{
+		Addr:                   kafkago.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafkago.Hash{},
+		RequiredAcks:           kafkago.RequireAll,
+		BatchTimeout:           batchTimeout,
+		Async:                  true,
+		AllowAutoTopicCreation: true,
+		Completion:             ans.report,
+	}
+
+	return &ans, nil
+}
+
+// report is the delivery report of a batch of messages
+func (w *Writer) report(messages []kafkago.Message, err error) {
+	if err != nil {
+		w.failed.Add(int64(len(messages)))
+
+		slog.Warn("kafka: places not delivered", "places", len(messages), "error", err)
+
+		return
+	}
+
+	w.delivered.Add(int64(len(messages)))
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{data}
+		case []*gmaps.Entry:
+			entries = data
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		messages := make([]kafkago.Message, 0, len(entries))
+
+		for _, e := range entries {
+			value, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			messages = append(messages, kafkago.Message{Key: []byte(e.Key()), Value: value})
+		}
+
+		// the async writer fails when the brokers cannot be reached, the
+		// delivery errors of the batches are in the reports
+		if err := w.writer.WriteMessages(ctx, messages...); err != nil {
+			return fmt.Errorf("kafka: %w", err)
+		}
+	}
+
+	return w.close()
+}
+
+// Publish sends v as a JSON message keyed by key outside of the results of a
+// run, e.g. the events of the monitor. Close flushes the messages.
+func (w *Writer) Publish(ctx context.Context, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := w.writer.WriteMessages(ctx, kafkago.Message{Key: []byte(key), Value: value}); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes the messages of Publish and waits for their reports
+func (w *Writer) Close() error {
+	return w.close()
+}
+
+// close flushes the pending messages and waits for their reports
+func (w *Writer) close() error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.writer.Close()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("kafka: %w", err)
+		}
+	case <-time.After(closeTimeout):
+		return errors.New("kafka: the pending messages were not flushed in time")
+	}
+
+	delivered, failed := w.delivered.Load(), w.failed.Load()
+
+	slog.Info("kafka: places delivered", "delivered", delivered, "failed", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("kafka: %d places not delivered", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/kafka"
+)
+
+var errBroker = errors.New("dial tcp: connection refused")
+
+// producer is a fake kafka-go writer delivering the messages when they are
+// written, or failing their delivery
+type producer struct {
+	report func([]kafkago.Message, error)
+
+	messages []kafkago.Message
+	// writeErr is the error of the writes, the brokers cannot be reached
+	writeErr error
+	// deliveryErr is the error of the delivery reports
+	deliveryErr error
+	// closeErr is the error of the flush of the pending messages
+	closeErr error
+	closed   bool
+}
+
+func (p *producer) WriteMessages(_ context.Context, messages ...kafkago.Message) error {
+	if p.writeErr != nil {
+		return p.writeErr
+	}
+
+	p.messages = append(p.messages, messages...)
+	p.report(messages, p.deliveryErr)
+
+	return nil
+}
+
+func (p *producer) Close() error {
+	p.closed = true
+
+	return p.closeErr
+}
+
+func newWriter(p *producer) *kafka.Writer {
+	return kafka.NewTestWriter(func(report func([]kafkago.Message, error)) kafka.Producer {
+		p.report = report
+
+		return p
+	})
+}
+
+func run(w *kafka.Writer, results ...scrapemate.Result) error {
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	return w.Run(context.Background(), in)
+}
+
+func Test_Writer(t *testing.T) {
+	var p producer
+
+	err := run(newWriter(&p),
+		scrapemate.Result{Data: &gmaps.Entry{Cid: "1", Title: "a"}},
+		scrapemate.Result{Data: []*gmaps.Entry{{DataID: "0x2", Title: "b"}, {Cid: "3", Title: "c"}}},
+		scrapemate.Result{Data: nil},
+	)
+	require.NoError(t, err)
+	require.True(t, p.closed)
+
+	// the messages are keyed by the place, so that they go to the same partition
+	keys := make([]string, 0, len(p.messages))
+	titles := make([]string, 0, len(p.messages))
+
+	for _, m := range p.messages {
+		var e gmaps.Entry
+		require.NoError(t, json.Unmarshal(m.Value, &e))
+
+		keys = append(keys, string(m.Key))
+		titles = append(titles, e.Title)
+	}
+
+	require.Equal(t, []string{"1", "0x2", "3"}, keys)
+	require.Equal(t, []string{"a", "b", "c"}, titles)
+}
+
+func Test_WriterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		producer producer
+		results  []scrapemate.Result
+		err      error
+	}{
+		{
+			name:     "brokers unreachable",
+			producer: producer{writeErr: errBroker},
+			results:  []scrapemate.Result{{Data: &gmaps.Entry{Cid: "1"}}},
+			err:      errBroker,
+		},
+		{
+			name:     "flush fails",
+			producer: producer{closeErr: errBroker},
+			results:  []scrapemate.Result{{Data: &gmaps.Entry{Cid: "1"}}},
+			err:      errBroker,
+		},
+		{
+			name:    "invalid data",
+			results: []scrapemate.Result{{Data: "place"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.producer
+
+			err := run(newWriter(&p), tc.results...)
+			require.Error(t, err)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func Test_WriterNotDelivered(t *testing.T) {
+	p := producer{deliveryErr: errBroker}
+
+	err := run(newWriter(&p), scrapemate.Result{Data: []*gmaps.Entry{{Cid: "1"}, {Cid: "2"}}})
+	require.EqualError(t, err, "kafka: 2 places not delivered")
+}
+
+func Test_WriterPublish(t *testing.T) {
+	var p producer
+
+	w := newWriter(&p)
+
+	require.NoError(t, w.Publish(context.Background(), "job-1", map[string]string{"event": "done"}))
+	require.NoError(t, w.Close())
+
+	require.Len(t, p.messages, 1)
+	require.Equal(t, "job-1", string(p.messages[0].Key))
+	require.JSONEq(t, `{"event":"done"}`, string(p.messages[0].Value))
+
+	p.writeErr = errBroker
+	require.ErrorIs(t, w.Publish(context.Background(), "job-1", "event"), errBroker)
+}
+
+func Test_NewWriter(t *testing.T) {
+	_, err := kafka.NewWriter(nil, "places")
+	require.Error(t, err)
+
+	_, err = kafka.NewWriter([]string{"localhost:9092"}, "")
+	require.Error(t, err)
+
+	_, err = kafka.NewWriter([]string{"localhost:9092"}, "places")
+	require.NoError(t, err)
+}
@@ -0,0 +1,154 @@
+// Package logger writes the logs of the scraper as structured slog records.
+//
+// The records of scrapemate, of the log package and of slog all go through the
+// same handler, so they have the same level and format.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gosom/kit/logging"
+	"github.com/gosom/scrapemate"
+)
+
+const (
+	// FormatText writes the records as key=value pairs
+	FormatText = "text"
+	// FormatJSON writes the records as JSON objects, one per line
+	FormatJSON = "json"
+
+	// LevelTrace is below debug, for the trace records of scrapemate
+	LevelTrace = slog.LevelDebug - 4
+)
+
+// New returns a logger writing to w. level is debug, info, warn or error and
+// format is text or json.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: use text or json", format)
+	}
+}
+
+// SetDefault makes l the logger of slog, of the log package and of scrapemate.
+// It must be called before the scrapemate apps are created.
+func SetDefault(l *slog.Logger) {
+	slog.SetDefault(l)
+	logging.SetDefault(Kit(l))
+}
+
+// Kit returns l as the logger used by scrapemate
+func Kit(l *slog.Logger) logging.Logger {
+	return &kitLogger{l: l, min: LevelTrace}
+}
+
+// kitLogger writes the records of a logging.Logger to slog
+type kitLogger struct {
+	l   *slog.Logger
+	min slog.Level
+}
+
+var _ logging.Logger = (*kitLogger)(nil)
+
+func (k *kitLogger) log(level slog.Level, msg string, args ...any) {
+	if level < k.min {
+		return
+	}
+
+	k.l.Log(context.Background(), level, msg, args...)
+}
+
+func (k *kitLogger) Info(msg string, args ...any) {
+	k.log(slog.LevelInfo, msg, args...)
+}
+
+func (k *kitLogger) Warn(msg string, args ...any) {
+	k.log(slog.LevelWarn, msg, args...)
+}
+
+func (k *kitLogger) Error(msg string, args ...any) {
+	k.log(slog.LevelError, msg, args...)
+}
+
+func (k *kitLogger) Debug(msg string, args ...any) {
+	k.log(slog.LevelDebug, msg, args...)
+}
+
+func (k *kitLogger) Trace(msg string, args ...any) {
+	k.log(LevelTrace, msg, args...)
+}
+
+func (k *kitLogger) Fatal(msg string, args ...any) {
+	k.l.Error(msg, args...)
+
+	os.Exit(1)
+}
+
+func (k *kitLogger) Panic(msg string, args ...any) {
+	k.l.Error(msg, args...)
+
+	panic(msg)
+}
+
+func (k *kitLogger) Log(level logging.Level, msg string, args ...any) {
+	switch level {
+	case logging.TRACE:
+		k.Trace(msg, args...)
+	case logging.DEBUG:
+		k.Debug(msg, args...)
+	case logging.INFO:
+		k.Info(msg, args...)
+	case logging.WARN:
+		k.Warn(msg, args...)
+	case logging.FATAL:
+		k.Fatal(msg, args...)
+	case logging.PANIC:
+		k.Panic(msg, args...)
+	case logging.DISABLED:
+	default:
+		k.Error(msg, args...)
+	}
+}
+
+func (k *kitLogger) With(args ...any) logging.Logger {
+	return &kitLogger{l: k.l.With(args...), min: k.min}
+}
+
+func (k *kitLogger) Level(level logging.Level) logging.Logger {
+	levels := map[logging.Level]slog.Level{
+		logging.TRACE: LevelTrace,
+		logging.DEBUG: slog.LevelDebug,
+		logging.INFO:  slog.LevelInfo,
+		logging.WARN:  slog.LevelWarn,
+		logging.ERROR: slog.LevelError,
+	}
+
+	// fatal, panic and disabled drop all the records but the fatal and panic ones
+	lvl, ok := levels[level]
+	if !ok {
+		lvl = slog.LevelError + 1
+	}
+
+	return &kitLogger{l: k.l, min: lvl}
+}
+
+func (k *kitLogger) NewContext(ctx context.Context) context.Context {
+	return scrapemate.ContextWithLogger(ctx, k)
+}
@@ -4,19 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/gosom/google-maps-scraper/logger"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/runner/databaserunner"
 	"github.com/gosom/google-maps-scraper/runner/filerunner"
 	"github.com/gosom/google-maps-scraper/runner/installplaywright"
 	"github.com/gosom/google-maps-scraper/runner/lambdaaws"
+	"github.com/gosom/google-maps-scraper/runner/monitorrunner"
+	"github.com/gosom/google-maps-scraper/runner/proxycheckrunner"
+	"github.com/gosom/google-maps-scraper/runner/reportrunner"
+	"github.com/gosom/google-maps-scraper/runner/schedulerunner"
 	"github.com/gosom/google-maps-scraper/runner/webrunner"
+	"github.com/gosom/google-maps-scraper/tracing"
 )
 
+// tracingShutdownTimeout is how long the pending spans are flushed for on exit
+const tracingShutdownTimeout = 5 * time.Second
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -25,23 +35,65 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	cfg := runner.ParseConfig()
+
+	// the file runs finish the jobs in flight and write their results first
+	graceful := cfg.RunMode == runner.RunModeFile && cfg.ShutdownTimeout > 0
+
+	shutdown := func() {}
+	if graceful {
+		ctx, shutdown = runner.WithShutdown(ctx)
+	}
+
 	go func() {
 		<-sigChan
 
-		log.Println("Received signal, shutting down...")
+		if graceful {
+			slog.Info("received signal, finishing the jobs in flight, send it again to stop at once", "timeout", cfg.ShutdownTimeout)
+
+			shutdown()
+
+			select {
+			case <-sigChan:
+			case <-time.After(cfg.ShutdownTimeout):
+				slog.Warn("the jobs in flight did not finish in time")
+			}
+		}
+
+		slog.Info("received signal, shutting down")
 
 		cancel()
 	}()
 
-	cfg := runner.ParseConfig()
+	// the report and the install commands have no flags, they keep the default logger
+	if cfg.Logger != nil {
+		logger.SetDefault(cfg.Logger)
+	}
 
-	runnerInstance, err := runnerFactory(cfg)
+	shutdownTracing, err := tracing.Setup(ctx)
 	if err != nil {
 		cancel()
 		os.Stderr.WriteString(err.Error() + "\n")
 
+		os.Exit(1)
+	}
+
+	closeTelemetry := func() {
 		runner.Telemetry().Close()
 
+		sctx, scancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer scancel()
+
+		_ = shutdownTracing(sctx)
+	}
+
+	runnerInstance, err := runnerFactory(cfg)
+	if err != nil {
+		cancel()
+		os.Stderr.WriteString(err.Error() + "\n")
+
+		closeTelemetry()
+
 		os.Exit(1)
 	}
 
@@ -49,15 +101,24 @@ func main() {
 		os.Stderr.WriteString(err.Error() + "\n")
 
 		_ = runnerInstance.Close(ctx)
-		runner.Telemetry().Close()
+		closeTelemetry()
+
+		cancel()
+
+		os.Exit(1)
+	}
+
+	if err := runnerInstance.Close(ctx); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+
+		closeTelemetry()
 
 		cancel()
 
 		os.Exit(1)
 	}
 
-	_ = runnerInstance.Close(ctx)
-	runner.Telemetry().Close()
+	closeTelemetry()
 
 	cancel()
 
@@ -78,6 +139,16 @@ func runnerFactory(cfg *runner.Config) (runner.Runner, error) {
 		return lambdaaws.New(cfg)
 	case runner.RunModeAwsLambdaInvoker:
 		return lambdaaws.NewInvoker(cfg)
+	case runner.RunModeReport:
+		return reportrunner.New(cfg)
+	case runner.RunModeConvert:
+		return filerunner.NewConvert(cfg)
+	case runner.RunModeSchedule:
+		return schedulerunner.New(cfg)
+	case runner.RunModeCheckProxies:
+		return proxycheckrunner.New(cfg)
+	case runner.RunModeMonitor:
+		return monitorrunner.New(cfg)
 	default:
 		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
 	}
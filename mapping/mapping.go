@@ -0,0 +1,299 @@
+// Package mapping converts the entries to a user defined output schema.
+//
+// A schema is a YAML file listing the output columns in order:
+//
+//	columns:
+//	  - name: business_name
+//	    field: title
+//	  - name: location
+//	    fields: [complete_address.city, complete_address.country]
+//	    separator: ", "
+//	  - name: rating
+//	    field: review_rating
+//	    format: "%.1f"
+//	  - name: phone
+//	    field: phone
+//	    default: "n/a"
+//	  - name: source
+//	    value: google maps
+//
+// Fields are referenced by their json name, nested values with dots
+// (e.g. complete_address.city or images.0.image).
+package mapping
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Column describes one column of the output
+type Column struct {
+	Name string `yaml:"name"`
+	// Field is the entry field used for the column
+	Field string `yaml:"field"`
+	// Fields are concatenated using Separator
+	Fields    []string `yaml:"fields"`
+	Separator string   `yaml:"separator"`
+	// Format is a fmt format applied to every field value
+	Format string `yaml:"format"`
+	// Default is used when the value is empty
+	Default string `yaml:"default"`
+	// Value is a constant value
+	Value string `yaml:"value"`
+
+	paths [][]string
+}
+
+// Schema is the output schema
+type Schema struct {
+	Columns []Column `yaml:"columns"`
+
+	headers []string
+}
+
+// Load reads and validates the schema at path
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses and validates a YAML schema
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid mapping: %w", err)
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid mapping: %w", err)
+	}
+
+	return &s, nil
+}
+
+func (s *Schema) validate() error {
+	if len(s.Columns) == 0 {
+		return errors.New("no columns defined")
+	}
+
+	seen := make(map[string]bool, len(s.Columns))
+	entryType := reflect.TypeOf(gmaps.Entry{})
+
+	for i := range s.Columns {
+		col := &s.Columns[i]
+
+		if col.Name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+
+		if seen[col.Name] {
+			return fmt.Errorf("duplicate column %s", col.Name)
+		}
+
+		seen[col.Name] = true
+
+		fields := col.Fields
+		if col.Field != "" {
+			fields = append([]string{col.Field}, fields...)
+		}
+
+		if len(fields) == 0 && col.Value == "" {
+			return fmt.Errorf("column %s needs field, fields or value", col.Name)
+		}
+
+		col.paths = make([][]string, 0, len(fields))
+
+		for _, f := range fields {
+			path := strings.Split(f, ".")
+			if err := checkPath(entryType, path); err != nil {
+				return fmt.Errorf("column %s: field %s: %w", col.Name, f, err)
+			}
+
+			col.paths = append(col.paths, path)
+		}
+
+		s.headers = append(s.headers, col.Name)
+	}
+
+	return nil
+}
+
+// Apply converts the entry to a Record
+func (s *Schema) Apply(entry *gmaps.Entry) *Record {
+	values := make([]string, len(s.Columns))
+
+	for i := range s.Columns {
+		values[i] = s.Columns[i].value(entry)
+	}
+
+	return &Record{headers: s.headers, values: values}
+}
+
+func (c *Column) value(entry *gmaps.Entry) string {
+	if len(c.paths) == 0 {
+		return c.Value
+	}
+
+	parts := make([]string, 0, len(c.paths))
+
+	for _, path := range c.paths {
+		v, ok := lookup(reflect.ValueOf(entry).Elem(), path)
+		if !ok {
+			continue
+		}
+
+		var s string
+		if c.Format != "" {
+			s = fmt.Sprintf(c.Format, v.Interface())
+		} else {
+			s = toString(v)
+		}
+
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	ans := strings.Join(parts, c.Separator)
+	if ans == "" {
+		return c.Default
+	}
+
+	return ans
+}
+
+// checkPath reports an error if path does not exist in t
+func checkPath(t reflect.Type, path []string) error {
+	for _, p := range path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() { //nolint:exhaustive // the other kinds cannot be traversed
+		case reflect.Struct:
+			idx, ok := fieldIndex(t, p)
+			if !ok {
+				return fmt.Errorf("unknown field %s", p)
+			}
+
+			t = t.Field(idx).Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Slice:
+			if _, err := strconv.Atoi(p); err != nil {
+				return fmt.Errorf("%s is not a list index", p)
+			}
+
+			t = t.Elem()
+		default:
+			return fmt.Errorf("cannot select %s from %s", p, t)
+		}
+	}
+
+	return nil
+}
+
+func lookup(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, p := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, false
+			}
+
+			v = v.Elem()
+		}
+
+		switch v.Kind() { //nolint:exhaustive // validated in checkPath
+		case reflect.Struct:
+			idx, _ := fieldIndex(v.Type(), p)
+			v = v.Field(idx)
+		case reflect.Map:
+			key := reflect.New(v.Type().Key()).Elem()
+
+			switch key.Kind() { //nolint:exhaustive // the entry only has string and int keys
+			case reflect.String:
+				key.SetString(p)
+			case reflect.Int:
+				n, err := strconv.Atoi(p)
+				if err != nil {
+					return v, false
+				}
+
+				key.SetInt(int64(n))
+			default:
+				return v, false
+			}
+
+			v = v.MapIndex(key)
+			if !v.IsValid() {
+				return v, false
+			}
+		case reflect.Slice:
+			n, _ := strconv.Atoi(p)
+			if n < 0 || n >= v.Len() {
+				return v, false
+			}
+
+			v = v.Index(n)
+		default:
+			return v, false
+		}
+	}
+
+	return v, true
+}
+
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := range t.NumField() {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func toString(v reflect.Value) string {
+	switch v.Kind() { //nolint:exhaustive // the rest is encoded as json
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return ""
+		}
+
+		if v.Type().Elem().Kind() == reflect.String {
+			return strings.Join(v.Interface().([]string), ", ")
+		}
+	}
+
+	if v.IsZero() {
+		return ""
+	}
+
+	return stringify(v.Interface())
+}
@@ -0,0 +1,119 @@
+package mapping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.CsvCapable = (*Record)(nil)
+
+// Record is an entry converted to the schema
+type Record struct {
+	headers []string
+	values  []string
+}
+
+// CsvHeaders returns the column names
+func (r *Record) CsvHeaders() []string {
+	return r.headers
+}
+
+// CsvRow returns the values of the columns
+func (r *Record) CsvRow() []string {
+	return r.values
+}
+
+// MarshalJSON encodes the record as an object keeping the order of the columns
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i := range r.headers {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(r.headers[i])
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// Wrap returns a writer that converts the entries to records
+// before passing them to next.
+func (s *Schema) Wrap(next scrapemate.ResultWriter) scrapemate.ResultWriter {
+	return &writer{schema: s, next: next}
+}
+
+type writer struct {
+	schema *Schema
+	next   scrapemate.ResultWriter
+}
+
+func (w *writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.next.Run(ctx, out)
+	}()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			result.Data = w.schema.Apply(data)
+		case []*gmaps.Entry:
+			records := make([]*Record, len(data))
+			for i := range data {
+				records[i] = w.schema.Apply(data[i])
+			}
+
+			result.Data = records
+		}
+
+		select {
+		case out <- result:
+		case err := <-done:
+			// keep consuming so that the producer does not block
+			go func() {
+				for range in {
+				}
+			}()
+
+			return err
+		}
+	}
+
+	close(out)
+
+	return <-done
+}
+
+func stringify(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
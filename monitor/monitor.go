@@ -0,0 +1,209 @@
+// Package monitor tracks the fields of a fixed list of places between the
+// checks of a monitoring run and reports their changes as events.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/report"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// EventChanged is the type of the events of the places whose tracked fields
+// changed
+const EventChanged = "place.changed"
+
+// The fields that can be tracked
+const (
+	FieldRating  = "rating"
+	FieldReviews = "reviews"
+	FieldStatus  = "status"
+	FieldPhone   = "phone"
+)
+
+// Fields are the fields that can be tracked, all of them are by default
+var Fields = []string{FieldRating, FieldReviews, FieldStatus, FieldPhone}
+
+// ParseFields parses a comma separated list of fields
+func ParseFields(s string) ([]string, error) {
+	var ans []string
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || slices.Contains(ans, name) {
+			continue
+		}
+
+		if !slices.Contains(Fields, name) {
+			return nil, fmt.Errorf("unknown field %q, the fields are %s", name, strings.Join(Fields, ", "))
+		}
+
+		ans = append(ans, name)
+	}
+
+	if len(ans) == 0 {
+		return nil, errors.New("no fields")
+	}
+
+	return ans, nil
+}
+
+// Place are the tracked values of a place at its last check
+type Place struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	Rating    float64   `json:"rating"`
+	Reviews   int       `json:"reviews"`
+	Status    string    `json:"status"`
+	Phone     string    `json:"phone"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// PlaceOf returns the tracked values of the entry checked at now
+func PlaceOf(e *gmaps.Entry, now time.Time) Place {
+	return Place{
+		ID:        e.Key(),
+		Title:     e.Title,
+		Link:      e.Link,
+		Rating:    e.ReviewRating,
+		Reviews:   e.ReviewCount,
+		Status:    e.Status,
+		Phone:     e.Phone,
+		CheckedAt: now.UTC(),
+	}
+}
+
+func (p *Place) value(field string) string {
+	switch field {
+	case FieldRating:
+		return strconv.FormatFloat(p.Rating, 'f', -1, 64)
+	case FieldReviews:
+		return strconv.Itoa(p.Reviews)
+	case FieldStatus:
+		return strings.TrimSpace(p.Status)
+	case FieldPhone:
+		return strings.TrimSpace(p.Phone)
+	default:
+		return ""
+	}
+}
+
+// Event is the change of the tracked fields of a place between two checks
+type Event struct {
+	Type      string                        `json:"type"`
+	ID        string                        `json:"id"`
+	Title     string                        `json:"title"`
+	Link      string                        `json:"link"`
+	CheckedAt time.Time                     `json:"checked_at"`
+	Changes   map[string]report.FieldChange `json:"changes"`
+}
+
+// Tracker has the last values of the places, the state of the monitoring
+type Tracker struct {
+	fields []string
+	places map[string]Place
+}
+
+// NewTracker returns a tracker of the fields, without places
+func NewTracker(fields []string) *Tracker {
+	return &Tracker{fields: fields, places: make(map[string]Place)}
+}
+
+// Update records the values of p and returns the event of the changes of its
+// tracked fields since its previous check. The first check of a place is its
+// baseline, it has no event.
+func (t *Tracker) Update(p Place) (Event, bool) {
+	prev, known := t.places[p.ID]
+
+	t.places[p.ID] = p
+
+	if !known {
+		return Event{}, false
+	}
+
+	changes := make(map[string]report.FieldChange)
+
+	for _, field := range t.fields {
+		if o, n := prev.value(field), p.value(field); o != n {
+			changes[field] = report.FieldChange{Old: o, New: n}
+		}
+	}
+
+	if len(changes) == 0 {
+		return Event{}, false
+	}
+
+	return Event{
+		Type:      EventChanged,
+		ID:        p.ID,
+		Title:     p.Title,
+		Link:      p.Link,
+		CheckedAt: p.CheckedAt,
+		Changes:   changes,
+	}, true
+}
+
+// Len is the number of the places checked once at least
+func (t *Tracker) Len() int {
+	return len(t.places)
+}
+
+// Load reads the places saved by Save under key, a missing key is an empty
+// state
+func (t *Tracker) Load(ctx context.Context, store state.Store, key string) error {
+	b, err := store.Get(ctx, key)
+	if errors.Is(err, state.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot read the monitor state: %w", err)
+	}
+
+	var places []Place
+
+	if err := json.Unmarshal(b, &places); err != nil {
+		return fmt.Errorf("invalid monitor state %s: %w", key, err)
+	}
+
+	for i := range places {
+		t.places[places[i].ID] = places[i]
+	}
+
+	return nil
+}
+
+// Save writes the places under key, an interrupted save keeps the previous
+// state
+func (t *Tracker) Save(ctx context.Context, store state.Store, key string) error {
+	places := make([]Place, 0, len(t.places))
+
+	for _, p := range t.places {
+		places = append(places, p)
+	}
+
+	sort.Slice(places, func(i, j int) bool {
+		return places[i].ID < places[j].ID
+	})
+
+	b, err := json.MarshalIndent(places, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := store.Put(ctx, key, b); err != nil {
+		return fmt.Errorf("cannot save the monitor state: %w", err)
+	}
+
+	return nil
+}
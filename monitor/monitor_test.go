@@ -0,0 +1,67 @@
+package monitor_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/monitor"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+func Test_TrackerState(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		open func(dir string) (state.Store, error)
+	}{
+		{
+			name: "fs",
+			open: state.NewFS,
+		},
+		{
+			name: "sqlite",
+			open: func(dir string) (state.Store, error) {
+				return state.NewSQLite(filepath.Join(dir, "state.db"))
+			},
+		},
+	}
+
+	checked := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := tc.open(t.TempDir())
+			require.NoError(t, err)
+
+			defer store.Close()
+
+			// a missing key is an empty state
+			tracker := monitor.NewTracker(monitor.Fields)
+			require.NoError(t, tracker.Load(ctx, store, "monitor.json"))
+			require.Zero(t, tracker.Len())
+
+			_, changed := tracker.Update(monitor.Place{ID: "a", Rating: 4.5, Reviews: 10, CheckedAt: checked})
+			require.False(t, changed)
+
+			require.NoError(t, tracker.Save(ctx, store, "monitor.json"))
+
+			// a restarted monitor reports the changes since the saved check
+			restarted := monitor.NewTracker(monitor.Fields)
+			require.NoError(t, restarted.Load(ctx, store, "monitor.json"))
+			require.Equal(t, 1, restarted.Len())
+
+			ev, changed := restarted.Update(monitor.Place{ID: "a", Rating: 4.5, Reviews: 12, CheckedAt: checked})
+			require.True(t, changed)
+			require.Equal(t, "10", ev.Changes[monitor.FieldReviews].Old)
+			require.Equal(t, "12", ev.Changes[monitor.FieldReviews].New)
+
+			require.NoError(t, store.Put(ctx, "invalid.json", []byte("{")))
+			require.Error(t, monitor.NewTracker(monitor.Fields).Load(ctx, store, "invalid.json"))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package nats publishes the entries to a NATS JetStream stream.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	// DefaultSubject is the subject the places are published to
+	DefaultSubject = "gmaps.places"
+	// InputID is replaced in the subject by the id of the query of the place
+	InputID = "{input_id}"
+	// noInputID is the subject token of the places of the queries without id
+	noInputID = "none"
+	// maxPending is the number of messages waiting for their ack
+	maxPending = 256
+	// closeTimeout is how long the pending messages wait for their acks on shutdown
+	closeTimeout = 30 * time.Second
+)
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer publishes every entry as a JSON message to a subject of JetStream,
+// with its cid as the message id so that the stream drops the duplicates of
+// its duplicate window. The messages are published asynchronously, the failed
+// acks are counted and logged and the pending acks are waited for when the
+// results end.
+type Writer struct {
+	conn    *natsgo.Conn
+	js      jetstream.JetStream
+	subject string
+	stream  string
+
+	published atomic.Int64
+	failed    atomic.Int64
+}
+
+// WriterOption configures the writer
+type WriterOption func(*Writer)
+
+// WithSubject sets the subject the places are published to, DefaultSubject by
+// default. InputID in the subject is replaced by the id of the query, which
+// gives a subject per query.
+func WithSubject(subject string) WriterOption {
+	return func(w *Writer) {
+		w.subject = subject
+	}
+}
+
+// WithStream creates the stream of the subjects when it does not exist
+func WithStream(name string) WriterOption {
+	return func(w *Writer) {
+		w.stream = name
+	}
+}
+
+// NewWriter returns the writer of the NATS server at url, e.g. nats://localhost:4222
+func NewWriter(ctx context.Context, url string, opts ...WriterOption) (*Writer, error) {
+	ans := Writer{subject: DefaultSubject}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	conn, err := natsgo.Connect(url, natsgo.Name("google-maps-scraper"))
+	if err != nil {
+		return nil, fmt.Errorf("nats: cannot connect to %s: %w", url, err)
+	}
+
+	ans.conn = conn
+
+	ans.js, err = jetstream.New(conn,
+		jetstream.WithPublishAsyncMaxPending(maxPending),
+		jetstream.WithPublishAsyncErrHandler(ans.report),
+	)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+
+	if err := ans.ensureStream(ctx); err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	return &ans, nil
+}
+
+// ensureStream creates the stream of WithStream, an existing stream is kept as it is
+func (w *Writer) ensureStream(ctx context.Context) error {
+	if w.stream == "" {
+		return nil
+	}
+
+	cfg := jetstream.StreamConfig{
+		Name:     w.stream,
+		Subjects: []string{strings.ReplaceAll(w.subject, InputID, "*")},
+	}
+
+	_, err := w.js.CreateStream(ctx, cfg)
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("nats: cannot create the stream %s: %w", w.stream, err)
+	}
+
+	return nil
+}
+
+// report is called for the messages that were not acknowledged
+func (w *Writer) report(_ jetstream.JetStream, msg *natsgo.Msg, err error) {
+	w.failed.Add(1)
+
+	slog.Warn("nats: place not published", "place", msg.Header.Get(natsgo.MsgIdHdr), "subject", msg.Subject, "error", err)
+}
+
+// subjectOf returns the subject of the entry, the id of its query is a single
+// token of the subject
+func (w *Writer) subjectOf(e *gmaps.Entry) string {
+	if !strings.Contains(w.subject, InputID) {
+		return w.subject
+	}
+
+	token := strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+
+		return r
+	}, e.ID)
+
+	if token == "" {
+		token = noInputID
+	}
+
+	return strings.ReplaceAll(w.subject, InputID, token)
+}
+
+func (w *Writer) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	defer w.conn.Close()
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{data}
+		case []*gmaps.Entry:
+			entries = data
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for _, e := range entries {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			msg := natsgo.Msg{Subject: w.subjectOf(e), Data: data}
+
+			// it blocks while maxPending messages wait for their ack
+			if _, err := w.js.PublishMsgAsync(&msg, jetstream.WithMsgID(e.Key())); err != nil {
+				return fmt.Errorf("nats: %w", err)
+			}
+
+			w.published.Add(1)
+		}
+	}
+
+	return w.close()
+}
+
+// close waits for the acks of the pending messages
+func (w *Writer) close() error {
+	select {
+	case <-w.js.PublishAsyncComplete():
+	case <-time.After(closeTimeout):
+		return fmt.Errorf("nats: %d places not acknowledged in time", w.js.PublishAsyncPending())
+	}
+
+	published, failed := w.published.Load(), w.failed.Load()
+
+	slog.Info("nats: places published", "published", published-failed, "failed", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("nats: %d places not published", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package nats_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/nats"
+)
+
+// message is a message published to the fake server
+type message struct {
+	subject string
+	id      string
+	title   string
+}
+
+// server is a fake NATS server with JetStream: it speaks the text protocol
+// of the clients, acknowledges the published messages and creates the streams
+type server struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []message
+	streams  map[string][]string
+	// nack fails the acks of the published messages
+	nack bool
+	// createErr is the error code of the creation of the streams
+	createErr int
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := server{ln: ln, streams: make(map[string][]string)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	return &s
+}
+
+func (s *server) url() string {
+	return "nats://" + s.ln.Addr().String()
+}
+
+func (s *server) published() []message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]message(nil), s.messages...)
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	subs := make(map[string]string)
+
+	var wmu sync.Mutex
+
+	write := func(format string, args ...any) {
+		wmu.Lock()
+		defer wmu.Unlock()
+
+		_, _ = fmt.Fprintf(conn, format, args...)
+	}
+
+	write("INFO {\"server_id\":\"fake\",\"version\":\"2.11.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576,\"jetstream\":true}\r\n")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			write("PONG\r\n")
+		case "SUB":
+			subs[fields[len(fields)-1]] = fields[1]
+		case "PUB", "HPUB":
+			var header textproto.MIMEHeader
+
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+
+			payload = payload[:size]
+
+			if fields[0] == "HPUB" {
+				hdrSize, _ := strconv.Atoi(fields[len(fields)-2])
+				// the headers follow the NATS/1.0 version line
+				tp := textproto.NewReader(bufio.NewReader(strings.NewReader(string(payload[:hdrSize]))))
+				_, _ = tp.ReadLine()
+				header, _ = tp.ReadMIMEHeader()
+				payload = payload[hdrSize:]
+			}
+
+			reply := s.handle(fields[1], header, payload)
+
+			inbox := fields[2]
+			for sid, subject := range subs {
+				if matches(subject, inbox) {
+					write("MSG %s %s %d\r\n%s\r\n", inbox, sid, len(reply), reply)
+				}
+			}
+		}
+	}
+}
+
+// handle returns the reply of JetStream to a message
+func (s *server) handle(subject string, header textproto.MIMEHeader, payload []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apiErr := func(code int) []byte {
+		return fmt.Appendf(nil, `{"error":{"code":400,"err_code":%d,"description":"failed"}}`, code)
+	}
+
+	if name, ok := strings.CutPrefix(subject, "$JS.API.STREAM.CREATE."); ok {
+		if s.createErr != 0 {
+			return apiErr(s.createErr)
+		}
+
+		var cfg struct {
+			Subjects []string `json:"subjects"`
+		}
+
+		_ = json.Unmarshal(payload, &cfg)
+		s.streams[name] = cfg.Subjects
+
+		return fmt.Appendf(nil, `{"type":"io.nats.jetstream.api.v1.stream_create_response","config":{"name":%q,"subjects":%q},"state":{}}`,
+			name, cfg.Subjects)
+	}
+
+	if s.nack {
+		return apiErr(10077)
+	}
+
+	var e gmaps.Entry
+
+	_ = json.Unmarshal(payload, &e)
+
+	s.messages = append(s.messages, message{subject: subject, id: header.Get("Nats-Msg-Id"), title: e.Title})
+
+	return fmt.Appendf(nil, `{"stream":"PLACES","seq":%d}`, len(s.messages))
+}
+
+// matches reports whether the subject matches the subject of a subscription
+func matches(pattern, subject string) bool {
+	p, s := strings.Split(pattern, "."), strings.Split(subject, ".")
+
+	for i, token := range p {
+		if token == ">" {
+			return len(s) > i
+		}
+
+		if i >= len(s) || (token != "*" && token != s[i]) {
+			return false
+		}
+	}
+
+	return len(p) == len(s)
+}
+
+func run(w *nats.Writer, results ...scrapemate.Result) error {
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	return w.Run(context.Background(), in)
+}
+
+func Test_Writer(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []nats.WriterOption
+		results  []scrapemate.Result
+		messages []message
+		streams  map[string][]string
+	}{
+		{
+			name: "default subject",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{ID: "q1", Cid: "1", Title: "a"}},
+				{Data: []*gmaps.Entry{{DataID: "0x2", Title: "b"}}},
+				{Data: nil},
+			},
+			messages: []message{
+				{subject: nats.DefaultSubject, id: "1", title: "a"},
+				{subject: nats.DefaultSubject, id: "0x2", title: "b"},
+			},
+			streams: map[string][]string{},
+		},
+		{
+			name: "subject per query",
+			opts: []nats.WriterOption{nats.WithSubject("places." + nats.InputID), nats.WithStream("PLACES")},
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{ID: "cafes in athens", Cid: "1", Title: "a"}},
+				{Data: &gmaps.Entry{ID: "bars.*.>", Cid: "2", Title: "b"}},
+				{Data: &gmaps.Entry{Cid: "3", Title: "c"}},
+			},
+			messages: []message{
+				{subject: "places.cafes_in_athens", id: "1", title: "a"},
+				{subject: "places.bars____", id: "2", title: "b"},
+				{subject: "places.none", id: "3", title: "c"},
+			},
+			streams: map[string][]string{"PLACES": {"places.*"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+
+			w, err := nats.NewWriter(context.Background(), srv.url(), tc.opts...)
+			require.NoError(t, err)
+
+			require.NoError(t, run(w, tc.results...))
+			require.Equal(t, tc.messages, srv.published())
+			require.Equal(t, tc.streams, srv.streams)
+		})
+	}
+}
+
+func Test_WriterExistingStream(t *testing.T) {
+	srv := newServer(t)
+	srv.createErr = 10058
+
+	w, err := nats.NewWriter(context.Background(), srv.url(), nats.WithStream("PLACES"))
+	require.NoError(t, err)
+	require.NoError(t, run(w, scrapemate.Result{Data: &gmaps.Entry{Cid: "1", Title: "a"}}))
+}
+
+func Test_WriterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		nack    bool
+		results []scrapemate.Result
+		err     string
+	}{
+		{
+			name:    "places not acknowledged",
+			nack:    true,
+			results: []scrapemate.Result{{Data: []*gmaps.Entry{{Cid: "1"}, {Cid: "2"}}}},
+			err:     "nats: 2 places not published",
+		},
+		{
+			name:    "invalid data",
+			results: []scrapemate.Result{{Data: "place"}},
+			err:     "invalid data type",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+			srv.nack = tc.nack
+
+			w, err := nats.NewWriter(context.Background(), srv.url())
+			require.NoError(t, err)
+
+			require.EqualError(t, run(w, tc.results...), tc.err)
+		})
+	}
+}
+
+func Test_NewWriterErrors(t *testing.T) {
+	srv := newServer(t)
+	srv.createErr = 10047
+
+	_, err := nats.NewWriter(context.Background(), srv.url(), nats.WithStream("PLACES"))
+	require.ErrorContains(t, err, "cannot create the stream PLACES")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	_, err = nats.NewWriter(context.Background(), "nats://"+ln.Addr().String())
+	require.ErrorContains(t, err, "cannot connect")
+}
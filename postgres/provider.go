@@ -1,16 +1,14 @@
 package postgres
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/gob"
-	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gosom/scrapemate"
 
+	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/gmaps"
 )
 
@@ -29,6 +27,7 @@ type provider struct {
 	errc      chan error
 	started   bool
 	batchSize int
+	deduper   deduper.Deduper
 }
 
 func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
@@ -60,6 +59,14 @@ func WithBatchSize(size int) ProviderOption {
 	}
 }
 
+// WithDeduper sets the deduper of the search jobs fetched from the database.
+// Use a shared deduper (e.g. deduper.NewRedis) when running many workers.
+func WithDeduper(d deduper.Deduper) ProviderOption {
+	return func(p *provider) {
+		p.deduper = d
+	}
+}
+
 //nolint:gocritic // it contains about unnamed results
 func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
 	outc := make(chan scrapemate.IJob)
@@ -110,36 +117,13 @@ func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
 		VALUES
 		($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`
 
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-
-	var payloadType string
-
-	switch j := job.(type) {
-	case *gmaps.GmapJob:
-		payloadType = "search"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	case *gmaps.PlaceJob:
-		payloadType = "place"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	case *gmaps.EmailExtractJob:
-		payloadType = "email"
-
-		if err := enc.Encode(j); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("invalid job type %T", job)
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
 	}
 
-	_, err := p.db.ExecContext(ctx, q,
-		job.GetID(), job.GetPriority(), payloadType, buf.Bytes(), time.Now().UTC(), statusNew,
+	_, err = p.db.ExecContext(ctx, q,
+		job.GetID(), job.GetPriority(), payloadType, payload, time.Now().UTC(), statusNew,
 	)
 
 	return err
@@ -197,13 +181,18 @@ func (p *provider) fetchJobs(ctx context.Context) {
 				return
 			}
 
-			job, err := decodeJob(payloadType, payload)
+			job, err := gmaps.DecodeJob(payloadType, payload)
 			if err != nil {
 				p.errc <- err
 
 				return
 			}
 
+			if p.deduper != nil {
+				rt := gmaps.Runtime{Deduper: p.deduper}
+				rt.Attach(job)
+			}
+
 			jobs = append(jobs, job)
 		}
 
@@ -242,39 +231,3 @@ func (p *provider) fetchJobs(ctx context.Context) {
 		}
 	}
 }
-
-type encjob struct {
-	Type string
-	Data scrapemate.IJob
-}
-
-func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
-	buf := bytes.NewBuffer(payload)
-	dec := gob.NewDecoder(buf)
-
-	switch payloadType {
-	case "search":
-		j := new(gmaps.GmapJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode search job: %w", err)
-		}
-
-		return j, nil
-	case "place":
-		j := new(gmaps.PlaceJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode place job: %w", err)
-		}
-
-		return j, nil
-	case "email":
-		j := new(gmaps.EmailExtractJob)
-		if err := dec.Decode(j); err != nil {
-			return nil, fmt.Errorf("failed to decode email job: %w", err)
-		}
-
-		return j, nil
-	default:
-		return nil, fmt.Errorf("invalid payload type: %s", payloadType)
-	}
-}
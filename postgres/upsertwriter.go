@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// column is a column of the entries table and its value for an entry
+type column struct {
+	name  string
+	typ   string
+	value func(*gmaps.Entry) any
+}
+
+// entryColumns are the columns of the entries table besides the id. The table
+// is migrated on start: the missing columns are added, so new columns only
+// need to be appended here.
+var entryColumns = []column{
+	{"cid", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Cid }},
+	{"data_id", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.DataID }},
+	{"link", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Link }},
+	{"title", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Title }},
+	{"category", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Category }},
+	{"address", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Address }},
+	{"website", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.WebSite }},
+	{"phone", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Phone }},
+	{"review_count", "INT NOT NULL DEFAULT 0", func(e *gmaps.Entry) any { return e.ReviewCount }},
+	{"review_rating", "DOUBLE PRECISION NOT NULL DEFAULT 0", func(e *gmaps.Entry) any { return e.ReviewRating }},
+	{"latitude", "DOUBLE PRECISION NOT NULL DEFAULT 0", func(e *gmaps.Entry) any { return e.Latitude }},
+	{"longitude", "DOUBLE PRECISION NOT NULL DEFAULT 0", func(e *gmaps.Entry) any { return e.Longtitude }},
+	{"kgmid", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.Kgmid }},
+	{"global_plus_code", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.GlobalPlusCode }},
+	{"distance_m", "DOUBLE PRECISION NOT NULL DEFAULT 0", func(e *gmaps.Entry) any { return e.DistanceM }},
+	{"canonical_category", "TEXT NOT NULL DEFAULT ''", func(e *gmaps.Entry) any { return e.CanonicalCategory }},
+	{"data", "JSONB NOT NULL DEFAULT '{}'", func(e *gmaps.Entry) any {
+		data, _ := json.Marshal(e)
+		return data
+	}},
+}
+
+// NewUpsertWriter returns a writer that upserts the entries into the entries
+// table by their cid, or their data id when the cid is missing. The entries of
+// a place scraped again replace the previous ones, so repeated runs do not
+// duplicate the places. The table is created or migrated first.
+func NewUpsertWriter(ctx context.Context, db *sql.DB) (scrapemate.ResultWriter, error) {
+	if err := migrateEntries(ctx, db); err != nil {
+		return nil, fmt.Errorf("cannot migrate the entries table: %w", err)
+	}
+
+	return &upsertWriter{db: db}, nil
+}
+
+type upsertWriter struct {
+	db *sql.DB
+}
+
+func migrateEntries(ctx context.Context, db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS entries (id TEXT PRIMARY KEY)`,
+	}
+
+	for _, c := range entryColumns {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE entries ADD COLUMN IF NOT EXISTS %s %s`, c.name, c.typ))
+	}
+
+	stmts = append(stmts,
+		`ALTER TABLE entries ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()`,
+		`ALTER TABLE entries ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()`,
+	)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, q := range stmts {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (u *upsertWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	const maxBatchSize = 50
+
+	buff := make([]*gmaps.Entry, 0, maxBatchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= maxBatchSize || time.Now().UTC().Sub(lastSave) >= time.Minute {
+			if err := u.batchSave(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	return u.batchSave(ctx, buff)
+}
+
+func (u *upsertWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) error {
+	// a statement cannot update the same row twice, the last entry of a place wins
+	byID := make(map[string]*gmaps.Entry, len(entries))
+	ids := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		id := e.Key()
+		if id == "" {
+			continue
+		}
+
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+
+		byID[id] = e
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(entryColumns)+1)
+	updates := make([]string, 0, len(entryColumns)+1)
+
+	names = append(names, "id")
+
+	for _, c := range entryColumns {
+		names = append(names, c.name)
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", c.name, c.name))
+	}
+
+	updates = append(updates, "updated_at = now()")
+
+	rows := make([]string, 0, len(ids))
+	args := make([]any, 0, len(ids)*len(names))
+
+	for _, id := range ids {
+		e := byID[id]
+
+		placeholders := make([]string, 0, len(names))
+
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+
+		for _, c := range entryColumns {
+			args = append(args, c.value(e))
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+
+		rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	q := `INSERT INTO entries (` + strings.Join(names, ", ") + `) VALUES ` +
+		strings.Join(rows, ", ") +
+		` ON CONFLICT (id) DO UPDATE SET ` + strings.Join(updates, ", ")
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,305 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/postgres"
+)
+
+var errDB = errors.New("connection refused")
+
+var insertRegex = regexp.MustCompile(`^INSERT INTO entries \(([^)]*)\) VALUES .* ON CONFLICT \(id\) DO UPDATE SET`)
+
+// database is a fake database/sql driver keeping the rows of the entries
+// table by id, with the semantics of the upserts of postgres
+type database struct {
+	mu sync.Mutex
+	// rows are the titles of the entries by id
+	rows map[string]string
+	// inserts are the number of rows of every committed insert
+	inserts []int
+	// fail is the statement prefix whose execution fails
+	fail string
+}
+
+func newDatabase() *database {
+	return &database{rows: make(map[string]string)}
+}
+
+func (d *database) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: d}, nil
+}
+
+func (d *database) Driver() driver.Driver {
+	return nil
+}
+
+// titles returns the titles of the rows by id
+func (d *database) titles() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ans := make(map[string]string, len(d.rows))
+	for k, v := range d.rows {
+		ans[k] = v
+	}
+
+	return ans
+}
+
+type conn struct {
+	db *database
+	tx *tx
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.tx = &tx{conn: c, rows: make(map[string]string)}
+
+	return c.tx, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.db.fail != "" && strings.HasPrefix(query, c.db.fail) {
+		return nil, errDB
+	}
+
+	m := insertRegex.FindStringSubmatch(query)
+	if m == nil {
+		return driver.RowsAffected(0), nil
+	}
+
+	names := strings.Split(m[1], ", ")
+	title := 0
+
+	for i, name := range names {
+		if name == "title" {
+			title = i
+		}
+	}
+
+	rows := make(map[string]string)
+
+	for i := 0; i+len(names) <= len(args); i += len(names) {
+		id := args[i].Value.(string)
+		if _, ok := rows[id]; ok {
+			return nil, errors.New("ON CONFLICT DO UPDATE command cannot affect row a second time")
+		}
+
+		rows[id] = args[i+title].Value.(string)
+	}
+
+	for id, t := range rows {
+		c.tx.rows[id] = t
+	}
+
+	c.tx.inserts = append(c.tx.inserts, len(rows))
+
+	return driver.RowsAffected(len(rows)), nil
+}
+
+type tx struct {
+	conn    *conn
+	rows    map[string]string
+	inserts []int
+}
+
+func (t *tx) Commit() error {
+	db := t.conn.db
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.fail == "COMMIT" {
+		return errDB
+	}
+
+	for id, title := range t.rows {
+		db.rows[id] = title
+	}
+
+	db.inserts = append(db.inserts, t.inserts...)
+
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	return nil
+}
+
+// entries returns n entries with the ids prefix0..prefixN
+func entries(prefix string, n int) []*gmaps.Entry {
+	ans := make([]*gmaps.Entry, 0, n)
+	for i := range n {
+		ans = append(ans, &gmaps.Entry{Cid: fmt.Sprintf("%s%d", prefix, i), Title: "new"})
+	}
+
+	return ans
+}
+
+func Test_UpsertWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    map[string]string
+		results []scrapemate.Result
+		want    map[string]string
+		inserts []int
+	}{
+		{
+			name: "new places",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Cid: "1", Title: "a"}},
+				{Data: []*gmaps.Entry{{Cid: "2", Title: "b"}}},
+			},
+			want:    map[string]string{"1": "a", "2": "b"},
+			inserts: []int{2},
+		},
+		{
+			name: "place scraped again",
+			rows: map[string]string{"1": "old", "2": "b"},
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Cid: "1", Title: "a"}},
+			},
+			want:    map[string]string{"1": "a", "2": "b"},
+			inserts: []int{1},
+		},
+		{
+			name: "same place twice in a batch",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Cid: "1", Title: "first"}},
+				{Data: &gmaps.Entry{Cid: "1", Title: "last"}},
+			},
+			want:    map[string]string{"1": "last"},
+			inserts: []int{1},
+		},
+		{
+			name: "key of the place",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{DataID: "0x1:0x2", Title: "by data id"}},
+				{Data: &gmaps.Entry{Link: "https://maps.google.com/x", Title: "by link"}},
+				{Data: &gmaps.Entry{Title: "without key"}},
+			},
+			want:    map[string]string{"0x1:0x2": "by data id", "https://maps.google.com/x": "by link"},
+			inserts: []int{2},
+		},
+		{
+			name:    "batches",
+			results: []scrapemate.Result{{Data: entries("a", 60)}, {Data: entries("b", 30)}},
+			inserts: []int{60, 30},
+		},
+		{
+			name:    "results without places",
+			results: []scrapemate.Result{{Data: nil}},
+			want:    map[string]string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newDatabase()
+			for k, v := range tc.rows {
+				fake.rows[k] = v
+			}
+
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			w, err := postgres.NewUpsertWriter(context.Background(), db)
+			require.NoError(t, err)
+
+			in := make(chan scrapemate.Result, len(tc.results))
+			for _, r := range tc.results {
+				in <- r
+			}
+
+			close(in)
+
+			require.NoError(t, w.Run(context.Background(), in))
+			require.Equal(t, tc.inserts, fake.inserts)
+
+			if tc.want != nil {
+				require.Equal(t, tc.want, fake.titles())
+			}
+		})
+	}
+}
+
+func Test_UpsertWriterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    string
+		results []scrapemate.Result
+	}{
+		{
+			name:    "insert fails",
+			fail:    "INSERT",
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Cid: "1", Title: "a"}}},
+		},
+		{
+			name:    "commit fails",
+			fail:    "COMMIT",
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Cid: "1", Title: "a"}}},
+		},
+		{
+			name:    "invalid data",
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Cid: "1", Title: "a"}}, {Data: "place"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newDatabase()
+
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			w, err := postgres.NewUpsertWriter(context.Background(), db)
+			require.NoError(t, err)
+
+			fake.fail = tc.fail
+
+			in := make(chan scrapemate.Result, len(tc.results))
+			for _, r := range tc.results {
+				in <- r
+			}
+
+			close(in)
+
+			require.Error(t, w.Run(context.Background(), in))
+			require.Empty(t, fake.titles())
+		})
+	}
+}
+
+func Test_UpsertWriterMigration(t *testing.T) {
+	fake := newDatabase()
+	fake.fail = "ALTER TABLE entries ADD COLUMN IF NOT EXISTS data "
+
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	_, err := postgres.NewUpsertWriter(context.Background(), db)
+	require.ErrorIs(t, err, errDB)
+}
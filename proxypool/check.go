@@ -0,0 +1,159 @@
+package proxypool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// DefaultIPInfoURL is the service returning the exit IP and its country of
+// the requests, as the JSON fields ip and country
+const DefaultIPInfoURL = "https://ipinfo.io/json"
+
+// CheckConfig configures Check
+type CheckConfig struct {
+	// URL is the Maps page requested through the proxies, DefaultCheckURL
+	// when empty
+	URL string
+	// IPInfoURL is the service of the exit IPs, DefaultIPInfoURL when empty
+	IPInfoURL string
+	// Concurrency is the number of the proxies checked at the same time
+	Concurrency int
+	// Timeout is the timeout of each request, checkTimeout when 0
+	Timeout time.Duration
+}
+
+// CheckResult is the check of a proxy
+type CheckResult struct {
+	// Proxy is the url of the proxy without its credentials
+	Proxy string `json:"proxy"`
+	// Status is ok, or why Google blocks the proxy, see Blocked
+	Status     string `json:"status"`
+	StatusCode int    `json:"status_code"`
+	LatencyMS  int64  `json:"latency_ms"`
+	ExitIP     string `json:"exit_ip"`
+	Country    string `json:"country"`
+	Error      string `json:"error"`
+}
+
+// OK reports whether the proxy got the Maps page
+func (r *CheckResult) OK() bool {
+	return r.Status == "ok"
+}
+
+// Check requests the Maps page of cfg.URL and the exit IP of cfg.IPInfoURL
+// through every proxy, the proxies are checked once each. The results are in
+// the order of the proxies.
+func Check(ctx context.Context, proxies []string, newFetcher FetcherFunc, cfg CheckConfig) []CheckResult {
+	if cfg.URL == "" {
+		cfg.URL = DefaultCheckURL
+	}
+
+	if cfg.IPInfoURL == "" {
+		cfg.IPInfoURL = DefaultIPInfoURL
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = checkTimeout
+	}
+
+	ans := make([]CheckResult, len(proxies))
+	sem := make(chan struct{}, max(cfg.Concurrency, 1))
+
+	var wg sync.WaitGroup
+
+	for i, proxy := range proxies {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				ans[i] = CheckResult{Proxy: proxy, Status: "error", Error: ctx.Err().Error()}
+
+				return
+			}
+
+			defer func() { <-sem }()
+
+			ans[i] = checkProxy(ctx, proxy, newFetcher, &cfg)
+		}()
+	}
+
+	wg.Wait()
+
+	return ans
+}
+
+func checkProxy(ctx context.Context, proxy string, newFetcher FetcherFunc, cfg *CheckConfig) CheckResult {
+	ans := CheckResult{Proxy: proxy, Status: "error"}
+
+	parsed, err := scrapemate.NewProxy(proxy)
+	if err != nil {
+		ans.Error = err.Error()
+
+		return ans
+	}
+
+	ans.Proxy = parsed.URL
+
+	f, err := newFetcher(proxy)
+	if err != nil {
+		ans.Error = err.Error()
+
+		return ans
+	}
+
+	defer f.Close()
+
+	fetch := func(u string) scrapemate.Response {
+		cctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+
+		return f.Fetch(cctx, &scrapemate.Job{
+			ID:      "proxy-check",
+			Method:  http.MethodGet,
+			URL:     u,
+			Timeout: cfg.Timeout,
+		})
+	}
+
+	start := time.Now()
+	resp := fetch(cfg.URL)
+
+	ans.LatencyMS = time.Since(start).Milliseconds()
+	ans.StatusCode = resp.StatusCode
+
+	if resp.Error != nil {
+		ans.Error = resp.Error.Error()
+	}
+
+	if reason := Blocked(&resp); reason != "" {
+		ans.Status = reason
+	} else {
+		ans.Status = "ok"
+	}
+
+	// the exit IP of a failing proxy tells which one of its IPs to replace
+	info := fetch(cfg.IPInfoURL)
+	if info.Error != nil || info.StatusCode != http.StatusOK {
+		return ans
+	}
+
+	var ip struct {
+		IP      string `json:"ip"`
+		Country string `json:"country"`
+	}
+
+	if json.Unmarshal(info.Body, &ip) == nil {
+		ans.ExitIP, ans.Country = ip.IP, ip.Country
+	}
+
+	return ans
+}
@@ -0,0 +1,79 @@
+package proxypool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Load reads the proxies of src, a file or a http(s) URL with one proxy per
+// line. The empty lines and the lines starting with # are skipped.
+func Load(ctx context.Context, src string) ([]string, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		data, err = download(ctx, src)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the proxies of %s: %w", src, err)
+	}
+
+	var ans []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ans = append(ans, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot load the proxies of %s: %w", src, err)
+	}
+
+	if len(ans) == 0 {
+		return nil, fmt.Errorf("no proxies in %s", src)
+	}
+
+	return ans, nil
+}
+
+func download(ctx context.Context, u string) ([]byte, error) {
+	const timeout = 30 * time.Second
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,328 @@
+// Package proxypool sends the requests through a pool of proxies. The proxies
+// returning blocked responses (captcha and consent pages, 429s, errors) are
+// quarantined, and the health checks put them back in the pool once Maps
+// answers them again.
+package proxypool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+)
+
+// The defaults of Config
+const (
+	DefaultQuarantine    = 10 * time.Minute
+	DefaultCheckInterval = 5 * time.Minute
+	DefaultCheckURL      = "https://www.google.com/maps/search/restaurants?hl=en"
+)
+
+// checkTimeout is the timeout of the health check of a proxy
+const checkTimeout = 30 * time.Second
+
+var _ scrapemate.HTTPFetcher = (*Pool)(nil)
+
+// Config configures a Pool
+type Config struct {
+	// Sticky is the number of consecutive jobs whose requests are sent through
+	// the same proxy, 1 rotates the proxies on every job
+	Sticky int
+	// Quarantine is how long a proxy returning a blocked response is not used
+	Quarantine time.Duration
+	// CheckInterval is the time between two health checks, 0 disables them
+	CheckInterval time.Duration
+	// CheckURL is the Maps page requested by the health checks
+	CheckURL string
+}
+
+// Health is the state of the proxies of a pool
+type Health struct {
+	Proxies     int `json:"proxies"`
+	Healthy     int `json:"healthy"`
+	Quarantined int `json:"quarantined"`
+}
+
+// FetcherFunc returns the fetcher sending the requests through proxy
+type FetcherFunc func(proxy string) (scrapemate.HTTPFetcher, error)
+
+type member struct {
+	// name is the url of the proxy without its credentials, it is logged
+	name    string
+	fetcher scrapemate.HTTPFetcher
+	until   time.Time
+}
+
+// Pool is an HTTPFetcher sending the requests of the jobs through the healthy
+// proxies in turn
+type Pool struct {
+	cfg     Config
+	members []*member
+
+	mu      sync.Mutex
+	current int
+	used    int
+}
+
+// New returns a pool of the proxies, newFetcher creates the fetcher of each
+// proxy
+func New(proxies []string, newFetcher FetcherFunc, cfg Config) (*Pool, error) {
+	if len(proxies) == 0 {
+		return nil, errors.New("no proxies provided")
+	}
+
+	if cfg.Sticky <= 0 {
+		cfg.Sticky = 1
+	}
+
+	if cfg.Quarantine <= 0 {
+		cfg.Quarantine = DefaultQuarantine
+	}
+
+	if cfg.CheckURL == "" {
+		cfg.CheckURL = DefaultCheckURL
+	}
+
+	p := Pool{
+		cfg:     cfg,
+		members: make([]*member, 0, len(proxies)),
+	}
+
+	for _, proxy := range proxies {
+		parsed, err := scrapemate.NewProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := newFetcher(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the fetcher of the proxy %s: %w", parsed.URL, err)
+		}
+
+		p.members = append(p.members, &member{name: parsed.URL, fetcher: f})
+	}
+
+	return &p, nil
+}
+
+// Fetch sends the request of the job through the next healthy proxy. When all
+// the proxies are quarantined the request waits for the first one released.
+func (p *Pool) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	m, wait := p.next()
+
+	if wait > 0 {
+		slog.Warn("proxy pool: all the proxies are quarantined", "wait", wait.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			return scrapemate.Response{Error: ctx.Err()}
+		case <-time.After(wait):
+		}
+	}
+
+	resp := m.fetcher.Fetch(ctx, job)
+
+	// the context was canceled, this says nothing about the proxy
+	if ctx.Err() != nil {
+		return resp
+	}
+
+	if reason := Blocked(&resp); reason != "" {
+		p.quarantine(m, reason)
+	}
+
+	return resp
+}
+
+// Health returns how many proxies of the pool are quarantined
+func (p *Pool) Health() Health {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ans := Health{Proxies: len(p.members)}
+
+	now := time.Now()
+
+	for _, m := range p.members {
+		if m.until.After(now) {
+			ans.Quarantined++
+		}
+	}
+
+	ans.Healthy = ans.Proxies - ans.Quarantined
+
+	return ans
+}
+
+func (p *Pool) Close() error {
+	var errs []error
+
+	for _, m := range p.members {
+		if err := m.fetcher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run checks the health of the proxies every CheckInterval until ctx is done
+func (p *Pool) Run(ctx context.Context) {
+	if p.cfg.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Blocked returns why the response shows that Google blocks the proxy: the
+// request failed, it was rate limited, or it got the captcha or the consent
+// page. It is empty for a healthy response.
+func Blocked(resp *scrapemate.Response) string {
+	switch {
+	case resp.Error != nil:
+		return "error"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "rate limited"
+	case fetcher.Interstitial(resp) == fetcher.InterstitialConsent:
+		return fetcher.InterstitialConsent
+	case fetcher.IsBlocked(resp):
+		return "blocked"
+	default:
+		return ""
+	}
+}
+
+// next returns the proxy of the next job and how long to wait before using it
+func (p *Pool) next() (*member, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	if p.used < p.cfg.Sticky && p.members[p.current].until.Before(now) {
+		p.used++
+
+		return p.members[p.current], 0
+	}
+
+	first := p.current
+
+	for i := 1; i <= len(p.members); i++ {
+		idx := (p.current + i) % len(p.members)
+
+		if p.members[idx].until.Before(now) {
+			p.current, p.used = idx, 1
+
+			return p.members[idx], 0
+		}
+
+		if p.members[idx].until.Before(p.members[first].until) {
+			first = idx
+		}
+	}
+
+	p.current, p.used = first, 1
+
+	return p.members[first], p.members[first].until.Sub(now)
+}
+
+func (p *Pool) quarantine(m *member, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the proxy is quarantined once by the requests in flight
+	if m.until.After(time.Now()) {
+		return
+	}
+
+	m.until = time.Now().Add(p.cfg.Quarantine)
+
+	slog.Warn("proxy pool: proxy quarantined", "proxy", m.name, "reason", reason, "for", p.cfg.Quarantine)
+}
+
+// check requests the Maps page of CheckURL through every proxy: the healthy
+// proxies are released and the blocked ones are quarantined
+func (p *Pool) check(ctx context.Context) {
+	var (
+		wg      sync.WaitGroup
+		healthy = make([]bool, len(p.members))
+	)
+
+	for i, m := range p.members {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			job := scrapemate.Job{
+				ID:      "proxy-check",
+				Method:  http.MethodGet,
+				URL:     p.cfg.CheckURL,
+				Timeout: checkTimeout,
+			}
+
+			cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			resp := m.fetcher.Fetch(cctx, &job)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if reason := Blocked(&resp); reason != "" {
+				p.quarantine(m, reason)
+
+				return
+			}
+
+			healthy[i] = true
+		}()
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	n := 0
+
+	p.mu.Lock()
+
+	for i, m := range p.members {
+		if !healthy[i] {
+			continue
+		}
+
+		n++
+
+		if !m.until.IsZero() {
+			m.until = time.Time{}
+
+			slog.Info("proxy pool: proxy released", "proxy", m.name)
+		}
+	}
+
+	p.mu.Unlock()
+
+	slog.Info("proxy pool: health check done", "healthy", n, "proxies", len(p.members))
+}
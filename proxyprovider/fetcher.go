@@ -0,0 +1,63 @@
+package proxyprovider
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/proxypool"
+)
+
+var _ scrapemate.HTTPFetcher = (*Fetcher)(nil)
+
+// Fetcher sends the requests of every job through its own session of the
+// provider. A job retried after a blocked response gets a new session, so a
+// new exit IP.
+type Fetcher struct {
+	provider   *Provider
+	newFetcher proxypool.FetcherFunc
+
+	// attempts are the blocked attempts of the jobs, by job ID
+	attempts sync.Map
+}
+
+// NewFetcher returns a fetcher of the sessions of p, newFetcher creates the
+// fetcher of the proxy of a session
+func NewFetcher(p *Provider, newFetcher proxypool.FetcherFunc) *Fetcher {
+	return &Fetcher{
+		provider:   p,
+		newFetcher: newFetcher,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	id := job.GetID()
+
+	v, _ := f.attempts.Load(id)
+	attempt, _ := v.(int)
+
+	hf, err := f.newFetcher(f.provider.URL(SessionID(id + "-" + strconv.Itoa(attempt))))
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	defer hf.Close()
+
+	resp := hf.Fetch(ctx, job)
+
+	switch {
+	case ctx.Err() != nil:
+	case proxypool.Blocked(&resp) != "":
+		f.attempts.Store(id, attempt+1)
+	default:
+		f.attempts.Delete(id)
+	}
+
+	return resp
+}
+
+func (f *Fetcher) Close() error {
+	return nil
+}
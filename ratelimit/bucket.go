@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*Buckets)(nil)
+
+// Buckets is an in memory token bucket Limiter: every key has a bucket of
+// burst tokens refilled at rate tokens per second.
+type Buckets struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	// tokens is negative when the requests waiting for a token are reserved
+	tokens   float64
+	last     time.Time
+	cooldown time.Time
+}
+
+// NewBuckets creates a limiter allowing rate operations per second for every
+// key, with bursts of burst operations
+func NewBuckets(rate float64, burst int) (*Buckets, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+
+	if burst <= 0 {
+		return nil, fmt.Errorf("burst must be positive")
+	}
+
+	ans := Buckets{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+
+	return &ans, nil
+}
+
+// Wait blocks until an operation for key is allowed
+func (b *Buckets) Wait(ctx context.Context, key string) error {
+	_, wait := b.ReserveAny(key)
+
+	return sleep(ctx, wait)
+}
+
+// Cooldown blocks all the operations for key for d
+func (b *Buckets) Cooldown(_ context.Context, key string, d time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.get(key, time.Now()).cooldown = time.Now().Add(d)
+
+	return nil
+}
+
+// ReserveAny takes a token of the key whose token is available first, the
+// first of keys on a tie. It returns the key and how long to wait before the
+// operation.
+func (b *Buckets) ReserveAny(keys ...string) (key string, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	var best *bucket
+
+	for i, k := range keys {
+		bk := b.get(k, now)
+		d := b.delay(bk, now)
+
+		if i == 0 || d < wait {
+			key, wait, best = k, d, bk
+		}
+
+		if d == 0 {
+			break
+		}
+	}
+
+	if best != nil {
+		best.tokens--
+	}
+
+	return key, wait
+}
+
+// get returns the bucket of key refilled until now
+func (b *Buckets) get(key string, now time.Time) *bucket {
+	bk, ok := b.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: b.burst, last: now}
+		b.buckets[key] = bk
+
+		return bk
+	}
+
+	bk.tokens = min(b.burst, bk.tokens+now.Sub(bk.last).Seconds()*b.rate)
+	bk.last = now
+
+	return bk
+}
+
+// delay returns how long until the next token of bk is available
+func (b *Buckets) delay(bk *bucket, now time.Time) time.Duration {
+	var d time.Duration
+
+	if bk.tokens < 1 {
+		d = time.Duration((1 - bk.tokens) / b.rate * float64(time.Second))
+	}
+
+	if cooldown := bk.cooldown.Sub(now); cooldown > d {
+		d = cooldown
+	}
+
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
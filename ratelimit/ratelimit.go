@@ -0,0 +1,15 @@
+// Package ratelimit limits the rate of the requests.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter limits the rate of the operations identified by a key
+type Limiter interface {
+	// Wait blocks until an operation for key is allowed
+	Wait(ctx context.Context, key string) error
+	// Cooldown blocks all the operations for key for d
+	Cooldown(ctx context.Context, key string, d time.Duration) error
+}
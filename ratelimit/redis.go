@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Limiter = (*Redis)(nil)
+
+// Redis is a fixed window Limiter that keeps its state in redis,
+// so all the workers using the same redis share the limits.
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+	limit  int64
+	window time.Duration
+}
+
+// NewRedis creates a limiter allowing limit operations per window for every key.
+// Keys in redis are prefixed with prefix.
+func NewRedis(client redis.UniversalClient, prefix string, limit int, window time.Duration) (*Redis, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	ans := Redis{
+		client: client,
+		prefix: prefix,
+		limit:  int64(limit),
+		window: window,
+	}
+
+	return &ans, nil
+}
+
+// NewRedisCooldowns creates a limiter sharing only the cooldowns of the keys:
+// the operations for a key wait while it cools down and are not limited
+// otherwise. Keys in redis are prefixed with prefix.
+func NewRedisCooldowns(client redis.UniversalClient, prefix string) *Redis {
+	return &Redis{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Wait blocks until an operation for key is allowed
+func (r *Redis) Wait(ctx context.Context, key string) error {
+	for {
+		wait, err := r.reserve(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if wait <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Cooldown blocks all the operations for key for d
+func (r *Redis) Cooldown(ctx context.Context, key string, d time.Duration) error {
+	return r.client.Set(ctx, r.prefix+"cooldown:"+key, 1, d).Err()
+}
+
+// reserve tries to take a slot in the current window.
+// It returns how long to wait if no slot is available.
+func (r *Redis) reserve(ctx context.Context, key string) (time.Duration, error) {
+	cooldown, err := r.client.PTTL(ctx, r.prefix+"cooldown:"+key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if cooldown > 0 {
+		return cooldown, nil
+	}
+
+	if r.limit == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	window := now.UnixNano() / int64(r.window)
+	windowKey := r.prefix + key + ":" + strconv.FormatInt(window, 10)
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, windowKey)
+	pipe.PExpire(ctx, windowKey, r.window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	if incr.Val() <= r.limit {
+		return 0, nil
+	}
+
+	next := time.Unix(0, (window+1)*int64(r.window))
+
+	return next.Sub(now), nil
+}
@@ -0,0 +1,444 @@
+// Package redisqueue is a job provider keeping the jobs in redis, so that the
+// jobs survive the restarts and many workers on many machines process them.
+//
+// A popped job is leased to the provider that popped it until it is done: a
+// job is done when the worker that received it takes its next job. The leases
+// are renewed while the provider runs, the jobs of the expired leases (e.g.
+// of a crashed worker) are pushed back to the queue.
+package redisqueue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	// DefaultLease is how long a popped job stays leased once the provider
+	// stops renewing it
+	DefaultLease = time.Minute
+	// popInterval is how long a worker waits before popping again from an
+	// empty queue
+	popInterval = time.Second
+	// priorityScore orders the queue by priority and then by the time the jobs
+	// are pushed, in milliseconds
+	priorityScore = 1e13
+	// renewals is the number of renewals during a lease
+	renewals = 3
+)
+
+var _ scrapemate.JobProvider = (*Provider)(nil)
+
+// pushScript pushes a job once: the ids of the jobs ever pushed are kept, as
+// the primary key of the jobs of the postgres provider
+var pushScript = redis.NewScript(`
+if redis.call('SADD', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1
+`)
+
+// popScript pops the first job of the queue and leases it until ARGV[1]
+var popScript = redis.NewScript(`
+local popped = redis.call('ZPOPMIN', KEYS[1])
+if #popped == 0 then
+	return false
+end
+local id = popped[1]
+redis.call('ZADD', KEYS[2], ARGV[1], id)
+local value = redis.call('HGET', KEYS[3], id)
+if not value then
+	return {id}
+end
+return {id, value}
+`)
+
+// requeueScript pushes the leased jobs of ARGV back to the front of the queue
+var requeueScript = redis.NewScript(`
+local n = 0
+for _, id in ipairs(ARGV) do
+	if redis.call('ZREM', KEYS[1], id) == 1 then
+		redis.call('ZADD', KEYS[2], 0, id)
+		n = n + 1
+	end
+end
+return n
+`)
+
+// completeScript removes the jobs of ARGV and forgets the ids of the pushed
+// jobs once no job is left
+var completeScript = redis.NewScript(`
+for _, id in ipairs(ARGV) do
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('HDEL', KEYS[2], id)
+end
+if redis.call('HLEN', KEYS[2]) == 0 then
+	redis.call('DEL', KEYS[3])
+end
+return 0
+`)
+
+// Provider is a job provider keeping the jobs in redis
+type Provider struct {
+	client redis.UniversalClient
+	ids    string
+	jobs   string
+	queue  string
+	leases string
+	lease  time.Duration
+
+	mu      sync.Mutex
+	runtime gmaps.Runtime
+	started bool
+	// inflight are the ids of the jobs leased by the provider
+	inflight map[string]struct{}
+	jobc     chan scrapemate.IJob
+	errc     chan error
+}
+
+// ProviderOption configures the provider
+type ProviderOption func(*Provider)
+
+// WithDeduper sets the deduper of the search jobs popped from the queue.
+// Use a shared deduper (e.g. deduper.NewRedis) when running many workers.
+func WithDeduper(d deduper.Deduper) ProviderOption {
+	return func(p *Provider) {
+		p.runtime.Deduper = d
+	}
+}
+
+// WithLease sets how long a popped job stays leased, see DefaultLease
+func WithLease(d time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.lease = d
+	}
+}
+
+// NewProvider returns a provider keeping the jobs in the keys of prefix: the
+// queue and the leases are sorted sets of the job ids and the payloads are in
+// a hash
+func NewProvider(client redis.UniversalClient, prefix string, opts ...ProviderOption) *Provider {
+	p := Provider{
+		client:   client,
+		ids:      prefix + "ids",
+		jobs:     prefix + "jobs",
+		queue:    prefix + "queue",
+		leases:   prefix + "leases",
+		lease:    DefaultLease,
+		inflight: make(map[string]struct{}),
+		jobc:     make(chan scrapemate.IJob),
+		errc:     make(chan error, 1),
+	}
+
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	return &p
+}
+
+// SetRuntime sets the state of the run on the popped jobs, it replaces the
+// deduper of WithDeduper. It is called before the jobs are popped.
+func (p *Provider) SetRuntime(rt gmaps.Runtime) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.runtime = rt
+}
+
+// Push pushes a job to the queue, a job already pushed is skipped
+func (p *Provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	value := append([]byte(payloadType+":"), payload...)
+	score := float64(job.GetPriority())*priorityScore + float64(time.Now().UnixMilli())
+
+	return pushScript.Run(ctx, p.client, []string{p.ids, p.jobs, p.queue}, job.GetID(), value, score).Err()
+}
+
+// Jobs returns the jobs popped from the queue. The jobs are popped by one
+// goroutine and shared by the workers of scrapemate: the previous job of the
+// channel is done when a job is received.
+//
+//nolint:gocritic // scrapemate.JobProvider returns read only channels
+func (p *Provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	outc := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	p.mu.Lock()
+	if !p.started {
+		go p.pop(ctx)
+		go p.keep(ctx)
+
+		p.started = true
+	}
+	p.mu.Unlock()
+
+	go func() {
+		var current string
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-p.errc:
+				errc <- err
+
+				return
+			case job, ok := <-p.jobc:
+				if !ok {
+					return
+				}
+
+				select {
+				case outc <- job:
+				case <-ctx.Done():
+					return
+				}
+
+				if current != "" {
+					p.ack(ctx, current)
+				}
+
+				current = job.GetID()
+			}
+		}
+	}()
+
+	return outc, errc
+}
+
+// Counts returns the number of the jobs in the queue or leased by payload
+// type, see gmaps.EncodeJob
+func (p *Provider) Counts(ctx context.Context) (map[string]int, error) {
+	values, err := p.client.HVals(ctx, p.jobs).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make(map[string]int)
+
+	for _, v := range values {
+		payloadType, _, _ := strings.Cut(v, ":")
+		ans[payloadType]++
+	}
+
+	return ans, nil
+}
+
+// Complete removes the jobs leased by the provider. It is called when all the
+// jobs of the run are done: the last jobs of the workers are not removed
+// since the workers take no next job.
+func (p *Provider) Complete(ctx context.Context) error {
+	ids := p.takeInflight()
+
+	return completeScript.Run(ctx, p.client, []string{p.leases, p.jobs, p.ids}, ids...).Err()
+}
+
+// Release pushes the jobs leased by the provider back to the queue. It is
+// called when the run is interrupted, the jobs are processed again.
+func (p *Provider) Release(ctx context.Context) error {
+	ids := p.takeInflight()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return requeueScript.Run(ctx, p.client, []string{p.leases, p.queue}, ids...).Err()
+}
+
+func (p *Provider) pop(ctx context.Context) {
+	defer close(p.jobc)
+
+	for {
+		job, err := p.take(ctx)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			// the workers restart the provider, the jobs are popped again
+			select {
+			case p.errc <- err:
+			default:
+			}
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(popInterval):
+			}
+
+			continue
+		}
+
+		select {
+		case p.jobc <- job:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// take pops and leases the first job of the queue, it returns nil when the
+// queue is empty
+func (p *Provider) take(ctx context.Context) (scrapemate.IJob, error) {
+	expiry := time.Now().Add(p.lease).UnixMilli()
+
+	res, err := popScript.Run(ctx, p.client, []string{p.queue, p.leases, p.jobs}, expiry).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	id := res[0]
+
+	p.mu.Lock()
+	p.inflight[id] = struct{}{}
+	rt := p.runtime
+	p.mu.Unlock()
+
+	if len(res) < 2 {
+		p.ack(ctx, id)
+
+		return nil, nil
+	}
+
+	payloadType, payload, ok := strings.Cut(res[1], ":")
+	if !ok {
+		p.ack(ctx, id)
+
+		return nil, errors.New("invalid job payload")
+	}
+
+	job, err := gmaps.DecodeJob(payloadType, []byte(payload))
+	if err != nil {
+		p.ack(ctx, id)
+
+		return nil, err
+	}
+
+	rt.Attach(job)
+
+	return job, nil
+}
+
+// ack removes a done job
+func (p *Provider) ack(ctx context.Context, id string) {
+	p.mu.Lock()
+	delete(p.inflight, id)
+	p.mu.Unlock()
+
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, p.leases, id)
+		pipe.HDel(ctx, p.jobs, id)
+
+		return nil
+	})
+	if err != nil {
+		slog.Warn("queue: cannot remove the done job", "job_id", id, "error", err)
+	}
+}
+
+// keep renews the leases of the provider and pushes the jobs of the expired
+// leases back to the queue until ctx is done
+func (p *Provider) keep(ctx context.Context) {
+	ticker := time.NewTicker(p.lease / renewals)
+	defer ticker.Stop()
+
+	for {
+		if err := p.requeueExpired(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("queue: cannot push back the jobs of the expired leases", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.renew(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("queue: cannot renew the leases", "error", err)
+		}
+	}
+}
+
+func (p *Provider) renew(ctx context.Context) error {
+	expiry := float64(time.Now().Add(p.lease).UnixMilli())
+
+	p.mu.Lock()
+
+	members := make([]redis.Z, 0, len(p.inflight))
+
+	for id := range p.inflight {
+		members = append(members, redis.Z{Score: expiry, Member: id})
+	}
+
+	p.mu.Unlock()
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	return p.client.ZAddXX(ctx, p.leases, members...).Err()
+}
+
+func (p *Provider) requeueExpired(ctx context.Context) error {
+	ids, err := p.client.ZRangeByScore(ctx, p.leases, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	args := make([]any, len(ids))
+	for i := range ids {
+		args[i] = ids[i]
+	}
+
+	n, err := requeueScript.Run(ctx, p.client, []string{p.leases, p.queue}, args...).Int()
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		slog.Info("queue: the jobs of the expired leases are pushed back", "jobs", n)
+	}
+
+	return nil
+}
+
+func (p *Provider) takeInflight() []any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]any, 0, len(p.inflight))
+
+	for id := range p.inflight {
+		ids = append(ids, id)
+	}
+
+	clear(p.inflight)
+
+	return ids
+}
@@ -0,0 +1,188 @@
+package redisqueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gosom/scrapemate"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/redisqueue"
+)
+
+const prefix = "test:"
+
+func newClient(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// newJob returns a place job of url with the priority prio
+func newJob(url string, prio int) scrapemate.IJob {
+	job := gmaps.NewPlaceJob("seed", "en", url, false, false)
+	job.Priority = prio
+
+	return job
+}
+
+// push pushes the jobs in order, the jobs of the same priority are ordered by
+// their push time in milliseconds
+func push(ctx context.Context, t *testing.T, p *redisqueue.Provider, jobs ...scrapemate.IJob) {
+	t.Helper()
+
+	for _, job := range jobs {
+		require.NoError(t, p.Push(ctx, job))
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+// receive returns the next job of jobs
+func receive(t *testing.T, jobs <-chan scrapemate.IJob) scrapemate.IJob {
+	t.Helper()
+
+	select {
+	case job := <-jobs:
+		return job
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no job received")
+
+		return nil
+	}
+}
+
+func Test_ProviderPriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := redisqueue.NewProvider(newClient(t), prefix)
+
+	low := newJob("https://www.google.com/maps/place/low", 1)
+	high := newJob("https://www.google.com/maps/place/high", 0)
+	next := newJob("https://www.google.com/maps/place/next", 0)
+
+	push(ctx, t, p, low, high, next, high)
+
+	counts, err := p.Counts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{gmaps.PayloadPlace: 3}, counts)
+
+	jobs, _ := p.Jobs(ctx)
+
+	// the jobs of the lower priority are popped first, in the order of their push
+	for _, want := range []scrapemate.IJob{high, next, low} {
+		require.Equal(t, want.GetID(), receive(t, jobs).GetID())
+	}
+}
+
+func Test_ProviderAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newClient(t)
+	p := redisqueue.NewProvider(client, prefix)
+
+	a := newJob("https://www.google.com/maps/place/a", 0)
+	b := newJob("https://www.google.com/maps/place/b", 0)
+
+	push(ctx, t, p, a, b)
+
+	jobs, _ := p.Jobs(ctx)
+
+	require.Equal(t, a.GetID(), receive(t, jobs).GetID())
+	require.Equal(t, b.GetID(), receive(t, jobs).GetID())
+
+	// a is done once the next job is received, b is leased
+	require.Eventually(t, func() bool {
+		counts, err := p.Counts(ctx)
+
+		return err == nil && counts[gmaps.PayloadPlace] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	leased, err := client.ZRange(ctx, prefix+"leases", 0, -1).Result()
+	require.NoError(t, err)
+	require.Equal(t, []string{b.GetID()}, leased)
+
+	require.NoError(t, p.Complete(ctx))
+
+	counts, err := p.Counts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, counts)
+
+	// the ids of the pushed jobs are forgotten once no job is left
+	require.NoError(t, p.Push(ctx, a))
+
+	counts, err = p.Counts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{gmaps.PayloadPlace: 1}, counts)
+}
+
+func Test_ProviderLease(t *testing.T) {
+	tests := []struct {
+		name string
+		// stop stops the first provider holding the lease of the job
+		stop func(ctx context.Context, p *redisqueue.Provider, cancel context.CancelFunc) error
+	}{
+		{
+			name: "expired lease",
+			stop: func(_ context.Context, _ *redisqueue.Provider, cancel context.CancelFunc) error {
+				cancel()
+
+				return nil
+			},
+		},
+		{
+			name: "released lease",
+			stop: func(ctx context.Context, p *redisqueue.Provider, cancel context.CancelFunc) error {
+				cancel()
+
+				return p.Release(ctx)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newClient(t)
+
+			const lease = 300 * time.Millisecond
+
+			job := newJob("https://www.google.com/maps/place/a", 0)
+
+			first := redisqueue.NewProvider(client, prefix, redisqueue.WithLease(lease))
+			require.NoError(t, first.Push(ctx, job))
+
+			firstCtx, cancelFirst := context.WithCancel(ctx)
+			defer cancelFirst()
+
+			jobs, _ := first.Jobs(firstCtx)
+			require.Equal(t, job.GetID(), receive(t, jobs).GetID())
+
+			// the lease is renewed while the provider runs
+			time.Sleep(2 * lease)
+
+			leased, err := client.ZCard(ctx, prefix+"leases").Result()
+			require.NoError(t, err)
+			require.Equal(t, int64(1), leased)
+
+			require.NoError(t, tc.stop(ctx, first, cancelFirst))
+
+			secondCtx, cancelSecond := context.WithCancel(ctx)
+			defer cancelSecond()
+
+			second := redisqueue.NewProvider(client, prefix, redisqueue.WithLease(lease))
+			jobs, _ = second.Jobs(secondCtx)
+
+			require.Equal(t, job.GetID(), receive(t, jobs).GetID())
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var closedRegex = regexp.MustCompile(`(?i)(permanently|temporarily) closed`)
+
+// Options tune what counts as a change
+type Options struct {
+	// MinRatingShift is the smallest rating change reported, 0.2 by default
+	MinRatingShift float64
+}
+
+// RatingShift is a place whose rating changed between the snapshots
+type RatingShift struct {
+	Place
+	OldRating  float64
+	OldReviews int
+}
+
+// Shift is the rating change, negative when the rating dropped
+func (r RatingShift) Shift() float64 {
+	return r.Rating - r.OldRating
+}
+
+// CategoryCount is a category and the number of its places
+type CategoryCount struct {
+	Category string
+	Count    int
+	OldCount int
+}
+
+// Comparison are the market changes between two snapshots of the same area
+type Comparison struct {
+	OldPlaces int
+	NewPlaces int
+	// Openings are the places of the new snapshot missing in the old one
+	Openings []Place
+	// Closures are the places missing in the new snapshot or marked as closed in it
+	Closures     []Place
+	RatingShifts []RatingShift
+	// NewCategories are the categories without places in the old snapshot
+	NewCategories []CategoryCount
+	// Categories are the counts of all the categories, the biggest first
+	Categories []CategoryCount
+}
+
+// Compare returns the changes from the old snapshot to the new one
+func Compare(old, current []Place, opts Options) Comparison {
+	const defaultMinRatingShift = 0.2
+
+	if opts.MinRatingShift <= 0 {
+		opts.MinRatingShift = defaultMinRatingShift
+	}
+
+	oldByID := index(old)
+	newByID := index(current)
+
+	ans := Comparison{
+		OldPlaces: len(oldByID),
+		NewPlaces: len(newByID),
+	}
+
+	for id, p := range newByID {
+		prev, existed := oldByID[id]
+
+		switch {
+		case !existed:
+			ans.Openings = append(ans.Openings, p)
+		case closedRegex.MatchString(p.Status) && !closedRegex.MatchString(prev.Status):
+			ans.Closures = append(ans.Closures, p)
+		// the tolerance keeps 4.5-4.3 (0.19999…) a shift of 0.2
+		case p.Rating > 0 && prev.Rating > 0 && math.Abs(p.Rating-prev.Rating) >= opts.MinRatingShift-1e-9:
+			ans.RatingShifts = append(ans.RatingShifts, RatingShift{
+				Place:      p,
+				OldRating:  prev.Rating,
+				OldReviews: prev.Reviews,
+			})
+		}
+	}
+
+	for id, p := range oldByID {
+		if _, ok := newByID[id]; !ok && !closedRegex.MatchString(p.Status) {
+			ans.Closures = append(ans.Closures, p)
+		}
+	}
+
+	oldCategories := countCategories(oldByID)
+	newCategories := countCategories(newByID)
+
+	for category, n := range newCategories {
+		c := CategoryCount{Category: category, Count: n, OldCount: oldCategories[category]}
+
+		ans.Categories = append(ans.Categories, c)
+
+		if c.OldCount == 0 {
+			ans.NewCategories = append(ans.NewCategories, c)
+		}
+	}
+
+	for category, n := range oldCategories {
+		if _, ok := newCategories[category]; !ok {
+			ans.Categories = append(ans.Categories, CategoryCount{Category: category, OldCount: n})
+		}
+	}
+
+	byTitle := func(places []Place) {
+		sort.Slice(places, func(i, j int) bool {
+			return strings.ToLower(places[i].Title) < strings.ToLower(places[j].Title)
+		})
+	}
+
+	byTitle(ans.Openings)
+	byTitle(ans.Closures)
+
+	sort.Slice(ans.RatingShifts, func(i, j int) bool {
+		return math.Abs(ans.RatingShifts[i].Shift()) > math.Abs(ans.RatingShifts[j].Shift())
+	})
+
+	byCount := func(categories []CategoryCount) {
+		sort.Slice(categories, func(i, j int) bool {
+			if categories[i].Count != categories[j].Count {
+				return categories[i].Count > categories[j].Count
+			}
+
+			return categories[i].Category < categories[j].Category
+		})
+	}
+
+	byCount(ans.NewCategories)
+	byCount(ans.Categories)
+
+	return ans
+}
+
+func index(places []Place) map[string]Place {
+	ans := make(map[string]Place, len(places))
+
+	for _, p := range places {
+		ans[p.ID] = p
+	}
+
+	return ans
+}
+
+func countCategories(places map[string]Place) map[string]int {
+	ans := make(map[string]int)
+
+	for _, p := range places {
+		if p.Category != "" && !closedRegex.MatchString(p.Status) {
+			ans[p.Category]++
+		}
+	}
+
+	return ans
+}
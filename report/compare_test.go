@@ -0,0 +1,110 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/report"
+)
+
+func Test_Compare(t *testing.T) {
+	cafe := report.Place{ID: "1", Title: "Cafe", Category: "Cafe", Rating: 4.5, Reviews: 100}
+	bakery := report.Place{ID: "2", Title: "bakery", Category: "Bakery", Rating: 4.0, Reviews: 10}
+	bar := report.Place{ID: "3", Title: "Bar", Category: "Bar", Rating: 3.9, Reviews: 50}
+
+	with := func(p report.Place, fn func(p *report.Place)) report.Place {
+		fn(&p)
+
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		old     []report.Place
+		current []report.Place
+		opts    report.Options
+		want    report.Comparison
+	}{
+		{
+			name:    "unchanged",
+			old:     []report.Place{cafe, bakery},
+			current: []report.Place{cafe, bakery},
+			want: report.Comparison{
+				OldPlaces: 2,
+				NewPlaces: 2,
+				Categories: []report.CategoryCount{
+					{Category: "Bakery", Count: 1, OldCount: 1},
+					{Category: "Cafe", Count: 1, OldCount: 1},
+				},
+			},
+		},
+		{
+			name:    "openings and closures",
+			old:     []report.Place{cafe, bakery},
+			current: []report.Place{bar, with(cafe, func(p *report.Place) { p.Status = "Permanently closed" })},
+			want: report.Comparison{
+				OldPlaces: 2,
+				NewPlaces: 2,
+				Openings:  []report.Place{bar},
+				Closures: []report.Place{
+					bakery,
+					with(cafe, func(p *report.Place) { p.Status = "Permanently closed" }),
+				},
+				NewCategories: []report.CategoryCount{{Category: "Bar", Count: 1}},
+				Categories: []report.CategoryCount{
+					{Category: "Bar", Count: 1},
+					{Category: "Bakery", OldCount: 1},
+					{Category: "Cafe", OldCount: 1},
+				},
+			},
+		},
+		{
+			name:    "closed places missing from the new snapshot",
+			old:     []report.Place{with(bakery, func(p *report.Place) { p.Status = "Temporarily closed" })},
+			current: []report.Place{},
+			want: report.Comparison{
+				OldPlaces: 1,
+			},
+		},
+		{
+			name: "rating shifts",
+			old:  []report.Place{cafe, bakery, bar},
+			current: []report.Place{
+				with(cafe, func(p *report.Place) { p.Rating = 4.3 }),
+				with(bakery, func(p *report.Place) { p.Rating = 4.6; p.Reviews = 20 }),
+				with(bar, func(p *report.Place) { p.Rating = 4.0 }),
+			},
+			want: report.Comparison{
+				OldPlaces: 3,
+				NewPlaces: 3,
+				RatingShifts: []report.RatingShift{
+					{Place: with(bakery, func(p *report.Place) { p.Rating = 4.6; p.Reviews = 20 }), OldRating: 4.0, OldReviews: 10},
+					{Place: with(cafe, func(p *report.Place) { p.Rating = 4.3 }), OldRating: 4.5, OldReviews: 100},
+				},
+				Categories: []report.CategoryCount{
+					{Category: "Bakery", Count: 1, OldCount: 1},
+					{Category: "Bar", Count: 1, OldCount: 1},
+					{Category: "Cafe", Count: 1, OldCount: 1},
+				},
+			},
+		},
+		{
+			name:    "minimum rating shift",
+			old:     []report.Place{cafe},
+			current: []report.Place{with(cafe, func(p *report.Place) { p.Rating = 4.3 })},
+			opts:    report.Options{MinRatingShift: 0.5},
+			want: report.Comparison{
+				OldPlaces:  1,
+				NewPlaces:  1,
+				Categories: []report.CategoryCount{{Category: "Cafe", Count: 1, OldCount: 1}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, report.Compare(tc.old, tc.current, tc.opts))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The changes of a place between two snapshots
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// DiffFields are the fields of the places that are compared
+var DiffFields = []string{"title", "category", "address", "phone", "website", "status", "rating"}
+
+// FieldChange is the old and the new value of a field
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// PlaceDiff is a place added, removed or changed from the old snapshot to the
+// new one. Fields are the changed fields of the changed places.
+type PlaceDiff struct {
+	ID     string                 `json:"id"`
+	Title  string                 `json:"title"`
+	Link   string                 `json:"link,omitempty"`
+	Change string                 `json:"change"`
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+}
+
+// Diff returns the places added, removed and changed from the old snapshot to
+// the new one, sorted by title. The rating changes smaller than
+// opts.MinRatingShift are ignored.
+func Diff(old, current []Place, opts Options) []PlaceDiff {
+	const defaultMinRatingShift = 0.2
+
+	if opts.MinRatingShift <= 0 {
+		opts.MinRatingShift = defaultMinRatingShift
+	}
+
+	oldByID := index(old)
+	newByID := index(current)
+
+	var ans []PlaceDiff
+
+	for id, p := range newByID {
+		prev, existed := oldByID[id]
+		if !existed {
+			ans = append(ans, PlaceDiff{ID: id, Title: p.Title, Link: p.Link, Change: ChangeAdded})
+
+			continue
+		}
+
+		if fields := diffFields(prev, p, opts.MinRatingShift); len(fields) > 0 {
+			ans = append(ans, PlaceDiff{ID: id, Title: p.Title, Link: p.Link, Change: ChangeChanged, Fields: fields})
+		}
+	}
+
+	for id, p := range oldByID {
+		if _, ok := newByID[id]; !ok {
+			ans = append(ans, PlaceDiff{ID: id, Title: p.Title, Link: p.Link, Change: ChangeRemoved})
+		}
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		ti, tj := strings.ToLower(ans[i].Title), strings.ToLower(ans[j].Title)
+		if ti != tj {
+			return ti < tj
+		}
+
+		return ans[i].ID < ans[j].ID
+	})
+
+	return ans
+}
+
+func diffFields(old, current Place, minRatingShift float64) map[string]FieldChange {
+	ans := make(map[string]FieldChange)
+
+	add := func(name, o, n string) {
+		if strings.TrimSpace(o) != strings.TrimSpace(n) {
+			ans[name] = FieldChange{Old: o, New: n}
+		}
+	}
+
+	add("title", old.Title, current.Title)
+	add("category", old.Category, current.Category)
+	add("address", old.Address, current.Address)
+	add("phone", old.Phone, current.Phone)
+	add("website", old.Website, current.Website)
+	add("status", old.Status, current.Status)
+
+	// the tolerance keeps 4.5-4.3 (0.19999…) a shift of 0.2
+	if math.Abs(current.Rating-old.Rating) >= minRatingShift-1e-9 {
+		ans["rating"] = FieldChange{Old: formatRating(old.Rating), New: formatRating(current.Rating)}
+	}
+
+	return ans
+}
+
+func formatRating(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// WriteDiffJSON writes the diffs as newline delimited JSON, a place per line
+func WriteDiffJSON(w io.Writer, diffs []PlaceDiff) error {
+	enc := json.NewEncoder(w)
+
+	for i := range diffs {
+		if err := enc.Encode(&diffs[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteDiffCSV writes the diffs as CSV, a row per changed field. The added
+// and removed places have a row without field.
+func WriteDiffCSV(w io.Writer, diffs []PlaceDiff) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"id", "title", "link", "change", "field", "old", "new"}); err != nil {
+		return err
+	}
+
+	for _, d := range diffs {
+		if len(d.Fields) == 0 {
+			if err := cw.Write([]string{d.ID, d.Title, d.Link, d.Change, "", "", ""}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, name := range DiffFields {
+			f, ok := d.Fields[name]
+			if !ok {
+				continue
+			}
+
+			if err := cw.Write([]string{d.ID, d.Title, d.Link, d.Change, name, f.Old, f.New}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// HTMLParams are the details of the report page
+type HTMLParams struct {
+	Title   string
+	OldName string
+	NewName string
+	Date    time.Time
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"shift": func(v float64) string {
+		return fmt.Sprintf("%+.1f", v)
+	},
+}).Parse(htmlReport))
+
+// WriteHTML writes the comparison as a standalone HTML page
+func (c *Comparison) WriteHTML(w io.Writer, params HTMLParams) error {
+	if params.Title == "" {
+		params.Title = "Market changes"
+	}
+
+	if params.Date.IsZero() {
+		params.Date = time.Now()
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		HTMLParams
+		*Comparison
+	}{params, c})
+}
+
+const htmlReport = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem auto; max-width: 1100px; color: #222; }
+h1 { margin-bottom: 0; }
+.meta { color: #666; margin-top: .3rem; }
+.cards { display: flex; gap: 1rem; margin: 1.5rem 0; }
+.card { flex: 1; border: 1px solid #ddd; border-radius: 6px; padding: 1rem; }
+.card b { display: block; font-size: 1.8rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #eee; }
+th { background: #f6f6f6; }
+.up { color: #1a7f37; }
+.down { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.OldName}} &rarr; {{.NewName}} &middot; generated {{.Date.Format "2006-01-02 15:04"}}</p>
+
+<div class="cards">
+<div class="card"><b>{{.OldPlaces}} &rarr; {{.NewPlaces}}</b>places</div>
+<div class="card"><b class="up">{{len .Openings}}</b>openings</div>
+<div class="card"><b class="down">{{len .Closures}}</b>closures</div>
+<div class="card"><b>{{len .RatingShifts}}</b>rating shifts</div>
+<div class="card"><b>{{len .NewCategories}}</b>new categories</div>
+</div>
+
+<h2>Openings</h2>
+{{if .Openings}}<table>
+<tr><th>Name</th><th>Category</th><th>Address</th><th>Rating</th><th>Reviews</th></tr>
+{{range .Openings}}<tr><td>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</td><td>{{.Category}}</td><td>{{.Address}}</td><td>{{.Rating}}</td><td>{{.Reviews}}</td></tr>
+{{end}}</table>{{else}}<p>No openings.</p>{{end}}
+
+<h2>Closures</h2>
+{{if .Closures}}<table>
+<tr><th>Name</th><th>Category</th><th>Address</th><th>Status</th></tr>
+{{range .Closures}}<tr><td>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</td><td>{{.Category}}</td><td>{{.Address}}</td><td>{{if .Status}}{{.Status}}{{else}}no longer listed{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No closures.</p>{{end}}
+
+<h2>Rating shifts</h2>
+{{if .RatingShifts}}<table>
+<tr><th>Name</th><th>Category</th><th>Rating</th><th>Shift</th><th>Reviews</th></tr>
+{{range .RatingShifts}}<tr><td>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</td><td>{{.Category}}</td><td>{{.OldRating}} &rarr; {{.Rating}}</td><td class="{{if lt .Shift 0.0}}down{{else}}up{{end}}">{{shift .Shift}}</td><td>{{.OldReviews}} &rarr; {{.Reviews}}</td></tr>
+{{end}}</table>{{else}}<p>No rating shifts.</p>{{end}}
+
+<h2>New categories</h2>
+{{if .NewCategories}}<table>
+<tr><th>Category</th><th>Places</th></tr>
+{{range .NewCategories}}<tr><td>{{.Category}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{else}}<p>No new categories.</p>{{end}}
+
+<h2>All categories</h2>
+<table>
+<tr><th>Category</th><th>Before</th><th>After</th></tr>
+{{range .Categories}}<tr><td>{{.Category}}</td><td>{{.OldCount}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
@@ -0,0 +1,164 @@
+// Package report compares snapshots of the same area taken at different times.
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Place is the part of an entry the comparison needs
+type Place struct {
+	ID       string
+	Title    string
+	Category string
+	Address  string
+	Phone    string
+	Website  string
+	Link     string
+	Status   string
+	Rating   float64
+	Reviews  int
+}
+
+// LoadSnapshot reads the results of a run: a CSV file, or the JSON output
+// (one entry or one array of entries per line, or a single array).
+func LoadSnapshot(path string) ([]Place, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSV(f)
+	}
+
+	return readJSON(f)
+}
+
+func readJSON(r io.Reader) ([]Place, error) {
+	var places []Place
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	for {
+		var raw json.RawMessage
+
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return places, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot: %w", err)
+		}
+
+		var entries []gmaps.Entry
+
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+			err = json.Unmarshal(raw, &entries)
+		} else {
+			entries = make([]gmaps.Entry, 1)
+			err = json.Unmarshal(raw, &entries[0])
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot entry: %w", err)
+		}
+
+		for i := range entries {
+			places = append(places, placeFromEntry(&entries[i]))
+		}
+	}
+}
+
+func placeFromEntry(e *gmaps.Entry) Place {
+	return Place{
+		ID:       placeID(e.DataID, e.Cid, e.Link, e.Title, e.Address),
+		Title:    e.Title,
+		Category: e.Category,
+		Address:  e.Address,
+		Phone:    e.Phone,
+		Website:  e.WebSite,
+		Link:     e.Link,
+		Status:   e.Status,
+		Rating:   e.ReviewRating,
+		Reviews:  e.ReviewCount,
+	}
+}
+
+func readCSV(r io.Reader) ([]Place, error) {
+	cr := csv.NewReader(r)
+	// the rows of older runs may have fewer columns
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[h] = i
+	}
+
+	var places []Place
+
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return places, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot row: %w", err)
+		}
+
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(row) {
+				return row[i]
+			}
+
+			return ""
+		}
+
+		rating, _ := strconv.ParseFloat(get("review_rating"), 64)
+		reviews, _ := strconv.Atoi(get("review_count"))
+
+		places = append(places, Place{
+			ID:       placeID(get("data_id"), get("cid"), get("link"), get("title"), get("address")),
+			Title:    get("title"),
+			Category: get("category"),
+			Address:  get("address"),
+			Phone:    get("phone"),
+			Website:  get("website"),
+			Link:     get("link"),
+			Status:   get("status"),
+			Rating:   rating,
+			Reviews:  reviews,
+		})
+	}
+}
+
+// placeID identifies a place across the snapshots, the stable ids first
+func placeID(dataID, cid, link, title, address string) string {
+	for _, id := range []string{dataID, cid, link} {
+		if id != "" {
+			return id
+		}
+	}
+
+	return strings.ToLower(title + "|" + address)
+}
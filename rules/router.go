@@ -0,0 +1,194 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*Router)(nil)
+
+// Router is a scrapemate.ResultWriter that evaluates the rules for
+// every entry and forwards it to the matching writers.
+type Router struct {
+	rules   *RuleSet
+	def     scrapemate.ResultWriter
+	routes  map[string]scrapemate.ResultWriter
+	webhook *http.Client
+}
+
+// NewRouter creates a Router. Entries without a route go to def.
+// Every route used in the rules must exist in routes.
+func NewRouter(rs *RuleSet, def scrapemate.ResultWriter, routes map[string]scrapemate.ResultWriter) (*Router, error) {
+	for _, name := range rs.Routes() {
+		if _, ok := routes[name]; !ok {
+			return nil, fmt.Errorf("no writer for route %s", name)
+		}
+	}
+
+	const webhookTimeout = 10 * time.Second
+
+	ans := Router{
+		rules:   rs,
+		def:     def,
+		routes:  routes,
+		webhook: &http.Client{Timeout: webhookTimeout},
+	}
+
+	return &ans, nil
+}
+
+// Run runs the router and the writers it forwards to.
+func (r *Router) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	g, gctx := errgroup.WithContext(ctx)
+
+	chans := make(map[string]chan scrapemate.Result, len(r.routes)+1)
+
+	start := func(name string, w scrapemate.ResultWriter) {
+		ch := make(chan scrapemate.Result)
+		chans[name] = ch
+
+		g.Go(func() error {
+			return w.Run(ctx, ch)
+		})
+	}
+
+	start("", r.def)
+
+	for name, w := range r.routes {
+		start(name, w)
+	}
+
+	g.Go(func() error {
+		defer func() {
+			for _, ch := range chans {
+				close(ch)
+			}
+		}()
+
+		for result := range in {
+			grouped, err := r.route(ctx, result.Data)
+			if err != nil {
+				return err
+			}
+
+			for name, data := range grouped {
+				select {
+				case chans[name] <- scrapemate.Result{Job: result.Job, Data: data}:
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+			}
+		}
+
+		return nil
+	})
+
+	err := g.Wait()
+
+	// keep consuming so that the producer does not block
+	go func() {
+		for range in {
+		}
+	}()
+
+	return err
+}
+
+// route returns the data to send to every writer
+func (r *Router) route(ctx context.Context, data any) (map[string]any, error) {
+	ans := make(map[string]any)
+
+	switch v := data.(type) {
+	case *gmaps.Entry:
+		names, err := r.eval(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			ans[name] = v
+		}
+	case []*gmaps.Entry:
+		grouped := make(map[string][]*gmaps.Entry)
+
+		for _, entry := range v {
+			names, err := r.eval(ctx, entry)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range names {
+				grouped[name] = append(grouped[name], entry)
+			}
+		}
+
+		for name, entries := range grouped {
+			ans[name] = entries
+		}
+	default:
+		ans[""] = data
+	}
+
+	return ans, nil
+}
+
+// eval returns the writers the entry must be sent to
+func (r *Router) eval(ctx context.Context, entry *gmaps.Entry) ([]string, error) {
+	decision, err := r.rules.Eval(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.Drop {
+		return nil, nil
+	}
+
+	for _, u := range decision.Webhooks {
+		if err := r.post(ctx, u, entry); err != nil {
+			slog.Warn("rules: webhook failed", "url", u, "error", err)
+		}
+	}
+
+	if len(decision.Routes) == 0 {
+		return []string{""}, nil
+	}
+
+	return decision.Routes, nil
+}
+
+func (r *Router) post(ctx context.Context, u string, entry *gmaps.Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.webhook.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package rules_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/rules"
+)
+
+func Test_ParseActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		actions []rules.Action
+		err     bool
+	}{
+		{
+			name: "actions",
+			rule: `categories contains "restaurant" -> route restaurants.csv, tag food`,
+			actions: []rules.Action{
+				{Kind: rules.ActionRoute, Arg: "restaurants.csv"},
+				{Kind: rules.ActionTag, Arg: "food"},
+			},
+		},
+		{
+			name:    "separators without spaces",
+			rule:    `*->tag a,drop`,
+			actions: []rules.Action{{Kind: rules.ActionTag, Arg: "a"}, {Kind: rules.ActionDrop}},
+		},
+		{
+			name:    "quoted separators in the conditions",
+			rule:    `title == "A, B -> C" -> tag "x, y"`,
+			actions: []rules.Action{{Kind: rules.ActionTag, Arg: "x, y"}},
+		},
+		{
+			name:    "quoted webhook url",
+			rule:    `* -> webhook "https://example.com/hook?fields=title,phone&next=a->b"`,
+			actions: []rules.Action{{Kind: rules.ActionWebhook, Arg: "https://example.com/hook?fields=title,phone&next=a->b"}},
+		},
+		{name: "missing arrow", rule: `title == "a -> b"`, err: true},
+		{name: "second arrow", rule: `* -> tag a -> drop`, err: true},
+		{name: "comma in the conditions", rule: `title == a, b -> drop`, err: true},
+		{name: "empty action", rule: `* -> tag a,`, err: true},
+		{name: "unquoted comma in the url", rule: `* -> webhook https://example.com/?a=1,2`, err: true},
+		{name: "unterminated quote", rule: `title == "a -> drop`, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rs, err := rules.Parse(strings.NewReader(tc.rule))
+			if tc.err {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, rs.Rules, 1)
+			require.Equal(t, tc.actions, rs.Rules[0].Actions)
+		})
+	}
+}
+
+func Test_RuleSetEval(t *testing.T) {
+	const src = `
+# comments and empty lines are skipped
+
+categories contains "restaurant" -> route restaurants.csv, tag food
+category == "Plumber" or category == "Plumbing service" -> route plumbers.csv
+review_rating < 3 and review_count > 10 -> drop
+title == "A, B -> C" -> tag quoted
+status matches "(?i)closed" -> webhook "https://example.com/closed?fields=title,status"
+`
+
+	rs, err := rules.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Equal(t, []string{"restaurants.csv", "plumbers.csv"}, rs.Routes())
+
+	tests := []struct {
+		name     string
+		entry    gmaps.Entry
+		decision rules.Decision
+		tags     []string
+	}{
+		{
+			name:     "routed and tagged",
+			entry:    gmaps.Entry{Categories: []string{"Greek Restaurant"}, ReviewRating: 4.5},
+			decision: rules.Decision{Routes: []string{"restaurants.csv"}},
+			tags:     []string{"food"},
+		},
+		{
+			name:     "any of the conditions",
+			entry:    gmaps.Entry{Category: "Plumbing service"},
+			decision: rules.Decision{Routes: []string{"plumbers.csv"}},
+		},
+		{
+			name:     "dropped",
+			entry:    gmaps.Entry{Category: "Plumber", ReviewRating: 2.5, ReviewCount: 20},
+			decision: rules.Decision{Routes: []string{"plumbers.csv"}, Drop: true},
+		},
+		{
+			name:  "quoted value",
+			entry: gmaps.Entry{Title: "A, B -> C"},
+			tags:  []string{"quoted"},
+		},
+		{
+			name:     "webhook",
+			entry:    gmaps.Entry{Title: "Shop", Status: "Permanently closed"},
+			decision: rules.Decision{Webhooks: []string{"https://example.com/closed?fields=title,status"}},
+		},
+		{
+			name:  "no rule",
+			entry: gmaps.Entry{Title: "Shop", ReviewRating: 2.5, ReviewCount: 5},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := tc.entry
+
+			decision, err := rs.Eval(&entry)
+			require.NoError(t, err)
+			require.Equal(t, tc.decision, decision)
+			require.Equal(t, tc.tags, entry.Tags)
+		})
+	}
+}
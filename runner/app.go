@@ -0,0 +1,728 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/cache/filecache"
+	"github.com/gosom/scrapemate/adapters/cache/leveldbcache"
+	jsfetcher "github.com/gosom/scrapemate/adapters/fetchers/jshttp"
+	"github.com/gosom/scrapemate/adapters/fetchers/nethttp"
+	parser "github.com/gosom/scrapemate/adapters/parsers/goqueryparser"
+	memprovider "github.com/gosom/scrapemate/adapters/providers/memory"
+	"github.com/gosom/scrapemate/scrapemateapp"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gosom/google-maps-scraper/budget"
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/proxypool"
+	"github.com/gosom/google-maps-scraper/proxyprovider"
+	"github.com/gosom/google-maps-scraper/ratelimit"
+	"github.com/gosom/google-maps-scraper/spillqueue"
+	"github.com/gosom/google-maps-scraper/tracing"
+	"github.com/gosom/google-maps-scraper/useragent"
+	"github.com/gosom/google-maps-scraper/webcheck"
+)
+
+// AppOption configures an App
+type AppOption func(*App)
+
+// App runs a scrapemate instance like scrapemateapp.ScrapemateApp does,
+// but it allows to replace the fetcher used to perform the requests.
+type App struct {
+	cfg *scrapemateapp.Config
+
+	httpFetcher  scrapemate.HTTPFetcher
+	roundTripper http.RoundTripper
+	middleware   *gmaps.Middleware
+	searchParser gmaps.SearchParser
+	limiter      ratelimit.Limiter
+	recordDir    string
+	replayDir    string
+	dumpPath     string
+	cacheDir     string
+	cacheTTL     time.Duration
+	breaker      *fetcher.BreakerConfig
+	adaptive     *fetcher.AdaptiveConfig
+	budget       *budget.Tracker
+	onFailed     func(scrapemate.IJob)
+	proxyRate    float64
+	proxyBurst   int
+	cooldowns    ratelimit.Limiter
+	results      *spillqueue.Results
+	proxyPool    *proxypool.Config
+	pool         *proxypool.Pool
+	sessions     *proxyprovider.Provider
+	rotator      scrapemate.ProxyRotator
+	browser      bool
+	cookiesFile  string
+	jars         *fetcher.CookieJars
+	agents       *useragent.Rotator
+	// websiteWorkers are the websites checked at the same time, 0 when
+	// they are not checked
+	websiteWorkers int
+
+	provider scrapemate.JobProvider
+	cacher   scrapemate.Cacher
+	// drain hands the jobs to the workers with a graceful shutdown, see
+	// WithShutdown
+	drain *drainProvider
+
+	// mu guards the pool and the writers read by the health checks
+	mu      sync.Mutex
+	writers []*pressureWriter
+}
+
+// WithFetcher sets the fetcher used for all jobs that do not carry their own.
+func WithFetcher(f scrapemate.HTTPFetcher) AppOption {
+	return func(a *App) {
+		a.httpFetcher = f
+	}
+}
+
+// WithRoundTripper makes the default fetcher a plain net/http fetcher
+// that uses the provided RoundTripper. It has no effect when WithFetcher is used.
+func WithRoundTripper(rt http.RoundTripper) AppOption {
+	return func(a *App) {
+		a.roundTripper = rt
+	}
+}
+
+// WithMiddleware sets the middleware that runs around the jobs.
+func WithMiddleware(m *gmaps.Middleware) AppOption {
+	return func(a *App) {
+		a.middleware = m
+	}
+}
+
+// WithSearchParser sets the parser of the responses of the fast mode searches.
+func WithSearchParser(p gmaps.SearchParser) AppOption {
+	return func(a *App) {
+		a.searchParser = p
+	}
+}
+
+// WithRateLimiter makes all the requests wait for the limiter.
+func WithRateLimiter(l ratelimit.Limiter) AppOption {
+	return func(a *App) {
+		a.limiter = l
+	}
+}
+
+// WithRecord saves all the responses as fixtures in dir.
+func WithRecord(dir string) AppOption {
+	return func(a *App) {
+		a.recordDir = dir
+	}
+}
+
+// WithReplay serves the requests from the fixtures in dir
+// instead of doing real requests.
+func WithReplay(dir string) AppOption {
+	return func(a *App) {
+		a.replayDir = dir
+	}
+}
+
+// WithDumpRaw writes the raw body of every response to the tar.gz archive
+// path, see fetcher.NewDumper.
+func WithDumpRaw(path string) AppOption {
+	return func(a *App) {
+		a.dumpPath = path
+	}
+}
+
+// WithResponseCache serves the requests from the responses saved in dir for
+// ttl, see fetcher.NewCache.
+func WithResponseCache(dir string, ttl time.Duration) AppOption {
+	return func(a *App) {
+		a.cacheDir = dir
+		a.cacheTTL = ttl
+	}
+}
+
+// WithCircuitBreaker pauses all the requests when too many of them fail.
+func WithCircuitBreaker(cfg fetcher.BreakerConfig) AppOption {
+	return func(a *App) {
+		a.breaker = &cfg
+	}
+}
+
+// WithAdaptiveConcurrency adapts the number of the requests to Google in
+// flight to their error rate, see fetcher.NewAdaptive. The workers of the
+// app are its maximum when cfg.Max is 0.
+func WithAdaptiveConcurrency(cfg fetcher.AdaptiveConfig) AppOption {
+	return func(a *App) {
+		a.adaptive = &cfg
+	}
+}
+
+// WithRequestBudget counts the requests sent with t, the requests it refuses
+// are not sent. The cached responses are not counted.
+func WithRequestBudget(t *budget.Tracker) AppOption {
+	return func(a *App) {
+		a.budget = t
+	}
+}
+
+// WithProxyRateLimit sends at most rate requests per second through every
+// proxy, with bursts of burst requests.
+func WithProxyRateLimit(rate float64, burst int) AppOption {
+	return func(a *App) {
+		a.proxyRate = rate
+		a.proxyBurst = burst
+	}
+}
+
+// WithResultsBuffer passes the results to the writers through b, the workers
+// wait while it is full unless it spills them, see spillqueue.Results.
+func WithResultsBuffer(b *spillqueue.Results) AppOption {
+	return func(a *App) {
+		a.results = b
+	}
+}
+
+// WithWebsiteCheck checks the websites of the places, workers at a time,
+// between the scraping and the writers, see webcheck.Checker.Run.
+func WithWebsiteCheck(workers int) AppOption {
+	return func(a *App) {
+		a.websiteWorkers = max(workers, 1)
+	}
+}
+
+// WithProxyCooldowns pauses the proxies of the responses with status 429 in
+// all the apps sharing the limiter, see fetcher.NewCooldownRotator.
+func WithProxyCooldowns(l ratelimit.Limiter) AppOption {
+	return func(a *App) {
+		a.cooldowns = l
+	}
+}
+
+// WithProxyPool sends the requests of the fast mode through a pool of the
+// proxies, see proxypool.
+func WithProxyPool(cfg proxypool.Config) AppOption {
+	return func(a *App) {
+		a.proxyPool = &cfg
+	}
+}
+
+// WithProxyProvider sends the requests of every job of the fast mode through
+// its own session of the rotating proxy provider p.
+func WithProxyProvider(p *proxyprovider.Provider) AppOption {
+	return func(a *App) {
+		a.sessions = p
+	}
+}
+
+// WithBrowserFallback renders the jobs of the fast mode needing a browser, the
+// searches falling back to it, with a headless browser started by the first one.
+func WithBrowserFallback() AppOption {
+	return func(a *App) {
+		a.browser = true
+	}
+}
+
+// WithCookies loads the cookie jars of the proxies of the fast mode from path
+// and saves them to it when the app is closed.
+func WithCookies(path string) AppOption {
+	return func(a *App) {
+		a.cookiesFile = path
+	}
+}
+
+// WithUserAgents sends the requests of the fast mode as the browser profiles
+// picked by r.
+func WithUserAgents(r *useragent.Rotator) AppOption {
+	return func(a *App) {
+		a.agents = r
+	}
+}
+
+// WithFailedJobs calls fn with every job that failed.
+func WithFailedJobs(fn func(scrapemate.IJob)) AppOption {
+	return func(a *App) {
+		a.onFailed = fn
+	}
+}
+
+// NewApp creates a new App from a scrapemateapp configuration.
+func NewApp(cfg *scrapemateapp.Config, opts ...AppOption) (*App, error) {
+	if cfg == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+
+	app := App{
+		cfg: cfg,
+	}
+
+	for _, opt := range opts {
+		opt(&app)
+	}
+
+	return &app, nil
+}
+
+// Start starts the app and pushes the seed jobs. With the shutdown of
+// WithShutdown, it returns ErrShutdown once the jobs in flight are done and
+// their results are written.
+func (a *App) Start(ctx context.Context, seedJobs ...scrapemate.IJob) error {
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	if a.middleware != nil {
+		ctx = gmaps.ContextWithMiddleware(ctx, a.middleware)
+	}
+
+	if a.searchParser != nil {
+		ctx = gmaps.ContextWithSearchParser(ctx, a.searchParser)
+	}
+
+	defer cancel(errors.New("closing app"))
+
+	var err error
+
+	a.rotator, err = a.getRotator()
+	if err != nil {
+		return err
+	}
+
+	if a.rotator != nil {
+		ctx = gmaps.ContextWithProxies(ctx, a.rotator)
+	}
+
+	a.jars, err = a.getCookieJars()
+	if err != nil {
+		return err
+	}
+
+	// the scraping stops on the shutdown while the writers flush the results
+	mateCtx, mateCancel := ctx, cancel
+
+	shutdown := shutdownFromContext(ctx)
+	if shutdown != nil {
+		mateCtx, mateCancel = context.WithCancelCause(ctx)
+	}
+
+	mate, err := a.getMate(mateCtx, func(cause error) {
+		// the signals are handled by the caller of WithShutdown
+		if shutdown != nil && errors.Is(cause, scrapemate.ErrorExitSignal) {
+			return
+		}
+
+		mateCancel(cause)
+	})
+	if err != nil {
+		return err
+	}
+
+	defer a.Close()
+	defer mate.Close()
+
+	results := mate.Results()
+
+	if a.websiteWorkers > 0 {
+		checker := a.websiteChecker()
+		defer checker.Close()
+
+		results = checker.Run(ctx, results)
+	}
+
+	if a.results != nil {
+		var errc <-chan error
+
+		results, errc = a.results.Run(ctx, results)
+
+		g.Go(func() error {
+			if err := <-errc; err != nil {
+				cancel(err)
+
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	// the before write functions run once for every result, before the
+	// writers take them
+	results, errc := a.middleware.FilterResults(ctx, results)
+
+	g.Go(func() error {
+		if err := <-errc; err != nil {
+			cancel(err)
+
+			return err
+		}
+
+		return nil
+	})
+
+	for i := range a.cfg.Writers {
+		writer := &pressureWriter{next: tracing.WrapWriter(a.cfg.Writers[i])}
+
+		a.mu.Lock()
+		a.writers = append(a.writers, writer)
+		a.mu.Unlock()
+
+		g.Go(func() error {
+			if err := writer.Run(ctx, results); err != nil {
+				cancel(err)
+
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	if a.onFailed != nil {
+		g.Go(func() error {
+			for job := range mate.Failed() {
+				a.onFailed(job)
+			}
+
+			return nil
+		})
+	}
+
+	var stopped bool
+
+	g.Go(func() error {
+		err := mate.Start()
+		if shutdown != nil && errors.Is(err, ErrShutdown) {
+			// the writers are not canceled with the error
+			stopped = true
+
+			return nil
+		}
+
+		return err
+	})
+
+	if shutdown != nil {
+		go func() {
+			select {
+			case <-mateCtx.Done():
+				return
+			case <-shutdown:
+			}
+
+			a.drain.Drain(mateCtx, a.cfg.Concurrency)
+
+			slog.Info("jobs in flight done, writing the results")
+
+			mateCancel(ErrShutdown)
+		}()
+	}
+
+	g.Go(func() error {
+		for i := range seedJobs {
+			if err := a.provider.Push(ctx, seedJobs[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if stopped {
+		return ErrShutdown
+	}
+
+	return nil
+}
+
+// Close closes the app and saves the cookie jars.
+func (a *App) Close() error {
+	var errs []error
+
+	if a.cookiesFile != "" && a.jars != nil {
+		if err := a.jars.Save(a.cookiesFile); err != nil {
+			errs = append(errs, fmt.Errorf("cannot save the cookies: %w", err))
+		}
+	}
+
+	if a.cacher != nil {
+		errs = append(errs, a.cacher.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *App) getMate(ctx context.Context, cancel context.CancelCauseFunc) (*scrapemate.ScrapeMate, error) {
+	a.provider = a.cfg.Provider
+	if a.provider == nil {
+		a.provider = memprovider.New()
+	}
+
+	jobs := a.provider
+	if shutdownFromContext(ctx) != nil {
+		a.drain = newDrainProvider(a.provider, a.cfg.Concurrency)
+		jobs = a.drain
+	}
+
+	httpFetcher, err := a.getFetcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.pool != nil {
+		go a.pool.Run(ctx)
+	}
+
+	if a.browser && a.cfg.UseStealth {
+		httpFetcher = fetcher.NewBrowserFallback(httpFetcher, a.browserFetcher)
+	}
+
+	httpFetcher = fetcher.NewDispatcher(httpFetcher)
+
+	if a.recordDir != "" {
+		httpFetcher, err = fetcher.NewRecorder(httpFetcher, a.recordDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.dumpPath != "" {
+		httpFetcher, err = fetcher.NewDumper(httpFetcher, a.dumpPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.limiter != nil {
+		httpFetcher = fetcher.NewRateLimited(httpFetcher, a.limiter, "global")
+	}
+
+	if a.breaker != nil {
+		httpFetcher = fetcher.NewCircuitBreaker(httpFetcher, *a.breaker)
+	}
+
+	if a.adaptive != nil {
+		cfg := *a.adaptive
+		if cfg.Max <= 0 {
+			cfg.Max = a.cfg.Concurrency
+		}
+
+		httpFetcher = fetcher.NewAdaptive(httpFetcher, cfg)
+	}
+
+	// the refused requests do not wait for the rate limits
+	if a.budget != nil {
+		httpFetcher = fetcher.NewBudgeted(httpFetcher, a.budget, gmaps.RequestKind)
+	}
+
+	// the cached responses are not rate limited, do not open the breaker and
+	// are not counted by the budget
+	if a.cacheDir != "" {
+		httpFetcher, err = fetcher.NewCache(httpFetcher, a.cacheDir, a.cacheTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tracing.Enabled() {
+		httpFetcher = fetcher.NewTraced(httpFetcher)
+	}
+
+	// the searches not needed anymore are skipped before they wait for anything
+	httpFetcher = fetcher.NewSkipper(httpFetcher)
+
+	switch a.cfg.CacheType {
+	case "file":
+		a.cacher, err = filecache.NewFileCache(a.cfg.CachePath)
+	case "leveldb":
+		a.cacher, err = leveldbcache.NewLevelDBCache(a.cfg.CachePath)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	params := []func(*scrapemate.ScrapeMate) error{
+		scrapemate.WithContext(ctx, cancel),
+		scrapemate.WithJobProvider(jobs),
+		scrapemate.WithHTTPFetcher(a.middleware.WrapFetcher(httpFetcher)),
+		scrapemate.WithHTMLParser(parser.New()),
+		scrapemate.WithConcurrency(a.cfg.Concurrency),
+		scrapemate.WithExitBecauseOfInactivity(a.cfg.ExitOnInactivityDuration),
+	}
+
+	if a.cacher != nil {
+		params = append(params, scrapemate.WithCache(a.cacher))
+	}
+
+	if a.onFailed != nil {
+		params = append(params, scrapemate.WithFailed())
+	}
+
+	if a.cfg.InitJob != nil {
+		params = append(params, scrapemate.WithInitJob(a.cfg.InitJob))
+	}
+
+	return scrapemate.New(params...)
+}
+
+func (a *App) getFetcher() (scrapemate.HTTPFetcher, error) {
+	if a.replayDir != "" {
+		return fetcher.NewReplayer(a.replayDir)
+	}
+
+	if a.httpFetcher != nil {
+		return a.httpFetcher, nil
+	}
+
+	if a.roundTripper != nil {
+		return fetcher.NewRoundTripper(a.roundTripper)
+	}
+
+	if a.sessions != nil && a.cfg.UseStealth {
+		return proxyprovider.NewFetcher(a.sessions, a.proxyFetcher), nil
+	}
+
+	if a.proxyPool != nil && a.cfg.UseStealth && len(a.cfg.Proxies) > 0 {
+		pool, err := proxypool.New(a.cfg.Proxies, a.proxyFetcher, *a.proxyPool)
+		if err != nil {
+			return nil, err
+		}
+
+		a.mu.Lock()
+		a.pool = pool
+		a.mu.Unlock()
+
+		return pool, nil
+	}
+
+	if a.cfg.UseJS {
+		return a.browserFetcher()
+	}
+
+	if a.cfg.UseStealth {
+		return fetcher.NewStealth(a.cfg.StealthBrowser, a.rotator, a.jars, a.agents), nil
+	}
+
+	cookieJar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	const timeout = 10 * time.Second
+
+	netClient := &http.Client{
+		Timeout: timeout,
+		Jar:     cookieJar,
+	}
+
+	if a.rotator != nil {
+		netClient.Transport = a.rotator
+	}
+
+	return nethttp.New(netClient), nil
+}
+
+// browserFetcher returns the fetcher of the browser, the one of the run or
+// the one of the fast mode searches falling back to it
+func (a *App) browserFetcher() (scrapemate.HTTPFetcher, error) {
+	return jsfetcher.New(jsfetcher.JSFetcherOptions{
+		Headless:          !a.cfg.JSOpts.Headfull,
+		DisableImages:     a.cfg.JSOpts.DisableImages || a.cfg.UseStealth,
+		Rotator:           a.rotator,
+		PoolSize:          a.cfg.Concurrency,
+		PageReuseLimit:    a.cfg.PageReuseLimit,
+		BrowserReuseLimit: a.cfg.BrowserReuseLimit,
+		UserAgent:         a.cfg.JSOpts.UA,
+	})
+}
+
+// getCookieJars returns the cookie jars of the proxies, the ones saved to the
+// cookies file by the previous runs
+func (a *App) getCookieJars() (*fetcher.CookieJars, error) {
+	if a.cookiesFile == "" {
+		return fetcher.NewCookieJars(), nil
+	}
+
+	return fetcher.LoadCookieJars(a.cookiesFile)
+}
+
+// websiteChecker returns the checker of the websites of the places, its
+// requests go through the proxies and the rate limiter of the app, with a
+// circuit breaker of their own
+func (a *App) websiteChecker() *webcheck.Checker {
+	opts := []webcheck.Option{webcheck.WithWorkers(a.websiteWorkers)}
+
+	switch {
+	case a.rotator != nil:
+		opts = append(opts, webcheck.WithTransport(a.rotator))
+	case a.roundTripper != nil:
+		opts = append(opts, webcheck.WithTransport(a.roundTripper))
+	}
+
+	if a.limiter != nil {
+		opts = append(opts, webcheck.WithRateLimiter(a.limiter))
+	}
+
+	if a.breaker != nil {
+		opts = append(opts, webcheck.WithCircuitBreaker(*a.breaker))
+	}
+
+	return webcheck.New(0, opts...)
+}
+
+// getRotator returns the rotator of the proxies of the run, nil without
+// proxies
+func (a *App) getRotator() (scrapemate.ProxyRotator, error) {
+	if len(a.cfg.Proxies) == 0 {
+		return nil, nil
+	}
+
+	return a.newRotator(a.cfg.Proxies)
+}
+
+// newRotator returns the rotator of proxies, limited by the proxy rate and
+// waiting for the shared cooldowns when they are set
+func (a *App) newRotator(proxies []string) (scrapemate.ProxyRotator, error) {
+	var (
+		rotator scrapemate.ProxyRotator
+		err     error
+	)
+
+	if a.proxyRate > 0 {
+		rotator, err = fetcher.NewLimitedRotator(proxies, a.proxyRate, a.proxyBurst)
+	} else {
+		rotator, err = fetcher.NewRotator(proxies)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if a.cooldowns != nil {
+		rotator = fetcher.NewCooldownRotator(rotator, a.cooldowns)
+	}
+
+	return rotator, nil
+}
+
+// proxyFetcher returns the fetcher of the fast mode sending the requests
+// through the proxy u, the fetcher of a proxy of the pool or of a session of
+// the proxy provider
+func (a *App) proxyFetcher(u string) (scrapemate.HTTPFetcher, error) {
+	rotator, err := a.newRotator([]string{u})
+	if err != nil {
+		return nil, err
+	}
+
+	return fetcher.NewStealth(a.cfg.StealthBrowser, rotator, a.jars, a.agents), nil
+}
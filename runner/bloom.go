@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+// SetupBloom loads the bloom filter configured via the command line
+// and uses it as the Deduper of cfg.
+func SetupBloom(cfg *Config) error {
+	if cfg.BloomFile == "" {
+		return nil
+	}
+
+	b, err := deduper.LoadBloom(cfg.BloomFile, cfg.BloomCapacity, cfg.BloomFPRate)
+	if err != nil {
+		return err
+	}
+
+	cfg.Deduper = b
+
+	return nil
+}
+
+// SaveBloom persists the bloom filter loaded by SetupBloom.
+func SaveBloom(cfg *Config) error {
+	b, ok := cfg.Deduper.(*deduper.Bloom)
+	if !ok || cfg.BloomFile == "" {
+		return nil
+	}
+
+	return b.Save(cfg.BloomFile)
+}
@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFlag is the flag of the configuration file
+	configFlag = "config"
+	// queriesKey is the key of the queries of a configuration file, they are
+	// searched without -input
+	queriesKey = "queries"
+	// envPrefix is the prefix of the environment variables of the flags
+	envPrefix = "GMAPS_"
+)
+
+// flagAliases are the keys of the configuration files and the environment
+// variables of the flags with a short name
+var flagAliases = map[string]string{
+	"concurrency": "c",
+}
+
+// applyConfigFile sets the flags of fs to the values of the configuration file
+// of -config in args, then to the environment variables GMAPS_<FLAG>, e.g.
+// GMAPS_FAST_MODE for -fast-mode. The flags of args override both once they
+// are parsed. It returns the queries of the file.
+func applyConfigFile(fs *flag.FlagSet, args []string) ([]string, error) {
+	var queries []string
+
+	if path := configPath(args); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			if key == queriesKey {
+				if queries, err = configList(values[key]); err != nil {
+					return nil, fmt.Errorf("invalid config %s: %s: %w", path, key, err)
+				}
+
+				continue
+			}
+
+			name := key
+			if alias, ok := flagAliases[key]; ok {
+				name = alias
+			}
+
+			if fs.Lookup(name) == nil || name == configFlag {
+				return nil, fmt.Errorf("invalid config %s: unknown option %q", path, key)
+			}
+
+			value, err := configValue(values[key])
+			if err == nil {
+				err = fs.Set(name, value)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid config %s: %s: %w", path, key, err)
+			}
+		}
+	}
+
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || f.Name == configFlag {
+			return
+		}
+
+		names := []string{f.Name}
+		for alias, name := range flagAliases {
+			if name == f.Name {
+				names = append(names, alias)
+			}
+		}
+
+		for _, name := range names {
+			key := envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+			if value, ok := os.LookupEnv(key); ok {
+				if serr := fs.Set(f.Name, value); serr != nil {
+					err = fmt.Errorf("invalid environment variable %s: %w", key, serr)
+				}
+			}
+		}
+	})
+
+	return queries, err
+}
+
+// configPath returns the value of -config in args, before they are parsed
+func configPath(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != configFlag {
+			continue
+		}
+
+		if hasValue {
+			return value
+		}
+
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
+
+// readConfigFile reads the options of a YAML file, or of a TOML file when its
+// extension is .toml
+func readConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	values := make(map[string]any)
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		_, err = toml.NewDecoder(bytes.NewReader(data)).Decode(&values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// configValue returns the flag value of an option, the items of a list are
+// joined with commas like the flags taking lists
+func configValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items, err := configList(v)
+
+		return strings.Join(items, ","), err
+	case map[string]any:
+		return "", errors.New("a table is not a valid value")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func configList(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("must be a list")
+	}
+
+	ans := make([]string, 0, len(list))
+
+	for _, item := range list {
+		switch item.(type) {
+		case []any, map[string]any:
+			return nil, errors.New("the items of a list must be values")
+		}
+
+		ans = append(ans, fmt.Sprint(item))
+	}
+
+	return ans, nil
+}
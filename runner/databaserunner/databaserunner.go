@@ -3,6 +3,7 @@ package databaserunner
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,7 +11,9 @@ import (
 	// postgres driver
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"github.com/gosom/google-maps-scraper/gmaps"
 	"github.com/gosom/google-maps-scraper/postgres"
+	"github.com/gosom/google-maps-scraper/redisqueue"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/tlmt"
 	"github.com/gosom/scrapemate"
@@ -20,8 +23,9 @@ import (
 type dbrunner struct {
 	cfg      *runner.Config
 	provider scrapemate.JobProvider
+	queue    *redisqueue.Provider
 	produce  bool
-	app      *scrapemateapp.ScrapemateApp
+	app      *runner.App
 	conn     *sql.DB
 }
 
@@ -35,9 +39,43 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
+	if err := runner.SetupRedis(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupBloom(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupState(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
+	var (
+		provider scrapemate.JobProvider
+		queue    *redisqueue.Provider
+	)
+
+	if cfg.Queue != "" {
+		queue, err = runner.NewRedisQueue(context.Background(), cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		provider = queue
+	} else {
+		var provOpts []postgres.ProviderOption
+		if cfg.Deduper != nil {
+			provOpts = append(provOpts, postgres.WithDeduper(cfg.Deduper))
+		}
+
+		provider = postgres.NewProvider(conn, provOpts...)
+	}
+
 	ans := dbrunner{
 		cfg:      cfg,
-		provider: postgres.NewProvider(conn),
+		provider: provider,
+		queue:    queue,
 		produce:  cfg.ProduceOnly,
 		conn:     conn,
 	}
@@ -46,6 +84,28 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return &ans, nil
 	}
 
+	runner.SetupFilters(cfg)
+
+	if err := runner.SetupDedup(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupTaxonomy(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupGeocoder(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupIsochrone(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupProcessors(cfg); err != nil {
+		return nil, err
+	}
+
 	psqlWriter := postgres.NewResultWriter(conn)
 
 	writers := []scrapemate.ResultWriter{
@@ -93,7 +153,7 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
-	ans.app, err = scrapemateapp.NewScrapeMateApp(matecfg)
+	ans.app, err = runner.NewApp(matecfg, cfg.AppOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,19 +168,42 @@ func (d *dbrunner) Run(ctx context.Context) error {
 		return d.produceSeedJobs(ctx)
 	}
 
-	return d.app.Start(ctx)
+	err := d.app.Start(ctx)
+
+	// the jobs in progress are processed by the other workers
+	if d.queue != nil {
+		if rerr := d.queue.Release(context.WithoutCancel(ctx)); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+
+	if serr := runner.SaveBloom(d.cfg); serr != nil && err == nil {
+		err = serr
+	}
+
+	if cerr := runner.CloseDedup(d.cfg); cerr != nil && err == nil {
+		err = cerr
+	}
+
+	if cerr := runner.CloseState(d.cfg); cerr != nil && err == nil {
+		err = cerr
+	}
+
+	return err
 }
 
 func (d *dbrunner) Close(context.Context) error {
+	perr := runner.CloseProcessors(d.cfg)
+
 	if d.app != nil {
-		return d.app.Close()
+		return errors.Join(perr, d.app.Close())
 	}
 
 	if d.conn != nil {
-		return d.conn.Close()
+		return errors.Join(perr, d.conn.Close())
 	}
 
-	return nil
+	return perr
 }
 
 func (d *dbrunner) produceSeedJobs(ctx context.Context) error {
@@ -140,20 +223,42 @@ func (d *dbrunner) produceSeedJobs(ctx context.Context) error {
 		input = f
 	}
 
-	jobs, err := runner.CreateSeedJobs(
-		d.cfg.FastMode,
-		d.cfg.LangCode,
-		input,
-		d.cfg.MaxDepth,
-		d.cfg.Email,
-		d.cfg.GeoCoordinates,
-		d.cfg.Zoom,
-		d.cfg.Radius,
-		nil,
-		nil,
-		d.cfg.ExtraReviews,
-		d.cfg.ValidatePlaceIdUrl,
-	)
+	jobs, err := runner.CreateSeedJobs(input, runner.SeedJobsOptions{
+		FastMode:           d.cfg.FastMode,
+		LangCode:           d.cfg.LangCode,
+		MaxDepth:           d.cfg.MaxDepth,
+		Email:              d.cfg.Email,
+		GeoCoordinates:     d.cfg.GeoCoordinates,
+		Zoom:               d.cfg.Zoom,
+		Radius:             d.cfg.Radius,
+		ExtraReviews:       d.cfg.ExtraReviews,
+		ValidatePlaceIdUrl: d.cfg.ValidatePlaceIdUrl,
+		Sample:             d.cfg.Sample,
+		QuarantineDir:      d.cfg.QuarantineDir,
+		Nearest:            gmaps.NewNearest(d.cfg.Nearest),
+		EmailPages:         d.cfg.EmailPages,
+		ExtraPosts:         d.cfg.ExtraPosts,
+		ExtraProducts:      d.cfg.ExtraProducts,
+		ExtraMenu:          d.cfg.ExtraMenu,
+		ExtraQuestions:     d.cfg.ExtraQuestions,
+		ExtraHotels:        d.cfg.Hotels,
+		Completeness:       d.cfg.Completeness,
+		Pages:              d.cfg.Pages,
+		Polygon:            d.cfg.Area,
+		EmailFetcher:       d.cfg.EmailFetcher,
+		Lookup:             d.cfg.Lookup,
+		BrowserFallback:    d.cfg.BrowserFallback,
+		Headers:            d.cfg.Headers,
+		ReviewLimits:       d.cfg.ReviewLimits,
+		Viewport:           d.cfg.Viewport,
+		Grid:               d.cfg.Grid,
+		Rings:              d.cfg.Rings,
+		Route:              d.cfg.Route,
+		Gl:                 d.cfg.Gl,
+		GoogleDomain:       d.cfg.GoogleDomain,
+		CategorySearch:     d.cfg.CategorySearch,
+		DeterministicIDs:   d.cfg.DeterministicIDs,
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,168 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+const sqliteStorePrefix = "sqlite:"
+
+// SetupDedup registers the after parse functions of cfg.Middleware that drop
+// the known places of cfg.Incremental and the places already found by another
+// query or location of the run. The places are keyed by their CID, or their
+// place id when they have none. With cfg.DedupStore the places seen by the
+// previous runs and by the other scrapers of the store are dropped too.
+// It must run before the other setups but SetupFilters, the duplicates skip
+// their processors.
+func SetupDedup(ctx context.Context, cfg *Config) error {
+	switch cfg.DedupStore {
+	case "":
+	case StateStoreRef:
+		store, err := cfg.State.Set(ctx, dedupSet, cfg.IncrementalTTL)
+		if err != nil {
+			return fmt.Errorf("cannot open the dedup store: %w", err)
+		}
+
+		cfg.Seen = store
+	default:
+		store, err := OpenDedupStore(ctx, cfg.DedupStore, cfg.IncrementalTTL)
+		if err != nil {
+			return err
+		}
+
+		cfg.Seen = store
+	}
+
+	switch cfg.Incremental {
+	case "":
+	case IncrementalStore:
+		cfg.Known = cfg.Seen
+	default:
+		known, err := LoadKnown(cfg.Incremental, cfg.IncrementalTTL)
+		if err != nil {
+			return err
+		}
+
+		cfg.Known = known
+	}
+
+	var fns []gmaps.AfterParseFunc
+
+	// the known places are dropped before they are added to the store
+	if cfg.Known != nil {
+		fns = append(fns, SkipKnown(cfg.Known))
+	}
+
+	if !cfg.KeepDuplicates {
+		var dedup deduper.Deduper = deduper.New()
+		if cfg.Seen != nil {
+			dedup = cfg.Seen
+		}
+
+		fns = append(fns, DedupEntries(dedup, cfg.ExtraLangs))
+	}
+
+	if len(fns) == 0 {
+		return nil
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(fns...)
+
+	return nil
+}
+
+// OpenDedupStore opens the store of the seen places, sqlite:<path> or a
+// redis url: the set seen of the state store of dsn. The places seen before
+// ttl are expired, 0 keeps them forever.
+func OpenDedupStore(ctx context.Context, dsn string, ttl time.Duration) (deduper.Store, error) {
+	if !strings.HasPrefix(dsn, sqliteStorePrefix) && !isRedisURL(dsn) {
+		return nil, fmt.Errorf("invalid dedup store, expected sqlite:<path>, a redis url or state: %s", dsn)
+	}
+
+	store, err := state.Open(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the dedup store: %w", err)
+	}
+
+	set, err := store.Set(ctx, dedupSet, ttl)
+	if err != nil {
+		_ = store.Close()
+
+		return nil, fmt.Errorf("cannot open the dedup store: %w", err)
+	}
+
+	return storeSet{Store: set, store: store}, nil
+}
+
+// storeSet is the set of a state store opened for it, the store is closed
+// with the set
+type storeSet struct {
+	deduper.Store
+	store state.Store
+}
+
+func (s storeSet) Close() error {
+	return errors.Join(s.Store.Close(), s.store.Close())
+}
+
+// CloseDedup closes the store opened by SetupDedup
+func CloseDedup(cfg *Config) error {
+	if cfg.Seen == nil {
+		return nil
+	}
+
+	return cfg.Seen.Close()
+}
+
+// DedupEntries returns an after parse function that skips the entries whose
+// key was already added to dedup. The entries in one of the extra languages
+// merged by MergeLanguages are keyed by their language too.
+func DedupEntries(dedup deduper.Deduper, extraLangs []string) gmaps.AfterParseFunc {
+	return func(ctx context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		key := entry.Key()
+		if key == "" {
+			return nil
+		}
+
+		if slices.Contains(extraLangs, entry.Language) {
+			key += "@" + entry.Language
+		}
+
+		if !dedup.AddIfNotExists(ctx, key) {
+			slog.Debug("duplicate place skipped", "key", key, "title", entry.Title, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
+
+// SkipKnown returns an after parse function that skips the entries of the
+// known places
+func SkipKnown(known gmaps.Known) gmaps.AfterParseFunc {
+	return func(ctx context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		if gmaps.KnownEntry(ctx, known, entry) {
+			slog.Debug("known place skipped", "key", entry.Key(), "title", entry.Title, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
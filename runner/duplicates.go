@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// MergeDuplicates returns a writer that merges the duplicate listings of the
+// same place, the entries with the same phone or with the same name within
+// cfg.MergeDistance meters, into one entry before passing them to next. The
+// entry with the most reviews is kept, with the others in MergedFrom. The
+// entries are written when the results end, every entry can be the duplicate
+// of one found later.
+func MergeDuplicates(cfg *Config, next scrapemate.ResultWriter) scrapemate.ResultWriter {
+	if !cfg.MergeDuplicates {
+		return next
+	}
+
+	return &duplicateMerger{
+		distance: cfg.MergeDistance,
+		next:     next,
+	}
+}
+
+type duplicateMerger struct {
+	distance float64
+	next     scrapemate.ResultWriter
+}
+
+// mergedPlace is the entry of a place, with the job of its first listing
+type mergedPlace struct {
+	job   scrapemate.IJob
+	entry *gmaps.Entry
+}
+
+// listing is a listing merged into a place, its location is compared to the
+// listings of the same name
+type listing struct {
+	entry *gmaps.Entry
+	place *mergedPlace
+}
+
+func (m *duplicateMerger) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- m.next.Run(ctx, out)
+	}()
+
+	var (
+		places []*mergedPlace
+		// the places by the phones of their listings, and the listings by
+		// name
+		byPhone = make(map[string]*mergedPlace)
+		byName  = make(map[string][]listing)
+	)
+
+	send := func(result scrapemate.Result) error {
+		select {
+		case out <- result:
+			return nil
+		case err := <-done:
+			// keep consuming so that the producer does not block
+			go func() {
+				for range in {
+				}
+			}()
+
+			return err
+		}
+	}
+
+	// duplicate returns the place the entry is a duplicate listing of
+	duplicate := func(entry *gmaps.Entry) (*mergedPlace, string) {
+		if p, ok := byPhone[entry.PhoneKey()]; ok {
+			return p, gmaps.DuplicatePhone
+		}
+
+		if entry.Latitude == 0 && entry.Longtitude == 0 {
+			return nil, ""
+		}
+
+		for _, l := range byName[entry.NameKey()] {
+			if l.entry.DistanceTo(entry) <= m.distance {
+				return l.place, gmaps.DuplicateName
+			}
+		}
+
+		return nil, ""
+	}
+
+	add := func(job scrapemate.IJob, entry *gmaps.Entry) {
+		p, reason := duplicate(entry)
+
+		switch {
+		case p == nil:
+			p = &mergedPlace{job: job, entry: entry}
+			places = append(places, p)
+		case entry.ReviewCount > p.entry.ReviewCount:
+			// the listing with the most reviews is the main one
+			entry.MergeDuplicate(p.entry, reason)
+			p.entry = entry
+		default:
+			p.entry.MergeDuplicate(entry, reason)
+		}
+
+		if key := entry.PhoneKey(); key != "" {
+			if _, ok := byPhone[key]; !ok {
+				byPhone[key] = p
+			}
+		}
+
+		if key := entry.NameKey(); key != "" && (entry.Latitude != 0 || entry.Longtitude != 0) {
+			byName[key] = append(byName[key], listing{entry: entry, place: p})
+		}
+	}
+
+	for result := range in {
+		var err error
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			add(result.Job, data)
+		case []*gmaps.Entry:
+			for i := range data {
+				add(result.Job, data[i])
+			}
+		default:
+			err = send(result)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range places {
+		if err := send(scrapemate.Result{Job: p.job, Data: p.entry}); err != nil {
+			return err
+		}
+	}
+
+	close(out)
+
+	return <-done
+}
@@ -0,0 +1,43 @@
+package filerunner
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// newCompressor returns the writer compressing the results to w with codec,
+// gzip or zstd. The results are compressed while they are written, it must
+// be closed to write the end of the stream. A resumed run appends a new
+// gzip member or zstd frame, the decompressors read them as one stream.
+func newCompressor(w io.Writer, codec string) (io.WriteCloser, error) {
+	switch codec {
+	case runner.CompressGzip:
+		return gzip.NewWriter(w), nil
+	case runner.CompressZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("invalid compression: %s", codec)
+	}
+}
+
+// closeResults writes the end of the compressed results, it is called once
+// the writers are done
+func (r *fileRunner) closeResults() error {
+	if r.compressor == nil {
+		return nil
+	}
+
+	err := r.compressor.Close()
+	r.compressor = nil
+
+	if err != nil {
+		return fmt.Errorf("cannot compress the results: %w", err)
+	}
+
+	return nil
+}
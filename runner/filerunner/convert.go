@@ -0,0 +1,240 @@
+package filerunner
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// convertRunner writes the entries of a previous run again with the writers of
+// the configuration, without scraping. The recorded responses are parsed again
+// so that the fixes of the parser apply to them.
+type convertRunner struct {
+	fileRunner
+}
+
+// NewConvert returns the runner of the convert command
+func NewConvert(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeConvert {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	ans := &convertRunner{fileRunner: fileRunner{cfg: cfg}}
+
+	if err := ans.setWriters(); err != nil {
+		return nil, err
+	}
+
+	return ans, nil
+}
+
+func (r *convertRunner) Run(ctx context.Context) error {
+	chans := make([]chan scrapemate.Result, len(r.writers))
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	for i, w := range r.writers {
+		chans[i] = make(chan scrapemate.Result)
+
+		g.Go(func() error {
+			return w.Run(gctx, chans[i])
+		})
+	}
+
+	// the same place is in the search results and in its own page
+	seen := make(map[string]bool)
+	count := 0
+
+	emit := func(entries []*gmaps.Entry) error {
+		for _, e := range entries {
+			key := e.DataID
+			if key == "" {
+				key = e.Link
+			}
+
+			if key != "" && seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			count++
+
+			for _, ch := range chans {
+				select {
+				case ch <- scrapemate.Result{Data: e}:
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+			}
+		}
+
+		return nil
+	}
+
+	err := r.read(gctx, emit)
+
+	for _, ch := range chans {
+		close(ch)
+	}
+
+	if werr := g.Wait(); err == nil {
+		err = werr
+	}
+
+	if cerr := r.closeResults(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	slog.Info("convert: places written", "places", count)
+
+	return nil
+}
+
+// read calls emit with the entries of the source
+func (r *convertRunner) read(ctx context.Context, emit func([]*gmaps.Entry) error) error {
+	from := r.cfg.ConvertFrom
+
+	if from == "postgres" {
+		return readPostgres(ctx, r.cfg.Dsn, emit)
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+
+	readResponses := fetcher.ReadFixtures
+
+	switch {
+	case isDump(from):
+		readResponses = fetcher.ReadDump
+	case !info.IsDir():
+		return readResultsFile(from, emit)
+	}
+
+	var failed int
+
+	err = readResponses(from, func(resp scrapemate.Response) error {
+		entries, err := gmaps.EntriesFromResponse(&resp)
+		if err != nil {
+			slog.Warn("convert: response skipped", "url", resp.URL, "error", err)
+
+			failed++
+
+			return nil
+		}
+
+		return emit(entries)
+	})
+
+	if failed > 0 {
+		slog.Warn("convert: responses could not be parsed", "responses", failed)
+	}
+
+	return err
+}
+
+// isDump reports whether path is an archive of the responses written with
+// -dump-raw
+func isDump(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// readResultsFile reads the entries of a -json or a -output file: a stream of
+// entries and, for the fast mode with -json, of arrays of entries
+func readResultsFile(path string, emit func([]*gmaps.Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid results file %s: %w", path, err)
+		}
+
+		var entries []*gmaps.Entry
+
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			err = json.Unmarshal(raw, &entries)
+		} else {
+			var e gmaps.Entry
+
+			err = json.Unmarshal(raw, &e)
+			entries = []*gmaps.Entry{&e}
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid entry in %s: %w", path, err)
+		}
+
+		if err := emit(entries); err != nil {
+			return err
+		}
+	}
+}
+
+// readPostgres reads the entries saved by the database runner
+func readPostgres(ctx context.Context, dsn string, emit func([]*gmaps.Entry) error) error {
+	if dsn == "" {
+		return errors.New("convert from postgres requires -dsn")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `SELECT data FROM results ORDER BY id`)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+
+		var e gmaps.Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("invalid entry in the results: %w", err)
+		}
+
+		if err := emit([]*gmaps.Entry{&e}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,73 @@
+package filerunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/estimate"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// dryRun writes the plans of the seed jobs of the input to w as JSON lines and
+// logs the estimated size of the run. Nothing is fetched.
+func (r *fileRunner) dryRun(w io.Writer) error {
+	jobs, err := r.createSeedJobs(deduper.New(), exiter.New())
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+
+	var (
+		requests int
+		kinds    = make(map[string]int)
+		queries  = make(map[string]struct{})
+	)
+
+	for _, job := range jobs {
+		plan := gmaps.PlanJob(job)
+
+		if err := enc.Encode(plan); err != nil {
+			return fmt.Errorf("cannot write the plan: %w", err)
+		}
+
+		requests += plan.Requests
+		kinds[plan.Kind]++
+		queries[plan.Query] = struct{}{}
+	}
+
+	args := []any{"jobs", len(jobs), "queries", len(queries), "search_requests", requests}
+	for _, kind := range []string{gmaps.PlanSearch, gmaps.PlanBrowserSearch, gmaps.PlanLookup} {
+		if kinds[kind] > 0 {
+			args = append(args, kind, kinds[kind])
+		}
+	}
+
+	// the seed jobs of a query are the tiles planned for its area
+	if est, err := estimate.EstimateRun(estimate.Params{
+		Queries:       len(queries),
+		TilesPerQuery: len(jobs) / max(len(queries), 1),
+		FastMode:      r.cfg.FastMode,
+		Depth:         r.cfg.MaxDepth,
+		Pages:         r.cfg.Pages,
+		Email:         r.cfg.Email,
+		EmailPages:    r.cfg.EmailPages,
+		ExtraReviews:  r.cfg.ExtraReviews,
+		Concurrency:   r.cfg.Concurrency,
+	}); err == nil {
+		args = append(args,
+			"estimated_places", est.Places,
+			"estimated_requests", est.Requests,
+			"estimated_bandwidth_mb", est.BandwidthBytes/(1<<20),
+			"estimated_duration", est.Duration.String(),
+		)
+	}
+
+	slog.Info("dry run: nothing was fetched", args...)
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package filerunner
+
+import (
+	"io"
+
+	"github.com/gosom/scrapemate"
+)
+
+// NewSQLiteWriter exposes newSQLiteWriter to the tests
+func NewSQLiteWriter(path string) (scrapemate.ResultWriter, error) {
+	return newSQLiteWriter(path)
+}
+
+// NewXLSXWriter exposes newXLSXWriter to the tests
+func NewXLSXWriter(w io.Writer) scrapemate.ResultWriter {
+	return newXLSXWriter(w)
+}
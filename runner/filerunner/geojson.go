@@ -0,0 +1,149 @@
+package filerunner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*geojsonWriter)(nil)
+
+// geojsonWriter writes the entries as a GeoJSON FeatureCollection of points.
+// The fields of the entries, or only the ones selected, are the properties.
+// Entries without coordinates are skipped.
+type geojsonWriter struct {
+	w      *bufio.Writer
+	fields []string
+	count  int
+}
+
+func newGeoJSONWriter(w io.Writer, fields []string) *geojsonWriter {
+	return &geojsonWriter{w: bufio.NewWriter(w), fields: fields}
+}
+
+type geojsonFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+func (g *geojsonWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if _, err := g.w.WriteString(`{"type":"FeatureCollection","features":[`); err != nil {
+		return err
+	}
+
+	for result := range in {
+		var items []any
+
+		switch data := result.Data.(type) {
+		case []*gmaps.Entry:
+			for _, entry := range data {
+				items = append(items, entry)
+			}
+		case nil:
+			continue
+		default:
+			items = append(items, data)
+		}
+
+		for _, item := range items {
+			if err := g.write(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := g.w.WriteString("\n]}\n"); err != nil {
+		return err
+	}
+
+	return g.w.Flush()
+}
+
+func (g *geojsonWriter) write(item any) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	var props map[string]any
+	if err := json.Unmarshal(data, &props); err != nil {
+		return fmt.Errorf("cannot convert the result to a feature: %w", err)
+	}
+
+	lat, okLat := coordinate(props["latitude"])
+
+	lon, okLon := coordinate(props["longtitude"])
+	if !okLon {
+		lon, okLon = coordinate(props["longitude"])
+	}
+
+	if !okLat || !okLon || (lat == 0 && lon == 0) {
+		return nil
+	}
+
+	delete(props, "latitude")
+	delete(props, "longtitude")
+	delete(props, "longitude")
+
+	if len(g.fields) > 0 {
+		subset := make(map[string]any, len(g.fields))
+
+		for _, f := range g.fields {
+			if v, ok := props[f]; ok {
+				subset[f] = v
+			}
+		}
+
+		props = subset
+	}
+
+	feature := geojsonFeature{Type: "Feature", Properties: props}
+	feature.Geometry.Type = "Point"
+	// GeoJSON positions are longitude first
+	feature.Geometry.Coordinates = [2]float64{lon, lat}
+
+	encoded, err := json.Marshal(feature)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if g.count == 0 {
+		sep = "\n"
+	}
+
+	g.count++
+
+	if _, err := g.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	_, err = g.w.Write(encoded)
+
+	return err
+}
+
+// coordinate reads a coordinate of an entry, or of a mapped record where it is a string
+func coordinate(v any) (float64, bool) {
+	switch c := v.(type) {
+	case float64:
+		return c, true
+	case string:
+		f, err := strconv.ParseFloat(c, 64)
+
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
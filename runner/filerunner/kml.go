@@ -0,0 +1,207 @@
+package filerunner
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*kmlWriter)(nil)
+
+// kmlPalette are the icon colors of the categories, in the aabbggrr order of KML
+var kmlPalette = []string{
+	"ff3c14dc", // red
+	"ffe16941", // blue
+	"ff32cd32", // green
+	"ff008cff", // orange
+	"ffd30094", // purple
+	"ffd1ce00", // turquoise
+	"ff9314ff", // pink
+	"ff00d7ff", // gold
+	"ff2d52a0", // brown
+	"ff808080", // grey
+}
+
+// kmlWriter writes the entries as a KML document, or a KMZ archive, with a
+// folder and an icon color per category and the details of the places in
+// their balloons. The placemarks are written when the results end since the
+// styles come first in the document.
+type kmlWriter struct {
+	w   io.Writer
+	kmz bool
+
+	placemarks map[string][]kmlPlacemark
+}
+
+func newKMLWriter(w io.Writer, kmz bool) *kmlWriter {
+	return &kmlWriter{w: w, kmz: kmz, placemarks: make(map[string][]kmlPlacemark)}
+}
+
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name    string      `xml:"name"`
+		Styles  []kmlStyle  `xml:"Style"`
+		Folders []kmlFolder `xml:"Folder"`
+	} `xml:"Document"`
+}
+
+type kmlStyle struct {
+	ID        string `xml:"id,attr"`
+	IconStyle struct {
+		Color string `xml:"color"`
+		Icon  struct {
+			Href string `xml:"href"`
+		} `xml:"Icon"`
+	} `xml:"IconStyle"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description,omitempty"`
+	StyleURL    string `xml:"styleUrl"`
+	Point       struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point"`
+}
+
+func (k *kmlWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			k.add(data)
+		case []*gmaps.Entry:
+			for _, entry := range data {
+				k.add(entry)
+			}
+		}
+	}
+
+	if !k.kmz {
+		return k.write(k.w)
+	}
+
+	zw := zip.NewWriter(k.w)
+
+	// doc.kml is the document Google Earth opens in a KMZ
+	f, err := zw.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+
+	if err := k.write(f); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (k *kmlWriter) add(e *gmaps.Entry) {
+	if e.Latitude == 0 && e.Longtitude == 0 {
+		return
+	}
+
+	category := e.Category
+	if category == "" {
+		category = "Other"
+	}
+
+	p := kmlPlacemark{Name: e.Title, Description: kmlBalloon(e)}
+	p.Point.Coordinates = strconv.FormatFloat(e.Longtitude, 'f', -1, 64) + "," + strconv.FormatFloat(e.Latitude, 'f', -1, 64)
+
+	k.placemarks[category] = append(k.placemarks[category], p)
+}
+
+func (k *kmlWriter) write(w io.Writer) error {
+	const iconHref = "http://maps.google.com/mapfiles/kml/pushpin/wht-pushpin.png"
+
+	categories := make([]string, 0, len(k.placemarks))
+	for category := range k.placemarks {
+		categories = append(categories, category)
+	}
+
+	sort.Strings(categories)
+
+	doc := kmlDocument{Xmlns: "http://www.opengis.net/kml/2.2"}
+	doc.Document.Name = "Google Maps places"
+
+	for i, category := range categories {
+		style := kmlStyle{ID: "category-" + strconv.Itoa(i)}
+		style.IconStyle.Color = kmlPalette[i%len(kmlPalette)]
+		style.IconStyle.Icon.Href = iconHref
+
+		placemarks := k.placemarks[category]
+		for j := range placemarks {
+			placemarks[j].StyleURL = "#" + style.ID
+		}
+
+		doc.Document.Styles = append(doc.Document.Styles, style)
+		doc.Document.Folders = append(doc.Document.Folders, kmlFolder{Name: category, Placemarks: placemarks})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("cannot encode kml: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// kmlBalloon returns the HTML shown when a placemark is clicked
+func kmlBalloon(e *gmaps.Entry) string {
+	var sb strings.Builder
+
+	row := func(label, value string) {
+		if value != "" {
+			sb.WriteString("<b>" + label + ":</b> " + html.EscapeString(value) + "<br>")
+		}
+	}
+
+	row("Category", e.Category)
+	row("Address", e.Address)
+	row("Phone", e.Phone)
+
+	if e.ReviewRating > 0 {
+		row("Rating", fmt.Sprintf("%.1f (%d reviews)", e.ReviewRating, e.ReviewCount))
+	}
+
+	row("Status", e.Status)
+
+	if e.DistanceM > 0 {
+		row("Distance", fmt.Sprintf("%.0f m", e.DistanceM))
+	}
+
+	if e.WebSite != "" {
+		sb.WriteString(`<a href="` + html.EscapeString(e.WebSite) + `">Website</a><br>`)
+	}
+
+	if e.Link != "" {
+		sb.WriteString(`<a href="` + html.EscapeString(e.Link) + `">Google Maps</a>`)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package filerunner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// manifest describes a run so that its results can be audited and the run
+// reproduced: the binary, the flags, the input, the outcome of every query
+// and the checksums of the files written
+type manifest struct {
+	Build      runner.BuildVersion       `json:"build"`
+	StartedAt  time.Time                 `json:"started_at"`
+	FinishedAt time.Time                 `json:"finished_at"`
+	Duration   string                    `json:"duration"`
+	Flags      map[string]string         `json:"flags"`
+	Input      manifestFile              `json:"input"`
+	Parser     manifestParser            `json:"parser"`
+	Progress   exiter.Progress           `json:"progress"`
+	Queries    []exiter.QueryStats       `json:"queries"`
+	Failures   map[exiter.ErrorClass]int `json:"failures"`
+	Outputs    []manifestFile            `json:"outputs"`
+	// PlacesRaw counts a place once per search that found it, PlacesUnique
+	// once
+	PlacesRaw    int `json:"places_raw"`
+	PlacesUnique int `json:"places_unique"`
+	// Error is why the run failed or was interrupted
+	Error string `json:"error,omitempty"`
+}
+
+// manifestFile is a file read or written by the run, its checksum is empty
+// when it is not a file, e.g. stdin
+type manifestFile struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+type manifestParser struct {
+	// Version is the version of -parser, auto tries Versions in order
+	Version  string   `json:"version"`
+	Versions []string `json:"versions"`
+}
+
+// writeManifest writes the manifest of the run to the file of -manifest, once
+// the results files are closed
+func (r *fileRunner) writeManifest(start time.Time, monitor exiter.Exiter, runErr error) error {
+	if r.cfg.Manifest == "" {
+		return nil
+	}
+
+	finished := time.Now().UTC()
+	summary := monitor.Summary()
+
+	m := manifest{
+		Build:      runner.Build(),
+		StartedAt:  start,
+		FinishedAt: finished,
+		Duration:   finished.Sub(start).Round(time.Millisecond).String(),
+		Flags:      runner.EffectiveFlags(),
+		Input:      r.manifestInput(),
+		Parser: manifestParser{
+			Version:  r.cfg.ParserVersion,
+			Versions: gmaps.SearchParserVersions(),
+		},
+		Progress:     monitor.Progress(),
+		PlacesRaw:    summary.PlacesRaw,
+		PlacesUnique: summary.PlacesUnique,
+		Queries:      summary.Queries,
+		Failures:     summary.Failures,
+	}
+
+	if runErr != nil {
+		m.Error = runErr.Error()
+	}
+
+	for _, name := range r.uploadFiles() {
+		if name == r.cfg.Manifest {
+			continue
+		}
+
+		f, err := checksum(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the run failed before writing it
+			continue
+		} else if err != nil {
+			return fmt.Errorf("cannot write the manifest: %w", err)
+		}
+
+		m.Outputs = append(m.Outputs, f)
+	}
+
+	data, err := json.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot write the manifest: %w", err)
+	}
+
+	if err := os.WriteFile(r.cfg.Manifest, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cannot write the manifest: %w", err)
+	}
+
+	slog.Info("manifest written", "path", r.cfg.Manifest)
+
+	return nil
+}
+
+// manifestInput returns the input of the run: the input file with its
+// checksum, stdin, or the queries of the config file
+func (r *fileRunner) manifestInput() manifestFile {
+	switch r.cfg.InputFile {
+	case "stdin":
+		return manifestFile{Path: "stdin"}
+	case "":
+		return manifestFile{Path: "config"}
+	}
+
+	f, err := checksum(r.cfg.InputFile)
+	if err != nil {
+		slog.Warn("cannot checksum the input of the manifest", "error", err)
+
+		return manifestFile{Path: r.cfg.InputFile}
+	}
+
+	return f
+}
+
+// checksum returns the size and the SHA-256 of the file of name
+func checksum(name string) (manifestFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return manifestFile{}, err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return manifestFile{}, err
+	}
+
+	return manifestFile{Path: name, Bytes: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
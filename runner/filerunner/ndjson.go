@@ -0,0 +1,63 @@
+package filerunner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*ndjsonWriter)(nil)
+
+// ndjsonWriter writes every entry as a line of JSON as soon as it is received.
+// The fast mode results are split into one line per entry. Every line is
+// flushed on its own, so a crash loses at most the entry being written.
+//
+// The lines are written synchronously: while the reader of w is slow the
+// writer does not receive more results, and since scrapemate sends the results
+// unbuffered the workers wait too instead of piling results in memory.
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	bw := bufio.NewWriter(w)
+
+	return &ndjsonWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (n *ndjsonWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch data := result.Data.(type) {
+		case []*gmaps.Entry:
+			for _, entry := range data {
+				if err := n.write(entry); err != nil {
+					return err
+				}
+			}
+		case nil:
+			continue
+		default:
+			if err := n.write(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return n.w.Flush()
+}
+
+// write encodes v as a line and flushes it so that it reaches the pipe or the
+// file right away
+func (n *ndjsonWriter) write(v any) error {
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+
+	return n.w.Flush()
+}
@@ -0,0 +1,77 @@
+package filerunner
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// queuedJobs are the jobs left in the queue by a previous run
+type queuedJobs struct {
+	seeds  int
+	places int
+}
+
+// setQueue connects to the queue of -queue. The run resumes the jobs left in
+// the queue, its seeds are not pushed and the results are appended.
+func (r *fileRunner) setQueue(ctx context.Context) error {
+	var err error
+
+	r.queue, err = runner.NewRedisQueue(ctx, r.cfg)
+	if err != nil {
+		return err
+	}
+
+	counts, err := r.queue.Counts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for payloadType, n := range counts {
+		switch payloadType {
+		case gmaps.PayloadSearch, gmaps.PayloadFastSearch, gmaps.PayloadLookup, gmaps.PayloadImage:
+			r.queued.seeds += n
+		default:
+			// the jobs of the places complete them
+			r.queued.places += n
+		}
+	}
+
+	if r.resumed() {
+		slog.Info("queue: the jobs of the previous run are resumed", "seeds", r.queued.seeds, "places", r.queued.places)
+	}
+
+	return nil
+}
+
+// runtime is the state of the run set on the jobs decoded by the queues
+func (r *fileRunner) runtime(dedup deduper.Deduper, exitMonitor exiter.Exiter) gmaps.Runtime {
+	return gmaps.Runtime{
+		Deduper:      dedup,
+		ExitMonitor:  exitMonitor,
+		Known:        r.cfg.Known,
+		Images:       r.cfg.Images,
+		EmailFetcher: r.cfg.EmailFetcher,
+		Nearest:      r.nearest,
+	}
+}
+
+func (r *fileRunner) resumed() bool {
+	return r.queued.seeds+r.queued.places > 0
+}
+
+// finishQueue removes the last jobs of the workers when the run completed,
+// they are pushed back to the queue when it is interrupted
+func (r *fileRunner) finishQueue(exitMonitor exiter.Exiter) error {
+	ctx := context.Background()
+
+	if p := exitMonitor.Progress(); p.SeedCompleted == p.SeedCount && p.PlacesCompleted == p.PlacesFound {
+		return r.queue.Complete(ctx)
+	}
+
+	return r.queue.Release(ctx)
+}
@@ -0,0 +1,58 @@
+package filerunner
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// createResults creates a results file. When the run is resumed, from its
+// checkpoint or from the queue, the file is appended to, appended reports
+// whether it already has results.
+func (r *fileRunner) createResults(path string) (f *os.File, appended bool, err error) {
+	if !r.cfg.Resume && !r.resumed() {
+		f, err = os.Create(path)
+
+		return f, false, err
+	}
+
+	f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, false, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return nil, false, err
+	}
+
+	return f, info.Size() > 0, nil
+}
+
+// headerSkipper drops the first line written to w, the CSV headers that the
+// results file of a resumed run already has
+type headerSkipper struct {
+	w       io.Writer
+	skipped bool
+}
+
+func (h *headerSkipper) Write(p []byte) (int, error) {
+	if h.skipped {
+		return h.w.Write(p)
+	}
+
+	i := bytes.IndexByte(p, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	h.skipped = true
+
+	if _, err := h.w.Write(p[i+1:]); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,285 @@
+package filerunner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	_ "modernc.org/sqlite" // sqlite driver
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*sqliteWriter)(nil)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	command TEXT NOT NULL,
+	started_at INTEGER NOT NULL,
+	finished_at INTEGER,
+	entries INTEGER NOT NULL DEFAULT 0,
+	reviews INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	id TEXT PRIMARY KEY,
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	cid TEXT NOT NULL,
+	data_id TEXT NOT NULL,
+	link TEXT NOT NULL,
+	title TEXT NOT NULL,
+	category TEXT NOT NULL,
+	address TEXT NOT NULL,
+	website TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	review_count INTEGER NOT NULL,
+	review_rating REAL NOT NULL,
+	latitude REAL NOT NULL,
+	longitude REAL NOT NULL,
+	distance_m REAL NOT NULL DEFAULT 0,
+	data TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	entry_id TEXT NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	name TEXT NOT NULL,
+	rating INTEGER NOT NULL,
+	description TEXT NOT NULL,
+	images TEXT NOT NULL,
+	posted TEXT NOT NULL,
+	owner_response TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_entry_id ON reviews(entry_id);
+`
+
+// sqliteWriter writes the entries, their reviews and the metadata of the run
+// to a SQLite database file. The entries are keyed by their cid, or their data
+// id when the cid is missing: a place scraped again replaces its row and its
+// reviews, so the runs appending to the same file do not duplicate the places.
+//
+// The database is in WAL mode with a busy timeout, so several scrapers can
+// write to the same file and it can be queried while a run is in progress.
+// Every result is written in its own transaction.
+type sqliteWriter struct {
+	db    *sql.DB
+	runID int64
+
+	entries int
+	reviews int
+}
+
+func newSQLiteWriter(path string) (*sqliteWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the connection serializes the writes of the writer, the busy timeout
+	// waits for the writes of the other processes
+	db.SetMaxOpenConns(1)
+
+	pragmas := []string{
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA foreign_keys = ON",
+	}
+
+	for _, q := range append(pragmas, sqliteSchema) {
+		if _, err := db.Exec(q); err != nil {
+			_ = db.Close()
+
+			return nil, err
+		}
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	return &sqliteWriter{db: db}, nil
+}
+
+// sqliteColumns are the columns added to the entries table after its first
+// version, they are added to the tables of the previous runs
+var sqliteColumns = map[string]string{
+	"distance_m": "REAL NOT NULL DEFAULT 0",
+}
+
+// migrateSQLite adds the missing columns to the entries table
+func migrateSQLite(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('entries')`)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	existing := make(map[string]struct{})
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		existing[name] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, typ := range sqliteColumns {
+		if _, ok := existing[name]; ok {
+			continue
+		}
+
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN ` + name + ` ` + typ); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *sqliteWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	defer s.db.Close()
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO runs (command, started_at) VALUES (?, ?)`,
+		strings.Join(os.Args, " "), time.Now().UTC().Unix())
+	if err != nil {
+		return err
+	}
+
+	s.runID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = append(entries, data)
+		case []*gmaps.Entry:
+			entries = data
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if err := s.write(ctx, entries); err != nil {
+			return err
+		}
+	}
+
+	// the run is closed even when the context is canceled
+	_, err = s.db.ExecContext(context.WithoutCancel(ctx),
+		`UPDATE runs SET finished_at = ?, entries = ?, reviews = ? WHERE id = ?`,
+		time.Now().UTC().Unix(), s.entries, s.reviews, s.runID)
+
+	return err
+}
+
+func (s *sqliteWriter) write(ctx context.Context, entries []*gmaps.Entry) error {
+	const (
+		upsertEntry = `INSERT INTO entries
+			(id, run_id, cid, data_id, link, title, category, address, website, phone,
+			review_count, review_rating, latitude, longitude, distance_m, data, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+			run_id = excluded.run_id, cid = excluded.cid, data_id = excluded.data_id, link = excluded.link,
+			title = excluded.title, category = excluded.category, address = excluded.address,
+			website = excluded.website, phone = excluded.phone, review_count = excluded.review_count,
+			review_rating = excluded.review_rating, latitude = excluded.latitude,
+			longitude = excluded.longitude, distance_m = excluded.distance_m, data = excluded.data,
+			updated_at = excluded.updated_at`
+		deleteReviews = `DELETE FROM reviews WHERE entry_id = ?`
+		insertReview  = `INSERT INTO reviews
+			(entry_id, run_id, name, rating, description, images, posted, owner_response)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC().Unix()
+
+	var written, reviews int
+
+	for _, e := range entries {
+		id := e.Key()
+		if id == "" {
+			continue
+		}
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, upsertEntry,
+			id, s.runID, e.Cid, e.DataID, e.Link, e.Title, e.Category, e.Address, e.WebSite, e.Phone,
+			e.ReviewCount, e.ReviewRating, e.Latitude, e.Longtitude, e.DistanceM, string(data), now,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteReviews, id); err != nil {
+			return err
+		}
+
+		// the extended reviews include the ones of the place page
+		placeReviews := e.UserReviewsExtended
+		if len(placeReviews) == 0 {
+			placeReviews = e.UserReviews
+		}
+
+		for _, r := range placeReviews {
+			images, err := json.Marshal(r.Images)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, insertReview,
+				id, s.runID, r.Name, r.Rating, r.Description, string(images), r.When, r.OwnerResponse,
+			); err != nil {
+				return err
+			}
+		}
+
+		written++
+		reviews += len(placeReviews)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.entries += written
+	s.reviews += reviews
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package filerunner_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite" // sqlite driver
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner/filerunner"
+)
+
+// runSQLite writes the results of every run to the SQLite file
+func runSQLite(t *testing.T, path string, runs ...[]scrapemate.Result) error {
+	t.Helper()
+
+	for _, results := range runs {
+		w, err := filerunner.NewSQLiteWriter(path)
+		require.NoError(t, err)
+
+		in := make(chan scrapemate.Result, len(results))
+		for _, r := range results {
+			in <- r
+		}
+
+		close(in)
+
+		if err := w.Run(context.Background(), in); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryStrings returns the first column of the rows of the query
+func queryStrings(t *testing.T, db *sql.DB, q string) []string {
+	t.Helper()
+
+	rows, err := db.Query(q)
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	var ans []string
+
+	for rows.Next() {
+		var s string
+		require.NoError(t, rows.Scan(&s))
+
+		ans = append(ans, s)
+	}
+
+	require.NoError(t, rows.Err())
+
+	return ans
+}
+
+func Test_SQLiteWriter(t *testing.T) {
+	review := func(name string) gmaps.Review {
+		return gmaps.Review{Name: name, Rating: 5, Images: []string{}}
+	}
+
+	tests := []struct {
+		name    string
+		runs    [][]scrapemate.Result
+		entries []string
+		reviews []string
+		// counts are the entries and the reviews of every run
+		counts []string
+	}{
+		{
+			name: "places and reviews",
+			runs: [][]scrapemate.Result{{
+				{Data: &gmaps.Entry{Cid: "1", Title: "a", UserReviews: []gmaps.Review{review("x")}}},
+				{Data: []*gmaps.Entry{{DataID: "0x2", Title: "b"}, {Title: "without key"}}},
+				{Data: nil},
+			}},
+			entries: []string{"0x2:b", "1:a"},
+			reviews: []string{"1:x"},
+			counts:  []string{"2:1"},
+		},
+		{
+			name: "extended reviews",
+			runs: [][]scrapemate.Result{{
+				{Data: &gmaps.Entry{
+					Cid:                 "1",
+					Title:               "a",
+					UserReviews:         []gmaps.Review{review("x")},
+					UserReviewsExtended: []gmaps.Review{review("x"), review("y")},
+				}},
+			}},
+			entries: []string{"1:a"},
+			reviews: []string{"1:x", "1:y"},
+			counts:  []string{"1:2"},
+		},
+		{
+			name: "place scraped again",
+			runs: [][]scrapemate.Result{
+				{{Data: &gmaps.Entry{Cid: "1", Title: "old", UserReviews: []gmaps.Review{review("x")}}}},
+				{{Data: &gmaps.Entry{Cid: "1", Title: "new", UserReviews: []gmaps.Review{review("y")}}}},
+			},
+			entries: []string{"1:new"},
+			reviews: []string{"1:y"},
+			counts:  []string{"1:1", "1:1"},
+		},
+		{
+			name: "same place twice in a run",
+			runs: [][]scrapemate.Result{{
+				{Data: &gmaps.Entry{Cid: "1", Title: "first"}},
+				{Data: &gmaps.Entry{Cid: "1", Title: "last"}},
+			}},
+			entries: []string{"1:last"},
+			counts:  []string{"2:0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results.db")
+
+			require.NoError(t, runSQLite(t, path, tc.runs...))
+
+			db, err := sql.Open("sqlite", path)
+			require.NoError(t, err)
+
+			defer db.Close()
+
+			require.Equal(t, tc.entries, queryStrings(t, db, `SELECT id || ':' || title FROM entries ORDER BY id`))
+			require.Equal(t, tc.reviews, queryStrings(t, db, `SELECT entry_id || ':' || name FROM reviews ORDER BY entry_id, name`))
+			require.Equal(t, tc.counts, queryStrings(t, db,
+				`SELECT entries || ':' || reviews FROM runs WHERE finished_at IS NOT NULL ORDER BY id`))
+		})
+	}
+}
+
+func Test_SQLiteWriterErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	err := runSQLite(t, path, []scrapemate.Result{
+		{Data: &gmaps.Entry{Cid: "1", Title: "a"}},
+		{Data: "place"},
+	})
+	require.Error(t, err)
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// the run is not finished, its written places are kept
+	require.Equal(t, []string{"1:a"}, queryStrings(t, db, `SELECT id || ':' || title FROM entries`))
+	require.Empty(t, queryStrings(t, db, `SELECT id FROM runs WHERE finished_at IS NOT NULL`))
+
+	_, err = filerunner.NewSQLiteWriter(t.TempDir())
+	require.Error(t, err)
+}
+
+func Test_SQLiteWriterMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// the entries table of a file written before the distance column
+	_, err = db.Exec(`CREATE TABLE entries (
+		id TEXT PRIMARY KEY, run_id INTEGER NOT NULL, cid TEXT NOT NULL, data_id TEXT NOT NULL,
+		link TEXT NOT NULL, title TEXT NOT NULL, category TEXT NOT NULL, address TEXT NOT NULL,
+		website TEXT NOT NULL, phone TEXT NOT NULL, review_count INTEGER NOT NULL,
+		review_rating REAL NOT NULL, latitude REAL NOT NULL, longitude REAL NOT NULL,
+		data TEXT NOT NULL, updated_at INTEGER NOT NULL
+	)`)
+	require.NoError(t, err)
+
+	require.NoError(t, runSQLite(t, path, []scrapemate.Result{
+		{Data: &gmaps.Entry{Cid: "1", Title: "a", DistanceM: 12.5}},
+	}))
+
+	require.Equal(t, []string{"12.5"}, queryStrings(t, db, `SELECT CAST(distance_m AS TEXT) FROM entries`))
+}
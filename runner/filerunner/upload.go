@@ -0,0 +1,231 @@
+package filerunner
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// uploadAttempts is the number of times an upload is tried
+	uploadAttempts = 3
+	// uploadBackoff is the wait before the second attempt, it doubles every attempt
+	uploadBackoff = 2 * time.Second
+)
+
+// firstQuery returns the first query of the input for the {query} of the
+// upload prefix, the input is read in memory only when the prefix has it
+func (r *fileRunner) firstQuery() (string, error) {
+	if !strings.Contains(r.cfg.UploadPrefix, "{query}") {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(r.input)
+	if err != nil {
+		return "", err
+	}
+
+	r.input = bytes.NewReader(data)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// the input id is not part of the query
+		line, _, _ := strings.Cut(scanner.Text(), "#!#")
+
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+
+	return "", scanner.Err()
+}
+
+// uploadPrefix replaces {date}, {time} and {query} in the prefix of -upload
+func uploadPrefix(prefix string, start time.Time, query string) string {
+	return strings.NewReplacer(
+		"{date}", start.Format("2006-01-02"),
+		"{time}", start.Format("150405"),
+		"{query}", slug(query),
+	).Replace(prefix)
+}
+
+// slug makes the query usable in a key, e.g. "Cafés in Athens" is cafés-in-athens
+func slug(query string) string {
+	const maxLen = 64
+
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(query) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+
+			continue
+		}
+
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+
+		b.WriteRune(r)
+
+		dash = false
+
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+
+	if b.Len() == 0 {
+		return "query"
+	}
+
+	return b.String()
+}
+
+// uploadFiles are the files written by the run
+func (r *fileRunner) uploadFiles() []string {
+	var files []string
+
+	switch {
+	case r.cfg.Output != "":
+		if r.cfg.Output != "-" {
+			files = append(files, r.cfg.Output)
+		}
+	default:
+		if r.cfg.ResultsFile != "stdout" {
+			files = append(files, r.cfg.ResultsFile)
+		}
+
+		if r.cfg.SQLite != "" {
+			files = append(files, r.cfg.SQLite)
+		}
+	}
+
+	for _, f := range r.routeFiles {
+		files = append(files, f.Name())
+	}
+
+	files = append(files, r.routeDatabases...)
+
+	if r.cfg.Stats != "" {
+		files = append(files, r.cfg.Stats)
+	}
+
+	if r.cfg.Coverage != "" {
+		files = append(files, r.cfg.Coverage)
+	}
+
+	if r.cfg.Manifest != "" {
+		files = append(files, r.cfg.Manifest)
+	}
+
+	return files
+}
+
+// upload uploads the files of the run, and the responses of -record with
+// -upload-record, to the destination of -upload. It runs after the run, the
+// output files are complete.
+func (r *fileRunner) upload(ctx context.Context, start time.Time, query string) error {
+	prefix := uploadPrefix(r.cfg.UploadPrefix, start, query)
+
+	key := func(name string) string {
+		return path.Join(prefix, name)
+	}
+
+	for _, name := range r.uploadFiles() {
+		err := r.retryUpload(ctx, key(filepath.Base(name)), func() (io.ReadCloser, error) {
+			return os.Open(name)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot upload %s: %w", name, err)
+		}
+	}
+
+	if r.cfg.UploadRecord {
+		err := r.retryUpload(ctx, key("responses.tar.gz"), func() (io.ReadCloser, error) {
+			return archive(r.cfg.RecordDir), nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot upload the responses of %s: %w", r.cfg.RecordDir, err)
+		}
+	}
+
+	return nil
+}
+
+// retryUpload uploads the body returned by open, opened again for every attempt
+func (r *fileRunner) retryUpload(ctx context.Context, key string, open func() (io.ReadCloser, error)) error {
+	backoff := uploadBackoff
+
+	var err error
+
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		var body io.ReadCloser
+
+		body, err = open()
+		if err != nil {
+			// the file is missing or unreadable, retrying does not help
+			return err
+		}
+
+		err = r.cfg.Uploader.Upload(ctx, r.cfg.UploadBucket, key, body)
+
+		_ = body.Close()
+
+		if err == nil {
+			slog.Info("uploaded", "key", key)
+
+			return nil
+		}
+
+		if attempt < uploadAttempts {
+			slog.Warn("upload failed, retrying", "key", key, "backoff", backoff, "error", err)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+	}
+
+	return err
+}
+
+// archive streams the files of dir as a tar.gz archive
+func archive(dir string) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		gz := gzip.NewWriter(pw)
+		tw := tar.NewWriter(gz)
+
+		err := tw.AddFS(os.DirFS(dir))
+		if err == nil {
+			err = tw.Close()
+		}
+
+		if err == nil {
+			err = gz.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
@@ -0,0 +1,192 @@
+package filerunner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*vcardWriter)(nil)
+
+var vcardFileRegex = regexp.MustCompile(`[^\pL\pN]+`)
+
+// vcardWriter writes the places with a phone or an email as vCard 3.0 contacts,
+// all in w or one .vcf file per place in dir
+type vcardWriter struct {
+	w   *bufio.Writer
+	dir string
+}
+
+func newVCardWriter(w io.Writer) *vcardWriter {
+	return &vcardWriter{w: bufio.NewWriter(w)}
+}
+
+func newVCardDirWriter(dir string) *vcardWriter {
+	return &vcardWriter{dir: dir}
+}
+
+func (v *vcardWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if v.dir != "" {
+		if err := os.MkdirAll(v.dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{data}
+		case []*gmaps.Entry:
+			entries = data
+		}
+
+		for _, e := range entries {
+			if e.Phone == "" && len(e.Emails) == 0 {
+				continue
+			}
+
+			if err := v.write(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v.w == nil {
+		return nil
+	}
+
+	return v.w.Flush()
+}
+
+func (v *vcardWriter) write(e *gmaps.Entry) error {
+	card := vcard(e)
+
+	if v.dir == "" {
+		_, err := v.w.WriteString(card)
+
+		return err
+	}
+
+	name := strings.Trim(vcardFileRegex.ReplaceAllString(e.Title, "-"), "-")
+	if e.Cid != "" {
+		// the cid keeps the places with the same name apart
+		name += "-" + e.Cid
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	return os.WriteFile(filepath.Join(v.dir, name+".vcf"), []byte(card), 0o644)
+}
+
+// vcard returns the contact card of the place
+func vcard(e *gmaps.Entry) string {
+	var sb strings.Builder
+
+	line := func(s string) {
+		sb.WriteString(vcardFold(s))
+		sb.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCARD")
+	line("VERSION:3.0")
+	line("FN:" + vcardEscape(e.Title))
+	line("ORG:" + vcardEscape(e.Title))
+
+	if e.Phone != "" {
+		line("TEL;TYPE=WORK,VOICE:" + vcardEscape(e.Phone))
+	}
+
+	for _, email := range e.Emails {
+		line("EMAIL;TYPE=INTERNET,WORK:" + vcardEscape(email))
+	}
+
+	if e.WebSite != "" {
+		line("URL:" + e.WebSite)
+	}
+
+	if a := e.CompleteAddress; a.Street != "" || a.City != "" {
+		// post office box;extended address;street;locality;region;postal code;country
+		line("ADR;TYPE=WORK:;;" + strings.Join([]string{
+			vcardEscape(a.Street), vcardEscape(a.City), vcardEscape(a.State),
+			vcardEscape(a.PostalCode), vcardEscape(a.Country),
+		}, ";"))
+	} else if e.Address != "" {
+		line("ADR;TYPE=WORK:;;" + vcardEscape(e.Address) + ";;;;")
+	}
+
+	if e.Latitude != 0 || e.Longtitude != 0 {
+		line("GEO:" + strconv.FormatFloat(e.Latitude, 'f', -1, 64) + ";" + strconv.FormatFloat(e.Longtitude, 'f', -1, 64))
+	}
+
+	if len(e.Categories) > 0 {
+		categories := make([]string, len(e.Categories))
+		for i := range e.Categories {
+			categories[i] = vcardEscape(e.Categories[i])
+		}
+
+		line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+
+	if e.Link != "" {
+		line("NOTE:" + vcardEscape("Google Maps: "+e.Link))
+	}
+
+	if e.Cid != "" {
+		line("UID:" + vcardEscape("google-maps-cid-"+e.Cid))
+	}
+
+	line("END:VCARD")
+
+	return sb.String()
+}
+
+// vcardEscape escapes the characters with a meaning in the values
+func vcardEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// vcardFold splits the lines longer than 75 bytes, the continuation lines start with a space
+func vcardFold(s string) string {
+	const maxLineBytes = 75
+
+	if len(s) <= maxLineBytes {
+		return s
+	}
+
+	var sb strings.Builder
+
+	limit := maxLineBytes
+
+	for len(s) > limit {
+		// do not split a multibyte character
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		sb.WriteString(s[:cut])
+		sb.WriteString("\r\n ")
+
+		s = s[cut:]
+		// the leading space counts
+		limit = maxLineBytes - 1
+	}
+
+	sb.WriteString(s)
+
+	return sb.String()
+}
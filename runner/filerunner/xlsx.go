@@ -0,0 +1,244 @@
+package filerunner
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosom/scrapemate"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.ResultWriter = (*xlsxWriter)(nil)
+
+// xlsxNumeric are the columns of the entries written as numbers
+var xlsxNumeric = map[string]bool{
+	"review_count":  true,
+	"review_rating": true,
+	"latitude":      true,
+	"longitude":     true,
+	"distance_m":    true,
+	"bearing":       true,
+	"star_class":    true,
+}
+
+var xlsxReviewHeaders = []string{
+	"cid", "title", "name", "rating", "when", "description", "language", "translation", "owner_response",
+	"owner_response_when", "images", "profile_picture",
+}
+
+// xlsxWriter writes the entries as an Excel workbook with a Places sheet, one
+// row per place with the columns of the CSV output, and a Reviews sheet, one
+// row per review. The counts, ratings and coordinates are numbers. The sheets
+// are streamed to temporary files and the workbook is written to w when the
+// results end.
+type xlsxWriter struct {
+	w io.Writer
+
+	file    *excelize.File
+	places  *xlsxSheet
+	reviews *xlsxSheet
+}
+
+// xlsxSheet is a sheet with a bold and frozen header row
+type xlsxSheet struct {
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{w: w}
+}
+
+func (x *xlsxWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	x.file = excelize.NewFile()
+	defer x.file.Close()
+
+	if err := x.file.SetSheetName("Sheet1", "Places"); err != nil {
+		return err
+	}
+
+	if _, err := x.file.NewSheet("Reviews"); err != nil {
+		return err
+	}
+
+	bold, err := x.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	for result := range in {
+		var items []scrapemate.CsvCapable
+
+		switch data := result.Data.(type) {
+		case []*gmaps.Entry:
+			for _, e := range data {
+				items = append(items, e)
+			}
+		case scrapemate.CsvCapable:
+			items = append(items, data)
+		default:
+			continue
+		}
+
+		for _, item := range items {
+			if err := x.write(item, bold); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range []*xlsxSheet{x.places, x.reviews} {
+		if s == nil {
+			continue
+		}
+
+		if err := s.sw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return x.file.Write(x.w)
+}
+
+func (x *xlsxWriter) write(item scrapemate.CsvCapable, bold int) error {
+	headers := item.CsvHeaders()
+
+	if x.places == nil {
+		s, err := newXLSXSheet(x.file, "Places", headers, bold)
+		if err != nil {
+			return err
+		}
+
+		x.places = s
+	}
+
+	_, entry := item.(*gmaps.Entry)
+
+	values := item.CsvRow()
+	row := make([]any, len(values))
+
+	for i, v := range values {
+		row[i] = xlsxValue(v, entry && i < len(headers) && xlsxNumeric[headers[i]])
+	}
+
+	if err := x.places.add(row); err != nil {
+		return err
+	}
+
+	if !entry {
+		return nil
+	}
+
+	return x.writeReviews(item.(*gmaps.Entry), bold)
+}
+
+func (x *xlsxWriter) writeReviews(e *gmaps.Entry, bold int) error {
+	// the extended reviews include the ones of the place page
+	reviews := e.UserReviewsExtended
+	if len(reviews) == 0 {
+		reviews = e.UserReviews
+	}
+
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	if x.reviews == nil {
+		s, err := newXLSXSheet(x.file, "Reviews", xlsxReviewHeaders, bold)
+		if err != nil {
+			return err
+		}
+
+		x.reviews = s
+	}
+
+	for _, r := range reviews {
+		row := []any{
+			xlsxValue(e.Cid, false),
+			xlsxValue(e.Title, false),
+			xlsxValue(r.Name, false),
+			r.Rating,
+			xlsxValue(r.When, false),
+			xlsxValue(r.Description, false),
+			xlsxValue(r.Language, false),
+			xlsxValue(r.Translation, false),
+			xlsxValue(r.OwnerResponse, false),
+			xlsxValue(r.OwnerResponseWhen, false),
+			xlsxValue(strings.Join(r.Images, ", "), false),
+			xlsxValue(r.ProfilePicture, false),
+		}
+
+		if err := x.reviews.add(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newXLSXSheet(f *excelize.File, name string, headers []string, bold int) (*xlsxSheet, error) {
+	const width = 18
+
+	sw, err := f.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sw.SetColWidth(1, max(len(headers), 1), width); err != nil {
+		return nil, err
+	}
+
+	err = sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]any, len(headers))
+	for i, h := range headers {
+		row[i] = excelize.Cell{StyleID: bold, Value: h}
+	}
+
+	s := xlsxSheet{sw: sw}
+
+	if err := s.add(row); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (s *xlsxSheet) add(row []any) error {
+	s.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+
+	return s.sw.SetRow(cell, row)
+}
+
+// xlsxValue is the number of v for the numeric columns, and v cut to the
+// 32767 characters a cell holds otherwise
+func xlsxValue(v string, numeric bool) any {
+	if numeric {
+		if v == "" {
+			return nil
+		}
+
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+
+	if utf8.RuneCountInString(v) <= excelize.TotalCellChars {
+		return v
+	}
+
+	return string([]rune(v)[:excelize.TotalCellChars])
+}
@@ -0,0 +1,178 @@
+package filerunner_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner/filerunner"
+)
+
+// runXLSX returns the workbook written from the results
+func runXLSX(t *testing.T, results ...scrapemate.Result) *excelize.File {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	require.NoError(t, filerunner.NewXLSXWriter(&buf).Run(context.Background(), in))
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = f.Close()
+	})
+
+	return f
+}
+
+// column returns the values of the column of the header in the sheet, below the header
+func column(t *testing.T, f *excelize.File, sheet, header string) []string {
+	t.Helper()
+
+	rows, err := f.GetRows(sheet)
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+
+	i := -1
+
+	for j, h := range rows[0] {
+		if h == header {
+			i = j
+		}
+	}
+
+	require.NotEqual(t, -1, i, header)
+
+	var ans []string
+
+	for _, r := range rows[1:] {
+		if i < len(r) {
+			ans = append(ans, r[i])
+		} else {
+			ans = append(ans, "")
+		}
+	}
+
+	return ans
+}
+
+// cellType returns the type of the cell of the header in the first row of places
+func cellType(t *testing.T, f *excelize.File, header string) excelize.CellType {
+	t.Helper()
+
+	headers := (&gmaps.Entry{}).CsvHeaders()
+
+	for i, h := range headers {
+		if h != header {
+			continue
+		}
+
+		cell, err := excelize.CoordinatesToCellName(i+1, 2)
+		require.NoError(t, err)
+
+		typ, err := f.GetCellType("Places", cell)
+		require.NoError(t, err)
+
+		return typ
+	}
+
+	require.Fail(t, "unknown header", header)
+
+	return excelize.CellTypeUnset
+}
+
+func Test_XLSXWriter(t *testing.T) {
+	review := func(name string) gmaps.Review {
+		return gmaps.Review{Name: name, Rating: 4}
+	}
+
+	f := runXLSX(t,
+		scrapemate.Result{Data: &gmaps.Entry{
+			Cid:          "1",
+			Title:        "Cafe",
+			Phone:        "+30 21 0000",
+			ReviewCount:  12,
+			ReviewRating: 4.5,
+			Latitude:     34.7,
+			UserReviews:  []gmaps.Review{review("x")},
+		}},
+		scrapemate.Result{Data: []*gmaps.Entry{{
+			Cid:                 "2",
+			Title:               "Bakery",
+			UserReviews:         []gmaps.Review{review("y")},
+			UserReviewsExtended: []gmaps.Review{review("y"), review("z")},
+		}}},
+		scrapemate.Result{Data: nil},
+	)
+
+	require.Equal(t, []string{"Cafe", "Bakery"}, column(t, f, "Places", "title"))
+	require.Equal(t, []string{"+30 21 0000", ""}, column(t, f, "Places", "phone"))
+	require.Equal(t, []string{"12", "0"}, column(t, f, "Places", "review_count"))
+
+	// the counts, ratings and coordinates are numbers, which have no type in
+	// the sheet, the phones are text
+	require.Equal(t, excelize.CellTypeUnset, cellType(t, f, "review_count"))
+	require.Equal(t, excelize.CellTypeUnset, cellType(t, f, "review_rating"))
+	require.Equal(t, excelize.CellTypeUnset, cellType(t, f, "latitude"))
+	require.Equal(t, excelize.CellTypeInlineString, cellType(t, f, "phone"))
+
+	// the extended reviews include the ones of the place page
+	require.Equal(t, []string{"1", "2", "2"}, column(t, f, "Reviews", "cid"))
+	require.Equal(t, []string{"x", "y", "z"}, column(t, f, "Reviews", "name"))
+	require.Equal(t, []string{"4", "4", "4"}, column(t, f, "Reviews", "rating"))
+}
+
+func Test_XLSXWriterLongText(t *testing.T) {
+	long := strings.Repeat("é", excelize.TotalCellChars+10)
+
+	f := runXLSX(t, scrapemate.Result{Data: &gmaps.Entry{Title: long}})
+
+	// a cell holds 32767 characters, of two bytes each here
+	title := column(t, f, "Places", "title")
+	require.Len(t, title, 1)
+	require.Equal(t, long[:2*excelize.TotalCellChars], title[0])
+}
+
+func Test_XLSXWriterWithoutPlaces(t *testing.T) {
+	f := runXLSX(t, scrapemate.Result{Data: nil})
+
+	require.Equal(t, []string{"Places", "Reviews"}, f.GetSheetList())
+
+	rows, err := f.GetRows("Places")
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+// failingWriter is an output whose writes fail, e.g. a full disk
+type failingWriter struct{}
+
+var errWrite = errors.New("no space left on device")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func Test_XLSXWriterError(t *testing.T) {
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "Cafe"}}
+
+	close(in)
+
+	err := filerunner.NewXLSXWriter(failingWriter{}).Run(context.Background(), in)
+	require.ErrorIs(t, err, errWrite)
+}
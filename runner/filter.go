@@ -0,0 +1,291 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SetupFilters registers the after parse functions of cfg.Middleware that drop
+// the places under cfg.MinRating or cfg.MinReviews, the places without the
+// phone or the website of cfg.RequirePhone and cfg.RequireWebsite, the places
+// closed with cfg.ExcludeClosed or at cfg.OpenAtTime, the places out of
+// cfg.PriceLevels and the places of the categories not kept by cfg.Categories.
+// It must run before SetupDedup, the places dropped are not added to the dedup
+// store and a later run finds them once they have enough reviews.
+//
+// The reviews are processed before the entries are written, once all their
+// pages are fetched: cfg.UnansweredReviews drops the reviews with an owner
+// response, cfg.ReviewLanguages the reviews in the other languages and
+// cfg.TranslatedReviews replaces their descriptions with the translations.
+func SetupFilters(cfg *Config) {
+	var fns []gmaps.AfterParseFunc
+
+	if cfg.MinRating > 0 || cfg.MinReviews > 0 {
+		fns = append(fns, MinRating(cfg.MinRating, cfg.MinReviews))
+	}
+
+	if cfg.RequirePhone || cfg.RequireWebsite {
+		fns = append(fns, RequireContact(cfg.RequirePhone, cfg.RequireWebsite))
+	}
+
+	if cfg.ExcludeClosed {
+		fns = append(fns, SkipClosed)
+	}
+
+	if cfg.OpenAtTime != nil {
+		fns = append(fns, OpenAt(*cfg.OpenAtTime))
+	}
+
+	if cfg.PriceLevels != nil {
+		fns = append(fns, PriceLevel(*cfg.PriceLevels))
+	}
+
+	if cfg.Categories != nil {
+		fns = append(fns, cfg.Categories.Filter)
+	}
+
+	var reviews []gmaps.BeforeWriteFunc
+
+	if cfg.UnansweredReviews {
+		reviews = append(reviews, UnansweredReviews)
+	}
+
+	if len(cfg.ReviewLanguages) > 0 {
+		reviews = append(reviews, ReviewLanguages(cfg.ReviewLanguages))
+	}
+
+	if cfg.TranslatedReviews {
+		reviews = append(reviews, TranslatedReviews)
+	}
+
+	if len(fns) == 0 && len(reviews) == 0 {
+		return
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(fns...)
+	cfg.Middleware.UseBeforeWrite(reviews...)
+}
+
+// MinRating returns an after parse function that skips the entries rated
+// under rating or with less than reviews reviews, 0 disables a minimum
+func MinRating(rating float64, reviews int) gmaps.AfterParseFunc {
+	return func(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		if entry.ReviewRating < rating || entry.ReviewCount < reviews {
+			slog.Debug("place under the minimum rating skipped", "title", entry.Title,
+				"rating", entry.ReviewRating, "reviews", entry.ReviewCount, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
+
+// RequireContact returns an after parse function that skips the entries
+// without a phone when phone is set and without a website when website is set
+func RequireContact(phone, website bool) gmaps.AfterParseFunc {
+	return func(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		if (phone && entry.Phone == "") || (website && entry.WebSite == "") {
+			slog.Debug("place without contact skipped", "title", entry.Title,
+				"phone", entry.Phone, "website", entry.WebSite, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
+
+// SkipClosed is an after parse function that skips the entries of the places
+// temporarily or permanently closed
+func SkipClosed(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+	if entry.BusinessStatus == gmaps.BusinessTemporarilyClosed || entry.BusinessStatus == gmaps.BusinessPermanentlyClosed {
+		slog.Debug("closed place skipped", "title", entry.Title, "business_status", entry.BusinessStatus, "job_id", job.GetID())
+
+		return gmaps.ErrSkipEntry
+	}
+
+	return nil
+}
+
+// UnansweredReviews is a before write function that drops the reviews of the
+// entries with an owner response
+func UnansweredReviews(_ context.Context, entry *gmaps.Entry) error {
+	answered := func(r gmaps.Review) bool {
+		return r.OwnerResponse != ""
+	}
+
+	entry.UserReviews = slices.DeleteFunc(entry.UserReviews, answered)
+	entry.UserReviewsExtended = slices.DeleteFunc(entry.UserReviewsExtended, answered)
+
+	return nil
+}
+
+// ReviewLanguages returns a before write function that drops the reviews of
+// the entries not written in one of the languages, e.g. en matches en and
+// en-GB. The reviews of an unknown language are dropped.
+func ReviewLanguages(languages []string) gmaps.BeforeWriteFunc {
+	other := func(r gmaps.Review) bool {
+		for _, l := range languages {
+			l = strings.TrimSpace(l)
+
+			if strings.EqualFold(r.Language, l) || (len(r.Language) > len(l) && strings.EqualFold(r.Language[:len(l)+1], l+"-")) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return func(_ context.Context, entry *gmaps.Entry) error {
+		entry.UserReviews = slices.DeleteFunc(entry.UserReviews, other)
+		entry.UserReviewsExtended = slices.DeleteFunc(entry.UserReviewsExtended, other)
+
+		return nil
+	}
+}
+
+// TranslatedReviews is a before write function that replaces the descriptions
+// of the reviews of the entries with their translations, the reviews written
+// in the language of the request are left as is
+func TranslatedReviews(_ context.Context, entry *gmaps.Entry) error {
+	for _, reviews := range [][]gmaps.Review{entry.UserReviews, entry.UserReviewsExtended} {
+		for i := range reviews {
+			if reviews[i].Translation != "" {
+				reviews[i].Description = reviews[i].Translation
+			}
+		}
+	}
+
+	return nil
+}
+
+// OpenAt returns an after parse function that skips the entries closed at t
+// in their local time, and the entries without opening hours
+func OpenAt(t gmaps.WeekTime) gmaps.AfterParseFunc {
+	return func(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		open, err := entry.OpenAt(t)
+		if err != nil || !open {
+			slog.Debug("place closed skipped", "title", entry.Title, "open_hours", entry.OpenHours, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
+
+// PriceLevel returns an after parse function that skips the entries out of
+// the price levels, and the entries without a price level
+func PriceLevel(levels gmaps.PriceLevels) gmaps.AfterParseFunc {
+	return func(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+		if !levels.Contains(entry.PriceLevel) {
+			slog.Debug("place out of the price levels skipped", "title", entry.Title,
+				"price_range", entry.PriceRange, "job_id", job.GetID())
+
+			return gmaps.ErrSkipEntry
+		}
+
+		return nil
+	}
+}
+
+// CategoryFilter keeps the places of the categories matching an include
+// pattern, all of them without include patterns, and drops the places of the
+// categories matching an exclude pattern. A pattern is a case insensitive
+// substring of the category, or a regular expression between slashes like
+// /^(bar|pub)$/.
+type CategoryFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewCategoryFilter returns the filter of the include and exclude patterns
+func NewCategoryFilter(include, exclude []string) (*CategoryFilter, error) {
+	var (
+		f   CategoryFilter
+		err error
+	)
+
+	if f.include, err = compileCategories(include); err != nil {
+		return nil, err
+	}
+
+	if f.exclude, err = compileCategories(exclude); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// Keep reports whether the place of the categories is kept
+func (f *CategoryFilter) Keep(categories []string) bool {
+	if len(f.include) > 0 && !matchCategories(f.include, categories) {
+		return false
+	}
+
+	return !matchCategories(f.exclude, categories)
+}
+
+// Filter is the after parse function skipping the entries not kept, the
+// categories of an entry are its main category and the others
+func (f *CategoryFilter) Filter(_ context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+	categories := entry.Categories
+	if len(categories) == 0 && entry.Category != "" {
+		categories = []string{entry.Category}
+	}
+
+	if !f.Keep(categories) {
+		slog.Debug("place of a filtered category skipped", "title", entry.Title,
+			"categories", categories, "job_id", job.GetID())
+
+		return gmaps.ErrSkipEntry
+	}
+
+	return nil
+}
+
+func compileCategories(patterns []string) ([]*regexp.Regexp, error) {
+	ans := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, p := range patterns {
+		expr := "(?i)" + regexp.QuoteMeta(p)
+
+		if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			expr = "(?i)" + p[1:len(p)-1]
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category pattern %q: %w", p, err)
+		}
+
+		ans = append(ans, re)
+	}
+
+	return ans, nil
+}
+
+func matchCategories(patterns []*regexp.Regexp, categories []string) bool {
+	for _, re := range patterns {
+		for _, c := range categories {
+			if re.MatchString(c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"github.com/gosom/google-maps-scraper/geocode"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SetupGeocoder registers the geocoder configured via the command line filling
+// in the coordinates of the places parsed without them. It must run after
+// SetupFilters and SetupDedup, the places dropped are not geocoded, and before
+// SetupIsochrone so that the geocoded places are filtered.
+func SetupGeocoder(cfg *Config) error {
+	if cfg.Geocoder == "" {
+		return nil
+	}
+
+	g, err := geocode.New(cfg.Geocoder)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(geocode.NewEnricher(g).Process)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/proxypool"
+)
+
+// pressureWriter measures how long the results wait for its writer, the
+// workers producing the results are blocked while they wait
+type pressureWriter struct {
+	next scrapemate.ResultWriter
+	// waiting is the unix time in nanoseconds since which a result waits
+	// for the writer, 0 when the writer keeps up
+	waiting atomic.Int64
+}
+
+func (w *pressureWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.next.Run(ctx, out)
+	}()
+
+	for result := range in {
+		w.waiting.Store(time.Now().UnixNano())
+
+		select {
+		case out <- result:
+		case err := <-done:
+			w.waiting.Store(0)
+
+			return err
+		}
+
+		w.waiting.Store(0)
+	}
+
+	close(out)
+
+	return <-done
+}
+
+// stall returns how long the current result has been waiting for the writer
+func (w *pressureWriter) stall(now time.Time) time.Duration {
+	since := w.waiting.Load()
+	if since == 0 {
+		return 0
+	}
+
+	return now.Sub(time.Unix(0, since))
+}
+
+// WriterStall returns how long the results have been waiting for the writers
+// of the app, 0 while one of the writers keeps up
+func (a *App) WriterStall() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.writers) == 0 {
+		return 0
+	}
+
+	now := time.Now()
+
+	// a result is written by the first writer free, the results are stalled
+	// when all the writers are busy
+	ans := a.writers[0].stall(now)
+	for _, w := range a.writers[1:] {
+		ans = min(ans, w.stall(now))
+	}
+
+	return ans
+}
+
+// ProxyHealth returns the health of the proxy pool of the app, false when it
+// has no pool
+func (a *App) ProxyHealth() (proxypool.Health, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pool == nil {
+		return proxypool.Health{}, false
+	}
+
+	return a.pool.Health(), true
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/report"
+)
+
+// IncrementalStore is the value of Incremental that uses the places of the
+// DedupStore as the known places
+const IncrementalStore = "store"
+
+var _ gmaps.Known = (knownPlaces)(nil)
+
+// knownPlaces are the ids of the places of a previous results file
+type knownPlaces map[string]struct{}
+
+func (k knownPlaces) Seen(_ context.Context, key string) bool {
+	_, ok := k[key]
+
+	return ok
+}
+
+// LoadKnown reads the places of a previous results file, a CSV or a JSON
+// file. The places are scraped when the file was modified: when it is older
+// than ttl they are all scraped again.
+func LoadKnown(path string, ttl time.Duration) (gmaps.Known, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the known places: %w", err)
+	}
+
+	if ttl > 0 && time.Since(info.ModTime()) > ttl {
+		slog.Info("incremental: the previous results are older than the ttl, all the places are scraped again",
+			"path", path, "modified", info.ModTime())
+
+		return knownPlaces{}, nil
+	}
+
+	places, err := report.LoadSnapshot(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the known places: %w", err)
+	}
+
+	known := make(knownPlaces, len(places))
+
+	for i := range places {
+		known[places[i].ID] = struct{}{}
+
+		// the places of the searches are known by their cid
+		if cid := gmaps.CidFromDataID(places[i].ID); cid != "" {
+			known[cid] = struct{}{}
+		}
+	}
+
+	slog.Info("incremental: known places loaded", "path", path, "places", len(places))
+
+	return known, nil
+}
@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The columns of the input rows, the other columns are their metadata
+const (
+	queryColumn    = "query"
+	idColumn       = "id"
+	latColumn      = "lat"
+	lonColumn      = "lon"
+	radiusColumn   = "radius"
+	zoomColumn     = "zoom"
+	languageColumn = "language"
+)
+
+// InputRow is a row of a CSV or JSONL input file, a query searched at its own
+// location and in its own language. The zero values are replaced by the ones
+// of the command line.
+type InputRow struct {
+	Query          string
+	ID             string
+	GeoCoordinates string
+	Radius         float64
+	Zoom           int
+	LangCode       string
+	Metadata       map[string]string
+}
+
+// Line returns the input line of the row read by CreateSeedJobs, the query,
+// the id and the metadata separated by #!#
+func (r *InputRow) Line() string {
+	line := r.Query + " #!# " + r.ID
+
+	if len(r.Metadata) > 0 {
+		values := make(url.Values, len(r.Metadata))
+		for k, v := range r.Metadata {
+			values.Set(k, v)
+		}
+
+		line += " #!# " + values.Encode()
+	}
+
+	return line + "\n"
+}
+
+// IsRowInput reports whether the input file is read by ReadInputRows, a file
+// with the .csv, .jsonl or .ndjson extension
+func IsRowInput(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".jsonl", ".ndjson":
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadInputRows reads the rows of the input file path from r, a CSV file with
+// a header or a JSONL file of objects. The columns query, id, lat, lon,
+// radius, zoom and language are matched case insensitively, the others are
+// the metadata of the row. The query of a row is tmpl executed with its
+// columns when tmpl is set, e.g. {{.Category}} in {{.City}}, its query column
+// otherwise.
+func ReadInputRows(path string, r io.Reader, tmpl *template.Template) ([]InputRow, error) {
+	var (
+		records []map[string]string
+		err     error
+	)
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err = readCSVRecords(r)
+	} else {
+		records, err = readJSONLRecords(r)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid input %s: %w", path, err)
+	}
+
+	rows := make([]InputRow, 0, len(records))
+
+	for i, record := range records {
+		row, err := newInputRow(record, tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input %s: row %d: %w", path, i+1, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func newInputRow(record map[string]string, tmpl *template.Template) (InputRow, error) {
+	var (
+		row     InputRow
+		lat     string
+		lon     string
+		err     error
+		columns = make(map[string]string, len(record))
+	)
+
+	for k, v := range record {
+		v = strings.TrimSpace(v)
+		columns[k] = v
+
+		switch strings.ToLower(k) {
+		case queryColumn:
+			row.Query = v
+		case idColumn:
+			row.ID = v
+		case latColumn:
+			lat = v
+		case lonColumn:
+			lon = v
+		case radiusColumn:
+			if v != "" {
+				if row.Radius, err = strconv.ParseFloat(v, 64); err != nil {
+					return row, fmt.Errorf("invalid radius %q", v)
+				}
+			}
+		case zoomColumn:
+			if v != "" {
+				if row.Zoom, err = strconv.Atoi(v); err != nil {
+					return row, fmt.Errorf("invalid zoom %q", v)
+				}
+			}
+		case languageColumn:
+			row.LangCode = v
+		default:
+			if v != "" {
+				if row.Metadata == nil {
+					row.Metadata = make(map[string]string)
+				}
+
+				row.Metadata[k] = v
+			}
+		}
+	}
+
+	if (lat == "") != (lon == "") {
+		return row, errors.New("lat and lon must be set together")
+	}
+
+	if lat != "" {
+		row.GeoCoordinates = lat + "," + lon
+	}
+
+	if tmpl != nil {
+		var buf bytes.Buffer
+
+		if err := tmpl.Execute(&buf, columns); err != nil {
+			return row, fmt.Errorf("invalid query template: %w", err)
+		}
+
+		row.Query = strings.TrimSpace(buf.String())
+	}
+
+	if row.Query == "" {
+		return row, errors.New("no query")
+	}
+
+	return row, nil
+}
+
+func readCSVRecords(r io.Reader) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a header written by Excel starts with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var records []map[string]string
+
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fields) > len(header) {
+			line, _ := cr.FieldPos(0)
+
+			return nil, fmt.Errorf("line %d: %d fields, the header has %d", line, len(fields), len(header))
+		}
+
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				record[strings.TrimSpace(name)] = fields[i]
+			}
+		}
+
+		records = append(records, record)
+	}
+}
+
+func readJSONLRecords(r io.Reader) ([]map[string]string, error) {
+	var records []map[string]string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, bufio.MaxScanTokenSize*16)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var object map[string]any
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		if err := dec.Decode(&object); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		record := make(map[string]string, len(object))
+		for k, v := range object {
+			switch v := v.(type) {
+			case nil:
+			case string:
+				record[k] = v
+			case json.Number, bool:
+				record[k] = fmt.Sprint(v)
+			default:
+				b, _ := json.Marshal(v)
+				record[k] = string(b)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/isochrone"
+)
+
+// SetupIsochrone fetches the isochrone configured via the command line
+// around cfg.GeoCoordinates and registers a filter dropping the places outside it.
+func SetupIsochrone(ctx context.Context, cfg *Config) error {
+	if cfg.Isochrone == "" {
+		return nil
+	}
+
+	provider, err := isochrone.New(cfg.Isochrone)
+	if err != nil {
+		return err
+	}
+
+	latStr, lonStr, ok := strings.Cut(cfg.GeoCoordinates, ",")
+	if !ok {
+		return fmt.Errorf("invalid geo coordinates: %s", cfg.GeoCoordinates)
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return fmt.Errorf("invalid latitude: %w", err)
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return fmt.Errorf("invalid longitude: %w", err)
+	}
+
+	poly, err := provider.Isochrone(ctx, isochrone.Point{Lat: lat, Lon: lon}, cfg.DriveTime)
+	if err != nil {
+		return fmt.Errorf("failed to get isochrone: %w", err)
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(isochrone.Filter(poly))
+
+	return nil
+}
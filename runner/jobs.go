@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"net/url"
 	"os"
 	"path/filepath"
 	"plugin"
@@ -16,30 +19,73 @@ import (
 	"github.com/gosom/scrapemate"
 )
 
-func CreateSeedJobs(
-	fastmode bool,
-	langCode string,
-	r io.Reader,
-	maxDepth int,
-	email bool,
-	geoCoordinates string,
-	zoom int,
-	radius float64,
-	dedup deduper.Deduper,
-	exitMonitor exiter.Exiter,
-	extraReviews bool,
-	validatePlaceIdUrl string,
-) (jobs []scrapemate.IJob, err error) {
+// maxTiles is the maximum number of tiles of the grid of a search
+const maxTiles = 400
+
+// autoZoom is the zoom level of the fast mode searches whose zoom is chosen
+// from the radius, see gmaps.ZoomForRadius
+const autoZoom = 0
+
+// SeedJobsOptions are the options of the seed jobs created by CreateSeedJobs
+type SeedJobsOptions struct {
+	FastMode       bool
+	LangCode       string
+	MaxDepth       int
+	Email          bool
+	GeoCoordinates string
+	Zoom           int
+	Radius         float64
+	// Polygon and Route replace the area of GeoCoordinates and Radius
+	Polygon *gmaps.Polygon
+	Route   *gmaps.Route
+	// Dedup drops the places found by several searches, it may be nil
+	Dedup              deduper.Deduper
+	ExitMonitor        exiter.Exiter
+	ExtraReviews       bool
+	ValidatePlaceIdUrl string
+	Sample             gmaps.Sample
+	QuarantineDir      string
+	// Nearest keeps the nearest places of every query, it may be nil
+	Nearest        *gmaps.Nearest
+	EmailPages     int
+	ExtraPosts     bool
+	ExtraProducts  bool
+	ExtraMenu      bool
+	ExtraQuestions bool
+	ExtraHotels    bool
+	Completeness   gmaps.Completeness
+	Pages          int
+	Images         *gmaps.ImageDownloader
+	EmailFetcher   *gmaps.EmailFetcher
+	// Lookup looks up the places of the lines instead of searching them
+	Lookup           bool
+	Known            gmaps.Known
+	BrowserFallback  bool
+	Headers          map[string]string
+	ReviewLimits     gmaps.ReviewLimits
+	Viewport         gmaps.Viewport
+	Grid             gmaps.CellGrid
+	Rings            gmaps.Rings
+	Gl               string
+	GoogleDomain     string
+	CategorySearch   bool
+	DeterministicIDs bool
+}
+
+// CreateSeedJobs returns the seed jobs of the queries of r, one per line
+func CreateSeedJobs(r io.Reader, o SeedJobsOptions) (jobs []scrapemate.IJob, err error) {
 	var lat, lon float64
 
-	if fastmode {
-		if geoCoordinates == "" {
-			return nil, fmt.Errorf("geo coordinates are required in fast mode")
-		}
+	// without geo coordinates, polygon or route fast mode searches without location
+	locationless := o.FastMode && o.GeoCoordinates == "" && o.Polygon == nil && o.Route == nil
+	if locationless {
+		slog.Info("fast mode without geo coordinates: searching without location, results are not filtered by radius")
+	}
 
-		parts := strings.Split(geoCoordinates, ",")
+	if o.FastMode && !locationless && o.Polygon == nil && o.Route == nil {
+		parts := strings.Split(o.GeoCoordinates, ",")
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid geo coordinates: %s", geoCoordinates)
+			return nil, fmt.Errorf("invalid geo coordinates: %s", o.GeoCoordinates)
 		}
 
 		lat, err = strconv.ParseFloat(parts[0], 64)
@@ -59,13 +105,90 @@ func CreateSeedJobs(
 		if lon < -180 || lon > 180 {
 			return nil, fmt.Errorf("invalid longitude: %f", lon)
 		}
+	}
+
+	if o.FastMode && !locationless {
+		if o.Zoom < autoZoom || o.Zoom > 21 {
+			return nil, fmt.Errorf("invalid zoom level: %d", o.Zoom)
+		}
+
+		if o.Radius < 0 {
+			return nil, fmt.Errorf("invalid radius: %f", o.Radius)
+		}
+
+		// the zoom of a route is chosen from its corridor
+		if o.Route == nil && (o.Completeness != "" || o.Zoom == autoZoom) {
+			if o.Polygon != nil {
+				c := o.Polygon.Circle(o.Zoom)
+				lat, o.Radius = c.Lat, c.Radius
+			}
+
+			o.Zoom = gmaps.ZoomForRadius(lat, o.Radius, o.Viewport)
+
+			if o.Completeness != "" {
+				slog.Info("searching at the zoom of the completeness", "completeness", o.Completeness, "zoom", o.Zoom)
+			} else {
+				slog.Info("searching at the zoom covering the radius", "radius", o.Radius, "zoom", o.Zoom)
+			}
+		}
+	}
+
+	area := gmaps.MapLocation{
+		Lat:     lat,
+		Lon:     lon,
+		ZoomLvl: float64(o.Zoom),
+		Radius:  o.Radius,
+	}
+
+	tiles := []gmaps.MapLocation{area}
+
+	switch {
+	case o.FastMode && o.Route != nil:
+		routeZoom := o.Zoom
+		if o.Completeness != "" {
+			routeZoom = autoZoom
+		}
+
+		if tiles, err = o.Route.Tiles(routeZoom, o.Viewport, maxTiles); err != nil {
+			return nil, err
+		}
+
+		slog.Info("searching the tiles along the route", "tiles", len(tiles), "length_m", math.Round(o.Route.Length()), "width_m", o.Route.Width())
+	case o.FastMode && !o.Grid.IsZero() && o.Polygon != nil:
+		if tiles, err = o.Grid.PolygonTiles(o.Polygon, o.Viewport, maxTiles); err != nil {
+			return nil, err
+		}
+
+		slog.Info("searching the cells covering the area", "cells", len(tiles), "grid", o.Grid.String())
+	case o.FastMode && !o.Grid.IsZero() && !locationless:
+		if tiles, err = o.Grid.AreaTiles(area, o.Viewport, maxTiles); err != nil {
+			return nil, err
+		}
+
+		slog.Info("searching the cells covering the radius", "cells", len(tiles), "grid", o.Grid.String())
+	case o.FastMode && !o.Rings.IsZero() && !locationless:
+		if tiles, err = o.Rings.Tiles(area, o.Viewport, maxTiles); err != nil {
+			return nil, err
+		}
 
-		if zoom < 1 || zoom > 21 {
-			return nil, fmt.Errorf("invalid zoom level: %d", zoom)
+		slog.Info("searching the rings around the center", "tiles", len(tiles), "rings", o.Rings.String(), "radius", o.Radius)
+	case o.FastMode && o.Polygon != nil:
+		tiles = o.Polygon.Tiles(o.Zoom, o.Viewport)
+
+		if len(tiles) > maxTiles {
+			return nil, fmt.Errorf("the area needs %d tiles at zoom %d, more than %d: lower the zoom or use -completeness", len(tiles), o.Zoom, maxTiles)
 		}
 
-		if radius < 0 {
-			return nil, fmt.Errorf("invalid radius: %f", radius)
+		slog.Info("searching the tiles covering the area", "tiles", len(tiles), "zoom", o.Zoom)
+	case o.FastMode && !locationless:
+		tiles = gmaps.TileArea(area, o.Viewport)
+
+		if len(tiles) > maxTiles {
+			return nil, fmt.Errorf("the radius needs %d tiles at zoom %d, more than %d: lower the zoom or use -completeness", len(tiles), o.Zoom, maxTiles)
+		}
+
+		if len(tiles) > 1 {
+			slog.Info("the radius is larger than the viewport: searching tiles", "tiles", len(tiles), "zoom", o.Zoom)
 		}
 	}
 
@@ -77,9 +200,55 @@ func CreateSeedJobs(
 			continue
 		}
 
+		var (
+			id       string
+			metadata map[string]string
+		)
+
+		if before, after, ok := strings.Cut(query, "#!#"); ok {
+			query = strings.TrimSpace(before)
+			id = strings.TrimSpace(after)
+		}
+
+		if before, after, ok := strings.Cut(id, "#!#"); ok {
+			id = strings.TrimSpace(before)
+
+			if metadata, err = parseMetadata(after); err != nil {
+				return nil, err
+			}
+		}
+
+		if o.Lookup {
+			if id == "" {
+				id = query
+			}
+
+			jopts := o.placeJobOptions()
+			if len(metadata) > 0 {
+				jopts = append(jopts, gmaps.WithPlaceJobMetadata(metadata))
+			}
+
+			if o.Gl != "" {
+				jopts = append(jopts, gmaps.WithPlaceJobGl(o.Gl))
+			}
+
+			if len(o.Headers) > 0 {
+				jopts = append(jopts, gmaps.WithPlaceJobHeaders(o.Headers))
+			}
+
+			job, err := gmaps.NewPlaceLookupJob(id, o.LangCode, query, o.Email, o.ExtraReviews, jopts...)
+			if err != nil {
+				return nil, err
+			}
+
+			jobs = append(jobs, job)
+
+			continue
+		}
+
 		// Clean URLs that are mistakenly used as search terms
 		if strings.HasPrefix(query, "http") {
-			fmt.Printf("WARNING: Input looks like a URL: %s\nCleaning for better search results.\n", query)
+			slog.Warn("input looks like a url, cleaning it for better search results", "query", query)
 
 			// For Google Maps URLs, extract meaningful parts
 			if strings.Contains(query, "google.com/maps") {
@@ -94,7 +263,7 @@ func CreateSeedJobs(
 						!strings.Contains(part, ",") &&
 						!strings.Contains(part, ".") {
 						query = part
-						fmt.Printf("Extracted query: %s\n", query)
+						slog.Info("query extracted from the url", "query", query)
 						cleaned = true
 						break
 					}
@@ -103,76 +272,257 @@ func CreateSeedJobs(
 				// If we couldn't find a good part, use a generic term
 				if !cleaned {
 					query = "restaurant"
-					fmt.Println("Could not extract meaningful search term from URL. Using 'business'.")
+					slog.Warn("no search term in the url, using the default one", "query", query)
 				}
 			} else {
 				// For regular URLs, use the domain
 				parts := strings.Split(query, "/")
 				if len(parts) > 2 {
 					query = parts[2] // Usually the domain name
-					fmt.Printf("Using domain as query: %s\n", query)
+					slog.Info("using the domain of the url as query", "query", query)
 				}
 			}
 		}
 
-		var id string
+		if !o.FastMode {
+			opts := []gmaps.GmapJobOptions{}
 
-		if before, after, ok := strings.Cut(query, "#!#"); ok {
-			query = strings.TrimSpace(before)
-			id = strings.TrimSpace(after)
-		}
+			if o.Dedup != nil {
+				opts = append(opts, gmaps.WithDeduper(o.Dedup))
+			}
 
-		var job scrapemate.IJob
+			if o.ExitMonitor != nil {
+				opts = append(opts, gmaps.WithExitMonitor(o.ExitMonitor))
+			}
 
-		if !fastmode {
-			opts := []gmaps.GmapJobOptions{}
+			if o.ExtraReviews {
+				opts = append(opts, gmaps.WithExtraReviews())
+			}
 
-			if dedup != nil {
-				opts = append(opts, gmaps.WithDeduper(dedup))
+			if !o.ReviewLimits.IsZero() {
+				opts = append(opts, gmaps.WithReviewLimits(o.ReviewLimits))
 			}
 
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithExitMonitor(exitMonitor))
+			if o.ValidatePlaceIdUrl != "" {
+				opts = append(opts, gmaps.WithValidatePlaceIdUrl(o.ValidatePlaceIdUrl))
 			}
 
-			if extraReviews {
-				opts = append(opts, gmaps.WithExtraReviews())
+			if !o.Sample.IsZero() {
+				opts = append(opts, gmaps.WithSample(o.Sample))
 			}
 
-			if validatePlaceIdUrl != "" {
-				opts = append(opts, gmaps.WithValidatePlaceIdUrl(validatePlaceIdUrl))
+			if o.EmailPages > 1 {
+				opts = append(opts, gmaps.WithEmailPages(o.EmailPages))
 			}
 
-			job = gmaps.NewGmapJob(id, langCode, query, maxDepth, email, geoCoordinates, zoom, validatePlaceIdUrl, opts...)
-		} else {
-			jparams := gmaps.MapSearchParams{
-				Location: gmaps.MapLocation{
-					Lat:     lat,
-					Lon:     lon,
-					ZoomLvl: float64(zoom),
-					Radius:  radius,
-				},
-				Query:     query,
-				ViewportW: 1920,
-				ViewportH: 450,
-				Hl:        langCode,
+			if o.ExtraPosts {
+				opts = append(opts, gmaps.WithExtraPosts())
 			}
 
+			if o.ExtraProducts {
+				opts = append(opts, gmaps.WithExtraProducts())
+			}
+
+			if o.ExtraMenu {
+				opts = append(opts, gmaps.WithExtraMenu())
+			}
+
+			if o.ExtraQuestions {
+				opts = append(opts, gmaps.WithExtraQuestions())
+			}
+
+			if o.ExtraHotels {
+				opts = append(opts, gmaps.WithExtraHotels())
+			}
+
+			if o.Images != nil {
+				opts = append(opts, gmaps.WithImages(o.Images))
+			}
+
+			if o.EmailFetcher != nil {
+				opts = append(opts, gmaps.WithEmailFetcher(o.EmailFetcher))
+			}
+
+			if o.Known != nil {
+				opts = append(opts, gmaps.WithKnown(o.Known))
+			}
+
+			if len(metadata) > 0 {
+				opts = append(opts, gmaps.WithMetadata(metadata))
+			}
+
+			if o.DeterministicIDs {
+				opts = append(opts, gmaps.WithDeterministicID())
+			}
+
+			if o.Gl != "" || o.GoogleDomain != "" {
+				opts = append(opts, gmaps.WithRegion(o.Gl, o.GoogleDomain))
+			}
+
+			if len(o.Headers) > 0 {
+				opts = append(opts, gmaps.WithHeaders(o.Headers))
+			}
+
+			jobs = append(jobs, gmaps.NewGmapJob(id, o.LangCode, query, o.MaxDepth, o.Email, o.GeoCoordinates, o.Zoom, o.ValidatePlaceIdUrl, opts...))
+		} else {
 			opts := []gmaps.SearchJobOptions{}
 
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithSearchJobExitMonitor(exitMonitor))
+			if o.ExitMonitor != nil {
+				opts = append(opts, gmaps.WithSearchJobExitMonitor(o.ExitMonitor))
 			}
 
-			job = gmaps.NewSearchJob(&jparams, opts...)
-		}
+			if !o.Sample.IsZero() {
+				opts = append(opts, gmaps.WithSearchJobSample(o.Sample))
+			}
+
+			if o.QuarantineDir != "" {
+				opts = append(opts, gmaps.WithSearchJobQuarantine(o.QuarantineDir))
+			}
+
+			if o.Nearest != nil {
+				opts = append(opts, gmaps.WithSearchJobNearest(o.Nearest))
+			}
+
+			if o.Pages > 1 {
+				opts = append(opts, gmaps.WithSearchJobPages(o.Pages))
+			}
+
+			if o.ExtraReviews {
+				opts = append(opts, gmaps.WithSearchJobReviews(0))
+			}
+
+			if !o.ReviewLimits.IsZero() {
+				opts = append(opts, gmaps.WithSearchJobReviewLimits(o.ReviewLimits))
+			}
+
+			if o.Completeness != "" && !locationless {
+				opts = append(opts, gmaps.WithSearchJobCompleteness(o.Completeness))
+			}
+
+			if o.BrowserFallback {
+				opts = append(opts, gmaps.WithSearchJobBrowserFallback(o.MaxDepth))
+			}
+
+			if len(o.Headers) > 0 {
+				opts = append(opts, gmaps.WithSearchJobHeaders(o.Headers))
+			}
+
+			if o.DeterministicIDs {
+				opts = append(opts, gmaps.WithSearchJobDeterministicID())
+			}
+
+			switch {
+			case o.Route != nil:
+				opts = append(opts, gmaps.WithSearchJobRoute(o.Route))
+			case o.Polygon != nil:
+				opts = append(opts, gmaps.WithSearchJobPolygon(o.Polygon))
+			case len(tiles) > 1 || !o.Grid.IsZero():
+				opts = append(opts, gmaps.WithSearchJobArea(area))
+			}
+
+			// the subdivided tiles and the tiles of the grid overlap
+			if o.Dedup != nil && (len(tiles) > 1 || (o.Completeness != "" && !locationless)) {
+				opts = append(opts, gmaps.WithSearchJobDeduper(o.Dedup))
+			}
+
+			for _, tile := range tiles {
+				jparams := gmaps.MapSearchParams{
+					Location:     tile,
+					Query:        query,
+					Locationless: locationless,
+					ViewportW:    o.Viewport.Width,
+					ViewportH:    o.Viewport.Height,
+					Hl:           o.LangCode,
+					Gl:           o.Gl,
+					Domain:       o.GoogleDomain,
+					Metadata:     metadata,
+				}
+
+				// the line is a category browsed around the location
+				if o.CategorySearch {
+					jparams.Query, jparams.Category = "", query
+				}
 
-		jobs = append(jobs, job)
+				jobs = append(jobs, gmaps.NewSearchJob(&jparams, opts...))
+			}
+		}
 	}
 
 	return jobs, scanner.Err()
 }
 
+// parseMetadata parses the metadata of an input line, the key=value pairs
+// of a query string like campaign=spring&row=12
+func parseMetadata(s string) (map[string]string, error) {
+	values, err := url.ParseQuery(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid input metadata %q: %w", s, err)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	ans := make(map[string]string, len(values))
+	for k, v := range values {
+		ans[k] = strings.Join(v, ",")
+	}
+
+	return ans, nil
+}
+
+// placeJobOptions are the options of the place jobs of the lookups, the
+// GmapJobs pass the same options to the place jobs of their results
+func (o *SeedJobsOptions) placeJobOptions() []gmaps.PlaceJobOptions {
+	opts := []gmaps.PlaceJobOptions{}
+
+	if o.ExitMonitor != nil {
+		opts = append(opts, gmaps.WithPlaceJobExitMonitor(o.ExitMonitor))
+	}
+
+	if o.EmailPages > 1 {
+		opts = append(opts, gmaps.WithPlaceJobEmailPages(o.EmailPages))
+	}
+
+	if !o.ReviewLimits.IsZero() {
+		opts = append(opts, gmaps.WithPlaceJobReviewLimits(o.ReviewLimits))
+	}
+
+	if o.ExtraPosts {
+		opts = append(opts, gmaps.WithPlaceJobPosts())
+	}
+
+	if o.ExtraProducts {
+		opts = append(opts, gmaps.WithPlaceJobProducts())
+	}
+
+	if o.ExtraMenu {
+		opts = append(opts, gmaps.WithPlaceJobMenu())
+	}
+
+	if o.ExtraQuestions {
+		opts = append(opts, gmaps.WithPlaceJobQuestions())
+	}
+
+	if o.ExtraHotels {
+		opts = append(opts, gmaps.WithPlaceJobHotels())
+	}
+
+	if o.Images != nil {
+		opts = append(opts, gmaps.WithPlaceJobImages(o.Images))
+	}
+
+	if o.EmailFetcher != nil {
+		opts = append(opts, gmaps.WithPlaceJobEmailFetcher(o.EmailFetcher))
+	}
+
+	if o.DeterministicIDs {
+		opts = append(opts, gmaps.WithPlaceJobDeterministicID())
+	}
+
+	return opts
+}
+
 func LoadCustomWriter(pluginDir, pluginName string) (scrapemate.ResultWriter, error) {
 	files, err := os.ReadDir(pluginDir)
 	if err != nil {
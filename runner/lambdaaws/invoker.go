@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strings"
 
@@ -82,8 +82,8 @@ func (i *invoker) invoke(ctx context.Context, input lInput) error {
 		return err
 	}
 
-	log.Printf("Lambda function %s invoked with JobID %s, Part %d, StatusCode %d\n",
-		input.FunctionName, input.JobID, input.Part, result.StatusCode)
+	slog.Info("lambda function invoked", "function", input.FunctionName, "job_id", input.JobID,
+		"part", input.Part, "status_code", result.StatusCode)
 
 	return nil
 }
@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -78,20 +78,14 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 
 	exitMonitor := exiter.New()
 
-	seedJobs, err = runner.CreateSeedJobs(
-		false, // TODO supoort fast mode
-		input.Language,
-		in,
-		input.Depth,
-		false,
-		"",
-		0,
-		10000, // TODO support radius
-		nil,
-		exitMonitor,
-		input.ExtraReviews,
-		"",
-	)
+	seedJobs, err = runner.CreateSeedJobs(in, runner.SeedJobsOptions{
+		// TODO supoort fast mode
+		LangCode:     input.Language,
+		MaxDepth:     input.Depth,
+		Radius:       10000, // TODO support radius
+		ExitMonitor:  exitMonitor,
+		ExtraReviews: input.ExtraReviews,
+	})
 	if err != nil {
 		return err
 	}
@@ -125,14 +119,14 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 			return err
 		}
 	} else {
-		log.Println("no uploader set results are at ", out.Name())
+		slog.Warn("no uploader set", "results", out.Name())
 	}
 
 	return nil
 }
 
 //nolint:gocritic // we pass a value to the handler
-func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out io.Writer) (*scrapemateapp.ScrapemateApp, error) {
+func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out io.Writer) (*runner.App, error) {
 	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(out))
 
 	writers := []scrapemate.ResultWriter{csvWriter}
@@ -155,7 +149,7 @@ func (l *lambdaAwsRunner) getApp(_ context.Context, input lInput, out io.Writer)
 		return nil, err
 	}
 
-	app, err := scrapemateapp.NewScrapeMateApp(mateCfg)
+	app, err := runner.NewApp(mateCfg)
 	if err != nil {
 		return nil, err
 	}
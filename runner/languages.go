@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// MergeLanguages returns a writer that merges the entries of the same place
+// found in the languages of cfg.ExtraLangs into its entry in cfg.LangCode,
+// with their names in Localized, before passing them to next. A place is
+// written once it is found in all the languages or when the results end, in
+// the first language found when it has no entry in cfg.LangCode.
+func MergeLanguages(cfg *Config, next scrapemate.ResultWriter) scrapemate.ResultWriter {
+	if len(cfg.ExtraLangs) == 0 {
+		return next
+	}
+
+	return &languageMerger{
+		primary: cfg.LangCode,
+		langs:   len(cfg.ExtraLangs) + 1,
+		next:    next,
+	}
+}
+
+type languageMerger struct {
+	primary string
+	langs   int
+	next    scrapemate.ResultWriter
+}
+
+// localizedPlace are the entries of a place by language, and the job and the
+// language of the first one
+type localizedPlace struct {
+	job     scrapemate.IJob
+	first   string
+	entries map[string]*gmaps.Entry
+}
+
+func (m *languageMerger) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- m.next.Run(ctx, out)
+	}()
+
+	var (
+		places = make(map[string]*localizedPlace)
+		order  []string
+	)
+
+	send := func(result scrapemate.Result) error {
+		select {
+		case out <- result:
+			return nil
+		case err := <-done:
+			// keep consuming so that the producer does not block
+			go func() {
+				for range in {
+				}
+			}()
+
+			return err
+		}
+	}
+
+	add := func(job scrapemate.IJob, entry *gmaps.Entry) error {
+		key := entry.Key()
+		if key == "" {
+			return send(scrapemate.Result{Job: job, Data: entry})
+		}
+
+		p, ok := places[key]
+		if !ok {
+			p = &localizedPlace{job: job, first: entry.Language, entries: make(map[string]*gmaps.Entry, m.langs)}
+			places[key] = p
+			order = append(order, key)
+		}
+
+		if _, ok := p.entries[entry.Language]; !ok {
+			p.entries[entry.Language] = entry
+		}
+
+		if len(p.entries) < m.langs {
+			return nil
+		}
+
+		delete(places, key)
+
+		return send(scrapemate.Result{Job: p.job, Data: m.merge(p)})
+	}
+
+	for result := range in {
+		var err error
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			err = add(result.Job, data)
+		case []*gmaps.Entry:
+			for i := range data {
+				if err = add(result.Job, data[i]); err != nil {
+					break
+				}
+			}
+		default:
+			err = send(result)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// the places missing from some languages
+	for _, key := range order {
+		p, ok := places[key]
+		if !ok {
+			continue
+		}
+
+		if err := send(scrapemate.Result{Job: p.job, Data: m.merge(p)}); err != nil {
+			return err
+		}
+	}
+
+	close(out)
+
+	return <-done
+}
+
+// merge returns the entry of the place in the primary language, or in the
+// first language found, with the names of the others
+func (m *languageMerger) merge(p *localizedPlace) *gmaps.Entry {
+	ans, ok := p.entries[m.primary]
+	if !ok {
+		ans = p.entries[p.first]
+	}
+
+	for _, e := range p.entries {
+		ans.Localize(e)
+	}
+
+	return ans
+}
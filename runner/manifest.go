@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"flag"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+const (
+	// manifestExt is appended to the results file for the default manifest
+	manifestExt = ".manifest.json"
+	// manifestNone disables the manifest
+	manifestNone = "none"
+)
+
+// redacted replaces the secrets in the flags of the manifest
+const redacted = "REDACTED"
+
+// secretFlags are the parts of the names of the flags holding secrets
+var secretFlags = []string{"token", "secret", "pass", "api-key", "access-key", "credentials"}
+
+// urlPassword matches the password of the userinfo of a URL, e.g. of a DSN
+// or of a proxy
+var urlPassword = regexp.MustCompile(`(://[^:/@\s]*:)[^@\s]+@`)
+
+// EffectiveFlags returns the values of all the flags of the run, the ones of
+// the command line, of the configuration file and of the environment, and the
+// defaults of the others. The secrets are redacted: the values of the flags
+// of tokens, passwords and keys and the passwords of the URLs.
+func EffectiveFlags() map[string]string {
+	ans := make(map[string]string)
+
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+
+		for _, part := range secretFlags {
+			if value != "" && strings.Contains(f.Name, part) {
+				value = redacted
+			}
+		}
+
+		ans[f.Name] = urlPassword.ReplaceAllString(value, "${1}"+redacted+"@")
+	})
+
+	return ans
+}
+
+// BuildVersion is the version of the binary of the run
+type BuildVersion struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+	Go       string `json:"go"`
+}
+
+// Build returns the version of the module of the binary and the revision it
+// was built from, when the binary has them
+func Build() BuildVersion {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildVersion{}
+	}
+
+	ans := BuildVersion{Version: info.Main.Version, Go: info.GoVersion}
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			ans.Revision = s.Value
+		case "vcs.modified":
+			ans.Modified = s.Value == "true"
+		}
+	}
+
+	return ans
+}
@@ -0,0 +1,355 @@
+package monitorrunner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/scrapemateapp"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/kafka"
+	"github.com/gosom/google-maps-scraper/monitor"
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+// defaultInactivity ends a check whose last lookups hang, without
+// -exit-on-inactivity
+const defaultInactivity = time.Minute
+
+type monitorRunner struct {
+	cfg     *runner.Config
+	places  []string
+	tracker *monitor.Tracker
+
+	out     io.WriteCloser
+	events  *json.Encoder
+	webhook *webhook.Writer
+	kafka   *kafka.Writer
+}
+
+// New returns the runner of -monitor: the places of the file are looked up
+// every -monitor-interval and the changes of their tracked fields are emitted
+// as events
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeMonitor {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	places, err := readPlaces(cfg.Monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := monitorRunner{
+		cfg:     cfg,
+		places:  places,
+		tracker: monitor.NewTracker(cfg.MonitorFields),
+	}
+
+	if cfg.MonitorState != "" {
+		if err := runner.SetupState(context.Background(), cfg); err != nil {
+			return nil, err
+		}
+
+		if err := ans.tracker.Load(context.Background(), cfg.State, cfg.MonitorState); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ResultsFile == "stdout" {
+		ans.out = nopCloser{os.Stdout}
+	} else {
+		// the events of the previous runs are kept
+		f, err := os.OpenFile(cfg.ResultsFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the events file: %w", err)
+		}
+
+		ans.out = f
+	}
+
+	ans.events = json.NewEncoder(ans.out)
+
+	if cfg.Webhook != "" {
+		ans.webhook = webhook.NewWriter(cfg.Webhook, webhook.WithSecret(cfg.WebhookSecret))
+	}
+
+	if len(cfg.Kafka) > 0 {
+		if ans.kafka, err = kafka.NewWriter(cfg.Kafka, cfg.KafkaTopic); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ans, nil
+}
+
+func (r *monitorRunner) Run(ctx context.Context) error {
+	slog.Info("monitor: started", "places", len(r.places), "interval", r.cfg.MonitorInterval,
+		"fields", strings.Join(r.cfg.MonitorFields, ","))
+
+	ticker := time.NewTicker(r.cfg.MonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.check(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("monitor: the check failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("monitor: stopped")
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *monitorRunner) Close(context.Context) error {
+	var err error
+
+	if r.kafka != nil {
+		err = r.kafka.Close()
+	}
+
+	return errors.Join(err, r.out.Close(), runner.CloseState(r.cfg))
+}
+
+// check looks the places up, emits the events of their changes and saves
+// their values
+func (r *monitorRunner) check(ctx context.Context) error {
+	start := time.Now()
+
+	entries, failed, err := r.lookup(ctx)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+
+	for _, e := range entries {
+		event, ok := r.tracker.Update(monitor.PlaceOf(e, start))
+		if !ok {
+			continue
+		}
+
+		changed++
+
+		r.emit(ctx, &event)
+	}
+
+	slog.Info("monitor: places checked", "checked", len(entries), "failed", failed, "changed", changed,
+		"duration", time.Since(start).Round(time.Second).String())
+
+	if r.cfg.MonitorState != "" {
+		return r.tracker.Save(context.WithoutCancel(ctx), r.cfg.State, r.cfg.MonitorState)
+	}
+
+	return nil
+}
+
+// emit writes the event to the events file and delivers it to the webhook and
+// to Kafka. An event that cannot be delivered is logged so that the monitor
+// goes on.
+func (r *monitorRunner) emit(ctx context.Context, event *monitor.Event) {
+	if err := r.events.Encode(event); err != nil {
+		slog.Error("monitor: cannot write the event", "place", event.Title, "error", err)
+	}
+
+	if r.webhook != nil {
+		if err := r.webhook.Post(ctx, event); err != nil {
+			slog.Warn("monitor: event not delivered to the webhook", "place", event.Title, "error", err)
+		}
+	}
+
+	if r.kafka != nil {
+		if err := r.kafka.Publish(ctx, event.ID, event); err != nil {
+			slog.Warn("monitor: event not delivered to kafka", "place", event.Title, "error", err)
+		}
+	}
+}
+
+// lookup fetches the places with a new app, so that the checks do not share
+// state. It returns the entries found and the number of the failed lookups.
+func (r *monitorRunner) lookup(ctx context.Context) ([]*gmaps.Entry, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := collector{total: len(r.places), done: cancel}
+
+	jobs := make([]scrapemate.IJob, 0, len(r.places))
+
+	for _, ref := range r.places {
+		var opts []gmaps.PlaceJobOptions
+		if r.cfg.Gl != "" {
+			opts = append(opts, gmaps.WithPlaceJobGl(r.cfg.Gl))
+		}
+
+		if len(r.cfg.Headers) > 0 {
+			opts = append(opts, gmaps.WithPlaceJobHeaders(r.cfg.Headers))
+		}
+
+		job, err := gmaps.NewPlaceLookupJob(ref, r.cfg.LangCode, ref, false, false, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	inactivity := r.cfg.ExitOnInactivityDuration
+	if inactivity <= 0 {
+		inactivity = defaultInactivity
+	}
+
+	opts := []func(*scrapemateapp.Config) error{
+		scrapemateapp.WithConcurrency(r.cfg.Concurrency),
+		scrapemateapp.WithExitOnInactivity(inactivity),
+	}
+
+	if len(r.cfg.Proxies) > 0 {
+		opts = append(opts, scrapemateapp.WithProxies(r.cfg.Proxies))
+	}
+
+	if r.cfg.Debug {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.Headfull(), scrapemateapp.DisableImages()))
+	} else {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.DisableImages()))
+	}
+
+	if !r.cfg.DisablePageReuse {
+		opts = append(opts, scrapemateapp.WithPageReuseLimit(2))
+	}
+
+	matecfg, err := scrapemateapp.NewConfig([]scrapemate.ResultWriter{&c}, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	app, err := runner.NewApp(matecfg, append(r.cfg.AppOptions(), runner.WithFailedJobs(func(scrapemate.IJob) {
+		c.fail()
+	}))...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer app.Close()
+
+	err = app.Start(ctx, jobs...)
+	if err != nil && !errors.Is(err, scrapemate.ErrInactivityTimeout) && !errors.Is(err, context.Canceled) {
+		return nil, 0, err
+	}
+
+	entries, failed := c.results()
+
+	return entries, failed, nil
+}
+
+var _ scrapemate.ResultWriter = (*collector)(nil)
+
+// collector keeps the entries of a check and ends it once every place is
+// found or failed
+type collector struct {
+	total int
+	done  context.CancelFunc
+
+	mu      sync.Mutex
+	entries []*gmaps.Entry
+	failed  int
+}
+
+func (c *collector) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		if e, ok := result.Data.(*gmaps.Entry); ok {
+			c.mu.Lock()
+			c.entries = append(c.entries, e)
+			c.mu.Unlock()
+
+			c.checkDone()
+		}
+	}
+
+	return nil
+}
+
+func (c *collector) fail() {
+	c.mu.Lock()
+	c.failed++
+	c.mu.Unlock()
+
+	c.checkDone()
+}
+
+func (c *collector) checkDone() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries)+c.failed >= c.total {
+		c.done()
+	}
+}
+
+func (c *collector) results() ([]*gmaps.Entry, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries, c.failed
+}
+
+// readPlaces reads the places of the file: Google Maps urls, cids or data
+// ids, one per line. The empty lines and the lines starting with # are
+// skipped.
+func readPlaces(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the monitored places: %w", err)
+	}
+
+	defer f.Close()
+
+	var places []string
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if _, err := gmaps.PlaceURL(line); err != nil {
+			return nil, fmt.Errorf("invalid monitored place %q: %w", line, err)
+		}
+
+		places = append(places, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the monitored places: %w", err)
+	}
+
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no places to monitor in %s", path)
+	}
+
+	return places, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
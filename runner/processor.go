@@ -0,0 +1,268 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/scripting"
+)
+
+// SetupProcessors loads the processors configured via the command line and
+// registers them as after parse functions of cfg.Middleware.
+// The gmaps.Processor of a plugin runs before the entries are written instead.
+func SetupProcessors(cfg *Config) error {
+	var (
+		fns        []gmaps.AfterParseFunc
+		processors []gmaps.Processor
+	)
+
+	if cfg.CustomProcessor != "" {
+		dir, symbol, ok := strings.Cut(cfg.CustomProcessor, ":")
+		if !ok {
+			return fmt.Errorf("invalid custom processor format: %s", cfg.CustomProcessor)
+		}
+
+		sym, name, err := lookupPluginSymbol(dir, symbol)
+		if err != nil {
+			return err
+		}
+
+		switch fn := sym.(type) {
+		case *gmaps.Processor:
+			processors = append(processors, *fn)
+		case func(context.Context, *gmaps.Entry) (*gmaps.Entry, error):
+			processors = append(processors, fn)
+		default:
+			afterParse, err := afterParseSymbol(sym, name)
+			if err != nil {
+				return err
+			}
+
+			fns = append(fns, afterParse)
+		}
+	}
+
+	if cfg.ProcessorCmd != "" {
+		p, err := NewExecProcessor(cfg.ProcessorCmd)
+		if err != nil {
+			return err
+		}
+
+		cfg.Processor = p
+		fns = append(fns, p.Process)
+	}
+
+	if cfg.Script != "" {
+		s, err := scripting.Load(cfg.Script)
+		if err != nil {
+			return err
+		}
+
+		fns = append(fns, s.Process)
+	}
+
+	if len(fns) == 0 && len(processors) == 0 {
+		return nil
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(fns...)
+	cfg.Middleware.UseProcessors(processors...)
+
+	return nil
+}
+
+// LoadCustomProcessor loads a processor from a Go plugin.
+// The plugin must export a variable of type gmaps.AfterParseFunc
+// or a function with the same signature.
+func LoadCustomProcessor(pluginDir, symbolName string) (gmaps.AfterParseFunc, error) {
+	sym, name, err := lookupPluginSymbol(pluginDir, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	return afterParseSymbol(sym, name)
+}
+
+func afterParseSymbol(sym plugin.Symbol, name string) (gmaps.AfterParseFunc, error) {
+	switch fn := sym.(type) {
+	case *gmaps.AfterParseFunc:
+		return *fn, nil
+	case func(context.Context, scrapemate.IJob, *gmaps.Entry) error:
+		return fn, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T from processor symbol in plugin %s", sym, name)
+	}
+}
+
+// lookupPluginSymbol returns the symbol of the first plugin of the directory
+// exporting it and the name of the plugin
+func lookupPluginSymbol(pluginDir, symbolName string) (plugin.Symbol, string, error) {
+	files, err := os.ReadDir(pluginDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		if filepath.Ext(file.Name()) != ".so" && filepath.Ext(file.Name()) != ".dll" {
+			continue
+		}
+
+		p, err := plugin.Open(filepath.Join(pluginDir, file.Name()))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open plugin %s: %w", file.Name(), err)
+		}
+
+		sym, err := p.Lookup(symbolName)
+		if err != nil {
+			// the symbol may be in another plugin of the directory
+			continue
+		}
+
+		return sym, file.Name(), nil
+	}
+
+	return nil, "", fmt.Errorf("no plugin exporting %s found in %s", symbolName, pluginDir)
+}
+
+// ExecProcessor runs an external program and exchanges entries with it
+// as newline delimited JSON.
+// For every entry one line is written to the stdin of the program and the program
+// must answer with one line in stdout: the (possibly modified) entry,
+// or an empty line / null to drop the entry.
+type ExecProcessor struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	closed bool
+}
+
+// NewExecProcessor starts the command line and returns a processor using it.
+func NewExecProcessor(cmdline string) (*ExecProcessor, error) {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty processor command")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // the command is provided by the user
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start processor command: %w", err)
+	}
+
+	ans := ExecProcessor{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}
+
+	return &ans, nil
+}
+
+// Process sends the entry to the external program and replaces it
+// with the answer.
+func (p *ExecProcessor) Process(_ context.Context, _ scrapemate.IJob, entry *gmaps.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return errors.New("the processor is closed")
+	}
+
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to processor: %w", err)
+	}
+
+	line, err := p.stdout.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read from processor: %w", err)
+	}
+
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || bytes.Equal(line, []byte("null")) {
+		return gmaps.ErrSkipEntry
+	}
+
+	var processed gmaps.Entry
+	if err := json.Unmarshal(line, &processed); err != nil {
+		return fmt.Errorf("invalid entry from processor: %w", err)
+	}
+
+	*entry = processed
+
+	return nil
+}
+
+// Close closes the stdin of the external program and waits until it exits.
+// It returns an error when the program fails.
+func (p *ExecProcessor) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+
+	if err := p.stdin.Close(); err != nil {
+		_ = p.cmd.Process.Kill()
+		_ = p.cmd.Wait()
+
+		return fmt.Errorf("failed to close the processor stdin: %w", err)
+	}
+
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("processor command failed: %w", err)
+	}
+
+	return nil
+}
+
+// CloseProcessors stops the program of the processor started by
+// SetupProcessors
+func CloseProcessors(cfg *Config) error {
+	if cfg.Processor == nil {
+		return nil
+	}
+
+	return cfg.Processor.Close()
+}
@@ -0,0 +1,130 @@
+package proxycheckrunner
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/proxypool"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// stealthBrowser is the browser impersonated by the requests, the one of the
+// fast mode
+const stealthBrowser = "firefox"
+
+type proxyCheckRunner struct {
+	cfg *runner.Config
+}
+
+// New returns the runner of the check-proxies command: every proxy of
+// -proxies and -proxy-file requests a Maps page, its latency, its blocking and
+// its exit IP are written to -results
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeCheckProxies {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	return &proxyCheckRunner{cfg: cfg}, nil
+}
+
+func (r *proxyCheckRunner) Run(ctx context.Context) error {
+	jars := fetcher.NewCookieJars()
+
+	newFetcher := func(proxy string) (scrapemate.HTTPFetcher, error) {
+		rotator, err := fetcher.NewRotator([]string{proxy})
+		if err != nil {
+			return nil, err
+		}
+
+		return fetcher.NewStealth(stealthBrowser, rotator, jars, r.cfg.UserAgents), nil
+	}
+
+	slog.Info("checking the proxies", "proxies", len(r.cfg.Proxies), "concurrency", r.cfg.Concurrency)
+
+	results := proxypool.Check(ctx, r.cfg.Proxies, newFetcher, proxypool.CheckConfig{
+		Concurrency: r.cfg.Concurrency,
+	})
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	healthy := 0
+
+	for i := range results {
+		if results[i].OK() {
+			healthy++
+		}
+	}
+
+	if err := r.writeReport(results); err != nil {
+		return err
+	}
+
+	slog.Info("proxies checked", "healthy", healthy, "failed", len(results)-healthy, "proxies", len(results))
+
+	return nil
+}
+
+func (r *proxyCheckRunner) Close(context.Context) error {
+	return nil
+}
+
+// writeReport writes the results to the results file, as JSON with -json or
+// as CSV
+func (r *proxyCheckRunner) writeReport(results []proxypool.CheckResult) (err error) {
+	var w io.Writer = os.Stdout
+
+	if r.cfg.ResultsFile != "stdout" {
+		f, err := os.Create(r.cfg.ResultsFile)
+		if err != nil {
+			return fmt.Errorf("cannot create the report: %w", err)
+		}
+
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+
+		w = f
+	}
+
+	if r.cfg.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(results)
+	}
+
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{"proxy", "status", "status_code", "latency_ms", "exit_ip", "country", "error"})
+
+	for i := range results {
+		res := &results[i]
+
+		_ = cw.Write([]string{
+			res.Proxy,
+			res.Status,
+			strconv.Itoa(res.StatusCode),
+			strconv.FormatInt(res.LatencyMS, 10),
+			res.ExitIP,
+			res.Country,
+			res.Error,
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
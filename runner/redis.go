@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/ratelimit"
+	"github.com/gosom/google-maps-scraper/redisqueue"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+const redisPrefix = "gmaps:"
+
+// SetupRedis connects to the redis configured via the command line and sets
+// the shared Deduper, ProxyCooldowns and RateLimiter of cfg. The places seen
+// by the Deduper expire after RedisDedupTTL.
+func SetupRedis(ctx context.Context, cfg *Config) error {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("cannot connect to redis: %w", err)
+	}
+
+	cfg.Deduper = deduper.NewRedisStore(client, redisPrefix+"dedup:places", cfg.RedisDedupTTL)
+	cfg.ProxyCooldowns = ratelimit.NewRedisCooldowns(client, redisPrefix+"proxy:")
+
+	if cfg.RateLimit > 0 {
+		cfg.RateLimiter, err = ratelimit.NewRedis(client, redisPrefix+"ratelimit:", cfg.RateLimit, time.Second)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewDeduper returns the shared deduper when one is configured
+// and a new in memory one otherwise.
+func (c *Config) NewDeduper() deduper.Deduper {
+	if c.Deduper != nil {
+		return c.Deduper
+	}
+
+	return deduper.New()
+}
+
+// NewRedisQueue connects to the redis of -queue, or to the one of the state
+// store with -queue state, and returns the provider of the jobs, it is shared
+// by the producer and the workers of the database mode
+func NewRedisQueue(ctx context.Context, cfg *Config) (*redisqueue.Provider, error) {
+	client, err := queueClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var provOpts []redisqueue.ProviderOption
+	if cfg.Deduper != nil {
+		provOpts = append(provOpts, redisqueue.WithDeduper(cfg.Deduper))
+	}
+
+	return redisqueue.NewProvider(client, redisPrefix+"queue:", provOpts...), nil
+}
+
+func queueClient(ctx context.Context, cfg *Config) (redis.UniversalClient, error) {
+	if cfg.Queue == StateStoreRef {
+		if err := SetupState(ctx, cfg); err != nil {
+			return nil, err
+		}
+
+		client, ok := state.RedisClient(cfg.State)
+		if !ok {
+			return nil, errors.New("the queue state requires a redis state store")
+		}
+
+		return client, nil
+	}
+
+	opts, err := redis.ParseURL(cfg.Queue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("cannot connect to the queue: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,184 @@
+package reportrunner
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/report"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+const usage = `usage: google-maps-scraper report compare [-out report.html] [-title title] [-min-rating-shift 0.2] OLD NEW
+       google-maps-scraper report diff [-out changes.ndjson] [-min-rating-shift 0.2] OLD NEW
+
+OLD and NEW are the CSV or JSON results of two runs over the same area.
+compare writes an HTML report of the market changes, diff writes the places
+added, removed and changed with their changed fields, as NDJSON or as CSV when
+-out ends in .csv.`
+
+var errUsage = errors.New(usage)
+
+type reportRunner struct {
+	args []string
+}
+
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeReport {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	return &reportRunner{args: cfg.ReportArgs}, nil
+}
+
+func (r *reportRunner) Run(context.Context) error {
+	if len(r.args) == 0 {
+		return errUsage
+	}
+
+	switch r.args[0] {
+	case "compare":
+		return compare(r.args[1:])
+	case "diff":
+		return diff(r.args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func (r *reportRunner) Close(context.Context) error {
+	return nil
+}
+
+func compare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+
+	out := fs.String("out", "report.html", "the file the HTML report is written to")
+	title := fs.String("title", "", "the title of the report")
+	minRatingShift := fs.Float64("min-rating-shift", 0.2, "the smallest rating change reported")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+
+	oldPath, newPath := fs.Arg(0), fs.Arg(1)
+
+	old, err := report.LoadSnapshot(oldPath)
+	if err != nil {
+		return fmt.Errorf("cannot load %s: %w", oldPath, err)
+	}
+
+	current, err := report.LoadSnapshot(newPath)
+	if err != nil {
+		return fmt.Errorf("cannot load %s: %w", newPath, err)
+	}
+
+	comparison := report.Compare(old, current, report.Options{MinRatingShift: *minRatingShift})
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	err = comparison.WriteHTML(f, report.HTMLParams{
+		Title:   *title,
+		OldName: filepath.Base(oldPath),
+		NewName: filepath.Base(newPath),
+	})
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d openings, %d closures, %d rating shifts, %d new categories: %s\n",
+		len(comparison.Openings), len(comparison.Closures), len(comparison.RatingShifts),
+		len(comparison.NewCategories), *out)
+
+	return nil
+}
+
+func diff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+
+	out := fs.String("out", "-", "the file the changes are written to, - for stdout")
+	minRatingShift := fs.Float64("min-rating-shift", 0.2, "the smallest rating change reported")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+
+	oldPath, newPath := fs.Arg(0), fs.Arg(1)
+
+	old, err := report.LoadSnapshot(oldPath)
+	if err != nil {
+		return fmt.Errorf("cannot load %s: %w", oldPath, err)
+	}
+
+	current, err := report.LoadSnapshot(newPath)
+	if err != nil {
+		return fmt.Errorf("cannot load %s: %w", newPath, err)
+	}
+
+	diffs := report.Diff(old, current, report.Options{MinRatingShift: *minRatingShift})
+
+	write := report.WriteDiffJSON
+	if strings.EqualFold(filepath.Ext(*out), ".csv") {
+		write = report.WriteDiffCSV
+	}
+
+	if *out == "-" {
+		err = write(os.Stdout, diffs)
+	} else {
+		err = writeFile(*out, func(w io.Writer) error {
+			return write(w, diffs)
+		})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, d := range diffs {
+		counts[d.Change]++
+	}
+
+	// the changes may be written to stdout
+	fmt.Fprintf(os.Stderr, "%d added, %d removed, %d changed places\n",
+		counts[report.ChangeAdded], counts[report.ChangeRemoved], counts[report.ChangeChanged])
+
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,108 @@
+package schedulerunner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/schedule"
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+// stopTimeout is how long a run has to stop after the interrupt of the
+// shutdown before it is killed
+const stopTimeout = 30 * time.Second
+
+type scheduleRunner struct {
+	cfg     *runner.Config
+	file    *schedule.File
+	command string
+}
+
+// New returns the runner of -schedule: every run of a job is a new process of
+// the scraper with the arguments of the job, so the runs do not share state
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModeSchedule {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	f, err := schedule.Load(cfg.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.WebhookSecret == "" {
+		f.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	}
+
+	command, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// the last runs are kept only in the store of -state-store
+	if cfg.StateStore != "" {
+		if err := runner.SetupState(context.Background(), cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return &scheduleRunner{cfg: cfg, file: f, command: command}, nil
+}
+
+func (r *scheduleRunner) Run(ctx context.Context) error {
+	slog.Info("schedule: started", "jobs", len(r.file.Jobs))
+
+	var opts []schedule.Option
+	if r.cfg.State != nil {
+		opts = append(opts, schedule.WithState(r.cfg.State))
+	}
+
+	err := schedule.New(r.file, r.exec, r.notify, opts...).Run(ctx)
+
+	slog.Info("schedule: stopped")
+
+	return err
+}
+
+func (r *scheduleRunner) Close(context.Context) error {
+	return runner.CloseState(r.cfg)
+}
+
+// exec runs the scraper with the arguments of the job, its logs are the logs
+// of the scheduler
+func (r *scheduleRunner) exec(ctx context.Context, job *schedule.Job, results string) error {
+	args := append(append([]string{}, job.Args...), "-results", results)
+
+	cmd := exec.CommandContext(ctx, r.command, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	// the run stops like on Ctrl+C, it saves its results and its checkpoint
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = stopTimeout
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("the scrape failed: %w", err)
+	}
+
+	return nil
+}
+
+// notify posts the run to the webhook of the job
+func (r *scheduleRunner) notify(ctx context.Context, job *schedule.Job, run *schedule.Run) {
+	if job.Webhook == "" {
+		return
+	}
+
+	w := webhook.NewWriter(job.Webhook, webhook.WithSecret(r.file.WebhookSecret))
+
+	if err := w.Post(ctx, run); err != nil {
+		slog.Error("schedule: cannot post the run to the webhook", "job", job.Name, "error", err)
+	}
+}
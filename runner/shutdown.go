@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// ErrShutdown is the cause of the runs stopped by a graceful shutdown, it is
+// a context.Canceled
+var ErrShutdown = fmt.Errorf("%w: shutdown", context.Canceled)
+
+type shutdownCtxKey struct{}
+
+// WithShutdown returns ctx with the shutdown of the apps started with it.
+// Once shutdown is called they stop taking new jobs, wait for the jobs in
+// flight to finish and stop, their writers flush the results. Canceling ctx
+// still stops them at once.
+func WithShutdown(ctx context.Context) (_ context.Context, shutdown func()) {
+	ch := make(chan struct{})
+
+	var once sync.Once
+
+	return context.WithValue(ctx, shutdownCtxKey{}, ch), func() {
+		once.Do(func() {
+			close(ch)
+		})
+	}
+}
+
+// shutdownFromContext returns the channel closed by the shutdown of ctx, nil
+// without WithShutdown
+func shutdownFromContext(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(shutdownCtxKey{}).(chan struct{})
+
+	return ch
+}
+
+var _ scrapemate.JobProvider = (*drainProvider)(nil)
+
+// drainProvider hands the jobs of next to the workers until it is drained,
+// then a drainJob to every worker instead. A worker takes its next job once
+// the previous one is done, the jobs in flight are done when all the workers
+// took their drainJob.
+type drainProvider struct {
+	next scrapemate.JobProvider
+	stop chan struct{}
+	once sync.Once
+	idle chan struct{}
+}
+
+func newDrainProvider(next scrapemate.JobProvider, workers int) *drainProvider {
+	return &drainProvider{
+		next: next,
+		stop: make(chan struct{}),
+		idle: make(chan struct{}, workers),
+	}
+}
+
+// Push pushes the job to the next provider, the jobs pushed by the jobs in
+// flight stay there
+func (d *drainProvider) Push(ctx context.Context, job scrapemate.IJob) error {
+	return d.next.Push(ctx, job)
+}
+
+//nolint:gocritic // scrapemate.JobProvider returns read only channels
+func (d *drainProvider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	in, errc := d.next.Jobs(ctx)
+	out := make(chan scrapemate.IJob)
+
+	go func() {
+		for {
+			var job scrapemate.IJob
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.stop:
+				d.park(ctx, out)
+
+				return
+			case job = <-in:
+			}
+
+			// the job taken is left in the next provider when the drain
+			// starts, it is not acknowledged
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.stop:
+				d.park(ctx, out)
+
+				return
+			case out <- job:
+			}
+		}
+	}()
+
+	return out, errc
+}
+
+// park hands the drainJob to the worker of out
+func (d *drainProvider) park(ctx context.Context, out chan<- scrapemate.IJob) {
+	select {
+	case <-ctx.Done():
+	case out <- &drainJob{Job: scrapemate.Job{ID: "shutdown", Method: "GET"}, idle: d.idle}:
+	}
+}
+
+// Drain stops handing the jobs and waits until the jobs in flight of the
+// workers are done or ctx is done
+func (d *drainProvider) Drain(ctx context.Context, workers int) {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+
+	for i := range workers {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.idle:
+			slog.Debug("worker stopped", "workers_left", workers-i-1)
+		}
+	}
+}
+
+// drainJob is the last job of a worker: it is not fetched, its processing
+// reports that the worker is idle
+type drainJob struct {
+	scrapemate.Job
+
+	idle chan<- struct{}
+}
+
+// SkipFetch skips the request of the job, see fetcher.SkippedJob
+func (j *drainJob) SkipFetch() error {
+	return ErrShutdown
+}
+
+func (j *drainJob) ProcessOnFetchError() bool {
+	return true
+}
+
+func (j *drainJob) DoCheckResponse(*scrapemate.Response) bool {
+	return true
+}
+
+func (j *drainJob) UseInResults() bool {
+	return false
+}
+
+func (j *drainJob) Process(context.Context, *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	select {
+	case j.idle <- struct{}{}:
+	default:
+	}
+
+	return nil, nil, nil
+}
@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// StateStoreRef is the value of -dedup-store and -queue using the store of
+// -state-store
+const StateStoreRef = "state"
+
+// dedupSet is the set of the state store of the places seen
+const dedupSet = "seen"
+
+// SetupState opens the store of cfg.StateStore, by default the files of the
+// flags, and sets cfg.State. It must run before SetupDedup.
+func SetupState(ctx context.Context, cfg *Config) error {
+	if cfg.State != nil {
+		return nil
+	}
+
+	store, err := state.Open(ctx, cfg.StateStore)
+	if err != nil {
+		return err
+	}
+
+	cfg.State = store
+
+	return nil
+}
+
+// CloseState closes the store opened by SetupState
+func CloseState(cfg *Config) error {
+	if cfg.State == nil {
+		return nil
+	}
+
+	return cfg.State.Close()
+}
+
+func isRedisURL(s string) bool {
+	return strings.HasPrefix(s, "redis://") || strings.HasPrefix(s, "rediss://")
+}
@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/taxonomy"
+)
+
+// SetupTaxonomy registers the after parse function of cfg.Middleware setting
+// the canonical categories of the places, with the built-in taxonomy extended
+// by cfg.CategoryMap. It must run after SetupFilters and SetupDedup, the places
+// dropped are not normalized, and before SetupProcessors so that the
+// processors see the canonical categories.
+func SetupTaxonomy(cfg *Config) error {
+	t := taxonomy.Default()
+
+	if cfg.CategoryMap != "" {
+		var err error
+
+		t, err = taxonomy.Load(cfg.CategoryMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Middleware == nil {
+		cfg.Middleware = gmaps.NewMiddleware()
+	}
+
+	cfg.Middleware.UseAfterParse(t.Normalize)
+
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,9 +14,11 @@ import (
 
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/mapping"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/tlmt"
 	"github.com/gosom/google-maps-scraper/web"
+	"github.com/gosom/google-maps-scraper/web/grpcapi"
 	"github.com/gosom/google-maps-scraper/web/sqlite"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
@@ -25,9 +27,11 @@ import (
 )
 
 type webrunner struct {
-	srv *web.Server
-	svc *web.Service
-	cfg *runner.Config
+	srv    *web.Server
+	grpc   *grpcapi.Server
+	svc    *web.Service
+	cfg    *runner.Config
+	schema *mapping.Schema
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -39,6 +43,25 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
+	runner.SetupFilters(cfg)
+
+	if err := runner.SetupTaxonomy(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupGeocoder(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := runner.SetupProcessors(cfg); err != nil {
+		return nil, err
+	}
+
+	// only the rate limiter is shared, every web job keeps its own dedup
+	if err := runner.SetupRedis(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
 	const dbfname = "jobs.db"
 
 	dbpath := filepath.Join(cfg.DataFolder, dbfname)
@@ -50,7 +73,10 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 
 	svc := web.NewService(repo, cfg.DataFolder)
 
-	srv, err := web.New(svc, cfg.Addr)
+	srv, err := web.New(svc, cfg.Addr, web.WithHealth(web.HealthConfig{
+		MaxPending:     cfg.ReadyMaxPending,
+		MaxWriterStall: cfg.ReadyMaxStall,
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +87,17 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		cfg: cfg,
 	}
 
+	if cfg.GRPCAddr != "" {
+		ans.grpc = grpcapi.New(svc, cfg.GRPCAddr)
+	}
+
+	if cfg.Mapping != "" {
+		ans.schema, err = mapping.Load(cfg.Mapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ans, nil
 }
 
@@ -75,11 +112,17 @@ func (w *webrunner) Run(ctx context.Context) error {
 		return w.srv.Start(ctx)
 	})
 
+	if w.grpc != nil {
+		egroup.Go(func() error {
+			return w.grpc.Start(ctx)
+		})
+	}
+
 	return egroup.Wait()
 }
 
 func (w *webrunner) Close(context.Context) error {
-	return nil
+	return runner.CloseProcessors(w.cfg)
 }
 
 func (w *webrunner) work(ctx context.Context) error {
@@ -91,6 +134,8 @@ func (w *webrunner) work(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
+			w.svc.Polled()
+
 			jobs, err := w.svc.SelectPending(ctx)
 			if err != nil {
 				return err
@@ -113,7 +158,7 @@ func (w *webrunner) work(ctx context.Context) error {
 
 						_ = runner.Telemetry().Send(ctx, evt)
 
-						log.Printf("error scraping job %s: %v", jobs[i].ID, err)
+						slog.Error("job failed", "job_id", jobs[i].ID, "error", err)
 					} else {
 						params := map[string]any{
 							"job_count": len(jobs[i].Data.Keywords),
@@ -122,7 +167,7 @@ func (w *webrunner) work(ctx context.Context) error {
 
 						_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("web_runner", params))
 
-						log.Printf("job %s scraped successfully", jobs[i].ID)
+						slog.Info("job scraped", "job_id", jobs[i].ID)
 					}
 				}
 			}
@@ -131,6 +176,9 @@ func (w *webrunner) work(ctx context.Context) error {
 }
 
 func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
+	// the writers of the job are done when it returns
+	defer w.svc.Finish(job.ID)
+
 	job.Status = web.StatusWorking
 
 	err := w.svc.Update(ctx, job)
@@ -161,7 +209,7 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 
 		err2 := w.svc.Update(ctx, job)
 		if err2 != nil {
-			log.Printf("failed to update job status: %v", err2)
+			slog.Error("failed to update job status", "job_id", job.ID, "error", err2)
 		}
 
 		return err
@@ -177,30 +225,48 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 	dedup := deduper.New()
 	exitMonitor := exiter.New()
 
-	seedJobs, err := runner.CreateSeedJobs(
-		job.Data.FastMode,
-		job.Data.Lang,
-		strings.NewReader(strings.Join(job.Data.Keywords, "\n")),
-		job.Data.Depth,
-		job.Data.Email,
-		coords,
-		job.Data.Zoom,
-		func() float64 {
-			if job.Data.Radius <= 0 {
-				return 10000 // 10 km
-			}
-
-			return float64(job.Data.Radius)
-		}(),
-		dedup,
-		exitMonitor,
-		w.cfg.ExtraReviews,
-		job.Data.ValidatePlaceIdUrl,
-	)
+	w.svc.Track(job.ID, exitMonitor)
+
+	keywords := strings.NewReader(strings.Join(job.Data.Keywords, "\n"))
+
+	seedJobs, err := runner.CreateSeedJobs(keywords, runner.SeedJobsOptions{
+		FastMode:           job.Data.FastMode,
+		LangCode:           job.Data.Lang,
+		MaxDepth:           job.Data.Depth,
+		Email:              job.Data.Email,
+		GeoCoordinates:     coords,
+		Zoom:               job.Data.Zoom,
+		Radius:             job.Data.SearchRadius(),
+		Dedup:              dedup,
+		ExitMonitor:        exitMonitor,
+		ExtraReviews:       w.cfg.ExtraReviews,
+		ValidatePlaceIdUrl: job.Data.ValidatePlaceIdUrl,
+		QuarantineDir:      w.cfg.QuarantineDir,
+		EmailPages:         w.cfg.EmailPages,
+		ExtraPosts:         w.cfg.ExtraPosts,
+		ExtraProducts:      w.cfg.ExtraProducts,
+		ExtraMenu:          w.cfg.ExtraMenu,
+		ExtraQuestions:     w.cfg.ExtraQuestions,
+		ExtraHotels:        w.cfg.Hotels,
+		Completeness:       w.cfg.Completeness,
+		Pages:              w.cfg.Pages,
+		EmailFetcher:       w.cfg.EmailFetcher,
+		BrowserFallback:    w.cfg.BrowserFallback,
+		Headers:            w.cfg.Headers,
+		ReviewLimits:       w.cfg.ReviewLimits,
+		Viewport:           w.cfg.Viewport,
+		Grid:               w.cfg.Grid,
+		Rings:              w.cfg.Rings,
+		Route:              w.cfg.Route,
+		Gl:                 w.cfg.Gl,
+		GoogleDomain:       w.cfg.GoogleDomain,
+		CategorySearch:     w.cfg.CategorySearch,
+		DeterministicIDs:   w.cfg.DeterministicIDs,
+	})
 	if err != nil {
 		err2 := w.svc.Update(ctx, job)
 		if err2 != nil {
-			log.Printf("failed to update job status: %v", err2)
+			slog.Error("failed to update job status", "job_id", job.ID, "error", err2)
 		}
 
 		return err
@@ -219,11 +285,16 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 			}
 		}
 
-		log.Printf("running job %s with %d seed jobs and %d allowed seconds", job.ID, len(seedJobs), allowedSeconds)
+		slog.Info("running job", "job_id", job.ID, "seed_jobs", len(seedJobs), "allowed_seconds", allowedSeconds)
+
+		deadline := time.Now().Add(time.Duration(allowedSeconds) * time.Second)
 
-		mateCtx, cancel := context.WithTimeout(ctx, time.Duration(allowedSeconds)*time.Second)
+		mateCtx, cancel := context.WithDeadline(ctx, deadline)
 		defer cancel()
 
+		w.svc.Running(job.ID, mate, deadline)
+		defer w.svc.Stopped(job.ID)
+
 		exitMonitor.SetCancelFunc(cancel)
 
 		go exitMonitor.Run(mateCtx)
@@ -234,7 +305,7 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 
 			err2 := w.svc.Update(ctx, job)
 			if err2 != nil {
-				log.Printf("failed to update job status: %v", err2)
+				slog.Error("failed to update job status", "job_id", job.ID, "error", err2)
 			}
 
 			return err
@@ -250,7 +321,7 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 	return w.svc.Update(ctx, job)
 }
 
-func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job) (*scrapemateapp.ScrapemateApp, error) {
+func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job) (*runner.App, error) {
 	opts := []func(*scrapemateapp.Config) error{
 		scrapemateapp.WithConcurrency(w.cfg.Concurrency),
 		scrapemateapp.WithExitOnInactivity(time.Minute * 3),
@@ -285,11 +356,15 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 		)
 	}
 
-	log.Printf("job %s has proxy: %v", job.ID, hasProxy)
+	slog.Info("job proxy", "job_id", job.ID, "proxy", hasProxy)
 
 	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(writer))
 
-	writers := []scrapemate.ResultWriter{csvWriter}
+	if w.schema != nil {
+		csvWriter = w.schema.Wrap(csvWriter)
+	}
+
+	writers := []scrapemate.ResultWriter{csvWriter, w.svc.ResultsWriter(job.ID)}
 
 	matecfg, err := scrapemateapp.NewConfig(
 		writers,
@@ -299,5 +374,5 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 		return nil, err
 	}
 
-	return scrapemateapp.NewScrapeMateApp(matecfg)
+	return runner.NewApp(matecfg, w.cfg.AppOptions()...)
 }
@@ -7,11 +7,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 type Uploader struct {
-	client *s3.Client
+	// uploader sends the large bodies as multipart uploads
+	uploader *manager.Uploader
 }
 
 func New(accessKey, secretKey, region string) *Uploader {
@@ -28,7 +30,7 @@ func New(accessKey, secretKey, region string) *Uploader {
 	client := s3.NewFromConfig(cfg)
 
 	return &Uploader{
-		client: client,
+		uploader: manager.NewUploader(client),
 	}
 }
 
@@ -39,7 +41,7 @@ func (u *Uploader) Upload(ctx context.Context, bucketName, key string, body io.R
 		Body:   body,
 	}
 
-	_, err := u.client.PutObject(ctx, input)
+	_, err := u.uploader.Upload(ctx, input)
 	if err != nil {
 		return err
 	}
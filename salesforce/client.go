@@ -0,0 +1,154 @@
+// Package salesforce upserts the entries as Salesforce records with the Bulk API 2.0.
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	apiVersion = "v60.0"
+	// pollInterval is how often the state of a job is checked
+	pollInterval = 5 * time.Second
+)
+
+// APIError is a response of the Salesforce API with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("salesforce: status %d: %s", e.StatusCode, e.Body)
+}
+
+type client struct {
+	instanceURL string
+	token       string
+	http        *http.Client
+}
+
+type job struct {
+	ID                     string `json:"id"`
+	State                  string `json:"state"`
+	ErrorMessage           string `json:"errorMessage"`
+	NumberRecordsProcessed int    `json:"numberRecordsProcessed"`
+	NumberRecordsFailed    int    `json:"numberRecordsFailed"`
+}
+
+func (c *client) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.instanceURL+"/services/data/"+apiVersion+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return data, nil
+}
+
+func (c *client) doJSON(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := c.do(ctx, method, path, "application/json", payload)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// upsert runs an upsert job of the CSV records and returns the finished job
+func (c *client) upsert(ctx context.Context, object, externalID string, records []byte) (job, error) {
+	var j job
+
+	err := c.doJSON(ctx, http.MethodPost, "/jobs/ingest", map[string]string{
+		"object":              object,
+		"externalIdFieldName": externalID,
+		"contentType":         "CSV",
+		"operation":           "upsert",
+		"lineEnding":          "LF",
+	}, &j)
+	if err != nil {
+		return j, fmt.Errorf("cannot create the job: %w", err)
+	}
+
+	if _, err := c.do(ctx, http.MethodPut, "/jobs/ingest/"+j.ID+"/batches", "text/csv", records); err != nil {
+		return j, fmt.Errorf("cannot upload the records of job %s: %w", j.ID, err)
+	}
+
+	if err := c.doJSON(ctx, http.MethodPatch, "/jobs/ingest/"+j.ID, map[string]string{"state": "UploadComplete"}, nil); err != nil {
+		return j, fmt.Errorf("cannot close job %s: %w", j.ID, err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.doJSON(ctx, http.MethodGet, "/jobs/ingest/"+j.ID, nil, &j); err != nil {
+			return j, fmt.Errorf("cannot get job %s: %w", j.ID, err)
+		}
+
+		switch j.State {
+		case "JobComplete":
+			return j, nil
+		case "Failed", "Aborted":
+			return j, fmt.Errorf("salesforce job %s %s: %s", j.ID, j.State, j.ErrorMessage)
+		}
+
+		select {
+		case <-ctx.Done():
+			return j, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// results returns the CSV of the successful or failed records of the job
+func (c *client) results(ctx context.Context, jobID, kind string) ([]byte, error) {
+	data, err := c.do(ctx, http.MethodGet, "/jobs/ingest/"+jobID+"/"+kind+"/", "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the %s of job %s: %w", kind, jobID, err)
+	}
+
+	return data, nil
+}
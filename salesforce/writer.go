@@ -0,0 +1,313 @@
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/mapping"
+)
+
+const (
+	// DefaultExternalID is the external id field the places are upserted by, it holds the cid
+	DefaultExternalID = "Google_Place_CID__c"
+	// maxJobRecords is the number of records of a job, far below the 150MB a job accepts
+	maxJobRecords = 10000
+)
+
+// defaultMappings map the entries to the standard fields of the objects
+var defaultMappings = map[string]string{
+	"Account": `
+columns:
+  - name: Name
+    field: title
+  - name: Phone
+    field: phone
+  - name: Website
+    field: web_site
+  - name: BillingStreet
+    field: complete_address.street
+  - name: BillingCity
+    field: complete_address.city
+  - name: BillingState
+    field: complete_address.state
+  - name: BillingPostalCode
+    field: complete_address.postal_code
+  - name: BillingCountry
+    field: complete_address.country
+  - name: Description
+    field: description
+`,
+	// the name of the person is not known, LastName is required
+	"Lead": `
+columns:
+  - name: Company
+    field: title
+  - name: LastName
+    field: title
+  - name: Email
+    field: emails.0
+  - name: Phone
+    field: phone
+  - name: Website
+    field: web_site
+  - name: Street
+    field: complete_address.street
+  - name: City
+    field: complete_address.city
+  - name: State
+    field: complete_address.state
+  - name: PostalCode
+    field: complete_address.postal_code
+  - name: Country
+    field: complete_address.country
+  - name: LeadSource
+    value: Google Maps
+`,
+}
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer upserts the places as records of an object, Account or Lead by default,
+// by an external id field holding their cid. The outcome of every record is
+// written as CSV to the results writer.
+type Writer struct {
+	client     client
+	object     string
+	externalID string
+	schema     *mapping.Schema
+	results    *csv.Writer
+}
+
+// WriterOption configures the writer
+type WriterOption func(*Writer)
+
+// WithObject sets the object the records are upserted to, Account by default
+func WithObject(name string) WriterOption {
+	return func(w *Writer) {
+		w.object = name
+	}
+}
+
+// WithExternalID sets the external id field, DefaultExternalID by default
+func WithExternalID(field string) WriterOption {
+	return func(w *Writer) {
+		w.externalID = field
+	}
+}
+
+// WithSchema maps the entries to the fields, the column names of the schema
+// are the API names of the fields
+func WithSchema(schema *mapping.Schema) WriterOption {
+	return func(w *Writer) {
+		w.schema = schema
+	}
+}
+
+// WithResults writes the cid, record id, created flag and error of every record to out
+func WithResults(out io.Writer) WriterOption {
+	return func(w *Writer) {
+		w.results = csv.NewWriter(out)
+	}
+}
+
+// WithHTTPClient replaces the HTTP client of the requests
+func WithHTTPClient(c *http.Client) WriterOption {
+	return func(w *Writer) {
+		w.client.http = c
+	}
+}
+
+// NewWriter returns a writer for the org at instanceURL, e.g. https://example.my.salesforce.com
+func NewWriter(instanceURL, token string, opts ...WriterOption) (*Writer, error) {
+	if instanceURL == "" || token == "" {
+		return nil, errors.New("salesforce: the instance url and the access token are required")
+	}
+
+	ans := Writer{
+		client:     client{instanceURL: strings.TrimSuffix(instanceURL, "/"), token: token, http: http.DefaultClient},
+		object:     "Account",
+		externalID: DefaultExternalID,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	if ans.schema == nil {
+		def, ok := defaultMappings[ans.object]
+		if !ok {
+			return nil, fmt.Errorf("salesforce: no default mapping for %s, use a mapping", ans.object)
+		}
+
+		schema, err := mapping.Parse([]byte(def))
+		if err != nil {
+			return nil, err
+		}
+
+		ans.schema = schema
+	}
+
+	return &ans, nil
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	if w.results != nil {
+		if err := w.results.Write([]string{"cid", "salesforce_id", "created", "error"}); err != nil {
+			return err
+		}
+	}
+
+	var (
+		buff    []*gmaps.Entry
+		skipped int
+	)
+
+	for result := range in {
+		var entries []*gmaps.Entry
+
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			entries = []*gmaps.Entry{data}
+		case []*gmaps.Entry:
+			entries = data
+		}
+
+		for _, e := range entries {
+			// without the cid the record cannot be matched by the next runs
+			if e.Cid == "" {
+				skipped++
+
+				continue
+			}
+
+			buff = append(buff, e)
+		}
+
+		if len(buff) >= maxJobRecords {
+			if err := w.flush(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+		}
+	}
+
+	if skipped > 0 {
+		slog.Warn("salesforce: places without cid skipped", "places", skipped)
+	}
+
+	return w.flush(ctx, buff)
+}
+
+func (w *Writer) flush(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+
+	cw := csv.NewWriter(&body)
+
+	// the same place found by several queries would fail the job
+	seen := make(map[string]bool, len(entries))
+
+	for i, e := range entries {
+		record := w.schema.Apply(e)
+
+		if i == 0 {
+			if err := cw.Write(append([]string{w.externalID}, record.CsvHeaders()...)); err != nil {
+				return err
+			}
+		}
+
+		if seen[e.Cid] {
+			continue
+		}
+
+		seen[e.Cid] = true
+
+		if err := cw.Write(append([]string{e.Cid}, record.CsvRow()...)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	j, err := w.client.upsert(ctx, w.object, w.externalID, body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	slog.Info("salesforce: records upserted", "bulk_job", j.ID, "object", w.object,
+		"upserted", j.NumberRecordsProcessed-j.NumberRecordsFailed, "failed", j.NumberRecordsFailed)
+
+	if w.results == nil {
+		return nil
+	}
+
+	for _, kind := range []string{"successfulResults", "failedResults"} {
+		data, err := w.client.results(ctx, j.ID, kind)
+		if err != nil {
+			return err
+		}
+
+		if err := w.writeResults(data); err != nil {
+			return err
+		}
+	}
+
+	w.results.Flush()
+
+	return w.results.Error()
+}
+
+// writeResults copies the outcome of the records of a results CSV, which has the
+// sf__Id, sf__Created or sf__Error columns followed by the uploaded ones
+func (w *Writer) writeResults(data []byte) error {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid salesforce results: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	cols := make(map[string]int, len(rows[0]))
+	for i, h := range rows[0] {
+		cols[h] = i
+	}
+
+	get := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return row[i]
+		}
+
+		return ""
+	}
+
+	for _, row := range rows[1:] {
+		err := w.results.Write([]string{
+			get(row, w.externalID), get(row, "sf__Id"), get(row, "sf__Created"), get(row, "sf__Error"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch is how far ahead Next looks for a matching minute, the expressions
+// like "0 0 30 2 *" never match
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Cron is a parsed cron expression
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are the * fields: when both days are restricted a day
+	// matching either of them matches
+	domAny, dowAny bool
+	// every is the interval of @every
+	every time.Duration
+}
+
+type field struct {
+	min, max int
+}
+
+var (
+	minuteField = field{0, 59}
+	hourField   = field{0, 23}
+	domField    = field{1, 31}
+	monthField  = field{1, 12}
+	// 7 is sunday too
+	dowField = field{0, 7}
+)
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression with five fields: minute, hour, day of
+// the month, month and day of the week (0 is sunday). A field is *, a value, a
+// range a-b or a list of them, followed by an optional step /n. The
+// shortcuts @hourly, @daily, @weekly, @monthly and @yearly and @every
+// <duration> are supported too.
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+
+	if d, ok := strings.CutPrefix(expr, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || every < time.Minute {
+			return nil, fmt.Errorf("invalid cron %q: @every needs a duration of at least 1m", expr)
+		}
+
+		return &Cron{every: every}, nil
+	}
+
+	if s, ok := shortcuts[expr]; ok {
+		expr = s
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid cron %q: expected 5 fields", expr)
+	}
+
+	var (
+		c   Cron
+		err error
+	)
+
+	fields := []struct {
+		bits *uint64
+		f    field
+	}{
+		{&c.minute, minuteField},
+		{&c.hour, hourField},
+		{&c.dom, domField},
+		{&c.month, monthField},
+		{&c.dow, dowField},
+	}
+
+	for i, p := range parts {
+		*fields[i].bits, err = parseField(p, fields[i].f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %w", expr, err)
+		}
+	}
+
+	if has(c.dow, 7) {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+
+	c.domAny = parts[2] == "*"
+	c.dowAny = parts[4] == "*"
+
+	return &c, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+
+			var err error
+
+			lo, err = strconv.Atoi(loStr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+
+			hi = lo
+
+			switch {
+			case isRange:
+				hi, err = strconv.Atoi(hiStr)
+				if err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			case hasStep:
+				// 5/15 is 5-max/15
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first time after t matching the expression, in the
+// location of t. It is the zero time when nothing matches.
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every).Truncate(time.Second)
+	}
+
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxSearch)
+
+	for next.Before(end) {
+		switch {
+		case !has(c.month, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !c.matchDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !has(c.hour, next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !has(c.minute, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) matchDay(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+
+	switch {
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
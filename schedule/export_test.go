@@ -0,0 +1,8 @@
+package schedule
+
+import "context"
+
+// RunJob runs the job once, like when its cron matches
+func (s *Scheduler) RunJob(ctx context.Context, job *Job) {
+	s.run(ctx, job)
+}
@@ -0,0 +1,326 @@
+// Package schedule runs the scrapes of a schedule file again and again, on
+// cron like expressions, so that the scraper can run as a daemon.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// defaultResults is the results file of the jobs without one
+const defaultResults = "{name}-{date}-{time}.csv"
+
+// File is the content of a schedule file
+type File struct {
+	// Webhook is posted the completion of the runs of the jobs without their
+	// own webhook. WebhookSecret signs the bodies.
+	Webhook       string `yaml:"webhook"`
+	WebhookSecret string `yaml:"webhook_secret"`
+	Jobs          []Job  `yaml:"jobs"`
+}
+
+// Job is a scrape run on a schedule
+type Job struct {
+	Name string `yaml:"name"`
+	// Cron is when the job runs, see ParseCron
+	Cron string `yaml:"cron"`
+	// Args are the command line arguments of the scrape, without -results
+	Args []string `yaml:"args"`
+	// Results is the results file of a run, {name}, {date} and {time} are
+	// replaced by the name of the job and the start of the run
+	Results string `yaml:"results"`
+	Webhook string `yaml:"webhook"`
+
+	cron *Cron
+}
+
+// Load reads and validates a schedule file
+func Load(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("invalid schedule %s: %w", path, err)
+	}
+
+	if len(f.Jobs) == 0 {
+		return nil, fmt.Errorf("invalid schedule %s: no jobs", path)
+	}
+
+	names := make(map[string]bool, len(f.Jobs))
+
+	for i := range f.Jobs {
+		job := &f.Jobs[i]
+
+		if job.Name == "" {
+			return nil, fmt.Errorf("invalid schedule %s: job %d has no name", path, i+1)
+		}
+
+		if names[job.Name] {
+			return nil, fmt.Errorf("invalid schedule %s: duplicate job %s", path, job.Name)
+		}
+
+		names[job.Name] = true
+
+		job.cron, err = ParseCron(job.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: job %s: %w", path, job.Name, err)
+		}
+
+		if job.Results == "" {
+			job.Results = defaultResults
+		}
+
+		if job.Webhook == "" {
+			job.Webhook = f.Webhook
+		}
+	}
+
+	return &f, nil
+}
+
+// ResultsPath returns the results file of the run of the job started at start
+func (j *Job) ResultsPath(start time.Time) string {
+	return strings.NewReplacer(
+		"{name}", j.Name,
+		"{date}", start.Format("2006-01-02"),
+		"{time}", start.Format("150405"),
+	).Replace(j.Results)
+}
+
+// Run is a run of a job
+type Run struct {
+	Job        string    `json:"job"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Results    string    `json:"results"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   float64   `json:"duration_seconds"`
+	NextRun    time.Time `json:"next_run"`
+}
+
+// The statuses of the runs
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// ExecFunc runs the scrape of a job, writing its results to results
+type ExecFunc func(ctx context.Context, job *Job, results string) error
+
+// NotifyFunc is called when a run is done
+type NotifyFunc func(ctx context.Context, job *Job, run *Run)
+
+// Scheduler runs the jobs of a schedule file when their cron matches. A job
+// is not started while its previous run is in progress, the jobs run at the
+// same time otherwise.
+type Scheduler struct {
+	file   *File
+	exec   ExecFunc
+	notify NotifyFunc
+	store  state.Store
+	now    func() time.Time
+}
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithState keeps the last run of every job in store, so that a restarted
+// scheduler reports the runs missed while it was stopped
+func WithState(store state.Store) Option {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+// New returns a scheduler running the jobs of f with exec. notify may be nil.
+func New(f *File, exec ExecFunc, notify NotifyFunc, opts ...Option) *Scheduler {
+	s := Scheduler{
+		file:   f,
+		exec:   exec,
+		notify: notify,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+// Run runs the jobs until ctx is done and waits for the runs in progress
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for i := range s.file.Jobs {
+		job := &s.file.Jobs[i]
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.loop(ctx, job)
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	var (
+		running sync.Mutex
+		wg      sync.WaitGroup
+	)
+
+	defer wg.Wait()
+
+	s.lastRun(ctx, job)
+
+	for {
+		next := job.cron.Next(s.now())
+		if next.IsZero() {
+			slog.Error("schedule: the cron of the job never matches", "job", job.Name, "cron", job.Cron)
+
+			return
+		}
+
+		slog.Info("schedule: next run", "job", job.Name, "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		if !running.TryLock() {
+			slog.Warn("schedule: the previous run is in progress, the run is skipped", "job", job.Name)
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer running.Unlock()
+
+			s.run(ctx, job)
+		}()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job *Job) {
+	start := s.now()
+
+	run := Run{
+		Job:       job.Name,
+		Results:   job.ResultsPath(start),
+		StartedAt: start.UTC(),
+	}
+
+	slog.Info("schedule: run started", "job", job.Name, "results", run.Results)
+
+	err := os.MkdirAll(filepath.Dir(run.Results), os.ModePerm)
+	if err == nil {
+		err = s.exec(ctx, job, run.Results)
+	}
+
+	finished := s.now()
+
+	run.FinishedAt = finished.UTC()
+	run.Duration = finished.Sub(start).Seconds()
+	run.NextRun = job.cron.Next(finished).UTC()
+	run.Status = StatusSucceeded
+
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+
+		slog.Error("schedule: run failed", "job", job.Name, "duration", finished.Sub(start), "error", err)
+	} else {
+		slog.Info("schedule: run done", "job", job.Name, "duration", finished.Sub(start), "results", run.Results)
+	}
+
+	// the runs interrupted by the shutdown are saved and notified too
+	s.saveRun(context.WithoutCancel(ctx), &run)
+
+	if s.notify != nil {
+		s.notify(context.WithoutCancel(ctx), job, &run)
+	}
+}
+
+// runKey is the key of the state of the last run of a job
+func runKey(job string) string {
+	return "schedule/" + job + ".json"
+}
+
+// lastRun logs the last run of the job saved in the state, and warns when its
+// next run was missed
+func (s *Scheduler) lastRun(ctx context.Context, job *Job) {
+	if s.store == nil {
+		return
+	}
+
+	b, err := s.store.Get(ctx, runKey(job.Name))
+	if errors.Is(err, state.ErrNotFound) {
+		return
+	}
+
+	var run Run
+
+	if err == nil {
+		err = json.Unmarshal(b, &run)
+	}
+
+	if err != nil {
+		slog.Warn("schedule: cannot read the last run", "job", job.Name, "error", err)
+
+		return
+	}
+
+	slog.Info("schedule: last run", "job", job.Name, "status", run.Status, "finished_at", run.FinishedAt)
+
+	if run.NextRun.Before(s.now()) {
+		slog.Warn("schedule: a run was missed while the scheduler was stopped", "job", job.Name, "at", run.NextRun)
+	}
+}
+
+func (s *Scheduler) saveRun(ctx context.Context, run *Run) {
+	if s.store == nil {
+		return
+	}
+
+	b, err := json.Marshal(run)
+	if err == nil {
+		err = s.store.Put(ctx, runKey(run.Job), b)
+	}
+
+	if err != nil {
+		slog.Error("schedule: cannot save the run", "job", run.Job, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package schedule_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/schedule"
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+func Test_SchedulerState(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  string
+		message string
+	}{
+		{
+			name:   "run succeeded",
+			status: schedule.StatusSucceeded,
+		},
+		{
+			name:    "run failed",
+			err:     errors.New("scrape failed"),
+			status:  schedule.StatusFailed,
+			message: "scrape failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			path := filepath.Join(dir, "schedule.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(`jobs:
+  - name: daily
+    cron: "@daily"
+    args: ["-input", "queries.txt"]
+    results: `+filepath.Join(dir, "results", "{name}.csv")+`
+`), 0o600))
+
+			f, err := schedule.Load(path)
+			require.NoError(t, err)
+
+			store, err := state.NewFS(filepath.Join(dir, "state"))
+			require.NoError(t, err)
+
+			exec := func(context.Context, *schedule.Job, string) error {
+				return tc.err
+			}
+
+			s := schedule.New(f, exec, nil, schedule.WithState(store))
+			s.RunJob(ctx, &f.Jobs[0])
+
+			b, err := store.Get(ctx, "schedule/daily.json")
+			require.NoError(t, err)
+
+			var run schedule.Run
+
+			require.NoError(t, json.Unmarshal(b, &run))
+			require.Equal(t, "daily", run.Job)
+			require.Equal(t, tc.status, run.Status)
+			require.Equal(t, tc.message, run.Error)
+			require.Equal(t, filepath.Join(dir, "results", "daily.csv"), run.Results)
+			require.True(t, run.NextRun.After(run.FinishedAt))
+		})
+	}
+}
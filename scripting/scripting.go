@@ -0,0 +1,365 @@
+// Package scripting runs user provided Lua scripts on every entry.
+//
+// The entry is the global table entry, its keys are the json names of the
+// fields. The script changes the fields of the table, derived fields are kept
+// in entry.metadata whose values are strings, and it drops the entry by
+// calling drop() or by returning false. The base, string, table and math
+// libraries are available, with the following functions:
+//
+//	drop()                              drops the entry
+//	match(pattern, s)                   reports whether s matches the Go regular expression
+//	regex_replace(pattern, repl, s)     replaces the matches of the Go regular expression
+//	trim(s), title(s)                   trims the spaces of s, capitalizes its words
+//
+// Example:
+//
+//	if entry.review_count < 10 then
+//	  return false
+//	end
+//
+//	entry.title = title(trim(entry.title):lower())
+//	entry.metadata.reviews_per_star = tostring(entry.review_count / 5)
+//
+// A Lua state runs the entries of one worker at a time, the globals set by the
+// script are kept from an entry to the next one of the same state.
+package scripting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gosom/scrapemate"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// metadataField is the field of the derived fields, its values are strings
+const metadataField = "metadata"
+
+// Script is a compiled script
+type Script struct {
+	name  string
+	proto *lua.FunctionProto
+	vms   sync.Pool
+}
+
+// vm is a Lua state running the script, dropped is set by drop()
+type vm struct {
+	L       *lua.LState
+	dropped bool
+}
+
+// Load reads and compiles the script at path
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	return New(filepath.Base(path), string(data))
+}
+
+// New compiles the script src
+func New(name, src string) (*Script, error) {
+	chunk, err := parse.Parse(strings.NewReader(src), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	proto, err := lua.Compile(chunk, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	s := Script{name: name, proto: proto}
+	s.vms.New = func() any { return newVM() }
+
+	return &s, nil
+}
+
+// Process runs the script for the entry.
+// It has the signature of gmaps.AfterParseFunc.
+func (s *Script) Process(ctx context.Context, _ scrapemate.IJob, entry *gmaps.Entry) error {
+	v := s.vms.Get().(*vm)
+	defer s.vms.Put(v)
+
+	fields, err := entryFields(entry)
+	if err != nil {
+		return err
+	}
+
+	// the derived fields are set without creating the table
+	if fields[metadataField] == nil {
+		fields[metadataField] = map[string]any{}
+	}
+
+	L := v.L
+	v.dropped = false
+
+	L.SetGlobal("entry", toLua(L, fields))
+	L.SetContext(ctx)
+
+	defer func() {
+		L.RemoveContext()
+		L.SetTop(0)
+	}()
+
+	L.Push(L.NewFunctionFromProto(s.proto))
+
+	err = L.PCall(0, 1, nil)
+
+	switch {
+	case v.dropped:
+		return gmaps.ErrSkipEntry
+	case err != nil:
+		return fmt.Errorf("script %s failed: %w", s.name, err)
+	}
+
+	if L.Get(-1) == lua.LFalse {
+		return gmaps.ErrSkipEntry
+	}
+
+	tbl, ok := L.GetGlobal("entry").(*lua.LTable)
+	if !ok {
+		return fmt.Errorf("script %s failed: entry is not a table", s.name)
+	}
+
+	if err := setEntry(L, entry, fields, tbl); err != nil {
+		return fmt.Errorf("script %s failed: %w", s.name, err)
+	}
+
+	return nil
+}
+
+func newVM() *vm {
+	v := vm{L: lua.NewState(lua.Options{SkipOpenLibs: true})}
+
+	for name, open := range map[string]lua.LGFunction{
+		lua.BaseLibName:   lua.OpenBase,
+		lua.StringLibName: lua.OpenString,
+		lua.TabLibName:    lua.OpenTable,
+		lua.MathLibName:   lua.OpenMath,
+	} {
+		v.L.Push(v.L.NewFunction(open))
+		v.L.Push(lua.LString(name))
+		v.L.Call(1, 0)
+	}
+
+	// the scripts do not load other files
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		v.L.SetGlobal(name, lua.LNil)
+	}
+
+	funcs := map[string]lua.LGFunction{
+		"drop": func(L *lua.LState) int {
+			v.dropped = true
+			L.RaiseError("entry dropped")
+
+			return 0
+		},
+		"match": func(L *lua.LState) int {
+			re, err := regexp.Compile(L.CheckString(1))
+			if err != nil {
+				L.RaiseError("%s", err)
+			}
+
+			L.Push(lua.LBool(re.MatchString(L.CheckString(2))))
+
+			return 1
+		},
+		"regex_replace": func(L *lua.LState) int {
+			re, err := regexp.Compile(L.CheckString(1))
+			if err != nil {
+				L.RaiseError("%s", err)
+			}
+
+			L.Push(lua.LString(re.ReplaceAllString(L.CheckString(3), L.CheckString(2))))
+
+			return 1
+		},
+		"trim": func(L *lua.LState) int {
+			L.Push(lua.LString(strings.TrimSpace(L.CheckString(1))))
+
+			return 1
+		},
+		"title": func(L *lua.LState) int {
+			L.Push(lua.LString(title(L.CheckString(1))))
+
+			return 1
+		},
+	}
+
+	for name, fn := range funcs {
+		v.L.SetGlobal(name, v.L.NewFunction(fn))
+	}
+
+	return &v
+}
+
+// entryFields returns the fields of the entry by json name
+func entryFields(entry *gmaps.Entry) (map[string]any, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// setEntry replaces the fields of the entry with the ones of tbl. The fields
+// the script did not change keep their values of fields, the tables lose the
+// nulls at the end of the arrays and the empty objects.
+func setEntry(L *lua.LState, entry *gmaps.Entry, fields map[string]any, tbl *lua.LTable) error {
+	updated, ok := fromLua(tbl).(map[string]any)
+	if !ok {
+		updated = map[string]any{}
+	}
+
+	if metadata, ok := tbl.RawGetString(metadataField).(*lua.LTable); ok {
+		updated[metadataField] = stringMap(metadata)
+	}
+
+	for k, v := range fields {
+		if reflect.DeepEqual(updated[k], fromLua(toLua(L, v))) {
+			updated[k] = v
+		}
+	}
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+
+	var e gmaps.Entry
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return fmt.Errorf("invalid entry: %w", err)
+	}
+
+	*entry = e
+
+	return nil
+}
+
+func toLua(L *lua.LState, v any) lua.LValue {
+	switch v := v.(type) {
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []any:
+		// the nulls keep the positions of the elements after them
+		tbl := L.CreateTable(len(v), 0)
+		for i, e := range v {
+			tbl.RawSetInt(i+1, toLua(L, e))
+		}
+
+		return tbl
+	case map[string]any:
+		tbl := L.CreateTable(0, len(v))
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			tbl.RawSetString(k, toLua(L, v[k]))
+		}
+
+		return tbl
+	default:
+		return lua.LNil
+	}
+}
+
+// fromLua returns the JSON value of v: the tables with a sequence are
+// arrays, the other ones objects and the empty ones null
+func fromLua(v lua.LValue) any {
+	switch v := v.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.MaxN(); n > 0 {
+			arr := make([]any, 0, n)
+			for i := 1; i <= n; i++ {
+				arr = append(arr, fromLua(v.RawGetInt(i)))
+			}
+
+			return arr
+		}
+
+		obj := make(map[string]any)
+
+		v.ForEach(func(k, e lua.LValue) {
+			if k.Type() == lua.LTString {
+				obj[k.String()] = fromLua(e)
+			}
+		})
+
+		if len(obj) == 0 {
+			return nil
+		}
+
+		return obj
+	default:
+		return nil
+	}
+}
+
+// stringMap returns the string and number values of tbl as strings
+func stringMap(tbl *lua.LTable) map[string]string {
+	ans := make(map[string]string)
+
+	tbl.ForEach(func(k, v lua.LValue) {
+		switch v.Type() { //nolint:exhaustive // the other values are dropped
+		case lua.LTString, lua.LTNumber, lua.LTBool:
+			ans[k.String()] = v.String()
+		}
+	})
+
+	if len(ans) == 0 {
+		return nil
+	}
+
+	return ans
+}
+
+func title(s string) string {
+	prev := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+
+		if unicode.IsSpace(prev) || prev == '-' {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
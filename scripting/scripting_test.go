@@ -0,0 +1,114 @@
+package scripting_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/scripting"
+)
+
+func Test_ScriptProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		entry    gmaps.Entry
+		expected gmaps.Entry
+		skip     bool
+		err      bool
+	}{
+		{
+			name:     "set fields",
+			src:      `entry.title = title(trim(entry.title):lower()); entry.category = entry.category or "unknown"`,
+			entry:    gmaps.Entry{Title: "  CAFE DEL MAR ", Category: "Cafe"},
+			expected: gmaps.Entry{Title: "Cafe Del Mar", Category: "Cafe"},
+		},
+		{
+			name:     "derived fields",
+			src:      `entry.metadata.reviews_per_star = entry.review_count / entry.review_rating`,
+			entry:    gmaps.Entry{ReviewCount: 90, ReviewRating: 4.5, Metadata: map[string]string{"row": "1"}},
+			expected: gmaps.Entry{ReviewCount: 90, ReviewRating: 4.5, Metadata: map[string]string{"row": "1", "reviews_per_star": "20"}},
+		},
+		{
+			name:     "lists",
+			src:      `table.insert(entry.categories, "Bar"); entry.phone = regex_replace("[^0-9+]", "", entry.phone)`,
+			entry:    gmaps.Entry{Categories: []string{"Cafe"}, Phone: "+30 210-123"},
+			expected: gmaps.Entry{Categories: []string{"Cafe", "Bar"}, Phone: "+30210123"},
+		},
+		{
+			name:     "nulls in arrays",
+			src:      `entry.title = entry.raw[2] .. entry.raw[4]`,
+			entry:    gmaps.Entry{Raw: []any{nil, "b", nil, float64(3), nil}},
+			expected: gmaps.Entry{Title: "b3", Raw: []any{nil, "b", nil, float64(3), nil}},
+		},
+		{
+			name:  "drop",
+			src:   `if match("(?i)closed", entry.status) then drop() end`,
+			entry: gmaps.Entry{Status: "Permanently closed"},
+			skip:  true,
+		},
+		{
+			name:  "return false",
+			src:   `return entry.review_count >= 10`,
+			entry: gmaps.Entry{ReviewCount: 3},
+			skip:  true,
+		},
+		{
+			name:     "return true",
+			src:      `return entry.review_count >= 10`,
+			entry:    gmaps.Entry{ReviewCount: 30},
+			expected: gmaps.Entry{ReviewCount: 30},
+		},
+		{
+			name:  "invalid field type",
+			src:   `entry.review_count = "many"`,
+			entry: gmaps.Entry{ReviewCount: 3},
+			err:   true,
+		},
+		{
+			name:  "runtime error",
+			src:   `entry.title = entry.missing.name`,
+			entry: gmaps.Entry{Title: "x"},
+			err:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := scripting.New("test.lua", tc.src)
+			require.NoError(t, err)
+
+			entry := tc.entry
+
+			err = s.Process(context.Background(), nil, &entry)
+
+			switch {
+			case tc.skip:
+				require.ErrorIs(t, err, gmaps.ErrSkipEntry)
+			case tc.err:
+				require.Error(t, err)
+				require.NotErrorIs(t, err, gmaps.ErrSkipEntry)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, entry)
+			}
+		})
+	}
+}
+
+func Test_ScriptSyntaxError(t *testing.T) {
+	_, err := scripting.New("test.lua", `if entry.title then`)
+	require.Error(t, err)
+}
+
+func Test_ScriptCanceled(t *testing.T) {
+	s, err := scripting.New("test.lua", `while true do end`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Error(t, s.Process(ctx, nil, &gmaps.Entry{}))
+}
@@ -0,0 +1,10 @@
+package sheets
+
+import "time"
+
+// SetIntervals sets the time between two appends and the wait before the
+// first retry, far below the quotas of the API in the tests
+func (w *Writer) SetIntervals(interval, backoff time.Duration) {
+	w.interval = interval
+	w.backoff = backoff
+}
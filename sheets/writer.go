@@ -0,0 +1,250 @@
+// Package sheets appends the entries to a Google Sheet.
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	sheetsapi "google.golang.org/api/sheets/v4"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	// DefaultTab is the tab the rows are appended to
+	DefaultTab = "Places"
+	// maxBatchRows is the number of rows of an append request
+	maxBatchRows = 500
+	// minInterval is the time between two appends: the API accepts 60 write
+	// requests per minute and per user, the appends stay far below it
+	minInterval = 5 * time.Second
+	// minBackoff is the wait before the first retry of an append
+	minBackoff = time.Second
+	// maxBatchAge is how long the rows wait for a full batch
+	maxBatchAge = 30 * time.Second
+	maxRetries  = 5
+)
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer appends the entries to a tab of a spreadsheet, as the columns of the
+// CSV output or of the custom output schema. The header row is written when
+// the tab is empty and the tab is created when it is missing. The rows are
+// appended in batches to respect the quotas of the API.
+type Writer struct {
+	service       *sheetsapi.Service
+	spreadsheetID string
+	tab           string
+
+	clientOpts []option.ClientOption
+	interval   time.Duration
+	backoff    time.Duration
+
+	header   bool
+	lastSave time.Time
+}
+
+// WriterOption configures the writer
+type WriterOption func(*Writer)
+
+// WithTab sets the tab the rows are appended to, DefaultTab by default
+func WithTab(name string) WriterOption {
+	return func(w *Writer) {
+		w.tab = name
+	}
+}
+
+// WithClientOptions adds options to the client of the API, e.g. its endpoint
+func WithClientOptions(opts ...option.ClientOption) WriterOption {
+	return func(w *Writer) {
+		w.clientOpts = append(w.clientOpts, opts...)
+	}
+}
+
+// NewWriter returns the writer of the spreadsheet. credentials is the JSON key
+// file of a service account the spreadsheet is shared with, the application
+// default credentials are used when it is empty.
+func NewWriter(ctx context.Context, spreadsheetID, credentials string, opts ...WriterOption) (*Writer, error) {
+	ans := Writer{
+		spreadsheetID: spreadsheetID,
+		tab:           DefaultTab,
+		clientOpts:    []option.ClientOption{option.WithScopes(sheetsapi.SpreadsheetsScope)},
+		interval:      minInterval,
+		backoff:       minBackoff,
+	}
+
+	if credentials != "" {
+		ans.clientOpts = append(ans.clientOpts, option.WithCredentialsFile(credentials))
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	service, err := sheetsapi.NewService(ctx, ans.clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("sheets: cannot create the client: %w", err)
+	}
+
+	ans.service = service
+
+	if err := ans.ensureTab(ctx); err != nil {
+		return nil, err
+	}
+
+	return &ans, nil
+}
+
+// ensureTab creates the tab when the spreadsheet does not have it and checks
+// whether it already has the header row
+func (w *Writer) ensureTab(ctx context.Context) error {
+	spreadsheet, err := w.service.Spreadsheets.Get(w.spreadsheetID).Fields("sheets.properties.title").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("sheets: cannot read the spreadsheet: %w", err)
+	}
+
+	for _, s := range spreadsheet.Sheets {
+		if s.Properties != nil && s.Properties.Title == w.tab {
+			values, err := w.service.Spreadsheets.Values.Get(w.spreadsheetID, w.rangeOf("1:1")).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("sheets: cannot read the tab %s: %w", w.tab, err)
+			}
+
+			w.header = len(values.Values) > 0
+
+			return nil
+		}
+	}
+
+	req := sheetsapi.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsapi.Request{
+			{AddSheet: &sheetsapi.AddSheetRequest{Properties: &sheetsapi.SheetProperties{Title: w.tab}}},
+		},
+	}
+
+	if _, err := w.service.Spreadsheets.BatchUpdate(w.spreadsheetID, &req).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("sheets: cannot create the tab %s: %w", w.tab, err)
+	}
+
+	return nil
+}
+
+// rangeOf returns the A1 notation of cells in the tab, the name is quoted
+// since it may have spaces
+func (w *Writer) rangeOf(cells string) string {
+	return "'" + w.tab + "'!" + cells
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	buff := make([][]any, 0, maxBatchRows)
+	lastRow := time.Now().UTC()
+
+	for result := range in {
+		var items []scrapemate.CsvCapable
+
+		switch data := result.Data.(type) {
+		case []*gmaps.Entry:
+			for _, e := range data {
+				items = append(items, e)
+			}
+		case scrapemate.CsvCapable:
+			items = append(items, data)
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+
+		for _, item := range items {
+			if !w.header {
+				buff = append(buff, row(item.CsvHeaders()))
+				w.header = true
+			}
+
+			buff = append(buff, row(item.CsvRow()))
+		}
+
+		if len(buff) >= maxBatchRows || time.Since(lastRow) >= maxBatchAge {
+			if err := w.append(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastRow = time.Now().UTC()
+		}
+	}
+
+	// the last rows are appended even when the context is canceled
+	return w.append(context.WithoutCancel(ctx), buff)
+}
+
+func row(values []string) []any {
+	ans := make([]any, len(values))
+	for i := range values {
+		ans[i] = values[i]
+	}
+
+	return ans
+}
+
+// append appends the rows below the last row of the tab. The values are
+// written as they are, a phone like +30 21 is not read as a formula. The rate
+// limited and the server error responses are retried, after one second and
+// then twice longer every time.
+func (w *Writer) append(ctx context.Context, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if wait := w.interval - time.Since(w.lastSave); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	defer func() {
+		w.lastSave = time.Now()
+	}()
+
+	values := sheetsapi.ValueRange{Values: rows}
+	backoff := w.backoff
+
+	for attempt := 0; ; attempt++ {
+		_, err := w.service.Spreadsheets.Values.Append(w.spreadsheetID, w.rangeOf("A1"), &values).
+			ValueInputOption("RAW").
+			InsertDataOption("INSERT_ROWS").
+			Context(ctx).
+			Do()
+		if err == nil {
+			return nil
+		}
+
+		var apiErr *googleapi.Error
+
+		retry := errors.As(err, &apiErr) &&
+			(apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError)
+
+		if !retry || attempt >= maxRetries {
+			return fmt.Errorf("sheets: cannot append %d rows: %w", len(rows), err)
+		}
+
+		slog.Warn("sheets: append failed, retrying", "backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
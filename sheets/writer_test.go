@@ -0,0 +1,312 @@
+package sheets_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/sheets"
+)
+
+// spreadsheet is a fake of the Sheets API keeping the rows of the tabs
+type spreadsheet struct {
+	mu   sync.Mutex
+	tabs map[string][][]any
+	// appends are the number of rows of every append request
+	appends []int
+	// statuses are the responses of the next append requests
+	statuses []int
+	// missing is set when the spreadsheet does not exist
+	missing bool
+}
+
+func (s *spreadsheet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/sheet-1")
+
+	if s.missing {
+		http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
+
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		var resp struct {
+			Sheets []map[string]map[string]string `json:"sheets"`
+		}
+
+		for title := range s.tabs {
+			resp.Sheets = append(resp.Sheets, map[string]map[string]string{"properties": {"title": title}})
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/values/"):
+		rows := s.tabs[tabOf(path)]
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"values": rows[:min(len(rows), 1)]})
+	case r.Method == http.MethodPost && path == ":batchUpdate":
+		var req struct {
+			Requests []struct {
+				AddSheet struct {
+					Properties struct {
+						Title string `json:"title"`
+					} `json:"properties"`
+				} `json:"addSheet"`
+			} `json:"requests"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.tabs[req.Requests[0].AddSheet.Properties.Title] = nil
+
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodPost && strings.HasSuffix(path, ":append"):
+		if len(s.statuses) > 0 {
+			code := s.statuses[0]
+			s.statuses = s.statuses[1:]
+
+			http.Error(w, `{"error":{"code":`+strconv.Itoa(code)+`,"message":"`+http.StatusText(code)+`"}}`, code)
+
+			return
+		}
+
+		if r.URL.Query().Get("valueInputOption") != "RAW" {
+			http.Error(w, "the values must be raw", http.StatusBadRequest)
+
+			return
+		}
+
+		var req struct {
+			Values [][]any `json:"values"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		tab := tabOf(strings.TrimSuffix(path, ":append"))
+		s.tabs[tab] = append(s.tabs[tab], req.Values...)
+		s.appends = append(s.appends, len(req.Values))
+
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// tabOf returns the tab of the range of a values path, e.g. /values/'Places'!A1
+func tabOf(path string) string {
+	cells := strings.TrimPrefix(path, "/values/")
+
+	return strings.Trim(cells[:strings.LastIndex(cells, "!")], "'")
+}
+
+func newWriter(t *testing.T, s *spreadsheet, opts ...sheets.WriterOption) (*sheets.Writer, error) {
+	t.Helper()
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	opts = append(opts, sheets.WithClientOptions(option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication()))
+
+	w, err := sheets.NewWriter(context.Background(), "sheet-1", "", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w.SetIntervals(0, 0)
+
+	return w, nil
+}
+
+func run(w *sheets.Writer, results []scrapemate.Result) error {
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	return w.Run(context.Background(), in)
+}
+
+// places returns n results of one entry each
+func places(n int) []scrapemate.Result {
+	ans := make([]scrapemate.Result, 0, n)
+	for range n {
+		ans = append(ans, scrapemate.Result{Data: &gmaps.Entry{Title: "a"}})
+	}
+
+	return ans
+}
+
+func Test_Writer(t *testing.T) {
+	header := (&gmaps.Entry{}).CsvHeaders()[0]
+
+	tests := []struct {
+		name    string
+		tabs    map[string][][]any
+		opts    []sheets.WriterOption
+		results []scrapemate.Result
+		tab     string
+		// titles are the first cells of the rows of the tab
+		titles  []string
+		appends []int
+	}{
+		{
+			name:    "new tab",
+			tabs:    map[string][][]any{"Sheet1": nil},
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Title: "a"}}, {Data: []*gmaps.Entry{{Title: "b"}}}},
+			tab:     sheets.DefaultTab,
+			titles:  []string{header, "", ""},
+			appends: []int{3},
+		},
+		{
+			name:    "tab with the header",
+			tabs:    map[string][][]any{"Leads": {{header}}},
+			opts:    []sheets.WriterOption{sheets.WithTab("Leads")},
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Title: "a"}}},
+			tab:     "Leads",
+			titles:  []string{header, ""},
+			appends: []int{1},
+		},
+		{
+			name:    "empty tab",
+			tabs:    map[string][][]any{"Leads": nil},
+			opts:    []sheets.WriterOption{sheets.WithTab("Leads")},
+			results: []scrapemate.Result{{Data: &gmaps.Entry{Title: "a"}}, {Data: nil}},
+			tab:     "Leads",
+			titles:  []string{header, ""},
+			appends: []int{2},
+		},
+		{
+			name:    "batches",
+			tabs:    map[string][][]any{sheets.DefaultTab: {{header}}},
+			results: places(501),
+			tab:     sheets.DefaultTab,
+			appends: []int{500, 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := spreadsheet{tabs: tc.tabs}
+
+			w, err := newWriter(t, &s, tc.opts...)
+			require.NoError(t, err)
+			require.NoError(t, run(w, tc.results))
+
+			require.Equal(t, tc.appends, s.appends)
+
+			if tc.titles != nil {
+				var titles []string
+				for _, r := range s.tabs[tc.tab] {
+					titles = append(titles, firstCell(r))
+				}
+
+				require.Equal(t, tc.titles, titles)
+			}
+		})
+	}
+}
+
+func Test_WriterRow(t *testing.T) {
+	s := spreadsheet{tabs: map[string][][]any{sheets.DefaultTab: nil}}
+
+	w, err := newWriter(t, &s)
+	require.NoError(t, err)
+
+	entry := gmaps.Entry{Title: "Cafe", Phone: "+30 21 0000"}
+	require.NoError(t, run(w, []scrapemate.Result{{Data: &entry}}))
+
+	rows := s.tabs[sheets.DefaultTab]
+	require.Len(t, rows, 2)
+
+	want := make([]any, 0, len(entry.CsvRow()))
+	for _, v := range entry.CsvRow() {
+		want = append(want, v)
+	}
+
+	require.Equal(t, want, rows[1])
+}
+
+func Test_WriterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		results  []scrapemate.Result
+		err      bool
+		appends  []int
+	}{
+		{
+			name:     "rate limited",
+			statuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			results:  places(1),
+			appends:  []int{1},
+		},
+		{
+			name:     "server errors",
+			statuses: []int{500, 502, 503, 500, 502, 503},
+			results:  places(1),
+			err:      true,
+		},
+		{
+			name:     "bad request",
+			statuses: []int{http.StatusBadRequest},
+			results:  places(1),
+			err:      true,
+		},
+		{
+			name:    "invalid data",
+			results: []scrapemate.Result{{Data: 1}},
+			err:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := spreadsheet{
+				tabs:     map[string][][]any{sheets.DefaultTab: {{"title"}}},
+				statuses: tc.statuses,
+			}
+
+			w, err := newWriter(t, &s)
+			require.NoError(t, err)
+
+			err = run(w, tc.results)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.appends, s.appends)
+			require.Empty(t, s.statuses)
+		})
+	}
+}
+
+func Test_NewWriterMissingSpreadsheet(t *testing.T) {
+	_, err := newWriter(t, &spreadsheet{missing: true})
+	require.Error(t, err)
+}
+
+func firstCell(row []any) string {
+	if len(row) == 0 {
+		return ""
+	}
+
+	s, _ := row[0].(string)
+
+	return s
+}
@@ -0,0 +1,172 @@
+// Package social classifies the links to the social media profiles of a business.
+package social
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Network is a social network
+type Network string
+
+const (
+	Facebook  Network = "facebook"
+	Instagram Network = "instagram"
+	LinkedIn  Network = "linkedin"
+	Twitter   Network = "twitter"
+	TikTok    Network = "tiktok"
+	YouTube   Network = "youtube"
+)
+
+// the first path segments that are not profiles, e.g. the share buttons
+var notProfiles = map[Network]map[string]bool{
+	Facebook: set("sharer", "sharer.php", "share.php", "share", "dialog", "plugins", "tr", "events",
+		"groups", "watch", "photo.php", "photo", "story.php", "permalink.php", "login", "login.php",
+		"hashtag", "policies", "privacy", "help", "l.php", "home.php", "pages", "people", "business",
+		"marketplace", "gaming", "legal"),
+	Instagram: set("p", "reel", "reels", "tv", "explore", "accounts", "stories", "about", "developer", "legal"),
+	Twitter: set("intent", "share", "home", "hashtag", "search", "i", "explore", "login", "signup",
+		"settings", "privacy", "tos", "messages", "notifications"),
+	YouTube: set("watch", "embed", "results", "playlist", "shorts", "feed", "redirect", "live", "t",
+		"howyoutubeworks", "about", "premium", "kids"),
+}
+
+var handleRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// Classify returns the network of the link and the profile url normalized as
+// https://<network host>/<profile>, without the query, the fragment and the
+// trailing slash. Links to posts, videos, share buttons or to the home page of
+// the network are not profiles.
+func Classify(link string) (Network, string, bool) {
+	link = strings.TrimSpace(link)
+	if strings.HasPrefix(link, "//") {
+		link = "https:" + link
+	} else if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	switch {
+	case isHost(host, "facebook.com", "fb.com"):
+		return facebook(u, segments)
+	case isHost(host, "instagram.com"):
+		return profile(Instagram, "https://www.instagram.com/", segments)
+	case isHost(host, "linkedin.com"):
+		return linkedIn(segments)
+	case isHost(host, "twitter.com", "x.com"):
+		return profile(Twitter, "https://x.com/", segments)
+	case isHost(host, "tiktok.com"):
+		return tikTok(segments)
+	case isHost(host, "youtube.com"):
+		return youTube(segments)
+	default:
+		return "", "", false
+	}
+}
+
+// isHost reports whether host is one of the domains or one of their subdomains,
+// e.g. m.facebook.com or de-de.facebook.com
+func isHost(host string, domains ...string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// profile is a network whose profiles are the first segment of the path
+func profile(n Network, prefix string, segments []string) (Network, string, bool) {
+	if len(segments) == 0 || notProfiles[n][strings.ToLower(segments[0])] || !handleRegex.MatchString(segments[0]) {
+		return "", "", false
+	}
+
+	return n, prefix + segments[0], true
+}
+
+// facebook has the pages at the first segment, the profiles without a name at
+// profile.php?id= and the pages of some businesses at /pages/<name>/<id>
+func facebook(u *url.URL, segments []string) (Network, string, bool) {
+	if len(segments) == 0 {
+		return "", "", false
+	}
+
+	if strings.EqualFold(segments[0], "profile.php") {
+		id := u.Query().Get("id")
+		if id == "" {
+			return "", "", false
+		}
+
+		return Facebook, "https://www.facebook.com/profile.php?id=" + url.QueryEscape(id), true
+	}
+
+	if strings.EqualFold(segments[0], "pages") && len(segments) >= 3 {
+		return Facebook, "https://www.facebook.com/pages/" + segments[1] + "/" + segments[2], true
+	}
+
+	return profile(Facebook, "https://www.facebook.com/", segments)
+}
+
+// linkedIn has the companies, schools and people at /company/, /school/ and /in/
+func linkedIn(segments []string) (Network, string, bool) {
+	if len(segments) < 2 {
+		return "", "", false
+	}
+
+	kind := strings.ToLower(segments[0])
+	if kind != "company" && kind != "school" && kind != "in" && kind != "showcase" {
+		return "", "", false
+	}
+
+	return LinkedIn, "https://www.linkedin.com/" + kind + "/" + segments[1], true
+}
+
+// tikTok has the profiles at /@<name>
+func tikTok(segments []string) (Network, string, bool) {
+	if len(segments) == 0 || !strings.HasPrefix(segments[0], "@") || len(segments[0]) == 1 {
+		return "", "", false
+	}
+
+	return TikTok, "https://www.tiktok.com/" + segments[0], true
+}
+
+// youTube has the channels at /@<handle>, /channel/<id>, /c/<name> and /user/<name>,
+// and at /<name> for the older custom urls
+func youTube(segments []string) (Network, string, bool) {
+	if len(segments) == 0 {
+		return "", "", false
+	}
+
+	const prefix = "https://www.youtube.com/"
+
+	switch kind := strings.ToLower(segments[0]); {
+	case strings.HasPrefix(segments[0], "@") && len(segments[0]) > 1:
+		return YouTube, prefix + segments[0], true
+	case kind == "channel" || kind == "c" || kind == "user":
+		if len(segments) < 2 {
+			return "", "", false
+		}
+
+		return YouTube, prefix + kind + "/" + segments[1], true
+	default:
+		return profile(YouTube, prefix, segments)
+	}
+}
+
+func set(values ...string) map[string]bool {
+	ans := make(map[string]bool, len(values))
+	for _, v := range values {
+		ans[v] = true
+	}
+
+	return ans
+}
@@ -0,0 +1,62 @@
+package social_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/social"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		link    string
+		network social.Network
+		profile string
+	}{
+		{"https://www.facebook.com/CafeAthens/", social.Facebook, "https://www.facebook.com/CafeAthens"},
+		{"http://m.facebook.com/CafeAthens?ref=page_internal", social.Facebook, "https://www.facebook.com/CafeAthens"},
+		{"https://el-gr.facebook.com/CafeAthens#about", social.Facebook, "https://www.facebook.com/CafeAthens"},
+		{"facebook.com/CafeAthens", social.Facebook, "https://www.facebook.com/CafeAthens"},
+		{"https://www.facebook.com/profile.php?id=100063745&sk=about", social.Facebook, "https://www.facebook.com/profile.php?id=100063745"},
+		{"https://www.facebook.com/pages/Cafe-Athens/123456789", social.Facebook, "https://www.facebook.com/pages/Cafe-Athens/123456789"},
+		{"https://www.facebook.com/sharer/sharer.php?u=https://example.com", "", ""},
+		{"https://www.facebook.com/tr?id=123&ev=PageView", "", ""},
+		{"https://www.facebook.com/", "", ""},
+		{"https://www.instagram.com/cafe.athens/", social.Instagram, "https://www.instagram.com/cafe.athens"},
+		{"https://instagram.com/cafe_athens?igshid=abc", social.Instagram, "https://www.instagram.com/cafe_athens"},
+		{"https://www.instagram.com/p/CxYz123/", "", ""},
+		{"https://www.linkedin.com/company/cafe-athens/about/", social.LinkedIn, "https://www.linkedin.com/company/cafe-athens"},
+		{"https://gr.linkedin.com/in/jane-doe-123", social.LinkedIn, "https://www.linkedin.com/in/jane-doe-123"},
+		{"https://www.linkedin.com/shareArticle?url=x", "", ""},
+		{"https://twitter.com/CafeAthens", social.Twitter, "https://x.com/CafeAthens"},
+		{"https://x.com/CafeAthens/status/1234", social.Twitter, "https://x.com/CafeAthens"},
+		{"https://mobile.twitter.com/CafeAthens?lang=en", social.Twitter, "https://x.com/CafeAthens"},
+		{"https://twitter.com/intent/tweet?text=hello", "", ""},
+		{"https://twitter.com/share", "", ""},
+		{"https://www.tiktok.com/@cafeathens?lang=en", social.TikTok, "https://www.tiktok.com/@cafeathens"},
+		{"https://www.tiktok.com/@cafeathens/video/7131", social.TikTok, "https://www.tiktok.com/@cafeathens"},
+		{"https://www.tiktok.com/tag/food", "", ""},
+		{"https://www.youtube.com/@CafeAthens", social.YouTube, "https://www.youtube.com/@CafeAthens"},
+		{"https://www.youtube.com/channel/UC1234abcd/videos", social.YouTube, "https://www.youtube.com/channel/UC1234abcd"},
+		{"https://youtube.com/c/CafeAthens", social.YouTube, "https://www.youtube.com/c/CafeAthens"},
+		{"https://www.youtube.com/user/cafeathens", social.YouTube, "https://www.youtube.com/user/cafeathens"},
+		{"https://m.youtube.com/CafeAthens", social.YouTube, "https://www.youtube.com/CafeAthens"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "", ""},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ", "", ""},
+		{"https://youtu.be/dQw4w9WgXcQ", "", ""},
+		{"https://notfacebook.com/CafeAthens", "", ""},
+		{"mailto:info@example.com", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			network, profile, ok := social.Classify(tt.link)
+
+			require.Equal(t, tt.network != "", ok)
+			require.Equal(t, tt.network, network)
+			require.Equal(t, tt.profile, profile)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+// Package spillqueue keeps a bounded number of the pending jobs, and of the
+// results waiting for the writers, in memory. The jobs and the results over
+// the bound are encoded to the spill files of a directory and read back once
+// the ones in memory are taken, so that the memory of a run stays flat however
+// many jobs and places it finds.
+package spillqueue
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// priorities are the priorities of the jobs, from scrapemate.PriorityHigh to
+// scrapemate.PriorityLow
+const priorities = 3
+
+// maxRecord is the size of the largest encoded job read back from a spill
+// file, larger records are a corrupted file
+const maxRecord = 64 << 20
+
+var _ scrapemate.JobProvider = (*Provider)(nil)
+
+// Provider is a job provider keeping at most maxJobs pending jobs in memory.
+// Push never blocks: the jobs that do not fit are appended to the spill file
+// of their priority. The jobs are taken by priority, the jobs in memory before
+// the spilled ones.
+type Provider struct {
+	dir string
+	// tmp is set when the directory was created by the provider
+	tmp     bool
+	maxJobs int
+
+	mu      sync.Mutex
+	runtime gmaps.Runtime
+	mem     [priorities][]scrapemate.IJob
+	inMem   int
+	spills  [priorities]*spill
+	spilled int
+	closed  bool
+	// notify wakes up Jobs when a job is pushed
+	notify chan struct{}
+}
+
+// New returns a provider keeping at most maxJobs pending jobs in memory and
+// spilling the others to dir. With an empty dir the spill files go to a
+// temporary directory, removed by Close.
+func New(maxJobs int, dir string) (*Provider, error) {
+	p := Provider{
+		dir:     dir,
+		maxJobs: max(maxJobs, 1),
+		notify:  make(chan struct{}, 1),
+	}
+
+	if dir == "" {
+		var err error
+
+		p.dir, err = os.MkdirTemp("", "gmaps-spill-")
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the spill directory: %w", err)
+		}
+
+		p.tmp = true
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create the spill directory: %w", err)
+	}
+
+	return &p, nil
+}
+
+// SetRuntime sets the state of the run on the jobs read back from the spill
+// files. It is called before the jobs are taken.
+func (p *Provider) SetRuntime(rt gmaps.Runtime) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.runtime = rt
+}
+
+// Push keeps the job in memory, or appends it to the spill file of its
+// priority when maxJobs jobs are in memory. The jobs that cannot be encoded
+// are kept in memory.
+func (p *Provider) Push(_ context.Context, job scrapemate.IJob) error {
+	prio := priority(job)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return errors.New("spillqueue: the provider is closed")
+	}
+
+	if p.inMem >= p.maxJobs {
+		err := p.spill(prio, job)
+		if err == nil {
+			p.wake()
+
+			return nil
+		}
+
+		slog.Warn("spillqueue: job kept in memory", "job", job.GetID(), "error", err)
+	}
+
+	p.mem[prio] = append(p.mem[prio], job)
+	p.inMem++
+
+	p.wake()
+
+	return nil
+}
+
+// Pending returns the number of the pending jobs in memory and in the spill
+// files
+func (p *Provider) Pending() (inMemory, spilled int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.inMem, p.spilled
+}
+
+// Jobs returns the pending jobs, by priority
+//
+//nolint:gocritic // scrapemate.JobProvider returns read only channels
+func (p *Provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	outc := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	go func() {
+		for {
+			job, err := p.pop()
+			if err != nil {
+				errc <- err
+
+				return
+			}
+
+			if job == nil {
+				select {
+				case <-ctx.Done():
+					errc <- ctx.Err()
+
+					return
+				case <-p.notify:
+				}
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				errc <- ctx.Err()
+
+				return
+			case outc <- job:
+			}
+		}
+	}()
+
+	return outc, errc
+}
+
+// Close removes the spill files, and the spill directory when it is a
+// temporary one
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+
+	var errs []error
+
+	for i, s := range p.spills {
+		if s == nil {
+			continue
+		}
+
+		errs = append(errs, s.remove())
+		p.spills[i] = nil
+	}
+
+	if p.tmp {
+		errs = append(errs, os.RemoveAll(p.dir))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Provider) wake() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the first job of the highest priority, nil when no job is
+// pending
+func (p *Provider) pop() (scrapemate.IJob, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for prio := range priorities {
+		if q := p.mem[prio]; len(q) > 0 {
+			job := q[0]
+			q[0] = nil
+			p.mem[prio] = q[1:]
+			p.inMem--
+
+			return job, nil
+		}
+
+		if s := p.spills[prio]; s != nil && s.count > 0 {
+			job, err := s.readJob()
+			if err != nil {
+				return nil, fmt.Errorf("spillqueue: cannot read the spilled jobs: %w", err)
+			}
+
+			p.spilled--
+
+			p.runtime.Attach(job)
+
+			return job, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// spill appends the job to the spill file of prio
+func (p *Provider) spill(prio int, job scrapemate.IJob) error {
+	payloadType, payload, err := gmaps.EncodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	s := p.spills[prio]
+	if s == nil {
+		s, err = newSpill(p.dir, fmt.Sprintf("spill-p%d-*", prio))
+		if err != nil {
+			return err
+		}
+
+		p.spills[prio] = s
+	}
+
+	if err := s.write(payloadType, payload); err != nil {
+		return err
+	}
+
+	p.spilled++
+
+	return nil
+}
+
+// spill is a spill file: the jobs are appended at the end and read from the
+// start, the file is truncated once all its jobs are read
+type spill struct {
+	f *os.File
+	// end is where the next job is appended, off where the next job is read
+	end   int64
+	off   int64
+	count int
+}
+
+func newSpill(dir, pattern string) (*spill, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the spill file: %w", err)
+	}
+
+	return &spill{f: f}, nil
+}
+
+// write appends a record: the lengths of the payload type and of the payload,
+// as uint32, then the payload type and the payload
+func (s *spill) write(payloadType string, payload []byte) error {
+	rec := make([]byte, 0, 8+len(payloadType)+len(payload))
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(len(payloadType)))
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(len(payload)))
+	rec = append(rec, payloadType...)
+	rec = append(rec, payload...)
+
+	if _, err := s.f.WriteAt(rec, s.end); err != nil {
+		return fmt.Errorf("cannot write the spill file: %w", err)
+	}
+
+	s.end += int64(len(rec))
+	s.count++
+
+	return nil
+}
+
+// readJob decodes the job of the next record
+func (s *spill) readJob() (scrapemate.IJob, error) {
+	payloadType, payload, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return gmaps.DecodeJob(payloadType, payload)
+}
+
+// read returns the payload type and the payload of the next record
+func (s *spill) read() (payloadType string, payload []byte, err error) {
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.off, s.end-s.off))
+
+	var header [8]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", nil, err
+	}
+
+	typeLen := binary.LittleEndian.Uint32(header[:4])
+	payloadLen := binary.LittleEndian.Uint32(header[4:])
+
+	if int64(typeLen)+int64(payloadLen) > maxRecord {
+		return "", nil, fmt.Errorf("invalid record of %d bytes at offset %d", typeLen+payloadLen, s.off)
+	}
+
+	rec := make([]byte, typeLen+payloadLen)
+
+	if _, err := io.ReadFull(r, rec); err != nil {
+		return "", nil, err
+	}
+
+	s.off += int64(len(header) + len(rec))
+	s.count--
+
+	if s.count == 0 {
+		if err := s.f.Truncate(0); err != nil {
+			return "", nil, err
+		}
+
+		s.off, s.end = 0, 0
+	}
+
+	return string(rec[:typeLen]), rec[typeLen:], nil
+}
+
+// remove closes and removes the spill file
+func (s *spill) remove() error {
+	return errors.Join(s.f.Close(), os.Remove(s.f.Name()))
+}
+
+func priority(job scrapemate.IJob) int {
+	prio := job.GetPriority()
+	if prio < scrapemate.PriorityHigh || prio > scrapemate.PriorityLow {
+		return scrapemate.PriorityHigh
+	}
+
+	return prio
+}
@@ -0,0 +1,133 @@
+package spillqueue_test
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/spillqueue"
+)
+
+func placeJob(u string) scrapemate.IJob {
+	return gmaps.NewPlaceJob("seed", "en", u, false, false)
+}
+
+// spillFile returns the only spill file of dir matching pattern
+func spillFile(t *testing.T, dir, pattern string) string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, pattern))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	return files[0]
+}
+
+func take(t *testing.T, jobs <-chan scrapemate.IJob, n int) []string {
+	t.Helper()
+
+	ans := make([]string, 0, n)
+	for range n {
+		ans = append(ans, (<-jobs).GetURL())
+	}
+
+	return ans
+}
+
+func Test_ProviderPriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p, err := spillqueue.New(1, t.TempDir())
+	require.NoError(t, err)
+
+	defer p.Close()
+
+	search := gmaps.NewGmapJob("q", "en", "cafe", 1, false, "", 0, "")
+	email := gmaps.NewEmailJob("seed", &gmaps.Entry{WebSite: "https://example.com"})
+
+	// the low priority search stays in memory, the others are spilled
+	for _, job := range []scrapemate.IJob{search, placeJob("https://example.com/a"), email, placeJob("https://example.com/b")} {
+		require.NoError(t, p.Push(ctx, job))
+	}
+
+	inMemory, spilled := p.Pending()
+	require.Equal(t, 1, inMemory)
+	require.Equal(t, 3, spilled)
+
+	jobs, _ := p.Jobs(ctx)
+
+	require.Equal(t, []string{
+		email.GetURL(),
+		"https://example.com/a",
+		"https://example.com/b",
+		search.GetURL(),
+	}, take(t, jobs, 4))
+}
+
+func Test_ProviderSpillFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+
+	p, err := spillqueue.New(1, dir)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Push(ctx, placeJob("https://example.com/mem")))
+	require.NoError(t, p.Push(ctx, placeJob("https://example.com/a")))
+
+	// a record is the lengths of the payload type and of the payload, then
+	// the payload type and the payload of the encoded job
+	name := spillFile(t, dir, "spill-p1-*")
+
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Greater(t, len(b), 8)
+
+	typeLen := binary.LittleEndian.Uint32(b[:4])
+	payloadLen := binary.LittleEndian.Uint32(b[4:8])
+	require.Equal(t, len(b), 8+int(typeLen)+int(payloadLen))
+	require.Equal(t, gmaps.PayloadPlace, string(b[8:8+typeLen]))
+
+	job, err := gmaps.DecodeJob(gmaps.PayloadPlace, b[8+typeLen:])
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/a", job.GetURL())
+
+	jobs, _ := p.Jobs(ctx)
+
+	// the jobs of a priority in memory are taken before the spilled ones
+	require.Equal(t, []string{"https://example.com/mem", "https://example.com/a"}, take(t, jobs, 2))
+
+	// the file is truncated once all its jobs are read
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+
+	require.NoError(t, p.Close())
+
+	_, err = os.Stat(name)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_ProviderTempDir(t *testing.T) {
+	p, err := spillqueue.New(1, "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, p.Push(ctx, placeJob("https://example.com/a")))
+	require.NoError(t, p.Push(ctx, placeJob("https://example.com/b")))
+
+	_, spilled := p.Pending()
+	require.Equal(t, 1, spilled)
+
+	require.NoError(t, p.Close())
+	require.Error(t, p.Push(ctx, placeJob("https://example.com/c")))
+}
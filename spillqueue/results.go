@@ -0,0 +1,393 @@
+package spillqueue
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The kinds of the data of the spilled results
+const (
+	kindEntry   = "entry"
+	kindEntries = "entries"
+)
+
+// Results is a bounded buffer of the results between the workers and the
+// writers, it keeps at most maxResults results in memory. The workers wait
+// while it is full, so that the scraping slows down to the pace of the
+// writers. With WithSpill the results over the bound are appended to a spill
+// file instead and read back once the writers took the ones in memory.
+type Results struct {
+	maxResults int
+	spill      bool
+	dir        string
+	// tmp is set when the directory was created by the buffer
+	tmp bool
+
+	mu sync.Mutex
+	// queue are the results in order, the ones in memory and the runs of
+	// the spilled ones read back from file
+	queue   []pending
+	inMem   int
+	file    *spill
+	spilled int
+	// ended is set once all the results of the workers are in the buffer
+	ended  bool
+	closed bool
+	// notify wakes up the writers when a result is pushed
+	notify chan struct{}
+}
+
+// pending is a result in memory, or the next spilled results of the file
+type pending struct {
+	result  scrapemate.Result
+	spilled int
+}
+
+// ResultsOption configures a Results buffer
+type ResultsOption func(*Results)
+
+// WithSpill spills the results over the bound to a file of dir instead of
+// making the workers wait. With an empty dir the file goes to a temporary
+// directory, removed by Close.
+func WithSpill(dir string) ResultsOption {
+	return func(b *Results) {
+		b.spill = true
+		b.dir = dir
+	}
+}
+
+// NewResults returns a buffer keeping at most maxResults results in memory
+func NewResults(maxResults int, opts ...ResultsOption) (*Results, error) {
+	b := Results{
+		maxResults: max(maxResults, 1),
+		notify:     make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(&b)
+	}
+
+	if !b.spill {
+		return &b, nil
+	}
+
+	if b.dir == "" {
+		var err error
+
+		b.dir, err = os.MkdirTemp("", "gmaps-spill-")
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the spill directory: %w", err)
+		}
+
+		b.tmp = true
+	} else if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create the spill directory: %w", err)
+	}
+
+	return &b, nil
+}
+
+// Run passes the results of in to the returned channel through the buffer.
+// The channel is closed once in is closed and all its results are taken, or
+// when ctx is done. A spill file that cannot be read is an error of errc.
+//
+//nolint:gocritic // the results are read like the jobs of a provider
+func (b *Results) Run(ctx context.Context, in <-chan scrapemate.Result) (<-chan scrapemate.Result, <-chan error) {
+	errc := make(chan error, 1)
+
+	if !b.spill {
+		out := make(chan scrapemate.Result, b.maxResults)
+
+		go func() {
+			defer close(out)
+			defer close(errc)
+
+			for result := range in {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- result:
+				}
+			}
+		}()
+
+		return out, errc
+	}
+
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		for result := range in {
+			b.push(result)
+		}
+
+		b.mu.Lock()
+		b.ended = true
+		b.mu.Unlock()
+
+		b.wake()
+	}()
+
+	go func() {
+		defer close(out)
+		defer close(errc)
+
+		for {
+			result, ok, err := b.pop()
+			if err != nil {
+				errc <- err
+
+				return
+			}
+
+			if !ok {
+				if b.done() {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-b.notify:
+				}
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- result:
+			}
+		}
+	}()
+
+	return out, errc
+}
+
+// Pending returns the number of the results waiting for the writers in
+// memory and in the spill file
+func (b *Results) Pending() (inMemory, spilled int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.inMem, b.spilled
+}
+
+// Close removes the spill file, and the spill directory when it is a
+// temporary one
+func (b *Results) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+
+	var errs []error
+
+	if b.file != nil {
+		errs = append(errs, b.file.remove())
+		b.file = nil
+	}
+
+	if b.tmp {
+		errs = append(errs, os.RemoveAll(b.dir))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Results) wake() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// push keeps the result in memory, or appends it to the spill file when
+// maxResults results are in memory. The results that cannot be encoded are
+// kept in memory, in their order.
+func (b *Results) push(result scrapemate.Result) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	defer b.wake()
+
+	if !b.closed && b.inMem >= b.maxResults {
+		err := b.spillResult(result)
+		if err == nil {
+			if n := len(b.queue); n > 0 && b.queue[n-1].spilled > 0 {
+				b.queue[n-1].spilled++
+			} else {
+				b.queue = append(b.queue, pending{spilled: 1})
+			}
+
+			return
+		}
+
+		slog.Warn("spillqueue: result kept in memory", "error", err)
+	}
+
+	b.queue = append(b.queue, pending{result: result})
+	b.inMem++
+}
+
+// pop takes the first result, false when no result is pending
+func (b *Results) pop() (scrapemate.Result, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.queue) == 0 {
+		return scrapemate.Result{}, false, nil
+	}
+
+	head := &b.queue[0]
+
+	if head.spilled == 0 {
+		result := head.result
+		b.queue[0] = pending{}
+		b.queue = b.queue[1:]
+		b.inMem--
+
+		return result, true, nil
+	}
+
+	if b.file == nil {
+		return scrapemate.Result{}, false, errors.New("spillqueue: the buffer is closed")
+	}
+
+	payloadType, payload, err := b.file.read()
+	if err != nil {
+		return scrapemate.Result{}, false, fmt.Errorf("spillqueue: cannot read the spilled results: %w", err)
+	}
+
+	b.spilled--
+
+	if head.spilled--; head.spilled == 0 {
+		b.queue = b.queue[1:]
+	}
+
+	result, err := decodeResult(payloadType, payload)
+	if err != nil {
+		return scrapemate.Result{}, false, fmt.Errorf("spillqueue: cannot read the spilled results: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// done reports whether all the results of the workers were taken
+func (b *Results) done() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.ended && len(b.queue) == 0
+}
+
+func (b *Results) spillResult(result scrapemate.Result) error {
+	payloadType, payload, err := encodeResult(result)
+	if err != nil {
+		return err
+	}
+
+	if b.file == nil {
+		if b.file, err = newSpill(b.dir, "spill-results-*"); err != nil {
+			return err
+		}
+	}
+
+	if err := b.file.write(payloadType, payload); err != nil {
+		return err
+	}
+
+	b.spilled++
+
+	return nil
+}
+
+// encodeResult encodes the entries of a result and its job: the payload type
+// is the kind of the data and the payload type of the job, the payload the
+// length of the job as uint32, the job and the data as JSON
+func encodeResult(result scrapemate.Result) (payloadType string, payload []byte, err error) {
+	var kind string
+
+	switch result.Data.(type) {
+	case *gmaps.Entry:
+		kind = kindEntry
+	case []*gmaps.Entry:
+		kind = kindEntries
+	default:
+		return "", nil, fmt.Errorf("invalid result data %T", result.Data)
+	}
+
+	jobType, job, err := gmaps.EncodeJob(result.Job)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload = make([]byte, 0, 4+len(job)+len(data))
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(len(job))) //nolint:gosec // the records are smaller than maxRecord
+	payload = append(payload, job...)
+	payload = append(payload, data...)
+
+	return kind + "/" + jobType, payload, nil
+}
+
+// decodeResult decodes a result encoded by encodeResult
+func decodeResult(payloadType string, payload []byte) (scrapemate.Result, error) {
+	kind, jobType, ok := strings.Cut(payloadType, "/")
+	if !ok || len(payload) < 4 {
+		return scrapemate.Result{}, fmt.Errorf("invalid result record %q", payloadType)
+	}
+
+	jobLen := binary.LittleEndian.Uint32(payload)
+	if int64(jobLen) > int64(len(payload)-4) {
+		return scrapemate.Result{}, fmt.Errorf("invalid result record %q", payloadType)
+	}
+
+	job, err := gmaps.DecodeJob(jobType, payload[4:4+jobLen])
+	if err != nil {
+		return scrapemate.Result{}, err
+	}
+
+	data := payload[4+jobLen:]
+
+	switch kind {
+	case kindEntry:
+		var entry gmaps.Entry
+
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return scrapemate.Result{}, err
+		}
+
+		return scrapemate.Result{Job: job, Data: &entry}, nil
+	case kindEntries:
+		var entries []*gmaps.Entry
+
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return scrapemate.Result{}, err
+		}
+
+		return scrapemate.Result{Job: job, Data: entries}, nil
+	default:
+		return scrapemate.Result{}, fmt.Errorf("invalid result record %q", payloadType)
+	}
+}
@@ -0,0 +1,132 @@
+package spillqueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/spillqueue"
+)
+
+func entryResult(title string) scrapemate.Result {
+	return scrapemate.Result{
+		Job:  placeJob("https://example.com/" + title),
+		Data: &gmaps.Entry{Title: title, Categories: []string{"Cafe"}},
+	}
+}
+
+func Test_ResultsOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []spillqueue.ResultsOption
+	}{
+		{name: "in memory"},
+		{name: "spilled", opts: []spillqueue.ResultsOption{spillqueue.WithSpill(t.TempDir())}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			b, err := spillqueue.NewResults(2, tc.opts...)
+			require.NoError(t, err)
+
+			defer b.Close()
+
+			in := make(chan scrapemate.Result)
+			out, errc := b.Run(ctx, in)
+
+			sent := []scrapemate.Result{
+				entryResult("a"),
+				{Job: placeJob("https://example.com/b"), Data: []*gmaps.Entry{{Title: "b1"}, {Title: "b2"}}},
+				entryResult("c"),
+				entryResult("d"),
+				// the results without entries are not spilled
+				{Job: placeJob("https://example.com/e"), Data: "e"},
+				entryResult("f"),
+			}
+
+			go func() {
+				defer close(in)
+
+				for _, result := range sent {
+					in <- result
+				}
+			}()
+
+			var got []scrapemate.Result
+			for result := range out {
+				got = append(got, result)
+			}
+
+			require.NoError(t, <-errc)
+			require.Len(t, got, len(sent))
+
+			for i := range sent {
+				require.Equal(t, sent[i].Job.GetURL(), got[i].Job.GetURL())
+				require.Equal(t, sent[i].Data, got[i].Data)
+			}
+		})
+	}
+}
+
+func Test_ResultsBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b, err := spillqueue.NewResults(2)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result)
+	_, _ = b.Run(ctx, in)
+
+	// the buffer takes two results and holds the third one, the writers
+	// do not take them
+	for _, title := range []string{"a", "b", "c"} {
+		in <- entryResult(title)
+	}
+
+	select {
+	case in <- entryResult("d"):
+		t.Fatal("the buffer took a result over its bound")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func Test_ResultsSpill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b, err := spillqueue.NewResults(2, spillqueue.WithSpill(""))
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result)
+	out, _ := b.Run(ctx, in)
+
+	// the workers do not wait for the writers
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		in <- entryResult(title)
+	}
+
+	close(in)
+
+	require.Eventually(t, func() bool {
+		inMemory, spilled := b.Pending()
+
+		// the first result may be held by the writers
+		return inMemory+spilled >= 4 && inMemory <= 2
+	}, time.Second, 10*time.Millisecond)
+
+	var titles []string
+	for result := range out {
+		titles = append(titles, result.Data.(*gmaps.Entry).Title)
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, titles)
+	require.NoError(t, b.Close())
+}
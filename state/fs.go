@@ -0,0 +1,250 @@
+package state
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+// setExt is the extension of the files of the sets of a directory
+const setExt = ".set"
+
+// setNameRe matches the names of the sets
+var setNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var _ Store = (*fsStore)(nil)
+
+// fsStore keeps the values in the files of a directory, a key is the path of
+// its file in the directory
+type fsStore struct {
+	dir string
+}
+
+// NewFS returns a store keeping the values in the files of dir, it is created
+// if it does not exist. The keys are relative paths that stay in dir. With an
+// empty dir the keys are the paths of the files.
+func NewFS(dir string) (Store, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("cannot create the state directory: %w", err)
+		}
+	}
+
+	return &fsStore{dir: dir}, nil
+}
+
+// path returns the file of the key, the keys escaping the directory are
+// rejected
+func (s *fsStore) path(key string) (string, error) {
+	key = filepath.FromSlash(key)
+
+	if s.dir == "" {
+		return key, nil
+	}
+
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid state key %q: it is not a path in the state directory", key)
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
+
+func (s *fsStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return b, err
+}
+
+// Put writes the value to a temporary file of the directory of the key and
+// renames it, so that an interrupted Put keeps the previous file
+func (s *fsStore) Put(_ context.Context, key string, value []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(value)
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *fsStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *fsStore) Set(_ context.Context, name string, ttl time.Duration) (deduper.Store, error) {
+	if !setNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid set name %q", name)
+	}
+
+	path, err := s.path(name + setExt)
+	if err != nil {
+		return nil, err
+	}
+
+	return openFileSet(path, ttl)
+}
+
+func (s *fsStore) Close() error {
+	return nil
+}
+
+var _ deduper.Store = (*fileSet)(nil)
+
+// fileSet keeps the seen keys in memory and appends them to its file with the
+// time they were added, one "time key" line per key. The file is read when
+// the set is opened, it is not shared by the scrapers running at the same
+// time.
+type fileSet struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	f    *os.File
+	seen map[string]int64
+}
+
+func openFileSet(path string, ttl time.Duration) (*fileSet, error) {
+	set := fileSet{ttl: ttl, seen: make(map[string]int64)}
+
+	if err := set.load(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the set: %w", err)
+	}
+
+	set.f = f
+
+	return &set, nil
+}
+
+func (s *fileSet) load(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot read the set: %w", err)
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		at, key, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+
+		// an interrupted write leaves a partial last line
+		t, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		s.seen[key] = max(s.seen[key], t)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("cannot read the set %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (s *fileSet) cutoff() int64 {
+	if s.ttl <= 0 {
+		return 0
+	}
+
+	return time.Now().Add(-s.ttl).Unix()
+}
+
+func (s *fileSet) AddIfNotExists(_ context.Context, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if at, ok := s.seen[key]; ok && at >= s.cutoff() {
+		return false
+	}
+
+	now := time.Now().UTC().Unix()
+
+	s.seen[key] = now
+
+	if _, err := fmt.Fprintf(s.f, "%d %s\n", now, key); err != nil {
+		slog.Warn("file set write failed", "key", key, "error", err)
+	}
+
+	return true
+}
+
+func (s *fileSet) Seen(_ context.Context, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.seen[key]
+
+	return ok && at >= s.cutoff()
+}
+
+func (s *fileSet) Close() error {
+	return s.f.Close()
+}
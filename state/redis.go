@@ -0,0 +1,61 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+var _ Store = (*redisStore)(nil)
+
+// redisStore keeps the values in the strings prefix state:<key> and the sets
+// in the sorted sets prefix<name>, the set seen is the one of the seen places
+// of -dedup-store with a redis url
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedis returns a store keeping the state in redis under the keys of
+// prefix, e.g. gmaps:. The client is closed with the store.
+func NewRedis(client redis.UniversalClient, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) key(key string) string {
+	return s.prefix + "state:" + key
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+
+	return b, err
+}
+
+func (s *redisStore) Put(ctx context.Context, key string, value []byte) error {
+	return s.client.Set(ctx, s.key(key), value, 0).Err()
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+func (s *redisStore) Set(_ context.Context, name string, ttl time.Duration) (deduper.Store, error) {
+	if !setNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid set name %q", name)
+	}
+
+	return sharedSet{deduper.NewRedisStore(s.client, s.prefix+name, ttl)}, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // sqlite driver
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+var _ Store = (*sqliteStore)(nil)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS state (
+	key TEXT PRIMARY KEY,
+	value BLOB NOT NULL,
+	updated_at INTEGER NOT NULL
+) WITHOUT ROWID;
+`
+
+// sqliteStore keeps the values in the state table of a SQLite database file
+// and the sets in their own tables, the set seen is the table of the seen
+// places of -dedup-store sqlite:<path>. The database is in WAL mode with a
+// busy timeout, so several scrapers can share the file.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLite opens the SQLite database file path, it is created if it does
+// not exist
+func NewSQLite(path string) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	pragmas := []string{
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+	}
+
+	for _, q := range append(pragmas, sqliteSchema) {
+		if _, err := db.Exec(q); err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("cannot open the state database: %w", err)
+		}
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM state WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+func (s *sqliteStore) Put(ctx context.Context, key string, value []byte) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO state (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		key, value, time.Now().UTC().Unix())
+
+	return err
+}
+
+func (s *sqliteStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM state WHERE key = ?`, key)
+
+	return err
+}
+
+func (s *sqliteStore) Set(_ context.Context, name string, ttl time.Duration) (deduper.Store, error) {
+	set, err := deduper.NewSQLiteDB(s.db, name, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return sharedSet{set}, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
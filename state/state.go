@@ -0,0 +1,90 @@
+// Package state keeps the state of the runs behind one Store: the
+// checkpoints, the sets of the places seen, the values of the monitored places
+// and the runs of the schedules. The state is kept in files of a directory, in
+// a SQLite database or in redis, so that the stateful features share one
+// backend instead of their own files.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+// ErrNotFound is returned by Get for the keys without a value
+var ErrNotFound = errors.New("state: not found")
+
+// sqlitePrefix is the prefix of the SQLite stores
+const sqlitePrefix = "sqlite:"
+
+// redisPrefix is the prefix of the keys of the redis stores
+const redisPrefix = "gmaps:"
+
+// Store keeps the values of the keys of the state, e.g. checkpoint.json,
+// and the sets of the keys seen
+type Store interface {
+	// Get returns the value of key or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put sets the value of key at once, an interrupted Put keeps the
+	// previous value
+	Put(ctx context.Context, key string, value []byte) error
+	// Delete removes the value of key, a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Set returns the set of the seen keys name, e.g. the places seen by the
+	// runs. The keys added before ttl are expired, 0 keeps them forever.
+	// Closing the set does not close the store.
+	Set(ctx context.Context, name string, ttl time.Duration) (deduper.Store, error)
+	Close() error
+}
+
+// Open opens the store of dsn: sqlite:<path>, a redis url or the path of a
+// directory, created if it does not exist. An empty dsn is the files of the
+// working directory, the keys are their paths.
+func Open(ctx context.Context, dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, sqlitePrefix):
+		return NewSQLite(strings.TrimPrefix(dsn, sqlitePrefix))
+	case strings.HasPrefix(dsn, "redis://"), strings.HasPrefix(dsn, "rediss://"):
+		opts, err := redis.ParseURL(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis url: %w", err)
+		}
+
+		client := redis.NewClient(opts)
+
+		if err := client.Ping(ctx).Err(); err != nil {
+			_ = client.Close()
+
+			return nil, fmt.Errorf("cannot connect to redis: %w", err)
+		}
+
+		return NewRedis(client, redisPrefix), nil
+	default:
+		return NewFS(dsn)
+	}
+}
+
+// RedisClient returns the client of a redis store, the other stores have none
+func RedisClient(s Store) (redis.UniversalClient, bool) {
+	r, ok := s.(*redisStore)
+	if !ok {
+		return nil, false
+	}
+
+	return r.client, true
+}
+
+// sharedSet is a set of a store, it is closed with the store
+type sharedSet struct {
+	deduper.Store
+}
+
+func (sharedSet) Close() error {
+	return nil
+}
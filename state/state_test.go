@@ -0,0 +1,196 @@
+package state_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/state"
+)
+
+// stores are the stores run by the conformance tests, open opens the store
+// kept in dir, again after it is closed
+var stores = []struct {
+	name string
+	open func(t *testing.T, dir string) state.Store
+}{
+	{
+		name: "fs",
+		open: func(t *testing.T, dir string) state.Store {
+			t.Helper()
+
+			s, err := state.NewFS(dir)
+			require.NoError(t, err)
+
+			return s
+		},
+	},
+	{
+		name: "sqlite",
+		open: func(t *testing.T, dir string) state.Store {
+			t.Helper()
+
+			s, err := state.NewSQLite(filepath.Join(dir, "state.db"))
+			require.NoError(t, err)
+
+			return s
+		},
+	},
+}
+
+func Test_StoreValues(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range stores {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := tc.open(t, dir)
+
+			_, err := s.Get(ctx, "checkpoint.json")
+			require.ErrorIs(t, err, state.ErrNotFound)
+
+			require.NoError(t, s.Put(ctx, "checkpoint.json", []byte("v1")))
+			require.NoError(t, s.Put(ctx, "schedule/daily.json", []byte("run")))
+			require.NoError(t, s.Put(ctx, "checkpoint.json", []byte("v2")))
+
+			b, err := s.Get(ctx, "checkpoint.json")
+			require.NoError(t, err)
+			require.Equal(t, []byte("v2"), b)
+
+			b, err = s.Get(ctx, "schedule/daily.json")
+			require.NoError(t, err)
+			require.Equal(t, []byte("run"), b)
+
+			require.NoError(t, s.Delete(ctx, "checkpoint.json"))
+			require.NoError(t, s.Delete(ctx, "missing.json"))
+
+			_, err = s.Get(ctx, "checkpoint.json")
+			require.ErrorIs(t, err, state.ErrNotFound)
+
+			require.NoError(t, s.Close())
+
+			// the values are kept by the store
+			s = tc.open(t, dir)
+			defer s.Close()
+
+			b, err = s.Get(ctx, "schedule/daily.json")
+			require.NoError(t, err)
+			require.Equal(t, []byte("run"), b)
+		})
+	}
+}
+
+func Test_StoreSet(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range stores {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := tc.open(t, dir)
+
+			set, err := s.Set(ctx, "seen", 0)
+			require.NoError(t, err)
+
+			require.True(t, set.AddIfNotExists(ctx, "a"))
+			require.False(t, set.AddIfNotExists(ctx, "a"))
+			require.True(t, set.Seen(ctx, "a"))
+			require.False(t, set.Seen(ctx, "b"))
+
+			require.NoError(t, set.Close())
+			require.NoError(t, s.Close())
+
+			// the set is read again when the store is opened
+			s = tc.open(t, dir)
+			defer s.Close()
+
+			set, err = s.Set(ctx, "seen", 0)
+			require.NoError(t, err)
+
+			defer set.Close()
+
+			require.True(t, set.Seen(ctx, "a"))
+			require.False(t, set.AddIfNotExists(ctx, "a"))
+			require.True(t, set.AddIfNotExists(ctx, "b"))
+
+			_, err = s.Set(ctx, "../seen", 0)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_StoreSetTTL(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range stores {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := tc.open(t, t.TempDir())
+			defer s.Close()
+
+			set, err := s.Set(ctx, "seen", time.Second)
+			require.NoError(t, err)
+
+			defer set.Close()
+
+			require.True(t, set.AddIfNotExists(ctx, "a"))
+			require.True(t, set.Seen(ctx, "a"))
+
+			// the times of the keys are in seconds
+			time.Sleep(2100 * time.Millisecond)
+
+			require.False(t, set.Seen(ctx, "a"))
+			require.True(t, set.AddIfNotExists(ctx, "a"))
+			require.True(t, set.Seen(ctx, "a"))
+		})
+	}
+}
+
+func Test_FSKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{key: "checkpoint.json", valid: true},
+		{key: "schedule/daily.json", valid: true},
+		{key: "schedule/../checkpoint.json", valid: true},
+		{key: "../checkpoint.json"},
+		{key: "schedule/../../checkpoint.json"},
+		{key: "/etc/checkpoint.json"},
+		{key: ""},
+	}
+
+	s, err := state.NewFS(filepath.Join(t.TempDir(), "state"))
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			err := s.Put(ctx, tc.key, []byte("v"))
+
+			if !tc.valid {
+				require.Error(t, err)
+
+				_, err = s.Get(ctx, tc.key)
+				require.Error(t, err)
+				require.NotErrorIs(t, err, state.ErrNotFound)
+
+				require.Error(t, s.Delete(ctx, tc.key))
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			b, err := s.Get(ctx, tc.key)
+			require.NoError(t, err)
+			require.Equal(t, []byte("v"), b)
+		})
+	}
+}
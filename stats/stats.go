@@ -0,0 +1,214 @@
+// Package stats aggregates the entries of a run into summary statistics.
+package stats
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// CategoryCount is a category and the number of its places
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// RatingBucket counts the places rated from Min (included) to Max
+type RatingBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// Aggregates are the statistics of the places of a run
+type Aggregates struct {
+	Places     int             `json:"places"`
+	Categories []CategoryCount `json:"categories"`
+	// CanonicalCategories counts the places by their canonical category, the
+	// places of the categories without one are not counted
+	CanonicalCategories []CategoryCount `json:"canonical_categories"`
+
+	Rated         int            `json:"rated"`
+	AverageRating float64        `json:"average_rating"`
+	Ratings       []RatingBucket `json:"ratings"`
+	Reviews       int            `json:"reviews"`
+
+	WithEmail   int     `json:"with_email"`
+	EmailRate   float64 `json:"email_rate"`
+	WithWebsite int     `json:"with_website"`
+	WebsiteRate float64 `json:"website_rate"`
+	WithPhone   int     `json:"with_phone"`
+	PhoneRate   float64 `json:"phone_rate"`
+
+	// AreaKm2 is the searched area, or the bounding box of the places
+	// when the run has no radius
+	AreaKm2      float64 `json:"area_km2"`
+	PlacesPerKm2 float64 `json:"places_per_km2"`
+}
+
+// Collector accumulates the entries, it is safe for concurrent use
+type Collector struct {
+	mu sync.Mutex
+
+	areaKm2 float64
+
+	places     int
+	categories map[string]int
+	canonical  map[string]int
+	rated      int
+	ratingSum  float64
+	ratings    [5]int
+	reviews    int
+	email      int
+	website    int
+	phone      int
+
+	minLat, minLon, maxLat, maxLon float64
+}
+
+// NewCollector returns a collector. areaKm2 is the searched area used for the
+// density, 0 to use the bounding box of the places.
+func NewCollector(areaKm2 float64) *Collector {
+	return &Collector{
+		areaKm2:    areaKm2,
+		categories: make(map[string]int),
+		canonical:  make(map[string]int),
+		minLat:     math.Inf(1),
+		minLon:     math.Inf(1),
+		maxLat:     math.Inf(-1),
+		maxLon:     math.Inf(-1),
+	}
+}
+
+// Add counts the entry
+func (c *Collector) Add(e *gmaps.Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.places++
+
+	if e.Category != "" {
+		c.categories[e.Category]++
+	}
+
+	if e.CanonicalCategory != "" {
+		c.canonical[e.CanonicalCategory]++
+	}
+
+	if e.ReviewRating > 0 {
+		c.rated++
+		c.ratingSum += e.ReviewRating
+		// 5 stars goes in the last bucket
+		c.ratings[min(int(e.ReviewRating), len(c.ratings)-1)]++
+	}
+
+	c.reviews += e.ReviewCount
+
+	if len(e.Emails) > 0 {
+		c.email++
+	}
+
+	if e.WebSite != "" {
+		c.website++
+	}
+
+	if e.Phone != "" {
+		c.phone++
+	}
+
+	if e.Latitude != 0 || e.Longtitude != 0 {
+		c.minLat, c.maxLat = min(c.minLat, e.Latitude), max(c.maxLat, e.Latitude)
+		c.minLon, c.maxLon = min(c.minLon, e.Longtitude), max(c.maxLon, e.Longtitude)
+	}
+}
+
+// Aggregates returns the statistics of the entries added so far
+func (c *Collector) Aggregates() Aggregates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ans := Aggregates{
+		Places:      c.places,
+		Categories:  categoryCounts(c.categories),
+		Rated:       c.rated,
+		Ratings:     make([]RatingBucket, len(c.ratings)),
+		Reviews:     c.reviews,
+		WithEmail:   c.email,
+		EmailRate:   rate(c.email, c.places),
+		WithWebsite: c.website,
+		WebsiteRate: rate(c.website, c.places),
+		WithPhone:   c.phone,
+		PhoneRate:   rate(c.phone, c.places),
+		AreaKm2:     c.areaKm2,
+	}
+
+	ans.CanonicalCategories = categoryCounts(c.canonical)
+
+	if c.rated > 0 {
+		ans.AverageRating = round(c.ratingSum / float64(c.rated))
+	}
+
+	for i, n := range c.ratings {
+		ans.Ratings[i] = RatingBucket{Min: float64(i), Max: float64(i + 1), Count: n}
+	}
+
+	if ans.AreaKm2 <= 0 && c.maxLat > c.minLat && c.maxLon > c.minLon {
+		ans.AreaKm2 = round(boxAreaKm2(c.minLat, c.minLon, c.maxLat, c.maxLon))
+	}
+
+	if ans.AreaKm2 > 0 {
+		ans.PlacesPerKm2 = round(float64(c.places) / ans.AreaKm2)
+	}
+
+	return ans
+}
+
+// CircleAreaKm2 returns the area of a circle of radius meters
+func CircleAreaKm2(radius float64) float64 {
+	return math.Pi * radius * radius / 1e6
+}
+
+// boxAreaKm2 returns the area of the box between the coordinates
+func boxAreaKm2(minLat, minLon, maxLat, maxLon float64) float64 {
+	const earthRadiusKm = 6371
+
+	toRad := math.Pi / 180
+
+	return earthRadiusKm * earthRadiusKm *
+		(maxLon - minLon) * toRad *
+		math.Abs(math.Sin(maxLat*toRad)-math.Sin(minLat*toRad))
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return round(float64(n) / float64(total))
+}
+
+// round keeps 3 decimals
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// categoryCounts returns the counts of the categories, the most frequent first
+func categoryCounts(counts map[string]int) []CategoryCount {
+	ans := make([]CategoryCount, 0, len(counts))
+
+	for category, n := range counts {
+		ans = append(ans, CategoryCount{Category: category, Count: n})
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Count != ans[j].Count {
+			return ans[i].Count > ans[j].Count
+		}
+
+		return ans[i].Category < ans[j].Category
+	})
+
+	return ans
+}
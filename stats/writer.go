@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Wrap returns a writer that counts the entries before passing them to next
+// and writes the aggregates as JSON to path when the results end
+func (c *Collector) Wrap(next scrapemate.ResultWriter, path string) scrapemate.ResultWriter {
+	return &writer{collector: c, next: next, path: path}
+}
+
+type writer struct {
+	collector *Collector
+	next      scrapemate.ResultWriter
+	path      string
+}
+
+func (w *writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.next.Run(ctx, out)
+	}()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			w.collector.Add(data)
+		case []*gmaps.Entry:
+			for i := range data {
+				w.collector.Add(data[i])
+			}
+		}
+
+		select {
+		case out <- result:
+		case err := <-done:
+			// keep consuming so that the producer does not block
+			go func() {
+				for range in {
+				}
+			}()
+
+			return err
+		}
+	}
+
+	close(out)
+
+	err := <-done
+
+	if werr := w.collector.WriteFile(w.path); err == nil {
+		err = werr
+	}
+
+	return err
+}
+
+// WriteFile writes the aggregates as indented JSON
+func (c *Collector) WriteFile(path string) error {
+	data, err := json.MarshalIndent(c.Aggregates(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,4 @@
+package taxonomy
+
+// Fold exposes fold to the tests
+var Fold = fold
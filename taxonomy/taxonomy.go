@@ -0,0 +1,206 @@
+// Package taxonomy maps the categories of the places, free-form and in the
+// language of the search, to the canonical categories of a stable taxonomy,
+// so that the places of different countries can be aggregated by their type.
+//
+// The built-in mapping is default.yaml. A mapping file has the same format: a
+// canonical category maps the names of its categories, compared in lower case
+// without accents, or /regular expressions/ matched against them.
+//
+//	restaurant:
+//	  - Restaurant
+//	  - Restaurante
+//	  - /ristorante|trattoria/
+package taxonomy
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gosom/scrapemate"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+//go:embed default.yaml
+var defaultMapping []byte
+
+// canonicalRe matches the canonical categories, e.g. gas_station
+var canonicalRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// pattern is a /regular expression/ of a canonical category
+type pattern struct {
+	re        *regexp.Regexp
+	canonical string
+}
+
+// Taxonomy maps the categories to their canonical category. The names are
+// looked up first, then the patterns in the order of the mapping, then the
+// taxonomy it extends.
+type Taxonomy struct {
+	names    map[string]string
+	patterns []pattern
+	base     *Taxonomy
+}
+
+var defaultTaxonomy = sync.OnceValue(func() *Taxonomy {
+	t, err := parse(defaultMapping, "default.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+})
+
+// Default returns the built-in taxonomy
+func Default() *Taxonomy {
+	return defaultTaxonomy()
+}
+
+// Load returns the built-in taxonomy extended with the mapping file path.
+// Its names and its patterns are matched before the built-in ones.
+func Load(path string) (*Taxonomy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the category mapping: %w", err)
+	}
+
+	t, err := parse(b, path)
+	if err != nil {
+		return nil, err
+	}
+
+	t.base = Default()
+
+	return t, nil
+}
+
+func parse(b []byte, path string) (*Taxonomy, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid category mapping %s: %w", path, err)
+	}
+
+	t := Taxonomy{names: make(map[string]string)}
+
+	// an empty file has no document
+	if len(doc.Content) == 0 {
+		return &t, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid category mapping %s: expected the canonical categories and their names", path)
+	}
+
+	// the pairs are read in order, the patterns are matched in it
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		canonical := root.Content[i].Value
+		if !canonicalRe.MatchString(canonical) {
+			return nil, fmt.Errorf("invalid category mapping %s: line %d: invalid canonical category %q",
+				path, root.Content[i].Line, canonical)
+		}
+
+		var names []string
+
+		if err := root.Content[i+1].Decode(&names); err != nil {
+			return nil, fmt.Errorf("invalid category mapping %s: %s: %w", path, canonical, err)
+		}
+
+		for _, name := range names {
+			if len(name) > 2 && strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/") {
+				re, err := regexp.Compile("(?i)" + name[1:len(name)-1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid category mapping %s: %s: %w", path, canonical, err)
+				}
+
+				t.patterns = append(t.patterns, pattern{re: re, canonical: canonical})
+
+				continue
+			}
+
+			if key := fold(name); key != "" {
+				t.names[key] = canonical
+			}
+		}
+	}
+
+	return &t, nil
+}
+
+// fold returns the category in lower case without accents, its words
+// separated by single spaces. The dotless i of Turkish is an i.
+func fold(category string) string {
+	category = strings.ToLower(category)
+
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	s, _, err := transform.String(t, category)
+	if err != nil {
+		s = category
+	}
+
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "ı", "i")), " ")
+}
+
+// Canonical returns the canonical category of category, empty when it is not
+// mapped
+func (t *Taxonomy) Canonical(category string) string {
+	key := fold(category)
+	if key == "" {
+		return ""
+	}
+
+	if canonical, ok := t.names[key]; ok {
+		return canonical
+	}
+
+	for _, p := range t.patterns {
+		if p.re.MatchString(key) {
+			return p.canonical
+		}
+	}
+
+	if t.base != nil {
+		return t.base.Canonical(category)
+	}
+
+	return ""
+}
+
+// Normalize sets the canonical categories of the entry: CanonicalCategories
+// are the canonical categories of its categories, without duplicates, and
+// CanonicalCategory the one of its main category, or the first of them when
+// the main category is not mapped. It can be used as a gmaps.AfterParseFunc.
+func (t *Taxonomy) Normalize(_ context.Context, _ scrapemate.IJob, entry *gmaps.Entry) error {
+	entry.CanonicalCategory = t.Canonical(entry.Category)
+	entry.CanonicalCategories = nil
+
+	for _, category := range entry.Categories {
+		canonical := t.Canonical(category)
+		if canonical != "" && !slices.Contains(entry.CanonicalCategories, canonical) {
+			entry.CanonicalCategories = append(entry.CanonicalCategories, canonical)
+		}
+	}
+
+	if entry.CanonicalCategory == "" && len(entry.CanonicalCategories) > 0 {
+		entry.CanonicalCategory = entry.CanonicalCategories[0]
+	}
+
+	if entry.CanonicalCategory != "" && !slices.Contains(entry.CanonicalCategories, entry.CanonicalCategory) {
+		entry.CanonicalCategories = append([]string{entry.CanonicalCategory}, entry.CanonicalCategories...)
+	}
+
+	return nil
+}
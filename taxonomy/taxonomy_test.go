@@ -0,0 +1,168 @@
+package taxonomy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/taxonomy"
+)
+
+func Test_Fold(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{category: "Restaurant", want: "restaurant"},
+		{category: "  Coffee   shop ", want: "coffee shop"},
+		{category: "Cafetería", want: "cafeteria"},
+		{category: "Salon de thé", want: "salon de the"},
+		{category: "Εστιατόριο", want: "εστιατοριο"},
+		{category: "Fast food restoranı", want: "fast food restorani"},
+		{category: "FIRIN", want: "firin"},
+		{category: "", want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.category, func(t *testing.T) {
+			require.Equal(t, tc.want, taxonomy.Fold(tc.category))
+		})
+	}
+}
+
+func Test_Canonical(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{category: "Restaurant", want: "restaurant"},
+		{category: "RESTAURANTE", want: "restaurant"},
+		{category: "Εστιατόριο", want: "restaurant"},
+		{category: "Café (bar)", want: "bar"},
+		{category: "Fast food restaurant", want: "fast_food"},
+		// the names are looked up before the patterns
+		{category: "Koffiebar", want: "cafe"},
+		// the patterns are matched in the order of the mapping
+		{category: "Specialty coffee roaster", want: "cafe"},
+		{category: "Irish brewpub", want: "bar"},
+		{category: "Unknown category", want: ""},
+		{category: " ", want: ""},
+	}
+
+	tx := taxonomy.Default()
+
+	for _, tc := range tests {
+		t.Run(tc.category, func(t *testing.T) {
+			require.Equal(t, tc.want, tx.Canonical(tc.category))
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	mapping := `
+pediatrician:
+  - Kinderarzt
+  - /pediatr/
+restaurant:
+  - Sushi bar
+wine_bar:
+  - /wine/
+`
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		// the names of the file replace the built-in patterns
+		{category: "Sushi bar", want: "restaurant"},
+		{category: "Kinderarzt", want: "pediatrician"},
+		// the patterns of the file are matched before the built-in ones
+		{category: "Pediatric doctor", want: "pediatrician"},
+		// and before the built-in names
+		{category: "Wine bar", want: "wine_bar"},
+		// the built-in taxonomy is kept
+		{category: "Doctor", want: "doctor"},
+		{category: "Cocktail bar", want: "bar"},
+		{category: "Cafetería", want: "cafe"},
+	}
+
+	path := filepath.Join(t.TempDir(), "categories.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(mapping), 0o600))
+
+	tx, err := taxonomy.Load(path)
+	require.NoError(t, err)
+
+	for _, tc := range tests {
+		t.Run(tc.category, func(t *testing.T) {
+			require.Equal(t, tc.want, tx.Canonical(tc.category))
+		})
+	}
+}
+
+func Test_LoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+	}{
+		{name: "not a mapping", mapping: "- restaurant\n"},
+		{name: "invalid canonical category", mapping: "Fast Food:\n  - Burger\n"},
+		{name: "invalid pattern", mapping: "bar:\n  - /(pub/\n"},
+		{name: "names not a list", mapping: "bar: pub\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "categories.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.mapping), 0o600))
+
+			_, err := taxonomy.Load(path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_Normalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      gmaps.Entry
+		category   string
+		categories []string
+	}{
+		{
+			name:       "main category mapped",
+			entry:      gmaps.Entry{Category: "Pizzeria", Categories: []string{"Pizzeria", "Bar", "Ristorante"}},
+			category:   "restaurant",
+			categories: []string{"restaurant", "bar"},
+		},
+		{
+			name:       "main category not mapped",
+			entry:      gmaps.Entry{Category: "Unknown", Categories: []string{"Unknown", "Wine bar"}},
+			category:   "bar",
+			categories: []string{"bar"},
+		},
+		{
+			name:       "main category not in the categories",
+			entry:      gmaps.Entry{Category: "Bakery", Categories: []string{"Cafe"}},
+			category:   "bakery",
+			categories: []string{"bakery", "cafe"},
+		},
+		{
+			name:  "nothing mapped",
+			entry: gmaps.Entry{Category: "Unknown"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := tc.entry
+
+			require.NoError(t, taxonomy.Default().Normalize(context.Background(), nil, &entry))
+			require.Equal(t, tc.category, entry.CanonicalCategory)
+			require.Equal(t, tc.categories, entry.CanonicalCategories)
+		})
+	}
+}
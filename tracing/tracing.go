@@ -0,0 +1,199 @@
+// Package tracing exports OpenTelemetry spans of the jobs to an OTLP collector.
+//
+// The exporter is configured with the standard OTEL_* environment variables and
+// tracing is enabled when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. Every seed job starts a trace and
+// the jobs it creates carry its context, so a trace has the fetches, the
+// parsing and the writes of a search and of its places.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName is the service of the spans when OTEL_SERVICE_NAME is not set
+	ServiceName = "google-maps-scraper"
+
+	instrumentation = "github.com/gosom/google-maps-scraper"
+)
+
+var enabled bool
+
+// Setup installs the OTLP exporter when it is configured. The exporter uses
+// OTLP over HTTP, or gRPC when OTEL_EXPORTER_OTLP_PROTOCOL is grpc. The
+// returned function flushes the pending spans, it must be called before exiting.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return noop, nil
+	}
+
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch strings.ToLower(protocol) {
+	case "grpc":
+		exporter, err = otlptracegrpc.New(ctx)
+	case "", "http/protobuf":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return noop, fmt.Errorf("unsupported OTLP protocol %q: use grpc or http/protobuf", protocol)
+	}
+
+	if err != nil {
+		return noop, fmt.Errorf("cannot create the OTLP exporter: %w", err)
+	}
+
+	// the attributes of OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME win
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName(ServiceName)),
+		resource.Environment(),
+	)
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	enabled = true
+
+	return provider.Shutdown, nil
+}
+
+// Enabled reports whether the spans are exported
+func Enabled() bool {
+	return enabled
+}
+
+// Tracer returns the tracer of the spans of the scraper
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Traced is a job carrying the context of the span it was created in
+type Traced interface {
+	TraceContext(ctx context.Context) context.Context
+	SetTraceContext(ctx context.Context)
+}
+
+// Carrier is embedded in the jobs to implement Traced. The context is kept as a
+// W3C traceparent, so it survives the encoding of the jobs in the database.
+type Carrier struct {
+	Traceparent string
+}
+
+// TraceContext returns ctx with the span the job was created in as the parent
+func (c *Carrier) TraceContext(ctx context.Context) context.Context {
+	if c.Traceparent == "" {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": c.Traceparent})
+}
+
+// SetTraceContext records the span of ctx as the parent of the spans of the job
+func (c *Carrier) SetTraceContext(ctx context.Context) {
+	carrier := propagation.MapCarrier{}
+
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	c.Traceparent = carrier.Get("traceparent")
+}
+
+// jobContext returns ctx with the parent span of the job. A job without one is
+// a seed: a seed span is recorded to be the root of the trace of the job and
+// of the jobs it creates.
+func jobContext(ctx context.Context, job scrapemate.IJob) context.Context {
+	traced, ok := job.(Traced)
+	if !ok {
+		return ctx
+	}
+
+	parent := traced.TraceContext(ctx)
+	if trace.SpanContextFromContext(parent).IsValid() {
+		return parent
+	}
+
+	// the seed does not belong to the span of the caller
+	root, span := Tracer().Start(ctx, "gmaps.seed",
+		trace.WithNewRoot(),
+		trace.WithAttributes(jobAttributes(job)...),
+	)
+	span.End()
+
+	traced.SetTraceContext(root)
+
+	return root
+}
+
+func jobAttributes(job scrapemate.IJob) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("job.id", job.GetID()),
+		attribute.String("job.type", fmt.Sprintf("%T", job)),
+		attribute.String("url.full", job.GetFullURL()),
+	}
+}
+
+// StartJob starts a span of a job, a child of the span the job was created in
+func StartJob(ctx context.Context, job scrapemate.IJob, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !enabled {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	attrs = append(jobAttributes(job), attrs...)
+
+	return Tracer().Start(jobContext(ctx, job), name, trace.WithAttributes(attrs...))
+}
+
+// Propagate makes the span of ctx the parent of the spans of the jobs
+func Propagate(ctx context.Context, jobs []scrapemate.IJob) {
+	if !enabled {
+		return
+	}
+
+	for _, job := range jobs {
+		if traced, ok := job.(Traced); ok {
+			traced.SetTraceContext(ctx)
+		}
+	}
+}
+
+// End ends a span with the status of err
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
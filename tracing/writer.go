@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gosom/scrapemate"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapWriter returns a writer that records a span for every result written by
+// next. The span of a result starts when the result is produced and ends when
+// next is ready for the following one, so it includes the time the result
+// waits for the writer. It returns next when tracing is not enabled.
+func WrapWriter(next scrapemate.ResultWriter) scrapemate.ResultWriter {
+	if !enabled {
+		return next
+	}
+
+	return &writer{next: next, name: fmt.Sprintf("%T", next)}
+}
+
+type writer struct {
+	next scrapemate.ResultWriter
+	name string
+}
+
+func (w *writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.next.Run(ctx, out)
+	}()
+
+	// the span of the result the writer is busy with
+	var current trace.Span
+
+	for result := range in {
+		span := w.start(ctx, result)
+
+		select {
+		case out <- result:
+			if current != nil {
+				current.End()
+			}
+
+			current = span
+		case err := <-done:
+			End(span, err)
+
+			if current != nil {
+				End(current, err)
+			}
+
+			// keep consuming so that the producer does not block
+			go func() {
+				for range in {
+				}
+			}()
+
+			return err
+		}
+	}
+
+	close(out)
+
+	err := <-done
+
+	if current != nil {
+		End(current, err)
+	}
+
+	return err
+}
+
+func (w *writer) start(ctx context.Context, result scrapemate.Result) trace.Span {
+	attrs := []attribute.KeyValue{attribute.String("gmaps.writer", w.name)}
+
+	if result.Job == nil {
+		_, span := Tracer().Start(ctx, "gmaps.write", trace.WithAttributes(attrs...))
+
+		return span
+	}
+
+	_, span := StartJob(ctx, result.Job, "gmaps.write", attrs...)
+
+	return span
+}
@@ -0,0 +1,184 @@
+// Package useragent rotates the user agents of the requests of the fast mode.
+// A profile is a real desktop or mobile browser: its user agent, the client
+// hints matching it and the TLS fingerprint of the browser, so that the
+// requests of a profile look like the requests of the same browser.
+package useragent
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// The rotations of the profiles
+const (
+	// RotateJob picks a profile for every job, the retries of a job keep it
+	RotateJob = "job"
+	// RotateSession picks a profile for every proxy or session of a rotating
+	// proxy provider, like the cookies of the proxy
+	RotateSession = "session"
+)
+
+// The kinds of the profiles
+const (
+	KindDesktop = "desktop"
+	KindMobile  = "mobile"
+	KindAll     = "all"
+)
+
+// The TLS fingerprints of the profiles, the presets of azuretls
+const (
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+)
+
+// The versions of the browsers of the profiles
+const (
+	chromeVersion  = "131"
+	firefoxVersion = "133.0"
+)
+
+// Profile is a browser the requests are sent as
+type Profile struct {
+	Name string
+	// Browser is the TLS fingerprint of the requests, BrowserChrome or
+	// BrowserFirefox
+	Browser string
+	Mobile  bool
+	// Headers are the headers of the browser in the order it sends them
+	Headers [][]string
+}
+
+// Profiles returns the profiles of kind, KindDesktop, KindMobile or KindAll
+func Profiles(kind string) ([]Profile, error) {
+	all := []Profile{
+		chrome("chrome-windows", "Windows NT 10.0; Win64; x64", "Windows", false, ""),
+		chrome("chrome-macos", "Macintosh; Intel Mac OS X 10_15_7", "macOS", false, ""),
+		chrome("chrome-linux", "X11; Linux x86_64", "Linux", false, ""),
+		chrome("edge-windows", "Windows NT 10.0; Win64; x64", "Windows", false, "Microsoft Edge"),
+		firefox("firefox-windows", "Windows NT 10.0; Win64; x64; rv:"+firefoxVersion, "Gecko/20100101", false),
+		firefox("firefox-macos", "Macintosh; Intel Mac OS X 10.15; rv:"+firefoxVersion, "Gecko/20100101", false),
+		firefox("firefox-linux", "X11; Linux x86_64; rv:"+firefoxVersion, "Gecko/20100101", false),
+		chrome("chrome-android", "Linux; Android 10; K", "Android", true, ""),
+		firefox("firefox-android", "Android 14; Mobile; rv:"+firefoxVersion, "Gecko/"+firefoxVersion, true),
+	}
+
+	var ans []Profile
+
+	for i := range all {
+		switch {
+		case kind == KindAll,
+			kind == KindDesktop && !all[i].Mobile,
+			kind == KindMobile && all[i].Mobile:
+			ans = append(ans, all[i])
+		}
+	}
+
+	if len(ans) == 0 {
+		return nil, fmt.Errorf("invalid user agent profiles %q: use %s, %s or %s", kind, KindDesktop, KindMobile, KindAll)
+	}
+
+	return ans, nil
+}
+
+// Rotator picks the profile of the requests
+type Rotator struct {
+	rotation string
+	profiles []Profile
+}
+
+// New returns a rotator picking the profiles of kind for every job or every
+// session, see RotateJob and RotateSession
+func New(rotation, kind string) (*Rotator, error) {
+	if rotation != RotateJob && rotation != RotateSession {
+		return nil, fmt.Errorf("invalid user agent rotation %q: use %s or %s", rotation, RotateJob, RotateSession)
+	}
+
+	profiles, err := Profiles(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rotator{rotation: rotation, profiles: profiles}, nil
+}
+
+// Pick returns the profile of the request of the job jobID sent through the
+// session, the key of the proxy. The same job or session always gets the same
+// profile.
+func (r *Rotator) Pick(jobID, session string) *Profile {
+	key := jobID
+	if r.rotation == RotateSession {
+		key = session
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	idx := binary.BigEndian.Uint64(sum[:8]) % uint64(len(r.profiles))
+
+	return &r.profiles[idx]
+}
+
+// chrome returns the profile of Chrome on platform, brand replaces Google
+// Chrome for the browsers based on Chromium
+func chrome(name, platform, hintPlatform string, mobile bool, brand string) Profile {
+	ua := "Mozilla/5.0 (" + platform + ") AppleWebKit/537.36 (KHTML, like Gecko) Chrome/" + chromeVersion + ".0.0.0 "
+
+	mobileHint := "?0"
+	if mobile {
+		ua += "Mobile "
+		mobileHint = "?1"
+	}
+
+	ua += "Safari/537.36"
+
+	if brand == "" {
+		brand = "Google Chrome"
+	} else {
+		// Edge adds its version to the user agent of Chrome
+		ua += " Edg/" + chromeVersion + ".0.0.0"
+	}
+
+	hints := fmt.Sprintf(`"%s";v="%s", "Chromium";v="%s", "Not_A Brand";v="24"`, brand, chromeVersion, chromeVersion)
+
+	return Profile{
+		Name:    name,
+		Browser: BrowserChrome,
+		Mobile:  mobile,
+		Headers: [][]string{
+			{"sec-ch-ua", hints},
+			{"sec-ch-ua-mobile", mobileHint},
+			{"sec-ch-ua-platform", `"` + hintPlatform + `"`},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"User-Agent", ua},
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-User", "?1"},
+			{"Sec-Fetch-Dest", "document"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Accept-Language", "en-US,en;q=0.9"},
+		},
+	}
+}
+
+// firefox returns the profile of Firefox on platform, Firefox sends no client
+// hints
+func firefox(name, platform, gecko string, mobile bool) Profile {
+	ua := "Mozilla/5.0 (" + platform + ") " + gecko + " Firefox/" + firefoxVersion
+
+	return Profile{
+		Name:    name,
+		Browser: BrowserFirefox,
+		Mobile:  mobile,
+		Headers: [][]string{
+			{"User-Agent", ua},
+			{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+			{"Accept-Language", "en-US,en;q=0.5"},
+			{"Accept-Encoding", "gzip, deflate, br"},
+			{"Upgrade-Insecure-Requests", "1"},
+			{"Sec-Fetch-Dest", "document"},
+			{"Sec-Fetch-Mode", "navigate"},
+			{"Sec-Fetch-Site", "none"},
+			{"Sec-Fetch-User", "?1"},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+)
+
+// dashboardData are the jobs of the dashboard, the running ones first
+type dashboardData struct {
+	Jobs []apiJob
+
+	Working       int
+	Pending       int
+	OK            int
+	Failed        int
+	ParseWarnings int
+}
+
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/dashboard.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	_ = tmpl.Execute(w, nil)
+}
+
+func (s *Server) dashboardJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	tmpl, ok := s.tmpl["static/templates/dashboard_jobs.html"]
+	if !ok {
+		http.Error(w, "missing tpl", http.StatusInternalServerError)
+
+		return
+	}
+
+	jobs, err := s.svc.All(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	var data dashboardData
+
+	for i := range jobs {
+		job := s.withProgress(jobs[i])
+
+		switch job.Status {
+		case StatusWorking:
+			data.Working++
+		case StatusPending:
+			data.Pending++
+		case StatusOK:
+			data.OK++
+		case StatusFailed:
+			data.Failed++
+		}
+
+		if job.Progress != nil {
+			data.ParseWarnings += job.Progress.ParseWarnings
+		}
+
+		data.Jobs = append(data.Jobs, job)
+	}
+
+	// the jobs are the newest first, the running and the pending ones go on top
+	rank := map[string]int{StatusWorking: 0, StatusPending: 1}
+
+	sort.SliceStable(data.Jobs, func(i, j int) bool {
+		ri, ok := rank[data.Jobs[i].Status]
+		if !ok {
+			ri = len(rank)
+		}
+
+		rj, ok := rank[data.Jobs[j].Status]
+		if !ok {
+			rj = len(rank)
+		}
+
+		return ri < rj
+	})
+
+	_ = tmpl.Execute(w, data)
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+// eventsInterval is the time between two progress events
+const eventsInterval = 2 * time.Second
+
+// progressEvent is the data of a progress event of a job
+type progressEvent struct {
+	Status string `json:"status"`
+	exiter.Progress
+}
+
+// apiJobEvents streams the progress of a job as Server-Sent Events, a
+// progress event every 2 seconds and a done event when the job ends
+func (s *Server) apiJobEvents(w http.ResponseWriter, r *http.Request) {
+	id, ok := getIDFromRequest(r)
+	if !ok {
+		renderJSON(w, http.StatusUnprocessableEntity, apiError{Code: http.StatusUnprocessableEntity, Message: "Invalid ID"})
+
+		return
+	}
+
+	ctx := r.Context()
+
+	if _, err := s.svc.Get(ctx, id.String()); err != nil {
+		renderJSON(w, http.StatusNotFound, apiError{Code: http.StatusNotFound, Message: http.StatusText(http.StatusNotFound)})
+
+		return
+	}
+
+	// the stream outlives the write timeout of the server
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		renderJSON(w, http.StatusInternalServerError, apiError{Code: http.StatusInternalServerError, Message: err.Error()})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(eventsInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.svc.Get(ctx, id.String())
+		if err != nil {
+			// the job was deleted
+			_ = writeEvent(w, rc, "done", map[string]string{"status": "deleted"})
+
+			return
+		}
+
+		evt := progressEvent{Status: job.Status}
+
+		if p, ok := s.svc.Progress(job.ID); ok {
+			evt.Progress = p
+		}
+
+		name := "progress"
+		if job.Status == StatusOK || job.Status == StatusFailed {
+			name = "done"
+		}
+
+		if err := writeEvent(w, rc, name, evt); err != nil || name == "done" {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, name string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, b); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
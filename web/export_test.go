@@ -0,0 +1,4 @@
+package web
+
+// MaxFeedEntries is how many entries the feed of a running job keeps
+const MaxFeedEntries = maxFeedEntries
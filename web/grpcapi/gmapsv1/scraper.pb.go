@@ -0,0 +1,647 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: web/grpcapi/gmapsv1/scraper.proto
+
+package gmapsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SubmitSearchRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Keywords []string               `protobuf:"bytes,2,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	// lang is the two letter language code of Google, e.g. en
+	Lang string `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	Zoom int32  `protobuf:"varint,4,opt,name=zoom,proto3" json:"zoom,omitempty"`
+	// lat and lon are the center of the search, required by the fast mode
+	Lat      string `protobuf:"bytes,5,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon      string `protobuf:"bytes,6,opt,name=lon,proto3" json:"lon,omitempty"`
+	FastMode bool   `protobuf:"varint,7,opt,name=fast_mode,json=fastMode,proto3" json:"fast_mode,omitempty"`
+	// radius is in meters
+	Radius         int32    `protobuf:"varint,8,opt,name=radius,proto3" json:"radius,omitempty"`
+	Depth          int32    `protobuf:"varint,9,opt,name=depth,proto3" json:"depth,omitempty"`
+	Email          bool     `protobuf:"varint,10,opt,name=email,proto3" json:"email,omitempty"`
+	MaxTimeSeconds int64    `protobuf:"varint,11,opt,name=max_time_seconds,json=maxTimeSeconds,proto3" json:"max_time_seconds,omitempty"`
+	Proxies        []string `protobuf:"bytes,12,rep,name=proxies,proto3" json:"proxies,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubmitSearchRequest) Reset() {
+	*x = SubmitSearchRequest{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitSearchRequest) ProtoMessage() {}
+
+func (x *SubmitSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitSearchRequest.ProtoReflect.Descriptor instead.
+func (*SubmitSearchRequest) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubmitSearchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubmitSearchRequest) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+func (x *SubmitSearchRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *SubmitSearchRequest) GetZoom() int32 {
+	if x != nil {
+		return x.Zoom
+	}
+	return 0
+}
+
+func (x *SubmitSearchRequest) GetLat() string {
+	if x != nil {
+		return x.Lat
+	}
+	return ""
+}
+
+func (x *SubmitSearchRequest) GetLon() string {
+	if x != nil {
+		return x.Lon
+	}
+	return ""
+}
+
+func (x *SubmitSearchRequest) GetFastMode() bool {
+	if x != nil {
+		return x.FastMode
+	}
+	return false
+}
+
+func (x *SubmitSearchRequest) GetRadius() int32 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *SubmitSearchRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *SubmitSearchRequest) GetEmail() bool {
+	if x != nil {
+		return x.Email
+	}
+	return false
+}
+
+func (x *SubmitSearchRequest) GetMaxTimeSeconds() int64 {
+	if x != nil {
+		return x.MaxTimeSeconds
+	}
+	return 0
+}
+
+func (x *SubmitSearchRequest) GetProxies() []string {
+	if x != nil {
+		return x.Proxies
+	}
+	return nil
+}
+
+type SubmitSearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitSearchResponse) Reset() {
+	*x = SubmitSearchResponse{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitSearchResponse) ProtoMessage() {}
+
+func (x *SubmitSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitSearchResponse.ProtoReflect.Descriptor instead.
+func (*SubmitSearchResponse) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubmitSearchResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultsRequest) Reset() {
+	*x = ResultsRequest{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsRequest) ProtoMessage() {}
+
+func (x *ResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultsRequest.ProtoReflect.Descriptor instead.
+func (*ResultsRequest) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResultsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Entry is a place with its main fields, json has all of them.
+type Entry struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	InputId      string                 `protobuf:"bytes,1,opt,name=input_id,json=inputId,proto3" json:"input_id,omitempty"`
+	Cid          string                 `protobuf:"bytes,2,opt,name=cid,proto3" json:"cid,omitempty"`
+	DataId       string                 `protobuf:"bytes,3,opt,name=data_id,json=dataId,proto3" json:"data_id,omitempty"`
+	Link         string                 `protobuf:"bytes,4,opt,name=link,proto3" json:"link,omitempty"`
+	Title        string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Category     string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	Address      string                 `protobuf:"bytes,7,opt,name=address,proto3" json:"address,omitempty"`
+	Website      string                 `protobuf:"bytes,8,opt,name=website,proto3" json:"website,omitempty"`
+	Phone        string                 `protobuf:"bytes,9,opt,name=phone,proto3" json:"phone,omitempty"`
+	ReviewCount  int32                  `protobuf:"varint,10,opt,name=review_count,json=reviewCount,proto3" json:"review_count,omitempty"`
+	ReviewRating float64                `protobuf:"fixed64,11,opt,name=review_rating,json=reviewRating,proto3" json:"review_rating,omitempty"`
+	Latitude     float64                `protobuf:"fixed64,12,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude    float64                `protobuf:"fixed64,13,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Json         []byte                 `protobuf:"bytes,14,opt,name=json,proto3" json:"json,omitempty"`
+	// distance_m is the distance in meters from the center of the search
+	DistanceM     float64 `protobuf:"fixed64,15,opt,name=distance_m,json=distanceM,proto3" json:"distance_m,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Entry) GetInputId() string {
+	if x != nil {
+		return x.InputId
+	}
+	return ""
+}
+
+func (x *Entry) GetCid() string {
+	if x != nil {
+		return x.Cid
+	}
+	return ""
+}
+
+func (x *Entry) GetDataId() string {
+	if x != nil {
+		return x.DataId
+	}
+	return ""
+}
+
+func (x *Entry) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *Entry) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Entry) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Entry) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Entry) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *Entry) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Entry) GetReviewCount() int32 {
+	if x != nil {
+		return x.ReviewCount
+	}
+	return 0
+}
+
+func (x *Entry) GetReviewRating() float64 {
+	if x != nil {
+		return x.ReviewRating
+	}
+	return 0
+}
+
+func (x *Entry) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Entry) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Entry) GetJson() []byte {
+	if x != nil {
+		return x.Json
+	}
+	return nil
+}
+
+func (x *Entry) GetDistanceM() float64 {
+	if x != nil {
+		return x.DistanceM
+	}
+	return 0
+}
+
+type ProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgressRequest) Reset() {
+	*x = ProgressRequest{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressRequest) ProtoMessage() {}
+
+func (x *ProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressRequest.ProtoReflect.Descriptor instead.
+func (*ProgressRequest) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProgressRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ProgressResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// status is pending, working, ok or failed
+	Status          string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	SeedCount       int64  `protobuf:"varint,2,opt,name=seed_count,json=seedCount,proto3" json:"seed_count,omitempty"`
+	SeedCompleted   int64  `protobuf:"varint,3,opt,name=seed_completed,json=seedCompleted,proto3" json:"seed_completed,omitempty"`
+	PlacesFound     int64  `protobuf:"varint,4,opt,name=places_found,json=placesFound,proto3" json:"places_found,omitempty"`
+	PlacesCompleted int64  `protobuf:"varint,5,opt,name=places_completed,json=placesCompleted,proto3" json:"places_completed,omitempty"`
+	ParseWarnings   int64  `protobuf:"varint,6,opt,name=parse_warnings,json=parseWarnings,proto3" json:"parse_warnings,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ProgressResponse) Reset() {
+	*x = ProgressResponse{}
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressResponse) ProtoMessage() {}
+
+func (x *ProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_web_grpcapi_gmapsv1_scraper_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressResponse.ProtoReflect.Descriptor instead.
+func (*ProgressResponse) Descriptor() ([]byte, []int) {
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProgressResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProgressResponse) GetSeedCount() int64 {
+	if x != nil {
+		return x.SeedCount
+	}
+	return 0
+}
+
+func (x *ProgressResponse) GetSeedCompleted() int64 {
+	if x != nil {
+		return x.SeedCompleted
+	}
+	return 0
+}
+
+func (x *ProgressResponse) GetPlacesFound() int64 {
+	if x != nil {
+		return x.PlacesFound
+	}
+	return 0
+}
+
+func (x *ProgressResponse) GetPlacesCompleted() int64 {
+	if x != nil {
+		return x.PlacesCompleted
+	}
+	return 0
+}
+
+func (x *ProgressResponse) GetParseWarnings() int64 {
+	if x != nil {
+		return x.ParseWarnings
+	}
+	return 0
+}
+
+var File_web_grpcapi_gmapsv1_scraper_proto protoreflect.FileDescriptor
+
+const file_web_grpcapi_gmapsv1_scraper_proto_rawDesc = "" +
+	"\n" +
+	"!web/grpcapi/gmapsv1/scraper.proto\x12\bgmaps.v1\"\xb6\x02\n" +
+	"\x13SubmitSearchRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bkeywords\x18\x02 \x03(\tR\bkeywords\x12\x12\n" +
+	"\x04lang\x18\x03 \x01(\tR\x04lang\x12\x12\n" +
+	"\x04zoom\x18\x04 \x01(\x05R\x04zoom\x12\x10\n" +
+	"\x03lat\x18\x05 \x01(\tR\x03lat\x12\x10\n" +
+	"\x03lon\x18\x06 \x01(\tR\x03lon\x12\x1b\n" +
+	"\tfast_mode\x18\a \x01(\bR\bfastMode\x12\x16\n" +
+	"\x06radius\x18\b \x01(\x05R\x06radius\x12\x14\n" +
+	"\x05depth\x18\t \x01(\x05R\x05depth\x12\x14\n" +
+	"\x05email\x18\n" +
+	" \x01(\bR\x05email\x12(\n" +
+	"\x10max_time_seconds\x18\v \x01(\x03R\x0emaxTimeSeconds\x12\x18\n" +
+	"\aproxies\x18\f \x03(\tR\aproxies\"&\n" +
+	"\x14SubmitSearchResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\" \n" +
+	"\x0eResultsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x92\x03\n" +
+	"\x05Entry\x12\x19\n" +
+	"\binput_id\x18\x01 \x01(\tR\ainputId\x12\x10\n" +
+	"\x03cid\x18\x02 \x01(\tR\x03cid\x12\x17\n" +
+	"\adata_id\x18\x03 \x01(\tR\x06dataId\x12\x12\n" +
+	"\x04link\x18\x04 \x01(\tR\x04link\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12\x18\n" +
+	"\aaddress\x18\a \x01(\tR\aaddress\x12\x18\n" +
+	"\awebsite\x18\b \x01(\tR\awebsite\x12\x14\n" +
+	"\x05phone\x18\t \x01(\tR\x05phone\x12!\n" +
+	"\freview_count\x18\n" +
+	" \x01(\x05R\vreviewCount\x12#\n" +
+	"\rreview_rating\x18\v \x01(\x01R\freviewRating\x12\x1a\n" +
+	"\blatitude\x18\f \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\r \x01(\x01R\tlongitude\x12\x12\n" +
+	"\x04json\x18\x0e \x01(\fR\x04json\x12\x1d\n" +
+	"\n" +
+	"distance_m\x18\x0f \x01(\x01R\tdistanceM\"!\n" +
+	"\x0fProgressRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xe5\x01\n" +
+	"\x10ProgressResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"seed_count\x18\x02 \x01(\x03R\tseedCount\x12%\n" +
+	"\x0eseed_completed\x18\x03 \x01(\x03R\rseedCompleted\x12!\n" +
+	"\fplaces_found\x18\x04 \x01(\x03R\vplacesFound\x12)\n" +
+	"\x10places_completed\x18\x05 \x01(\x03R\x0fplacesCompleted\x12%\n" +
+	"\x0eparse_warnings\x18\x06 \x01(\x03R\rparseWarnings2\xd3\x01\n" +
+	"\aScraper\x12M\n" +
+	"\fSubmitSearch\x12\x1d.gmaps.v1.SubmitSearchRequest\x1a\x1e.gmaps.v1.SubmitSearchResponse\x126\n" +
+	"\aResults\x12\x18.gmaps.v1.ResultsRequest\x1a\x0f.gmaps.v1.Entry0\x01\x12A\n" +
+	"\bProgress\x12\x19.gmaps.v1.ProgressRequest\x1a\x1a.gmaps.v1.ProgressResponseB:Z8github.com/gosom/google-maps-scraper/web/grpcapi/gmapsv1b\x06proto3"
+
+var (
+	file_web_grpcapi_gmapsv1_scraper_proto_rawDescOnce sync.Once
+	file_web_grpcapi_gmapsv1_scraper_proto_rawDescData []byte
+)
+
+func file_web_grpcapi_gmapsv1_scraper_proto_rawDescGZIP() []byte {
+	file_web_grpcapi_gmapsv1_scraper_proto_rawDescOnce.Do(func() {
+		file_web_grpcapi_gmapsv1_scraper_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_web_grpcapi_gmapsv1_scraper_proto_rawDesc), len(file_web_grpcapi_gmapsv1_scraper_proto_rawDesc)))
+	})
+	return file_web_grpcapi_gmapsv1_scraper_proto_rawDescData
+}
+
+var file_web_grpcapi_gmapsv1_scraper_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_web_grpcapi_gmapsv1_scraper_proto_goTypes = []any{
+	(*SubmitSearchRequest)(nil),  // 0: gmaps.v1.SubmitSearchRequest
+	(*SubmitSearchResponse)(nil), // 1: gmaps.v1.SubmitSearchResponse
+	(*ResultsRequest)(nil),       // 2: gmaps.v1.ResultsRequest
+	(*Entry)(nil),                // 3: gmaps.v1.Entry
+	(*ProgressRequest)(nil),      // 4: gmaps.v1.ProgressRequest
+	(*ProgressResponse)(nil),     // 5: gmaps.v1.ProgressResponse
+}
+var file_web_grpcapi_gmapsv1_scraper_proto_depIdxs = []int32{
+	0, // 0: gmaps.v1.Scraper.SubmitSearch:input_type -> gmaps.v1.SubmitSearchRequest
+	2, // 1: gmaps.v1.Scraper.Results:input_type -> gmaps.v1.ResultsRequest
+	4, // 2: gmaps.v1.Scraper.Progress:input_type -> gmaps.v1.ProgressRequest
+	1, // 3: gmaps.v1.Scraper.SubmitSearch:output_type -> gmaps.v1.SubmitSearchResponse
+	3, // 4: gmaps.v1.Scraper.Results:output_type -> gmaps.v1.Entry
+	5, // 5: gmaps.v1.Scraper.Progress:output_type -> gmaps.v1.ProgressResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_web_grpcapi_gmapsv1_scraper_proto_init() }
+func file_web_grpcapi_gmapsv1_scraper_proto_init() {
+	if File_web_grpcapi_gmapsv1_scraper_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_web_grpcapi_gmapsv1_scraper_proto_rawDesc), len(file_web_grpcapi_gmapsv1_scraper_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_web_grpcapi_gmapsv1_scraper_proto_goTypes,
+		DependencyIndexes: file_web_grpcapi_gmapsv1_scraper_proto_depIdxs,
+		MessageInfos:      file_web_grpcapi_gmapsv1_scraper_proto_msgTypes,
+	}.Build()
+	File_web_grpcapi_gmapsv1_scraper_proto = out.File
+	file_web_grpcapi_gmapsv1_scraper_proto_goTypes = nil
+	file_web_grpcapi_gmapsv1_scraper_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: web/grpcapi/gmapsv1/scraper.proto
+
+package gmapsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Scraper_SubmitSearch_FullMethodName = "/gmaps.v1.Scraper/SubmitSearch"
+	Scraper_Results_FullMethodName      = "/gmaps.v1.Scraper/Results"
+	Scraper_Progress_FullMethodName     = "/gmaps.v1.Scraper/Progress"
+)
+
+// ScraperClient is the client API for Scraper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Scraper runs the scraping jobs of the web mode.
+type ScraperClient interface {
+	// SubmitSearch creates a job, it runs when the jobs before it are done.
+	SubmitSearch(ctx context.Context, in *SubmitSearchRequest, opts ...grpc.CallOption) (*SubmitSearchResponse, error)
+	// Results streams the places of a running job: the last 1000 places parsed
+	// before the call and then the ones parsed after it. The stream ends when
+	// the job ends.
+	Results(ctx context.Context, in *ResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entry], error)
+	// Progress returns the status of a job and the counters of its run.
+	Progress(ctx context.Context, in *ProgressRequest, opts ...grpc.CallOption) (*ProgressResponse, error)
+}
+
+type scraperClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScraperClient(cc grpc.ClientConnInterface) ScraperClient {
+	return &scraperClient{cc}
+}
+
+func (c *scraperClient) SubmitSearch(ctx context.Context, in *SubmitSearchRequest, opts ...grpc.CallOption) (*SubmitSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitSearchResponse)
+	err := c.cc.Invoke(ctx, Scraper_SubmitSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scraperClient) Results(ctx context.Context, in *ResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Scraper_ServiceDesc.Streams[0], Scraper_Results_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ResultsRequest, Entry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Scraper_ResultsClient = grpc.ServerStreamingClient[Entry]
+
+func (c *scraperClient) Progress(ctx context.Context, in *ProgressRequest, opts ...grpc.CallOption) (*ProgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProgressResponse)
+	err := c.cc.Invoke(ctx, Scraper_Progress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScraperServer is the server API for Scraper service.
+// All implementations must embed UnimplementedScraperServer
+// for forward compatibility.
+//
+// Scraper runs the scraping jobs of the web mode.
+type ScraperServer interface {
+	// SubmitSearch creates a job, it runs when the jobs before it are done.
+	SubmitSearch(context.Context, *SubmitSearchRequest) (*SubmitSearchResponse, error)
+	// Results streams the places of a running job: the last 1000 places parsed
+	// before the call and then the ones parsed after it. The stream ends when
+	// the job ends.
+	Results(*ResultsRequest, grpc.ServerStreamingServer[Entry]) error
+	// Progress returns the status of a job and the counters of its run.
+	Progress(context.Context, *ProgressRequest) (*ProgressResponse, error)
+	mustEmbedUnimplementedScraperServer()
+}
+
+// UnimplementedScraperServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedScraperServer struct{}
+
+func (UnimplementedScraperServer) SubmitSearch(context.Context, *SubmitSearchRequest) (*SubmitSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitSearch not implemented")
+}
+func (UnimplementedScraperServer) Results(*ResultsRequest, grpc.ServerStreamingServer[Entry]) error {
+	return status.Errorf(codes.Unimplemented, "method Results not implemented")
+}
+func (UnimplementedScraperServer) Progress(context.Context, *ProgressRequest) (*ProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Progress not implemented")
+}
+func (UnimplementedScraperServer) mustEmbedUnimplementedScraperServer() {}
+func (UnimplementedScraperServer) testEmbeddedByValue()                 {}
+
+// UnsafeScraperServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScraperServer will
+// result in compilation errors.
+type UnsafeScraperServer interface {
+	mustEmbedUnimplementedScraperServer()
+}
+
+func RegisterScraperServer(s grpc.ServiceRegistrar, srv ScraperServer) {
+	// If the following call pancis, it indicates UnimplementedScraperServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Scraper_ServiceDesc, srv)
+}
+
+func _Scraper_SubmitSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScraperServer).SubmitSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scraper_SubmitSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScraperServer).SubmitSearch(ctx, req.(*SubmitSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scraper_Results_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScraperServer).Results(m, &grpc.GenericServerStream[ResultsRequest, Entry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Scraper_ResultsServer = grpc.ServerStreamingServer[Entry]
+
+func _Scraper_Progress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScraperServer).Progress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scraper_Progress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScraperServer).Progress(ctx, req.(*ProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Scraper_ServiceDesc is the grpc.ServiceDesc for Scraper service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Scraper_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gmaps.v1.Scraper",
+	HandlerType: (*ScraperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitSearch",
+			Handler:    _Scraper_SubmitSearch_Handler,
+		},
+		{
+			MethodName: "Progress",
+			Handler:    _Scraper_Progress_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Results",
+			Handler:       _Scraper_Results_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "web/grpcapi/gmapsv1/scraper.proto",
+}
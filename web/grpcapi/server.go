@@ -0,0 +1,194 @@
+// Package grpcapi serves the jobs of the web mode over gRPC, with the places
+// of a job streamed as they are parsed.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/web"
+	"github.com/gosom/google-maps-scraper/web/grpcapi/gmapsv1"
+)
+
+type Server struct {
+	gmapsv1.UnimplementedScraperServer
+
+	svc  *web.Service
+	addr string
+	srv  *grpc.Server
+}
+
+// New returns the server of the jobs of svc listening on addr, e.g. :9090
+func New(svc *web.Service, addr string) *Server {
+	ans := Server{
+		svc:  svc,
+		addr: addr,
+		srv:  grpc.NewServer(),
+	}
+
+	gmapsv1.RegisterScraperServer(ans.srv, &ans)
+
+	return &ans
+}
+
+// Start serves until ctx is done, the running calls are finished first
+func (s *Server) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", s.addr, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		s.srv.GracefulStop()
+
+		slog.Info("grpc server stopped")
+	}()
+
+	fmt.Fprintf(os.Stderr, "grpc server listening on %s\n", s.addr)
+
+	if err := s.srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) SubmitSearch(ctx context.Context, req *gmapsv1.SubmitSearchRequest) (*gmapsv1.SubmitSearchResponse, error) {
+	job := web.Job{
+		ID:     uuid.New().String(),
+		Name:   req.GetName(),
+		Date:   time.Now().UTC(),
+		Status: web.StatusPending,
+		Data: web.JobData{
+			Keywords: req.GetKeywords(),
+			Lang:     req.GetLang(),
+			Zoom:     int(req.GetZoom()),
+			Lat:      req.GetLat(),
+			Lon:      req.GetLon(),
+			FastMode: req.GetFastMode(),
+			Radius:   int(req.GetRadius()),
+			Depth:    int(req.GetDepth()),
+			Email:    req.GetEmail(),
+			MaxTime:  time.Duration(req.GetMaxTimeSeconds()) * time.Second,
+			Proxies:  req.GetProxies(),
+		},
+	}
+
+	if err := job.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.svc.Create(ctx, &job); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &gmapsv1.SubmitSearchResponse{Id: job.ID}, nil
+}
+
+func (s *Server) Results(req *gmapsv1.ResultsRequest, stream grpc.ServerStreamingServer[gmapsv1.Entry]) error {
+	ctx := stream.Context()
+
+	entries, cancel := s.svc.Subscribe(req.GetId())
+	defer cancel()
+
+	// the job may have ended before the subscription
+	job, err := s.getJob(ctx, req.GetId())
+	if err != nil {
+		return err
+	}
+
+	if job.Status == web.StatusOK || job.Status == web.StatusFailed {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-entries:
+			if !ok {
+				return nil
+			}
+
+			msg, err := toEntry(e)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) Progress(ctx context.Context, req *gmapsv1.ProgressRequest) (*gmapsv1.ProgressResponse, error) {
+	job, err := s.getJob(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	ans := gmapsv1.ProgressResponse{Status: job.Status}
+
+	if p, ok := s.svc.Progress(job.ID); ok {
+		ans.SeedCount = int64(p.SeedCount)
+		ans.SeedCompleted = int64(p.SeedCompleted)
+		ans.PlacesFound = int64(p.PlacesFound)
+		ans.PlacesCompleted = int64(p.PlacesCompleted)
+		ans.ParseWarnings = int64(p.ParseWarnings)
+	}
+
+	return &ans, nil
+}
+
+func (s *Server) getJob(ctx context.Context, id string) (web.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return web.Job{}, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	job, err := s.svc.Get(ctx, id)
+	if err != nil {
+		return web.Job{}, status.Error(codes.NotFound, "job not found")
+	}
+
+	return job, nil
+}
+
+func toEntry(e *gmaps.Entry) (*gmapsv1.Entry, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gmapsv1.Entry{
+		InputId:      e.ID,
+		Cid:          e.Cid,
+		DataId:       e.DataID,
+		Link:         e.Link,
+		Title:        e.Title,
+		Category:     e.Category,
+		Address:      e.Address,
+		Website:      e.WebSite,
+		Phone:        e.Phone,
+		ReviewCount:  int32(e.ReviewCount), //nolint:gosec // the review counts fit in an int32
+		ReviewRating: e.ReviewRating,
+		Latitude:     e.Latitude,
+		Longitude:    e.Longtitude,
+		Json:         data,
+		DistanceM:    e.DistanceM,
+	}, nil
+}
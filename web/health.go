@@ -0,0 +1,190 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/proxypool"
+)
+
+// The limits of the liveness of the server
+const (
+	// pollTimeout is how long the idle worker can go without polling the
+	// pending jobs
+	pollTimeout = time.Minute
+	// jobGrace is how long a job can run after its deadline while its
+	// results are written
+	jobGrace = 5 * time.Minute
+)
+
+// The statuses of a HealthReport
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// RunHealth is the health of the scraping of the running job, runner.App
+// implements it
+type RunHealth interface {
+	// WriterStall returns how long the results have been waiting for the
+	// writers
+	WriterStall() time.Duration
+	// ProxyHealth returns the health of the proxy pool, false without a pool
+	ProxyHealth() (proxypool.Health, bool)
+}
+
+// HealthConfig are the limits of the readiness of the server
+type HealthConfig struct {
+	// MaxPending is the number of the pending jobs from which the server is
+	// not ready, 0 disables it
+	MaxPending int
+	// MaxWriterStall is how long the results can wait for the writers before
+	// the server is not ready, 0 disables it
+	MaxWriterStall time.Duration
+}
+
+// QueueHealth is the depth of the queue of the jobs
+type QueueHealth struct {
+	Pending int `json:"pending"`
+	// Running is the id of the running job
+	Running    string `json:"running,omitempty"`
+	SeedsLeft  int    `json:"seeds_left"`
+	PlacesLeft int    `json:"places_left"`
+}
+
+// HealthReport is the body of /healthz and /readyz
+type HealthReport struct {
+	Status string `json:"status"`
+	// Reasons are why the server is unavailable
+	Reasons []string    `json:"reasons,omitempty"`
+	Queue   QueueHealth `json:"queue"`
+	// WriterStallSeconds is how long the results of the running job have
+	// been waiting for the writers
+	WriterStallSeconds float64           `json:"writer_stall_seconds"`
+	Proxies            *proxypool.Health `json:"proxies,omitempty"`
+}
+
+// running is the job run by the worker
+type running struct {
+	id       string
+	health   RunHealth
+	deadline time.Time
+}
+
+// Polled records that the worker polled the pending jobs
+func (s *Service) Polled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.polled = time.Now()
+}
+
+// Running records the job run by the worker until deadline, the health of
+// its scraping is reported by the health checks
+func (s *Service) Running(id string, health RunHealth, deadline time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running = &running{id: id, health: health, deadline: deadline}
+}
+
+// Stopped records that the worker is done with the job id
+func (s *Service) Stopped(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != nil && s.running.id == id {
+		s.running = nil
+	}
+
+	s.polled = time.Now()
+}
+
+// Health returns the health of the server. The server is not live when the
+// database cannot be read or the worker is stuck, and it is not ready either
+// when the limits of cfg are reached or all the proxies are quarantined.
+func (s *Service) Health(ctx context.Context, cfg HealthConfig, ready bool) HealthReport {
+	ans := HealthReport{Status: HealthOK}
+
+	pending, err := s.repo.Select(ctx, SelectParams{Status: StatusPending})
+	if err != nil {
+		ans.Reasons = append(ans.Reasons, fmt.Sprintf("cannot read the jobs: %v", err))
+	}
+
+	ans.Queue.Pending = len(pending)
+
+	s.mu.Lock()
+	job, polled := s.running, s.polled
+	s.mu.Unlock()
+
+	now := time.Now()
+
+	switch {
+	case job != nil && now.After(job.deadline.Add(jobGrace)):
+		ans.Reasons = append(ans.Reasons, fmt.Sprintf("job %s is running past its deadline", job.id))
+	case job == nil && !polled.IsZero() && now.Sub(polled) > pollTimeout:
+		ans.Reasons = append(ans.Reasons, fmt.Sprintf("the worker has not polled the jobs for %s", now.Sub(polled).Round(time.Second)))
+	}
+
+	if job != nil {
+		ans.Queue.Running = job.id
+
+		if p, ok := s.Progress(job.id); ok {
+			ans.Queue.SeedsLeft = max(p.SeedCount-p.SeedCompleted, 0)
+			ans.Queue.PlacesLeft = max(p.PlacesFound-p.PlacesCompleted, 0)
+		}
+
+		stall := job.health.WriterStall()
+		ans.WriterStallSeconds = stall.Round(time.Millisecond).Seconds()
+
+		if ready && cfg.MaxWriterStall > 0 && stall > cfg.MaxWriterStall {
+			ans.Reasons = append(ans.Reasons, fmt.Sprintf("the results have been waiting for the writers for %s", stall.Round(time.Second)))
+		}
+
+		if h, ok := job.health.ProxyHealth(); ok {
+			ans.Proxies = &h
+
+			if ready && h.Healthy == 0 {
+				ans.Reasons = append(ans.Reasons, "all the proxies are quarantined")
+			}
+		}
+	}
+
+	if ready && cfg.MaxPending > 0 && ans.Queue.Pending >= cfg.MaxPending {
+		ans.Reasons = append(ans.Reasons, fmt.Sprintf("%d jobs are pending", ans.Queue.Pending))
+	}
+
+	if len(ans.Reasons) > 0 {
+		ans.Status = HealthUnavailable
+	}
+
+	return ans
+}
+
+// healthHandler serves /healthz, the liveness, and /readyz, the readiness of the
+// server. They answer 503 when the server is unavailable.
+func (s *Server) healthHandler(ready bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans := s.svc.Health(r.Context(), s.healthCfg, ready)
+
+		code := http.StatusOK
+		if ans.Status != HealthOK {
+			code = http.StatusServiceUnavailable
+		}
+
+		renderJSON(w, code, ans)
+	}
+}
@@ -77,6 +77,19 @@ type JobData struct {
 	ValidatePlaceIdUrl string        `json:"validate_place_id_url"`
 }
 
+// defaultRadius is the radius in meters of the jobs without one
+const defaultRadius = 10000
+
+// SearchRadius returns the radius in meters searched by the job, 10 km by
+// default
+func (d *JobData) SearchRadius() float64 {
+	if d.Radius > 0 {
+		return float64(d.Radius)
+	}
+
+	return defaultRadius
+}
+
 func (d *JobData) Validate() error {
 	if len(d.Keywords) == 0 {
 		return errors.New("missing keywords")
@@ -0,0 +1,200 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// maxFeedEntries is how many of the last entries of a running job its feed
+// keeps for the subscribers
+const maxFeedEntries = 1000
+
+// feed are the last entries of a running job, kept until the job ends so that
+// the subscribers get the entries published before they subscribed
+type feed struct {
+	mu sync.Mutex
+	// entries are the last maxFeedEntries entries, the entry n of the job is
+	// entries[n%maxFeedEntries]
+	entries []*gmaps.Entry
+	// count is the number of entries published
+	count int
+	// notify is closed and replaced when an entry is published or the job ends
+	notify chan struct{}
+	ended  bool
+	// published is set once the job publishes, subs are the subscriptions
+	published bool
+	subs      int
+}
+
+// feed returns the feed of a job, it is created on the first call
+func (s *Service) feed(id string) *feed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.feeds[id]
+	if !ok {
+		f = &feed{notify: make(chan struct{})}
+		s.feeds[id] = f
+	}
+
+	return f
+}
+
+// Subscribe returns the entries of a running job: the last maxFeedEntries
+// already published and then the ones parsed from now on. A subscription that
+// falls behind by more than maxFeedEntries skips the entries it missed. The
+// channel is closed when the job ends and the entries are received, cancel
+// stops the subscription before it. The entries of a job that ended are not
+// kept.
+func (s *Service) Subscribe(id string) (entries <-chan *gmaps.Entry, cancel func()) {
+	f := s.feed(id)
+
+	f.mu.Lock()
+	f.subs++
+	f.mu.Unlock()
+
+	out := make(chan *gmaps.Entry)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+
+		for next := 0; ; {
+			f.mu.Lock()
+			// the entries dropped from the feed before they were received are skipped
+			next = max(next, f.count-len(f.entries))
+
+			pending := make([]*gmaps.Entry, 0, f.count-next)
+			for i := next; i < f.count; i++ {
+				pending = append(pending, f.entries[i%maxFeedEntries])
+			}
+
+			ended, notify := f.ended, f.notify
+			f.mu.Unlock()
+
+			for _, e := range pending {
+				select {
+				case out <- e:
+					next++
+				case <-done:
+					return
+				}
+			}
+
+			if len(pending) > 0 {
+				continue
+			}
+
+			if ended {
+				return
+			}
+
+			select {
+			case <-notify:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	cancel = func() {
+		once.Do(func() {
+			close(done)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			// the feed of a job that does not run is dropped with its last subscription
+			if f.subs--; f.subs == 0 && !f.published && s.feeds[id] == f {
+				delete(s.feeds, id)
+			}
+		})
+	}
+
+	return out, cancel
+}
+
+// Publish adds an entry of a job to its feed, in place of its oldest entry
+// once the feed is full. It does not wait for the subscribers, each one
+// receives the entries of the feed at its own pace.
+func (s *Service) Publish(id string, e *gmaps.Entry) {
+	f := s.feed(id)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.entries) < maxFeedEntries {
+		f.entries = append(f.entries, e)
+	} else {
+		f.entries[f.count%maxFeedEntries] = e
+	}
+
+	f.count++
+	f.published = true
+	f.wake()
+}
+
+// Finish ends the feed of a job, it is called when the job ends and its
+// entries are published. The subscriptions end once they received the entries.
+func (s *Service) Finish(id string) {
+	s.mu.Lock()
+	f, ok := s.feeds[id]
+	delete(s.feeds, id)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ended = true
+	f.wake()
+}
+
+// wake wakes up the subscribers, f.mu is held
+func (f *feed) wake() {
+	close(f.notify)
+	f.notify = make(chan struct{})
+}
+
+// ResultsWriter returns the writer that publishes the entries of a job to the
+// subscribers of Subscribe
+func (s *Service) ResultsWriter(id string) scrapemate.ResultWriter {
+	return &resultsWriter{svc: s, id: id}
+}
+
+type resultsWriter struct {
+	svc *Service
+	id  string
+}
+
+func (w *resultsWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			w.svc.Publish(w.id, data)
+		case []*gmaps.Entry:
+			for _, e := range data {
+				w.svc.Publish(w.id, e)
+			}
+		case nil:
+			continue
+		default:
+			return errors.New("invalid data type")
+		}
+	}
+
+	return nil
+}
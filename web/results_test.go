@@ -0,0 +1,108 @@
+package web_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+// titles returns the titles of the entries received until the channel is
+// closed
+func titles(t *testing.T, entries <-chan *gmaps.Entry) []string {
+	t.Helper()
+
+	var ans []string
+
+	for {
+		select {
+		case e, ok := <-entries:
+			if !ok {
+				return ans
+			}
+
+			ans = append(ans, e.Title)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the subscription did not end")
+		}
+	}
+}
+
+func Test_ServiceSubscribe(t *testing.T) {
+	svc := web.NewService(nil, t.TempDir())
+
+	const id = "job"
+
+	// the subscription before the job runs gets all the entries
+	early, cancelEarly := svc.Subscribe(id)
+	defer cancelEarly()
+
+	svc.Publish(id, &gmaps.Entry{Title: "a"})
+	svc.Publish(id, &gmaps.Entry{Title: "b"})
+
+	// the subscription of the running job gets the entries published before it
+	late, cancelLate := svc.Subscribe(id)
+	defer cancelLate()
+
+	// a subscription that is not read does not block the job
+	_, cancelIdle := svc.Subscribe(id)
+	defer cancelIdle()
+
+	svc.Publish(id, &gmaps.Entry{Title: "c"})
+	svc.Finish(id)
+
+	require.Equal(t, []string{"a", "b", "c"}, titles(t, early))
+	require.Equal(t, []string{"a", "b", "c"}, titles(t, late))
+
+	// the entries of a job that ended are not kept
+	ended, cancelEnded := svc.Subscribe(id)
+	cancelEnded()
+
+	require.Empty(t, titles(t, ended))
+}
+
+func Test_ServiceSubscribeCancel(t *testing.T) {
+	svc := web.NewService(nil, t.TempDir())
+
+	const id = "job"
+
+	entries, cancel := svc.Subscribe(id)
+
+	svc.Publish(id, &gmaps.Entry{Title: "a"})
+	require.Equal(t, "a", (<-entries).Title)
+
+	cancel()
+	cancel()
+
+	// the channel is closed by cancel, before the job ends
+	require.Empty(t, titles(t, entries))
+
+	svc.Publish(id, &gmaps.Entry{Title: "b"})
+	svc.Finish(id)
+}
+
+func Test_ServiceSubscribeLimit(t *testing.T) {
+	svc := web.NewService(nil, t.TempDir())
+
+	const id = "job"
+
+	for i := range web.MaxFeedEntries + 5 {
+		svc.Publish(id, &gmaps.Entry{Title: strconv.Itoa(i)})
+	}
+
+	// the subscription gets the last entries kept by the feed
+	entries, cancel := svc.Subscribe(id)
+	defer cancel()
+
+	svc.Finish(id)
+
+	got := titles(t, entries)
+
+	require.Len(t, got, web.MaxFeedEntries)
+	require.Equal(t, "5", got[0])
+	require.Equal(t, strconv.Itoa(web.MaxFeedEntries+4), got[len(got)-1])
+}
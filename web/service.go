@@ -6,20 +6,56 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
 )
 
 type Service struct {
 	repo       JobRepository
 	dataFolder string
+
+	mu       sync.Mutex
+	monitors map[string]exiter.Exiter
+	feeds    map[string]*feed
+	// running is the job run by the worker, polled is when it last polled
+	// the pending jobs
+	running *running
+	polled  time.Time
 }
 
 func NewService(repo JobRepository, dataFolder string) *Service {
 	return &Service{
 		repo:       repo,
 		dataFolder: dataFolder,
+		monitors:   make(map[string]exiter.Exiter),
+		feeds:      make(map[string]*feed),
 	}
 }
 
+// Track records the exit monitor of a running job for its progress. The
+// monitor is kept after the job ends, its counters are the final ones.
+func (s *Service) Track(id string, monitor exiter.Exiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.monitors[id] = monitor
+}
+
+// Progress returns the progress of a job run since the server started
+func (s *Service) Progress(id string) (exiter.Progress, bool) {
+	s.mu.Lock()
+	monitor, ok := s.monitors[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return exiter.Progress{}, false
+	}
+
+	return monitor.Progress(), true
+}
+
 func (s *Service) Create(ctx context.Context, job *Job) error {
 	return s.repo.Create(ctx, job)
 }
@@ -47,6 +83,10 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	s.mu.Lock()
+	delete(s.monitors, id)
+	s.mu.Unlock()
+
 	return s.repo.Delete(ctx, id)
 }
 
@@ -8,7 +8,7 @@ import (
 	"html/template"
 	"io"
 	"io/fs"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,18 +17,34 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/gosom/google-maps-scraper/estimate"
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/webhook"
 )
 
 //go:embed static
 var static embed.FS
 
 type Server struct {
-	tmpl map[string]*template.Template
-	srv  *http.Server
-	svc  *Service
+	tmpl      map[string]*template.Template
+	srv       *http.Server
+	svc       *Service
+	healthCfg HealthConfig
+}
+
+// ServerOption configures a Server
+type ServerOption func(*Server)
+
+// WithHealth sets the limits of the readiness of /readyz
+func WithHealth(cfg HealthConfig) ServerOption {
+	return func(s *Server) {
+		s.healthCfg = cfg
+	}
 }
 
-func New(svc *Service, addr string) (*Server, error) {
+func New(svc *Service, addr string, opts ...ServerOption) (*Server, error) {
 	ans := Server{
 		svc:  svc,
 		tmpl: make(map[string]*template.Template),
@@ -42,6 +58,10 @@ func New(svc *Service, addr string) (*Server, error) {
 		},
 	}
 
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
 	staticFS, err := fs.Sub(static, "static")
 	if err != nil {
 		return nil, err
@@ -63,7 +83,11 @@ func New(svc *Service, addr string) (*Server, error) {
 		ans.delete(w, r)
 	})
 	mux.HandleFunc("/jobs", ans.getJobs)
+	mux.HandleFunc("/dashboard", ans.dashboard)
+	mux.HandleFunc("/dashboard/jobs", ans.dashboardJobs)
 	mux.HandleFunc("/", ans.index)
+	mux.HandleFunc("/healthz", ans.healthHandler(false))
+	mux.HandleFunc("/readyz", ans.healthHandler(true))
 
 	// api routes
 	mux.HandleFunc("/api/docs", ans.redocHandler)
@@ -83,6 +107,36 @@ func New(svc *Service, addr string) (*Server, error) {
 		}
 	})
 
+	mux.HandleFunc("/api/v1/estimate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiEstimate(w, r)
+	})
+
+	mux.HandleFunc("/schema", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		renderJSON(w, http.StatusOK, webhook.Schema())
+	})
+
 	mux.HandleFunc("/api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
 		r = requestWithID(r)
 
@@ -101,7 +155,7 @@ func New(svc *Service, addr string) (*Server, error) {
 		}
 	})
 
-	mux.HandleFunc("/api/v1/jobs/{id}/download", func(w http.ResponseWriter, r *http.Request) {
+	apiDownload := func(w http.ResponseWriter, r *http.Request) {
 		r = requestWithID(r)
 
 		if r.Method != http.MethodGet {
@@ -116,6 +170,26 @@ func New(svc *Service, addr string) (*Server, error) {
 		}
 
 		ans.download(w, r)
+	}
+
+	mux.HandleFunc("/api/v1/jobs/{id}/download", apiDownload)
+	mux.HandleFunc("/api/v1/jobs/{id}/results", apiDownload)
+
+	mux.HandleFunc("/api/v1/jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		r = requestWithID(r)
+
+		if r.Method != http.MethodGet {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		ans.apiJobEvents(w, r)
 	})
 
 	handler := securityHeaders(mux)
@@ -126,6 +200,8 @@ func New(svc *Service, addr string) (*Server, error) {
 		"static/templates/job_rows.html",
 		"static/templates/job_row.html",
 		"static/templates/redoc.html",
+		"static/templates/dashboard.html",
+		"static/templates/dashboard_jobs.html",
 	}
 
 	for _, key := range tmplsKeys {
@@ -146,12 +222,12 @@ func (s *Server) Start(ctx context.Context) error {
 
 		err := s.srv.Shutdown(context.Background())
 		if err != nil {
-			log.Println(err)
+			slog.Error("web server failed", "error", err)
 
 			return
 		}
 
-		log.Println("server stopped")
+		slog.Info("web server stopped")
 	}()
 
 	fmt.Fprintf(os.Stderr, "visit http://localhost%s\n", s.srv.Addr)
@@ -465,10 +541,56 @@ type apiScrapeRequest struct {
 	JobData
 }
 
+// apiJob is a job with the progress of its run, missing when the job did not
+// run since the server started
+type apiJob struct {
+	Job
+	Progress *exiter.Progress `json:"progress,omitempty"`
+}
+
+func (s *Server) withProgress(job Job) apiJob {
+	ans := apiJob{Job: job}
+
+	if p, ok := s.svc.Progress(job.ID); ok {
+		ans.Progress = &p
+	}
+
+	return ans
+}
+
 type apiScrapeResponse struct {
 	ID string `json:"id"`
 }
 
+type apiEstimateRequest struct {
+	JobData
+	EmailPages     int  `json:"email_pages"`
+	ExtraReviews   bool `json:"extra_reviews"`
+	Concurrency    int  `json:"concurrency"`
+	PlacesPerQuery int  `json:"places_per_query"`
+}
+
+type apiEstimateResponse struct {
+	estimate.Estimate
+	// Duration is in seconds like the max_time of the jobs
+	Duration int64 `json:"duration"`
+}
+
+// area returns the circle searched by the job, nil without coordinates
+func (r *apiEstimateRequest) area() *gmaps.MapLocation {
+	lat, err := strconv.ParseFloat(r.Lat, 64)
+	if err != nil {
+		return nil
+	}
+
+	lon, err := strconv.ParseFloat(r.Lon, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &gmaps.MapLocation{Lat: lat, Lon: lon, Radius: r.SearchRadius()}
+}
+
 func (s *Server) redocHandler(w http.ResponseWriter, _ *http.Request) {
 	tmpl, ok := s.tmpl["static/templates/redoc.html"]
 	if !ok {
@@ -537,6 +659,49 @@ func (s *Server) apiScrape(w http.ResponseWriter, r *http.Request) {
 	renderJSON(w, http.StatusCreated, ans)
 }
 
+func (s *Server) apiEstimate(w http.ResponseWriter, r *http.Request) {
+	var req apiEstimateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ans := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, ans)
+
+		return
+	}
+
+	ans, err := estimate.EstimateRun(estimate.Params{
+		Queries:        len(req.Keywords),
+		Area:           req.area(),
+		Zoom:           req.Zoom,
+		FastMode:       req.FastMode,
+		Depth:          req.Depth,
+		Email:          req.Email,
+		EmailPages:     req.EmailPages,
+		ExtraReviews:   req.ExtraReviews,
+		Concurrency:    req.Concurrency,
+		PlacesPerQuery: req.PlacesPerQuery,
+	})
+	if err != nil {
+		apiError := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, apiError)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, apiEstimateResponse{
+		Estimate: ans,
+		Duration: int64(ans.Duration.Seconds()),
+	})
+}
+
 func (s *Server) apiGetJobs(w http.ResponseWriter, r *http.Request) {
 	jobs, err := s.svc.All(r.Context())
 	if err != nil {
@@ -550,7 +715,12 @@ func (s *Server) apiGetJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	renderJSON(w, http.StatusOK, jobs)
+	ans := make([]apiJob, len(jobs))
+	for i := range jobs {
+		ans[i] = s.withProgress(jobs[i])
+	}
+
+	renderJSON(w, http.StatusOK, ans)
 }
 
 func (s *Server) apiGetJob(w http.ResponseWriter, r *http.Request) {
@@ -578,7 +748,7 @@ func (s *Server) apiGetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	renderJSON(w, http.StatusOK, job)
+	renderJSON(w, http.StatusOK, s.withProgress(job))
 }
 
 func (s *Server) apiDeleteJob(w http.ResponseWriter, r *http.Request) {
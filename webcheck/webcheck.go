@@ -0,0 +1,406 @@
+// Package webcheck checks whether the websites of the places are alive.
+package webcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/fetcher"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/ratelimit"
+)
+
+// parkingHosts are the domain marketplaces parked domains redirect to
+var parkingHosts = []string{
+	"sedo.com", "dan.com", "afternic.com", "hugedomains.com", "godaddy.com",
+	"bodis.com", "parkingcrew.net", "above.com", "undeveloped.com", "buydomains.com",
+}
+
+// parkingPhrases are found in the pages of parked domains
+var parkingPhrases = [][]byte{
+	[]byte("domain is for sale"),
+	[]byte("domain may be for sale"),
+	[]byte("buy this domain"),
+	[]byte("domain name is for sale"),
+	[]byte("this domain is parked"),
+	[]byte("parked free"),
+	[]byte("sedoparking"),
+	[]byte("parkingcrew"),
+	[]byte("domain has expired"),
+}
+
+// The defaults of the checker
+const (
+	defaultTimeout = 15 * time.Second
+	defaultWorkers = 4
+	maxRedirects   = 10
+	// the body is only read to detect parked domains
+	maxBody = 64 * 1024
+)
+
+// limiterKey is the key of the requests of the websites in the rate limiter
+const limiterKey = "website"
+
+// Checker requests the websites following the redirects. The requests go
+// through the transport of the app, its rate limiter and a circuit breaker of
+// their own, so that the websites are checked like the other requests of the
+// run.
+type Checker struct {
+	timeout   time.Duration
+	workers   int
+	transport http.RoundTripper
+	limiter   ratelimit.Limiter
+	breaker   *fetcher.BreakerConfig
+
+	// fetcher sends the requests of the checks
+	fetcher scrapemate.HTTPFetcher
+}
+
+// Option configures a Checker
+type Option func(*Checker)
+
+// WithWorkers sets the number of websites checked at the same time, 4 by
+// default
+func WithWorkers(n int) Option {
+	return func(c *Checker) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithTransport sends the requests with rt, e.g. the rotator of the proxies
+// of the run. A scrapemate.ProxyRotator also gives the proxies of the
+// requests of the invalid certificates.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Checker) {
+		c.transport = rt
+	}
+}
+
+// WithRateLimiter makes the requests wait for the limiter
+func WithRateLimiter(l ratelimit.Limiter) Option {
+	return func(c *Checker) {
+		c.limiter = l
+	}
+}
+
+// WithCircuitBreaker pauses the requests when too many of them fail
+func WithCircuitBreaker(cfg fetcher.BreakerConfig) Option {
+	return func(c *Checker) {
+		c.breaker = &cfg
+	}
+}
+
+// New creates a Checker. A zero timeout uses 15 seconds.
+func New(timeout time.Duration, opts ...Option) *Checker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	c := Checker{timeout: timeout, workers: defaultWorkers}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	c.fetcher = &checkFetcher{
+		client:   c.client(c.transport),
+		insecure: c.insecureTransport,
+	}
+
+	if c.limiter != nil {
+		c.fetcher = fetcher.NewRateLimited(c.fetcher, c.limiter, limiterKey)
+	}
+
+	if c.breaker != nil {
+		c.fetcher = fetcher.NewCircuitBreaker(c.fetcher, *c.breaker)
+	}
+
+	return &c
+}
+
+// Run checks the websites of the entries of the results of in with the
+// workers of the checker, so that the scraping does not wait for the
+// websites, and passes the checked results to the returned channel. The
+// channel is closed once in is closed and the checks are done, or when ctx is
+// done.
+//
+//nolint:gocritic // the results are read like the ones of scrapemate
+func (c *Checker) Run(ctx context.Context, in <-chan scrapemate.Result) <-chan scrapemate.Result {
+	out := make(chan scrapemate.Result)
+
+	var wg sync.WaitGroup
+
+	for range c.workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for result := range in {
+				c.checkResult(ctx, &result)
+
+				select {
+				case <-ctx.Done():
+					// keep consuming so that the producer does not block
+					for range in {
+					}
+
+					return
+				case out <- result:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+func (c *Checker) checkResult(ctx context.Context, result *scrapemate.Result) {
+	switch data := result.Data.(type) {
+	case *gmaps.Entry:
+		c.checkEntry(ctx, data)
+	case []*gmaps.Entry:
+		for _, entry := range data {
+			c.checkEntry(ctx, entry)
+		}
+	}
+}
+
+// checkEntry checks the website of the entry
+func (c *Checker) checkEntry(ctx context.Context, entry *gmaps.Entry) {
+	if entry.WebSite == "" {
+		return
+	}
+
+	entry.WebsiteCheck = c.Check(ctx, entry.WebSite)
+	entry.WebsiteStatus = entry.WebsiteCheck.Status
+}
+
+// Check requests u and reports its status and its certificate
+func (c *Checker) Check(ctx context.Context, u string) gmaps.WebsiteCheck {
+	resp := c.fetcher.Fetch(ctx, &scrapemate.Job{Method: http.MethodGet, URL: u})
+	if resp.Error != nil {
+		return gmaps.WebsiteCheck{Status: gmaps.WebsiteUnreachable, Error: resp.Error.Error()}
+	}
+
+	final, err := url.Parse(resp.URL)
+	if err != nil {
+		return gmaps.WebsiteCheck{Status: gmaps.WebsiteUnreachable, Error: err.Error()}
+	}
+
+	state, _ := resp.Meta[metaTLS].(*tls.ConnectionState)
+	certErr, _ := resp.Meta[metaCertError].(error)
+
+	ans := gmaps.WebsiteCheck{
+		FinalURL:   resp.URL,
+		StatusCode: resp.StatusCode,
+		Parked:     isParked(final, resp.Body),
+		HTTPS:      state != nil,
+	}
+
+	if certErr != nil {
+		ans.Error = certErr.Error()
+	}
+
+	if state != nil && len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+
+		ans.Certificate = gmaps.Certificate{
+			Issuer:       certName(cert.Issuer.Organization, cert.Issuer.CommonName),
+			Organization: certName(cert.Subject.Organization, ""),
+			Expires:      cert.NotAfter,
+			Valid:        certErr == nil && time.Now().Before(cert.NotAfter),
+		}
+	}
+
+	switch {
+	case ans.Parked:
+		ans.Status = gmaps.WebsiteParked
+	case resp.StatusCode >= http.StatusBadRequest:
+		ans.Status = gmaps.WebsiteDead
+	case !sameSite(u, final):
+		ans.Status = gmaps.WebsiteRedirected
+	default:
+		ans.Status = gmaps.WebsiteLive
+	}
+
+	return ans
+}
+
+// Close closes the idle connections of the checker
+func (c *Checker) Close() error {
+	return c.fetcher.Close()
+}
+
+func (c *Checker) client(rt http.RoundTripper) *http.Client {
+	return &http.Client{
+		Timeout:   c.timeout,
+		Transport: rt,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return http.ErrUseLastResponse
+			}
+
+			return nil
+		},
+	}
+}
+
+// insecureTransport returns the transport reading the certificates that fail
+// the verification, through the next proxy of the rotator of the checker
+func (c *Checker) insecureTransport() (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+
+	if rotator, ok := c.transport.(scrapemate.ProxyRotator); ok {
+		var err error
+
+		if tr, err = fetcher.NewProxyTransport(rotator.Next()); err != nil {
+			return nil, err
+		}
+	}
+
+	tr.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // only used to read invalid certificates
+	}
+
+	return tr, nil
+}
+
+// The metadata of the responses of checkFetcher
+const (
+	// metaTLS is the *tls.ConnectionState of the https responses
+	metaTLS = "tls"
+	// metaCertError is the error of the verification of the certificate
+	metaCertError = "cert_error"
+)
+
+var _ scrapemate.HTTPFetcher = (*checkFetcher)(nil)
+
+// checkFetcher requests the websites, the response has the final url after the
+// redirects and the TLS state of the connection in its metadata. A certificate
+// that fails the verification is read again without it.
+type checkFetcher struct {
+	client   *http.Client
+	insecure func() (*http.Transport, error)
+}
+
+func (f *checkFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	u := job.GetFullURL()
+
+	meta := make(map[string]any)
+
+	resp, err := f.get(ctx, f.client, u)
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		meta[metaCertError] = certErr
+
+		var tr *http.Transport
+
+		if tr, err = f.insecure(); err == nil {
+			defer tr.CloseIdleConnections()
+
+			client := *f.client
+			client.Transport = tr
+
+			resp, err = f.get(ctx, &client, u)
+		}
+	}
+
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	if resp.TLS != nil {
+		meta[metaTLS] = resp.TLS
+	}
+
+	return scrapemate.Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		Meta:       meta,
+	}
+}
+
+func (f *checkFetcher) Close() error {
+	f.client.CloseIdleConnections()
+
+	return nil
+}
+
+func (f *checkFetcher) get(ctx context.Context, client *http.Client, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36")
+
+	return client.Do(req)
+}
+
+func certName(orgs []string, fallback string) string {
+	if len(orgs) > 0 {
+		return strings.Join(orgs, ", ")
+	}
+
+	return fallback
+}
+
+func isParked(final *url.URL, body []byte) bool {
+	host := strings.ToLower(final.Hostname())
+
+	for _, h := range parkingHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+
+	body = bytes.ToLower(body)
+
+	for _, phrase := range parkingPhrases {
+		if bytes.Contains(body, phrase) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameSite reports whether final is on the same domain as the original url,
+// ignoring the www prefix
+func sameSite(original string, final *url.URL) bool {
+	u, err := url.Parse(original)
+	if err != nil {
+		return false
+	}
+
+	strip := func(h string) string {
+		return strings.TrimPrefix(strings.ToLower(h), "www.")
+	}
+
+	return strip(u.Hostname()) == strip(final.Hostname())
+}
@@ -0,0 +1,113 @@
+package webcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/webcheck"
+)
+
+func Test_Check(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>other site</html>"))
+	}))
+	defer other.Close()
+
+	// the hosts are compared, the other site is reached by its name
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live":
+			_, _ = w.Write([]byte("<html>welcome</html>"))
+		case "/parked":
+			_, _ = w.Write([]byte("<html>This domain is for sale!</html>"))
+		case "/moved":
+			http.Redirect(w, r, "/live", http.StatusFound)
+		case "/away":
+			http.Redirect(w, r, otherURL, http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		url    string
+		status string
+		final  string
+	}{
+		{name: "live", url: srv.URL + "/live", status: gmaps.WebsiteLive, final: srv.URL + "/live"},
+		{name: "redirect on the site", url: srv.URL + "/moved", status: gmaps.WebsiteLive, final: srv.URL + "/live"},
+		{name: "redirect to another site", url: srv.URL + "/away", status: gmaps.WebsiteRedirected, final: otherURL},
+		{name: "parked", url: srv.URL + "/parked", status: gmaps.WebsiteParked, final: srv.URL + "/parked"},
+		{name: "dead", url: srv.URL + "/missing", status: gmaps.WebsiteDead, final: srv.URL + "/missing"},
+		{name: "unreachable", url: "http://127.0.0.1:1/", status: gmaps.WebsiteUnreachable},
+	}
+
+	c := webcheck.New(0)
+	defer c.Close()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c.Check(context.Background(), tc.url)
+			require.Equal(t, tc.status, got.Status)
+			require.Equal(t, tc.final, got.FinalURL)
+			require.False(t, got.HTTPS)
+		})
+	}
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func Test_CheckerRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>welcome</html>"))
+	}))
+	defer srv.Close()
+
+	transport := &countingTransport{}
+
+	c := webcheck.New(0, webcheck.WithWorkers(2), webcheck.WithTransport(transport))
+	defer c.Close()
+
+	in := make(chan scrapemate.Result, 3)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a", WebSite: srv.URL}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "b", WebSite: srv.URL}, {Title: "c"}}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "d"}}
+	close(in)
+
+	statuses := make(map[string]string)
+
+	for result := range c.Run(context.Background(), in) {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			statuses[data.Title] = data.WebsiteStatus
+		case []*gmaps.Entry:
+			for _, entry := range data {
+				statuses[entry.Title] = entry.WebsiteStatus
+			}
+		}
+	}
+
+	require.Equal(t, map[string]string{"a": gmaps.WebsiteLive, "b": gmaps.WebsiteLive, "c": "", "d": ""}, statuses)
+	require.Equal(t, int32(2), transport.requests.Load())
+}
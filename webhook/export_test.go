@@ -0,0 +1,9 @@
+package webhook
+
+import "time"
+
+// SetBackoff sets the wait before the first retry, far below the one of the
+// webhooks in the tests
+func (w *Writer) SetBackoff(backoff time.Duration) {
+	w.backoff = backoff
+}
@@ -0,0 +1,141 @@
+// Package webhook posts the entries as flat JSON payloads that no-code tools
+// like Zapier and Make consume without custom parsing.
+package webhook
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SchemaVersion changes when a field of the payload is renamed or removed,
+// new fields are added without changing it
+const SchemaVersion = 1
+
+// Payload is the flat version of an entry: every field is always present and
+// is a string, a number or a boolean. Lists are joined with ", ".
+type Payload struct {
+	SchemaVersion int     `json:"schema_version" desc:"version of the payload fields"`
+	ScrapedAt     string  `json:"scraped_at" desc:"time the place was scraped (RFC 3339, UTC)"`
+	Query         string  `json:"query" desc:"id of the input query, when the query has one"`
+	PlaceID       string  `json:"place_id" desc:"Google Maps data id of the place"`
+	CID           string  `json:"cid" desc:"Google Maps customer id of the place"`
+	Kgmid         string  `json:"kgmid" desc:"Google knowledge graph id of the place"`
+	Title         string  `json:"title" desc:"name of the place"`
+	Category      string  `json:"category" desc:"main category"`
+	Categories    string  `json:"categories" desc:"all the categories"`
+	Canonical     string  `json:"canonical_category" desc:"main category in the canonical taxonomy, e.g. restaurant"`
+	Address       string  `json:"address" desc:"full address"`
+	Street        string  `json:"street" desc:"street of the address"`
+	City          string  `json:"city" desc:"city of the address"`
+	PostalCode    string  `json:"postal_code" desc:"postal code of the address"`
+	State         string  `json:"state" desc:"state of the address"`
+	Country       string  `json:"country" desc:"country code of the address"`
+	Latitude      float64 `json:"latitude" desc:"latitude"`
+	Longitude     float64 `json:"longitude" desc:"longitude"`
+	DistanceM     float64 `json:"distance_m" desc:"distance in meters from the center of the search, 0 without one"`
+	Phone         string  `json:"phone" desc:"phone number"`
+	Website       string  `json:"website" desc:"website"`
+	Email         string  `json:"email" desc:"first email found on the website"`
+	Emails        string  `json:"emails" desc:"all the emails found on the website"`
+	Rating        float64 `json:"rating" desc:"average rating, 0 when not rated"`
+	ReviewCount   int     `json:"review_count" desc:"number of reviews"`
+	PriceRange    string  `json:"price_range" desc:"price range"`
+	Status        string  `json:"status" desc:"business status, e.g. Permanently closed"`
+	Closed        bool    `json:"permanently_closed" desc:"whether the place is permanently closed"`
+	Description   string  `json:"description" desc:"description"`
+	OwnerName     string  `json:"owner_name" desc:"name of the owner"`
+	PlusCode      string  `json:"plus_code" desc:"plus code"`
+	GlobalPlus    string  `json:"global_plus_code" desc:"full plus code, without the locality"`
+	Timezone      string  `json:"timezone" desc:"IANA timezone"`
+	Thumbnail     string  `json:"thumbnail" desc:"URL of the main picture"`
+	Link          string  `json:"link" desc:"Google Maps URL of the place"`
+	ReviewsLink   string  `json:"reviews_link" desc:"Google Maps URL of the reviews"`
+}
+
+// NewPayload flattens the entry
+func NewPayload(e *gmaps.Entry, scrapedAt time.Time) Payload {
+	var email string
+	if len(e.Emails) > 0 {
+		email = e.Emails[0]
+	}
+
+	return Payload{
+		SchemaVersion: SchemaVersion,
+		ScrapedAt:     scrapedAt.UTC().Format(time.RFC3339),
+		Query:         e.ID,
+		PlaceID:       e.DataID,
+		CID:           e.Cid,
+		Kgmid:         e.Kgmid,
+		Title:         e.Title,
+		Category:      e.Category,
+		Categories:    strings.Join(e.Categories, ", "),
+		Canonical:     e.CanonicalCategory,
+		Address:       e.Address,
+		Street:        e.CompleteAddress.Street,
+		City:          e.CompleteAddress.City,
+		PostalCode:    e.CompleteAddress.PostalCode,
+		State:         e.CompleteAddress.State,
+		Country:       e.CompleteAddress.Country,
+		Latitude:      e.Latitude,
+		Longitude:     e.Longtitude,
+		DistanceM:     e.DistanceM,
+		Phone:         e.Phone,
+		Website:       e.WebSite,
+		Email:         email,
+		Emails:        strings.Join(e.Emails, ", "),
+		Rating:        e.ReviewRating,
+		ReviewCount:   e.ReviewCount,
+		PriceRange:    e.PriceRange,
+		Status:        e.Status,
+		Closed:        e.BusinessStatus == gmaps.BusinessPermanentlyClosed,
+		Description:   e.Description,
+		OwnerName:     e.Owner.Name,
+		PlusCode:      e.PlusCode,
+		GlobalPlus:    e.GlobalPlusCode,
+		Timezone:      e.Timezone,
+		Thumbnail:     e.Thumbnail,
+		Link:          e.Link,
+		ReviewsLink:   e.ReviewsLink,
+	}
+}
+
+// Schema returns the JSON Schema of the payload
+func Schema() map[string]any {
+	properties := make(map[string]any)
+	required := make([]string, 0)
+
+	t := reflect.TypeOf(Payload{})
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := f.Tag.Get("json")
+
+		var kind string
+
+		switch f.Type.Kind() {
+		case reflect.Int:
+			kind = "integer"
+		case reflect.Float64:
+			kind = "number"
+		case reflect.Bool:
+			kind = "boolean"
+		default:
+			kind = "string"
+		}
+
+		properties[name] = map[string]string{"type": kind, "description": f.Tag.Get("desc")}
+		required = append(required, name)
+	}
+
+	return map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"title":      "Google Maps place",
+		"version":    SchemaVersion,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
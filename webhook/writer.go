@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// maxRetries is how many times a rate limited or failed request is retried
+	maxRetries = 3
+	// minBackoff is the wait before the first retry
+	minBackoff = time.Second
+	// defaultBatchAge is how long the places wait for a full batch
+	defaultBatchAge = 10 * time.Second
+	// SignatureHeader has the HMAC-SHA256 of the body with the secret, as sha256=<hex>
+	SignatureHeader = "X-Signature-256"
+)
+
+var _ scrapemate.ResultWriter = (*Writer)(nil)
+
+// Writer posts a payload per place to the webhook URL, or a JSON array of
+// payloads per batch of places. A place that cannot be delivered is logged
+// and skipped so that the run goes on.
+type Writer struct {
+	url       string
+	client    *http.Client
+	secret    []byte
+	batchSize int
+	batchAge  time.Duration
+	backoff   time.Duration
+}
+
+// WriterOption configures the writer
+type WriterOption func(*Writer)
+
+// WithHTTPClient replaces the HTTP client of the requests
+func WithHTTPClient(c *http.Client) WriterOption {
+	return func(w *Writer) {
+		w.client = c
+	}
+}
+
+// WithSecret signs the bodies: SignatureHeader has their HMAC-SHA256 with the secret
+func WithSecret(secret string) WriterOption {
+	return func(w *Writer) {
+		w.secret = []byte(secret)
+	}
+}
+
+// WithBatchSize posts the places by batches of n as a JSON array, every
+// place is posted on its own by default
+func WithBatchSize(n int) WriterOption {
+	return func(w *Writer) {
+		w.batchSize = n
+	}
+}
+
+// WithBatchAge posts the batch that is not full once its first place waited
+// for d, 10 seconds by default
+func WithBatchAge(d time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.batchAge = d
+	}
+}
+
+// NewWriter returns a writer posting to u
+func NewWriter(u string, opts ...WriterOption) *Writer {
+	ans := Writer{
+		url:      u,
+		client:   &http.Client{Timeout: defaultTimeout},
+		batchAge: defaultBatchAge,
+		backoff:  minBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+func (w *Writer) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	var sent, failed int
+
+	batch := make([]Payload, 0, max(w.batchSize, 1))
+
+	// the timer posts the batch not full while no place arrives, it is
+	// started by the first place of the batch
+	timer := time.NewTimer(w.batchAge)
+	timer.Stop()
+
+	defer timer.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := w.postBatch(ctx, batch); err != nil {
+			if len(batch) == 1 {
+				slog.Warn("webhook: place not delivered", "place", batch[0].Title, "error", err)
+			} else {
+				slog.Warn("webhook: batch not delivered", "places", len(batch), "error", err)
+			}
+
+			failed += len(batch)
+		} else {
+			sent += len(batch)
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case result, ok := <-in:
+			if !ok {
+				flush()
+
+				slog.Info("webhook: places delivered", "delivered", sent, "failed", failed)
+
+				return nil
+			}
+
+			var entries []*gmaps.Entry
+
+			switch data := result.Data.(type) {
+			case *gmaps.Entry:
+				entries = []*gmaps.Entry{data}
+			case []*gmaps.Entry:
+				entries = data
+			}
+
+			for _, e := range entries {
+				if len(batch) == 0 {
+					timer.Reset(w.batchAge)
+				}
+
+				batch = append(batch, NewPayload(e, time.Now()))
+
+				if len(batch) >= w.batchSize {
+					flush()
+				}
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// postBatch posts the payload of a place, or the array of payloads of a batch
+func (w *Writer) postBatch(ctx context.Context, batch []Payload) error {
+	var (
+		body []byte
+		err  error
+	)
+
+	if w.batchSize <= 1 {
+		body, err = json.Marshal(batch[0])
+	} else {
+		body, err = json.Marshal(batch)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return w.post(ctx, body)
+}
+
+// Post posts v as JSON, with the signature and the retries of the places
+func (w *Writer) Post(ctx context.Context, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return w.post(ctx, body)
+}
+
+// Sign returns the value of SignatureHeader of the body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post sends the body, retrying the connection errors, the rate limited (429)
+// and the server error responses after minBackoff and then twice longer
+// every time
+func (w *Writer) post(ctx context.Context, body []byte) error {
+	backoff := w.backoff
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		if len(w.secret) > 0 {
+			req.Header.Set(SignatureHeader, Sign(w.secret, body))
+		}
+
+		var retry bool
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= maxRetries {
+				return err
+			}
+
+			retry = true
+		} else {
+			_ = resp.Body.Close()
+
+			retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		}
+
+		if retry && attempt < maxRetries {
+			delay := backoff
+			if resp != nil {
+				if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+					delay = time.Duration(s) * time.Second
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+
+			backoff *= 2
+
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
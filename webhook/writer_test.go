@@ -0,0 +1,293 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+// receiver is a webhook recording the titles of the places of every request
+type receiver struct {
+	mu       sync.Mutex
+	requests [][]string
+	// arrays are whether the bodies of the requests were JSON arrays
+	arrays []bool
+	// valid are whether the signatures of the requests matched the secret
+	valid    []bool
+	secret   string
+	attempts int
+	// statuses are the status codes of the next requests, before they succeed
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+
+	if len(r.statuses) > 0 {
+		w.WriteHeader(r.statuses[0])
+		r.statuses = r.statuses[1:]
+
+		return
+	}
+
+	var batch []webhook.Payload
+
+	err := json.Unmarshal(body, &batch)
+	if err != nil {
+		var p webhook.Payload
+		_ = json.Unmarshal(body, &p)
+		batch = []webhook.Payload{p}
+	}
+
+	titles := make([]string, 0, len(batch))
+	for _, p := range batch {
+		titles = append(titles, p.Title)
+	}
+
+	r.requests = append(r.requests, titles)
+	r.arrays = append(r.arrays, err == nil)
+
+	if r.secret != "" {
+		r.valid = append(r.valid, req.Header.Get(webhook.SignatureHeader) == webhook.Sign([]byte(r.secret), body))
+	} else {
+		r.valid = append(r.valid, req.Header.Get(webhook.SignatureHeader) == "")
+	}
+}
+
+func (r *receiver) received() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([][]string(nil), r.requests...)
+}
+
+func run(w *webhook.Writer, results ...scrapemate.Result) error {
+	in := make(chan scrapemate.Result, len(results))
+	for _, r := range results {
+		in <- r
+	}
+
+	close(in)
+
+	return w.Run(context.Background(), in)
+}
+
+func places(titles ...string) []*gmaps.Entry {
+	ans := make([]*gmaps.Entry, 0, len(titles))
+	for _, t := range titles {
+		ans = append(ans, &gmaps.Entry{Title: t})
+	}
+
+	return ans
+}
+
+func Test_Writer(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []webhook.WriterOption
+		results  []scrapemate.Result
+		requests [][]string
+		arrays   []bool
+	}{
+		{
+			name: "place per request",
+			results: []scrapemate.Result{
+				{Data: &gmaps.Entry{Title: "a"}},
+				{Data: places("b", "c")},
+				{Data: nil},
+				{Data: "place"},
+			},
+			requests: [][]string{{"a"}, {"b"}, {"c"}},
+			arrays:   []bool{false, false, false},
+		},
+		{
+			name:     "batches",
+			opts:     []webhook.WriterOption{webhook.WithBatchSize(2)},
+			results:  []scrapemate.Result{{Data: places("a", "b", "c")}, {Data: places("d", "e")}},
+			requests: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			arrays:   []bool{true, true, true},
+		},
+		{
+			name:    "results without places",
+			opts:    []webhook.WriterOption{webhook.WithBatchSize(2)},
+			results: []scrapemate.Result{{Data: nil}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var rcv receiver
+
+			srv := httptest.NewServer(&rcv)
+			defer srv.Close()
+
+			require.NoError(t, run(webhook.NewWriter(srv.URL, tc.opts...), tc.results...))
+			require.Equal(t, tc.requests, rcv.received())
+			require.Equal(t, tc.arrays, rcv.arrays)
+		})
+	}
+}
+
+func Test_WriterSignature(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		opts   []webhook.WriterOption
+	}{
+		{
+			name: "without secret",
+		},
+		{
+			name:   "place",
+			secret: "s3cret",
+			opts:   []webhook.WriterOption{webhook.WithSecret("s3cret")},
+		},
+		{
+			name:   "batch",
+			secret: "s3cret",
+			opts:   []webhook.WriterOption{webhook.WithSecret("s3cret"), webhook.WithBatchSize(2)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rcv := receiver{secret: tc.secret}
+
+			srv := httptest.NewServer(&rcv)
+			defer srv.Close()
+
+			require.NoError(t, run(webhook.NewWriter(srv.URL, tc.opts...), scrapemate.Result{Data: places("a", "b")}))
+			require.NotEmpty(t, rcv.valid)
+			require.NotContains(t, rcv.valid, false)
+		})
+	}
+}
+
+func Test_Sign(t *testing.T) {
+	require.Equal(t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		webhook.Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func Test_WriterRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests [][]string
+		attempts int
+	}{
+		{
+			name:     "rate limited",
+			statuses: []int{http.StatusTooManyRequests, http.StatusBadGateway},
+			requests: [][]string{{"a"}},
+			attempts: 3,
+		},
+		{
+			name:     "retries exhausted",
+			statuses: []int{500, 500, 500, 500},
+			attempts: 4,
+		},
+		{
+			name:     "client error not retried",
+			statuses: []int{http.StatusNotFound},
+			attempts: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rcv := receiver{statuses: tc.statuses}
+
+			srv := httptest.NewServer(&rcv)
+			defer srv.Close()
+
+			w := webhook.NewWriter(srv.URL)
+			w.SetBackoff(time.Millisecond)
+
+			// the places that cannot be delivered are skipped
+			require.NoError(t, run(w, scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}))
+			require.Equal(t, tc.requests, rcv.received())
+			require.Equal(t, tc.attempts, rcv.attempts)
+		})
+	}
+}
+
+func Test_WriterUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	w := webhook.NewWriter(srv.URL)
+	w.SetBackoff(time.Millisecond)
+
+	require.NoError(t, run(w, scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}))
+	require.Error(t, w.Post(context.Background(), map[string]string{"event": "done"}))
+}
+
+func Test_WriterPost(t *testing.T) {
+	rcv := receiver{secret: "s3cret", statuses: []int{http.StatusServiceUnavailable}}
+
+	srv := httptest.NewServer(&rcv)
+	defer srv.Close()
+
+	w := webhook.NewWriter(srv.URL, webhook.WithSecret("s3cret"))
+	w.SetBackoff(time.Millisecond)
+
+	require.NoError(t, w.Post(context.Background(), webhook.Payload{Title: "a"}))
+	require.Equal(t, [][]string{{"a"}}, rcv.received())
+	require.Equal(t, []bool{true}, rcv.valid)
+
+	rcv.mu.Lock()
+	rcv.statuses = []int{http.StatusUnauthorized}
+	rcv.mu.Unlock()
+
+	require.EqualError(t, w.Post(context.Background(), webhook.Payload{Title: "b"}), "unexpected status code 401")
+	require.ErrorContains(t, w.Post(context.Background(), func() {}), "unsupported type")
+}
+
+func Test_WriterBatchAge(t *testing.T) {
+	var rcv receiver
+
+	srv := httptest.NewServer(&rcv)
+	defer srv.Close()
+
+	w := webhook.NewWriter(srv.URL, webhook.WithBatchSize(10), webhook.WithBatchAge(50*time.Millisecond))
+
+	in := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.Run(context.Background(), in)
+	}()
+
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "a"}, {Title: "b"}}}
+
+	// the batch is posted while no place arrives
+	require.Eventually(t, func() bool {
+		return len(rcv.received()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, [][]string{{"a", "b"}}, rcv.received())
+
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "c"}}
+
+	close(in)
+	require.NoError(t, <-done)
+
+	require.Equal(t, [][]string{{"a", "b"}, {"c"}}, rcv.received())
+}